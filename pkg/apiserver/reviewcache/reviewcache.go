@@ -0,0 +1,66 @@
+// Package reviewcache caches the TokenReviews and SubjectAccessReviews of webhooks and extension servers, so they do
+// not create a review in the kube-apiserver for every request they serve. The caches are bounded in size and their
+// entries expire, the allowed and denied reviews having separate TTLs. Failed reviews are never cached.
+package reviewcache
+
+import (
+	"time"
+
+	"k8s.io/utils/clock"
+)
+
+const (
+	defaultMaxSize = 1024
+	// the same defaults as the webhook token authenticator and authorizer of the kube-apiserver
+	defaultTokenAllowedTTL = 2 * time.Minute
+	defaultTokenDeniedTTL  = 2 * time.Minute
+	defaultSARAllowedTTL   = 5 * time.Minute
+	defaultSARDeniedTTL    = 30 * time.Second
+)
+
+type options struct {
+	maxSize    int
+	allowedTTL time.Duration
+	deniedTTL  time.Duration
+	clock      clock.PassiveClock
+}
+
+// Option configures a TokenReviewer or a SubjectAccessReviewer.
+type Option func(*options)
+
+// WithMaxSize bounds the number of cached reviews, the least recently used are evicted first. It defaults to 1024.
+func WithMaxSize(maxSize int) Option {
+	return func(o *options) {
+		o.maxSize = maxSize
+	}
+}
+
+// WithTTL sets how long the authenticated or allowed reviews, and the unauthenticated or denied reviews, are cached.
+// A zero TTL disables the cache of the reviews. The defaults are 2m for the tokens, and 5m and 30s for the subject
+// access reviews.
+func WithTTL(allowed, denied time.Duration) Option {
+	return func(o *options) {
+		o.allowedTTL = allowed
+		o.deniedTTL = denied
+	}
+}
+
+func newOptions(allowedTTL, deniedTTL time.Duration, opts []Option) *options {
+	ret := &options{
+		maxSize:    defaultMaxSize,
+		allowedTTL: allowedTTL,
+		deniedTTL:  deniedTTL,
+		clock:      clock.RealClock{},
+	}
+	for _, opt := range opts {
+		opt(ret)
+	}
+	return ret
+}
+
+func (o *options) ttl(allowed bool) time.Duration {
+	if allowed {
+		return o.allowedTTL
+	}
+	return o.deniedTTL
+}
@@ -0,0 +1,120 @@
+package reviewcache
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func withClock(clock *clocktesting.FakeClock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+func TestTokenReviewer(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	reviews := 0
+	client.PrependReactor("create", "tokenreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		reviews++
+		review := action.(clienttesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		if review.Spec.Token == "valid" {
+			review.Status = authenticationv1.TokenReviewStatus{
+				Authenticated: true,
+				Audiences:     review.Spec.Audiences,
+				User:          authenticationv1.UserInfo{Username: "alice", Groups: []string{"system:authenticated"}},
+			}
+		}
+		if review.Spec.Token == "expired" {
+			review.Status = authenticationv1.TokenReviewStatus{Error: "token has expired"}
+		}
+		return true, review, nil
+	})
+	clock := clocktesting.NewFakeClock(time.Now())
+	reviewer := NewTokenReviewer(client.AuthenticationV1().TokenReviews(), []string{"webhook"}, WithTTL(time.Minute, 10*time.Second), withClock(clock))
+
+	for i := 0; i < 3; i++ {
+		response, ok, err := reviewer.AuthenticateToken(context.TODO(), "valid")
+		if err != nil || !ok || response.User.GetName() != "alice" || response.Audiences[0] != "webhook" {
+			t.Fatalf("unexpected response %v, %v, %v", response, ok, err)
+		}
+		if _, ok, err := reviewer.AuthenticateToken(context.TODO(), "invalid"); err != nil || ok {
+			t.Fatalf("expected the invalid token to be unauthenticated: %v", err)
+		}
+		if _, ok, err := reviewer.AuthenticateToken(context.TODO(), "expired"); err == nil || err.Error() != "token has expired" || ok {
+			t.Fatalf("expected the error of the review of the expired token, got %v", err)
+		}
+	}
+	if reviews != 3 {
+		t.Errorf("expected the reviews to be cached, got %d reviews", reviews)
+	}
+
+	// the unauthenticated tokens expire first
+	clock.Step(30 * time.Second)
+	reviewer.AuthenticateToken(context.TODO(), "valid")
+	reviewer.AuthenticateToken(context.TODO(), "invalid")
+	if reviews != 4 {
+		t.Errorf("expected the invalid token to be reviewed again, got %d reviews", reviews)
+	}
+
+	for _, key := range reviewer.cache.Keys() {
+		if strings.Contains(key.(string), "valid") {
+			t.Errorf("expected the tokens to be hashed in the cache keys, got %q", key)
+		}
+	}
+}
+
+func TestSubjectAccessReviewer(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	reviews := 0
+	failing := false
+	client.PrependReactor("create", "subjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		reviews++
+		if failing {
+			return true, nil, kerrors.NewServiceUnavailable("unavailable")
+		}
+		review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		review.Status.Allowed = review.Spec.User == "alice"
+		if !review.Status.Allowed {
+			review.Status.Reason = "not alice"
+		}
+		return true, review, nil
+	})
+	reviewer := NewSubjectAccessReviewer(client.AuthorizationV1().SubjectAccessReviews(), WithMaxSize(10))
+	attributes := &authorizationv1.ResourceAttributes{Verb: "get", Resource: "secrets", Namespace: "ns"}
+	alice := &user.DefaultInfo{Name: "alice", Extra: map[string][]string{"b": {"1"}, "a": {"2"}}}
+	bob := &user.DefaultInfo{Name: "bob"}
+
+	for i := 0; i < 3; i++ {
+		if err := reviewer.Authorize(context.TODO(), alice, attributes); err != nil {
+			t.Fatal(err)
+		}
+		if err := reviewer.Authorize(context.TODO(), bob, attributes); !kerrors.IsForbidden(err) {
+			t.Fatalf("expected bob to be forbidden, got %v", err)
+		}
+	}
+	if reviews != 2 {
+		t.Errorf("expected the reviews to be cached, got %d reviews", reviews)
+	}
+
+	failing = true
+	other := &authorizationv1.ResourceAttributes{Verb: "list", Resource: "secrets", Namespace: "ns"}
+	for i := 0; i < 2; i++ {
+		if _, err := reviewer.Review(context.TODO(), authorizationv1.SubjectAccessReviewSpec{User: "alice", ResourceAttributes: other}); err == nil {
+			t.Fatal("expected the review to fail")
+		}
+	}
+	if reviews != 4 {
+		t.Errorf("expected the failed reviews not to be cached, got %d reviews", reviews)
+	}
+}
@@ -0,0 +1,77 @@
+package reviewcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/apiserver/pkg/authentication/user"
+	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+
+	"github.com/openshift/library-go/pkg/authorization/authorizationutil"
+)
+
+// SubjectAccessReviewer reviews the access of users with SubjectAccessReviews, caching the results.
+type SubjectAccessReviewer struct {
+	client  authorizationclient.SubjectAccessReviewInterface
+	options *options
+	cache   *cache.LRUExpireCache
+}
+
+// NewSubjectAccessReviewer returns a reviewer creating the SubjectAccessReviews with the client.
+func NewSubjectAccessReviewer(client authorizationclient.SubjectAccessReviewInterface, opts ...Option) *SubjectAccessReviewer {
+	options := newOptions(defaultSARAllowedTTL, defaultSARDeniedTTL, opts)
+	return &SubjectAccessReviewer{
+		client:  client,
+		options: options,
+		cache:   cache.NewLRUExpireCacheWithClock(options.maxSize, options.clock),
+	}
+}
+
+// Review returns the status of the review of the access.
+func (r *SubjectAccessReviewer) Review(ctx context.Context, spec authorizationv1.SubjectAccessReviewSpec) (*authorizationv1.SubjectAccessReviewStatus, error) {
+	// the JSON of the spec is stable, the keys of the extra maps are sorted
+	key, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := r.cache.Get(string(key)); ok {
+		return cached.(*authorizationv1.SubjectAccessReviewStatus).DeepCopy(), nil
+	}
+
+	review, err := r.client.Create(ctx, &authorizationv1.SubjectAccessReview{Spec: spec}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(review.Status.EvaluationError) > 0 && !review.Status.Allowed && !review.Status.Denied {
+		// not a decision, the authorizer failed
+		return nil, errors.New(review.Status.EvaluationError)
+	}
+	if ttl := r.options.ttl(review.Status.Allowed); ttl > 0 {
+		r.cache.Add(string(key), review.Status.DeepCopy(), ttl)
+	}
+	return &review.Status, nil
+}
+
+// Authorize verifies that the user is permitted to carry out the action, like authorizationutil.Authorize. It returns
+// a forbidden error when the user is not permitted or when it cannot be determined.
+func (r *SubjectAccessReviewer) Authorize(ctx context.Context, user user.Info, resourceAttributes *authorizationv1.ResourceAttributes) error {
+	sar := authorizationutil.AddUserToSAR(user, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: resourceAttributes,
+		},
+	})
+	status, err := r.Review(ctx, sar.Spec)
+	if err == nil && status.Allowed {
+		return nil
+	}
+	if err == nil {
+		err = errors.New(status.Reason)
+	}
+	return kerrors.NewForbidden(schema.GroupResource{Group: resourceAttributes.Group, Resource: resourceAttributes.Resource}, resourceAttributes.Name, err)
+}
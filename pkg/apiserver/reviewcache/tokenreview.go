@@ -0,0 +1,92 @@
+package reviewcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	authenticationclient "k8s.io/client-go/kubernetes/typed/authentication/v1"
+)
+
+// TokenReviewer reviews bearer tokens with TokenReviews, caching the results. It is an authenticator.Token.
+type TokenReviewer struct {
+	client    authenticationclient.TokenReviewInterface
+	audiences []string
+	options   *options
+	cache     *cache.LRUExpireCache
+}
+
+var _ authenticator.Token = &TokenReviewer{}
+
+// NewTokenReviewer returns a reviewer of the tokens meant for the audiences, or for the kube-apiserver when no
+// audience is given.
+func NewTokenReviewer(client authenticationclient.TokenReviewInterface, audiences []string, opts ...Option) *TokenReviewer {
+	options := newOptions(defaultTokenAllowedTTL, defaultTokenDeniedTTL, opts)
+	return &TokenReviewer{
+		client:    client,
+		audiences: audiences,
+		options:   options,
+		cache:     cache.NewLRUExpireCacheWithClock(options.maxSize, options.clock),
+	}
+}
+
+// Review returns the status of the review of the token, or the error of an unauthenticated review. The reviews are
+// cached, including the unauthenticated ones with an error, only the failed TokenReview requests are not.
+func (r *TokenReviewer) Review(ctx context.Context, token string) (*authenticationv1.TokenReviewStatus, error) {
+	// the tokens are only kept hashed in memory
+	key := tokenCacheKey(token, r.audiences)
+	if cached, ok := r.cache.Get(key); ok {
+		return reviewStatus(cached.(*authenticationv1.TokenReviewStatus).DeepCopy())
+	}
+
+	review, err := r.client.Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token, Audiences: r.audiences},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if ttl := r.options.ttl(review.Status.Authenticated); ttl > 0 {
+		r.cache.Add(key, review.Status.DeepCopy(), ttl)
+	}
+	return reviewStatus(&review.Status)
+}
+
+func reviewStatus(status *authenticationv1.TokenReviewStatus) (*authenticationv1.TokenReviewStatus, error) {
+	if len(status.Error) > 0 && !status.Authenticated {
+		return nil, errors.New(status.Error)
+	}
+	return status, nil
+}
+
+// AuthenticateToken authenticates the token with a TokenReview.
+func (r *TokenReviewer) AuthenticateToken(ctx context.Context, token string) (*authenticator.Response, bool, error) {
+	status, err := r.Review(ctx, token)
+	if err != nil || !status.Authenticated {
+		return nil, false, err
+	}
+	extra := map[string][]string{}
+	for key, value := range status.User.Extra {
+		extra[key] = value
+	}
+	return &authenticator.Response{
+		Audiences: status.Audiences,
+		User: &user.DefaultInfo{
+			Name:   status.User.Username,
+			UID:    status.User.UID,
+			Groups: status.User.Groups,
+			Extra:  extra,
+		},
+	}, true, nil
+}
+
+func tokenCacheKey(token string, audiences []string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:]) + "/" + strings.Join(audiences, ",")
+}
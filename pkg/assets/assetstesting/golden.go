@@ -0,0 +1,214 @@
+// Package assetstesting compares the assets an operator renders for the usual cluster profiles against golden files,
+// so rendering regressions are caught in unit tests:
+//
+//	func TestRender(t *testing.T) {
+//		assetstesting.AssertGoldenFiles(t, "testdata/golden", assetstesting.DefaultClusterProfiles(), func(profile assetstesting.ClusterProfile) (map[string][]byte, error) {
+//			return renderAssets(profile.Infrastructure, profile.Network, profile.Proxy)
+//		})
+//	}
+//
+// The golden files are stored in a directory per profile and are rewritten by running the tests with
+// UPDATE_GOLDEN_FILES=true.
+package assetstesting
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// UpdateGoldenFilesEnvVar rewrites the golden files with the rendered assets when it is set to "true".
+const UpdateGoldenFilesEnvVar = "UPDATE_GOLDEN_FILES"
+
+// ClusterProfile is the cluster configuration the assets are rendered for.
+type ClusterProfile struct {
+	// Name is the name of the directory of the golden files of the profile.
+	Name           string
+	Infrastructure *configv1.Infrastructure
+	Network        *configv1.Network
+	Proxy          *configv1.Proxy
+}
+
+// RenderFunc renders the assets for the profile, by file name.
+type RenderFunc func(profile ClusterProfile) (map[string][]byte, error)
+
+// HAProfile is a highly available cluster with an IPv4 network and no proxy.
+func HAProfile() ClusterProfile {
+	return ClusterProfile{
+		Name: "ha",
+		Infrastructure: &configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Status: configv1.InfrastructureStatus{
+				ControlPlaneTopology:   configv1.HighlyAvailableTopologyMode,
+				InfrastructureTopology: configv1.HighlyAvailableTopologyMode,
+				APIServerURL:           "https://api.example.com:6443",
+				APIServerInternalURL:   "https://api-int.example.com:6443",
+			},
+		},
+		Network: &configv1.Network{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Status: configv1.NetworkStatus{
+				ClusterNetwork: []configv1.ClusterNetworkEntry{{CIDR: "10.128.0.0/14", HostPrefix: 23}},
+				ServiceNetwork: []string{"172.30.0.0/16"},
+				NetworkType:    "OVNKubernetes",
+			},
+		},
+		Proxy: &configv1.Proxy{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
+	}
+}
+
+// SNOProfile is a single node cluster.
+func SNOProfile() ClusterProfile {
+	profile := HAProfile()
+	profile.Name = "sno"
+	profile.Infrastructure.Status.ControlPlaneTopology = configv1.SingleReplicaTopologyMode
+	profile.Infrastructure.Status.InfrastructureTopology = configv1.SingleReplicaTopologyMode
+	return profile
+}
+
+// HostedProfile is a cluster whose control plane runs outside of it.
+func HostedProfile() ClusterProfile {
+	profile := HAProfile()
+	profile.Name = "hosted"
+	profile.Infrastructure.Status.ControlPlaneTopology = configv1.ExternalTopologyMode
+	return profile
+}
+
+// ProxyProfile is a highly available cluster reaching the outside through a proxy.
+func ProxyProfile() ClusterProfile {
+	profile := HAProfile()
+	profile.Name = "proxy"
+	profile.Proxy.Spec = configv1.ProxySpec{
+		HTTPProxy:  "http://proxy.example.com:3128",
+		HTTPSProxy: "http://proxy.example.com:3128",
+		NoProxy:    "example.org",
+	}
+	profile.Proxy.Status = configv1.ProxyStatus{
+		HTTPProxy:  "http://proxy.example.com:3128",
+		HTTPSProxy: "http://proxy.example.com:3128",
+		NoProxy:    ".cluster.local,.svc,10.128.0.0/14,127.0.0.1,172.30.0.0/16,api-int.example.com,example.org,localhost",
+	}
+	return profile
+}
+
+// DualStackProfile is a highly available cluster with IPv4 and IPv6 networks.
+func DualStackProfile() ClusterProfile {
+	profile := HAProfile()
+	profile.Name = "dual-stack"
+	profile.Network.Status.ClusterNetwork = append(profile.Network.Status.ClusterNetwork, configv1.ClusterNetworkEntry{CIDR: "fd01::/48", HostPrefix: 64})
+	profile.Network.Status.ServiceNetwork = append(profile.Network.Status.ServiceNetwork, "fd02::/112")
+	return profile
+}
+
+// DefaultClusterProfiles returns the SNO, HA, hosted, proxy and dual-stack profiles.
+func DefaultClusterProfiles() []ClusterProfile {
+	return []ClusterProfile{SNOProfile(), HAProfile(), HostedProfile(), ProxyProfile(), DualStackProfile()}
+}
+
+// AssertGoldenFiles renders the assets for every profile and compares them with the golden files in the directory
+// of the profile in goldenDir, reporting a diff for every changed, missing or unexpected file.
+func AssertGoldenFiles(t *testing.T, goldenDir string, profiles []ClusterProfile, render RenderFunc) {
+	t.Helper()
+	for _, profile := range profiles {
+		t.Run(profile.Name, func(t *testing.T) {
+			t.Helper()
+			rendered, err := render(profile)
+			if err != nil {
+				t.Fatalf("failed to render the assets: %v", err)
+			}
+			profileDir := filepath.Join(goldenDir, profile.Name)
+
+			if os.Getenv(UpdateGoldenFilesEnvVar) == "true" {
+				if err := writeGoldenFiles(profileDir, rendered); err != nil {
+					t.Fatal(err)
+				}
+				t.Logf("updated the golden files in %s", profileDir)
+				return
+			}
+
+			golden, err := readGoldenFiles(profileDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, diff := range diffFiles(golden, rendered) {
+				t.Error(diff)
+			}
+			if t.Failed() {
+				t.Logf("re-run with %s=true to update the golden files", UpdateGoldenFilesEnvVar)
+			}
+		})
+	}
+}
+
+// diffFiles returns a readable difference for every file which is not rendered as expected, by file name.
+func diffFiles(expected, actual map[string][]byte) []string {
+	var ret []string
+	for _, name := range sets.List(sets.KeySet(expected).Union(sets.KeySet(actual))) {
+		expectedContent, isExpected := expected[name]
+		actualContent, isRendered := actual[name]
+		switch {
+		case !isRendered:
+			ret = append(ret, fmt.Sprintf("%s: expected but not rendered", name))
+		case !isExpected:
+			ret = append(ret, fmt.Sprintf("%s: rendered but has no golden file:\n%s", name, actualContent))
+		case string(expectedContent) != string(actualContent):
+			ret = append(ret, fmt.Sprintf("%s: differs from the golden file (-golden +rendered):\n%s", name, cmp.Diff(lines(expectedContent), lines(actualContent))))
+		}
+	}
+	return ret
+}
+
+func lines(content []byte) []string {
+	return strings.Split(string(content), "\n")
+}
+
+func readGoldenFiles(dir string) (map[string][]byte, error) {
+	ret := map[string][]byte{}
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		ret[filepath.ToSlash(name)] = content
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return ret, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the golden files: %w", err)
+	}
+	return ret, nil
+}
+
+// writeGoldenFiles replaces the golden files in the directory with the files.
+func writeGoldenFiles(dir string, files map[string][]byte) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
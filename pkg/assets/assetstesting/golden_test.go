@@ -0,0 +1,79 @@
+package assetstesting
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffFiles(t *testing.T) {
+	expected := map[string][]byte{
+		"deployment.yaml": []byte("replicas: 1\nimage: foo\n"),
+		"service.yaml":    []byte("port: 443\n"),
+		"missing.yaml":    []byte("kind: ConfigMap\n"),
+	}
+	actual := map[string][]byte{
+		"deployment.yaml":    []byte("replicas: 3\nimage: foo\n"),
+		"service.yaml":       []byte("port: 443\n"),
+		"unexpected.yaml":    []byte("kind: Secret\n"),
+		"nested/config.yaml": []byte("a: b\n"),
+	}
+
+	diffs := diffFiles(expected, actual)
+	if len(diffs) != 4 {
+		t.Fatalf("expected 4 differences, got %d:\n%s", len(diffs), strings.Join(diffs, "\n"))
+	}
+	for i, prefix := range []string{
+		"deployment.yaml: differs from the golden file",
+		"missing.yaml: expected but not rendered",
+		"nested/config.yaml: rendered but has no golden file",
+		"unexpected.yaml: rendered but has no golden file",
+	} {
+		if !strings.HasPrefix(diffs[i], prefix) {
+			t.Errorf("expected %q to start with %q", diffs[i], prefix)
+		}
+	}
+	if !strings.Contains(diffs[0], `"replicas: 1"`) || !strings.Contains(diffs[0], `"replicas: 3"`) {
+		t.Errorf("expected the changed line in the diff, got:\n%s", diffs[0])
+	}
+}
+
+func TestGoldenFilesRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"deployment.yaml":    []byte("replicas: 1\n"),
+		"nested/config.yaml": []byte("a: b\n"),
+	}
+	if err := writeGoldenFiles(dir, map[string][]byte{"stale.yaml": []byte("stale")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeGoldenFiles(dir, files); err != nil {
+		t.Fatal(err)
+	}
+	read, err := readGoldenFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(files, read); diff != "" {
+		t.Errorf("unexpected golden files (-want +got):\n%s", diff)
+	}
+
+	read, err = readGoldenFiles(dir + "/missing")
+	if err != nil || len(read) != 0 {
+		t.Errorf("expected no golden files for a missing directory, got %v, %v", read, err)
+	}
+}
+
+func TestDefaultClusterProfiles(t *testing.T) {
+	names := map[string]bool{}
+	for _, profile := range DefaultClusterProfiles() {
+		if names[profile.Name] {
+			t.Errorf("duplicate profile %q", profile.Name)
+		}
+		names[profile.Name] = true
+	}
+	if HAProfile().Infrastructure == SNOProfile().Infrastructure {
+		t.Error("expected the profiles not to share their config")
+	}
+}
@@ -0,0 +1,134 @@
+package capabilities
+
+import (
+	"sync"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+const clusterVersionName = "version"
+
+// CapabilityChangeFunc is called when a capability becomes enabled or disabled.
+type CapabilityChangeFunc func(capability configv1.ClusterVersionCapability, enabled bool)
+
+// Capabilities answers whether ClusterVersion capabilities are enabled, using an informer cache.
+type Capabilities struct {
+	lister    configv1listers.ClusterVersionLister
+	hasSynced cache.InformerSynced
+
+	lock    sync.Mutex
+	enabled sets.Set[configv1.ClusterVersionCapability]
+	// allEnabled is set while there is no ClusterVersion, every capability is enabled then
+	allEnabled bool
+	synced     bool
+	handlers   map[configv1.ClusterVersionCapability][]CapabilityChangeFunc
+}
+
+// NewCapabilities returns Capabilities backed by the given ClusterVersion informer. The informer must be started by the caller.
+func NewCapabilities(clusterVersionInformer configv1informers.ClusterVersionInformer) *Capabilities {
+	c := &Capabilities{
+		lister:    clusterVersionInformer.Lister(),
+		hasSynced: clusterVersionInformer.Informer().HasSynced,
+		enabled:   sets.New[configv1.ClusterVersionCapability](),
+		handlers:  map[configv1.ClusterVersionCapability][]CapabilityChangeFunc{},
+	}
+	clusterVersionInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { c.notify() },
+		UpdateFunc: func(interface{}, interface{}) { c.notify() },
+		DeleteFunc: func(interface{}) { c.notify() },
+	})
+	return c
+}
+
+// HasSynced returns true when the backing informer has synced.
+func (c *Capabilities) HasSynced() bool {
+	return c.hasSynced()
+}
+
+// IsCapabilityEnabled returns whether the capability is enabled. A missing ClusterVersion means the cluster is not
+// managed by the cluster version operator, in which case every capability is considered enabled.
+func (c *Capabilities) IsCapabilityEnabled(capability configv1.ClusterVersionCapability) (bool, error) {
+	enabled, err := c.EnabledCapabilities()
+	if err != nil {
+		return false, err
+	}
+	if enabled == nil {
+		return true, nil
+	}
+	return enabled.Has(capability), nil
+}
+
+// EnabledCapabilities returns the enabled capabilities, or nil when there is no ClusterVersion.
+func (c *Capabilities) EnabledCapabilities() (sets.Set[configv1.ClusterVersionCapability], error) {
+	clusterVersion, err := c.lister.Get(clusterVersionName)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sets.New(clusterVersion.Status.Capabilities.EnabledCapabilities...), nil
+}
+
+// Subscribe registers a function that is called whenever the capability is enabled or disabled.
+// The function is called immediately with the current state if it is already known.
+func (c *Capabilities) Subscribe(capability configv1.ClusterVersionCapability, handler CapabilityChangeFunc) {
+	c.lock.Lock()
+	c.handlers[capability] = append(c.handlers[capability], handler)
+	synced, enabled := c.synced, c.allEnabled || c.enabled.Has(capability)
+	c.lock.Unlock()
+
+	if synced {
+		handler(capability, enabled)
+		return
+	}
+	// without a ClusterVersion the informer delivers no event, the state is known once it has synced
+	if c.hasSynced() {
+		c.notify()
+	}
+}
+
+func (c *Capabilities) notify() {
+	enabled, err := c.EnabledCapabilities()
+	if err != nil {
+		klog.V(4).Infof("unable to read enabled capabilities: %v", err)
+		return
+	}
+	// a missing ClusterVersion enables every capability, like IsCapabilityEnabled reports
+	allEnabled := enabled == nil
+
+	type change struct {
+		capability configv1.ClusterVersionCapability
+		enabled    bool
+		handlers   []CapabilityChangeFunc
+	}
+	var changes []change
+
+	c.lock.Lock()
+	firstSync := !c.synced
+	for capability, handlers := range c.handlers {
+		wasEnabled, isEnabled := c.allEnabled || c.enabled.Has(capability), allEnabled || enabled.Has(capability)
+		if firstSync || wasEnabled != isEnabled {
+			changes = append(changes, change{capability: capability, enabled: isEnabled, handlers: append([]CapabilityChangeFunc{}, handlers...)})
+		}
+	}
+	if !allEnabled {
+		c.enabled = enabled
+	}
+	c.allEnabled = allEnabled
+	c.synced = true
+	c.lock.Unlock()
+
+	for _, change := range changes {
+		klog.V(2).Infof("capability %q enabled: %v", change.capability, change.enabled)
+		for _, handler := range change.handlers {
+			handler(change.capability, change.enabled)
+		}
+	}
+}
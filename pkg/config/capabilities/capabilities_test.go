@@ -0,0 +1,139 @@
+package capabilities
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	configinformers "github.com/openshift/client-go/config/informers/externalversions"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestCapabilities(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clusterVersion := &configv1.ClusterVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "version"},
+		Status: configv1.ClusterVersionStatus{
+			Capabilities: configv1.ClusterVersionCapabilitiesStatus{
+				EnabledCapabilities: []configv1.ClusterVersionCapability{configv1.ClusterVersionCapabilityConsole},
+			},
+		},
+	}
+	client := configfake.NewSimpleClientset(clusterVersion)
+	informers := configinformers.NewSharedInformerFactory(client, 0)
+	capabilities := NewCapabilities(informers.Config().V1().ClusterVersions())
+
+	var lock sync.Mutex
+	observed := map[configv1.ClusterVersionCapability][]bool{}
+	record := func(capability configv1.ClusterVersionCapability, enabled bool) {
+		lock.Lock()
+		defer lock.Unlock()
+		observed[capability] = append(observed[capability], enabled)
+	}
+	capabilities.Subscribe(configv1.ClusterVersionCapabilityBuild, record)
+
+	informers.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), capabilities.HasSynced) {
+		t.Fatal("caches did not sync")
+	}
+
+	if enabled, err := capabilities.IsCapabilityEnabled(configv1.ClusterVersionCapabilityConsole); err != nil || !enabled {
+		t.Errorf("expected Console to be enabled: %v", err)
+	}
+	if enabled, err := capabilities.IsCapabilityEnabled(configv1.ClusterVersionCapabilityBuild); err != nil || enabled {
+		t.Errorf("expected Build to be disabled: %v", err)
+	}
+
+	clusterVersion = clusterVersion.DeepCopy()
+	clusterVersion.Status.Capabilities.EnabledCapabilities = append(clusterVersion.Status.Capabilities.EnabledCapabilities, configv1.ClusterVersionCapabilityBuild)
+	if _, err := client.ConfigV1().ClusterVersions().UpdateStatus(ctx, clusterVersion, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, 5*time.Second, true, func(ctx context.Context) (bool, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(observed[configv1.ClusterVersionCapabilityBuild]) == 2, nil
+	})
+	lock.Lock()
+	defer lock.Unlock()
+	if err != nil || observed[configv1.ClusterVersionCapabilityBuild][0] || !observed[configv1.ClusterVersionCapabilityBuild][1] {
+		t.Errorf("expected Build to be reported disabled and then enabled, got %v", observed)
+	}
+
+	// late subscribers get the current state right away
+	var late []bool
+	capabilities.Subscribe(configv1.ClusterVersionCapabilityConsole, func(_ configv1.ClusterVersionCapability, enabled bool) {
+		late = append(late, enabled)
+	})
+	if len(late) != 1 || !late[0] {
+		t.Errorf("expected Console to be reported enabled, got %v", late)
+	}
+}
+
+func TestCapabilitiesWithoutClusterVersion(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	informers := configinformers.NewSharedInformerFactory(configfake.NewSimpleClientset(), 0)
+	capabilities := NewCapabilities(informers.Config().V1().ClusterVersions())
+	if enabled, err := capabilities.IsCapabilityEnabled(configv1.ClusterVersionCapabilityBuild); err != nil || !enabled {
+		t.Errorf("expected capabilities to be enabled without a ClusterVersion: %v", err)
+	}
+
+	informers.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), capabilities.HasSynced) {
+		t.Fatal("caches did not sync")
+	}
+	// no event is delivered without a ClusterVersion, subscribers still learn the state
+	var observed []bool
+	capabilities.Subscribe(configv1.ClusterVersionCapabilityBuild, func(_ configv1.ClusterVersionCapability, enabled bool) {
+		observed = append(observed, enabled)
+	})
+	if len(observed) != 1 || !observed[0] {
+		t.Errorf("expected Build to be reported enabled, got %v", observed)
+	}
+}
+
+func TestCapabilitiesClusterVersionRemoved(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clusterVersion := &configv1.ClusterVersion{ObjectMeta: metav1.ObjectMeta{Name: "version"}}
+	client := configfake.NewSimpleClientset(clusterVersion)
+	informers := configinformers.NewSharedInformerFactory(client, 0)
+	capabilities := NewCapabilities(informers.Config().V1().ClusterVersions())
+
+	var lock sync.Mutex
+	var observed []bool
+	capabilities.Subscribe(configv1.ClusterVersionCapabilityBuild, func(_ configv1.ClusterVersionCapability, enabled bool) {
+		lock.Lock()
+		defer lock.Unlock()
+		observed = append(observed, enabled)
+	})
+	informers.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), capabilities.HasSynced) {
+		t.Fatal("caches did not sync")
+	}
+
+	if err := client.ConfigV1().ClusterVersions().Delete(ctx, "version", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	err := wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, 5*time.Second, true, func(ctx context.Context) (bool, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(observed) == 2, nil
+	})
+	lock.Lock()
+	defer lock.Unlock()
+	if err != nil || observed[0] || !observed[1] {
+		t.Errorf("expected Build to be reported disabled and then enabled once the ClusterVersion is gone, got %v", observed)
+	}
+}
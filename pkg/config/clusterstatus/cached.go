@@ -0,0 +1,224 @@
+package clusterstatus
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// ClusterInfo is the subset of cluster-wide configuration that controllers consult most often.
+type ClusterInfo struct {
+	PlatformType           configv1.PlatformType
+	ControlPlaneTopology   configv1.TopologyMode
+	InfrastructureTopology configv1.TopologyMode
+	APIServerURL           string
+	APIServerInternalURL   string
+	// DeploymentMode is derived from the control plane topology.
+	DeploymentMode DeploymentMode
+	// IngressDomain is only populated when an Ingress informer was provided.
+	IngressDomain string
+
+	// APILoadBalancerType, PlatformDNS and Region are derived from the platform, see APILoadBalancerTypeFor,
+	// PlatformDNSFor and RegionFor.
+	APILoadBalancerType APILoadBalancerType
+	PlatformDNS         PlatformDNS
+	Region              string
+}
+
+// ClusterInfoChangeFunc is called with the previous and the current ClusterInfo whenever it changes.
+type ClusterInfoChangeFunc func(old, new ClusterInfo)
+
+// CachedClusterStatus provides typed access to the cluster Infrastructure (and optionally Ingress) configuration
+// from informer caches instead of a live GET on every call.
+type CachedClusterStatus struct {
+	infraLister   configv1listers.InfrastructureLister
+	ingressLister configv1listers.IngressLister
+	cachesToSync  []cache.InformerSynced
+
+	lock     sync.Mutex
+	last     *ClusterInfo
+	handlers []ClusterInfoChangeFunc
+}
+
+// NewCachedClusterStatus returns a CachedClusterStatus backed by the given informers. ingressInformer is optional,
+// when it is nil the ingress domain is not available. The informers must be started by the caller.
+func NewCachedClusterStatus(infraInformer configv1informers.InfrastructureInformer, ingressInformer configv1informers.IngressInformer) *CachedClusterStatus {
+	c := &CachedClusterStatus{
+		infraLister:  infraInformer.Lister(),
+		cachesToSync: []cache.InformerSynced{infraInformer.Informer().HasSynced},
+	}
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { c.notify() },
+		UpdateFunc: func(interface{}, interface{}) { c.notify() },
+		DeleteFunc: func(interface{}) { c.notify() },
+	}
+	infraInformer.Informer().AddEventHandler(handler)
+	if ingressInformer != nil {
+		c.ingressLister = ingressInformer.Lister()
+		c.cachesToSync = append(c.cachesToSync, ingressInformer.Informer().HasSynced)
+		ingressInformer.Informer().AddEventHandler(handler)
+	}
+	return c
+}
+
+// HasSynced returns true when all backing informers have synced.
+func (c *CachedClusterStatus) HasSynced() bool {
+	for _, hasSynced := range c.cachesToSync {
+		if !hasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// CachesToSync returns the informer synced functions to wait for before using the accessors.
+func (c *CachedClusterStatus) CachesToSync() []cache.InformerSynced {
+	return c.cachesToSync
+}
+
+// AddChangeHandler registers a function called whenever the ClusterInfo changes.
+func (c *CachedClusterStatus) AddChangeHandler(handler ClusterInfoChangeFunc) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.handlers = append(c.handlers, handler)
+}
+
+// Infrastructure returns the cached cluster Infrastructure. Do not mutate the returned object.
+func (c *CachedClusterStatus) Infrastructure() (*configv1.Infrastructure, error) {
+	return c.infraLister.Get(infraResourceName)
+}
+
+// ClusterInfo returns the current ClusterInfo.
+func (c *CachedClusterStatus) ClusterInfo() (ClusterInfo, error) {
+	infra, err := c.Infrastructure()
+	if err != nil {
+		return ClusterInfo{}, err
+	}
+	info := ClusterInfo{
+		PlatformType:           PlatformTypeFor(infra),
+		ControlPlaneTopology:   infra.Status.ControlPlaneTopology,
+		InfrastructureTopology: infra.Status.InfrastructureTopology,
+		APIServerURL:           infra.Status.APIServerURL,
+		APIServerInternalURL:   infra.Status.APIServerInternalURL,
+		DeploymentMode:         DeploymentModeFor(infra),
+		APILoadBalancerType:    APILoadBalancerTypeFor(infra),
+		PlatformDNS:            PlatformDNSFor(infra),
+		Region:                 RegionFor(infra),
+	}
+	if c.ingressLister != nil {
+		ingress, err := c.ingressLister.Get(infraResourceName)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return ClusterInfo{}, err
+		}
+		if ingress != nil {
+			info.IngressDomain = ingress.Spec.Domain
+		}
+	}
+	return info, nil
+}
+
+// PlatformType returns the platform of the cluster.
+func (c *CachedClusterStatus) PlatformType() (configv1.PlatformType, error) {
+	info, err := c.ClusterInfo()
+	return info.PlatformType, err
+}
+
+// ControlPlaneTopology returns the control plane topology of the cluster.
+func (c *CachedClusterStatus) ControlPlaneTopology() (configv1.TopologyMode, error) {
+	info, err := c.ClusterInfo()
+	return info.ControlPlaneTopology, err
+}
+
+// InfrastructureTopology returns the infrastructure topology of the cluster.
+func (c *CachedClusterStatus) InfrastructureTopology() (configv1.TopologyMode, error) {
+	info, err := c.ClusterInfo()
+	return info.InfrastructureTopology, err
+}
+
+// DeploymentMode returns whether the control plane of the cluster is hosted. It can be used as DeploymentModeFunc.
+func (c *CachedClusterStatus) DeploymentMode() (DeploymentMode, error) {
+	info, err := c.ClusterInfo()
+	return info.DeploymentMode, err
+}
+
+// APIServerURL returns the external URL of the kube-apiserver.
+func (c *CachedClusterStatus) APIServerURL() (string, error) {
+	info, err := c.ClusterInfo()
+	return info.APIServerURL, err
+}
+
+// APIServerInternalURL returns the internal URL of the kube-apiserver.
+func (c *CachedClusterStatus) APIServerInternalURL() (string, error) {
+	info, err := c.ClusterInfo()
+	return info.APIServerInternalURL, err
+}
+
+// APILoadBalancerType returns where the load balancer of the kube-apiserver runs.
+func (c *CachedClusterStatus) APILoadBalancerType() (APILoadBalancerType, error) {
+	info, err := c.ClusterInfo()
+	return info.APILoadBalancerType, err
+}
+
+// PlatformDNS returns who serves the API and ingress DNS records of the cluster.
+func (c *CachedClusterStatus) PlatformDNS() (PlatformDNS, error) {
+	info, err := c.ClusterInfo()
+	return info.PlatformDNS, err
+}
+
+// Region returns the cloud region of the cluster, empty when it is not known.
+func (c *CachedClusterStatus) Region() (string, error) {
+	info, err := c.ClusterInfo()
+	return info.Region, err
+}
+
+// Zones returns the zones of the cluster known from the Infrastructure, see ZonesFor.
+func (c *CachedClusterStatus) Zones() ([]string, error) {
+	infra, err := c.Infrastructure()
+	if err != nil {
+		return nil, err
+	}
+	return ZonesFor(infra), nil
+}
+
+// IngressDomain returns the default ingress domain of the cluster. It fails if no Ingress informer was provided.
+func (c *CachedClusterStatus) IngressDomain() (string, error) {
+	if c.ingressLister == nil {
+		return "", fmt.Errorf("ingress domain is not available without an Ingress informer")
+	}
+	info, err := c.ClusterInfo()
+	return info.IngressDomain, err
+}
+
+func (c *CachedClusterStatus) notify() {
+	info, err := c.ClusterInfo()
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.V(4).Infof("unable to read cluster info: %v", err)
+		}
+		return
+	}
+
+	c.lock.Lock()
+	if c.last != nil && reflect.DeepEqual(*c.last, info) {
+		c.lock.Unlock()
+		return
+	}
+	var old ClusterInfo
+	if c.last != nil {
+		old = *c.last
+	}
+	c.last = &info
+	handlers := append([]ClusterInfoChangeFunc{}, c.handlers...)
+	c.lock.Unlock()
+
+	for _, handler := range handlers {
+		handler(old, info)
+	}
+}
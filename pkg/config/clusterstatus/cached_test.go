@@ -0,0 +1,102 @@
+package clusterstatus
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	configinformers "github.com/openshift/client-go/config/informers/externalversions"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestCachedClusterStatus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status: configv1.InfrastructureStatus{
+			Platform:               configv1.NonePlatformType,
+			PlatformStatus:         &configv1.PlatformStatus{Type: configv1.AWSPlatformType, AWS: &configv1.AWSPlatformStatus{Region: "us-east-1"}},
+			ControlPlaneTopology:   configv1.HighlyAvailableTopologyMode,
+			InfrastructureTopology: configv1.HighlyAvailableTopologyMode,
+			APIServerURL:           "https://api.example.com:6443",
+			APIServerInternalURL:   "https://api-int.example.com:6443",
+		},
+	}
+	ingress := &configv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       configv1.IngressSpec{Domain: "apps.example.com"},
+	}
+	client := configfake.NewSimpleClientset(infra, ingress)
+	informers := configinformers.NewSharedInformerFactory(client, 0)
+	status := NewCachedClusterStatus(informers.Config().V1().Infrastructures(), informers.Config().V1().Ingresses())
+
+	var lock sync.Mutex
+	var changes []ClusterInfo
+	status.AddChangeHandler(func(old, new ClusterInfo) {
+		lock.Lock()
+		defer lock.Unlock()
+		changes = append(changes, new)
+	})
+
+	informers.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), status.CachesToSync()...) {
+		t.Fatal("caches did not sync")
+	}
+
+	expected := ClusterInfo{
+		PlatformType:           configv1.AWSPlatformType,
+		ControlPlaneTopology:   configv1.HighlyAvailableTopologyMode,
+		InfrastructureTopology: configv1.HighlyAvailableTopologyMode,
+		APIServerURL:           "https://api.example.com:6443",
+		APIServerInternalURL:   "https://api-int.example.com:6443",
+		DeploymentMode:         StandaloneDeploymentMode,
+		IngressDomain:          "apps.example.com",
+		APILoadBalancerType:    ExternalAPILoadBalancer,
+		PlatformDNS:            CloudPlatformDNS,
+		Region:                 "us-east-1",
+	}
+	info, err := status.ClusterInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info != expected {
+		t.Errorf("expected %#v, got %#v", expected, info)
+	}
+	if domain, err := status.IngressDomain(); err != nil || domain != "apps.example.com" {
+		t.Errorf("unexpected ingress domain %q: %v", domain, err)
+	}
+
+	infra = infra.DeepCopy()
+	infra.Status.ControlPlaneTopology = configv1.SingleReplicaTopologyMode
+	if _, err := client.ConfigV1().Infrastructures().UpdateStatus(ctx, infra, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	// handlers are notified after the cache is updated, wait for the notification
+	err = wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, 5*time.Second, true, func(ctx context.Context) (bool, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(changes) > 0 && changes[len(changes)-1].ControlPlaneTopology == configv1.SingleReplicaTopologyMode, nil
+	})
+	if err != nil {
+		t.Errorf("expected change notification for the topology change, got %#v", changes)
+	}
+	if topology, err := status.ControlPlaneTopology(); err != nil || topology != configv1.SingleReplicaTopologyMode {
+		t.Errorf("unexpected topology %q: %v", topology, err)
+	}
+}
+
+func TestCachedClusterStatusWithoutIngress(t *testing.T) {
+	client := configfake.NewSimpleClientset()
+	informers := configinformers.NewSharedInformerFactory(client, 0)
+	status := NewCachedClusterStatus(informers.Config().V1().Infrastructures(), nil)
+	if _, err := status.IngressDomain(); err == nil {
+		t.Errorf("expected error without ingress informer")
+	}
+}
@@ -0,0 +1,40 @@
+package clusterstatus
+
+import (
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// DeploymentMode tells whether the control plane runs in the cluster or is hosted outside of it, e.g. by HyperShift.
+type DeploymentMode string
+
+const (
+	// StandaloneDeploymentMode means the control plane runs on the control plane nodes of the cluster.
+	StandaloneDeploymentMode DeploymentMode = "Standalone"
+	// HostedControlPlaneDeploymentMode means the control plane is hosted outside of the cluster. There are no control
+	// plane nodes to run static pods on, and the ClusterOperators are not written by the control plane operators.
+	HostedControlPlaneDeploymentMode DeploymentMode = "HostedControlPlane"
+)
+
+// IsHosted returns true for hosted control planes.
+func (m DeploymentMode) IsHosted() bool {
+	return m == HostedControlPlaneDeploymentMode
+}
+
+// DeploymentModeFunc returns the current deployment mode.
+type DeploymentModeFunc func() (DeploymentMode, error)
+
+// StaticDeploymentMode returns a DeploymentModeFunc always returning the mode, e.g. for operators that know how they
+// are deployed from their command line.
+func StaticDeploymentMode(mode DeploymentMode) DeploymentModeFunc {
+	return func() (DeploymentMode, error) {
+		return mode, nil
+	}
+}
+
+// DeploymentModeFor returns the deployment mode derived from the control plane topology of the Infrastructure.
+func DeploymentModeFor(infra *configv1.Infrastructure) DeploymentMode {
+	if infra.Status.ControlPlaneTopology == configv1.ExternalTopologyMode {
+		return HostedControlPlaneDeploymentMode
+	}
+	return StandaloneDeploymentMode
+}
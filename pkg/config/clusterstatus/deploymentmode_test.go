@@ -0,0 +1,21 @@
+package clusterstatus
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func TestDeploymentModeFor(t *testing.T) {
+	for topology, expected := range map[configv1.TopologyMode]DeploymentMode{
+		"":                                   StandaloneDeploymentMode,
+		configv1.HighlyAvailableTopologyMode: StandaloneDeploymentMode,
+		configv1.SingleReplicaTopologyMode:   StandaloneDeploymentMode,
+		configv1.ExternalTopologyMode:        HostedControlPlaneDeploymentMode,
+	} {
+		infra := &configv1.Infrastructure{Status: configv1.InfrastructureStatus{ControlPlaneTopology: topology}}
+		if mode := DeploymentModeFor(infra); mode != expected {
+			t.Errorf("expected %s for topology %q, got %s", expected, topology, mode)
+		}
+	}
+}
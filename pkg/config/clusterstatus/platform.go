@@ -0,0 +1,176 @@
+package clusterstatus
+
+import (
+	configv1 "github.com/openshift/api/config/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// APILoadBalancerType tells where the load balancer of the kube-apiserver runs.
+type APILoadBalancerType string
+
+const (
+	// InternalAPILoadBalancer runs in the cluster: keepalived and haproxy on the control plane nodes serve the API VIPs
+	// of the on-premise platforms.
+	InternalAPILoadBalancer APILoadBalancerType = "Internal"
+	// ExternalAPILoadBalancer is provided outside of the cluster, by the cloud or by the user.
+	ExternalAPILoadBalancer APILoadBalancerType = "External"
+	// UnknownAPILoadBalancer is returned for the platforms the helpers do not know.
+	UnknownAPILoadBalancer APILoadBalancerType = "Unknown"
+)
+
+// PlatformDNS tells who serves the API and ingress DNS records of the cluster.
+type PlatformDNS string
+
+const (
+	// CloudPlatformDNS means the records are served by the DNS service of the cloud.
+	CloudPlatformDNS PlatformDNS = "Cloud"
+	// ClusterHostedPlatformDNS means the records are served by DNS servers running in the cluster, e.g. the CoreDNS
+	// static pods of the on-premise platforms.
+	ClusterHostedPlatformDNS PlatformDNS = "ClusterHosted"
+	// UserProvidedPlatformDNS means the records are created by the user.
+	UserProvidedPlatformDNS PlatformDNS = "UserProvided"
+	// UnknownPlatformDNS is returned for the platforms the helpers do not know.
+	UnknownPlatformDNS PlatformDNS = "Unknown"
+)
+
+// PlatformTypeFor returns the platform of the Infrastructure, preferring the platform status over the deprecated
+// platform field.
+func PlatformTypeFor(infra *configv1.Infrastructure) configv1.PlatformType {
+	if infra.Status.PlatformStatus != nil && len(infra.Status.PlatformStatus.Type) > 0 {
+		return infra.Status.PlatformStatus.Type
+	}
+	return infra.Status.Platform
+}
+
+// APILoadBalancerTypeFor returns where the load balancer of the kube-apiserver runs. The on-premise platforms run it
+// in the cluster unless the user manages it or there are no API VIPs, e.g. on user provisioned infrastructure.
+func APILoadBalancerTypeFor(infra *configv1.Infrastructure) APILoadBalancerType {
+	platform := PlatformTypeFor(infra)
+	switch platform {
+	case configv1.AWSPlatformType, configv1.AzurePlatformType, configv1.GCPPlatformType, configv1.IBMCloudPlatformType,
+		configv1.PowerVSPlatformType, configv1.AlibabaCloudPlatformType, configv1.NonePlatformType, configv1.ExternalPlatformType:
+		return ExternalAPILoadBalancer
+	}
+	if vips, loadBalancer, ok := onPremiseAPI(infra.Status.PlatformStatus, platform); ok {
+		if len(vips) == 0 || loadBalancer == configv1.LoadBalancerTypeUserManaged {
+			return ExternalAPILoadBalancer
+		}
+		return InternalAPILoadBalancer
+	}
+	return UnknownAPILoadBalancer
+}
+
+// PlatformDNSFor returns who serves the API and ingress DNS records of the cluster.
+func PlatformDNSFor(infra *configv1.Infrastructure) PlatformDNS {
+	platform := PlatformTypeFor(infra)
+	switch platform {
+	case configv1.GCPPlatformType:
+		if status := infra.Status.PlatformStatus; status != nil && status.GCP != nil && status.GCP.CloudLoadBalancerConfig != nil &&
+			status.GCP.CloudLoadBalancerConfig.DNSType == configv1.ClusterHostedDNSType {
+			return ClusterHostedPlatformDNS
+		}
+		return CloudPlatformDNS
+	case configv1.AWSPlatformType, configv1.AzurePlatformType, configv1.IBMCloudPlatformType, configv1.PowerVSPlatformType,
+		configv1.AlibabaCloudPlatformType:
+		return CloudPlatformDNS
+	case configv1.NonePlatformType, configv1.ExternalPlatformType:
+		return UserProvidedPlatformDNS
+	}
+	// the CoreDNS static pods serve the records of the API VIPs
+	if vips, loadBalancer, ok := onPremiseAPI(infra.Status.PlatformStatus, platform); ok {
+		if len(vips) == 0 || loadBalancer == configv1.LoadBalancerTypeUserManaged {
+			return UserProvidedPlatformDNS
+		}
+		return ClusterHostedPlatformDNS
+	}
+	return UnknownPlatformDNS
+}
+
+// RegionFor returns the cloud region, or location, the cluster runs in. It is empty when the platform has no region or
+// the cluster spans several regions.
+func RegionFor(infra *configv1.Infrastructure) string {
+	if status := infra.Status.PlatformStatus; status != nil {
+		switch {
+		case status.AWS != nil:
+			return status.AWS.Region
+		case status.GCP != nil:
+			return status.GCP.Region
+		case status.IBMCloud != nil:
+			return status.IBMCloud.Location
+		case status.PowerVS != nil:
+			return status.PowerVS.Region
+		case status.AlibabaCloud != nil:
+			return status.AlibabaCloud.Region
+		}
+	}
+	if regions := vSphereFailureDomains(infra, func(fd configv1.VSpherePlatformFailureDomainSpec) string { return fd.Region }); len(regions) == 1 {
+		return regions[0]
+	}
+	return ""
+}
+
+// ZonesFor returns the sorted zones of the cluster known from the Infrastructure: the zone of PowerVS and the zones of
+// the vSphere failure domains. It is empty for the other platforms, their zones are found in the topology labels of
+// the nodes.
+func ZonesFor(infra *configv1.Infrastructure) []string {
+	if status := infra.Status.PlatformStatus; status != nil && status.PowerVS != nil && len(status.PowerVS.Zone) > 0 {
+		return []string{status.PowerVS.Zone}
+	}
+	return vSphereFailureDomains(infra, func(fd configv1.VSpherePlatformFailureDomainSpec) string { return fd.Zone })
+}
+
+// onPremiseAPI returns the API VIPs and the load balancer type of the on-premise platforms, ok is false for the other
+// platforms.
+func onPremiseAPI(status *configv1.PlatformStatus, platform configv1.PlatformType) (vips []string, loadBalancer configv1.PlatformLoadBalancerType, ok bool) {
+	switch platform {
+	case configv1.BareMetalPlatformType, configv1.OpenStackPlatformType, configv1.VSpherePlatformType,
+		configv1.OvirtPlatformType, configv1.NutanixPlatformType:
+	default:
+		return nil, "", false
+	}
+	if status == nil {
+		return nil, "", true
+	}
+	switch {
+	case status.BareMetal != nil:
+		vips = status.BareMetal.APIServerInternalIPs
+		if status.BareMetal.LoadBalancer != nil {
+			loadBalancer = status.BareMetal.LoadBalancer.Type
+		}
+	case status.OpenStack != nil:
+		vips = status.OpenStack.APIServerInternalIPs
+		if status.OpenStack.LoadBalancer != nil {
+			loadBalancer = status.OpenStack.LoadBalancer.Type
+		}
+	case status.VSphere != nil:
+		vips = status.VSphere.APIServerInternalIPs
+		if status.VSphere.LoadBalancer != nil {
+			loadBalancer = status.VSphere.LoadBalancer.Type
+		}
+	case status.Ovirt != nil:
+		vips = status.Ovirt.APIServerInternalIPs
+		if status.Ovirt.LoadBalancer != nil {
+			loadBalancer = status.Ovirt.LoadBalancer.Type
+		}
+	case status.Nutanix != nil:
+		vips = status.Nutanix.APIServerInternalIPs
+		if status.Nutanix.LoadBalancer != nil {
+			loadBalancer = status.Nutanix.LoadBalancer.Type
+		}
+	}
+	return vips, loadBalancer, true
+}
+
+// vSphereFailureDomains returns the sorted distinct values of the field of the vSphere failure domains.
+func vSphereFailureDomains(infra *configv1.Infrastructure, field func(configv1.VSpherePlatformFailureDomainSpec) string) []string {
+	if infra.Spec.PlatformSpec.VSphere == nil {
+		return nil
+	}
+	values := sets.New[string]()
+	for _, fd := range infra.Spec.PlatformSpec.VSphere.FailureDomains {
+		if value := field(fd); len(value) > 0 {
+			values.Insert(value)
+		}
+	}
+	return sets.List(values)
+}
@@ -0,0 +1,113 @@
+package clusterstatus
+
+import (
+	"reflect"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func TestPlatformHelpers(t *testing.T) {
+	tests := []struct {
+		name                 string
+		infra                *configv1.Infrastructure
+		expectedLoadBalancer APILoadBalancerType
+		expectedDNS          PlatformDNS
+		expectedRegion       string
+		expectedZones        []string
+	}{
+		{
+			name: "AWS",
+			infra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{
+				Type: configv1.AWSPlatformType,
+				AWS:  &configv1.AWSPlatformStatus{Region: "us-east-1"},
+			}}},
+			expectedLoadBalancer: ExternalAPILoadBalancer,
+			expectedDNS:          CloudPlatformDNS,
+			expectedRegion:       "us-east-1",
+		},
+		{
+			name: "GCP with cluster hosted DNS",
+			infra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{
+				Type: configv1.GCPPlatformType,
+				GCP: &configv1.GCPPlatformStatus{
+					Region:                  "europe-west1",
+					CloudLoadBalancerConfig: &configv1.CloudLoadBalancerConfig{DNSType: configv1.ClusterHostedDNSType},
+				},
+			}}},
+			expectedLoadBalancer: ExternalAPILoadBalancer,
+			expectedDNS:          ClusterHostedPlatformDNS,
+			expectedRegion:       "europe-west1",
+		},
+		{
+			name: "bare metal with API VIPs",
+			infra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{
+				Type:      configv1.BareMetalPlatformType,
+				BareMetal: &configv1.BareMetalPlatformStatus{APIServerInternalIPs: []string{"192.168.111.5"}},
+			}}},
+			expectedLoadBalancer: InternalAPILoadBalancer,
+			expectedDNS:          ClusterHostedPlatformDNS,
+		},
+		{
+			name: "vSphere with a user managed load balancer and failure domains",
+			infra: &configv1.Infrastructure{
+				Spec: configv1.InfrastructureSpec{PlatformSpec: configv1.PlatformSpec{VSphere: &configv1.VSpherePlatformSpec{
+					FailureDomains: []configv1.VSpherePlatformFailureDomainSpec{
+						{Name: "b", Region: "us-east", Zone: "us-east-2b"},
+						{Name: "a", Region: "us-east", Zone: "us-east-2a"},
+					},
+				}}},
+				Status: configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{
+					Type: configv1.VSpherePlatformType,
+					VSphere: &configv1.VSpherePlatformStatus{
+						APIServerInternalIPs: []string{"10.0.0.5"},
+						LoadBalancer:         &configv1.VSpherePlatformLoadBalancer{Type: configv1.LoadBalancerTypeUserManaged},
+					},
+				}},
+			},
+			expectedLoadBalancer: ExternalAPILoadBalancer,
+			expectedDNS:          UserProvidedPlatformDNS,
+			expectedRegion:       "us-east",
+			expectedZones:        []string{"us-east-2a", "us-east-2b"},
+		},
+		{
+			name:                 "OpenStack without platform status",
+			infra:                &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.OpenStackPlatformType}},
+			expectedLoadBalancer: ExternalAPILoadBalancer,
+			expectedDNS:          UserProvidedPlatformDNS,
+		},
+		{
+			name: "PowerVS",
+			infra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{
+				Type:    configv1.PowerVSPlatformType,
+				PowerVS: &configv1.PowerVSPlatformStatus{Region: "dal", Zone: "dal12"},
+			}}},
+			expectedLoadBalancer: ExternalAPILoadBalancer,
+			expectedDNS:          CloudPlatformDNS,
+			expectedRegion:       "dal",
+			expectedZones:        []string{"dal12"},
+		},
+		{
+			name:                 "unknown platform",
+			infra:                &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: "Future"}},
+			expectedLoadBalancer: UnknownAPILoadBalancer,
+			expectedDNS:          UnknownPlatformDNS,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if loadBalancer := APILoadBalancerTypeFor(tt.infra); loadBalancer != tt.expectedLoadBalancer {
+				t.Errorf("expected the %s API load balancer, got %s", tt.expectedLoadBalancer, loadBalancer)
+			}
+			if dns := PlatformDNSFor(tt.infra); dns != tt.expectedDNS {
+				t.Errorf("expected the %s DNS, got %s", tt.expectedDNS, dns)
+			}
+			if region := RegionFor(tt.infra); region != tt.expectedRegion {
+				t.Errorf("expected region %q, got %q", tt.expectedRegion, region)
+			}
+			if zones := ZonesFor(tt.infra); !reflect.DeepEqual(zones, tt.expectedZones) {
+				t.Errorf("expected zones %v, got %v", tt.expectedZones, zones)
+			}
+		})
+	}
+}
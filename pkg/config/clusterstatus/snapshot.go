@@ -0,0 +1,90 @@
+package clusterstatus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	openshiftcorev1 "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+const clusterVersionResourceName = "version"
+
+// ClusterSnapshot holds the cluster-wide configuration most operators need at startup.
+// Every field is nil when the corresponding resource could not be read, see Errors for the reason.
+type ClusterSnapshot struct {
+	Infrastructure *configv1.Infrastructure
+	Network        *configv1.Network
+	Proxy          *configv1.Proxy
+	APIServer      *configv1.APIServer
+	// Capabilities holds the capabilities status of the ClusterVersion.
+	Capabilities *configv1.ClusterVersionCapabilitiesStatus
+	FeatureGate  *configv1.FeatureGate
+
+	// Errors holds the error for every resource that could not be read, keyed by resource name, e.g. "infrastructures".
+	Errors map[string]error
+}
+
+// Err returns an error describing all failures, or nil when every resource was read.
+func (s *ClusterSnapshot) Err() error {
+	if len(s.Errors) == 0 {
+		return nil
+	}
+	resources := make([]string, 0, len(s.Errors))
+	for resource := range s.Errors {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	messages := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		messages = append(messages, fmt.Sprintf("%s: %v", resource, s.Errors[resource]))
+	}
+	return fmt.Errorf("unable to read cluster configuration: %s", strings.Join(messages, "; "))
+}
+
+// GetClusterSnapshot reads the cluster-wide configuration in one call. The returned snapshot is never nil and holds
+// everything that could be read, the error reports every resource that could not.
+func GetClusterSnapshot(ctx context.Context, restClient *rest.Config) (*ClusterSnapshot, error) {
+	client, err := openshiftcorev1.NewForConfig(restClient)
+	if err != nil {
+		return &ClusterSnapshot{}, err
+	}
+	return GetClusterSnapshotWithClient(ctx, client)
+}
+
+// GetClusterSnapshotWithClient is GetClusterSnapshot using an existing client.
+func GetClusterSnapshotWithClient(ctx context.Context, client openshiftcorev1.ConfigV1Interface) (*ClusterSnapshot, error) {
+	snapshot := &ClusterSnapshot{Errors: map[string]error{}}
+	recordErr := func(resource string, err error) bool {
+		if err != nil {
+			snapshot.Errors[resource] = err
+			return false
+		}
+		return true
+	}
+
+	if infra, err := client.Infrastructures().Get(ctx, infraResourceName, metav1.GetOptions{}); recordErr("infrastructures", err) {
+		snapshot.Infrastructure = infra
+	}
+	if network, err := client.Networks().Get(ctx, infraResourceName, metav1.GetOptions{}); recordErr("networks", err) {
+		snapshot.Network = network
+	}
+	if proxy, err := client.Proxies().Get(ctx, infraResourceName, metav1.GetOptions{}); recordErr("proxies", err) {
+		snapshot.Proxy = proxy
+	}
+	if apiServer, err := client.APIServers().Get(ctx, infraResourceName, metav1.GetOptions{}); recordErr("apiservers", err) {
+		snapshot.APIServer = apiServer
+	}
+	if clusterVersion, err := client.ClusterVersions().Get(ctx, clusterVersionResourceName, metav1.GetOptions{}); recordErr("clusterversions", err) {
+		snapshot.Capabilities = clusterVersion.Status.Capabilities.DeepCopy()
+	}
+	if featureGate, err := client.FeatureGates().Get(ctx, infraResourceName, metav1.GetOptions{}); recordErr("featuregates", err) {
+		snapshot.FeatureGate = featureGate
+	}
+
+	return snapshot, snapshot.Err()
+}
@@ -0,0 +1,49 @@
+package clusterstatus
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetClusterSnapshotWithClient(t *testing.T) {
+	client := configfake.NewSimpleClientset(
+		&configv1.Infrastructure{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
+		&configv1.Network{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
+		&configv1.Proxy{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
+		&configv1.ClusterVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "version"},
+			Status: configv1.ClusterVersionStatus{
+				Capabilities: configv1.ClusterVersionCapabilitiesStatus{
+					EnabledCapabilities: []configv1.ClusterVersionCapability{configv1.ClusterVersionCapabilityConsole},
+				},
+			},
+		},
+	)
+
+	snapshot, err := GetClusterSnapshotWithClient(context.TODO(), client.ConfigV1())
+	if err == nil {
+		t.Fatal("expected error for missing resources")
+	}
+	for _, resource := range []string{"apiservers", "featuregates"} {
+		if !strings.Contains(err.Error(), resource) {
+			t.Errorf("expected error to mention %s, got %v", resource, err)
+		}
+	}
+	if len(snapshot.Errors) != 2 {
+		t.Errorf("expected two errors, got %v", snapshot.Errors)
+	}
+	if snapshot.Infrastructure == nil || snapshot.Network == nil || snapshot.Proxy == nil {
+		t.Errorf("expected existing resources to be returned, got %#v", snapshot)
+	}
+	if snapshot.APIServer != nil || snapshot.FeatureGate != nil {
+		t.Errorf("expected missing resources to be nil, got %#v", snapshot)
+	}
+	if snapshot.Capabilities == nil || len(snapshot.Capabilities.EnabledCapabilities) != 1 {
+		t.Errorf("expected capabilities, got %#v", snapshot.Capabilities)
+	}
+}
@@ -0,0 +1,181 @@
+package leaderelection
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coordinationv1client "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/ptr"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// podNameEnv and podNamespaceEnv are set from the downward API.
+const (
+	podNameEnv      = "POD_NAME"
+	podNamespaceEnv = "POD_NAMESPACE"
+)
+
+// PodIdentity returns a leader identity naming the pod, <namespace>/<name>_<uid>, so the holder of a lease can be
+// looked up, see HolderPodGuard. The uid distinguishes the processes running in the same pod over time.
+func PodIdentity(namespace, name string) string {
+	return fmt.Sprintf("%s/%s_%s", namespace, name, uuid.NewUUID())
+}
+
+// PodIdentityFromEnv returns the PodIdentity of the pod named by the POD_NAME and POD_NAMESPACE environment variables,
+// set from the downward API:
+//
+//	env:
+//	- name: POD_NAME
+//	  valueFrom:
+//	    fieldRef:
+//	      fieldPath: metadata.name
+//	- name: POD_NAMESPACE
+//	  valueFrom:
+//	    fieldRef:
+//	      fieldPath: metadata.namespace
+//
+// It returns an empty identity when they are not set.
+func PodIdentityFromEnv() string {
+	name, namespace := os.Getenv(podNameEnv), os.Getenv(podNamespaceEnv)
+	if len(name) == 0 || len(namespace) == 0 {
+		return ""
+	}
+	return PodIdentity(namespace, name)
+}
+
+// ParsePodIdentity returns the namespace and name of the pod of an identity returned by PodIdentity.
+func ParsePodIdentity(identity string) (string, string, bool) {
+	namespace, rest, ok := strings.Cut(identity, "/")
+	if !ok || len(namespace) == 0 {
+		return "", "", false
+	}
+	name, _, ok := strings.Cut(rest, "_")
+	if !ok || len(name) == 0 {
+		return "", "", false
+	}
+	return namespace, name, true
+}
+
+// HolderPodGonePolicy tells what the HolderPodGuard does with a lease held by a pod which is gone.
+type HolderPodGonePolicy string
+
+const (
+	// ReleaseLease clears the holder of the lease, so any candidate acquires it on its next try.
+	ReleaseLease HolderPodGonePolicy = "Release"
+	// TakeOverLease makes the guarding process the holder of the lease.
+	TakeOverLease HolderPodGonePolicy = "TakeOver"
+)
+
+// HolderPodGuard frees the lease of a leader election when its holder is a pod which no longer runs, e.g. after the
+// node of the pod crashed and the pod was deleted, instead of waiting for the lease to expire. It only acts on the
+// holders with a PodIdentity, and must run in every candidate with the pod identity of the candidate.
+type HolderPodGuard struct {
+	leases    coordinationv1client.LeasesGetter
+	pods      corev1client.PodsGetter
+	namespace string
+	name      string
+	identity  string
+	policy    HolderPodGonePolicy
+
+	clock clock.PassiveClock
+}
+
+// NewHolderPodGuard returns a guard of the lease named by config, for the candidate with the identity.
+func NewHolderPodGuard(leases coordinationv1client.LeasesGetter, pods corev1client.PodsGetter, config configv1.LeaderElection, identity string, policy HolderPodGonePolicy) (*HolderPodGuard, error) {
+	if len(config.Namespace) == 0 {
+		return nil, fmt.Errorf("namespace may not be empty")
+	}
+	if len(config.Name) == 0 {
+		return nil, fmt.Errorf("name may not be empty")
+	}
+	if _, _, ok := ParsePodIdentity(identity); !ok {
+		return nil, fmt.Errorf("identity %q is not a pod identity", identity)
+	}
+	if policy != ReleaseLease && policy != TakeOverLease {
+		return nil, fmt.Errorf("unknown holder pod gone policy %q", policy)
+	}
+	return &HolderPodGuard{
+		leases:    leases,
+		pods:      pods,
+		namespace: config.Namespace,
+		name:      config.Name,
+		identity:  identity,
+		policy:    policy,
+		clock:     clock.RealClock{},
+	}, nil
+}
+
+// Run checks the holder of the lease every interval, usually the retry period of the leader election, until the
+// context is done.
+func (g *HolderPodGuard) Run(ctx context.Context, interval time.Duration) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := g.check(ctx); err != nil {
+			klog.Warningf("failed to check the holder of lease %s/%s: %v", g.namespace, g.name, err)
+		}
+	}, interval)
+}
+
+func (g *HolderPodGuard) check(ctx context.Context) error {
+	lease, err := g.leases.Leases(g.namespace).Get(ctx, g.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	holder := ptr.Deref(lease.Spec.HolderIdentity, "")
+	if len(holder) == 0 || holder == g.identity {
+		return nil
+	}
+	namespace, name, ok := ParsePodIdentity(holder)
+	if !ok {
+		return nil
+	}
+	gone, err := g.podGone(ctx, namespace, name)
+	if err != nil || !gone {
+		return err
+	}
+
+	switch g.policy {
+	case ReleaseLease:
+		lease.Spec.HolderIdentity = nil
+		lease.Spec.AcquireTime = nil
+		lease.Spec.RenewTime = nil
+	case TakeOverLease:
+		now := metav1.NewMicroTime(g.clock.Now())
+		lease.Spec.HolderIdentity = ptr.To(g.identity)
+		lease.Spec.AcquireTime = &now
+		lease.Spec.RenewTime = &now
+		lease.Spec.LeaseTransitions = ptr.To(ptr.Deref(lease.Spec.LeaseTransitions, 0) + 1)
+	}
+	// the resource version makes the update fail when the lease changed meanwhile, e.g. it was renewed
+	if _, err := g.leases.Leases(g.namespace).Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	klog.Infof("The pod %s/%s holding lease %s/%s is gone, applied the %s policy", namespace, name, g.namespace, g.name, g.policy)
+	return nil
+}
+
+// podGone returns true when the pod does not exist or will not run anymore.
+func (g *HolderPodGuard) podGone(ctx context.Context, namespace, name string) (bool, error) {
+	pod, err := g.pods.Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded, nil
+}
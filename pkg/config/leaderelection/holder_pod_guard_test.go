@@ -0,0 +1,116 @@
+package leaderelection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func TestParsePodIdentity(t *testing.T) {
+	namespace, name, ok := ParsePodIdentity(PodIdentity("openshift-foo", "foo-operator-7d9c5-x2x4v"))
+	if !ok || namespace != "openshift-foo" || name != "foo-operator-7d9c5-x2x4v" {
+		t.Errorf("unexpected pod %q/%q, %v", namespace, name, ok)
+	}
+	for _, identity := range []string{"", "host_uid", "/name_uid", "ns/name", "ns/_uid"} {
+		if _, _, ok := ParsePodIdentity(identity); ok {
+			t.Errorf("expected %q not to be a pod identity", identity)
+		}
+	}
+}
+
+func TestHolderPodGuard(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	config := configv1.LeaderElection{Namespace: "ns", Name: "lock"}
+	self := "ns/self_uid"
+	pod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name}, Status: corev1.PodStatus{Phase: phase}}
+	}
+
+	tests := []struct {
+		name           string
+		holder         string
+		pods           []*corev1.Pod
+		policy         HolderPodGonePolicy
+		expectedHolder string
+	}{
+		{
+			name:           "running holder is kept",
+			holder:         "ns/leader_uid",
+			pods:           []*corev1.Pod{pod("leader", corev1.PodRunning)},
+			policy:         TakeOverLease,
+			expectedHolder: "ns/leader_uid",
+		},
+		{
+			name:           "holder without a pod identity is kept",
+			holder:         "host_uid",
+			policy:         TakeOverLease,
+			expectedHolder: "host_uid",
+		},
+		{
+			name:   "lease of a deleted pod is released",
+			holder: "ns/leader_uid",
+			policy: ReleaseLease,
+		},
+		{
+			name:           "lease of a deleted pod is taken over",
+			holder:         "ns/leader_uid",
+			policy:         TakeOverLease,
+			expectedHolder: self,
+		},
+		{
+			name:           "lease of a failed pod is taken over",
+			holder:         "ns/leader_uid",
+			pods:           []*corev1.Pod{pod("leader", corev1.PodFailed)},
+			policy:         TakeOverLease,
+			expectedHolder: self,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renewTime := metav1.NewMicroTime(now.Add(-time.Second))
+			kubeClient := fake.NewSimpleClientset(&coordinationv1.Lease{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "lock"},
+				Spec: coordinationv1.LeaseSpec{
+					HolderIdentity:       ptr.To(tt.holder),
+					LeaseDurationSeconds: ptr.To[int32](137),
+					RenewTime:            &renewTime,
+					LeaseTransitions:     ptr.To[int32](3),
+				},
+			})
+			for _, p := range tt.pods {
+				if _, err := kubeClient.CoreV1().Pods(p.Namespace).Create(context.TODO(), p, metav1.CreateOptions{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			guard, err := NewHolderPodGuard(kubeClient.CoordinationV1(), kubeClient.CoreV1(), config, self, tt.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			guard.clock = clocktesting.NewFakePassiveClock(now)
+
+			if err := guard.check(context.TODO()); err != nil {
+				t.Fatal(err)
+			}
+
+			lease, err := kubeClient.CoordinationV1().Leases("ns").Get(context.TODO(), "lock", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if holder := ptr.Deref(lease.Spec.HolderIdentity, ""); holder != tt.expectedHolder {
+				t.Errorf("expected holder %q, got %q", tt.expectedHolder, holder)
+			}
+			if tt.expectedHolder == self && (!lease.Spec.RenewTime.Time.Equal(now) || *lease.Spec.LeaseTransitions != 4) {
+				t.Errorf("expected the lease to be acquired now, got %#v", lease.Spec)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package leaderelection
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+)
+
+// LeaderStatus describes the leader observed through a leader election lock.
+type LeaderStatus struct {
+	// Identity is the identity of the current leader. Empty when no leader has been observed yet.
+	Identity string
+	// Since is the time the current leader acquired the lock, or the time the leader was first observed
+	// when the acquire time cannot be read from the lock.
+	Since time.Time
+	// IsSelf is true when the current leader is this process.
+	IsSelf bool
+}
+
+// LeaderChangeHandler is notified about every observed leadership change.
+type LeaderChangeHandler func(LeaderStatus)
+
+// LeaderObserver tracks who holds a leader election lock. It is fed by the leader election callbacks, so
+// every replica, the leader as well as standby replicas, see the same leadership transitions.
+type LeaderObserver struct {
+	lock  resourcelock.Interface
+	clock clock.PassiveClock
+
+	statusLock sync.RWMutex
+	status     LeaderStatus
+	handlers   []LeaderChangeHandler
+}
+
+// NewLeaderObserver returns an observer for the given lock. The identity of this process is taken from the lock.
+func NewLeaderObserver(lock resourcelock.Interface) *LeaderObserver {
+	return &LeaderObserver{
+		lock:  lock,
+		clock: clock.RealClock{},
+	}
+}
+
+// AddHandler registers a handler that is called on every observed leadership change. If a leader is already
+// known, the handler is called immediately with the current status.
+func (o *LeaderObserver) AddHandler(handler LeaderChangeHandler) {
+	o.statusLock.Lock()
+	o.handlers = append(o.handlers, handler)
+	status := o.status
+	o.statusLock.Unlock()
+
+	if len(status.Identity) > 0 {
+		handler(status)
+	}
+}
+
+// Status returns the last observed leader.
+func (o *LeaderObserver) Status() LeaderStatus {
+	o.statusLock.RLock()
+	defer o.statusLock.RUnlock()
+	return o.status
+}
+
+// IsLeader returns true when this process was last observed as the leader.
+func (o *LeaderObserver) IsLeader() bool {
+	return o.Status().IsSelf
+}
+
+// OnNewLeader records the new leader and notifies handlers. It is meant to be used as leaderelection.LeaderCallbacks.OnNewLeader.
+func (o *LeaderObserver) OnNewLeader(identity string) {
+	status := LeaderStatus{
+		Identity: identity,
+		Since:    o.clock.Now(),
+		IsSelf:   identity == o.lock.Identity(),
+	}
+	if acquireTime, ok := o.acquireTime(identity); ok {
+		status.Since = acquireTime
+	}
+
+	o.statusLock.Lock()
+	if o.status.Identity == status.Identity {
+		o.statusLock.Unlock()
+		return
+	}
+	o.status = status
+	handlers := append([]LeaderChangeHandler{}, o.handlers...)
+	o.statusLock.Unlock()
+
+	klog.Infof("observed new leader %q for %s since %v", status.Identity, o.lock.Describe(), status.Since)
+	for _, handler := range handlers {
+		handler(status)
+	}
+}
+
+// WrapCallbacks returns callbacks that feed the observer while keeping the existing OnNewLeader callback, if any.
+func (o *LeaderObserver) WrapCallbacks(callbacks leaderelection.LeaderCallbacks) leaderelection.LeaderCallbacks {
+	originalOnNewLeader := callbacks.OnNewLeader
+	callbacks.OnNewLeader = func(identity string) {
+		o.OnNewLeader(identity)
+		if originalOnNewLeader != nil {
+			originalOnNewLeader(identity)
+		}
+	}
+	return callbacks
+}
+
+// acquireTime reads the time the given identity acquired the lock. Lease locks cache the last read lease which
+// the leader elector relies on, so the record is read through a copy of the lock that does not share that state.
+func (o *LeaderObserver) acquireTime(identity string) (time.Time, bool) {
+	leaseLock, ok := o.lock.(*resourcelock.LeaseLock)
+	if !ok {
+		return time.Time{}, false
+	}
+	readOnlyLock := &resourcelock.LeaseLock{
+		LeaseMeta: leaseLock.LeaseMeta,
+		Client:    leaseLock.Client,
+	}
+	record, _, err := readOnlyLock.Get(context.TODO())
+	if err != nil {
+		klog.V(4).Infof("unable to read leader election record for %s: %v", o.lock.Describe(), err)
+		return time.Time{}, false
+	}
+	if record.HolderIdentity != identity || record.AcquireTime.IsZero() {
+		return time.Time{}, false
+	}
+	return record.AcquireTime.Time, true
+}
@@ -0,0 +1,74 @@
+package leaderelection
+
+import (
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/utils/ptr"
+)
+
+func TestLeaderObserver(t *testing.T) {
+	acquireTime := metav1.NewMicroTime(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	kubeClient := fake.NewSimpleClientset(&coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "lock"},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity: ptr.To("other"),
+			AcquireTime:    &acquireTime,
+		},
+	})
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta:  metav1.ObjectMeta{Namespace: "ns", Name: "lock"},
+		Client:     kubeClient.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: "self"},
+	}
+
+	observer := NewLeaderObserver(lock)
+	var observed []LeaderStatus
+	observer.AddHandler(func(status LeaderStatus) {
+		observed = append(observed, status)
+	})
+
+	originalCalled := 0
+	callbacks := observer.WrapCallbacks(leaderelection.LeaderCallbacks{
+		OnNewLeader: func(identity string) { originalCalled++ },
+	})
+
+	callbacks.OnNewLeader("other")
+	if status := observer.Status(); status.Identity != "other" || status.IsSelf || !status.Since.Equal(acquireTime.Time) {
+		t.Errorf("unexpected status: %#v", status)
+	}
+	if observer.IsLeader() {
+		t.Errorf("expected not to be leader")
+	}
+
+	// same leader again does not notify
+	callbacks.OnNewLeader("other")
+	callbacks.OnNewLeader("self")
+	if !observer.IsLeader() {
+		t.Errorf("expected to be leader")
+	}
+	if status := observer.Status(); status.Since.Equal(acquireTime.Time) {
+		t.Errorf("expected acquire time of a different holder not to be used, got %v", status.Since)
+	}
+
+	if len(observed) != 2 || observed[0].Identity != "other" || observed[1].Identity != "self" {
+		t.Errorf("unexpected notifications: %#v", observed)
+	}
+	if originalCalled != 3 {
+		t.Errorf("expected original callback to be called 3 times, got %d", originalCalled)
+	}
+
+	// late handlers are called with the current status
+	var late []LeaderStatus
+	observer.AddHandler(func(status LeaderStatus) {
+		late = append(late, status)
+	})
+	if len(late) != 1 || late[0].Identity != "self" {
+		t.Errorf("unexpected notifications: %#v", late)
+	}
+}
@@ -0,0 +1,420 @@
+package leaderelection
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coordinationv1client "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/ptr"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// shardedLockLabel is set on the member leases of a sharded lock, its value is the name of the lock.
+const shardedLockLabel = "leaderelection.openshift.io/sharded-lock"
+
+// ShardsChangedFunc is called with the sorted list of shards owned by this process whenever it changes. It must stop
+// the work on shards no longer listed before it returns: their leases are only released to other members afterwards.
+type ShardsChangedFunc func(ownedShards []int)
+
+// ShardedLeaderElection elects a leader for each of N shards of work. Every shard is guarded by its own lease, named
+// <name>-shard-<index>. Every process also maintains a member lease so that all members know who is alive, and the
+// shards are spread across the live members using rendezvous hashing. When members come and go, shards are released
+// by members that no longer should own them and picked up by their new owners.
+type ShardedLeaderElection struct {
+	client    coordinationv1client.LeasesGetter
+	namespace string
+	name      string
+	identity  string
+	shards    int
+
+	leaseDuration time.Duration
+	renewDeadline time.Duration
+	retryPeriod   time.Duration
+
+	clock clock.Clock
+
+	lock      sync.RWMutex
+	owned     sets.Set[int]
+	lastRenew map[int]time.Time
+	handlers  []ShardsChangedFunc
+
+	// observed keeps, per lease name, the last lease spec seen and when it was seen on the local clock. Like client-go
+	// leader election, leases expire relative to when they last changed locally, so clock skew between members does
+	// not matter.
+	observedLock sync.Mutex
+	observed     map[string]observedLease
+}
+
+type observedLease struct {
+	spec coordinationv1.LeaseSpec
+	time time.Time
+}
+
+// NewShardedLeaderElection returns a sharded leader election using the namespace, name and durations from config.
+// Use LeaderElectionDefaulting to get reasonable durations.
+func NewShardedLeaderElection(client coordinationv1client.LeasesGetter, config configv1.LeaderElection, shards int, identity string) (*ShardedLeaderElection, error) {
+	if len(config.Namespace) == 0 {
+		return nil, fmt.Errorf("namespace may not be empty")
+	}
+	if len(config.Name) == 0 {
+		return nil, fmt.Errorf("name may not be empty")
+	}
+	if len(identity) == 0 {
+		return nil, fmt.Errorf("identity may not be empty")
+	}
+	if shards <= 0 {
+		return nil, fmt.Errorf("shards must be greater than zero")
+	}
+	if config.LeaseDuration.Duration <= config.RenewDeadline.Duration {
+		return nil, fmt.Errorf("leaseDuration must be greater than renewDeadline")
+	}
+	if config.RetryPeriod.Duration <= 0 || config.RenewDeadline.Duration <= config.RetryPeriod.Duration {
+		return nil, fmt.Errorf("renewDeadline must be greater than retryPeriod and retryPeriod must be positive")
+	}
+
+	return &ShardedLeaderElection{
+		client:        client,
+		namespace:     config.Namespace,
+		name:          config.Name,
+		identity:      identity,
+		shards:        shards,
+		leaseDuration: config.LeaseDuration.Duration,
+		renewDeadline: config.RenewDeadline.Duration,
+		retryPeriod:   config.RetryPeriod.Duration,
+		clock:         clock.RealClock{},
+		owned:         sets.New[int](),
+		lastRenew:     map[int]time.Time{},
+		observed:      map[string]observedLease{},
+	}, nil
+}
+
+// AddHandler registers a function that is called whenever the set of owned shards changes.
+func (s *ShardedLeaderElection) AddHandler(handler ShardsChangedFunc) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.handlers = append(s.handlers, handler)
+}
+
+// OwnedShards returns the sorted list of shards currently owned by this process.
+func (s *ShardedLeaderElection) OwnedShards() []int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return sets.List(s.owned)
+}
+
+// Owns returns true if the shard is currently owned by this process.
+func (s *ShardedLeaderElection) Owns(shard int) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.owned.Has(shard)
+}
+
+// Run participates in the election until the context is done. On exit, all owned shards and the member lease are released.
+func (s *ShardedLeaderElection) Run(ctx context.Context) {
+	wait.UntilWithContext(ctx, s.sync, s.retryPeriod)
+
+	// use a fresh context, the passed one is already done
+	releaseCtx, cancel := context.WithTimeout(context.Background(), s.renewDeadline)
+	defer cancel()
+	s.release(releaseCtx)
+}
+
+func (s *ShardedLeaderElection) sync(ctx context.Context) {
+	if err := s.renewMember(ctx); err != nil {
+		klog.Warningf("failed to renew member lease for sharded lock %s/%s: %v", s.namespace, s.name, err)
+	}
+	members, err := s.liveMembers(ctx)
+	if err != nil {
+		klog.Warningf("failed to list members of sharded lock %s/%s: %v", s.namespace, s.name, err)
+		// without knowing the members, keep what we have, but do not acquire anything new
+		members = nil
+	}
+
+	owned := sets.New[int]()
+	var handovers []*coordinationv1.Lease
+	for shard := 0; shard < s.shards; shard++ {
+		preferred := len(members) == 0 || preferredMember(members, shard) == s.identity
+		held, handover, err := s.syncShard(ctx, shard, preferred, len(members) > 0)
+		if err != nil {
+			klog.V(2).Infof("failed to sync shard %d of sharded lock %s/%s: %v", shard, s.namespace, s.name, err)
+		}
+		if held {
+			owned.Insert(shard)
+		}
+		if handover != nil {
+			handovers = append(handovers, handover)
+		}
+	}
+	// the handlers stop working on the shards handed over before their leases are released
+	s.setOwned(owned)
+	for _, lease := range handovers {
+		if err := s.releaseLease(ctx, lease); err != nil {
+			klog.V(2).Infof("failed to release %s of sharded lock %s/%s for rebalancing: %v", lease.Name, s.namespace, s.name, err)
+			continue
+		}
+		klog.Infof("released %s of sharded lock %s/%s for rebalancing", lease.Name, s.namespace, s.name)
+	}
+}
+
+// syncShard acquires or renews a single shard and returns whether it is held afterwards. A shard held by this process
+// that another live member should own is not held anymore, its lease is returned to be released once the handlers
+// stopped working on it.
+func (s *ShardedLeaderElection) syncShard(ctx context.Context, shard int, preferred, membersKnown bool) (bool, *coordinationv1.Lease, error) {
+	now := s.clock.Now()
+	name := s.shardLeaseName(shard)
+	lease, err := s.client.Leases(s.namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if !preferred || !membersKnown {
+			return false, nil, nil
+		}
+		_, err := s.client.Leases(s.namespace).Create(ctx, &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Namespace: s.namespace, Name: name},
+			Spec:       s.heldLeaseSpec(now, nil),
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return false, nil, err
+		}
+		s.renewed(shard, now)
+		return true, nil, nil
+	}
+	if err != nil {
+		return s.stillHeld(shard, now), nil, err
+	}
+	s.observe(lease, now)
+
+	holder := ptr.Deref(lease.Spec.HolderIdentity, "")
+	switch {
+	case holder == s.identity && (preferred || !membersKnown):
+		lease.Spec.RenewTime = ptr.To(metav1.NewMicroTime(now))
+		if _, err := s.client.Leases(s.namespace).Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+			if apierrors.IsConflict(err) {
+				return false, nil, err
+			}
+			return s.stillHeld(shard, now), nil, err
+		}
+		s.renewed(shard, now)
+		return true, nil, nil
+
+	case holder == s.identity:
+		// another live member should own this shard, hand it over
+		return false, lease, nil
+
+	case preferred && membersKnown && (len(holder) == 0 || s.leaseExpired(lease, now)):
+		lease.Spec = s.heldLeaseSpec(now, &lease.Spec)
+		if _, err := s.client.Leases(s.namespace).Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+			return false, nil, err
+		}
+		s.renewed(shard, now)
+		klog.Infof("acquired shard %d of sharded lock %s/%s", shard, s.namespace, s.name)
+		return true, nil, nil
+	}
+	return false, nil, nil
+}
+
+// releaseLease clears the holder of a shard lease held by this process, so that other members can acquire it.
+func (s *ShardedLeaderElection) releaseLease(ctx context.Context, lease *coordinationv1.Lease) error {
+	lease.Spec.HolderIdentity = nil
+	lease.Spec.RenewTime = nil
+	lease.Spec.AcquireTime = nil
+	_, err := s.client.Leases(s.namespace).Update(ctx, lease, metav1.UpdateOptions{})
+	return err
+}
+
+func (s *ShardedLeaderElection) heldLeaseSpec(now time.Time, previous *coordinationv1.LeaseSpec) coordinationv1.LeaseSpec {
+	transitions := int32(0)
+	if previous != nil {
+		transitions = ptr.Deref(previous.LeaseTransitions, 0)
+		if len(ptr.Deref(previous.HolderIdentity, "")) > 0 {
+			transitions++
+		}
+	}
+	return coordinationv1.LeaseSpec{
+		HolderIdentity:       ptr.To(s.identity),
+		LeaseDurationSeconds: ptr.To(int32(s.leaseDuration / time.Second)),
+		AcquireTime:          ptr.To(metav1.NewMicroTime(now)),
+		RenewTime:            ptr.To(metav1.NewMicroTime(now)),
+		LeaseTransitions:     ptr.To(transitions),
+	}
+}
+
+// stillHeld returns whether a shard we could not renew is still considered ours, that is until the renew deadline passes.
+func (s *ShardedLeaderElection) stillHeld(shard int, now time.Time) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	lastRenew, ok := s.lastRenew[shard]
+	return ok && s.owned.Has(shard) && now.Sub(lastRenew) < s.renewDeadline
+}
+
+func (s *ShardedLeaderElection) renewed(shard int, now time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.lastRenew[shard] = now
+}
+
+func (s *ShardedLeaderElection) setOwned(owned sets.Set[int]) {
+	s.lock.Lock()
+	if s.owned.Equal(owned) {
+		s.lock.Unlock()
+		return
+	}
+	s.owned = owned
+	for shard := range s.lastRenew {
+		if !owned.Has(shard) {
+			delete(s.lastRenew, shard)
+		}
+	}
+	handlers := append([]ShardsChangedFunc{}, s.handlers...)
+	ownedList := sets.List(owned)
+	s.lock.Unlock()
+
+	klog.Infof("sharded lock %s/%s: %q now owns shards %v", s.namespace, s.name, s.identity, ownedList)
+	for _, handler := range handlers {
+		handler(ownedList)
+	}
+}
+
+func (s *ShardedLeaderElection) renewMember(ctx context.Context) error {
+	now := s.clock.Now()
+	name := s.memberLeaseName()
+	lease, err := s.client.Leases(s.namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err := s.client.Leases(s.namespace).Create(ctx, &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: s.namespace,
+				Name:      name,
+				Labels:    map[string]string{shardedLockLabel: s.name},
+			},
+			Spec: s.heldLeaseSpec(now, nil),
+		}, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	lease.Spec.HolderIdentity = ptr.To(s.identity)
+	lease.Spec.RenewTime = ptr.To(metav1.NewMicroTime(now))
+	_, err = s.client.Leases(s.namespace).Update(ctx, lease, metav1.UpdateOptions{})
+	return err
+}
+
+// liveMembers returns the sorted identities of all members with a non-expired member lease.
+func (s *ShardedLeaderElection) liveMembers(ctx context.Context) ([]string, error) {
+	leases, err := s.client.Leases(s.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{shardedLockLabel: s.name}).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	members := sets.New[string]()
+	listed := sets.New[string]()
+	for i := range leases.Items {
+		lease := &leases.Items[i]
+		listed.Insert(lease.Name)
+		s.observe(lease, now)
+		holder := ptr.Deref(lease.Spec.HolderIdentity, "")
+		if len(holder) == 0 || s.leaseExpired(lease, now) {
+			continue
+		}
+		members.Insert(holder)
+	}
+	s.forgetMembersExcept(listed)
+	return sets.List(members), nil
+}
+
+func (s *ShardedLeaderElection) release(ctx context.Context) {
+	// the handlers stop working on all shards before their leases are released
+	owned := s.OwnedShards()
+	s.setOwned(sets.New[int]())
+	for _, shard := range owned {
+		lease, err := s.client.Leases(s.namespace).Get(ctx, s.shardLeaseName(shard), metav1.GetOptions{})
+		if err != nil || ptr.Deref(lease.Spec.HolderIdentity, "") != s.identity {
+			continue
+		}
+		if err := s.releaseLease(ctx, lease); err != nil {
+			klog.Warningf("failed to release shard %d of sharded lock %s/%s: %v", shard, s.namespace, s.name, err)
+		}
+	}
+	if err := s.client.Leases(s.namespace).Delete(ctx, s.memberLeaseName(), metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		klog.Warningf("failed to remove member lease for sharded lock %s/%s: %v", s.namespace, s.name, err)
+	}
+}
+
+func (s *ShardedLeaderElection) shardLeaseName(shard int) string {
+	return fmt.Sprintf("%s-shard-%d", s.name, shard)
+}
+
+// memberLeaseName uses a hash of the identity, identities usually contain characters not allowed in names.
+func (s *ShardedLeaderElection) memberLeaseName() string {
+	hash := sha256.Sum256([]byte(s.identity))
+	return fmt.Sprintf("%s-member-%s", s.name, hex.EncodeToString(hash[:])[:16])
+}
+
+// observe records when the lease was seen to change on the local clock.
+func (s *ShardedLeaderElection) observe(lease *coordinationv1.Lease, now time.Time) {
+	s.observedLock.Lock()
+	defer s.observedLock.Unlock()
+	if observed, ok := s.observed[lease.Name]; ok && equality.Semantic.DeepEqual(observed.spec, lease.Spec) {
+		return
+	}
+	s.observed[lease.Name] = observedLease{spec: *lease.Spec.DeepCopy(), time: now}
+}
+
+// leaseExpired returns true when the lease has not changed for the lease duration, measured on the local clock since
+// it was observed to change. The renew time written by the holder is not compared to the local clock.
+func (s *ShardedLeaderElection) leaseExpired(lease *coordinationv1.Lease, now time.Time) bool {
+	s.observedLock.Lock()
+	defer s.observedLock.Unlock()
+	observed, ok := s.observed[lease.Name]
+	if !ok {
+		return false
+	}
+	return observed.time.Add(s.leaseDuration).Before(now)
+}
+
+// forgetMembersExcept drops the observations of member leases that no longer exist.
+func (s *ShardedLeaderElection) forgetMembersExcept(listed sets.Set[string]) {
+	s.observedLock.Lock()
+	defer s.observedLock.Unlock()
+	prefix := s.name + "-member-"
+	for name := range s.observed {
+		if strings.HasPrefix(name, prefix) && !listed.Has(name) {
+			delete(s.observed, name)
+		}
+	}
+}
+
+// preferredMember picks the owner of a shard using rendezvous hashing, so that only the shards of members that
+// come or go move to a different member.
+func preferredMember(members []string, shard int) string {
+	sorted := append([]string{}, members...)
+	sort.Strings(sorted)
+
+	var preferred string
+	var highest uint64
+	for _, member := range sorted {
+		hash := sha256.Sum256([]byte(fmt.Sprintf("%s/%d", member, shard)))
+		weight := binary.BigEndian.Uint64(hash[:8])
+		if len(preferred) == 0 || weight > highest {
+			preferred, highest = member, weight
+		}
+	}
+	return preferred
+}
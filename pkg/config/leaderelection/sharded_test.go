@@ -0,0 +1,178 @@
+package leaderelection
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func TestShardedLeaderElection(t *testing.T) {
+	ctx := context.Background()
+	kubeClient := fake.NewSimpleClientset()
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	config := configv1.LeaderElection{
+		Namespace:     "ns",
+		Name:          "lock",
+		LeaseDuration: metav1.Duration{Duration: 60 * time.Second},
+		RenewDeadline: metav1.Duration{Duration: 40 * time.Second},
+		RetryPeriod:   metav1.Duration{Duration: 10 * time.Second},
+	}
+	const shards = 8
+
+	newMember := func(identity string) *ShardedLeaderElection {
+		s, err := NewShardedLeaderElection(kubeClient.CoordinationV1(), config, shards, identity)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.clock = fakeClock
+		return s
+	}
+
+	a := newMember("a")
+	var notified [][]int
+	previous := sets.New[int]()
+	a.AddHandler(func(owned []int) {
+		// the shards no longer owned must still be leased while the handler stops working on them
+		for _, shard := range sets.List(previous.Difference(sets.New(owned...))) {
+			lease, err := kubeClient.CoordinationV1().Leases("ns").Get(ctx, a.shardLeaseName(shard), metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if holder := ptr.Deref(lease.Spec.HolderIdentity, ""); holder != "a" {
+				t.Errorf("expected shard %d to be held by a until the handler returns, held by %q", shard, holder)
+			}
+		}
+		notified = append(notified, owned)
+		previous = sets.New(owned...)
+	})
+
+	// a single member owns everything
+	a.sync(ctx)
+	if owned := a.OwnedShards(); len(owned) != shards {
+		t.Fatalf("expected a to own all shards, got %v", owned)
+	}
+	if len(notified) != 1 {
+		t.Errorf("expected one notification, got %v", notified)
+	}
+
+	// a second member joins, a hands over the shards b prefers and b picks them up
+	b := newMember("b")
+	b.sync(ctx)
+	if owned := b.OwnedShards(); len(owned) != 0 {
+		t.Errorf("expected b not to own anything while a holds all shards, got %v", owned)
+	}
+	fakeClock.Step(10 * time.Second)
+	a.sync(ctx)
+	b.sync(ctx)
+	assertPartition(t, shards, a, b)
+	for _, s := range []*ShardedLeaderElection{a, b} {
+		for _, shard := range s.OwnedShards() {
+			if preferred := preferredMember([]string{"a", "b"}, shard); preferred != s.identity {
+				t.Errorf("expected shard %d to be owned by %s, owned by %s", shard, preferred, s.identity)
+			}
+		}
+	}
+
+	// b goes away without releasing, a takes over after b's leases expire
+	fakeClock.Step(30 * time.Second)
+	a.sync(ctx)
+	if owned := a.OwnedShards(); len(owned) == shards {
+		t.Errorf("expected a not to take over before b's leases expire")
+	}
+	fakeClock.Step(61 * time.Second)
+	a.sync(ctx)
+	if owned := a.OwnedShards(); len(owned) != shards {
+		t.Errorf("expected a to own all shards after b's leases expired, got %v", owned)
+	}
+
+	// releasing gives everything up
+	a.release(ctx)
+	if owned := a.OwnedShards(); len(owned) != 0 {
+		t.Errorf("expected a to own nothing after release, got %v", owned)
+	}
+	c := newMember("c")
+	c.sync(ctx)
+	if owned := c.OwnedShards(); len(owned) == 0 {
+		t.Errorf("expected c to pick up the released shards it prefers")
+	}
+	// c has just seen the stale member lease of b, b counts as live until c sees it unchanged for the lease duration
+	fakeClock.Step(61 * time.Second)
+	c.sync(ctx)
+	if owned := c.OwnedShards(); len(owned) != shards {
+		t.Errorf("expected c to own all shards after a released them, got %v", owned)
+	}
+}
+
+func TestShardedLeaderElectionClockSkew(t *testing.T) {
+	ctx := context.Background()
+	kubeClient := fake.NewSimpleClientset()
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	config := configv1.LeaderElection{
+		Namespace:     "ns",
+		Name:          "lock",
+		LeaseDuration: metav1.Duration{Duration: 60 * time.Second},
+		RenewDeadline: metav1.Duration{Duration: 40 * time.Second},
+		RetryPeriod:   metav1.Duration{Duration: 10 * time.Second},
+	}
+
+	a, err := NewShardedLeaderElection(kubeClient.CoordinationV1(), config, 1, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.clock = fakeClock
+	b, err := NewShardedLeaderElection(kubeClient.CoordinationV1(), config, 1, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the clock of b is an hour behind, its renew times look long expired to a
+	b.clock = clocktesting.NewFakeClock(fakeClock.Now().Add(-time.Hour))
+
+	b.sync(ctx)
+	if owned := b.OwnedShards(); len(owned) != 1 {
+		t.Fatalf("expected b to own the shard, got %v", owned)
+	}
+	a.sync(ctx)
+	if owned := a.OwnedShards(); len(owned) != 0 {
+		t.Errorf("expected a not to take over the shard b keeps renewing, got %v", owned)
+	}
+
+	// b stops renewing, a takes over once it has not seen the leases change for the lease duration
+	fakeClock.Step(30 * time.Second)
+	a.sync(ctx)
+	if owned := a.OwnedShards(); len(owned) != 0 {
+		t.Errorf("expected a not to take over before the lease duration passed, got %v", owned)
+	}
+	fakeClock.Step(31 * time.Second)
+	a.sync(ctx)
+	if owned := a.OwnedShards(); len(owned) != 1 {
+		t.Errorf("expected a to take over after the lease duration passed, got %v", owned)
+	}
+}
+
+func assertPartition(t *testing.T, shards int, members ...*ShardedLeaderElection) {
+	t.Helper()
+	all := sets.New[int]()
+	for _, member := range members {
+		owned := member.OwnedShards()
+		if all.HasAny(owned...) {
+			t.Errorf("shards %v owned by more than one member", sets.List(all.Intersection(sets.New(owned...))))
+		}
+		all.Insert(owned...)
+	}
+	expected := make([]int, 0, shards)
+	for i := 0; i < shards; i++ {
+		expected = append(expected, i)
+	}
+	if !reflect.DeepEqual(sets.List(all), expected) {
+		t.Errorf("expected all shards to be owned, got %v", sets.List(all))
+	}
+}
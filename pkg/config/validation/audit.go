@@ -0,0 +1,52 @@
+package validation
+
+import (
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// ValidateAuditConfig validates an enabled audit config: its files, its limits, its log format and its webhook.
+func ValidateAuditConfig(config configv1.AuditConfig, fldPath *field.Path) ValidationResults {
+	validationResults := ValidationResults{}
+	if !config.Enabled {
+		return validationResults
+	}
+
+	if len(config.AuditFilePath) == 0 && len(config.WebHookKubeConfig) == 0 {
+		validationResults.AddErrors(field.Required(fldPath.Child("auditFilePath"), "an audit file path or a webhook kubeconfig is required to write the audit entries"))
+	}
+	if config.MaximumFileRetentionDays < 0 {
+		validationResults.AddErrors(field.Invalid(fldPath.Child("maximumFileRetentionDays"), config.MaximumFileRetentionDays, "must be zero (no limit) or greater"))
+	}
+	if config.MaximumRetainedFiles < 0 {
+		validationResults.AddErrors(field.Invalid(fldPath.Child("maximumRetainedFiles"), config.MaximumRetainedFiles, "must be zero (no limit) or greater"))
+	}
+	if config.MaximumFileSizeMegabytes < 0 {
+		validationResults.AddErrors(field.Invalid(fldPath.Child("maximumFileSizeMegabytes"), config.MaximumFileSizeMegabytes, "must be zero (no limit) or greater"))
+	}
+
+	if len(config.PolicyFile) > 0 {
+		validationResults.AddErrors(ValidateFile(config.PolicyFile, fldPath.Child("policyFile"))...)
+		if len(config.PolicyConfiguration.Raw) > 0 {
+			validationResults.AddErrors(field.Invalid(fldPath.Child("policyConfiguration"), "", "cannot be set with a policyFile"))
+		}
+	}
+
+	switch config.LogFormat {
+	case "", configv1.LogFormatLegacy, configv1.LogFormatJson:
+	default:
+		validationResults.AddErrors(field.NotSupported(fldPath.Child("logFormat"), config.LogFormat, []string{string(configv1.LogFormatLegacy), string(configv1.LogFormatJson)}))
+	}
+
+	if len(config.WebHookKubeConfig) > 0 {
+		validationResults.AddErrors(ValidateFile(config.WebHookKubeConfig, fldPath.Child("webHookKubeConfig"))...)
+	}
+	switch config.WebHookMode {
+	case "", configv1.WebHookModeBatch, configv1.WebHookModeBlocking:
+	default:
+		validationResults.AddErrors(field.NotSupported(fldPath.Child("webHookMode"), config.WebHookMode, []string{string(configv1.WebHookModeBatch), string(configv1.WebHookModeBlocking)}))
+	}
+
+	return validationResults
+}
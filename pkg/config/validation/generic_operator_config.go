@@ -0,0 +1,46 @@
+package validation
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
+)
+
+// ValidateGenericOperatorConfig validates the serving info and the leader election of the config of an operator, as
+// read and defaulted by controllercmd. The serving certificate must exist and parse, and the bind address must have a
+// valid port and an IP address or DNS name as host, which ValidateHostPort does not check.
+func ValidateGenericOperatorConfig(config *operatorv1alpha1.GenericOperatorConfig, fldPath *field.Path) ValidationResults {
+	validationResults := ValidationResults{}
+	servingInfoResults := ValidateHTTPServingInfo(config.ServingInfo, fldPath.Child("servingInfo"))
+	servingInfoResults.AddErrors(validateBindAddress(config.ServingInfo.BindAddress, fldPath.Child("servingInfo", "bindAddress"))...)
+	validationResults.Append(servingInfoResults)
+	if len(servingInfoResults.Errors) == 0 && len(config.ServingInfo.CertFile) > 0 {
+		if _, err := tls.LoadX509KeyPair(config.ServingInfo.CertFile, config.ServingInfo.KeyFile); err != nil {
+			validationResults.AddErrors(field.Invalid(fldPath.Child("servingInfo", "certFile"), config.ServingInfo.CertFile, fmt.Sprintf("could not load the certificate and key: %v", err)))
+		}
+	}
+	validationResults.Append(ValidateLeaderElection(config.LeaderElection, fldPath.Child("leaderElection")))
+	return validationResults
+}
+
+// validateBindAddress checks the port and the host of a bind address which ValidateHostPort accepted.
+func validateBindAddress(value string, fldPath *field.Path) field.ErrorList {
+	host, port, err := net.SplitHostPort(value)
+	if err != nil {
+		// reported by ValidateHostPort
+		return nil
+	}
+	allErrs := field.ErrorList{}
+	if portNumber, err := strconv.Atoi(port); err != nil || portNumber < 0 || portNumber > 65535 {
+		allErrs = append(allErrs, field.Invalid(fldPath, value, "port must be a number between 0 (any port) and 65535"))
+	} else if len(host) > 0 && net.ParseIP(host) == nil && len(utilvalidation.IsDNS1123Subdomain(host)) > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath, value, "host must be an IP address or a DNS name"))
+	}
+	return allErrs
+}
@@ -0,0 +1,179 @@
+package validation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
+	"github.com/openshift/library-go/pkg/crypto"
+)
+
+func validServingInfo(t *testing.T) configv1.HTTPServingInfo {
+	ca, err := crypto.UnsafeMakeSelfSignedCAConfigForDurationAtTime("serving", time.Now, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	if err := ca.WriteCertConfigFile(certFile, keyFile); err != nil {
+		t.Fatal(err)
+	}
+	return configv1.HTTPServingInfo{
+		ServingInfo: configv1.ServingInfo{
+			BindAddress: "0.0.0.0:8443",
+			BindNetwork: "tcp",
+			CertInfo:    configv1.CertInfo{CertFile: certFile, KeyFile: keyFile},
+		},
+	}
+}
+
+func TestValidateGenericOperatorConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		mutate    func(t *testing.T, config *operatorv1alpha1.GenericOperatorConfig)
+		expErrors []string
+	}{
+		{
+			name:   "valid",
+			mutate: func(*testing.T, *operatorv1alpha1.GenericOperatorConfig) {},
+		},
+		{
+			name: "invalid port",
+			mutate: func(_ *testing.T, config *operatorv1alpha1.GenericOperatorConfig) {
+				config.ServingInfo.BindAddress = "0.0.0.0:84430"
+			},
+			expErrors: []string{"servingInfo.bindAddress"},
+		},
+		{
+			name: "invalid host",
+			mutate: func(_ *testing.T, config *operatorv1alpha1.GenericOperatorConfig) {
+				config.ServingInfo.BindAddress = "not_a_host:8443"
+			},
+			expErrors: []string{"servingInfo.bindAddress"},
+		},
+		{
+			name: "certificate not parsing",
+			mutate: func(t *testing.T, config *operatorv1alpha1.GenericOperatorConfig) {
+				if err := os.WriteFile(config.ServingInfo.CertFile, []byte("garbage"), 0600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			expErrors: []string{"servingInfo.certFile"},
+		},
+		{
+			name: "missing key",
+			mutate: func(_ *testing.T, config *operatorv1alpha1.GenericOperatorConfig) {
+				config.ServingInfo.KeyFile = filepath.Join(filepath.Dir(config.ServingInfo.KeyFile), "missing.key")
+			},
+			expErrors: []string{"servingInfo.keyFile"},
+		},
+		{
+			name: "lease shorter than the renew deadline",
+			mutate: func(_ *testing.T, config *operatorv1alpha1.GenericOperatorConfig) {
+				config.LeaderElection.LeaseDuration = metav1.Duration{Duration: 10 * time.Second}
+			},
+			expErrors: []string{"leaderElection.leaseDuration"},
+		},
+		{
+			name: "retry period too long for the renew deadline",
+			mutate: func(_ *testing.T, config *operatorv1alpha1.GenericOperatorConfig) {
+				config.LeaderElection.RetryPeriod = metav1.Duration{Duration: 100 * time.Second}
+			},
+			expErrors: []string{"leaderElection.renewDeadline"},
+		},
+		{
+			name: "negative duration",
+			mutate: func(_ *testing.T, config *operatorv1alpha1.GenericOperatorConfig) {
+				config.LeaderElection.RetryPeriod = metav1.Duration{Duration: -time.Second}
+			},
+			expErrors: []string{"leaderElection.retryPeriod"},
+		},
+		{
+			name: "leader election disabled",
+			mutate: func(_ *testing.T, config *operatorv1alpha1.GenericOperatorConfig) {
+				config.LeaderElection.Disable = true
+				config.LeaderElection.LeaseDuration = metav1.Duration{Duration: time.Second}
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := &operatorv1alpha1.GenericOperatorConfig{
+				ServingInfo: validServingInfo(t),
+				LeaderElection: configv1.LeaderElection{
+					LeaseDuration: metav1.Duration{Duration: 137 * time.Second},
+					RenewDeadline: metav1.Duration{Duration: 107 * time.Second},
+					RetryPeriod:   metav1.Duration{Duration: 26 * time.Second},
+				},
+			}
+			test.mutate(t, config)
+
+			results := ValidateGenericOperatorConfig(config, nil)
+			var fields []string
+			for _, err := range results.Errors {
+				fields = append(fields, err.Field)
+			}
+			if len(fields) != len(test.expErrors) {
+				t.Fatalf("expected errors on %v, got %v", test.expErrors, results.Errors)
+			}
+			for i := range fields {
+				if fields[i] != test.expErrors[i] {
+					t.Errorf("expected errors on %v, got %v", test.expErrors, results.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateCertInfoDoesNotLoadCertificates(t *testing.T) {
+	servingInfo := validServingInfo(t)
+	if err := os.WriteFile(servingInfo.CertFile, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ValidateCertInfo(servingInfo.CertInfo, true, nil); len(errs) > 0 {
+		t.Errorf("expected the certificate not to be loaded, got %v", errs)
+	}
+}
+
+func TestValidateAuditConfig(t *testing.T) {
+	config := configv1.AuditConfig{
+		Enabled:                  true,
+		MaximumRetainedFiles:     -1,
+		PolicyFile:               filepath.Join(t.TempDir(), "missing-policy.yaml"),
+		LogFormat:                "xml",
+		WebHookMode:              configv1.WebHookModeBatch,
+		MaximumFileSizeMegabytes: 100,
+	}
+	results := ValidateAuditConfig(config, nil)
+	var fields []string
+	for _, err := range results.Errors {
+		fields = append(fields, err.Field)
+	}
+	expected := []string{"auditFilePath", "maximumRetainedFiles", "policyFile", "logFormat"}
+	if len(fields) != len(expected) {
+		t.Fatalf("expected errors on %v, got %v", expected, results.Errors)
+	}
+	for i := range fields {
+		if fields[i] != expected[i] {
+			t.Errorf("expected errors on %v, got %v", expected, results.Errors)
+		}
+	}
+
+	config.Enabled = false
+	if results := ValidateAuditConfig(config, nil); len(results.Errors) > 0 {
+		t.Errorf("expected a disabled audit config not to be validated, got %v", results.Errors)
+	}
+}
+
+func TestValidateHostPortIsNotStricterForExistingCallers(t *testing.T) {
+	for _, value := range []string{"0.0.0.0:84430", "not_a_host:8443", ":https"} {
+		if errs := ValidateHostPort(value, nil); len(errs) > 0 {
+			t.Errorf("expected %q to be accepted by ValidateHostPort, got %v", value, errs)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package validation
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/leaderelection"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// ValidateLeaderElection validates the durations of a leader election, the zero durations are defaulted. The leader
+// must renew its lease before the lease expires, and retry at least once before the renew deadline.
+func ValidateLeaderElection(config configv1.LeaderElection, fldPath *field.Path) ValidationResults {
+	validationResults := ValidationResults{}
+	if config.Disable {
+		return validationResults
+	}
+
+	leaseDuration, renewDeadline, retryPeriod := config.LeaseDuration.Duration, config.RenewDeadline.Duration, config.RetryPeriod.Duration
+	for _, duration := range []struct {
+		name  string
+		value time.Duration
+	}{{"leaseDuration", leaseDuration}, {"renewDeadline", renewDeadline}, {"retryPeriod", retryPeriod}} {
+		if duration.value < 0 {
+			validationResults.AddErrors(field.Invalid(fldPath.Child(duration.name), duration.value.String(), "must be zero (default) or greater"))
+		}
+	}
+	if leaseDuration > 0 && renewDeadline > 0 && leaseDuration <= renewDeadline {
+		validationResults.AddErrors(field.Invalid(fldPath.Child("leaseDuration"), leaseDuration.String(), fmt.Sprintf("must be greater than the renewDeadline %v", renewDeadline)))
+	}
+	if renewDeadline > 0 && retryPeriod > 0 && float64(renewDeadline) <= leaderelection.JitterFactor*float64(retryPeriod) {
+		validationResults.AddErrors(field.Invalid(fldPath.Child("renewDeadline"), renewDeadline.String(), fmt.Sprintf("must be greater than %v times the retryPeriod %v", leaderelection.JitterFactor, retryPeriod)))
+	}
+	return validationResults
+}
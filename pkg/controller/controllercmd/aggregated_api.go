@@ -0,0 +1,205 @@
+package controllercmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	openapinamer "k8s.io/apiserver/pkg/endpoints/openapi"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/apiserver/pkg/server/dynamiccertificates"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	openapicommon "k8s.io/kube-openapi/pkg/common"
+)
+
+// AggregatedAPI describes the API groups the server of the controller serves through the kube-aggregator. The server
+// is the one of WithServer: it uses the delegated authentication and authorization, and runs for the lifetime of the
+// controller.
+type AggregatedAPI struct {
+	// APIGroups returns the API groups to install in the server. It is called before the server starts, with the client
+	// config of the controller.
+	APIGroups func(kubeConfig *rest.Config) ([]*genericapiserver.APIGroupInfo, error)
+
+	// Scheme has the types of the API groups, it names their OpenAPI definitions.
+	Scheme *runtime.Scheme
+	// OpenAPIDefinitions returns the OpenAPI definitions of the types, usually generated by openapi-gen. The server
+	// publishes no OpenAPI spec without them.
+	OpenAPIDefinitions openapicommon.GetOpenAPIDefinitions
+	// Title is the title of the OpenAPI spec. It defaults to the component name.
+	Title string
+
+	// ServingCertSecret is the kubernetes.io/tls secret, e.g. maintained by a certrotation.RotatedSelfSignedCertKeySecret,
+	// served instead of the certificate of the serving info. The rotated certificates are served without a restart.
+	// The certificate of the serving info is served until the secret exists. Optional.
+	ServingCertSecret types.NamespacedName
+}
+
+// WithAggregatedAPI serves the API groups from the server of the controller, as a minimal aggregated API server.
+// It requires WithServer.
+func (b *ControllerBuilder) WithAggregatedAPI(api AggregatedAPI) *ControllerBuilder {
+	b.aggregatedAPI = &api
+	return b
+}
+
+// applyAggregatedAPI configures the OpenAPI and the serving certificate of the aggregated API in the server config.
+func (b *ControllerBuilder) applyAggregatedAPI(serverConfig *genericapiserver.Config, kubeClient kubernetes.Interface) error {
+	api := b.aggregatedAPI
+	if api.OpenAPIDefinitions != nil {
+		if api.Scheme == nil {
+			return fmt.Errorf("the aggregated API needs a scheme to name its OpenAPI definitions")
+		}
+		title := api.Title
+		if len(title) == 0 {
+			title = b.componentName
+		}
+		namer := openapinamer.NewDefinitionNamer(api.Scheme)
+		serverConfig.OpenAPIConfig = genericapiserver.DefaultOpenAPIConfig(api.OpenAPIDefinitions, namer)
+		serverConfig.OpenAPIConfig.Info.Title = title
+		serverConfig.OpenAPIV3Config = genericapiserver.DefaultOpenAPIV3Config(api.OpenAPIDefinitions, namer)
+		serverConfig.OpenAPIV3Config.Info.Title = title
+	}
+	if len(api.ServingCertSecret.Name) > 0 {
+		if serverConfig.SecureServing == nil {
+			return fmt.Errorf("the serving certificate of the aggregated API needs secure serving")
+		}
+		serverConfig.SecureServing.Cert = newSecretServingCertContent(kubeClient, api.ServingCertSecret, serverConfig.SecureServing.Cert)
+	}
+	return nil
+}
+
+// installAggregatedAPI installs the API groups of the aggregated API in the server.
+func (b *ControllerBuilder) installAggregatedAPI(server *genericapiserver.GenericAPIServer, kubeConfig *rest.Config) error {
+	if b.aggregatedAPI.APIGroups == nil {
+		return nil
+	}
+	groups, err := b.aggregatedAPI.APIGroups(kubeConfig)
+	if err != nil {
+		return fmt.Errorf("unable to build the API groups of the aggregated API: %w", err)
+	}
+	return server.InstallAPIGroups(groups...)
+}
+
+// secretServingCertContent serves the certificate and key of a kubernetes.io/tls secret, watching the secret for
+// rotations. The fallback is served until the secret exists, the server starts before the leader creates the secret.
+type secretServingCertContent struct {
+	secret     types.NamespacedName
+	kubeClient kubernetes.Interface
+	fallback   dynamiccertificates.CertKeyContentProvider
+
+	lock      sync.RWMutex
+	cert, key []byte
+	listeners []dynamiccertificates.Listener
+}
+
+var _ dynamiccertificates.CertKeyContentProvider = &secretServingCertContent{}
+var _ dynamiccertificates.ControllerRunner = &secretServingCertContent{}
+
+func newSecretServingCertContent(kubeClient kubernetes.Interface, secret types.NamespacedName, fallback dynamiccertificates.CertKeyContentProvider) *secretServingCertContent {
+	return &secretServingCertContent{secret: secret, kubeClient: kubeClient, fallback: fallback}
+}
+
+func (c *secretServingCertContent) Name() string {
+	return "secret::" + c.secret.String()
+}
+
+func (c *secretServingCertContent) CurrentCertKeyContent() ([]byte, []byte) {
+	c.lock.RLock()
+	cert, key := c.cert, c.key
+	c.lock.RUnlock()
+	if len(cert) == 0 && c.fallback != nil {
+		return c.fallback.CurrentCertKeyContent()
+	}
+	return cert, key
+}
+
+func (c *secretServingCertContent) AddListener(listener dynamiccertificates.Listener) {
+	c.lock.Lock()
+	c.listeners = append(c.listeners, listener)
+	c.lock.Unlock()
+	if c.fallback != nil {
+		c.fallback.AddListener(listener)
+	}
+}
+
+// RunOnce reads the fallback and the secret, so the server starts with the certificate. A missing secret is not an
+// error: the fallback is served until the secret is created.
+func (c *secretServingCertContent) RunOnce(ctx context.Context) error {
+	if runner, ok := c.fallback.(dynamiccertificates.ControllerRunner); ok {
+		if err := runner.RunOnce(ctx); err != nil {
+			klog.Warningf("Unable to load the fallback certificate of %s: %v", c.Name(), err)
+		}
+	}
+	secret, err := c.kubeClient.CoreV1().Secrets(c.secret.Namespace).Get(ctx, c.secret.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		klog.Infof("Serving the certificate of the serving info until %s exists", c.Name())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return c.update(secret)
+}
+
+// Run watches the secret until the context is done.
+func (c *secretServingCertContent) Run(ctx context.Context, workers int) {
+	if runner, ok := c.fallback.(dynamiccertificates.ControllerRunner); ok {
+		go runner.Run(ctx, workers)
+	}
+	informer := informers.NewSharedInformerFactoryWithOptions(c.kubeClient, 10*time.Minute,
+		informers.WithNamespace(c.secret.Namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", c.secret.Name).String()
+		}),
+	).Core().V1().Secrets().Informer()
+	update := func(obj interface{}) {
+		if secret, ok := obj.(*corev1.Secret); ok {
+			if err := c.update(secret); err != nil {
+				klog.Warningf("Unable to serve the certificate of %s: %v", c.Name(), err)
+			}
+		}
+	}
+	if _, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    update,
+		UpdateFunc: func(_, obj interface{}) { update(obj) },
+	}); err != nil {
+		klog.Warningf("Unable to watch %s: %v", c.Name(), err)
+		return
+	}
+	informer.Run(ctx.Done())
+}
+
+// update serves the certificate of the secret when it is a valid key pair different from the served one.
+func (c *secretServingCertContent) update(secret *corev1.Secret) error {
+	cert, key := secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]
+	if _, err := tls.X509KeyPair(cert, key); err != nil {
+		return fmt.Errorf("invalid key pair: %w", err)
+	}
+
+	c.lock.Lock()
+	if bytes.Equal(cert, c.cert) && bytes.Equal(key, c.key) {
+		c.lock.Unlock()
+		return nil
+	}
+	c.cert, c.key = cert, key
+	listeners := append([]dynamiccertificates.Listener{}, c.listeners...)
+	c.lock.Unlock()
+
+	klog.Infof("Serving the certificate of %s", c.Name())
+	for _, listener := range listeners {
+		listener.Enqueue()
+	}
+	return nil
+}
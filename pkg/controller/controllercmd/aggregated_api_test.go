@@ -0,0 +1,129 @@
+package controllercmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/apiserver/pkg/server/dynamiccertificates"
+	"k8s.io/client-go/kubernetes/fake"
+	openapicommon "k8s.io/kube-openapi/pkg/common"
+
+	"github.com/openshift/library-go/pkg/crypto"
+)
+
+type countingListener struct {
+	count int
+}
+
+func (l *countingListener) Enqueue() {
+	l.count++
+}
+
+func TestSecretServingCertContent(t *testing.T) {
+	ca, err := crypto.UnsafeMakeSelfSignedCAConfigForDurationAtTime("serving", time.Now, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, key, err := ca.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "serving-cert"},
+		Data:       map[string][]byte{corev1.TLSCertKey: cert, corev1.TLSPrivateKeyKey: key},
+	}
+
+	content := newSecretServingCertContent(fake.NewSimpleClientset(secret), types.NamespacedName{Namespace: "ns", Name: "serving-cert"}, nil)
+	listener := &countingListener{}
+	content.AddListener(listener)
+	if err := content.RunOnce(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if servedCert, servedKey := content.CurrentCertKeyContent(); string(servedCert) != string(cert) || string(servedKey) != string(key) {
+		t.Error("expected the certificate of the secret to be served")
+	}
+	if err := content.update(secret); err != nil || listener.count != 1 {
+		t.Errorf("expected the listeners to be notified only when the certificate changes, got %d notifications (%v)", listener.count, err)
+	}
+
+	invalid := secret.DeepCopy()
+	invalid.Data[corev1.TLSPrivateKeyKey] = []byte("garbage")
+	if err := content.update(invalid); err == nil {
+		t.Error("expected an invalid key pair to be rejected")
+	}
+	if _, servedKey := content.CurrentCertKeyContent(); string(servedKey) != string(key) {
+		t.Error("expected the valid certificate to still be served")
+	}
+}
+
+func TestSecretServingCertContentMissingSecret(t *testing.T) {
+	newKeyPair := func(name string) ([]byte, []byte) {
+		ca, err := crypto.UnsafeMakeSelfSignedCAConfigForDurationAtTime(name, time.Now, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, key, err := ca.GetPEMBytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert, key
+	}
+	fallbackCert, fallbackKey := newKeyPair("serving-info")
+	fallback, err := dynamiccertificates.NewStaticCertKeyContent("serving-info", fallbackCert, fallbackKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := newSecretServingCertContent(fake.NewSimpleClientset(), types.NamespacedName{Namespace: "ns", Name: "serving-cert"}, fallback)
+	listener := &countingListener{}
+	content.AddListener(listener)
+	if err := content.RunOnce(context.TODO()); err != nil {
+		t.Fatalf("expected a missing secret not to fail the start of the server, got %v", err)
+	}
+	if servedCert, _ := content.CurrentCertKeyContent(); string(servedCert) != string(fallbackCert) {
+		t.Error("expected the certificate of the serving info to be served until the secret exists")
+	}
+
+	cert, key := newKeyPair("serving")
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "serving-cert"},
+		Data:       map[string][]byte{corev1.TLSCertKey: cert, corev1.TLSPrivateKeyKey: key},
+	}
+	if err := content.update(secret); err != nil {
+		t.Fatal(err)
+	}
+	if servedCert, _ := content.CurrentCertKeyContent(); string(servedCert) != string(cert) || listener.count != 1 {
+		t.Errorf("expected the certificate of the secret to be served once it exists, got %d notifications", listener.count)
+	}
+}
+
+func TestApplyAggregatedAPI(t *testing.T) {
+	builder := NewController("foo-apiserver", nil).WithAggregatedAPI(AggregatedAPI{
+		Scheme:             runtime.NewScheme(),
+		OpenAPIDefinitions: func(openapicommon.ReferenceCallback) map[string]openapicommon.OpenAPIDefinition { return nil },
+		ServingCertSecret:  types.NamespacedName{Namespace: "ns", Name: "serving-cert"},
+	})
+	serverConfig := genericapiserver.NewConfig(serializer.NewCodecFactory(runtime.NewScheme()))
+	serverConfig.SecureServing = &genericapiserver.SecureServingInfo{}
+	if err := builder.applyAggregatedAPI(serverConfig, fake.NewSimpleClientset()); err != nil {
+		t.Fatal(err)
+	}
+	if serverConfig.OpenAPIConfig == nil || serverConfig.OpenAPIConfig.Info.Title != "foo-apiserver" || serverConfig.OpenAPIV3Config == nil {
+		t.Errorf("expected the OpenAPI to be configured, got %#v", serverConfig.OpenAPIConfig)
+	}
+	if serverConfig.SecureServing.Cert == nil || serverConfig.SecureServing.Cert.Name() != "secret::ns/serving-cert" {
+		t.Errorf("expected the serving certificate of the secret, got %v", serverConfig.SecureServing.Cert)
+	}
+
+	builder.aggregatedAPI.Scheme = nil
+	if err := builder.applyAggregatedAPI(serverConfig, fake.NewSimpleClientset()); err == nil {
+		t.Error("expected the OpenAPI definitions to require a scheme")
+	}
+}
@@ -16,8 +16,10 @@ import (
 	"github.com/openshift/library-go/pkg/config/configdefaults"
 	leaderelectionconverter "github.com/openshift/library-go/pkg/config/leaderelection"
 	"github.com/openshift/library-go/pkg/config/serving"
+	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/controller/fileobserver"
 	"github.com/openshift/library-go/pkg/operator/events"
+	oteltrace "go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -31,6 +33,8 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/component-base/metrics"
 	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/component-base/tracing"
+	tracingapiv1 "k8s.io/component-base/tracing/api/v1"
 	"k8s.io/klog/v2"
 )
 
@@ -38,6 +42,8 @@ import (
 type StartFunc func(context.Context, *ControllerContext) error
 
 type ControllerContext struct {
+	// ComponentConfig is the config of the operator read from the config files. Use DecodeComponentConfig to decode
+	// it, or a section of it, into a typed config.
 	ComponentConfig *unstructured.Unstructured
 
 	// KubeConfig provides the REST config with no content type (it will default to JSON).
@@ -56,8 +62,31 @@ type ControllerContext struct {
 
 	// Namespace where the operator runs. Either specified on the command line or autodetected.
 	OperatorNamespace string
+
+	// LeaderObserver reports the current leader and notifies about leadership changes.
+	// It is nil when leader election is disabled.
+	LeaderObserver *leaderelectionconverter.LeaderObserver
+
+	// NamespaceScoped is true when the operator runs with namespace scoped RBAC only. Controllers needing cluster
+	// scoped resources must not be started.
+	NamespaceScoped bool
+
+	// MissingPermissions lists the required permissions the startup self-check found missing.
+	// See PermissionsDegradedCondition to report them.
+	MissingPermissions []MissingPermission
+
+	// ControllerHealth collects the health of the controllers built with factory.WithHealthRegistry.
+	// It is nil unless WithControllerHealth was used.
+	ControllerHealth *factory.HealthRegistry
+
+	// TracerProvider records the spans of the controller syncs and API calls. It does not record anything unless
+	// tracing was enabled with WithTracing.
+	TracerProvider oteltrace.TracerProvider
 }
 
+// controllerHealthPath serves the health of all controllers of the process as JSON.
+const controllerHealthPath = "/debug/controllers"
+
 // defaultObserverInterval specifies the default interval that file observer will do rehash the files it watches and react to any changes
 // in those files.
 var defaultObserverInterval = 5 * time.Second
@@ -73,6 +102,7 @@ type ControllerBuilder struct {
 	componentOwnerReference *corev1.ObjectReference
 
 	startFunc          StartFunc
+	followerStartFunc  StartFunc
 	componentName      string
 	componentNamespace string
 	instanceIdentity   string
@@ -82,6 +112,10 @@ type ControllerBuilder struct {
 	authenticationConfig *operatorv1alpha1.DelegatedAuthentication
 	authorizationConfig  *operatorv1alpha1.DelegatedAuthorization
 	healthChecks         []healthz.HealthChecker
+	controllerHealth     *factory.HealthRegistry
+	tracingConfig        *tracingapiv1.TracingConfiguration
+	diagnosticsConfigMap string
+	aggregatedAPI        *AggregatedAPI
 
 	versionInfo *version.Info
 
@@ -99,6 +133,17 @@ type ControllerBuilder struct {
 
 	// Allow enabling HTTP2
 	enableHTTP2 bool
+
+	// permissions verified by the startup self-check
+	requiredPermissions []RequiredPermission
+	// disables the features needing cluster scoped permissions
+	namespaceScoped bool
+
+	runtimePaths RuntimePaths
+
+	// the leader identity names the pod, and what to do with the lease of a leader pod which is gone
+	podIdentity         bool
+	holderPodGonePolicy leaderelectionconverter.HolderPodGonePolicy
 }
 
 type TopologyDetector interface {
@@ -214,6 +259,30 @@ func (b *ControllerBuilder) WithHealthChecks(healthChecks ...healthz.HealthCheck
 	return b
 }
 
+// WithControllerHealth reports the health of all controllers using the registry in the "controllers" healthz check
+// and serves the per-controller details on /debug/controllers. The registry is passed to the start function in
+// ControllerContext.ControllerHealth.
+func (b *ControllerBuilder) WithControllerHealth(registry *factory.HealthRegistry) *ControllerBuilder {
+	b.controllerHealth = registry
+	return b
+}
+
+// WithTracing exports the spans of the controller syncs and of the API calls made with the client configs of the
+// ControllerContext to the OTLP endpoint of the config. Nil disables tracing.
+func (b *ControllerBuilder) WithTracing(tracingConfig *tracingapiv1.TracingConfiguration) *ControllerBuilder {
+	b.tracingConfig = tracingConfig
+	return b
+}
+
+// WithDiagnostics serves the goroutine, mutex and block profiles on /debug/diagnostics/ while the named configmap
+// exists in the component namespace. The profiles stay enabled for the duration in the "duration" key of the
+// configmap counted from its creation, 10 minutes by default and at most one hour. The configmap is deleted when
+// the duration expires. It requires WithServer.
+func (b *ControllerBuilder) WithDiagnostics(configMapName string) *ControllerBuilder {
+	b.diagnosticsConfigMap = configMapName
+	return b
+}
+
 // WithKubeConfigFile sets an optional kubeconfig file. inclusterconfig will be used if filename is empty
 func (b *ControllerBuilder) WithKubeConfigFile(kubeConfigFilename string, defaults *client.ClientConnectionOverrides) *ControllerBuilder {
 	b.kubeAPIServerConfigFile = &kubeConfigFilename
@@ -228,6 +297,16 @@ func (b *ControllerBuilder) WithInstanceIdentity(identity string) *ControllerBui
 	return b
 }
 
+// WithPodIdentity uses the pod named by the POD_NAME and POD_NAMESPACE environment variables, set from the downward API,
+// as the leader identity unless WithInstanceIdentity is used. With a holderPodGonePolicy, every replica watches the
+// pod holding the leader election lease and releases or takes over the lease as soon as the pod is gone, instead of
+// waiting for the lease to expire.
+func (b *ControllerBuilder) WithPodIdentity(holderPodGonePolicy leaderelectionconverter.HolderPodGonePolicy) *ControllerBuilder {
+	b.podIdentity = true
+	b.holderPodGonePolicy = holderPodGonePolicy
+	return b
+}
+
 // WithEventRecorderOptions allows to override the default Kubernetes event recorder correlator options.
 // This is needed if the binary is sending a lot of events.
 // Using events.DefaultOperatorEventRecorderOptions here makes a good default for normal operator binary.
@@ -236,12 +315,41 @@ func (b *ControllerBuilder) WithEventRecorderOptions(options record.CorrelatorOp
 	return b
 }
 
+// WithFollowerStartFunc sets a function that is started on every replica, regardless of whether it holds the leader
+// election lock. Only run "follower-safe" controllers from it, i.e. controllers that do not write to the cluster, like
+// metrics or certificate expiry reporting. ControllerContext.LeaderObserver can be used to react to leadership changes.
+func (b *ControllerBuilder) WithFollowerStartFunc(startFunc StartFunc) *ControllerBuilder {
+	b.followerStartFunc = startFunc
+	return b
+}
+
 // WithComponentOwnerReference overrides controller reference resolution for event recording
 func (b *ControllerBuilder) WithComponentOwnerReference(reference *corev1.ObjectReference) *ControllerBuilder {
 	b.componentOwnerReference = reference
 	return b
 }
 
+// WithRequiredPermissions makes the controller verify the permissions with SelfSubjectAccessReviews on startup.
+// Missing permissions are reported as an event and are available in ControllerContext.MissingPermissions.
+func (b *ControllerBuilder) WithRequiredPermissions(permissions ...RequiredPermission) *ControllerBuilder {
+	b.requiredPermissions = append(b.requiredPermissions, permissions...)
+	return b
+}
+
+// WithNamespaceScoped runs the controller with namespace scoped RBAC only. Cluster scoped features, like the
+// control plane topology detection, are disabled and cluster scoped required permissions are not checked.
+func (b *ControllerBuilder) WithNamespaceScoped() *ControllerBuilder {
+	b.namespaceScoped = true
+	return b
+}
+
+// WithRuntimePaths replaces the pod paths the namespace of the component is read from, for running as non root,
+// outside of a cluster or on Windows.
+func (b *ControllerBuilder) WithRuntimePaths(paths RuntimePaths) *ControllerBuilder {
+	b.runtimePaths = paths
+	return b
+}
+
 // Run starts your controller for you.  It uses leader election if you asked, otherwise it directly calls you
 func (b *ControllerBuilder) Run(ctx context.Context, config *unstructured.Unstructured) error {
 	clientConfig, err := b.getClientConfig()
@@ -253,6 +361,14 @@ func (b *ControllerBuilder) Run(ctx context.Context, config *unstructured.Unstru
 		go b.fileObserver.Run(ctx.Done())
 	}
 
+	var tracerProvider oteltrace.TracerProvider = tracing.NewNoopTracerProvider()
+	if b.tracingConfig != nil {
+		tracerProvider, err = b.startTracing(ctx, clientConfig)
+		if err != nil {
+			return err
+		}
+	}
+
 	kubeClient := kubernetes.NewForConfigOrDie(clientConfig)
 	namespace, err := b.getComponentNamespace()
 	if err != nil {
@@ -272,6 +388,11 @@ func (b *ControllerBuilder) Run(ctx context.Context, config *unstructured.Unstru
 		eventRecorder.Warningf(fmt.Sprintf("%sPanic", strings.Title(b.componentName)), "Panic observed: %v", r)
 	})
 
+	missingPermissions, err := b.checkPermissions(ctx, kubeClient, eventRecorder)
+	if err != nil {
+		return err
+	}
+
 	// if there is file observer defined for this command, add event into default reaction function.
 	if b.fileObserverReactorFn != nil {
 		originalFileObserverReactorFn := b.fileObserverReactorFn
@@ -303,6 +424,10 @@ func (b *ControllerBuilder) Run(ctx context.Context, config *unstructured.Unstru
 		kubeConfig = *b.kubeAPIServerConfigFile
 	}
 
+	if b.aggregatedAPI != nil && b.servingInfo == nil {
+		return fmt.Errorf("the aggregated API requires a server")
+	}
+
 	var server *genericapiserver.GenericAPIServer
 	if b.servingInfo != nil {
 		serverConfig, err := serving.ToServerConfig(ctx, *b.servingInfo, *b.authenticationConfig, *b.authorizationConfig, kubeConfig, kubeClient, b.leaderElection, b.enableHTTP2, b.versionInfo)
@@ -316,11 +441,32 @@ func (b *ControllerBuilder) Run(ctx context.Context, config *unstructured.Unstru
 			serverConfig.Authorization.Authorizer,
 		)
 		serverConfig.HealthzChecks = append(serverConfig.HealthzChecks, b.healthChecks...)
+		if b.controllerHealth != nil {
+			serverConfig.HealthzChecks = append(serverConfig.HealthzChecks, b.controllerHealth)
+		}
+		if b.aggregatedAPI != nil {
+			if err := b.applyAggregatedAPI(serverConfig, kubeClient); err != nil {
+				return err
+			}
+		}
 
 		server, err = serverConfig.Complete(nil).New(b.componentName, genericapiserver.NewEmptyDelegate())
 		if err != nil {
 			return err
 		}
+		if b.controllerHealth != nil {
+			server.Handler.NonGoRestfulMux.Handle(controllerHealthPath, b.controllerHealth)
+		}
+		if b.aggregatedAPI != nil {
+			if err := b.installAggregatedAPI(server, clientConfig); err != nil {
+				return err
+			}
+		}
+		if len(b.diagnosticsConfigMap) > 0 {
+			diagnostics := newDiagnosticsGate(namespace, b.diagnosticsConfigMap, kubeClient.CoreV1(), eventRecorder)
+			server.Handler.NonGoRestfulMux.HandlePrefix(diagnosticsPath, diagnostics)
+			go diagnostics.run(ctx)
+		}
 
 		go func() {
 			if err := server.PrepareRun().Run(ctx.Done()); err != nil {
@@ -341,16 +487,25 @@ func (b *ControllerBuilder) Run(ctx context.Context, config *unstructured.Unstru
 		EventRecorder:     eventRecorder,
 		Server:            server,
 		OperatorNamespace: namespace,
+
+		NamespaceScoped:    b.namespaceScoped,
+		MissingPermissions: missingPermissions,
+		ControllerHealth:   b.controllerHealth,
+		TracerProvider:     tracerProvider,
 	}
 
 	if b.leaderElection == nil {
+		if b.followerStartFunc != nil {
+			go b.runFollowerStartFunc(ctx, controllerContext)
+		}
 		if err := b.startFunc(ctx, controllerContext); err != nil {
 			return err
 		}
 		return nil
 	}
 
-	if !b.userExplicitlySetLeaderElectionValues {
+	// the topology is read from the cluster scoped infrastructure resource
+	if !b.userExplicitlySetLeaderElectionValues && !b.namespaceScoped {
 		topology, err := b.topologyDetector.DetectTopology(ctx, clientConfig)
 		if err != nil || topology == "" {
 			eventRecorder.Warningf("ControlPlaneTopology", "unable to get control plane topology, using HA cluster values for leader election: %v", err)
@@ -361,20 +516,47 @@ func (b *ControllerBuilder) Run(ctx context.Context, config *unstructured.Unstru
 		}
 	}
 
+	// the namespace file may not be the one of a pod, see WithRuntimePaths
+	if len(b.leaderElection.Namespace) == 0 {
+		if namespace, err := b.runtimePaths.readNamespace(); err == nil {
+			b.leaderElection.Namespace = namespace
+		}
+	}
+
 	// ensure blocking TCP connections don't block the leader election
 	leaderConfig := rest.CopyConfig(protoConfig)
 	leaderConfig.Timeout = b.leaderElection.RenewDeadline.Duration
 
-	leaderElection, err := leaderelectionconverter.ToLeaderElectionWithLease(leaderConfig, *b.leaderElection, b.componentName, b.instanceIdentity)
+	identity := b.instanceIdentity
+	if b.podIdentity && len(identity) == 0 {
+		if identity = leaderelectionconverter.PodIdentityFromEnv(); len(identity) == 0 {
+			klog.Warningf("POD_NAME and POD_NAMESPACE are not set, the leader identity does not name the pod")
+		}
+	}
+	leaderElection, err := leaderelectionconverter.ToLeaderElectionWithLease(leaderConfig, *b.leaderElection, b.componentName, identity)
 	if err != nil {
 		return err
 	}
+	if _, _, ok := leaderelectionconverter.ParsePodIdentity(identity); ok && len(b.holderPodGonePolicy) > 0 {
+		guard, err := leaderelectionconverter.NewHolderPodGuard(kubeClient.CoordinationV1(), kubeClient.CoreV1(), *b.leaderElection, identity, b.holderPodGonePolicy)
+		if err != nil {
+			return err
+		}
+		go guard.Run(ctx, b.leaderElection.RetryPeriod.Duration)
+	}
 
 	// 10s is the graceful termination time we give the controllers to finish their workers.
 	// when this time pass, we exit with non-zero code, killing all controller workers.
 	// NOTE: The pod must set the termination graceful time.
 	leaderElection.Callbacks.OnStartedLeading = b.getOnStartedLeadingFunc(controllerContext, 10*time.Second)
 
+	// every replica observes the leadership through the same lock object
+	controllerContext.LeaderObserver = leaderelectionconverter.NewLeaderObserver(leaderElection.Lock)
+	leaderElection.Callbacks = controllerContext.LeaderObserver.WrapCallbacks(leaderElection.Callbacks)
+	if b.followerStartFunc != nil {
+		go b.runFollowerStartFunc(ctx, controllerContext)
+	}
+
 	leaderelection.RunOrDie(ctx, leaderElection)
 	return nil
 }
@@ -408,15 +590,43 @@ func (b ControllerBuilder) getOnStartedLeadingFunc(controllerContext *Controller
 	}
 }
 
+func (b *ControllerBuilder) runFollowerStartFunc(ctx context.Context, controllerContext *ControllerContext) {
+	if err := b.followerStartFunc(ctx, controllerContext); err != nil {
+		b.nonZeroExitFn(fmt.Sprintf("follower controllers failed with error: %v", err))
+	}
+}
+
+// checkPermissions runs the permission self-check and reports the missing permissions. It does not fail on missing
+// permissions, operators are expected to report them as degraded instead.
+func (b *ControllerBuilder) checkPermissions(ctx context.Context, kubeClient kubernetes.Interface, eventRecorder events.Recorder) ([]MissingPermission, error) {
+	required := b.requiredPermissions
+	if b.namespaceScoped {
+		required = filterNamespaceScoped(required)
+	}
+	if len(required) == 0 {
+		return nil, nil
+	}
+	missing, err := CheckPermissions(ctx, kubeClient.AuthorizationV1(), required)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		message := MissingPermissionsMessage(missing)
+		klog.Warningf("%s: %s", b.componentName, message)
+		eventRecorder.Warningf("MissingPermissions", "%s", message)
+	}
+	return missing, nil
+}
+
 func (b *ControllerBuilder) getComponentNamespace() (string, error) {
 	if len(b.componentNamespace) > 0 {
 		return b.componentNamespace, nil
 	}
-	nsBytes, err := os.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace")
+	namespace, err := b.runtimePaths.readNamespace()
 	if err != nil {
 		return "openshift-config-managed", err
 	}
-	return string(nsBytes), nil
+	return namespace, nil
 }
 
 func (b *ControllerBuilder) getClientConfig() (*rest.Config, error) {
@@ -17,6 +17,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/apiserver/pkg/server"
 	"k8s.io/client-go/tools/record"
@@ -27,6 +28,9 @@ import (
 	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
 
 	"github.com/openshift/library-go/pkg/config/configdefaults"
+	leaderelectionconverter "github.com/openshift/library-go/pkg/config/leaderelection"
+	"github.com/openshift/library-go/pkg/config/validation"
+	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/controller/fileobserver"
 	"github.com/openshift/library-go/pkg/crypto"
 	"github.com/openshift/library-go/pkg/operator/events"
@@ -34,13 +38,16 @@ import (
 
 	// load all the prometheus client-go metrics
 	_ "k8s.io/component-base/metrics/prometheus/clientgo"
+	// load the prometheus workqueue metrics, reported for the named queues of the controllers
+	_ "k8s.io/component-base/metrics/prometheus/workqueue"
 )
 
 // ControllerCommandConfig holds values required to construct a command to run.
 type ControllerCommandConfig struct {
-	componentName string
-	startFunc     StartFunc
-	version       version.Info
+	componentName     string
+	startFunc         StartFunc
+	followerStartFunc StartFunc
+	version           version.Info
 
 	basicFlags *ControllerFlags
 
@@ -71,7 +78,14 @@ type ControllerCommandConfig struct {
 
 	ComponentOwnerReference *corev1.ObjectReference
 	healthChecks            []healthz.HealthChecker
+	controllerHealth        *factory.HealthRegistry
 	eventRecorderOptions    record.CorrelatorOptions
+	requiredPermissions     []RequiredPermission
+	namespaceScoped         bool
+	runtimePaths            RuntimePaths
+	podIdentity             bool
+	holderPodGonePolicy     leaderelectionconverter.HolderPodGonePolicy
+	aggregatedAPI           *AggregatedAPI
 }
 
 // NewControllerConfig returns a new ControllerCommandConfig which can be used to wire up all the boiler plate of a controller
@@ -96,16 +110,70 @@ func (c *ControllerCommandConfig) WithComponentOwnerReference(reference *corev1.
 	return c
 }
 
+// WithFollowerStartFunc sets a function that is started on every replica, including those not holding the leader election lock.
+// See ControllerBuilder.WithFollowerStartFunc.
+func (c *ControllerCommandConfig) WithFollowerStartFunc(startFunc StartFunc) *ControllerCommandConfig {
+	c.followerStartFunc = startFunc
+	return c
+}
+
 func (c *ControllerCommandConfig) WithHealthChecks(healthChecks ...healthz.HealthChecker) *ControllerCommandConfig {
 	c.healthChecks = append(c.healthChecks, healthChecks...)
 	return c
 }
 
+// WithControllerHealth serves the health of the controllers using the registry. See ControllerBuilder.WithControllerHealth.
+func (c *ControllerCommandConfig) WithControllerHealth(registry *factory.HealthRegistry) *ControllerCommandConfig {
+	c.controllerHealth = registry
+	return c
+}
+
 func (c *ControllerCommandConfig) WithTopologyDetector(topologyDetector TopologyDetector) *ControllerCommandConfig {
 	c.TopologyDetector = topologyDetector
 	return c
 }
 
+// WithConfigEnvExpansion enables ${ENV_VAR} substitution in the string values of the config files. When
+// allowedEnvVars are given, referencing any other environment variable is an error.
+func (c *ControllerCommandConfig) WithConfigEnvExpansion(allowedEnvVars ...string) *ControllerCommandConfig {
+	c.basicFlags.ExpandEnv = true
+	c.basicFlags.ExpandEnvAllowlist = append(c.basicFlags.ExpandEnvAllowlist, allowedEnvVars...)
+	return c
+}
+
+// WithRequiredPermissions enables the startup permission self-check. See ControllerBuilder.WithRequiredPermissions.
+func (c *ControllerCommandConfig) WithRequiredPermissions(permissions ...RequiredPermission) *ControllerCommandConfig {
+	c.requiredPermissions = append(c.requiredPermissions, permissions...)
+	return c
+}
+
+// WithNamespaceScoped disables cluster scoped features. See ControllerBuilder.WithNamespaceScoped.
+func (c *ControllerCommandConfig) WithNamespaceScoped() *ControllerCommandConfig {
+	c.namespaceScoped = true
+	return c
+}
+
+// WithRuntimePaths replaces the pod paths of the serving certificate, of the self-signed certificate generated in its
+// absence and of the namespace file, for running as non root, outside of a cluster or on Windows.
+// See ControllerBuilder.WithRuntimePaths.
+func (c *ControllerCommandConfig) WithRuntimePaths(paths RuntimePaths) *ControllerCommandConfig {
+	c.runtimePaths = paths
+	return c
+}
+
+// WithPodIdentity uses the pod as the leader identity. See ControllerBuilder.WithPodIdentity.
+func (c *ControllerCommandConfig) WithPodIdentity(holderPodGonePolicy leaderelectionconverter.HolderPodGonePolicy) *ControllerCommandConfig {
+	c.podIdentity = true
+	c.holderPodGonePolicy = holderPodGonePolicy
+	return c
+}
+
+// WithAggregatedAPI serves the API groups from the server of the controller. See ControllerBuilder.WithAggregatedAPI.
+func (c *ControllerCommandConfig) WithAggregatedAPI(api AggregatedAPI) *ControllerCommandConfig {
+	c.aggregatedAPI = &api
+	return c
+}
+
 func (c *ControllerCommandConfig) WithEventRecorderOptions(eventRecorderOptions record.CorrelatorOptions) *ControllerCommandConfig {
 	c.eventRecorderOptions = eventRecorderOptions
 	return c
@@ -220,7 +288,8 @@ func hasServiceServingCerts(certDir string) bool {
 // you do not need to customize the controller builder. This method modifies config with self-signed default cert locations if
 // necessary.
 func (c *ControllerCommandConfig) AddDefaultRotationToConfig(config *operatorv1alpha1.GenericOperatorConfig, configContent []byte) (map[string][]byte, []string, error) {
-	certDir := "/var/run/secrets/serving-cert"
+	paths := c.runtimePaths.withDefaults()
+	certDir := paths.ServingCertDir
 
 	observedFiles := []string{
 		// We observe these, so we they are created or modified by service serving cert signer, we can react and restart the process
@@ -235,9 +304,24 @@ func (c *ControllerCommandConfig) AddDefaultRotationToConfig(config *operatorv1a
 	startingFileContent := map[string][]byte{}
 
 	// Since provision of a config filename is optional, only observe when one is provided.
-	if len(c.basicFlags.ConfigFile) > 0 {
-		observedFiles = append(observedFiles, c.basicFlags.ConfigFile)
-		startingFileContent[c.basicFlags.ConfigFile] = configContent
+	configFiles, err := c.basicFlags.ConfigFileNames()
+	if err != nil {
+		return nil, nil, err
+	}
+	switch {
+	case len(configFiles) == 1:
+		observedFiles = append(observedFiles, configFiles[0])
+		startingFileContent[configFiles[0]] = configContent
+	case len(configFiles) > 1:
+		// configContent holds the merged config, observe every file that contributed to it with its own content
+		for _, configFile := range configFiles {
+			fileContent, err := os.ReadFile(configFile)
+			if err != nil {
+				return nil, nil, err
+			}
+			observedFiles = append(observedFiles, configFile)
+			startingFileContent[configFile] = fileContent
+		}
 	}
 
 	// if we don't have any serving cert/key pairs specified and the defaults are not present, generate a self-signed set
@@ -257,7 +341,7 @@ func (c *ControllerCommandConfig) AddDefaultRotationToConfig(config *operatorv1a
 			startingFileContent[filepath.Join(certDir, "tls.crt")] = []byte{}
 			startingFileContent[filepath.Join(certDir, "tls.key")] = []byte{}
 
-			temporaryCertDir, err := os.MkdirTemp("", "serving-cert-")
+			temporaryCertDir, err := os.MkdirTemp(paths.TempDir, "serving-cert-")
 			if err != nil {
 				return nil, nil, err
 			}
@@ -289,6 +373,30 @@ func (c *ControllerCommandConfig) AddDefaultRotationToConfig(config *operatorv1a
 	return startingFileContent, observedFiles, nil
 }
 
+// validateConfig validates the config as the controller will default it, before the controller starts.
+func (c *ControllerCommandConfig) validateConfig(config *operatorv1alpha1.GenericOperatorConfig) error {
+	defaulted := config.DeepCopy()
+	// the default client CA is optional, the server starts without it
+	clientCA := defaulted.ServingInfo.ClientCA
+	configdefaults.SetRecommendedHTTPServingInfoDefaults(&defaulted.ServingInfo)
+	defaulted.ServingInfo.ClientCA = clientCA
+	defaulted.LeaderElection = leaderelectionconverter.LeaderElectionDefaulting(defaulted.LeaderElection, c.basicFlags.Namespace, c.componentName+"-lock")
+
+	var validationResults validation.ValidationResults
+	if c.DisableServing {
+		validationResults = validation.ValidateLeaderElection(defaulted.LeaderElection, field.NewPath("leaderElection"))
+	} else {
+		validationResults = validation.ValidateGenericOperatorConfig(defaulted, nil)
+	}
+	for _, warning := range validationResults.Warnings {
+		klog.Warningf("Config: %v", warning)
+	}
+	if err := validationResults.Errors.ToAggregate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	return nil
+}
+
 // StartController runs the controller. This is the recommend entrypoint when you don't need
 // to customize the builder.
 func (c *ControllerCommandConfig) StartController(ctx context.Context) error {
@@ -322,6 +430,10 @@ func (c *ControllerCommandConfig) StartController(ctx context.Context) error {
 	config.LeaderElection.RenewDeadline = c.RenewDeadline
 	config.LeaderElection.RetryPeriod = c.RetryPeriod
 
+	if err := c.validateConfig(config); err != nil {
+		return err
+	}
+
 	builder := NewController(c.componentName, c.startFunc).
 		WithKubeConfigFile(c.basicFlags.KubeConfigFile, nil).
 		WithComponentNamespace(c.basicFlags.Namespace).
@@ -343,5 +455,35 @@ func (c *ControllerCommandConfig) StartController(ctx context.Context) error {
 		builder = builder.WithTopologyDetector(c.TopologyDetector)
 	}
 
+	if c.controllerHealth != nil {
+		builder = builder.WithControllerHealth(c.controllerHealth)
+	}
+
+	tracingConfig, err := TracingConfigFromConfig(unstructuredConfig)
+	if err != nil {
+		return err
+	}
+	if tracingConfig != nil {
+		builder = builder.WithTracing(tracingConfig)
+	}
+
+	if c.followerStartFunc != nil {
+		builder = builder.WithFollowerStartFunc(c.followerStartFunc)
+	}
+
+	if len(c.requiredPermissions) > 0 {
+		builder = builder.WithRequiredPermissions(c.requiredPermissions...)
+	}
+	if c.namespaceScoped {
+		builder = builder.WithNamespaceScoped()
+	}
+	builder = builder.WithRuntimePaths(c.runtimePaths)
+	if c.podIdentity {
+		builder = builder.WithPodIdentity(c.holderPodGonePolicy)
+	}
+	if c.aggregatedAPI != nil {
+		builder = builder.WithAggregatedAPI(*c.aggregatedAPI)
+	}
+
 	return builder.Run(controllerCtx, unstructuredConfig)
 }
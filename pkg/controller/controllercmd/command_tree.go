@@ -0,0 +1,135 @@
+package controllercmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/component-base/logs"
+)
+
+// The names of the subcommands of operator binaries built with CommandTree.
+const (
+	OperatorCommandName         = "operator"
+	RenderCommandName           = "render"
+	InstallerCommandName        = "installer"
+	PruneCommandName            = "prune"
+	CertRegenerationCommandName = "cert-regeneration"
+	VersionCommandName          = "version"
+)
+
+// CommandTree builds the root command of an operator binary exposing multiple subcommands, e.g. the operator itself,
+// the installer and pruner of static pod operators and the cert regeneration controller, so every operator wires
+// them the same way:
+//
+//	cmd := controllercmd.NewCommandTree("cluster-foo-operator", "OpenShift cluster foo operator", version.Get()).
+//	  WithOperator(ctx, controllercmd.NewControllerCommandConfig("foo-operator", version.Get(), operator.RunOperator), "Start the operator").
+//	  WithInstaller(installerpod.NewInstaller(ctx)).
+//	  WithPrune(prune.NewPrune()).
+//	  NewCommand()
+//
+// The subcommands share the log flags, the flags added by WithSharedFlags and the version info.
+type CommandTree struct {
+	name        string
+	short       string
+	version     version.Info
+	subcommands []*cobra.Command
+	sharedFlags []func(flags *pflag.FlagSet)
+}
+
+// NewCommandTree returns a command tree for the binary with the name and description.
+func NewCommandTree(name, short string, version version.Info) *CommandTree {
+	return &CommandTree{
+		name:    name,
+		short:   short,
+		version: version,
+	}
+}
+
+// WithOperator adds the operator subcommand running the controllers of the config.
+func (t *CommandTree) WithOperator(ctx context.Context, config *ControllerCommandConfig, short string) *CommandTree {
+	cmd := config.NewCommandWithContext(ctx)
+	cmd.Short = short
+	return t.WithCommand(OperatorCommandName, cmd)
+}
+
+// WithRender adds the render subcommand, rendering the bootstrap manifests of the operand.
+func (t *CommandTree) WithRender(cmd *cobra.Command) *CommandTree {
+	return t.WithCommand(RenderCommandName, cmd)
+}
+
+// WithInstaller adds the installer subcommand, e.g. installerpod.NewInstaller.
+func (t *CommandTree) WithInstaller(cmd *cobra.Command) *CommandTree {
+	return t.WithCommand(InstallerCommandName, cmd)
+}
+
+// WithPrune adds the prune subcommand, e.g. prune.NewPrune.
+func (t *CommandTree) WithPrune(cmd *cobra.Command) *CommandTree {
+	return t.WithCommand(PruneCommandName, cmd)
+}
+
+// WithCertRegeneration adds the cert-regeneration subcommand, running the cert regeneration controller.
+func (t *CommandTree) WithCertRegeneration(cmd *cobra.Command) *CommandTree {
+	return t.WithCommand(CertRegenerationCommandName, cmd)
+}
+
+// WithCommand adds a subcommand under the name. An empty name keeps the name the command was created with.
+func (t *CommandTree) WithCommand(name string, cmd *cobra.Command) *CommandTree {
+	if len(name) > 0 {
+		cmd.Use = name
+	}
+	t.subcommands = append(t.subcommands, cmd)
+	return t
+}
+
+// WithSharedFlags adds flags to all subcommands.
+func (t *CommandTree) WithSharedFlags(addFlags func(flags *pflag.FlagSet)) *CommandTree {
+	t.sharedFlags = append(t.sharedFlags, addFlags)
+	return t
+}
+
+// NewCommand returns the root command of the binary. It panics when two subcommands have the same name.
+func (t *CommandTree) NewCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:     t.name,
+		Short:   t.short,
+		Version: t.version.String(),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+			os.Exit(1)
+		},
+	}
+
+	logs.AddFlags(root.PersistentFlags())
+	for _, addFlags := range t.sharedFlags {
+		addFlags(root.PersistentFlags())
+	}
+
+	names := map[string]bool{VersionCommandName: true}
+	root.AddCommand(t.newVersionCommand())
+	for _, cmd := range t.subcommands {
+		if names[cmd.Name()] {
+			panic(fmt.Errorf("duplicate subcommand %q of %q", cmd.Name(), t.name))
+		}
+		names[cmd.Name()] = true
+		root.AddCommand(cmd)
+	}
+	return root
+}
+
+func (t *CommandTree) newVersionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   VersionCommandName,
+		Short: "Print the version information",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", t.name, t.version.String())
+			if len(t.version.GitCommit) > 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "commit %s, built %s\n", t.version.GitCommit, t.version.BuildDate)
+			}
+		},
+	}
+}
@@ -0,0 +1,72 @@
+package controllercmd
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"k8s.io/apimachinery/pkg/version"
+)
+
+func TestCommandTree(t *testing.T) {
+	var installed, sharedValue string
+	installer := &cobra.Command{
+		Use: "install",
+		Run: func(cmd *cobra.Command, args []string) {
+			installed = cmd.Name()
+		},
+	}
+	versionInfo := version.Info{GitVersion: "v4.18.0", GitCommit: "abc123", BuildDate: "2024-01-01"}
+
+	root := NewCommandTree("cluster-foo-operator", "foo operator", versionInfo).
+		WithOperator(context.TODO(), NewControllerCommandConfig("foo-operator", versionInfo, nil), "Start the operator").
+		WithInstaller(installer).
+		WithPrune(&cobra.Command{Use: "pruner"}).
+		WithSharedFlags(func(flags *pflag.FlagSet) {
+			flags.StringVar(&sharedValue, "shared", "", "a shared flag")
+		}).
+		NewCommand()
+
+	var names []string
+	for _, cmd := range root.Commands() {
+		names = append(names, cmd.Name())
+	}
+	if expected := []string{"installer", "operator", "prune", "version"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected subcommands %v, got %v", expected, names)
+	}
+
+	root.SetArgs([]string{"installer", "--shared=value", "--v=2"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if installed != "installer" || sharedValue != "value" {
+		t.Errorf("expected the installer to run with the shared flag, got %q and %q", installed, sharedValue)
+	}
+
+	out := &bytes.Buffer{}
+	root.SetOut(out)
+	root.SetArgs([]string{"version"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "cluster-foo-operator v4.18.0") || !strings.Contains(out.String(), "abc123") {
+		t.Errorf("unexpected version output %q", out.String())
+	}
+}
+
+func TestCommandTreeDuplicateSubcommand(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expected a panic for a duplicate subcommand")
+		}
+	}()
+	NewCommandTree("foo", "", version.Info{}).
+		WithPrune(&cobra.Command{Use: "prune"}).
+		WithCommand("", &cobra.Command{Use: "prune"}).
+		NewCommand()
+}
@@ -0,0 +1,107 @@
+package controllercmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DecodeOption configures DecodeComponentConfig.
+type DecodeOption func(*decodeOptions)
+
+type decodeOptions struct {
+	strict     bool
+	path       []string
+	defaulters []func(obj interface{}) error
+}
+
+// Strict fails the decoding when the config has fields the target type does not know, e.g. misspelled ones.
+// By default unknown fields are ignored, so a config can carry sections for other consumers.
+func Strict() DecodeOption {
+	return func(o *decodeOptions) {
+		o.strict = true
+	}
+}
+
+// FromField decodes the section of the config at the path of fields instead of the whole config, e.g.
+// FromField("operator", "foo").
+func FromField(path ...string) DecodeOption {
+	return func(o *decodeOptions) {
+		o.path = path
+	}
+}
+
+// WithDefaulting sets defaults on the decoded config, T must be the type the config is decoded into. Defaulters run in
+// the order they are given, after the decoding.
+func WithDefaulting[T any](defaulter func(config *T)) DecodeOption {
+	return func(o *decodeOptions) {
+		o.defaulters = append(o.defaulters, func(obj interface{}) error {
+			config, ok := obj.(*T)
+			if !ok {
+				return fmt.Errorf("defaulting of %T cannot be applied to %T", config, obj)
+			}
+			defaulter(config)
+			return nil
+		})
+	}
+}
+
+// DecodeComponentConfig decodes the unstructured component config of the ControllerContext, or a section of it, into
+// a typed config:
+//
+//	config, err := controllercmd.DecodeComponentConfig[FooConfig](controllerContext.ComponentConfig,
+//	  controllercmd.FromField("foo"),
+//	  controllercmd.Strict(),
+//	  controllercmd.WithDefaulting(func(c *FooConfig) { ... }),
+//	)
+//
+// A missing config or section decodes to the zero value of the type, with the defaults set.
+func DecodeComponentConfig[T any](config *unstructured.Unstructured, options ...DecodeOption) (*T, error) {
+	opts := &decodeOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+
+	ret := new(T)
+	var content interface{}
+	if config != nil {
+		content = config.Object
+	}
+	for i, field := range opts.path {
+		if content == nil {
+			break
+		}
+		section, ok := content.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s of the component config is not an object", strings.Join(opts.path[:i], "."))
+		}
+		content = section[field]
+	}
+
+	if content != nil {
+		data, err := json.Marshal(content)
+		if err != nil {
+			return nil, err
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		if opts.strict {
+			decoder.DisallowUnknownFields()
+		}
+		if err := decoder.Decode(ret); err != nil {
+			if len(opts.path) > 0 {
+				return nil, fmt.Errorf("unable to decode %s of the component config: %w", strings.Join(opts.path, "."), err)
+			}
+			return nil, fmt.Errorf("unable to decode the component config: %w", err)
+		}
+	}
+
+	for _, defaulter := range opts.defaulters {
+		if err := defaulter(ret); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
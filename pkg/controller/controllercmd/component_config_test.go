@@ -0,0 +1,98 @@
+package controllercmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type fooConfig struct {
+	Replicas int      `json:"replicas"`
+	Image    string   `json:"image"`
+	Args     []string `json:"args,omitempty"`
+}
+
+func TestDecodeComponentConfig(t *testing.T) {
+	config := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "operator.openshift.io/v1alpha1",
+		"kind":       "GenericOperatorConfig",
+		"foo": map[string]interface{}{
+			"replicas": int64(3),
+			"args":     []interface{}{"--v=2"},
+		},
+		"bar": "not an object",
+	}}
+	defaultImage := WithDefaulting(func(c *fooConfig) {
+		if len(c.Image) == 0 {
+			c.Image = "foo:latest"
+		}
+	})
+
+	tests := []struct {
+		name          string
+		config        *unstructured.Unstructured
+		options       []DecodeOption
+		expected      *fooConfig
+		expectedError string
+	}{
+		{
+			name:     "section with defaults",
+			config:   config,
+			options:  []DecodeOption{FromField("foo"), Strict(), defaultImage},
+			expected: &fooConfig{Replicas: 3, Image: "foo:latest", Args: []string{"--v=2"}},
+		},
+		{
+			name:     "whole config ignoring unknown fields",
+			config:   config,
+			expected: &fooConfig{},
+		},
+		{
+			name:          "whole config strict",
+			config:        config,
+			options:       []DecodeOption{Strict()},
+			expectedError: `unknown field "apiVersion"`,
+		},
+		{
+			name:     "missing section",
+			config:   config,
+			options:  []DecodeOption{FromField("baz", "foo"), defaultImage},
+			expected: &fooConfig{Image: "foo:latest"},
+		},
+		{
+			name:     "missing config",
+			options:  []DecodeOption{defaultImage},
+			expected: &fooConfig{Image: "foo:latest"},
+		},
+		{
+			name:          "section in a non-object",
+			config:        config,
+			options:       []DecodeOption{FromField("bar", "foo")},
+			expectedError: "bar of the component config is not an object",
+		},
+		{
+			name:          "defaulting of another type",
+			config:        config,
+			options:       []DecodeOption{WithDefaulting(func(c *ControllerFlags) {})},
+			expectedError: "cannot be applied",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := DecodeComponentConfig[fooConfig](test.config, test.options...)
+			if len(test.expectedError) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", test.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package controllercmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+const (
+	// diagnosticsPath serves the goroutine, mutex and block profiles while diagnostics are enabled.
+	diagnosticsPath = "/debug/diagnostics/"
+	// DiagnosticsDurationKey is the key of the enable configmap with the duration diagnostics stay enabled, e.g. "15m".
+	DiagnosticsDurationKey = "duration"
+
+	defaultDiagnosticsDuration = 10 * time.Minute
+	maxDiagnosticsDuration     = time.Hour
+	diagnosticsPollInterval    = 10 * time.Second
+)
+
+// diagnosticsProfiles are the profiles served under diagnosticsPath.
+var diagnosticsProfiles = []string{"goroutine", "mutex", "block"}
+
+// diagnosticsGate enables the diagnostics endpoint while the enable configmap exists, for the duration in the configmap
+// counted from its creation. Expired configmaps are deleted, so diagnostics cannot be left enabled by accident.
+type diagnosticsGate struct {
+	namespace string
+	name      string
+	client    coreclientv1.ConfigMapsGetter
+	recorder  events.Recorder
+	clock     clock.PassiveClock
+
+	lock         sync.RWMutex
+	enabledUntil time.Time
+}
+
+func newDiagnosticsGate(namespace, name string, client coreclientv1.ConfigMapsGetter, recorder events.Recorder) *diagnosticsGate {
+	return &diagnosticsGate{
+		namespace: namespace,
+		name:      name,
+		client:    client,
+		recorder:  recorder,
+		clock:     clock.RealClock{},
+	}
+}
+
+func (g *diagnosticsGate) run(ctx context.Context) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := g.sync(ctx); err != nil {
+			klog.Warningf("Failed to sync diagnostics configmap %s/%s: %v", g.namespace, g.name, err)
+		}
+	}, diagnosticsPollInterval)
+	g.setEnabledUntil(time.Time{})
+}
+
+func (g *diagnosticsGate) sync(ctx context.Context) error {
+	configMap, err := g.client.ConfigMaps(g.namespace).Get(ctx, g.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		g.setEnabledUntil(time.Time{})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	duration := defaultDiagnosticsDuration
+	if value, ok := configMap.Data[DiagnosticsDurationKey]; ok {
+		duration, err = time.ParseDuration(value)
+		if err != nil || duration <= 0 {
+			g.recorder.Warningf("DiagnosticsInvalidDuration", "Invalid %s %q in configmap %s/%s, using %s", DiagnosticsDurationKey, value, g.namespace, g.name, defaultDiagnosticsDuration)
+			duration = defaultDiagnosticsDuration
+		}
+	}
+	if duration > maxDiagnosticsDuration {
+		duration = maxDiagnosticsDuration
+	}
+
+	enabledUntil := configMap.CreationTimestamp.Add(duration)
+	if !g.clock.Now().Before(enabledUntil) {
+		g.setEnabledUntil(time.Time{})
+		err := g.client.ConfigMaps(g.namespace).Delete(ctx, g.name, metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &configMap.UID}})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	g.setEnabledUntil(enabledUntil)
+	return nil
+}
+
+// setEnabledUntil switches the mutex and block profiling on and off, they have a cost while enabled.
+func (g *diagnosticsGate) setEnabledUntil(enabledUntil time.Time) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	wasEnabled := !g.enabledUntil.IsZero()
+	enabled := !enabledUntil.IsZero()
+	g.enabledUntil = enabledUntil
+	switch {
+	case enabled && !wasEnabled:
+		runtime.SetMutexProfileFraction(1)
+		runtime.SetBlockProfileRate(1)
+		g.recorder.Warningf("DiagnosticsEnabled", "Diagnostics endpoint %s enabled by configmap %s/%s until %s", diagnosticsPath, g.namespace, g.name, enabledUntil.UTC().Format(time.RFC3339))
+	case !enabled && wasEnabled:
+		runtime.SetMutexProfileFraction(0)
+		runtime.SetBlockProfileRate(0)
+		g.recorder.Eventf("DiagnosticsDisabled", "Diagnostics endpoint %s disabled", diagnosticsPath)
+	}
+}
+
+func (g *diagnosticsGate) enabled() bool {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	return !g.enabledUntil.IsZero() && g.clock.Now().Before(g.enabledUntil)
+}
+
+// ServeHTTP serves the profiles while diagnostics are enabled, e.g. /debug/diagnostics/goroutine?debug=2.
+func (g *diagnosticsGate) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !g.enabled() {
+		http.Error(w, fmt.Sprintf("diagnostics are disabled, create configmap %s/%s to enable them", g.namespace, g.name), http.StatusForbidden)
+		return
+	}
+	name := r.URL.Path[len(diagnosticsPath):]
+	for _, profile := range diagnosticsProfiles {
+		if profile == name {
+			pprof.Handler(name).ServeHTTP(w, r)
+			return
+		}
+	}
+	http.Error(w, fmt.Sprintf("unknown profile %q, available profiles: %v", name, diagnosticsProfiles), http.StatusNotFound)
+}
@@ -0,0 +1,64 @@
+package controllercmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestDiagnosticsGate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := clocktesting.NewFakePassiveClock(now)
+	kubeClient := fake.NewSimpleClientset()
+	gate := newDiagnosticsGate("operator", "enable-diagnostics", kubeClient.CoreV1(), events.NewInMemoryRecorder("test"))
+	gate.clock = clock
+	defer gate.setEnabledUntil(time.Time{})
+
+	expectStatus := func(path string, expected int) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		gate.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != expected {
+			t.Errorf("expected %s to return %d, got %d: %s", path, expected, w.Code, w.Body.String())
+		}
+	}
+	sync := func() {
+		t.Helper()
+		if err := gate.sync(context.TODO()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sync()
+	expectStatus("/debug/diagnostics/goroutine", http.StatusForbidden)
+
+	if _, err := kubeClient.CoreV1().ConfigMaps("operator").Create(context.TODO(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "operator", Name: "enable-diagnostics", CreationTimestamp: metav1.NewTime(now)},
+		// capped to an hour
+		Data: map[string]string{DiagnosticsDurationKey: "2h"},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	sync()
+	expectStatus("/debug/diagnostics/goroutine", http.StatusOK)
+	expectStatus("/debug/diagnostics/mutex", http.StatusOK)
+	expectStatus("/debug/diagnostics/heap", http.StatusNotFound)
+
+	// the endpoint is disabled on expiry even before the next sync
+	clock.SetTime(now.Add(time.Hour))
+	expectStatus("/debug/diagnostics/goroutine", http.StatusForbidden)
+
+	sync()
+	if _, err := kubeClient.CoreV1().ConfigMaps("operator").Get(context.TODO(), "enable-diagnostics", metav1.GetOptions{}); err == nil {
+		t.Errorf("expected the expired configmap to be deleted")
+	}
+}
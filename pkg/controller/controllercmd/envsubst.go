@@ -0,0 +1,73 @@
+package controllercmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// envReferenceRegex matches ${ENV_VAR} references, optionally escaped with a leading $.
+var envReferenceRegex = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces ${ENV_VAR} references in the string values of the parsed config with values returned by
+// lookupEnv.  $${ENV_VAR} is replaced with a literal ${ENV_VAR}.  The values are substituted after the config is
+// parsed, so they are never interpreted as YAML: a value containing newlines, ": " or "#" stays part of the string
+// it is referenced in, and a string referencing a variable stays a string.  Keys are left as they are.
+// Referencing a variable that is unset or, when allowlist is not empty, not part of the allowlist is an error, so
+// that a misconfigured deployment fails instead of running with an empty value.
+func expandEnv(config map[string]interface{}, allowlist []string, lookupEnv func(string) (string, bool)) (map[string]interface{}, error) {
+	expander := &envExpander{allowed: sets.New(allowlist...), lookupEnv: lookupEnv}
+	ret := expander.expandValue(config).(map[string]interface{})
+	if len(expander.errs) > 0 {
+		return nil, fmt.Errorf("unable to expand environment variables: %v", expander.errs)
+	}
+	return ret, nil
+}
+
+type envExpander struct {
+	allowed   sets.Set[string]
+	lookupEnv func(string) (string, bool)
+	errs      []string
+}
+
+func (e *envExpander) expandValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			ret[key] = e.expandValue(item)
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, 0, len(value))
+		for _, item := range value {
+			ret = append(ret, e.expandValue(item))
+		}
+		return ret
+	case string:
+		return e.expandString(value)
+	default:
+		return value
+	}
+}
+
+func (e *envExpander) expandString(value string) string {
+	return envReferenceRegex.ReplaceAllStringFunc(value, func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+		name := envReferenceRegex.FindStringSubmatch(match)[1]
+		if e.allowed.Len() > 0 && !e.allowed.Has(name) {
+			e.errs = append(e.errs, fmt.Sprintf("environment variable %q is not allowed", name))
+			return match
+		}
+		value, ok := e.lookupEnv(name)
+		if !ok {
+			e.errs = append(e.errs, fmt.Sprintf("environment variable %q is not set", name))
+			return match
+		}
+		return value
+	})
+}
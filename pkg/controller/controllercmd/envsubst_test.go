@@ -0,0 +1,109 @@
+package controllercmd
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestExpandEnv(t *testing.T) {
+	env := map[string]string{
+		"ENDPOINT":  "https://example.com",
+		"TOKEN":     "secret",
+		"EMPTY":     "",
+		"MULTILINE": "line one\nline two: injected\n",
+		"COLON":     "value: injected # comment",
+	}
+	lookupEnv := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		name        string
+		content     string
+		allowlist   []string
+		expected    string
+		expectedErr bool
+	}{
+		{
+			name:     "no references",
+			content:  "a: b",
+			expected: "a: b",
+		},
+		{
+			name:     "substitution",
+			content:  "endpoint: ${ENDPOINT}/api\ntoken: ${TOKEN}\nempty: \"${EMPTY}\"",
+			expected: "endpoint: https://example.com/api\ntoken: secret\nempty: \"\"",
+		},
+		{
+			name:     "escaped",
+			content:  "literal: $${TOKEN}\nvalue: ${TOKEN}\ndollar: $TOKEN",
+			expected: "literal: ${TOKEN}\nvalue: secret\ndollar: $TOKEN",
+		},
+		{
+			name:        "unset",
+			content:     "a: ${MISSING}",
+			expectedErr: true,
+		},
+		{
+			name:      "allowed",
+			content:   "a: ${ENDPOINT}",
+			allowlist: []string{"ENDPOINT"},
+			expected:  "a: https://example.com",
+		},
+		{
+			name:        "not allowed",
+			content:     "a: ${ENDPOINT}\nb: ${TOKEN}",
+			allowlist:   []string{"ENDPOINT"},
+			expectedErr: true,
+		},
+		{
+			name:      "escaped not allowed",
+			content:   "a: $${TOKEN}",
+			allowlist: []string{"ENDPOINT"},
+			expected:  "a: ${TOKEN}",
+		},
+		{
+			name:     "nested",
+			content:  "a:\n  b: ${TOKEN}\n  c:\n  - ${ENDPOINT}\n  - 1\n${TOKEN}: d",
+			expected: "a:\n  b: secret\n  c:\n  - https://example.com\n  - 1\n${TOKEN}: d",
+		},
+		{
+			name:     "multi-line value",
+			content:  "a: ${MULTILINE}\nb: c",
+			expected: "a: \"line one\\nline two: injected\\n\"\nb: c",
+		},
+		{
+			name:     "value with colon",
+			content:  "a: prefix ${COLON}\nb: c",
+			expected: "a: \"prefix value: injected # comment\"\nb: c",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := map[string]interface{}{}
+			if err := yaml.Unmarshal([]byte(test.content), &config); err != nil {
+				t.Fatal(err)
+			}
+			actual, err := expandEnv(config, test.allowlist, lookupEnv)
+			if test.expectedErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := map[string]interface{}{}
+			if err := yaml.Unmarshal([]byte(test.expected), &expected); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected %v, got %v", expected, actual)
+			}
+		})
+	}
+}
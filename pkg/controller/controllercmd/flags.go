@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -12,14 +14,20 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
 	kyaml "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/rest"
 )
 
 // ControllerFlags provides the "normal" controller flags
 type ControllerFlags struct {
-	// ConfigFile hold the configfile to load
+	// ConfigFile hold the configfile to load. When ConfigFiles are also specified, ConfigFile is loaded first.
+	// The first --config flag sets it, so callers reading ConfigFile keep working with a single --config.
 	ConfigFile string
+	// ConfigFiles holds the config files or directories to load, in order. Later entries are deep-merged over earlier ones.
+	// The --config flags after the first one set it.
+	// Directories contribute all of their *.yaml, *.yml and *.json files in lexical order.
+	ConfigFiles []string
 	// KubeConfigFile points to a kubeconfig file if you don't want to use the in cluster config
 	KubeConfigFile string
 	// Namespace points to a base namespace for the controller and related events
@@ -28,6 +36,11 @@ type ControllerFlags struct {
 	BindAddress string
 	// TerminateOnFiles is a list of files. If any of these changes, the process terminates.
 	TerminateOnFiles []string
+
+	// ExpandEnv enables ${ENV_VAR} substitution in the string values of config files. Use $${ENV_VAR} for a literal ${ENV_VAR}.
+	ExpandEnv bool
+	// ExpandEnvAllowlist restricts ExpandEnv to the listed environment variables. Empty means every variable may be referenced.
+	ExpandEnvAllowlist []string
 }
 
 // NewControllerFlags returns flags with default values set
@@ -45,7 +58,7 @@ func (o *ControllerFlags) Validate() error {
 func (f *ControllerFlags) AddFlags(cmd *cobra.Command) {
 	flags := cmd.Flags()
 	// This command only supports reading from config
-	flags.StringVar(&f.ConfigFile, "config", f.ConfigFile, "Location of the master configuration file to run from.")
+	flags.Var(&configFilesValue{flags: f}, "config", "Location of the master configuration file or directory to run from. May be specified multiple times, later files are merged over earlier ones.")
 	cmd.MarkFlagFilename("config", "yaml", "yml")
 	flags.StringVar(&f.KubeConfigFile, "kubeconfig", f.KubeConfigFile, "Location of the master configuration file to run from.")
 	cmd.MarkFlagFilename("kubeconfig", "kubeconfig")
@@ -54,33 +67,166 @@ func (f *ControllerFlags) AddFlags(cmd *cobra.Command) {
 	flags.StringArrayVar(&f.TerminateOnFiles, "terminate-on-files", f.TerminateOnFiles, "A list of files. If one of them changes, the process will terminate.")
 }
 
+// configFilesValue binds the repeatable --config flag: the first value sets ConfigFile, the following ones are
+// appended to ConfigFiles.
+type configFilesValue struct {
+	flags *ControllerFlags
+	set   bool
+}
+
+func (v *configFilesValue) String() string {
+	if v.flags == nil {
+		return ""
+	}
+	values := []string{}
+	if len(v.flags.ConfigFile) > 0 {
+		values = append(values, v.flags.ConfigFile)
+	}
+	return strings.Join(append(values, v.flags.ConfigFiles...), ",")
+}
+
+func (v *configFilesValue) Set(value string) error {
+	if !v.set {
+		// the first value replaces the defaults
+		v.set = true
+		v.flags.ConfigFile = value
+		v.flags.ConfigFiles = nil
+		return nil
+	}
+	v.flags.ConfigFiles = append(v.flags.ConfigFiles, value)
+	return nil
+}
+
+func (v *configFilesValue) Type() string {
+	return "string"
+}
+
 // ToConfigObj given completed flags, returns a config object for the flag that was specified.
+// When more than one config file is specified, the files are deep-merged in order and the returned content is the
+// JSON serialization of the merged config.
 // TODO versions goes away in 1.11
 func (f *ControllerFlags) ToConfigObj() ([]byte, *unstructured.Unstructured, error) {
+	configFiles, err := f.ConfigFileNames()
+	if err != nil {
+		return nil, nil, err
+	}
 	// no file means empty, not err
-	if len(f.ConfigFile) == 0 {
+	if len(configFiles) == 0 {
 		return nil, nil, nil
 	}
 
-	content, err := os.ReadFile(f.ConfigFile)
-	if err != nil {
-		return nil, nil, err
+	var lastContent []byte
+	merged := map[string]interface{}{}
+	for _, configFile := range configFiles {
+		content, err := os.ReadFile(configFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		// empty file means empty, not err
+		if len(content) == 0 {
+			continue
+		}
+		lastContent = content
+
+		data, err := kyaml.ToJSON(content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not load config file %q due to an error: %v", configFile, err)
+		}
+		// only the merged result needs to be a complete object, overlays are allowed to omit apiVersion and kind
+		fileConfig := map[string]interface{}{}
+		if err := utiljson.Unmarshal(data, &fileConfig); err != nil {
+			return nil, nil, captureSurroundingJSONForError(fmt.Sprintf("could not load config file %q due to an error: ", configFile), data, err)
+		}
+		if f.ExpandEnv {
+			fileConfig, err = expandEnv(fileConfig, f.ExpandEnvAllowlist, os.LookupEnv)
+			if err != nil {
+				return nil, nil, fmt.Errorf("could not load config file %q due to an error: %v", configFile, err)
+			}
+		}
+		merged = mergeConfig(merged, fileConfig)
 	}
-	// empty file means empty, not err
-	if len(content) == 0 {
-		return nil, nil, err
+	if len(merged) == 0 {
+		return nil, nil, nil
 	}
 
-	data, err := kyaml.ToJSON(content)
+	content, err := json.Marshal(merged)
 	if err != nil {
 		return nil, nil, err
 	}
-	uncastObj, err := runtime.Decode(unstructured.UnstructuredJSONScheme, data)
+	// the merged config must be a complete object with apiVersion and kind
+	uncastObj, err := runtime.Decode(unstructured.UnstructuredJSONScheme, content)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, fmt.Errorf("could not load config files %v due to an error: %v", configFiles, err)
+	}
+	obj, ok := uncastObj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, nil, fmt.Errorf("could not load config files %v: expected an object, got %T", configFiles, uncastObj)
+	}
+
+	// a single file keeps its original, unexpanded content, so that it can be compared against the file on disk
+	if len(configFiles) == 1 {
+		return lastContent, obj, nil
+	}
+	return content, obj, nil
+}
+
+// ConfigFileNames returns the config files to load in merge order. Directories are expanded to the
+// *.yaml, *.yml and *.json files they contain, sorted lexically.
+func (f *ControllerFlags) ConfigFileNames() ([]string, error) {
+	locations := []string{}
+	if len(f.ConfigFile) > 0 {
+		locations = append(locations, f.ConfigFile)
 	}
+	locations = append(locations, f.ConfigFiles...)
 
-	return content, uncastObj.(*unstructured.Unstructured), nil
+	ret := []string{}
+	for _, location := range locations {
+		if len(location) == 0 {
+			continue
+		}
+		info, err := os.Stat(location)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			ret = append(ret, location)
+			continue
+		}
+		entries, err := os.ReadDir(location)
+		if err != nil {
+			return nil, err
+		}
+		// os.ReadDir returns entries sorted by filename
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			switch filepath.Ext(entry.Name()) {
+			case ".yaml", ".yml", ".json":
+				ret = append(ret, filepath.Join(location, entry.Name()))
+			}
+		}
+	}
+	return ret, nil
+}
+
+// mergeConfig deep-merges overlay into base and returns base. Nested maps are merged key by key, any other
+// value (scalars and lists) in overlay replaces the value in base. An explicit null in overlay removes the key.
+func mergeConfig(base, overlay map[string]interface{}) map[string]interface{} {
+	for key, overlayValue := range overlay {
+		if overlayValue == nil {
+			delete(base, key)
+			continue
+		}
+		overlayMap, overlayIsMap := overlayValue.(map[string]interface{})
+		baseMap, baseIsMap := base[key].(map[string]interface{})
+		if overlayIsMap && baseIsMap {
+			base[key] = mergeConfig(baseMap, overlayMap)
+			continue
+		}
+		base[key] = runtime.DeepCopyJSONValue(overlayValue)
+	}
+	return base
 }
 
 // ToClientConfig given completed flags, returns a rest.Config.  overrides are optional
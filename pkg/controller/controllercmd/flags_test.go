@@ -0,0 +1,176 @@
+package controllercmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestToConfigObjMerge(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	base := writeFile("base.yaml", `
+apiVersion: operator.openshift.io/v1alpha1
+kind: GenericOperatorConfig
+servingInfo:
+  bindAddress: 0.0.0.0:8443
+  cipherSuites:
+  - a
+  - b
+leaderElection:
+  namespace: base
+`)
+	writeFile("overrides/10-serving.yaml", `
+servingInfo:
+  cipherSuites:
+  - c
+`)
+	writeFile("overrides/20-leader.json", `{"leaderElection": {"namespace": "override", "name": "lock"}}`)
+	writeFile("overrides/README.md", `ignored`)
+	incomplete := writeFile("incomplete.yaml", `
+servingInfo:
+  bindAddress: 0.0.0.0:8443
+`)
+	removal := writeFile("removal.yaml", `
+servingInfo:
+  bindAddress: null
+`)
+
+	tests := []struct {
+		name     string
+		flags    *ControllerFlags
+		expected map[string]interface{}
+	}{
+		{
+			name:     "no config",
+			flags:    &ControllerFlags{},
+			expected: nil,
+		},
+		{
+			name:  "single file",
+			flags: &ControllerFlags{ConfigFiles: []string{base}},
+			expected: map[string]interface{}{
+				"apiVersion":     "operator.openshift.io/v1alpha1",
+				"kind":           "GenericOperatorConfig",
+				"servingInfo":    map[string]interface{}{"bindAddress": "0.0.0.0:8443", "cipherSuites": []interface{}{"a", "b"}},
+				"leaderElection": map[string]interface{}{"namespace": "base"},
+			},
+		},
+		{
+			name:  "file and directory",
+			flags: &ControllerFlags{ConfigFile: base, ConfigFiles: []string{filepath.Join(dir, "overrides")}},
+			expected: map[string]interface{}{
+				"apiVersion":     "operator.openshift.io/v1alpha1",
+				"kind":           "GenericOperatorConfig",
+				"servingInfo":    map[string]interface{}{"bindAddress": "0.0.0.0:8443", "cipherSuites": []interface{}{"c"}},
+				"leaderElection": map[string]interface{}{"namespace": "override", "name": "lock"},
+			},
+		},
+		{
+			name:  "null removes key",
+			flags: &ControllerFlags{ConfigFiles: []string{base, removal}},
+			expected: map[string]interface{}{
+				"apiVersion":     "operator.openshift.io/v1alpha1",
+				"kind":           "GenericOperatorConfig",
+				"servingInfo":    map[string]interface{}{"cipherSuites": []interface{}{"a", "b"}},
+				"leaderElection": map[string]interface{}{"namespace": "base"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, obj, err := test.flags.ToConfigObj()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var actual map[string]interface{}
+			if obj != nil {
+				actual = obj.Object
+			}
+			if !reflect.DeepEqual(test.expected, actual) {
+				t.Errorf("expected %#v, got %#v", test.expected, actual)
+			}
+		})
+	}
+
+	// overlays may omit apiVersion and kind, the merged config must not
+	if _, _, err := (&ControllerFlags{ConfigFiles: []string{incomplete, removal}}).ToConfigObj(); err == nil || !strings.Contains(err.Error(), "Kind") {
+		t.Errorf("expected an error about the missing kind of the merged config, got %v", err)
+	}
+}
+
+func TestConfigFileNames(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.yaml", "a.json", "c.yml", "d.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	flags := &ControllerFlags{ConfigFiles: []string{dir}}
+	actual, err := flags.ConfigFileNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{filepath.Join(dir, "a.json"), filepath.Join(dir, "b.yaml"), filepath.Join(dir, "c.yml")}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	flags = &ControllerFlags{ConfigFiles: []string{filepath.Join(dir, "missing.yaml")}}
+	if _, err := flags.ConfigFileNames(); err == nil {
+		t.Errorf("expected error for missing file")
+	}
+}
+
+func TestAddFlagsConfig(t *testing.T) {
+	tests := []struct {
+		name                string
+		args                []string
+		expectedConfigFile  string
+		expectedConfigFiles []string
+	}{
+		{
+			name: "no config",
+		},
+		{
+			name:               "single config",
+			args:               []string{"--config=base.yaml"},
+			expectedConfigFile: "base.yaml",
+		},
+		{
+			name:                "layered configs",
+			args:                []string{"--config=base.yaml", "--config", "overrides/", "--config=last.yaml"},
+			expectedConfigFile:  "base.yaml",
+			expectedConfigFiles: []string{"overrides/", "last.yaml"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flags := NewControllerFlags()
+			cmd := &cobra.Command{Use: "test", Run: func(*cobra.Command, []string) {}}
+			flags.AddFlags(cmd)
+			cmd.SetArgs(test.args)
+			if err := cmd.Execute(); err != nil {
+				t.Fatal(err)
+			}
+			if flags.ConfigFile != test.expectedConfigFile || !reflect.DeepEqual(flags.ConfigFiles, test.expectedConfigFiles) {
+				t.Errorf("expected %q and %v, got %q and %v", test.expectedConfigFile, test.expectedConfigFiles, flags.ConfigFile, flags.ConfigFiles)
+			}
+		})
+	}
+}
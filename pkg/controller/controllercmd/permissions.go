@@ -0,0 +1,125 @@
+package controllercmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authorizationclientv1 "k8s.io/client-go/kubernetes/typed/authorization/v1"
+)
+
+// RequiredPermission declares verbs the operator needs on a resource. An empty Namespace means the permission is
+// needed cluster wide.
+type RequiredPermission struct {
+	Verbs       []string
+	Group       string
+	Resource    string
+	Subresource string
+	Namespace   string
+}
+
+// IsClusterScoped returns true when the permission is needed cluster wide.
+func (p RequiredPermission) IsClusterScoped() bool {
+	return len(p.Namespace) == 0
+}
+
+func (p RequiredPermission) resourceString() string {
+	resource := p.Resource
+	if len(p.Subresource) > 0 {
+		resource = resource + "/" + p.Subresource
+	}
+	if len(p.Group) > 0 {
+		resource = p.Group + "/" + resource
+	}
+	return resource
+}
+
+// MissingPermission lists the verbs of a required permission the operator is not allowed to use.
+type MissingPermission struct {
+	RequiredPermission
+	MissingVerbs []string
+}
+
+func (m MissingPermission) String() string {
+	scope := "cluster wide"
+	if !m.IsClusterScoped() {
+		scope = fmt.Sprintf("in namespace %s", m.Namespace)
+	}
+	return fmt.Sprintf("%s on %s %s", strings.Join(m.MissingVerbs, ","), m.resourceString(), scope)
+}
+
+// CheckPermissions verifies every verb of the required permissions with a SelfSubjectAccessReview and returns the
+// ones that are not allowed.
+func CheckPermissions(ctx context.Context, client authorizationclientv1.SelfSubjectAccessReviewsGetter, required []RequiredPermission) ([]MissingPermission, error) {
+	var missing []MissingPermission
+	for _, permission := range required {
+		var missingVerbs []string
+		for _, verb := range permission.Verbs {
+			review, err := client.SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+				Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Namespace:   permission.Namespace,
+						Verb:        verb,
+						Group:       permission.Group,
+						Resource:    permission.Resource,
+						Subresource: permission.Subresource,
+					},
+				},
+			}, metav1.CreateOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("unable to check %s on %s: %w", verb, permission.resourceString(), err)
+			}
+			if !review.Status.Allowed {
+				missingVerbs = append(missingVerbs, verb)
+			}
+		}
+		if len(missingVerbs) > 0 {
+			missing = append(missing, MissingPermission{RequiredPermission: permission, MissingVerbs: missingVerbs})
+		}
+	}
+	return missing, nil
+}
+
+// MissingPermissionsMessage returns a sorted, human readable list of the missing permissions.
+func MissingPermissionsMessage(missing []MissingPermission) string {
+	lines := make([]string, 0, len(missing))
+	for _, m := range missing {
+		lines = append(lines, m.String())
+	}
+	sort.Strings(lines)
+	return "missing permissions: " + strings.Join(lines, "; ")
+}
+
+// PermissionsDegradedCondition returns a <conditionPrefix>PermissionsDegraded condition reporting the missing
+// permissions, for operators to put into their status.
+func PermissionsDegradedCondition(conditionPrefix string, missing []MissingPermission) operatorv1.OperatorCondition {
+	if len(missing) == 0 {
+		return operatorv1.OperatorCondition{
+			Type:   conditionPrefix + "PermissionsDegraded",
+			Status: operatorv1.ConditionFalse,
+			Reason: "AsExpected",
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    conditionPrefix + "PermissionsDegraded",
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "MissingPermissions",
+		Message: MissingPermissionsMessage(missing),
+	}
+}
+
+// filterNamespaceScoped drops the cluster scoped permissions, they are not needed when cluster scoped features are
+// disabled.
+func filterNamespaceScoped(required []RequiredPermission) []RequiredPermission {
+	var ret []RequiredPermission
+	for _, permission := range required {
+		if !permission.IsClusterScoped() {
+			ret = append(ret, permission)
+		}
+	}
+	return ret
+}
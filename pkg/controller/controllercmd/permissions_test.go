@@ -0,0 +1,90 @@
+package controllercmd
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// newAccessReviewClient returns a client allowing everything except the denied "verb group/resource" combinations.
+func newAccessReviewClient(denied ...string) *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview).DeepCopy()
+		attributes := review.Spec.ResourceAttributes
+		key := attributes.Verb + " " + attributes.Group + "/" + attributes.Resource
+		review.Status.Allowed = true
+		for _, d := range denied {
+			if d == key {
+				review.Status.Allowed = false
+			}
+		}
+		return true, review, nil
+	})
+	return client
+}
+
+func TestCheckPermissions(t *testing.T) {
+	client := newAccessReviewClient("update apps/deployments", "delete apps/deployments", "list /nodes")
+	required := []RequiredPermission{
+		{Verbs: []string{"get", "update", "delete"}, Group: "apps", Resource: "deployments", Namespace: "operand"},
+		{Verbs: []string{"get", "list"}, Resource: "nodes"},
+		{Verbs: []string{"get"}, Resource: "secrets", Namespace: "operand"},
+	}
+
+	missing, err := CheckPermissions(context.TODO(), client.AuthorizationV1(), required)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []MissingPermission{
+		{RequiredPermission: required[0], MissingVerbs: []string{"update", "delete"}},
+		{RequiredPermission: required[1], MissingVerbs: []string{"list"}},
+	}
+	if !reflect.DeepEqual(missing, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, missing)
+	}
+
+	condition := PermissionsDegradedCondition("Operator", missing)
+	expectedMessage := "missing permissions: list on nodes cluster wide; update,delete on apps/deployments in namespace operand"
+	if condition.Status != operatorv1.ConditionTrue || condition.Message != expectedMessage {
+		t.Errorf("unexpected condition %#v", condition)
+	}
+	if condition := PermissionsDegradedCondition("Operator", nil); condition.Status != operatorv1.ConditionFalse {
+		t.Errorf("expected no degraded condition, got %#v", condition)
+	}
+}
+
+func TestCheckPermissionsNamespaceScoped(t *testing.T) {
+	client := newAccessReviewClient("list /nodes")
+	b := NewController("test", nil).
+		WithRequiredPermissions(
+			RequiredPermission{Verbs: []string{"list"}, Resource: "nodes"},
+			RequiredPermission{Verbs: []string{"get"}, Resource: "secrets", Namespace: "operand"},
+		)
+
+	recorder := events.NewInMemoryRecorder("test")
+	missing, err := b.checkPermissions(context.TODO(), client, recorder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 1 || len(recorder.Events()) != 1 {
+		t.Errorf("expected the cluster scoped permission to be missing, got %#v", missing)
+	}
+
+	recorder = events.NewInMemoryRecorder("test")
+	missing, err = b.WithNamespaceScoped().checkPermissions(context.TODO(), client, recorder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 0 || len(recorder.Events()) != 0 {
+		t.Errorf("expected cluster scoped permissions to be ignored, got %#v", missing)
+	}
+}
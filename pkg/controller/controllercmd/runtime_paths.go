@@ -0,0 +1,50 @@
+package controllercmd
+
+import (
+	"os"
+	"strings"
+)
+
+const (
+	defaultServingCertDir = "/var/run/secrets/serving-cert"
+	defaultNamespaceFile  = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// RuntimePaths are the paths the controller process reads and writes while it runs. The empty paths default to those of
+// a pod, so operators running as non root, outside of a cluster or on Windows only set the paths they need, e.g. in a
+// development environment:
+//
+//	RuntimePaths{ServingCertDir: filepath.Join(home, "serving-cert"), TempDir: filepath.Join(home, "tmp")}
+type RuntimePaths struct {
+	// ServingCertDir holds the tls.crt and tls.key serving certificate, /var/run/secrets/serving-cert by default.
+	ServingCertDir string
+	// TempDir is where the self-signed serving certificate is generated when ServingCertDir has none, the default
+	// temporary directory of the OS by default.
+	TempDir string
+	// NamespaceFile holds the namespace the component runs in, used when the namespace of the events or of the leader
+	// election lock is not set, /var/run/secrets/kubernetes.io/serviceaccount/namespace by default.
+	NamespaceFile string
+}
+
+// withDefaults returns the paths with the empty ones defaulted.
+func (p RuntimePaths) withDefaults() RuntimePaths {
+	if len(p.ServingCertDir) == 0 {
+		p.ServingCertDir = defaultServingCertDir
+	}
+	if len(p.TempDir) == 0 {
+		p.TempDir = os.TempDir()
+	}
+	if len(p.NamespaceFile) == 0 {
+		p.NamespaceFile = defaultNamespaceFile
+	}
+	return p
+}
+
+// readNamespace returns the namespace in the namespace file.
+func (p RuntimePaths) readNamespace() (string, error) {
+	data, err := os.ReadFile(p.withDefaults().NamespaceFile)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,50 @@
+package controllercmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
+	"k8s.io/apimachinery/pkg/version"
+)
+
+func TestRuntimePaths(t *testing.T) {
+	dir := t.TempDir()
+	paths := RuntimePaths{
+		ServingCertDir: filepath.Join(dir, "serving-cert"),
+		TempDir:        filepath.Join(dir, "tmp"),
+		NamespaceFile:  filepath.Join(dir, "namespace"),
+	}
+	if err := os.Mkdir(paths.TempDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(paths.NamespaceFile, []byte("openshift-foo\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	namespace, err := NewController("foo", nil).WithRuntimePaths(paths).getComponentNamespace()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if namespace != "openshift-foo" {
+		t.Errorf("expected the namespace of the namespace file, got %q", namespace)
+	}
+
+	// without a serving certificate, a self-signed one is generated in the temporary directory
+	config := &operatorv1alpha1.GenericOperatorConfig{}
+	_, observedFiles, err := NewControllerCommandConfig("foo", version.Info{}, nil).WithRuntimePaths(paths).AddDefaultRotationToConfig(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(config.ServingInfo.CertFile, paths.TempDir) || !strings.HasPrefix(config.ServingInfo.KeyFile, paths.TempDir) {
+		t.Errorf("expected the self-signed certificate in %s, got %s and %s", paths.TempDir, config.ServingInfo.CertFile, config.ServingInfo.KeyFile)
+	}
+	if _, err := os.Stat(config.ServingInfo.CertFile); err != nil {
+		t.Error(err)
+	}
+	if len(observedFiles) != 2 || observedFiles[0] != filepath.Join(paths.ServingCertDir, "tls.crt") {
+		t.Errorf("expected the serving certificate to be observed, got %v", observedFiles)
+	}
+}
@@ -0,0 +1,63 @@
+package controllercmd
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/component-base/tracing"
+	tracingapiv1 "k8s.io/component-base/tracing/api/v1"
+	"k8s.io/klog/v2"
+)
+
+// TracingConfigFromConfig reads the optional "tracing" stanza of the operator config, e.g.
+//
+//	tracing:
+//	  endpoint: otel-collector.openshift-monitoring.svc:4317
+//	  samplingRatePerMillion: 10000
+//
+// It returns nil if the config does not enable tracing.
+func TracingConfigFromConfig(config *unstructured.Unstructured) (*tracingapiv1.TracingConfiguration, error) {
+	if config == nil {
+		return nil, nil
+	}
+	tracingStanza, found, err := unstructured.NestedMap(config.Object, "tracing")
+	if err != nil {
+		return nil, fmt.Errorf("invalid tracing configuration: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	tracingConfig := &tracingapiv1.TracingConfiguration{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(tracingStanza, tracingConfig); err != nil {
+		return nil, fmt.Errorf("invalid tracing configuration: %w", err)
+	}
+	return tracingConfig, nil
+}
+
+// startTracing creates an OTLP exporting tracer provider, makes it the global one used for the spans of the
+// controller syncs, and wraps the client config so the API calls are recorded as child spans.
+// The tracer provider is shut down, flushing the pending spans, when the context is done.
+func (b *ControllerBuilder) startTracing(ctx context.Context, clientConfig *rest.Config) (tracing.TracerProvider, error) {
+	tracerProvider, err := tracing.NewProvider(ctx, b.tracingConfig, nil, []resource.Option{
+		resource.WithAttributes(semconv.ServiceName(b.componentName)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create tracer provider: %w", err)
+	}
+	otel.SetTracerProvider(tracerProvider)
+	clientConfig.Wrap(tracing.WrapperFor(tracerProvider))
+
+	go func() {
+		<-ctx.Done()
+		if err := tracerProvider.Shutdown(context.Background()); err != nil {
+			klog.Warningf("Failed to shut down the tracer provider: %v", err)
+		}
+	}()
+	return tracerProvider, nil
+}
@@ -0,0 +1,39 @@
+package controllercmd
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestTracingConfigFromConfig(t *testing.T) {
+	tracingConfig, err := TracingConfigFromConfig(&unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "GenericOperatorConfig",
+	}})
+	if err != nil || tracingConfig != nil {
+		t.Fatalf("expected tracing to be disabled, got %v: %v", tracingConfig, err)
+	}
+
+	tracingConfig, err = TracingConfigFromConfig(&unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "GenericOperatorConfig",
+		"tracing": map[string]interface{}{
+			"endpoint":               "collector:4317",
+			"samplingRatePerMillion": int64(10000),
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tracingConfig.Endpoint == nil || *tracingConfig.Endpoint != "collector:4317" {
+		t.Errorf("unexpected endpoint %v", tracingConfig.Endpoint)
+	}
+	if tracingConfig.SamplingRatePerMillion == nil || *tracingConfig.SamplingRatePerMillion != 10000 {
+		t.Errorf("unexpected sampling rate %v", tracingConfig.SamplingRatePerMillion)
+	}
+
+	if _, err := TracingConfigFromConfig(&unstructured.Unstructured{Object: map[string]interface{}{
+		"tracing": "enabled",
+	}}); err == nil {
+		t.Errorf("expected an invalid tracing stanza to fail")
+	}
+}
@@ -10,14 +10,17 @@ import (
 	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
 
 	"github.com/robfig/cron"
+	oteltrace "go.opentelemetry.io/otel/trace"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/management"
 	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
 )
@@ -28,6 +31,9 @@ var SyntheticRequeueError = errors.New("synthetic requeue request")
 
 var defaultCacheSyncTimeout = 10 * time.Minute
 
+// pausedRequeueInterval is how often the keys skipped while the controller is paused are checked again.
+var pausedRequeueInterval = 30 * time.Second
+
 // baseController represents generic Kubernetes controller boiler-plate
 type baseController struct {
 	name                   string
@@ -40,10 +46,55 @@ type baseController struct {
 	resyncSchedules        []cron.Schedule
 	postStartHooks         []PostStartHook
 	cacheSyncTimeout       time.Duration
+	informerTracker        *InformerTracker
+	healthRegistry         *HealthRegistry
+	tracerProvider         oteltrace.TracerProvider
+	clock                  clock.WithTicker
+	hotloopDetector        *HotloopDetector
+	pauseGate              PauseGate
+
+	// handlerRegistrations are the event handlers of the controller, removed when it stops
+	handlerRegistrations []handlerRegistration
+}
+
+// handlerRegistration is an event handler added to an informer.
+type handlerRegistration struct {
+	informer     Informer
+	registration cache.ResourceEventHandlerRegistration
+}
+
+// eventHandlerRemover is implemented by the shared informers, which can remove the handlers of a stopped controller.
+type eventHandlerRemover interface {
+	RemoveEventHandler(handle cache.ResourceEventHandlerRegistration) error
 }
 
 var _ Controller = &baseController{}
 
+// addEventHandler adds the event handler to the informer and remembers it to remove it when the controller stops.
+func (c *baseController) addEventHandler(informer Informer, handler cache.ResourceEventHandler) {
+	registration, err := informer.AddEventHandler(handler)
+	if err != nil {
+		klog.Warningf("Unable to add the event handler of %s controller: %v", c.name, err)
+		return
+	}
+	c.handlerRegistrations = append(c.handlerRegistrations, handlerRegistration{informer: informer, registration: registration})
+}
+
+// removeEventHandlers removes the event handlers of the controller from the informers supporting it, so a controller
+// stopped and constructed again, e.g. by a controller.RuntimeControllerManager, does not leave its handlers behind.
+func (c *baseController) removeEventHandlers() {
+	for _, handler := range c.handlerRegistrations {
+		remover, ok := handler.informer.(eventHandlerRemover)
+		if !ok || handler.registration == nil {
+			continue
+		}
+		if err := remover.RemoveEventHandler(handler.registration); err != nil {
+			klog.Warningf("Unable to remove the event handler of %s controller: %v", c.name, err)
+		}
+	}
+	c.handlerRegistrations = nil
+}
+
 // Name returns a controller name.
 func (c baseController) Name() string {
 	return c.name
@@ -85,13 +136,19 @@ func waitForNamedCacheSync(controllerName string, stopCh <-chan struct{}, cacheS
 }
 
 func (c *baseController) Run(ctx context.Context, workers int) {
+	defer c.removeEventHandlers()
 	// HandleCrash recovers panics
 	defer utilruntime.HandleCrash(c.degradedPanicHandler)
 
 	// give caches 10 minutes to sync
 	cacheSyncCtx, cacheSyncCancel := context.WithTimeout(ctx, c.cacheSyncTimeout)
 	defer cacheSyncCancel()
-	err := waitForNamedCacheSync(c.name, cacheSyncCtx.Done(), c.cachesToSync...)
+	var err error
+	if c.informerTracker != nil {
+		err = c.informerTracker.waitForCacheSync(cacheSyncCtx, c.name, c.cachesToSync...)
+	} else {
+		err = waitForNamedCacheSync(c.name, cacheSyncCtx.Done(), c.cachesToSync...)
+	}
 	if err != nil {
 		select {
 		case <-ctx.Done():
@@ -112,6 +169,10 @@ func (c *baseController) Run(ctx context.Context, workers int) {
 		workerWg.Wait()
 	}()
 
+	if c.healthRegistry != nil {
+		c.healthRegistry.started(c.name)
+	}
+
 	// queueContext is used to track and initiate queue shutdown
 	queueContext, queueContextCancel := context.WithCancel(context.TODO())
 
@@ -147,7 +208,7 @@ func (c *baseController) Run(ctx context.Context, workers int) {
 		}
 		go func() {
 			defer workerWg.Done()
-			wait.UntilWithContext(ctx, func(ctx context.Context) { c.syncContext.Queue().Add(DefaultQueueKey) }, c.resyncEvery)
+			c.runPeriodicalResync(ctx)
 		}()
 	}
 
@@ -181,6 +242,24 @@ func (c *baseController) Run(ctx context.Context, workers int) {
 	klog.Infof("Shutting down %s ...", c.name)
 }
 
+// runPeriodicalResync queues a sync right away and then every resync interval until the context is done.
+func (c *baseController) runPeriodicalResync(ctx context.Context) {
+	var resyncClock clock.WithTicker = clock.RealClock{}
+	if c.clock != nil {
+		resyncClock = c.clock
+	}
+	ticker := resyncClock.NewTicker(c.resyncEvery)
+	defer ticker.Stop()
+	for {
+		c.syncContext.Queue().Add(DefaultQueueKey)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
 func (c *baseController) Sync(ctx context.Context, syncCtx SyncContext) error {
 	return c.sync(ctx, syncCtx)
 }
@@ -260,28 +339,53 @@ func (c *baseController) processNextWorkItem(queueCtx context.Context) {
 	}
 	defer c.syncContext.Queue().Done(key)
 
-	syncCtx := c.syncContext.(syncContext)
-	var ok bool
-	syncCtx.queueKey, ok = key.(string)
+	queueKey, ok := key.(string)
 	if !ok {
 		utilruntime.HandleError(fmt.Errorf("%q controller failed to process key %q (not a string)", c.name, key))
 		return
 	}
+	syncCtx, syncContext := c.syncContext.(syncContext).forSync(queueCtx, c.name, c.controllerInstanceName, queueKey)
+	if c.pauseGate != nil && c.pauseGate.Paused() {
+		klog.FromContext(syncCtx).V(2).Info("Skipping sync, the reconciliation is paused")
+		if c.healthRegistry != nil {
+			c.healthRegistry.recordPaused(c.name)
+		}
+		c.syncContext.Queue().Forget(key)
+		c.syncContext.Queue().AddAfter(key, pausedRequeueInterval)
+		return
+	}
+	klog.FromContext(syncCtx).V(5).Info("Syncing")
+
+	backoff := false
+	if c.hotloopDetector != nil {
+		backoff = c.hotloopDetector.observeSync(c.name, queueKey, syncContext.Recorder())
+	}
 
-	if err := c.reconcile(queueCtx, syncCtx); err != nil {
+	correlationID := events.CorrelationIDFromContext(syncCtx)
+	syncCtx, span := c.startSyncSpan(syncCtx, queueKey, correlationID)
+	err := c.reconcile(syncCtx, syncContext)
+	endSyncSpan(span, err)
+	if c.healthRegistry != nil {
+		c.healthRegistry.recordSync(c.name, err)
+	}
+	if err != nil {
 		if err == SyntheticRequeueError {
 			// logging this helps detecting wedged controllers with missing pre-requirements
 			klog.V(5).Infof("%q controller requested synthetic requeue with key %q", c.name, key)
 		} else {
 			if klog.V(4).Enabled() || key != "key" {
-				utilruntime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", c.name, key, err))
+				utilruntime.HandleError(fmt.Errorf("%q controller failed to sync %q (correlation ID %s), err: %w", c.name, key, correlationID, err))
 			} else {
-				utilruntime.HandleError(fmt.Errorf("%s reconciliation failed: %w", c.name, err))
+				utilruntime.HandleError(fmt.Errorf("%s reconciliation failed (correlation ID %s): %w", c.name, correlationID, err))
 			}
 		}
 		c.syncContext.Queue().AddRateLimited(key)
 		return
 	}
 
+	if backoff {
+		// keep the rate limiting of the hotlooping key, so its changes are queued with an increasing delay
+		return
+	}
 	c.syncContext.Queue().Forget(key)
 }
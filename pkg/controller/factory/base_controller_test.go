@@ -5,13 +5,17 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr/funcr"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/events/eventstesting"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 )
@@ -184,3 +188,102 @@ func TestBaseController_Run(t *testing.T) {
 		t.Errorf("expected the post start hook to be terminated when context is cancelled")
 	}
 }
+
+func TestBaseController_SyncCorrelationID(t *testing.T) {
+	recorder := events.NewInMemoryRecorder("test")
+	var correlationIDs []string
+	c := &baseController{
+		name:        "test",
+		syncContext: NewSyncContext("test", recorder),
+		sync: func(ctx context.Context, syncCtx SyncContext) error {
+			correlationIDs = append(correlationIDs, events.CorrelationIDFromContext(ctx))
+			syncCtx.Recorder().Eventf("Synced", "synced %s", syncCtx.QueueKey())
+			return nil
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		c.syncContext.Queue().Add(DefaultQueueKey)
+		c.processNextWorkItem(context.TODO())
+	}
+
+	if len(correlationIDs) != 2 || len(correlationIDs[0]) == 0 || correlationIDs[0] == correlationIDs[1] {
+		t.Fatalf("expected a unique correlation ID per sync, got %v", correlationIDs)
+	}
+	for i, event := range recorder.Events() {
+		if event.Message != "synced key" {
+			t.Errorf("expected the event message to be left as it is, got %q", event.Message)
+		}
+		if id := event.Annotations[events.CorrelationIDAnnotation]; id != correlationIDs[i] {
+			t.Errorf("expected event %q to be annotated with correlation ID %q, got %q", event.Message, correlationIDs[i], id)
+		}
+	}
+}
+
+func TestBaseController_SyncLogger(t *testing.T) {
+	var lines []string
+	logger := funcr.New(func(prefix, args string) {
+		lines = append(lines, prefix+" "+args)
+	}, funcr.Options{})
+
+	c := &baseController{
+		name:                   "TestController",
+		controllerInstanceName: "test-instance",
+		syncContext:            NewSyncContext("TestController", events.NewInMemoryRecorder("test")),
+		sync: func(ctx context.Context, syncCtx SyncContext) error {
+			klog.FromContext(ctx).Info("from context")
+			return nil
+		},
+	}
+	c.syncContext.Queue().Add("some-key")
+	c.processNextWorkItem(klog.NewContext(context.TODO(), logger))
+
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 log line, got %v", lines)
+	}
+	for _, line := range lines {
+		for _, expected := range []string{`TestController "level"=0`, `"controller"="TestController"`, `"controllerInstance"="test-instance"`, `"key"="some-key"`, `"correlationID"=`} {
+			if !strings.Contains(line, expected) {
+				t.Errorf("expected %q in log line %q", expected, line)
+			}
+		}
+	}
+}
+
+type removableInformer struct {
+	fakeInformer
+	removed []cache.ResourceEventHandlerRegistration
+}
+
+type fakeRegistration struct {
+	cache.ResourceEventHandlerRegistration
+	id int
+}
+
+func (f *removableInformer) AddEventHandler(handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error) {
+	f.fakeInformer.AddEventHandler(handler)
+	return &fakeRegistration{id: f.addEventHandlerCount}, nil
+}
+
+func (f *removableInformer) RemoveEventHandler(handle cache.ResourceEventHandlerRegistration) error {
+	f.removed = append(f.removed, handle)
+	return nil
+}
+
+func TestBaseController_RemovesEventHandlersOnStop(t *testing.T) {
+	informer := &removableInformer{}
+	c := New().
+		WithInformers(informer).
+		WithSync(func(ctx context.Context, syncCtx SyncContext) error { return nil }).
+		ToController("test", eventstesting.NewTestingEventRecorder(t))
+	if len(informer.removed) != 0 {
+		t.Fatal("expected the event handler to be kept until the controller stops")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.Run(ctx, 1)
+	if len(informer.removed) != 1 || informer.removed[0].(*fakeRegistration).id != 1 {
+		t.Errorf("expected the event handler to be removed when the controller stops, got %v", informer.removed)
+	}
+}
@@ -1,6 +1,7 @@
 package factory
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -10,6 +11,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	"github.com/openshift/library-go/pkg/operator/events"
 )
@@ -20,14 +23,26 @@ type syncContext struct {
 	eventRecorder events.Recorder
 	queue         workqueue.RateLimitingInterface
 	queueKey      string
+
+	// hotloopDetector, if set, is told about the updates queueing keys of the controller named hotloopController
+	hotloopDetector   *HotloopDetector
+	hotloopController string
 }
 
 var _ SyncContext = syncContext{}
 
 // NewSyncContext gives new sync context.
 func NewSyncContext(name string, recorder events.Recorder) SyncContext {
+	return newSyncContext(name, recorder, clock.RealClock{})
+}
+
+// newSyncContext gives new sync context with a queue delaying requeues by the clock.
+func newSyncContext(name string, recorder events.Recorder, clock clock.WithTicker) SyncContext {
 	return syncContext{
-		queue:         workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), name),
+		queue: workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultControllerRateLimiter(), workqueue.RateLimitingQueueConfig{
+			Name:  name,
+			Clock: clock,
+		}),
 		eventRecorder: recorder.WithComponentSuffix(strings.ToLower(name)),
 	}
 }
@@ -44,6 +59,22 @@ func (c syncContext) Recorder() events.Recorder {
 	return c.eventRecorder
 }
 
+// forSync returns a copy of the sync context for a sync of the queue key, with a new correlation ID, and the
+// context of the sync carrying the correlation ID and the logger of the sync.
+func (c syncContext) forSync(ctx context.Context, controllerName, controllerInstanceName, queueKey string) (context.Context, syncContext) {
+	c.queueKey = queueKey
+	correlationID := events.NewCorrelationID()
+	c.eventRecorder = events.WithCorrelationID(c.eventRecorder, correlationID)
+
+	ctx = events.ContextWithCorrelationID(ctx, correlationID)
+	logger := klog.FromContext(ctx).WithName(controllerName).WithValues("controller", controllerName)
+	if len(controllerInstanceName) > 0 {
+		logger = logger.WithValues("controllerInstance", controllerInstanceName)
+	}
+	logger = logger.WithValues("key", queueKey, "correlationID", correlationID)
+	return klog.NewContext(ctx, logger), c
+}
+
 // eventHandler provides default event handler that is added to an informers passed to controller factory.
 func (c syncContext) eventHandler(queueKeysFunc ObjectQueueKeysFunc, filter EventFilterFunc) cache.ResourceEventHandler {
 	resourceEventHandler := cache.ResourceEventHandlerFuncs{
@@ -61,7 +92,18 @@ func (c syncContext) eventHandler(queueKeysFunc ObjectQueueKeysFunc, filter Even
 				utilruntime.HandleError(fmt.Errorf("updated object %+v is not runtime Object", runtimeObj))
 				return
 			}
-			c.enqueueKeys(queueKeysFunc(runtimeObj)...)
+			if c.hotloopDetector == nil {
+				c.enqueueKeys(queueKeysFunc(runtimeObj)...)
+				return
+			}
+			for _, qKey := range queueKeysFunc(runtimeObj) {
+				c.hotloopDetector.recordChange(c.hotloopController, qKey, old, new)
+				if c.hotloopDetector.backingOff(c.hotloopController, qKey) {
+					c.queue.AddRateLimited(qKey)
+				} else {
+					c.queue.Add(qKey)
+				}
+			}
 		},
 		DeleteFunc: func(obj interface{}) {
 			runtimeObj, ok := obj.(runtime.Object)
@@ -1,9 +1,12 @@
 package factory
 
 import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
 )
 
 func ObjectNameToKey(obj runtime.Object) string {
@@ -24,3 +27,67 @@ func NamesFilter(names ...string) EventFilterFunc {
 		return nameSet.Has(metaObj.GetObjectMeta().GetName())
 	}
 }
+
+// updateFilteringHandler passes all events to the handler except the updates rejected by the updateFilter.
+type updateFilteringHandler struct {
+	updateFilter UpdateFilterFunc
+	handler      cache.ResourceEventHandler
+}
+
+func (h updateFilteringHandler) OnAdd(obj interface{}, isInInitialList bool) {
+	h.handler.OnAdd(obj, isInInitialList)
+}
+
+func (h updateFilteringHandler) OnUpdate(old, new interface{}) {
+	if !h.updateFilter(old, new) {
+		return
+	}
+	h.handler.OnUpdate(old, new)
+}
+
+func (h updateFilteringHandler) OnDelete(obj interface{}) {
+	h.handler.OnDelete(obj)
+}
+
+// StatusOnlyUpdatesFilter ignores updates that only changed the status or the server maintained metadata
+// (resourceVersion, managedFields) of an object.
+func StatusOnlyUpdatesFilter(old, new interface{}) bool {
+	oldContent, oldErr := withoutStatus(old)
+	newContent, newErr := withoutStatus(new)
+	if oldErr != nil || newErr != nil {
+		return true
+	}
+	return !equality.Semantic.DeepEqual(oldContent, newContent)
+}
+
+func withoutStatus(obj interface{}) (map[string]interface{}, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	delete(content, "status")
+	if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "resourceVersion")
+		delete(metadata, "managedFields")
+	}
+	return content, nil
+}
+
+// LabelsUpdateFilter only passes updates that changed the value of any of the given label keys.
+func LabelsUpdateFilter(keys ...string) UpdateFilterFunc {
+	return func(old, new interface{}) bool {
+		oldMeta, oldErr := meta.Accessor(old)
+		newMeta, newErr := meta.Accessor(new)
+		if oldErr != nil || newErr != nil {
+			return true
+		}
+		for _, key := range keys {
+			oldValue, oldOk := oldMeta.GetLabels()[key]
+			newValue, newOk := newMeta.GetLabels()[key]
+			if oldOk != newOk || oldValue != newValue {
+				return true
+			}
+		}
+		return false
+	}
+}
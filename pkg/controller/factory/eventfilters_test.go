@@ -0,0 +1,73 @@
+package factory
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestStatusOnlyUpdatesFilter(t *testing.T) {
+	old := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", ResourceVersion: "1"},
+		Spec:       corev1.PodSpec{NodeName: "a"},
+	}
+	statusOnly := old.DeepCopy()
+	statusOnly.ResourceVersion = "2"
+	statusOnly.Status.Phase = corev1.PodRunning
+	specChange := old.DeepCopy()
+	specChange.Spec.NodeName = "b"
+	labelChange := old.DeepCopy()
+	labelChange.Labels = map[string]string{"foo": "bar"}
+
+	if StatusOnlyUpdatesFilter(old, statusOnly) {
+		t.Errorf("expected status only update to be filtered")
+	}
+	if !StatusOnlyUpdatesFilter(old, specChange) {
+		t.Errorf("expected spec update to pass")
+	}
+	if !StatusOnlyUpdatesFilter(old, labelChange) {
+		t.Errorf("expected label update to pass")
+	}
+}
+
+func TestLabelsUpdateFilter(t *testing.T) {
+	filter := LabelsUpdateFilter("app")
+	old := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Labels: map[string]string{"app": "a", "other": "a"}}}
+	unrelated := old.DeepCopy()
+	unrelated.Labels["other"] = "b"
+	related := old.DeepCopy()
+	related.Labels["app"] = "b"
+	removed := old.DeepCopy()
+	delete(removed.Labels, "app")
+
+	if filter(old, unrelated) {
+		t.Errorf("expected unrelated label update to be filtered")
+	}
+	if !filter(old, related) {
+		t.Errorf("expected related label update to pass")
+	}
+	if !filter(old, removed) {
+		t.Errorf("expected label removal to pass")
+	}
+}
+
+func TestUpdateFilteringHandler(t *testing.T) {
+	var adds, updates, deletes int
+	handler := updateFilteringHandler{
+		updateFilter: func(old, new interface{}) bool { return new.(string) == "pass" },
+		handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(interface{}) { adds++ },
+			UpdateFunc: func(interface{}, interface{}) { updates++ },
+			DeleteFunc: func(interface{}) { deletes++ },
+		},
+	}
+	handler.OnAdd("obj", false)
+	handler.OnUpdate("obj", "drop")
+	handler.OnUpdate("obj", "pass")
+	handler.OnDelete("obj")
+	if adds != 1 || updates != 1 || deletes != 1 {
+		t.Errorf("expected one event of each kind, got adds=%d updates=%d deletes=%d", adds, updates, deletes)
+	}
+}
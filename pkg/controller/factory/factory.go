@@ -6,9 +6,11 @@ import (
 	"time"
 
 	"github.com/robfig/cron"
+	oteltrace "go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/runtime"
 	errorutil "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
 
 	"github.com/openshift/library-go/pkg/operator/events"
 	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
@@ -30,6 +32,7 @@ type Factory struct {
 	syncDegradedClient     operatorv1helpers.OperatorClient
 	resyncInterval         time.Duration
 	resyncSchedules        []string
+	syncSchedules          []syncSchedule
 	informers              []filteredInformers
 	informerQueueKeys      []informersWithQueueKey
 	bareInformers          []Informer
@@ -37,6 +40,12 @@ type Factory struct {
 	namespaceInformers     []*namespaceInformer
 	cachesToSync           []cache.InformerSynced
 	controllerInstanceName string
+	informerTracker        *InformerTracker
+	healthRegistry         *HealthRegistry
+	tracerProvider         oteltrace.TracerProvider
+	clock                  clock.WithTicker
+	hotloopDetector        *HotloopDetector
+	pauseGate              PauseGate
 }
 
 // Informer represents any structure that allow to register event handlers and informs if caches are synced.
@@ -58,8 +67,9 @@ type informersWithQueueKey struct {
 }
 
 type filteredInformers struct {
-	informers []Informer
-	filter    EventFilterFunc
+	informers    []Informer
+	filter       EventFilterFunc
+	updateFilter UpdateFilterFunc
 }
 
 // PostStartHook specify a function that will run after controller is started.
@@ -81,6 +91,10 @@ type ObjectQueueKeysFunc func(runtime.Object) []string
 // EventFilterFunc is used to filter informer events to prevent Sync() from being called
 type EventFilterFunc func(obj interface{}) bool
 
+// UpdateFilterFunc is used to filter informer update events to prevent Sync() from being called for irrelevant
+// changes, like status only updates. It returns true when the update should trigger Sync().
+type UpdateFilterFunc func(old, new interface{}) bool
+
 // New return new factory instance.
 func New() *Factory {
 	return &Factory{}
@@ -113,6 +127,18 @@ func (f *Factory) WithFilteredEventsInformers(filter EventFilterFunc, informers
 	return f
 }
 
+// WithInformersFiltered is used to register event handlers and get the caches synchronized functions.
+// Pass the informers you want to use to react to changes on resources. Add and delete events always call Sync(),
+// updates only when the updateFilter returns true for them. This allows to ignore status only changes or changes of
+// unrelated labels before they hit the queue, see StatusOnlyUpdatesFilter and LabelsUpdateFilter.
+func (f *Factory) WithInformersFiltered(updateFilter UpdateFilterFunc, informers ...Informer) *Factory {
+	f.informers = append(f.informers, filteredInformers{
+		informers:    informers,
+		updateFilter: updateFilter,
+	})
+	return f
+}
+
 // WithBareInformers allow to register informer that already has custom event handlers registered and no additional
 // event handlers will be added to this informer.
 // The controller will wait for the cache of this informer to be synced.
@@ -223,6 +249,22 @@ func (f *Factory) ResyncSchedule(schedules ...string) *Factory {
 	return f
 }
 
+// WithSyncSchedule schedules the sync() call runs at predictable times, for periodic maintenance like certificate
+// sweeps, pruning or report generation. Unlike ResyncSchedule, the standard cron schedule is in UTC unless it starts
+// with a CRON_TZ= time zone, and every run is delayed by a random jitter up to maxJitter, so the replicas of many
+// operators do not all sync at once. The jitter must not be negative and must be shorter than the interval between
+// two runs, or ToController panics.
+// Examples:
+//
+// factory.New().WithSyncSchedule("0 3 * * *", 10*time.Minute).ToController()            // Every day between 03:00 and 03:10 UTC
+// factory.New().WithSyncSchedule("CRON_TZ=Europe/Prague 0 3 * * 0", 0).ToController()  // Every Sunday at 03:00 in Prague
+//
+// Note: Like with ResyncSchedule, the controller context passed to Sync() function does not contain any object.
+func (f *Factory) WithSyncSchedule(schedule string, maxJitter time.Duration) *Factory {
+	f.syncSchedules = append(f.syncSchedules, syncSchedule{spec: schedule, maxJitter: maxJitter})
+	return f
+}
+
 // WithSyncContext allows to specify custom, existing sync context for this factory.
 // This is useful during unit testing where you can override the default event recorder or mock the runtime objects.
 // If this function not called, a SyncContext is created by the factory automatically.
@@ -245,21 +287,74 @@ func (f *Factory) WithControllerInstanceName(controllerInstanceName string) *Fac
 	return f
 }
 
+// WithInformerTracker records all informers given to this factory in the tracker under the controller name.
+// The controller then waits for its caches through the tracker, which reports cache sync metrics and informers
+// registered after the informers were started.
+func (f *Factory) WithInformerTracker(tracker *InformerTracker) *Factory {
+	f.informerTracker = tracker
+	return f
+}
+
+// WithHealthRegistry reports the result of every sync of the controller to the registry.
+func (f *Factory) WithHealthRegistry(registry *HealthRegistry) *Factory {
+	f.healthRegistry = registry
+	return f
+}
+
+// WithClock sets the clock driving the periodical resyncs and the delayed requeues of the controller, so tests can
+// simulate their timing with a fake clock instead of sleeping. It is ignored for the queue of a sync context given
+// by WithSyncContext.
+func (f *Factory) WithClock(clock clock.WithTicker) *Factory {
+	f.clock = clock
+	return f
+}
+
+// WithHotloopDetector reports the controller when it keeps re-syncing a queue key because a watched object changes
+// before every sync, see HotloopDetector. Only the informers with event handlers added by the factory are watched.
+func (f *Factory) WithHotloopDetector(detector *HotloopDetector) *Factory {
+	f.hotloopDetector = detector
+	return f
+}
+
+// PauseGate tells whether the reconciliation is paused, e.g. management.PauseGate.
+type PauseGate interface {
+	Paused() bool
+}
+
+// WithPauseGate skips the syncs of the controller while the gate is paused, so the controller does not write anything.
+// The skipped keys are checked again every 30 seconds and synced once the gate is no longer paused.
+func (f *Factory) WithPauseGate(gate PauseGate) *Factory {
+	f.pauseGate = gate
+	return f
+}
+
 // Controller produce a runnable controller.
 func (f *Factory) ToController(name string, eventRecorder events.Recorder) Controller {
 	if f.sync == nil {
 		panic(fmt.Errorf("WithSync() must be used before calling ToController() in %q", name))
 	}
 
+	controllerClock := f.clock
+	if controllerClock == nil {
+		controllerClock = clock.RealClock{}
+	}
+
 	var ctx SyncContext
 	if f.syncContext != nil {
 		ctx = f.syncContext
 	} else {
-		ctx = NewSyncContext(name, eventRecorder)
+		ctx = newSyncContext(name, eventRecorder, controllerClock)
+	}
+	if f.hotloopDetector != nil {
+		if sc, ok := ctx.(syncContext); ok {
+			sc.hotloopDetector = f.hotloopDetector
+			sc.hotloopController = name
+			ctx = sc
+		}
 	}
 
 	var cronSchedules []cron.Schedule
-	if len(f.resyncSchedules) > 0 {
+	if len(f.resyncSchedules) > 0 || len(f.syncSchedules) > 0 {
 		var errors []error
 		for _, schedule := range f.resyncSchedules {
 			if s, err := cron.ParseStandard(schedule); err != nil {
@@ -268,6 +363,13 @@ func (f *Factory) ToController(name string, eventRecorder events.Recorder) Contr
 				cronSchedules = append(cronSchedules, s)
 			}
 		}
+		for _, schedule := range f.syncSchedules {
+			if s, err := parseSyncSchedule(schedule); err != nil {
+				errors = append(errors, err)
+			} else {
+				cronSchedules = append(cronSchedules, s)
+			}
+		}
 		if err := errorutil.NewAggregate(errors); err != nil {
 			panic(fmt.Errorf("failed to parse controller schedules for %q: %v", name, err))
 		}
@@ -284,13 +386,19 @@ func (f *Factory) ToController(name string, eventRecorder events.Recorder) Contr
 		syncContext:            ctx,
 		postStartHooks:         f.postStartHooks,
 		cacheSyncTimeout:       defaultCacheSyncTimeout,
+		informerTracker:        f.informerTracker,
+		healthRegistry:         f.healthRegistry,
+		tracerProvider:         f.tracerProvider,
+		clock:                  controllerClock,
+		hotloopDetector:        f.hotloopDetector,
+		pauseGate:              f.pauseGate,
 	}
 
 	for i := range f.informerQueueKeys {
 		for d := range f.informerQueueKeys[i].informers {
 			informer := f.informerQueueKeys[i].informers[d]
 			queueKeyFn := f.informerQueueKeys[i].queueKeyFn
-			informer.AddEventHandler(c.syncContext.(syncContext).eventHandler(queueKeyFn, f.informerQueueKeys[i].filter))
+			c.addEventHandler(informer, c.syncContext.(syncContext).eventHandler(queueKeyFn, f.informerQueueKeys[i].filter))
 			c.cachesToSync = append(c.cachesToSync, informer.HasSynced)
 		}
 	}
@@ -298,7 +406,11 @@ func (f *Factory) ToController(name string, eventRecorder events.Recorder) Contr
 	for i := range f.informers {
 		for d := range f.informers[i].informers {
 			informer := f.informers[i].informers[d]
-			informer.AddEventHandler(c.syncContext.(syncContext).eventHandler(DefaultQueueKeysFunc, f.informers[i].filter))
+			handler := c.syncContext.(syncContext).eventHandler(DefaultQueueKeysFunc, f.informers[i].filter)
+			if f.informers[i].updateFilter != nil {
+				handler = updateFilteringHandler{updateFilter: f.informers[i].updateFilter, handler: handler}
+			}
+			c.addEventHandler(informer, handler)
 			c.cachesToSync = append(c.cachesToSync, informer.HasSynced)
 		}
 	}
@@ -308,9 +420,32 @@ func (f *Factory) ToController(name string, eventRecorder events.Recorder) Contr
 	}
 
 	for i := range f.namespaceInformers {
-		f.namespaceInformers[i].informer.AddEventHandler(c.syncContext.(syncContext).eventHandler(DefaultQueueKeysFunc, f.namespaceInformers[i].nsFilter))
+		c.addEventHandler(f.namespaceInformers[i].informer, c.syncContext.(syncContext).eventHandler(DefaultQueueKeysFunc, f.namespaceInformers[i].nsFilter))
 		c.cachesToSync = append(c.cachesToSync, f.namespaceInformers[i].informer.HasSynced)
 	}
 
+	if f.informerTracker != nil {
+		f.informerTracker.Track(name, f.trackedInformers()...)
+	}
+	if f.healthRegistry != nil {
+		f.healthRegistry.register(name, f.resyncInterval, f.syncDegradedClient != nil)
+	}
+
 	return c
 }
+
+// trackedInformers returns all informers given to the factory.
+func (f *Factory) trackedInformers() []Informer {
+	var informers []Informer
+	for i := range f.informerQueueKeys {
+		informers = append(informers, f.informerQueueKeys[i].informers...)
+	}
+	for i := range f.informers {
+		informers = append(informers, f.informers[i].informers...)
+	}
+	informers = append(informers, f.bareInformers...)
+	for i := range f.namespaceInformers {
+		informers = append(informers, f.namespaceInformers[i].informer)
+	}
+	return informers
+}
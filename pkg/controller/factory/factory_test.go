@@ -14,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
 
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/events/eventstesting"
@@ -121,6 +122,43 @@ func TestResyncController(t *testing.T) {
 	}
 }
 
+func TestResyncControllerWithClock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+
+	syncs := make(chan struct{}, 10)
+	controller := New().ResyncEvery(time.Hour).WithClock(fakeClock).WithSync(func(ctx context.Context, controllerContext SyncContext) error {
+		syncs <- struct{}{}
+		return nil
+	}).ToController("PeriodicController", events.NewInMemoryRecorder("periodic-controller"))
+	go controller.Run(ctx, 1)
+
+	waitForSync := func() {
+		select {
+		case <-syncs:
+		case <-time.After(10 * time.Second):
+			t.Fatal("controller did not sync")
+		}
+	}
+	// the first resync is queued right away
+	waitForSync()
+	for i := 0; i < 2; i++ {
+		if err := wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, 10*time.Second, true, func(context.Context) (bool, error) {
+			return fakeClock.HasWaiters(), nil
+		}); err != nil {
+			t.Fatal("resync ticker not started")
+		}
+		select {
+		case <-syncs:
+			t.Fatal("unexpected sync before the resync interval passed")
+		default:
+		}
+		fakeClock.Step(time.Hour)
+		waitForSync()
+	}
+}
+
 func TestMultiWorkerControllerShutdown(t *testing.T) {
 	controllerCtx, shutdown := context.WithCancel(context.TODO())
 	factory := New().ResyncEvery(10 * time.Minute) // make sure we only call 1 sync manually
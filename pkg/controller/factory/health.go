@@ -0,0 +1,213 @@
+package factory
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+)
+
+const (
+	// defaultFailingThreshold is how long a controller may keep failing before it is reported unhealthy.
+	defaultFailingThreshold = 10 * time.Minute
+	// resyncMissedFactor is the number of missed resyncs after which a controller is considered wedged.
+	resyncMissedFactor = 3
+)
+
+// ControllerHealth describes the health of a single controller.
+type ControllerHealth struct {
+	Name string `json:"name"`
+	// Started is true once the controller workers are running.
+	Started bool `json:"started"`
+	// LastSyncTime is the time the last sync finished.
+	LastSyncTime time.Time `json:"lastSyncTime,omitempty"`
+	// LastSuccessfulSyncTime is the time the last successful sync finished.
+	LastSuccessfulSyncTime time.Time `json:"lastSuccessfulSyncTime,omitempty"`
+	// LastError is the error of the last sync, empty if it succeeded.
+	LastError string `json:"lastError,omitempty"`
+	// ConsecutiveFailures is the number of syncs failed since the last successful sync.
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+	// Degraded is true when the last sync failed and the controller reports a Degraded condition.
+	Degraded bool `json:"degraded"`
+	// Paused is true when the last sync was skipped because the reconciliation is paused.
+	Paused bool `json:"paused"`
+	// Healthy is false when the controller is failing or wedged, Reason explains why.
+	Healthy bool   `json:"healthy"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+type controllerHealthState struct {
+	ControllerHealth
+	resyncEvery   time.Duration
+	reportsStatus bool
+	startTime     time.Time
+	firstFailure  time.Time
+}
+
+// HealthRegistry collects the health of all controllers built by factories using the registry. A single registry is
+// meant to be shared by all controllers of a process, so one probe can tell which of them is failing or wedged.
+// A controller is unhealthy when its syncs keep failing for longer than the failing threshold, or when it has a
+// resync interval and did not finish a sync for several intervals.
+type HealthRegistry struct {
+	lock             sync.Mutex
+	controllers      map[string]*controllerHealthState
+	failingThreshold time.Duration
+
+	clock clock.PassiveClock
+}
+
+// NewHealthRegistry returns an empty HealthRegistry.
+func NewHealthRegistry() *HealthRegistry {
+	return &HealthRegistry{
+		controllers:      map[string]*controllerHealthState{},
+		failingThreshold: defaultFailingThreshold,
+		clock:            clock.RealClock{},
+	}
+}
+
+// WithClock sets the clock the sync times and the failing and resync thresholds are measured with.
+func (r *HealthRegistry) WithClock(clock clock.PassiveClock) *HealthRegistry {
+	r.clock = clock
+	return r
+}
+
+// WithFailingThreshold sets how long a controller may keep failing before it is reported unhealthy.
+func (r *HealthRegistry) WithFailingThreshold(threshold time.Duration) *HealthRegistry {
+	r.failingThreshold = threshold
+	return r
+}
+
+func (r *HealthRegistry) register(name string, resyncEvery time.Duration, reportsStatus bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.controllers[name] = &controllerHealthState{
+		ControllerHealth: ControllerHealth{Name: name},
+		resyncEvery:      resyncEvery,
+		reportsStatus:    reportsStatus,
+	}
+}
+
+func (r *HealthRegistry) started(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if state, ok := r.controllers[name]; ok {
+		state.Started = true
+		state.startTime = r.clock.Now()
+	}
+}
+
+// recordSync records a finished sync. A SyntheticRequeueError is a requested retry, not a failure.
+func (r *HealthRegistry) recordSync(name string, err error) {
+	if err == SyntheticRequeueError {
+		err = nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	state, ok := r.controllers[name]
+	if !ok {
+		return
+	}
+	now := r.clock.Now()
+	state.LastSyncTime = now
+	state.Paused = false
+	if err == nil {
+		state.LastSuccessfulSyncTime = now
+		state.LastError = ""
+		state.ConsecutiveFailures = 0
+		state.firstFailure = time.Time{}
+		state.Degraded = false
+		return
+	}
+	if state.ConsecutiveFailures == 0 {
+		state.firstFailure = now
+	}
+	state.LastError = err.Error()
+	state.ConsecutiveFailures++
+	state.Degraded = state.reportsStatus
+}
+
+// recordPaused records a sync skipped because the reconciliation is paused. Paused controllers are not expected to
+// sync, so they are neither failing nor wedged until they sync again.
+func (r *HealthRegistry) recordPaused(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if state, ok := r.controllers[name]; ok {
+		state.Paused = true
+	}
+}
+
+// Health returns the health of all registered controllers sorted by name.
+func (r *HealthRegistry) Health() []ControllerHealth {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	now := r.clock.Now()
+	result := make([]ControllerHealth, 0, len(r.controllers))
+	for _, state := range r.controllers {
+		health := state.ControllerHealth
+		health.Healthy, health.Reason = r.evaluate(state, now)
+		result = append(result, health)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func (r *HealthRegistry) evaluate(state *controllerHealthState, now time.Time) (bool, string) {
+	if !state.Started {
+		return true, "NotStarted"
+	}
+	if state.Paused {
+		return true, "Paused"
+	}
+	if state.ConsecutiveFailures > 0 && now.Sub(state.firstFailure) > r.failingThreshold {
+		return false, fmt.Sprintf("sync failing for %v (%d times): %s", now.Sub(state.firstFailure).Round(time.Second), state.ConsecutiveFailures, state.LastError)
+	}
+	if state.resyncEvery > 0 {
+		lastActivity := state.LastSyncTime
+		if lastActivity.IsZero() {
+			lastActivity = state.startTime
+		}
+		if maxAge := resyncMissedFactor * state.resyncEvery; now.Sub(lastActivity) > maxAge {
+			return false, fmt.Sprintf("no sync finished for %v, expected every %v", now.Sub(lastActivity).Round(time.Second), state.resyncEvery)
+		}
+	}
+	return true, ""
+}
+
+// Name implements the healthz.HealthChecker interface.
+func (r *HealthRegistry) Name() string {
+	return "controllers"
+}
+
+// Check implements the healthz.HealthChecker interface. It fails when any controller is unhealthy.
+func (r *HealthRegistry) Check(_ *http.Request) error {
+	var unhealthy []string
+	for _, health := range r.Health() {
+		if !health.Healthy {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s: %s", health.Name, health.Reason))
+		}
+	}
+	if len(unhealthy) > 0 {
+		return fmt.Errorf("unhealthy controllers:\n%s", strings.Join(unhealthy, "\n"))
+	}
+	return nil
+}
+
+// ServeHTTP serves the health of all controllers as JSON. The response status is 503 if any controller is unhealthy.
+func (r *HealthRegistry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	health := r.Health()
+	status := http.StatusOK
+	for i := range health {
+		if !health[i].Healthy {
+			status = http.StatusServiceUnavailable
+			break
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(health)
+}
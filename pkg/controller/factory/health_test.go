@@ -0,0 +1,124 @@
+package factory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestHealthRegistry(t *testing.T) {
+	fakeClock := clocktesting.NewFakePassiveClock(time.Now())
+	registry := NewHealthRegistry()
+	registry.clock = fakeClock
+
+	registry.register("resyncing", time.Minute, false)
+	registry.register("failing", 0, true)
+	registry.register("idle", 0, false)
+	for _, name := range []string{"resyncing", "failing", "idle"} {
+		registry.started(name)
+	}
+
+	registry.recordSync("resyncing", nil)
+	registry.recordSync("failing", fmt.Errorf("boom"))
+	if err := registry.Check(nil); err != nil {
+		t.Fatalf("expected all controllers to be healthy, got %v", err)
+	}
+
+	fakeClock.SetTime(fakeClock.Now().Add(11 * time.Minute))
+	registry.recordSync("failing", fmt.Errorf("boom again"))
+
+	health := map[string]ControllerHealth{}
+	for _, h := range registry.Health() {
+		health[h.Name] = h
+	}
+	if h := health["resyncing"]; h.Healthy || !strings.Contains(h.Reason, "no sync finished") {
+		t.Errorf("expected the controller missing its resyncs to be unhealthy, got %#v", h)
+	}
+	if h := health["failing"]; h.Healthy || !h.Degraded || h.ConsecutiveFailures != 2 || h.LastError != "boom again" {
+		t.Errorf("expected the failing controller to be unhealthy and degraded, got %#v", h)
+	}
+	if h := health["idle"]; !h.Healthy {
+		t.Errorf("expected the idle controller without resync to be healthy, got %#v", h)
+	}
+	if err := registry.Check(nil); err == nil || !strings.Contains(err.Error(), "failing: sync failing") {
+		t.Errorf("expected the check to report the failing controller, got %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	registry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/controllers", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", recorder.Code)
+	}
+	var served []ControllerHealth
+	if err := json.Unmarshal(recorder.Body.Bytes(), &served); err != nil || len(served) != 3 {
+		t.Errorf("expected the health of 3 controllers, got %s: %v", recorder.Body.String(), err)
+	}
+
+	registry.recordSync("resyncing", nil)
+	registry.recordSync("failing", nil)
+	if err := registry.Check(nil); err != nil {
+		t.Errorf("expected the controllers to recover, got %v", err)
+	}
+}
+
+func TestHealthRegistrySyntheticRequeue(t *testing.T) {
+	fakeClock := clocktesting.NewFakePassiveClock(time.Now())
+	registry := NewHealthRegistry()
+	registry.clock = fakeClock
+
+	registry.register("requeueing", 0, true)
+	registry.started("requeueing")
+	registry.recordSync("requeueing", SyntheticRequeueError)
+	fakeClock.SetTime(fakeClock.Now().Add(11 * time.Minute))
+	registry.recordSync("requeueing", SyntheticRequeueError)
+
+	h := registry.Health()[0]
+	if !h.Healthy || h.Degraded || h.ConsecutiveFailures != 0 || len(h.LastError) > 0 || !h.LastSuccessfulSyncTime.Equal(fakeClock.Now()) {
+		t.Errorf("expected synthetic requeues to count as successful syncs, got %#v", h)
+	}
+}
+
+func TestHealthRegistryPaused(t *testing.T) {
+	fakeClock := clocktesting.NewFakePassiveClock(time.Now())
+	registry := NewHealthRegistry()
+	registry.clock = fakeClock
+
+	registry.register("paused", time.Minute, true)
+	registry.started("paused")
+	registry.recordSync("paused", fmt.Errorf("boom"))
+	registry.recordPaused("paused")
+	fakeClock.SetTime(fakeClock.Now().Add(11 * time.Minute))
+
+	if h := registry.Health()[0]; !h.Healthy || !h.Paused || h.Reason != "Paused" {
+		t.Errorf("expected the paused controller to be healthy, got %#v", h)
+	}
+
+	// once it syncs again, it is evaluated as usual
+	registry.recordSync("paused", fmt.Errorf("boom again"))
+	fakeClock.SetTime(fakeClock.Now().Add(11 * time.Minute))
+	if h := registry.Health()[0]; h.Healthy || h.Paused {
+		t.Errorf("expected the resumed controller to be unhealthy, got %#v", h)
+	}
+}
+
+func TestFactoryRegistersHealth(t *testing.T) {
+	registry := NewHealthRegistry()
+	New().WithSync(func(ctx context.Context, syncContext SyncContext) error { return nil }).
+		ResyncEvery(time.Minute).
+		WithHealthRegistry(registry).
+		ToController("test", events.NewInMemoryRecorder("test"))
+
+	health := registry.Health()
+	if len(health) != 1 || health[0].Name != "test" || health[0].Started {
+		t.Errorf("expected the controller to be registered, got %#v", health)
+	}
+}
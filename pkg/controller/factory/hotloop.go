@@ -0,0 +1,240 @@
+package factory
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+const (
+	// hotloopWindow is the period the syncs of a key are counted in.
+	hotloopWindow = time.Minute
+	// maxHotloopDiffLength limits the size of the changed fields reported in the hotloop event.
+	maxHotloopDiffLength = 2048
+)
+
+var hotloopsMetric = metrics.NewCounterVec(&metrics.CounterOpts{
+	Subsystem:      "controller_factory",
+	Name:           "hotloops_total",
+	Help:           "Number of times a controller was detected to re-sync a key over and over, changing a watched object each time.",
+	StabilityLevel: metrics.ALPHA,
+}, []string{"controller"})
+
+func init() {
+	(&sync.Once{}).Do(func() {
+		legacyregistry.MustRegister(hotloopsMetric)
+	})
+}
+
+// HotloopDetector notices controllers that re-sync the same queue key more often than the threshold per minute,
+// with a watched object changing before each of the syncs. This is usually a controller fighting with another actor,
+// or with itself, over a field, e.g. writing a status with a new timestamp on every sync. A hotlooping controller is
+// reported by a warning event with the fields changed by the last change, at most once per minute and key. The event
+// never contains the values of the fields.
+//
+// A single detector can be shared by all controllers of a process.
+type HotloopDetector struct {
+	lock      sync.Mutex
+	threshold int
+	backoff   bool
+	clock     clock.PassiveClock
+	keys      map[hotloopKey]*hotloopState
+}
+
+type hotloopKey struct {
+	controller, queueKey string
+}
+
+type hotloopState struct {
+	// changed is true when a watched object changed since the last sync of the key
+	changed bool
+	// lastOld and lastNew are the last change of a watched object
+	lastOld, lastNew interface{}
+	// syncs are the times of the syncs following a change within the window
+	syncs        []time.Time
+	hotlooping   bool
+	lastReported time.Time
+}
+
+// NewHotloopDetector returns a detector reporting keys synced more than threshold times per minute.
+func NewHotloopDetector(threshold int) *HotloopDetector {
+	return &HotloopDetector{
+		threshold: threshold,
+		clock:     clock.RealClock{},
+		keys:      map[hotloopKey]*hotloopState{},
+	}
+}
+
+// WithBackoff makes hotlooping keys back off: changes queue them rate limited instead of right away, and a successful
+// sync does not reset their rate limiting, so the syncs get slower the longer the controller keeps hotlooping.
+func (d *HotloopDetector) WithBackoff() *HotloopDetector {
+	d.backoff = true
+	return d
+}
+
+// WithClock sets the clock the syncs are counted with.
+func (d *HotloopDetector) WithClock(clock clock.PassiveClock) *HotloopDetector {
+	d.clock = clock
+	return d
+}
+
+// recordChange records an update of a watched object queueing the key. Updates of the informer resyncs do not change
+// the object and are ignored.
+func (d *HotloopDetector) recordChange(controller, queueKey string, old, new interface{}) {
+	oldMeta, oldErr := meta.Accessor(old)
+	newMeta, newErr := meta.Accessor(new)
+	if oldErr == nil && newErr == nil && oldMeta.GetResourceVersion() == newMeta.GetResourceVersion() {
+		return
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	key := hotloopKey{controller: controller, queueKey: queueKey}
+	state, ok := d.keys[key]
+	if !ok {
+		state = &hotloopState{}
+		d.keys[key] = state
+	}
+	state.changed = true
+	state.lastOld, state.lastNew = old, new
+}
+
+// backingOff returns true when the key is hotlooping and the detector backs off.
+func (d *HotloopDetector) backingOff(controller, queueKey string) bool {
+	if !d.backoff {
+		return false
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	state, ok := d.keys[hotloopKey{controller: controller, queueKey: queueKey}]
+	return ok && state.hotlooping
+}
+
+// observeSync is called at the start of every sync of the key. It reports the controller when the key is hotlooping
+// and returns true if the key should back off.
+func (d *HotloopDetector) observeSync(controller, queueKey string, recorder events.Recorder) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	key := hotloopKey{controller: controller, queueKey: queueKey}
+	state, ok := d.keys[key]
+	if !ok {
+		return false
+	}
+	if !state.changed {
+		// a sync without a change, e.g. a resync, ends a hotloop
+		delete(d.keys, key)
+		return false
+	}
+
+	now := d.clock.Now()
+	state.changed = false
+	state.syncs = append(state.syncs, now)
+	for len(state.syncs) > 0 && now.Sub(state.syncs[0]) > hotloopWindow {
+		state.syncs = state.syncs[1:]
+	}
+	state.hotlooping = len(state.syncs) > d.threshold
+	if !state.hotlooping {
+		return false
+	}
+
+	if state.lastReported.IsZero() || now.Sub(state.lastReported) >= hotloopWindow {
+		state.lastReported = now
+		hotloopsMetric.WithLabelValues(controller).Inc()
+		recorder.Warningf("ControllerHotloop", "Controller %q synced key %q %d times within %v, each time after a watched object changed. Last change:\n%s",
+			controller, queueKey, len(state.syncs), hotloopWindow, hotloopDiff(state.lastOld, state.lastNew))
+	}
+	return d.backoff
+}
+
+// hotloopDiff returns the object and the paths of the fields changed between the objects, ignoring the metadata
+// changing with every update. It never returns field values, the data of secrets is not even broken down by key.
+func hotloopDiff(old, new interface{}) string {
+	oldContent, oldErr := runtime.DefaultUnstructuredConverter.ToUnstructured(old)
+	newContent, newErr := runtime.DefaultUnstructuredConverter.ToUnstructured(new)
+	if oldErr != nil || newErr != nil {
+		return fmt.Sprintf("unable to compare %T objects", new)
+	}
+	for _, content := range []map[string]interface{}{oldContent, newContent} {
+		if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+			delete(metadata, "resourceVersion")
+			delete(metadata, "managedFields")
+		}
+	}
+
+	kind := hotloopObjectKind(new, newContent)
+	var opaque sets.Set[string]
+	if kind == "Secret" {
+		opaque = sets.New("data", "stringData")
+	}
+	var changed []string
+	hotloopChangedFields("", oldContent, newContent, opaque, &changed)
+	sort.Strings(changed)
+
+	name := ""
+	if metadata, err := meta.Accessor(new); err == nil {
+		name = metadata.GetName()
+		if len(metadata.GetNamespace()) > 0 {
+			name = metadata.GetNamespace() + "/" + name
+		}
+	}
+	diff := fmt.Sprintf("%s %q changed %s", kind, name, strings.Join(changed, ", "))
+	if len(diff) > maxHotloopDiffLength {
+		end := maxHotloopDiffLength
+		for end > 0 && !utf8.RuneStart(diff[end]) {
+			end--
+		}
+		diff = diff[:end] + "..."
+	}
+	return diff
+}
+
+func hotloopObjectKind(obj interface{}, content map[string]interface{}) string {
+	if kind, ok := content["kind"].(string); ok && len(kind) > 0 {
+		return kind
+	}
+	t := reflect.TypeOf(obj)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return "object"
+	}
+	return t.Name()
+}
+
+// hotloopChangedFields appends the paths of the fields differing between old and new to changed. The fields in
+// opaque are reported as a whole, without their keys.
+func hotloopChangedFields(prefix string, old, new map[string]interface{}, opaque sets.Set[string], changed *[]string) {
+	keys := sets.KeySet(old).Union(sets.KeySet(new))
+	for _, key := range sets.List(keys) {
+		path := key
+		if len(prefix) > 0 {
+			path = prefix + "." + key
+		}
+		oldValue, newValue := old[key], new[key]
+		if equality.Semantic.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		oldMap, oldIsMap := oldValue.(map[string]interface{})
+		newMap, newIsMap := newValue.(map[string]interface{})
+		if oldIsMap && newIsMap && !opaque.Has(path) {
+			hotloopChangedFields(path, oldMap, newMap, opaque, changed)
+			continue
+		}
+		*changed = append(*changed, path)
+	}
+}
@@ -0,0 +1,119 @@
+package factory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestHotloopDetector(t *testing.T) {
+	configMap := func(resourceVersion int) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cm", ResourceVersion: fmt.Sprintf("%d", resourceVersion)},
+			Data:       map[string]string{"timestamp": fmt.Sprintf("%d", resourceVersion)},
+		}
+	}
+
+	scenarios := []struct {
+		name           string
+		changes        int
+		interval       time.Duration
+		resync         bool
+		expectedEvents int
+	}{
+		{
+			name:     "syncs below the threshold",
+			changes:  3,
+			interval: time.Second,
+		},
+		{
+			name:           "syncs above the threshold",
+			changes:        5,
+			interval:       time.Second,
+			expectedEvents: 1,
+		},
+		{
+			name:     "syncs spread over more than the window",
+			changes:  5,
+			interval: 30 * time.Second,
+		},
+		{
+			name:     "informer resyncs without changes",
+			changes:  5,
+			interval: time.Second,
+			resync:   true,
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			fakeClock := clocktesting.NewFakeClock(time.Now())
+			recorder := events.NewInMemoryRecorder("test")
+			informer := &fakeInformer{}
+			controller := New().
+				WithInformers(informer).
+				WithSync(func(ctx context.Context, syncCtx SyncContext) error { return nil }).
+				WithHotloopDetector(NewHotloopDetector(3).WithClock(fakeClock)).
+				ToController("FooController", recorder).(*baseController)
+
+			for i := 0; i < scenario.changes; i++ {
+				if scenario.resync {
+					informer.eventHandler.OnUpdate(configMap(i), configMap(i))
+				} else {
+					informer.eventHandler.OnUpdate(configMap(i), configMap(i+1))
+				}
+				controller.processNextWorkItem(context.TODO())
+				fakeClock.Step(scenario.interval)
+			}
+
+			var hotloopEvents []string
+			for _, event := range recorder.Events() {
+				if event.Reason == "ControllerHotloop" {
+					hotloopEvents = append(hotloopEvents, event.Message)
+				}
+			}
+			if len(hotloopEvents) != scenario.expectedEvents {
+				t.Fatalf("expected %d hotloop events, got %v", scenario.expectedEvents, hotloopEvents)
+			}
+			for _, message := range hotloopEvents {
+				if !strings.Contains(message, "timestamp") || strings.Contains(message, "resourceVersion") {
+					t.Errorf("expected the event to show the changed data only, got %s", message)
+				}
+			}
+		})
+	}
+}
+
+func TestHotloopDiff(t *testing.T) {
+	secret := func(value string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "secret", Labels: map[string]string{"rotated": value}},
+			Data:       map[string][]byte{"tls.key": []byte(value)},
+			StringData: map[string]string{"password": value},
+		}
+	}
+	diff := hotloopDiff(secret("old-secret-value"), secret("new-secret-value"))
+	if expected := `Secret "ns/secret" changed data, metadata.labels.rotated, stringData`; diff != expected {
+		t.Errorf("expected %q, got %q", expected, diff)
+	}
+
+	configMap := func(keys int) *corev1.ConfigMap {
+		data := map[string]string{}
+		for i := 0; i < keys; i++ {
+			data[fmt.Sprintf("ключ-%d", i)] = fmt.Sprintf("%d", i)
+		}
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cm"}, Data: data}
+	}
+	diff = hotloopDiff(configMap(0), configMap(500))
+	if !utf8.ValidString(diff) || len(diff) > maxHotloopDiffLength+len("...") {
+		t.Errorf("expected the changed fields to be truncated on a rune boundary, got %d bytes", len(diff))
+	}
+}
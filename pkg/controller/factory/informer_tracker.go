@@ -0,0 +1,179 @@
+package factory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+)
+
+var (
+	cacheSyncDurationMetric = metrics.NewHistogramVec(&metrics.HistogramOpts{
+		Subsystem:      "controller_factory",
+		Name:           "cache_sync_duration_seconds",
+		Help:           "Time it took for the informer caches of a controller to sync.",
+		Buckets:        []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600},
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"controller"})
+
+	cacheSyncFailuresMetric = metrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      "controller_factory",
+		Name:           "cache_sync_failures_total",
+		Help:           "Number of times the informer caches of a controller failed to sync in time.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"controller"})
+
+	lateInformerRegistrationsMetric = metrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      "controller_factory",
+		Name:           "late_informer_registrations_total",
+		Help:           "Number of informers registered by a controller after the informers were started.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"controller"})
+)
+
+func init() {
+	(&sync.Once{}).Do(func() {
+		legacyregistry.MustRegister(cacheSyncDurationMetric)
+		legacyregistry.MustRegister(cacheSyncFailuresMetric)
+		legacyregistry.MustRegister(lateInformerRegistrationsMetric)
+	})
+}
+
+// InformerStarter is implemented by shared informer factories.
+type InformerStarter interface {
+	Start(stopCh <-chan struct{})
+}
+
+// InformerTracker tracks the informers given to controllers built by factories using the tracker.
+// Shared informer factories only start informers that were requested before Start() was called, a controller
+// constructed after that would wait for caches that never sync. The tracker records which informers were
+// registered after the informers were started and had not synced, so such controllers fail with a clear error instead.
+type InformerTracker struct {
+	lock      sync.Mutex
+	started   bool
+	informers map[string][]Informer
+	late      map[string]int
+}
+
+// NewInformerTracker returns an empty InformerTracker.
+func NewInformerTracker() *InformerTracker {
+	return &InformerTracker{
+		informers: map[string][]Informer{},
+		late:      map[string]int{},
+	}
+}
+
+// Start starts the given informer factories and marks the tracker as started. Informers registered after this call
+// are reported as late registrations.
+func (t *InformerTracker) Start(stopCh <-chan struct{}, starters ...InformerStarter) {
+	for _, starter := range starters {
+		starter.Start(stopCh)
+	}
+	t.MarkStarted()
+}
+
+// MarkStarted marks the tracker as started. Use it when the informers are started by other means than Start().
+func (t *InformerTracker) MarkStarted() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.started = true
+}
+
+// Track records the informers used by the named controller. A controller built again under the same name, e.g. when
+// it is restarted or enabled at runtime, replaces its earlier registration. Informers registered after start are only
+// reported as late registrations when they have not synced yet: a synced informer was started by its factory already.
+func (t *InformerTracker) Track(controllerName string, informers ...Informer) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.informers[controllerName] = append([]Informer{}, informers...)
+	delete(t.late, controllerName)
+	if !t.started {
+		return
+	}
+	late := 0
+	for _, informer := range informers {
+		if !informer.HasSynced() {
+			late++
+		}
+	}
+	if late == 0 {
+		return
+	}
+	t.late[controllerName] = late
+	lateInformerRegistrationsMetric.WithLabelValues(controllerName).Add(float64(late))
+	klog.Errorf("Controller %s registered %d informer(s) after the informers were started, its caches might never sync", controllerName, late)
+}
+
+// ControllerNames returns the sorted names of all controllers with tracked informers.
+func (t *InformerTracker) ControllerNames() []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	names := make([]string, 0, len(t.informers))
+	for name := range t.informers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Informers returns the informers tracked for the named controller.
+func (t *InformerTracker) Informers(controllerName string) []Informer {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return append([]Informer{}, t.informers[controllerName]...)
+}
+
+// Err returns an error listing all controllers which registered informers after the informers were started.
+func (t *InformerTracker) Err() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if len(t.late) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(t.late))
+	for name := range t.late {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("informers registered after start by controllers: %s", strings.Join(names, ", "))
+}
+
+// WaitForNamedCacheSync waits for the informers tracked for the named controller to sync. When the timeout is zero,
+// the default cache sync timeout is used.
+func (t *InformerTracker) WaitForNamedCacheSync(ctx context.Context, controllerName string, timeout time.Duration) error {
+	if timeout == 0 {
+		timeout = defaultCacheSyncTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	informers := t.Informers(controllerName)
+	cacheSyncs := make([]cache.InformerSynced, 0, len(informers))
+	for i := range informers {
+		cacheSyncs = append(cacheSyncs, informers[i].HasSynced)
+	}
+	return t.waitForCacheSync(ctx, controllerName, cacheSyncs...)
+}
+
+func (t *InformerTracker) waitForCacheSync(ctx context.Context, controllerName string, cacheSyncs ...cache.InformerSynced) error {
+	start := time.Now()
+	if err := waitForNamedCacheSync(controllerName, ctx.Done(), cacheSyncs...); err != nil {
+		cacheSyncFailuresMetric.WithLabelValues(controllerName).Inc()
+		t.lock.Lock()
+		late := t.late[controllerName]
+		t.lock.Unlock()
+		if late > 0 {
+			return fmt.Errorf("%w: %d informer(s) were registered after the informers were started", err, late)
+		}
+		return err
+	}
+	cacheSyncDurationMetric.WithLabelValues(controllerName).Observe(time.Since(start).Seconds())
+	return nil
+}
@@ -0,0 +1,57 @@
+package factory
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/operator/events/eventstesting"
+)
+
+type neverSyncedInformer struct {
+	fakeInformer
+}
+
+func (f *neverSyncedInformer) HasSynced() bool {
+	return false
+}
+
+func TestInformerTracker(t *testing.T) {
+	tracker := NewInformerTracker()
+	syncFn := func(ctx context.Context, controllerContext SyncContext) error { return nil }
+
+	early := &fakeInformer{}
+	New().WithSync(syncFn).WithInformerTracker(tracker).WithInformers(early).ToController("early", eventstesting.NewTestingEventRecorder(t))
+	if informers := tracker.Informers("early"); len(informers) != 1 {
+		t.Fatalf("expected one tracked informer, got %d", len(informers))
+	}
+	if err := tracker.WaitForNamedCacheSync(context.Background(), "early", time.Second); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	tracker.Start(make(chan struct{}))
+	if err := tracker.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	late := &neverSyncedInformer{}
+	New().WithSync(syncFn).WithInformerTracker(tracker).WithBareInformers(late).ToController("late", eventstesting.NewTestingEventRecorder(t))
+	if err := tracker.Err(); err == nil || !strings.Contains(err.Error(), "late") {
+		t.Errorf("expected late registration error, got %v", err)
+	}
+	err := tracker.WaitForNamedCacheSync(context.Background(), "late", 100*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "registered after the informers were started") {
+		t.Errorf("expected cache sync error mentioning late registration, got %v", err)
+	}
+
+	// a controller restarted after the informers synced re-registers them legitimately
+	New().WithSync(syncFn).WithInformerTracker(tracker).WithInformers(early).ToController("early", eventstesting.NewTestingEventRecorder(t))
+	if informers := tracker.Informers("early"); len(informers) != 1 {
+		t.Errorf("expected the restarted controller to replace its informers, got %d", len(informers))
+	}
+	New().WithSync(syncFn).WithInformerTracker(tracker).WithInformers(early).ToController("late", eventstesting.NewTestingEventRecorder(t))
+	if err := tracker.Err(); err != nil {
+		t.Errorf("expected no late registrations of synced informers, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package factory
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+// syncSchedule is a schedule added by WithSyncSchedule.
+type syncSchedule struct {
+	spec      string
+	maxJitter time.Duration
+}
+
+// jitteredSchedule delays every activation of a cron schedule by a random duration, and interprets the schedule in
+// its time zone.
+type jitteredSchedule struct {
+	schedule  cron.Schedule
+	location  *time.Location
+	maxJitter time.Duration
+	jitter    func(maxJitter time.Duration) time.Duration
+}
+
+func (s *jitteredSchedule) Next(t time.Time) time.Time {
+	next := s.schedule.Next(t.In(s.location))
+	if s.maxJitter > 0 {
+		next = next.Add(s.jitter(s.maxJitter))
+	}
+	return next
+}
+
+func randomJitter(maxJitter time.Duration) time.Duration {
+	return time.Duration(rand.Int63n(int64(maxJitter)))
+}
+
+// parseSyncSchedule parses a standard cron schedule with an optional CRON_TZ= or TZ= time zone prefix, e.g.
+// "CRON_TZ=Europe/Prague 0 3 * * *". Without a prefix, the schedule is in UTC. The jitter must not be negative, and
+// must be shorter than the interval between two runs.
+func parseSyncSchedule(schedule syncSchedule) (cron.Schedule, error) {
+	if schedule.maxJitter < 0 {
+		return nil, fmt.Errorf("negative jitter %s for %q", schedule.maxJitter, schedule.spec)
+	}
+	spec, location := schedule.spec, time.UTC
+	for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+		if !strings.HasPrefix(spec, prefix) {
+			continue
+		}
+		zone, rest, found := strings.Cut(strings.TrimPrefix(spec, prefix), " ")
+		if !found {
+			return nil, fmt.Errorf("missing schedule after the time zone in %q", schedule.spec)
+		}
+		var err error
+		if location, err = time.LoadLocation(zone); err != nil {
+			return nil, fmt.Errorf("invalid time zone in %q: %v", schedule.spec, err)
+		}
+		spec = strings.TrimSpace(rest)
+		break
+	}
+	parsed, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.maxJitter > 0 {
+		if interval := shortestInterval(parsed, time.Now().In(location)); schedule.maxJitter >= interval {
+			return nil, fmt.Errorf("jitter %s for %q is not shorter than the interval of %s between two runs", schedule.maxJitter, schedule.spec, interval)
+		}
+	}
+	return &jitteredSchedule{schedule: parsed, location: location, maxJitter: schedule.maxJitter, jitter: randomJitter}, nil
+}
+
+// shortestInterval returns the shortest interval between the next consecutive activations of the schedule, the
+// intervals of schedules like "0 3 * * 1,2" differ.
+func shortestInterval(schedule cron.Schedule, now time.Time) time.Duration {
+	var shortest time.Duration
+	previous := schedule.Next(now)
+	for i := 0; i < 16; i++ {
+		next := schedule.Next(previous)
+		if next.IsZero() {
+			break
+		}
+		if interval := next.Sub(previous); shortest == 0 || interval < shortest {
+			shortest = interval
+		}
+		previous = next
+	}
+	return shortest
+}
@@ -0,0 +1,82 @@
+package factory
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSyncSchedule(t *testing.T) {
+	prague, err := time.LoadLocation("Europe/Prague")
+	if err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		schedule      syncSchedule
+		expectedNext  time.Time
+		expectedError bool
+	}{
+		{
+			name:         "utc by default",
+			schedule:     syncSchedule{spec: "0 3 * * *"},
+			expectedNext: time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "jitter",
+			schedule:     syncSchedule{spec: "0 3 * * *", maxJitter: 10 * time.Minute},
+			expectedNext: time.Date(2024, 1, 2, 3, 5, 0, 0, time.UTC),
+		},
+		{
+			name:         "time zone",
+			schedule:     syncSchedule{spec: "CRON_TZ=Europe/Prague 0 3 * * *"},
+			expectedNext: time.Date(2024, 1, 2, 3, 0, 0, 0, prague),
+		},
+		{
+			name:          "invalid time zone",
+			schedule:      syncSchedule{spec: "TZ=Nowhere/Nothing 0 3 * * *"},
+			expectedError: true,
+		},
+		{
+			name:          "missing schedule",
+			schedule:      syncSchedule{spec: "TZ=UTC"},
+			expectedError: true,
+		},
+		{
+			name:          "negative jitter",
+			schedule:      syncSchedule{spec: "0 3 * * *", maxJitter: -time.Minute},
+			expectedError: true,
+		},
+		{
+			name:          "jitter as long as the interval",
+			schedule:      syncSchedule{spec: "*/10 * * * *", maxJitter: 10 * time.Minute},
+			expectedError: true,
+		},
+		{
+			name:          "jitter longer than the shortest interval",
+			schedule:      syncSchedule{spec: "0 3 * * 1,2", maxJitter: 36 * time.Hour},
+			expectedError: true,
+		},
+		{
+			name:          "invalid schedule",
+			schedule:      syncSchedule{spec: "0 25 * * *"},
+			expectedError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := parseSyncSchedule(tt.schedule)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if err != nil {
+				return
+			}
+			schedule.(*jitteredSchedule).jitter = func(maxJitter time.Duration) time.Duration { return maxJitter / 2 }
+			if next := schedule.Next(now); !next.Equal(tt.expectedNext) {
+				t.Errorf("expected the next run at %s, got %s", tt.expectedNext, next)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package factory
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = "github.com/openshift/library-go/pkg/controller/factory"
+
+	syncResultSuccess = "success"
+	syncResultError   = "error"
+	syncResultRequeue = "requeue"
+)
+
+// WithTracerProvider sets the tracer provider used for the spans of the controller syncs. By default, the global
+// tracer provider is used, which does not record anything unless it is configured, e.g. by controllercmd.
+func (f *Factory) WithTracerProvider(tracerProvider oteltrace.TracerProvider) *Factory {
+	f.tracerProvider = tracerProvider
+	return f
+}
+
+// startSyncSpan starts the span of a single sync. API calls made with the returned context by clients wrapped with
+// the tracing round tripper are recorded as child spans.
+func (c *baseController) startSyncSpan(ctx context.Context, queueKey, correlationID string) (context.Context, oteltrace.Span) {
+	tracerProvider := c.tracerProvider
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	return tracerProvider.Tracer(tracerName).Start(ctx, c.name+".Sync",
+		oteltrace.WithSpanKind(oteltrace.SpanKindInternal),
+		oteltrace.WithAttributes(
+			attribute.String("controller.name", c.name),
+			attribute.String("controller.instance", c.controllerInstanceName),
+			attribute.String("controller.queue_key", queueKey),
+			attribute.String("controller.correlation_id", correlationID),
+		))
+}
+
+// endSyncSpan records the result of the sync and ends the span.
+func endSyncSpan(span oteltrace.Span, err error) {
+	defer span.End()
+	switch {
+	case err == nil:
+		span.SetAttributes(attribute.String("controller.sync_result", syncResultSuccess))
+		span.SetStatus(codes.Ok, "")
+	case err == SyntheticRequeueError:
+		span.SetAttributes(attribute.String("controller.sync_result", syncResultRequeue))
+	default:
+		span.SetAttributes(attribute.String("controller.sync_result", syncResultError))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,92 @@
+package factory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+type spanRecorder struct {
+	lock  sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (r *spanRecorder) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+func (r *spanRecorder) OnEnd(s sdktrace.ReadOnlySpan) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.spans = append(r.spans, s)
+}
+func (r *spanRecorder) Shutdown(context.Context) error   { return nil }
+func (r *spanRecorder) ForceFlush(context.Context) error { return nil }
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key string) string {
+	for _, kv := range span.Attributes() {
+		if kv.Key == attribute.Key(key) {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestBaseController_SyncSpans(t *testing.T) {
+	recorder := &spanRecorder{}
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	syncErr := fmt.Errorf("sync failed")
+	results := []error{nil, syncErr}
+	c := &baseController{
+		name:           "test",
+		syncContext:    NewSyncContext("test", events.NewInMemoryRecorder("test")),
+		tracerProvider: tracerProvider,
+	}
+	c.sync = func(ctx context.Context, syncCtx SyncContext) error {
+		// API calls made through the tracing round tripper start child spans from the sync context
+		_, apiSpan := oteltrace.SpanFromContext(ctx).TracerProvider().Tracer("test").Start(ctx, "GET")
+		apiSpan.End()
+		err := results[0]
+		results = results[1:]
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		c.syncContext.Queue().Add(DefaultQueueKey)
+		c.processNextWorkItem(context.TODO())
+	}
+
+	if len(recorder.spans) != 4 {
+		t.Fatalf("expected 2 sync and 2 API call spans, got %d", len(recorder.spans))
+	}
+	for i, expected := range []struct {
+		result string
+		status codes.Code
+	}{
+		{result: syncResultSuccess, status: codes.Ok},
+		{result: syncResultError, status: codes.Error},
+	} {
+		apiSpan, syncSpan := recorder.spans[2*i], recorder.spans[2*i+1]
+		if syncSpan.Name() != "test.Sync" {
+			t.Errorf("unexpected sync span name %q", syncSpan.Name())
+		}
+		if apiSpan.Parent().SpanID() != syncSpan.SpanContext().SpanID() {
+			t.Errorf("expected the API call span to be a child of the sync span")
+		}
+		if key := spanAttribute(syncSpan, "controller.queue_key"); key != DefaultQueueKey {
+			t.Errorf("unexpected queue key %q", key)
+		}
+		if result := spanAttribute(syncSpan, "controller.sync_result"); result != expected.result {
+			t.Errorf("expected sync result %q, got %q", expected.result, result)
+		}
+		if syncSpan.Status().Code != expected.status {
+			t.Errorf("expected status %v, got %v", expected.status, syncSpan.Status())
+		}
+	}
+}
@@ -0,0 +1,253 @@
+// Package informeraudit reports how many objects the informer caches of a process hold and roughly how much memory
+// they use, to help finding informers that cache far more than the operator needs.
+package informeraudit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// InformerStats describes the cache of a single informer.
+type InformerStats struct {
+	// Name identifies where the informer was registered, e.g. the namespace or the controller using it.
+	Name string `json:"name"`
+	// Type is the Go type of the cached objects, empty when the cache is empty and the type is unknown.
+	Type string `json:"type"`
+	// Objects is the number of cached objects.
+	Objects int `json:"objects"`
+	// EstimatedBytes is the sum of the serialized size of the cached objects. The real memory footprint is higher,
+	// but the estimate is good enough to compare informers.
+	EstimatedBytes int64 `json:"estimatedBytes"`
+}
+
+// storeProvider is implemented by all shared informers.
+type storeProvider interface {
+	GetStore() cache.Store
+}
+
+type namedStore struct {
+	name  string
+	store cache.Store
+}
+
+// Auditor collects informer caches and reports their size.
+type Auditor struct {
+	lock        sync.Mutex
+	informers   []namedStore
+	trackers    []*factory.InformerTracker
+	kubeFactory map[string]informers.SharedInformerFactory
+}
+
+// NewAuditor returns an empty Auditor.
+func NewAuditor() *Auditor {
+	return &Auditor{
+		kubeFactory: map[string]informers.SharedInformerFactory{},
+	}
+}
+
+// AddInformer adds a single informer, e.g. one created by an openshift client-go informer factory.
+func (a *Auditor) AddInformer(name string, informer cache.SharedInformer) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.informers = append(a.informers, namedStore{name: name, store: informer.GetStore()})
+}
+
+// AddInformerTracker adds all informers given to controllers using the tracker. The informers are reported under
+// the name of the first controller using them.
+func (a *Auditor) AddInformerTracker(tracker *factory.InformerTracker) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.trackers = append(a.trackers, tracker)
+}
+
+// AddKubeInformersForNamespaces adds all started informers of the given kube informers, reported by namespace.
+func (a *Auditor) AddKubeInformersForNamespaces(kubeInformers v1helpers.KubeInformersForNamespaces) {
+	for _, namespace := range sortedNamespaces(kubeInformers) {
+		name := namespace
+		if len(name) == 0 {
+			name = "<cluster>"
+		}
+		a.AddKubeInformerFactory(name, kubeInformers.InformersFor(namespace))
+	}
+}
+
+// AddKubeInformerFactory adds all started informers of a kube shared informer factory.
+func (a *Auditor) AddKubeInformerFactory(name string, informerFactory informers.SharedInformerFactory) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.kubeFactory[name] = informerFactory
+}
+
+// Report returns the stats of all known informers, the largest first. Informers known through multiple sources
+// are reported only once.
+func (a *Auditor) Report() []InformerStats {
+	a.lock.Lock()
+	stores := append([]namedStore{}, a.informers...)
+	trackers := append([]*factory.InformerTracker{}, a.trackers...)
+	kubeFactories := make(map[string]informers.SharedInformerFactory, len(a.kubeFactory))
+	for name, informerFactory := range a.kubeFactory {
+		kubeFactories[name] = informerFactory
+	}
+	a.lock.Unlock()
+
+	for _, name := range sortedKeys(kubeFactories) {
+		stores = append(stores, kubeFactoryStores(name, kubeFactories[name])...)
+	}
+	for _, tracker := range trackers {
+		for _, controllerName := range tracker.ControllerNames() {
+			for _, informer := range tracker.Informers(controllerName) {
+				if provider, ok := informer.(storeProvider); ok {
+					stores = append(stores, namedStore{name: controllerName, store: provider.GetStore()})
+				}
+			}
+		}
+	}
+
+	seen := map[cache.Store]bool{}
+	ret := []InformerStats{}
+	for _, s := range stores {
+		if seen[s.store] {
+			continue
+		}
+		seen[s.store] = true
+		ret = append(ret, storeStats(s.name, s.store))
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].EstimatedBytes > ret[j].EstimatedBytes
+	})
+	return ret
+}
+
+// ServeHTTP writes the report as a table, or as JSON when requested with ?format=json.
+func (a *Auditor) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	report := a.Report()
+	if req.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, FormatReport(report))
+}
+
+// LogOnSignal logs the report every time one of the signals (usually syscall.SIGUSR1) is received, until the context
+// is done.
+func (a *Auditor) LogOnSignal(ctx context.Context, signals ...os.Signal) {
+	signalCh := make(chan os.Signal, 1)
+	signal.Notify(signalCh, signals...)
+	go func() {
+		defer signal.Stop(signalCh)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signalCh:
+				klog.Infof("Informer cache audit:\n%s", FormatReport(a.Report()))
+			}
+		}
+	}()
+}
+
+// FormatReport formats the report as a table.
+func FormatReport(report []InformerStats) string {
+	out := &strings.Builder{}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tOBJECTS\tESTIMATED BYTES")
+	for _, stats := range report {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\n", stats.Name, stats.Type, stats.Objects, stats.EstimatedBytes)
+	}
+	w.Flush()
+	return out.String()
+}
+
+func storeStats(name string, store cache.Store) InformerStats {
+	stats := InformerStats{Name: name}
+	for _, obj := range store.List() {
+		if len(stats.Type) == 0 {
+			stats.Type = reflect.TypeOf(obj).String()
+		}
+		stats.Objects++
+		stats.EstimatedBytes += int64(objectSize(obj))
+	}
+	return stats
+}
+
+// objectSize returns the protobuf size of API objects, falling back to the JSON size.
+func objectSize(obj interface{}) int {
+	if sized, ok := obj.(interface{ Size() int }); ok {
+		return sized.Size()
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+// kubeFactoryStores returns the stores of all started informers of the factory. The factory does not expose its
+// informers, but it reports the types of the started ones and returns the existing informer when asked for a type.
+func kubeFactoryStores(name string, informerFactory informers.SharedInformerFactory) []namedStore {
+	stopped := make(chan struct{})
+	close(stopped)
+	types := informerFactory.WaitForCacheSync(stopped)
+
+	typeNames := make([]string, 0, len(types))
+	byName := map[string]reflect.Type{}
+	for informerType := range types {
+		typeNames = append(typeNames, informerType.String())
+		byName[informerType.String()] = informerType
+	}
+	sort.Strings(typeNames)
+
+	var ret []namedStore
+	for _, typeName := range typeNames {
+		informerType := byName[typeName]
+		if informerType.Kind() != reflect.Ptr {
+			continue
+		}
+		obj, ok := reflect.New(informerType.Elem()).Interface().(runtime.Object)
+		if !ok {
+			continue
+		}
+		informer := informerFactory.InformerFor(obj, nil)
+		if informer == nil {
+			continue
+		}
+		ret = append(ret, namedStore{name: name, store: informer.GetStore()})
+	}
+	return ret
+}
+
+func sortedNamespaces(kubeInformers v1helpers.KubeInformersForNamespaces) []string {
+	namespaces := kubeInformers.Namespaces().UnsortedList()
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+func sortedKeys(m map[string]informers.SharedInformerFactory) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,66 @@
+package informeraudit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events/eventstesting"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestAuditor(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "a"}, Data: map[string][]byte{"key": make([]byte, 1024)}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "b"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "c"}},
+	)
+	kubeInformers := v1helpers.NewKubeInformersForNamespaces(kubeClient, "ns")
+	secretInformer := kubeInformers.InformersFor("ns").Core().V1().Secrets().Informer()
+	configMapInformer := kubeInformers.InformersFor("ns").Core().V1().ConfigMaps().Informer()
+
+	tracker := factory.NewInformerTracker()
+	factory.New().
+		WithSync(func(ctx context.Context, controllerContext factory.SyncContext) error { return nil }).
+		WithInformerTracker(tracker).
+		WithInformers(secretInformer).
+		ToController("SecretController", eventstesting.NewTestingEventRecorder(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	kubeInformers.Start(ctx.Done())
+	kubeInformers.WaitForCacheSync(ctx.Done())
+
+	auditor := NewAuditor()
+	auditor.AddInformerTracker(tracker)
+	auditor.AddKubeInformersForNamespaces(kubeInformers)
+	auditor.AddInformer("configmaps", configMapInformer)
+
+	report := auditor.Report()
+	if len(report) != 2 {
+		t.Fatalf("expected two deduplicated informers, got %#v", report)
+	}
+	secrets := report[0]
+	if secrets.Type != "*v1.Secret" || secrets.Objects != 2 || secrets.EstimatedBytes < 1024 || secrets.Name != "ns" {
+		t.Errorf("unexpected secret stats: %#v", secrets)
+	}
+	if configMaps := report[1]; configMaps.Type != "*v1.ConfigMap" || configMaps.Objects != 1 {
+		t.Errorf("unexpected configmap stats: %#v", configMaps)
+	}
+
+	recorder := httptest.NewRecorder()
+	auditor.ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/informers?format=json", nil))
+	var served []InformerStats
+	if err := json.Unmarshal(recorder.Body.Bytes(), &served); err != nil {
+		t.Fatal(err)
+	}
+	if len(served) != 2 {
+		t.Errorf("expected two informers served, got %#v", served)
+	}
+}
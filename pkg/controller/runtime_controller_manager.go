@@ -0,0 +1,223 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+)
+
+// ControllerConstructor builds a new controller instance. A controller can not be run again once it was stopped
+// (its queue is shut down), so every (re)start of a managed controller calls the constructor for a fresh instance.
+// The event handlers of a factory controller are removed from the informers when it stops.
+type ControllerConstructor func() (factory.Controller, error)
+
+// managedController holds the state of a single controller managed by the RuntimeControllerManager.
+type managedController struct {
+	name        string
+	workers     int
+	constructor ControllerConstructor
+
+	// cancel and done are set while the controller is running, done is closed when its Run returned
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// pending is set when the controller was started before the manager runs, it is started by Run
+	pending bool
+}
+
+// running returns true if the controller was started and its Run did not return.
+func (c *managedController) running() bool {
+	if c.done == nil {
+		return false
+	}
+	select {
+	case <-c.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// RuntimeControllerManager runs controllers registered by name and allows to stop, start and restart individual
+// controllers at runtime, e.g. when the management state, a feature gate or a cluster capability changes, without
+// restarting the whole process. Unlike manager.ControllerManager, the controllers are constructed on every start.
+type RuntimeControllerManager struct {
+	// operationLock serializes the starts and stops, so a controller is never started while its previous instance
+	// is still stopping
+	operationLock sync.Mutex
+
+	lock        sync.Mutex
+	ctx         context.Context
+	controllers map[string]*managedController
+}
+
+// NewRuntimeControllerManager returns an empty RuntimeControllerManager.
+func NewRuntimeControllerManager() *RuntimeControllerManager {
+	return &RuntimeControllerManager{
+		controllers: map[string]*managedController{},
+	}
+}
+
+// Register adds a controller under the given name. Registered controllers are not started until StartController
+// is called, see also SetEnabled.
+func (m *RuntimeControllerManager) Register(name string, workers int, constructor ControllerConstructor) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, exists := m.controllers[name]; exists {
+		return fmt.Errorf("controller %q is already registered", name)
+	}
+	m.controllers[name] = &managedController{
+		name:        name,
+		workers:     workers,
+		constructor: constructor,
+	}
+	return nil
+}
+
+// Run sets the context all controllers run in, starts the controllers started before, and blocks until the context
+// is done. All running controllers are stopped before Run returns.
+func (m *RuntimeControllerManager) Run(ctx context.Context) {
+	m.lock.Lock()
+	m.ctx = ctx
+	var pending []string
+	for name, controller := range m.controllers {
+		if controller.pending {
+			pending = append(pending, name)
+		}
+	}
+	m.lock.Unlock()
+
+	sort.Strings(pending)
+	for _, name := range pending {
+		if err := m.StartController(name); err != nil {
+			klog.Warningf("Failed to start %s controller: %v", name, err)
+		}
+	}
+
+	<-ctx.Done()
+
+	for _, name := range m.ControllerNames() {
+		if err := m.StopController(name); err != nil {
+			klog.Warningf("Failed to stop %s controller: %v", name, err)
+		}
+	}
+}
+
+// ControllerNames returns the sorted names of all registered controllers.
+func (m *RuntimeControllerManager) ControllerNames() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	names := make([]string, 0, len(m.controllers))
+	for name := range m.controllers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsRunning returns true if the named controller is running.
+func (m *RuntimeControllerManager) IsRunning(name string) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	controller, ok := m.controllers[name]
+	return ok && controller.running()
+}
+
+// StartController starts a new instance of the named controller. Starting a running controller is a no-op, a
+// controller whose Run returned is started again. Controllers started before Run are started once Run is called.
+// The constructor is called without holding the manager lock, so it may query the manager, but it must not start or
+// stop controllers.
+func (m *RuntimeControllerManager) StartController(name string) error {
+	m.operationLock.Lock()
+	defer m.operationLock.Unlock()
+
+	m.lock.Lock()
+	controller, ok := m.controllers[name]
+	if !ok {
+		m.lock.Unlock()
+		return fmt.Errorf("controller %q is not registered", name)
+	}
+	if m.ctx == nil {
+		controller.pending = true
+		m.lock.Unlock()
+		return nil
+	}
+	managerCtx := m.ctx
+	running := controller.running()
+	m.lock.Unlock()
+
+	if managerCtx.Err() != nil {
+		return fmt.Errorf("unable to start controller %q: the manager is shutting down", name)
+	}
+	if running {
+		return nil
+	}
+
+	instance, err := controller.constructor()
+	if err != nil {
+		return fmt.Errorf("unable to construct controller %q: %w", name, err)
+	}
+	ctx, cancel := context.WithCancel(managerCtx)
+	done := make(chan struct{})
+	m.lock.Lock()
+	controller.pending = false
+	controller.cancel = cancel
+	controller.done = done
+	m.lock.Unlock()
+
+	klog.Infof("Starting %s controller", name)
+	go func() {
+		defer close(done)
+		defer cancel()
+		defer klog.Infof("%s controller terminated", name)
+		instance.Run(ctx, controller.workers)
+	}()
+	return nil
+}
+
+// StopController stops the named controller and waits for it to terminate. Stopping a stopped controller is a no-op.
+func (m *RuntimeControllerManager) StopController(name string) error {
+	m.operationLock.Lock()
+	defer m.operationLock.Unlock()
+
+	m.lock.Lock()
+	controller, ok := m.controllers[name]
+	if !ok {
+		m.lock.Unlock()
+		return fmt.Errorf("controller %q is not registered", name)
+	}
+	cancel, done := controller.cancel, controller.done
+	controller.cancel, controller.done = nil, nil
+	controller.pending = false
+	m.lock.Unlock()
+
+	if done == nil {
+		return nil
+	}
+	klog.Infof("Stopping %s controller", name)
+	cancel()
+	<-done
+	return nil
+}
+
+// RestartController stops the named controller, if running, and starts a new instance of it.
+func (m *RuntimeControllerManager) RestartController(name string) error {
+	if err := m.StopController(name); err != nil {
+		return err
+	}
+	return m.StartController(name)
+}
+
+// SetEnabled starts or stops the named controller. It is meant to be called from handlers reacting to the
+// management state, feature gates or capabilities.
+func (m *RuntimeControllerManager) SetEnabled(name string, enabled bool) error {
+	if enabled {
+		return m.StartController(name)
+	}
+	return m.StopController(name)
+}
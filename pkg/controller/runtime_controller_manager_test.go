@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+)
+
+type fakeController struct {
+	running *int32
+	exit    chan struct{}
+}
+
+func (f *fakeController) Run(ctx context.Context, workers int) {
+	atomic.AddInt32(f.running, 1)
+	defer atomic.AddInt32(f.running, -1)
+	select {
+	case <-ctx.Done():
+	case <-f.exit:
+	}
+}
+
+func (f *fakeController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	return nil
+}
+
+func (f *fakeController) Name() string {
+	return "fake"
+}
+
+func TestRuntimeControllerManager(t *testing.T) {
+	var running, constructed int32
+	exit := make(chan struct{})
+	m := NewRuntimeControllerManager()
+	if err := m.Register("fake", 1, func() (factory.Controller, error) {
+		// querying the manager from a constructor must not deadlock
+		if m.IsRunning("fake") {
+			t.Error("expected the controller not to be running while constructing it")
+		}
+		atomic.AddInt32(&constructed, 1)
+		return &fakeController{running: &running, exit: exit}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Register("fake", 1, nil); err == nil {
+		t.Fatal("expected duplicate registration to fail")
+	}
+	// a start before Run is deferred until Run
+	if err := m.StartController("fake"); err != nil {
+		t.Fatal(err)
+	}
+	if m.IsRunning("fake") || atomic.LoadInt32(&constructed) != 0 {
+		t.Fatal("expected the controller not to be started before Run")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runDone := make(chan struct{})
+	go func() {
+		defer close(runDone)
+		m.Run(ctx)
+	}()
+
+	waitForRunning := func(expected int32) {
+		t.Helper()
+		if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			return atomic.LoadInt32(&running) == expected, nil
+		}); err != nil {
+			t.Fatalf("expected %d running controllers, got %d", expected, atomic.LoadInt32(&running))
+		}
+	}
+
+	waitForRunning(1)
+	if !m.IsRunning("fake") {
+		t.Error("expected controller to be running")
+	}
+	if err := m.StartController("fake"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.StopController("fake"); err != nil {
+		t.Fatal(err)
+	}
+	waitForRunning(0)
+	if m.IsRunning("fake") {
+		t.Error("expected controller to be stopped")
+	}
+
+	if err := m.RestartController("fake"); err != nil {
+		t.Fatal(err)
+	}
+	waitForRunning(1)
+	if err := m.RestartController("fake"); err != nil {
+		t.Fatal(err)
+	}
+	waitForRunning(1)
+	if c := atomic.LoadInt32(&constructed); c != 3 {
+		t.Errorf("expected 3 constructed controllers, got %d", c)
+	}
+
+	// a controller whose Run returned is started again
+	exit <- struct{}{}
+	waitForRunning(0)
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return !m.IsRunning("fake"), nil
+	}); err != nil {
+		t.Fatal("expected the exited controller not to be running")
+	}
+	if err := m.StartController("fake"); err != nil {
+		t.Fatal(err)
+	}
+	waitForRunning(1)
+	if c := atomic.LoadInt32(&constructed); c != 4 {
+		t.Errorf("expected 4 constructed controllers, got %d", c)
+	}
+
+	// concurrent starts and stops never run two instances
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = m.SetEnabled("fake", i%2 == 0)
+			if r := atomic.LoadInt32(&running); r > 1 {
+				t.Errorf("expected at most one running instance, got %d", r)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := m.SetEnabled("missing", true); err == nil {
+		t.Error("expected unknown controller to fail")
+	}
+
+	cancel()
+	<-runDone
+	waitForRunning(0)
+	if m.IsRunning("fake") {
+		t.Error("expected controller to be stopped after shutdown")
+	}
+}
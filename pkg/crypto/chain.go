@@ -0,0 +1,184 @@
+package crypto
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// ChainProblem is the kind of problem found in a certificate chain.
+type ChainProblem string
+
+const (
+	// ChainEmpty means no certificate was presented.
+	ChainEmpty ChainProblem = "Empty"
+	// ChainCertificateExpired means a certificate of the chain is expired.
+	ChainCertificateExpired ChainProblem = "CertificateExpired"
+	// ChainCertificateNotYetValid means a certificate of the chain is not valid yet.
+	ChainCertificateNotYetValid ChainProblem = "CertificateNotYetValid"
+	// ChainHostnameMismatch means the leaf certificate is not valid for the expected hostname.
+	ChainHostnameMismatch ChainProblem = "HostnameMismatch"
+	// ChainUnknownAuthority means the chain is not signed by a certificate of the bundle.
+	ChainUnknownAuthority ChainProblem = "UnknownAuthority"
+	// ChainInvalid covers the other problems, like an incompatible key usage.
+	ChainInvalid ChainProblem = "Invalid"
+)
+
+// ChainError is a problem found in a certificate chain, with a human readable message.
+type ChainError struct {
+	Problem ChainProblem
+	// Certificate is the certificate with the problem, it is nil for an empty chain.
+	Certificate *x509.Certificate
+	Message     string
+}
+
+func (e *ChainError) Error() string {
+	return e.Message
+}
+
+// ChainValidationOptions are the options of ValidateChain.
+type ChainValidationOptions struct {
+	// Hostname is the DNS name or IP the leaf certificate must be valid for, it is not checked when empty.
+	Hostname string
+	// KeyUsages are the extended key usages the leaf certificate must allow, ServerAuth when empty. Use
+	// x509.ExtKeyUsageAny to accept any usage.
+	KeyUsages []x509.ExtKeyUsage
+	// CurrentTime is the time the chain is validated at, the current time when zero.
+	CurrentTime time.Time
+}
+
+// ValidateChain validates the presented chain, leaf first, against the CA bundle. It returns a *ChainError telling
+// why the chain is invalid in human readable terms, e.g. which certificate expired and when, or nil for a valid chain.
+func ValidateChain(chain []*x509.Certificate, bundle []*x509.Certificate, options ChainValidationOptions) error {
+	if len(chain) == 0 {
+		return &ChainError{Problem: ChainEmpty, Message: "no certificate was presented"}
+	}
+	now := options.CurrentTime
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	for _, cert := range chain {
+		if now.After(cert.NotAfter) {
+			return &ChainError{
+				Problem:     ChainCertificateExpired,
+				Certificate: cert,
+				Message:     fmt.Sprintf("certificate %s expired at %s", describeCertificate(cert), cert.NotAfter.UTC().Format(time.RFC3339)),
+			}
+		}
+		if now.Before(cert.NotBefore) {
+			return &ChainError{
+				Problem:     ChainCertificateNotYetValid,
+				Certificate: cert,
+				Message:     fmt.Sprintf("certificate %s is not valid before %s", describeCertificate(cert), cert.NotBefore.UTC().Format(time.RFC3339)),
+			}
+		}
+	}
+
+	leaf := chain[0]
+	if len(options.Hostname) > 0 {
+		if err := leaf.VerifyHostname(options.Hostname); err != nil {
+			return &ChainError{
+				Problem:     ChainHostnameMismatch,
+				Certificate: leaf,
+				Message:     fmt.Sprintf("certificate %s is valid for %s, wanted %s", describeCertificate(leaf), describeNames(leaf), options.Hostname),
+			}
+		}
+	}
+
+	roots := x509.NewCertPool()
+	for _, cert := range bundle {
+		roots.AddCert(cert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     options.KeyUsages,
+	})
+	if err == nil {
+		return nil
+	}
+	var unknownAuthority x509.UnknownAuthorityError
+	if errors.As(err, &unknownAuthority) {
+		last := chain[len(chain)-1]
+		return &ChainError{
+			Problem:     ChainUnknownAuthority,
+			Certificate: last,
+			Message:     fmt.Sprintf("certificate %s is signed by unknown authority %q, the bundle holds %s", describeCertificate(last), last.Issuer.String(), describeBundle(bundle)),
+		}
+	}
+	return &ChainError{
+		Problem:     ChainInvalid,
+		Certificate: leaf,
+		Message:     fmt.Sprintf("certificate %s is invalid: %v", describeCertificate(leaf), err),
+	}
+}
+
+// DiagnoseEndpoint connects to the TLS endpoint at address, host:port, and validates the chain it presents against the
+// CA bundle, see ValidateChain. The hostname defaults to the host of the address. It returns the problem found, or an
+// error when the endpoint cannot be reached.
+func DiagnoseEndpoint(ctx context.Context, address string, bundle []*x509.Certificate, options ChainValidationOptions) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if len(options.Hostname) == 0 {
+		options.Hostname = host
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: 10 * time.Second},
+		Config: &tls.Config{
+			ServerName: options.Hostname,
+			// the chain is validated below, to tell why it is invalid
+			InsecureSkipVerify: true,
+		},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("unable to connect to %s: %w", address, err)
+	}
+	defer conn.Close()
+
+	return ValidateChain(conn.(*tls.Conn).ConnectionState().PeerCertificates, bundle, options)
+}
+
+func describeCertificate(cert *x509.Certificate) string {
+	name := cert.Subject.CommonName
+	if len(name) == 0 {
+		name = cert.Subject.String()
+	}
+	return fmt.Sprintf("%q (serial %s)", name, cert.SerialNumber)
+}
+
+func describeNames(cert *x509.Certificate) string {
+	names := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+	if len(names) == 0 {
+		return "no names"
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
+
+func describeBundle(bundle []*x509.Certificate) string {
+	if len(bundle) == 0 {
+		return "no certificates"
+	}
+	var names []string
+	for _, cert := range bundle {
+		names = append(names, describeCertificate(cert))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,174 @@
+package crypto
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+func newTestCA(t *testing.T, name string) *CA {
+	t.Helper()
+	config, err := MakeSelfSignedCAConfigForDuration(name, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &CA{Config: config, SerialGenerator: &RandomSerialGenerator{}}
+}
+
+func TestValidateChain(t *testing.T) {
+	ca := newTestCA(t, "test-ca")
+	otherCA := newTestCA(t, "other-ca")
+	serving, err := ca.MakeServerCertForDuration(sets.New("foo.example.com"), 30*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := ca.MakeClientCertificateForDuration(&user.DefaultInfo{Name: "client"}, 30*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		chain           []*x509.Certificate
+		bundle          []*x509.Certificate
+		options         ChainValidationOptions
+		expectedProblem ChainProblem
+		expectedMessage string
+	}{
+		{
+			name:    "valid chain",
+			chain:   serving.Certs,
+			bundle:  ca.Config.Certs,
+			options: ChainValidationOptions{Hostname: "foo.example.com"},
+		},
+		{
+			name:            "empty chain",
+			bundle:          ca.Config.Certs,
+			expectedProblem: ChainEmpty,
+			expectedMessage: "no certificate was presented",
+		},
+		{
+			name:            "expired certificate",
+			chain:           serving.Certs,
+			bundle:          ca.Config.Certs,
+			options:         ChainValidationOptions{CurrentTime: time.Now().Add(45 * time.Minute)},
+			expectedProblem: ChainCertificateExpired,
+			expectedMessage: "expired at " + serving.Certs[0].NotAfter.UTC().Format(time.RFC3339),
+		},
+		{
+			name:            "not yet valid certificate",
+			chain:           serving.Certs,
+			bundle:          ca.Config.Certs,
+			options:         ChainValidationOptions{CurrentTime: time.Now().Add(-time.Hour)},
+			expectedProblem: ChainCertificateNotYetValid,
+			expectedMessage: "is not valid before",
+		},
+		{
+			name:            "hostname mismatch",
+			chain:           serving.Certs,
+			bundle:          ca.Config.Certs,
+			options:         ChainValidationOptions{Hostname: "bar.example.com"},
+			expectedProblem: ChainHostnameMismatch,
+			expectedMessage: "is valid for [foo.example.com], wanted bar.example.com",
+		},
+		{
+			name:            "unknown authority",
+			chain:           serving.Certs,
+			bundle:          otherCA.Config.Certs,
+			expectedProblem: ChainUnknownAuthority,
+			expectedMessage: `signed by unknown authority "CN=test-ca", the bundle holds "other-ca"`,
+		},
+		{
+			name:            "wrong key usage",
+			chain:           client.Certs,
+			bundle:          ca.Config.Certs,
+			expectedProblem: ChainInvalid,
+		},
+		{
+			name:    "any key usage",
+			chain:   client.Certs,
+			bundle:  ca.Config.Certs,
+			options: ChainValidationOptions{KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateChain(tt.chain, tt.bundle, tt.options)
+			if len(tt.expectedProblem) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var chainErr *ChainError
+			if !errors.As(err, &chainErr) {
+				t.Fatalf("expected a ChainError, got %v", err)
+			}
+			if chainErr.Problem != tt.expectedProblem {
+				t.Errorf("expected problem %s, got %s: %v", tt.expectedProblem, chainErr.Problem, err)
+			}
+			if !strings.Contains(err.Error(), tt.expectedMessage) {
+				t.Errorf("expected the message to contain %q, got %q", tt.expectedMessage, err.Error())
+			}
+		})
+	}
+}
+
+func TestDiagnoseEndpoint(t *testing.T) {
+	ca := newTestCA(t, "test-ca")
+	serving, err := ca.MakeServerCertForDuration(sets.New("localhost", "127.0.0.1"), 30*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, keyPEM, err := serving.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{keyPair}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	if err := DiagnoseEndpoint(context.TODO(), listener.Addr().String(), ca.Config.Certs, ChainValidationOptions{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err = DiagnoseEndpoint(context.TODO(), listener.Addr().String(), ca.Config.Certs, ChainValidationOptions{Hostname: "foo.example.com"})
+	var chainErr *ChainError
+	if !errors.As(err, &chainErr) || chainErr.Problem != ChainHostnameMismatch {
+		t.Errorf("expected a hostname mismatch, got %v", err)
+	}
+
+	// nothing listens on the port of a closed listener
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+	err = DiagnoseEndpoint(context.TODO(), closed.Addr().String(), ca.Config.Certs, ChainValidationOptions{})
+	if err == nil || errors.As(err, &chainErr) {
+		t.Errorf("expected a connection error, got %v", err)
+	}
+}
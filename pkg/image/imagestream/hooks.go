@@ -0,0 +1,59 @@
+package imagestream
+
+import (
+	"fmt"
+
+	opv1 "github.com/openshift/api/operator/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	dc "github.com/openshift/library-go/pkg/operator/deploymentcontroller"
+)
+
+// WithDeploymentHook sets the image of the containers of the deployment to the digest pull specs of the
+// ImageStreamTags. containerTags maps container names to <stream>:<tag> in the namespace. Pass the image stream
+// informer to the deployment controller with WithExtraInformers, so that updated tags roll out the deployment.
+// The hook fails until all tags are resolved.
+func WithDeploymentHook(resolver *Resolver, namespace string, containerTags map[string]string) dc.DeploymentHookFunc {
+	return func(_ *opv1.OperatorSpec, deployment *appsv1.Deployment) error {
+		return SetContainerImages(resolver, namespace, &deployment.Spec.Template.Spec, containerTags)
+	}
+}
+
+// WithDaemonSetHook is WithDeploymentHook for daemonsets, see csidrivernodeservicecontroller.DaemonSetHookFunc.
+func WithDaemonSetHook(resolver *Resolver, namespace string, containerTags map[string]string) func(*opv1.OperatorSpec, *appsv1.DaemonSet) error {
+	return func(_ *opv1.OperatorSpec, daemonSet *appsv1.DaemonSet) error {
+		return SetContainerImages(resolver, namespace, &daemonSet.Spec.Template.Spec, containerTags)
+	}
+}
+
+// SetContainerImages sets the image of the containers and init containers of the pod spec to the digest pull specs
+// of the ImageStreamTags in containerTags, keyed by container name. Containers missing in the pod spec are an error.
+func SetContainerImages(resolver *Resolver, namespace string, podSpec *corev1.PodSpec, containerTags map[string]string) error {
+	for containerName, nameAndTag := range containerTags {
+		container := findContainer(podSpec, containerName)
+		if container == nil {
+			return fmt.Errorf("container %q not found", containerName)
+		}
+		pullSpec, err := resolver.Resolve(namespace, nameAndTag)
+		if err != nil {
+			return err
+		}
+		container.Image = pullSpec
+	}
+	return nil
+}
+
+func findContainer(podSpec *corev1.PodSpec, name string) *corev1.Container {
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == name {
+			return &podSpec.Containers[i]
+		}
+	}
+	for i := range podSpec.InitContainers {
+		if podSpec.InitContainers[i].Name == name {
+			return &podSpec.InitContainers[i]
+		}
+	}
+	return nil
+}
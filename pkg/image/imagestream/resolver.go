@@ -0,0 +1,78 @@
+// Package imagestream resolves ImageStreamTags to digest pull specs for operators whose operands are deployed from
+// image streams.
+package imagestream
+
+import (
+	"fmt"
+	"strconv"
+
+	imagev1listers "github.com/openshift/client-go/image/listers/image/v1"
+
+	"github.com/openshift/library-go/pkg/image/imageutil"
+	imagereference "github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/library-go/pkg/image/trigger"
+)
+
+// ErrTagNotImported is returned when the tag has no image yet, e.g. because the import is still running.
+var ErrTagNotImported = fmt.Errorf("no image has been imported or pushed to the tag yet")
+
+// Resolver resolves ImageStreamTags from an informer cache. The returned pull specs always reference the image by
+// digest and follow the reference policy of the tag: Local references the image through the integrated registry,
+// Source references the image at the registry it was imported from.
+type Resolver struct {
+	lister imagev1listers.ImageStreamLister
+}
+
+var _ trigger.TagRetriever = &Resolver{}
+
+// NewResolver returns a resolver reading image streams from the lister.
+func NewResolver(lister imagev1listers.ImageStreamLister) *Resolver {
+	return &Resolver{lister: lister}
+}
+
+// Resolve returns the digest pull spec of the latest image of the ImageStreamTag <stream>:<tag> in the namespace. The
+// latest tag is used when the tag is omitted.
+func (r *Resolver) Resolve(namespace, nameAndTag string) (string, error) {
+	pullSpec, _, err := r.resolve(namespace, nameAndTag)
+	return pullSpec, err
+}
+
+// ImageStreamTag implements trigger.TagRetriever. It returns the digest pull spec of the tag and the resource version
+// of the image stream it was resolved from.
+func (r *Resolver) ImageStreamTag(namespace, nameAndTag string) (string, int64, bool) {
+	pullSpec, resourceVersion, err := r.resolve(namespace, nameAndTag)
+	if err != nil {
+		return "", 0, false
+	}
+	rv, err := strconv.ParseInt(resourceVersion, 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return pullSpec, rv, true
+}
+
+func (r *Resolver) resolve(namespace, nameAndTag string) (string, string, error) {
+	name, tag, _ := imageutil.SplitImageStreamTag(nameAndTag)
+	stream, err := r.lister.ImageStreams(namespace).Get(name)
+	if err != nil {
+		return "", "", err
+	}
+	latest := imageutil.LatestTaggedImage(stream, tag)
+	if latest == nil {
+		return "", "", fmt.Errorf("imagestreamtag %s/%s: %w", namespace, imageutil.JoinImageStreamTag(name, tag), ErrTagNotImported)
+	}
+	pullSpec, _ := imageutil.ResolveLatestTaggedImage(stream, tag)
+
+	ref, err := imagereference.Parse(pullSpec)
+	if err != nil {
+		return "", "", fmt.Errorf("imagestreamtag %s/%s resolves to invalid pull spec %q: %w", namespace, imageutil.JoinImageStreamTag(name, tag), pullSpec, err)
+	}
+	if len(ref.ID) == 0 {
+		// pushed images and the Source reference policy may reference the image by tag
+		if len(latest.Image) == 0 {
+			return "", "", fmt.Errorf("imagestreamtag %s/%s has no image digest for %q", namespace, imageutil.JoinImageStreamTag(name, tag), pullSpec)
+		}
+		ref.ID = latest.Image
+	}
+	return ref.Exact(), stream.ResourceVersion, nil
+}
@@ -0,0 +1,116 @@
+package imagestream
+
+import (
+	"errors"
+	"testing"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	imagev1listers "github.com/openshift/client-go/image/listers/image/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const digest = "sha256:0000000000000000000000000000000000000000000000000000000000000001"
+
+func newResolver(t *testing.T, streams ...*imagev1.ImageStream) *Resolver {
+	t.Helper()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, stream := range streams {
+		if err := indexer.Add(stream); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewResolver(imagev1listers.NewImageStreamLister(indexer))
+}
+
+func newStream(policy imagev1.TagReferencePolicyType, reference string) *imagev1.ImageStream {
+	return &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "operand", Name: "operand", ResourceVersion: "42"},
+		Spec: imagev1.ImageStreamSpec{Tags: []imagev1.TagReference{
+			{Name: "latest", ReferencePolicy: imagev1.TagReferencePolicy{Type: policy}},
+		}},
+		Status: imagev1.ImageStreamStatus{
+			DockerImageRepository: "image-registry.openshift-image-registry.svc:5000/operand/operand",
+			Tags: []imagev1.NamedTagEventList{
+				{Tag: "latest", Items: []imagev1.TagEvent{{DockerImageReference: reference, Image: digest}}},
+			},
+		},
+	}
+}
+
+func TestResolve(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		stream      *imagev1.ImageStream
+		nameAndTag  string
+		expected    string
+		expectError error
+	}{
+		{
+			name:       "source policy",
+			stream:     newStream(imagev1.SourceTagReferencePolicy, "quay.io/openshift/operand@"+digest),
+			nameAndTag: "operand:latest",
+			expected:   "quay.io/openshift/operand@" + digest,
+		},
+		{
+			name:       "local policy",
+			stream:     newStream(imagev1.LocalTagReferencePolicy, "quay.io/openshift/operand@"+digest),
+			nameAndTag: "operand:latest",
+			expected:   "image-registry.openshift-image-registry.svc:5000/operand/operand@" + digest,
+		},
+		{
+			name:       "tag reference gets the digest",
+			stream:     newStream(imagev1.SourceTagReferencePolicy, "quay.io/openshift/operand:v1"),
+			nameAndTag: "operand",
+			expected:   "quay.io/openshift/operand@" + digest,
+		},
+		{
+			name:        "not imported",
+			stream:      newStream(imagev1.SourceTagReferencePolicy, "quay.io/openshift/operand@"+digest),
+			nameAndTag:  "operand:v2",
+			expectError: ErrTagNotImported,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resolver := newResolver(t, tc.stream)
+			pullSpec, err := resolver.Resolve("operand", tc.nameAndTag)
+			if tc.expectError != nil {
+				if !errors.Is(err, tc.expectError) {
+					t.Fatalf("expected %v, got %v", tc.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pullSpec != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, pullSpec)
+			}
+			if _, rv, ok := resolver.ImageStreamTag("operand", tc.nameAndTag); !ok || rv != 42 {
+				t.Errorf("expected resource version 42, got %d", rv)
+			}
+		})
+	}
+}
+
+func TestWithDeploymentHook(t *testing.T) {
+	resolver := newResolver(t, newStream(imagev1.SourceTagReferencePolicy, "quay.io/openshift/operand@"+digest))
+	deployment := &appsv1.Deployment{}
+	deployment.Spec.Template.Spec.Containers = []corev1.Container{{Name: "operand", Image: "placeholder"}, {Name: "sidecar", Image: "sidecar"}}
+
+	if err := WithDeploymentHook(resolver, "operand", map[string]string{"operand": "operand:latest"})(nil, deployment); err != nil {
+		t.Fatal(err)
+	}
+	if image := deployment.Spec.Template.Spec.Containers[0].Image; image != "quay.io/openshift/operand@"+digest {
+		t.Errorf("unexpected image %q", image)
+	}
+	if image := deployment.Spec.Template.Spec.Containers[1].Image; image != "sidecar" {
+		t.Errorf("expected sidecar to be untouched, got %q", image)
+	}
+
+	if err := WithDeploymentHook(resolver, "operand", map[string]string{"missing": "operand:latest"})(nil, deployment); err == nil {
+		t.Error("expected error for missing container")
+	}
+}
@@ -0,0 +1,147 @@
+// Package servicereadiness reports the readiness of the endpoints of the operand services from their EndpointSlices,
+// across IP families, e.g. to wait for an operand to serve before rolling out what depends on it.
+package servicereadiness
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	discoveryv1informers "k8s.io/client-go/informers/discovery/v1"
+	discoveryv1listers "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+)
+
+// Readiness is the readiness of the endpoints of a service.
+type Readiness struct {
+	// Ready and NotReady count the endpoints, an endpoint with an IPv4 and an IPv6 address counts once per family.
+	Ready    int
+	NotReady int
+	// ReadyByFamily counts the ready endpoints by IP family.
+	ReadyByFamily map[corev1.IPFamily]int
+	// ReadyByZone counts the ready endpoints by zone, the endpoints without a zone are counted under "".
+	ReadyByZone map[string]int
+}
+
+// IsReady returns true when the service has a ready endpoint in every family, or in any family when none is given.
+func (r Readiness) IsReady(families ...corev1.IPFamily) bool {
+	if len(families) == 0 {
+		return r.Ready > 0
+	}
+	for _, family := range families {
+		if r.ReadyByFamily[family] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a summary of the readiness, e.g. "2 ready (IPv4: 2, zone-a: 1, zone-b: 1), 1 not ready".
+func (r Readiness) String() string {
+	var details []string
+	for _, family := range sets.List(sets.KeySet(r.ReadyByFamily)) {
+		details = append(details, fmt.Sprintf("%s: %d", family, r.ReadyByFamily[family]))
+	}
+	for _, zone := range sets.List(sets.KeySet(r.ReadyByZone)) {
+		if len(zone) > 0 {
+			details = append(details, fmt.Sprintf("%s: %d", zone, r.ReadyByZone[zone]))
+		}
+	}
+	ret := fmt.Sprintf("%d ready", r.Ready)
+	if len(details) > 0 {
+		ret += " (" + strings.Join(details, ", ") + ")"
+	}
+	return fmt.Sprintf("%s, %d not ready", ret, r.NotReady)
+}
+
+// ReadinessFor returns the readiness of the endpoints of the EndpointSlices of a service. An endpoint listed by several
+// slices, e.g. while they are rebalanced, counts once. The FQDN slices are ignored.
+func ReadinessFor(slices []*discoveryv1.EndpointSlice) Readiness {
+	ret := Readiness{ReadyByFamily: map[corev1.IPFamily]int{}, ReadyByZone: map[string]int{}}
+	// slices are sorted by name, so the counts do not depend on the order of the lister
+	sorted := append([]*discoveryv1.EndpointSlice{}, slices...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	seen := sets.New[string]()
+	for _, slice := range sorted {
+		var family corev1.IPFamily
+		switch slice.AddressType {
+		case discoveryv1.AddressTypeIPv4:
+			family = corev1.IPv4Protocol
+		case discoveryv1.AddressTypeIPv6:
+			family = corev1.IPv6Protocol
+		default:
+			continue
+		}
+		for _, endpoint := range slice.Endpoints {
+			if len(endpoint.Addresses) == 0 {
+				continue
+			}
+			key := string(family) + "/" + endpoint.Addresses[0]
+			if seen.Has(key) {
+				continue
+			}
+			seen.Insert(key)
+
+			// an unknown readiness means ready
+			if !ptr.Deref(endpoint.Conditions.Ready, true) {
+				ret.NotReady++
+				continue
+			}
+			ret.Ready++
+			ret.ReadyByFamily[family]++
+			ret.ReadyByZone[ptr.Deref(endpoint.Zone, "")]++
+		}
+	}
+	return ret
+}
+
+// ServiceReadiness reports the readiness of services from the EndpointSlices in an informer.
+type ServiceReadiness struct {
+	lister   discoveryv1listers.EndpointSliceLister
+	informer cache.SharedIndexInformer
+}
+
+// NewServiceReadiness returns a ServiceReadiness backed by the informer of the EndpointSlices of the namespaces of the
+// services. The informer must be started by the caller.
+func NewServiceReadiness(informer discoveryv1informers.EndpointSliceInformer) *ServiceReadiness {
+	return &ServiceReadiness{
+		lister:   informer.Lister(),
+		informer: informer.Informer(),
+	}
+}
+
+// Informer returns the EndpointSlice informer, for the controllers to sync when the readiness changes.
+func (s *ServiceReadiness) Informer() cache.SharedIndexInformer {
+	return s.informer
+}
+
+// Readiness returns the readiness of the service.
+func (s *ServiceReadiness) Readiness(namespace, service string) (Readiness, error) {
+	slices, err := s.lister.EndpointSlices(namespace).List(labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: service}))
+	if err != nil {
+		return Readiness{}, err
+	}
+	return ReadinessFor(slices), nil
+}
+
+// Precondition returns a precondition, e.g. a revisioncontroller.PreconditionFunc, met when the service has a ready
+// endpoint in every family, or in any family when none is given.
+func (s *ServiceReadiness) Precondition(namespace, service string, families ...corev1.IPFamily) func(ctx context.Context) (bool, error) {
+	return func(context.Context) (bool, error) {
+		if !s.informer.HasSynced() {
+			return false, nil
+		}
+		readiness, err := s.Readiness(namespace, service)
+		if err != nil {
+			return false, err
+		}
+		return readiness.IsReady(families...), nil
+	}
+}
@@ -0,0 +1,90 @@
+package servicereadiness
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+)
+
+func endpoint(address, zone string, ready *bool) discoveryv1.Endpoint {
+	return discoveryv1.Endpoint{Addresses: []string{address}, Zone: ptr.To(zone), Conditions: discoveryv1.EndpointConditions{Ready: ready}}
+}
+
+func slice(name, service string, addressType discoveryv1.AddressType, endpoints ...discoveryv1.Endpoint) *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta:  metav1.ObjectMeta{Namespace: "ns", Name: name, Labels: map[string]string{discoveryv1.LabelServiceName: service}},
+		AddressType: addressType,
+		Endpoints:   endpoints,
+	}
+}
+
+func TestReadinessFor(t *testing.T) {
+	readiness := ReadinessFor([]*discoveryv1.EndpointSlice{
+		slice("api-v4-a", "api", discoveryv1.AddressTypeIPv4,
+			endpoint("10.0.0.1", "zone-a", ptr.To(true)),
+			endpoint("10.0.0.2", "zone-b", nil),
+			endpoint("10.0.0.3", "zone-b", ptr.To(false))),
+		// the endpoint moved to another slice is counted once
+		slice("api-v4-b", "api", discoveryv1.AddressTypeIPv4, endpoint("10.0.0.1", "zone-a", ptr.To(true))),
+		slice("api-v6", "api", discoveryv1.AddressTypeIPv6, endpoint("fd00::1", "zone-a", ptr.To(true))),
+		slice("api-fqdn", "api", discoveryv1.AddressTypeFQDN, endpoint("api.example.com", "", ptr.To(true))),
+	})
+
+	if readiness.Ready != 3 || readiness.NotReady != 1 {
+		t.Errorf("unexpected counts %s", readiness)
+	}
+	if readiness.ReadyByFamily[corev1.IPv4Protocol] != 2 || readiness.ReadyByFamily[corev1.IPv6Protocol] != 1 {
+		t.Errorf("unexpected families %v", readiness.ReadyByFamily)
+	}
+	if readiness.ReadyByZone["zone-a"] != 2 || readiness.ReadyByZone["zone-b"] != 1 {
+		t.Errorf("unexpected zones %v", readiness.ReadyByZone)
+	}
+	if !readiness.IsReady() || !readiness.IsReady(corev1.IPv4Protocol, corev1.IPv6Protocol) {
+		t.Errorf("expected the service to be ready in both families")
+	}
+	if expected := "3 ready (IPv4: 2, IPv6: 1, zone-a: 2, zone-b: 1), 1 not ready"; readiness.String() != expected {
+		t.Errorf("expected %q, got %q", expected, readiness.String())
+	}
+}
+
+func TestPrecondition(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := fake.NewSimpleClientset(
+		slice("api-v4", "api", discoveryv1.AddressTypeIPv4, endpoint("10.0.0.1", "", ptr.To(true))),
+		slice("other-v6", "other", discoveryv1.AddressTypeIPv6, endpoint("fd00::1", "", ptr.To(true))),
+	)
+	factory := informers.NewSharedInformerFactoryWithOptions(client, 0, informers.WithNamespace("ns"))
+	readiness := NewServiceReadiness(factory.Discovery().V1().EndpointSlices())
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), readiness.Informer().HasSynced) {
+		t.Fatal("caches did not sync")
+	}
+
+	for _, tt := range []struct {
+		service  string
+		families []corev1.IPFamily
+		expected bool
+	}{
+		{service: "api", expected: true},
+		{service: "api", families: []corev1.IPFamily{corev1.IPv4Protocol}, expected: true},
+		{service: "api", families: []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}, expected: false},
+		{service: "missing", expected: false},
+	} {
+		ready, err := readiness.Precondition("ns", tt.service, tt.families...)(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ready != tt.expected {
+			t.Errorf("expected service %s to be ready in %v: %v, got %v", tt.service, tt.families, tt.expected, ready)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package admissionpolicy
+
+import (
+	"context"
+	"sync"
+
+	admissionregistrationclientv1 "k8s.io/client-go/kubernetes/typed/admissionregistration/v1"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+)
+
+var (
+	applyMetric = metrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      "admissionpolicy",
+		Name:           "apply_total",
+		Help:           "Number of applies of generated admission policies by policy, mode and result.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"policy", "mode", "result"})
+
+	modeMetric = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Subsystem:      "admissionpolicy",
+		Name:           "mode",
+		Help:           "The mode of the last applied generated admission policy, 1 for the current mode.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"policy", "mode"})
+
+	typeCheckingWarningsMetric = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Subsystem:      "admissionpolicy",
+		Name:           "type_checking_warnings",
+		Help:           "Number of type checking warnings the API server reported for the generated admission policy.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"policy"})
+)
+
+func init() {
+	(&sync.Once{}).Do(func() {
+		legacyregistry.MustRegister(applyMetric)
+		legacyregistry.MustRegister(modeMetric)
+		legacyregistry.MustRegister(typeCheckingWarningsMetric)
+	})
+}
+
+// ApplyPolicy generates the ValidatingAdmissionPolicy and ValidatingAdmissionPolicyBinding of the policy and applies
+// them, the policy first so the binding never references a missing policy. It returns true when any of them changed.
+func ApplyPolicy(ctx context.Context, client admissionregistrationclientv1.AdmissionregistrationV1Interface, recorder events.Recorder, policy Policy, cache resourceapply.ResourceCache) (bool, error) {
+	changed, err := applyPolicy(ctx, client, recorder, policy, cache)
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	applyMetric.WithLabelValues(policy.Name, string(policy.mode()), result).Inc()
+	return changed, err
+}
+
+func applyPolicy(ctx context.Context, client admissionregistrationclientv1.AdmissionregistrationV1Interface, recorder events.Recorder, policy Policy, cache resourceapply.ResourceCache) (bool, error) {
+	requiredPolicy, err := policy.ValidatingAdmissionPolicy()
+	if err != nil {
+		return false, err
+	}
+	requiredBinding, err := policy.ValidatingAdmissionPolicyBinding()
+	if err != nil {
+		return false, err
+	}
+
+	actualPolicy, policyChanged, err := resourceapply.ApplyValidatingAdmissionPolicyV1(ctx, client, recorder, requiredPolicy, cache)
+	if err != nil {
+		return false, err
+	}
+	if actualPolicy.Status.TypeChecking != nil {
+		typeCheckingWarningsMetric.WithLabelValues(policy.Name).Set(float64(len(actualPolicy.Status.TypeChecking.ExpressionWarnings)))
+	}
+
+	_, bindingChanged, err := resourceapply.ApplyValidatingAdmissionPolicyBindingV1(ctx, client, recorder, requiredBinding, cache)
+	if err != nil {
+		return policyChanged, err
+	}
+	for _, mode := range []Mode{Enforce, Warn, Audit} {
+		value := 0.0
+		if mode == policy.mode() {
+			value = 1
+		}
+		modeMetric.WithLabelValues(policy.Name, string(mode)).Set(value)
+	}
+	return policyChanged || bindingChanged, nil
+}
+
+// DeletePolicy removes the binding and the policy, in this order.
+func DeletePolicy(ctx context.Context, client admissionregistrationclientv1.AdmissionregistrationV1Interface, recorder events.Recorder, policy Policy) (bool, error) {
+	requiredPolicy, err := policy.ValidatingAdmissionPolicy()
+	if err != nil {
+		return false, err
+	}
+	requiredBinding, err := policy.ValidatingAdmissionPolicyBinding()
+	if err != nil {
+		return false, err
+	}
+	_, bindingDeleted, err := resourceapply.DeleteValidatingAdmissionPolicyBindingV1(ctx, client, recorder, requiredBinding)
+	if err != nil {
+		return false, err
+	}
+	_, policyDeleted, err := resourceapply.DeleteValidatingAdmissionPolicyV1(ctx, client, recorder, requiredPolicy)
+	if err != nil {
+		return bindingDeleted, err
+	}
+	modeMetric.DeletePartialMatch(map[string]string{"policy": policy.Name})
+	typeCheckingWarningsMetric.DeletePartialMatch(map[string]string{"policy": policy.Name})
+	return bindingDeleted || policyDeleted, nil
+}
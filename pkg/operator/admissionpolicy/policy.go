@@ -0,0 +1,224 @@
+package admissionpolicy
+
+import (
+	"fmt"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// Mode controls what happens to requests violating a rule of the policy.
+type Mode string
+
+const (
+	// Enforce denies violating requests.
+	Enforce Mode = "Enforce"
+	// Warn admits violating requests and returns a warning to the client.
+	Warn Mode = "Warn"
+	// Audit admits violating requests and records the violation in the audit log only. It is meant to roll out new
+	// rules before they are enforced.
+	Audit Mode = "Audit"
+)
+
+// Rule is a single CEL validation of the policy.
+type Rule struct {
+	// Expression is the CEL expression that must evaluate to true, e.g. "object.spec.replicas <= 5".
+	Expression string
+	// Message is returned when the expression evaluates to false.
+	Message string
+	// MessageExpression is an optional CEL expression producing the message, it takes precedence over Message.
+	MessageExpression string
+	// Reason is the status reason returned to the client, it defaults to Invalid.
+	Reason *metav1.StatusReason
+}
+
+// Resources matches requests for a set of resources.
+type Resources struct {
+	APIGroups   []string
+	APIVersions []string
+	Resources   []string
+	// Operations defaults to CREATE and UPDATE.
+	Operations []admissionregistrationv1.OperationType
+}
+
+// Variable is a named CEL expression that can be referenced from the rules as variables.<name>.
+type Variable struct {
+	Name       string
+	Expression string
+}
+
+// Policy declares a set of CEL rules protecting operand resources. It generates a ValidatingAdmissionPolicy and
+// a ValidatingAdmissionPolicyBinding named after the policy.
+type Policy struct {
+	Name   string
+	Labels map[string]string
+
+	// Match lists the resources the policy applies to, at least one is required.
+	Match []Resources
+	// NamespaceSelector and ObjectSelector restrict the matched requests further.
+	NamespaceSelector *metav1.LabelSelector
+	ObjectSelector    *metav1.LabelSelector
+	// MatchConditions are CEL expressions that must all evaluate to true for the rules to be evaluated.
+	MatchConditions []Variable
+	Variables       []Variable
+	Rules           []Rule
+
+	// Mode defaults to Enforce.
+	Mode Mode
+	// FailurePolicy controls what happens when a rule fails to evaluate, it defaults to Fail.
+	FailurePolicy *admissionregistrationv1.FailurePolicyType
+}
+
+// Validate checks the policy for errors that would make the API server reject the generated objects. CEL
+// expressions are compiled by the API server only.
+func (p Policy) Validate() error {
+	if len(p.Name) == 0 {
+		return fmt.Errorf("policy name is required")
+	}
+	if len(p.Match) == 0 {
+		return fmt.Errorf("policy %q: at least one match is required", p.Name)
+	}
+	if len(p.Rules) == 0 {
+		return fmt.Errorf("policy %q: at least one rule is required", p.Name)
+	}
+	for i, rule := range p.Rules {
+		if len(rule.Expression) == 0 {
+			return fmt.Errorf("policy %q: rule %d has no expression", p.Name, i)
+		}
+	}
+	for _, variable := range append(append([]Variable{}, p.Variables...), p.MatchConditions...) {
+		if len(variable.Name) == 0 || len(variable.Expression) == 0 {
+			return fmt.Errorf("policy %q: variables and match conditions need a name and an expression", p.Name)
+		}
+	}
+	if _, err := p.validationActions(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ValidatingAdmissionPolicy returns the policy object.
+func (p Policy) ValidatingAdmissionPolicy() (*admissionregistrationv1.ValidatingAdmissionPolicy, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	failurePolicy := ptr.To(admissionregistrationv1.Fail)
+	if p.FailurePolicy != nil {
+		failurePolicy = ptr.To(*p.FailurePolicy)
+	}
+	policy := &admissionregistrationv1.ValidatingAdmissionPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   p.Name,
+			Labels: copyLabels(p.Labels),
+		},
+		Spec: admissionregistrationv1.ValidatingAdmissionPolicySpec{
+			FailurePolicy:    failurePolicy,
+			MatchConstraints: p.matchResources(),
+		},
+	}
+	for _, condition := range p.MatchConditions {
+		policy.Spec.MatchConditions = append(policy.Spec.MatchConditions, admissionregistrationv1.MatchCondition{
+			Name:       condition.Name,
+			Expression: condition.Expression,
+		})
+	}
+	for _, variable := range p.Variables {
+		policy.Spec.Variables = append(policy.Spec.Variables, admissionregistrationv1.Variable{
+			Name:       variable.Name,
+			Expression: variable.Expression,
+		})
+	}
+	for _, rule := range p.Rules {
+		policy.Spec.Validations = append(policy.Spec.Validations, admissionregistrationv1.Validation{
+			Expression:        rule.Expression,
+			Message:           rule.Message,
+			MessageExpression: rule.MessageExpression,
+			Reason:            rule.Reason,
+		})
+	}
+	return policy, nil
+}
+
+// ValidatingAdmissionPolicyBinding returns the binding of the policy, with validation actions for the mode.
+func (p Policy) ValidatingAdmissionPolicyBinding() (*admissionregistrationv1.ValidatingAdmissionPolicyBinding, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	actions, err := p.validationActions()
+	if err != nil {
+		return nil, err
+	}
+	return &admissionregistrationv1.ValidatingAdmissionPolicyBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   p.Name,
+			Labels: copyLabels(p.Labels),
+		},
+		Spec: admissionregistrationv1.ValidatingAdmissionPolicyBindingSpec{
+			PolicyName:        p.Name,
+			ValidationActions: actions,
+		},
+	}, nil
+}
+
+func (p Policy) mode() Mode {
+	if len(p.Mode) == 0 {
+		return Enforce
+	}
+	return p.Mode
+}
+
+func (p Policy) validationActions() ([]admissionregistrationv1.ValidationAction, error) {
+	switch p.mode() {
+	case Enforce:
+		return []admissionregistrationv1.ValidationAction{admissionregistrationv1.Deny}, nil
+	case Warn:
+		return []admissionregistrationv1.ValidationAction{admissionregistrationv1.Warn, admissionregistrationv1.Audit}, nil
+	case Audit:
+		return []admissionregistrationv1.ValidationAction{admissionregistrationv1.Audit}, nil
+	}
+	return nil, fmt.Errorf("policy %q: unknown mode %q", p.Name, p.Mode)
+}
+
+func (p Policy) matchResources() *admissionregistrationv1.MatchResources {
+	match := &admissionregistrationv1.MatchResources{
+		NamespaceSelector: p.NamespaceSelector.DeepCopy(),
+		ObjectSelector:    p.ObjectSelector.DeepCopy(),
+		MatchPolicy:       ptr.To(admissionregistrationv1.Equivalent),
+	}
+	if match.NamespaceSelector == nil {
+		match.NamespaceSelector = &metav1.LabelSelector{}
+	}
+	if match.ObjectSelector == nil {
+		match.ObjectSelector = &metav1.LabelSelector{}
+	}
+	for _, resources := range p.Match {
+		operations := resources.Operations
+		if len(operations) == 0 {
+			operations = []admissionregistrationv1.OperationType{admissionregistrationv1.Create, admissionregistrationv1.Update}
+		}
+		match.ResourceRules = append(match.ResourceRules, admissionregistrationv1.NamedRuleWithOperations{
+			RuleWithOperations: admissionregistrationv1.RuleWithOperations{
+				Operations: operations,
+				Rule: admissionregistrationv1.Rule{
+					APIGroups:   resources.APIGroups,
+					APIVersions: resources.APIVersions,
+					Resources:   resources.Resources,
+				},
+			},
+		})
+	}
+	return match
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	ret := make(map[string]string, len(labels))
+	for k, v := range labels {
+		ret[k] = v
+	}
+	return ret
+}
@@ -0,0 +1,136 @@
+package admissionpolicy
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+)
+
+func testPolicy() Policy {
+	return Policy{
+		Name:   "replica-limit",
+		Labels: map[string]string{"app": "operand"},
+		Match: []Resources{{
+			APIGroups:   []string{"apps"},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"deployments"},
+		}},
+		Variables: []Variable{{Name: "replicas", Expression: "object.spec.replicas"}},
+		Rules: []Rule{{
+			Expression: "variables.replicas <= 5",
+			Message:    "at most 5 replicas are allowed",
+		}},
+	}
+}
+
+func TestValidatingAdmissionPolicy(t *testing.T) {
+	policy, err := testPolicy().ValidatingAdmissionPolicy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *policy.Spec.FailurePolicy != admissionregistrationv1.Fail {
+		t.Errorf("expected failure policy Fail, got %v", *policy.Spec.FailurePolicy)
+	}
+	rules := policy.Spec.MatchConstraints.ResourceRules
+	if len(rules) != 1 || !reflect.DeepEqual(rules[0].Operations, []admissionregistrationv1.OperationType{admissionregistrationv1.Create, admissionregistrationv1.Update}) {
+		t.Errorf("unexpected resource rules %#v", rules)
+	}
+	if len(policy.Spec.Validations) != 1 || policy.Spec.Validations[0].Expression != "variables.replicas <= 5" {
+		t.Errorf("unexpected validations %#v", policy.Spec.Validations)
+	}
+	if len(policy.Spec.Variables) != 1 {
+		t.Errorf("unexpected variables %#v", policy.Spec.Variables)
+	}
+}
+
+func TestValidatingAdmissionPolicyBindingModes(t *testing.T) {
+	tests := []struct {
+		mode     Mode
+		expected []admissionregistrationv1.ValidationAction
+	}{
+		{"", []admissionregistrationv1.ValidationAction{admissionregistrationv1.Deny}},
+		{Enforce, []admissionregistrationv1.ValidationAction{admissionregistrationv1.Deny}},
+		{Warn, []admissionregistrationv1.ValidationAction{admissionregistrationv1.Warn, admissionregistrationv1.Audit}},
+		{Audit, []admissionregistrationv1.ValidationAction{admissionregistrationv1.Audit}},
+	}
+	for _, test := range tests {
+		t.Run(string(test.mode), func(t *testing.T) {
+			policy := testPolicy()
+			policy.Mode = test.mode
+			binding, err := policy.ValidatingAdmissionPolicyBinding()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if binding.Spec.PolicyName != policy.Name {
+				t.Errorf("expected binding to reference %q, got %q", policy.Name, binding.Spec.PolicyName)
+			}
+			if !reflect.DeepEqual(binding.Spec.ValidationActions, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, binding.Spec.ValidationActions)
+			}
+		})
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	tests := map[string]func(*Policy){
+		"no name":        func(p *Policy) { p.Name = "" },
+		"no match":       func(p *Policy) { p.Match = nil },
+		"no rules":       func(p *Policy) { p.Rules = nil },
+		"empty rule":     func(p *Policy) { p.Rules[0].Expression = "" },
+		"empty variable": func(p *Policy) { p.Variables[0].Name = "" },
+		"unknown mode":   func(p *Policy) { p.Mode = "Block" },
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy := testPolicy()
+			mutate(&policy)
+			if err := policy.Validate(); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestApplyPolicy(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	recorder := events.NewInMemoryRecorder("test")
+	policy := testPolicy()
+	policy.Mode = Audit
+
+	changed, err := ApplyPolicy(context.TODO(), client.AdmissionregistrationV1(), recorder, policy, resourceapply.NewResourceCache())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Errorf("expected the policy to be created")
+	}
+
+	policy.Mode = Enforce
+	if changed, err = ApplyPolicy(context.TODO(), client.AdmissionregistrationV1(), recorder, policy, resourceapply.NewResourceCache()); err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Errorf("expected the binding to be updated")
+	}
+	binding, err := client.AdmissionregistrationV1().ValidatingAdmissionPolicyBindings().Get(context.TODO(), policy.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(binding.Spec.ValidationActions, []admissionregistrationv1.ValidationAction{admissionregistrationv1.Deny}) {
+		t.Errorf("expected the policy to be enforced, got %v", binding.Spec.ValidationActions)
+	}
+
+	if _, err := DeletePolicy(context.TODO(), client.AdmissionregistrationV1(), recorder, policy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.AdmissionregistrationV1().ValidatingAdmissionPolicies().Get(context.TODO(), policy.Name, metav1.GetOptions{}); err == nil {
+		t.Errorf("expected the policy to be deleted")
+	}
+}
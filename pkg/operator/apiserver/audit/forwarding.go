@@ -0,0 +1,219 @@
+package audit
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ForwardingTargetType is where a forwarding sidecar sends the audit events to.
+type ForwardingTargetType string
+
+const (
+	// FileForwardingTarget writes the audit events to a file on the host, rotated by size.
+	FileForwardingTarget ForwardingTargetType = "File"
+	// SyslogForwardingTarget sends the audit events to a syslog endpoint.
+	SyslogForwardingTarget ForwardingTargetType = "Syslog"
+	// WebhookForwardingTarget posts the audit events to an HTTPS endpoint.
+	WebhookForwardingTarget ForwardingTargetType = "Webhook"
+
+	forwardingContainerName  = "audit-forwarder"
+	auditLogVolumeName       = "audit-forwarder-audit-dir"
+	outputVolumeName         = "audit-forwarder-output-dir"
+	webhookCAVolumeName      = "audit-forwarder-webhook-ca"
+	webhookCAMountPath       = "/etc/audit-forwarder/webhook-ca"
+	webhookCAKey             = "ca-bundle.crt"
+	defaultRotationMaxSizeMB = 100
+	defaultRotationBackups   = 10
+)
+
+// ForwardingConfig describes a sidecar tailing the audit log of an apiserver and forwarding the events.
+//
+// The sidecar runs Command, e.g. a subcommand of the operator binary, with the flags
+//
+//	--audit-log-path, --target and, depending on the target, --output-path, --max-size-mb and --max-backups,
+//	--syslog-endpoint and --syslog-protocol, or --webhook-url and --webhook-ca-file.
+type ForwardingConfig struct {
+	Image   string
+	Command []string
+
+	// AuditLogPath is the path of the audit log on the host, e.g. /var/log/kube-apiserver/audit.log. Its directory is
+	// mounted read-only at the same path.
+	AuditLogPath string
+
+	Target ForwardingTargetType
+
+	// OutputPath is the path of the file on the host the File target writes to. Its directory is mounted at the same
+	// path and must differ from the directory of the audit log.
+	OutputPath string
+	// MaxSizeMB is the size in megabytes after which the output file is rotated. Defaults to 100.
+	MaxSizeMB int
+	// MaxBackups is the number of rotated output files kept. Defaults to 10.
+	MaxBackups int
+
+	// SyslogEndpoint is the host:port of the syslog endpoint of the Syslog target.
+	SyslogEndpoint string
+	// SyslogProtocol is tcp or udp, defaults to tcp.
+	SyslogProtocol string
+
+	// WebhookURL is the https URL of the Webhook target.
+	WebhookURL string
+	// WebhookCAConfigMap optionally names a configmap in the namespace of the pod with the CA bundle, under the
+	// ca-bundle.crt key, to verify the webhook with.
+	WebhookCAConfigMap string
+}
+
+// Validate checks that the settings required by the target are set.
+func (c ForwardingConfig) Validate() error {
+	if len(c.Image) == 0 || len(c.Command) == 0 {
+		return fmt.Errorf("image and command of the audit forwarder are required")
+	}
+	if !filepath.IsAbs(c.AuditLogPath) {
+		return fmt.Errorf("audit log path %q must be absolute", c.AuditLogPath)
+	}
+	if c.MaxSizeMB < 0 || c.MaxBackups < 0 {
+		return fmt.Errorf("rotation settings must not be negative")
+	}
+
+	switch c.Target {
+	case FileForwardingTarget:
+		if !filepath.IsAbs(c.OutputPath) {
+			return fmt.Errorf("output path %q must be absolute", c.OutputPath)
+		}
+		if filepath.Dir(c.OutputPath) == filepath.Dir(c.AuditLogPath) {
+			return fmt.Errorf("output path %q must not be in the directory of the audit log", c.OutputPath)
+		}
+	case SyslogForwardingTarget:
+		if _, _, err := net.SplitHostPort(c.SyslogEndpoint); err != nil {
+			return fmt.Errorf("invalid syslog endpoint %q: %w", c.SyslogEndpoint, err)
+		}
+		if len(c.SyslogProtocol) > 0 && c.SyslogProtocol != "tcp" && c.SyslogProtocol != "udp" {
+			return fmt.Errorf("syslog protocol must be tcp or udp, got %q", c.SyslogProtocol)
+		}
+	case WebhookForwardingTarget:
+		webhookURL, err := url.Parse(c.WebhookURL)
+		if err != nil {
+			return fmt.Errorf("invalid webhook URL %q: %w", c.WebhookURL, err)
+		}
+		if webhookURL.Scheme != "https" || len(webhookURL.Host) == 0 {
+			return fmt.Errorf("webhook URL %q must be an https URL", c.WebhookURL)
+		}
+	default:
+		return fmt.Errorf("unknown audit forwarding target %q", c.Target)
+	}
+	return nil
+}
+
+// ForwardingSidecar renders the container and the volumes of the audit forwarding sidecar to add to the apiserver pod.
+func ForwardingSidecar(config ForwardingConfig) (*corev1.Container, []corev1.Volume, error) {
+	if err := config.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	hostPathDirectoryOrCreate := corev1.HostPathDirectoryOrCreate
+	auditLogDir := filepath.Dir(config.AuditLogPath)
+	volumes := []corev1.Volume{{
+		Name:         auditLogVolumeName,
+		VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: auditLogDir}},
+	}}
+	container := &corev1.Container{
+		Name:    forwardingContainerName,
+		Image:   config.Image,
+		Command: config.Command,
+		Args: []string{
+			"--audit-log-path=" + config.AuditLogPath,
+			"--target=" + string(config.Target),
+		},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("5m"),
+				corev1.ResourceMemory: resource.MustParse("50Mi"),
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: auditLogVolumeName, MountPath: auditLogDir, ReadOnly: true},
+		},
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}
+
+	switch config.Target {
+	case FileForwardingTarget:
+		maxSizeMB, maxBackups := config.MaxSizeMB, config.MaxBackups
+		if maxSizeMB == 0 {
+			maxSizeMB = defaultRotationMaxSizeMB
+		}
+		if maxBackups == 0 {
+			maxBackups = defaultRotationBackups
+		}
+		outputDir := filepath.Dir(config.OutputPath)
+		container.Args = append(container.Args,
+			"--output-path="+config.OutputPath,
+			"--max-size-mb="+strconv.Itoa(maxSizeMB),
+			"--max-backups="+strconv.Itoa(maxBackups),
+		)
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: outputVolumeName, MountPath: outputDir})
+		volumes = append(volumes, corev1.Volume{
+			Name:         outputVolumeName,
+			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: outputDir, Type: &hostPathDirectoryOrCreate}},
+		})
+
+	case SyslogForwardingTarget:
+		protocol := config.SyslogProtocol
+		if len(protocol) == 0 {
+			protocol = "tcp"
+		}
+		container.Args = append(container.Args,
+			"--syslog-endpoint="+config.SyslogEndpoint,
+			"--syslog-protocol="+protocol,
+		)
+
+	case WebhookForwardingTarget:
+		container.Args = append(container.Args, "--webhook-url="+config.WebhookURL)
+		if len(config.WebhookCAConfigMap) > 0 {
+			container.Args = append(container.Args, "--webhook-ca-file="+path.Join(webhookCAMountPath, webhookCAKey))
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: webhookCAVolumeName, MountPath: webhookCAMountPath, ReadOnly: true})
+			volumes = append(volumes, corev1.Volume{
+				Name: webhookCAVolumeName,
+				VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: config.WebhookCAConfigMap},
+					Items:                []corev1.KeyToPath{{Key: webhookCAKey, Path: webhookCAKey}},
+				}},
+			})
+		}
+	}
+
+	return container, volumes, nil
+}
+
+// WithForwardingSidecar adds the audit forwarding sidecar and its volumes to the pod spec, replacing a previously
+// added sidecar.
+func WithForwardingSidecar(spec *corev1.PodSpec, config ForwardingConfig) error {
+	container, volumes, err := ForwardingSidecar(config)
+	if err != nil {
+		return err
+	}
+
+	containers := spec.Containers[:0]
+	for _, c := range spec.Containers {
+		if c.Name != forwardingContainerName {
+			containers = append(containers, c)
+		}
+	}
+	spec.Containers = append(containers, *container)
+
+	forwarderVolumes := map[string]bool{auditLogVolumeName: true, outputVolumeName: true, webhookCAVolumeName: true}
+	podVolumes := spec.Volumes[:0]
+	for _, v := range spec.Volumes {
+		if !forwarderVolumes[v.Name] {
+			podVolumes = append(podVolumes, v)
+		}
+	}
+	spec.Volumes = append(podVolumes, volumes...)
+	return nil
+}
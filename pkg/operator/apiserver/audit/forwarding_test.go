@@ -0,0 +1,173 @@
+package audit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestForwardingSidecar(t *testing.T) {
+	base := ForwardingConfig{
+		Image:        "operator:latest",
+		Command:      []string{"cluster-kube-apiserver-operator", "audit-forwarder"},
+		AuditLogPath: "/var/log/kube-apiserver/audit.log",
+	}
+
+	scenarios := []struct {
+		name          string
+		config        func(c *ForwardingConfig)
+		expectedArgs  []string
+		expectedVols  []string
+		expectedError string
+	}{
+		{
+			name: "file target with default rotation",
+			config: func(c *ForwardingConfig) {
+				c.Target = FileForwardingTarget
+				c.OutputPath = "/var/log/audit-forwarded/audit.log"
+			},
+			expectedArgs: []string{
+				"--audit-log-path=/var/log/kube-apiserver/audit.log",
+				"--target=File",
+				"--output-path=/var/log/audit-forwarded/audit.log",
+				"--max-size-mb=100",
+				"--max-backups=10",
+			},
+			expectedVols: []string{auditLogVolumeName, outputVolumeName},
+		},
+		{
+			name: "file target with custom rotation",
+			config: func(c *ForwardingConfig) {
+				c.Target = FileForwardingTarget
+				c.OutputPath = "/var/log/audit-forwarded/audit.log"
+				c.MaxSizeMB = 20
+				c.MaxBackups = 3
+			},
+			expectedArgs: []string{
+				"--audit-log-path=/var/log/kube-apiserver/audit.log",
+				"--target=File",
+				"--output-path=/var/log/audit-forwarded/audit.log",
+				"--max-size-mb=20",
+				"--max-backups=3",
+			},
+			expectedVols: []string{auditLogVolumeName, outputVolumeName},
+		},
+		{
+			name: "file target in the audit log directory",
+			config: func(c *ForwardingConfig) {
+				c.Target = FileForwardingTarget
+				c.OutputPath = "/var/log/kube-apiserver/forwarded.log"
+			},
+			expectedError: "must not be in the directory of the audit log",
+		},
+		{
+			name: "syslog target",
+			config: func(c *ForwardingConfig) {
+				c.Target = SyslogForwardingTarget
+				c.SyslogEndpoint = "syslog.example.com:514"
+			},
+			expectedArgs: []string{
+				"--audit-log-path=/var/log/kube-apiserver/audit.log",
+				"--target=Syslog",
+				"--syslog-endpoint=syslog.example.com:514",
+				"--syslog-protocol=tcp",
+			},
+			expectedVols: []string{auditLogVolumeName},
+		},
+		{
+			name: "syslog target with invalid protocol",
+			config: func(c *ForwardingConfig) {
+				c.Target = SyslogForwardingTarget
+				c.SyslogEndpoint = "syslog.example.com:514"
+				c.SyslogProtocol = "http"
+			},
+			expectedError: "syslog protocol must be tcp or udp",
+		},
+		{
+			name: "webhook target with CA",
+			config: func(c *ForwardingConfig) {
+				c.Target = WebhookForwardingTarget
+				c.WebhookURL = "https://audit.example.com/events"
+				c.WebhookCAConfigMap = "audit-webhook-ca"
+			},
+			expectedArgs: []string{
+				"--audit-log-path=/var/log/kube-apiserver/audit.log",
+				"--target=Webhook",
+				"--webhook-url=https://audit.example.com/events",
+				"--webhook-ca-file=/etc/audit-forwarder/webhook-ca/ca-bundle.crt",
+			},
+			expectedVols: []string{auditLogVolumeName, webhookCAVolumeName},
+		},
+		{
+			name: "webhook target without https",
+			config: func(c *ForwardingConfig) {
+				c.Target = WebhookForwardingTarget
+				c.WebhookURL = "http://audit.example.com/events"
+			},
+			expectedError: "must be an https URL",
+		},
+		{
+			name:          "unknown target",
+			config:        func(c *ForwardingConfig) { c.Target = "Kafka" },
+			expectedError: "unknown audit forwarding target",
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			config := base
+			scenario.config(&config)
+
+			container, volumes, err := ForwardingSidecar(config)
+			if len(scenario.expectedError) > 0 {
+				if err == nil || !strings.Contains(err.Error(), scenario.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", scenario.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(container.Args, scenario.expectedArgs) {
+				t.Errorf("unexpected args:\n%v\nexpected:\n%v", container.Args, scenario.expectedArgs)
+			}
+			var volumeNames []string
+			for _, v := range volumes {
+				volumeNames = append(volumeNames, v.Name)
+			}
+			if !reflect.DeepEqual(volumeNames, scenario.expectedVols) {
+				t.Errorf("unexpected volumes %v, expected %v", volumeNames, scenario.expectedVols)
+			}
+			if len(container.VolumeMounts) != len(volumes) {
+				t.Errorf("expected a mount per volume, got %d mounts for %d volumes", len(container.VolumeMounts), len(volumes))
+			}
+		})
+	}
+}
+
+func TestWithForwardingSidecar(t *testing.T) {
+	config := ForwardingConfig{
+		Image:          "operator:latest",
+		Command:        []string{"audit-forwarder"},
+		AuditLogPath:   "/var/log/kube-apiserver/audit.log",
+		Target:         SyslogForwardingTarget,
+		SyslogEndpoint: "syslog.example.com:514",
+	}
+	spec := &corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "kube-apiserver"}},
+		Volumes:    []corev1.Volume{{Name: "audit-dir"}},
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := WithForwardingSidecar(spec, config); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(spec.Containers) != 2 || spec.Containers[0].Name != "kube-apiserver" || spec.Containers[1].Name != forwardingContainerName {
+		t.Errorf("unexpected containers %v", spec.Containers)
+	}
+	if len(spec.Volumes) != 2 || spec.Volumes[0].Name != "audit-dir" || spec.Volumes[1].Name != auditLogVolumeName {
+		t.Errorf("unexpected volumes %v", spec.Volumes)
+	}
+}
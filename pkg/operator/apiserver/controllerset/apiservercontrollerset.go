@@ -381,6 +381,7 @@ func (cs *APIServerControllerSet) WithEncryptionControllers(
 		kubeInformersForNamespaces: kubeInformersForNamespaces,
 		secretsClient:              secretsClient,
 		resourceSyncer:             resourceSyncer,
+		keyRetention:               controllers.DefaultKeyRetention,
 	}
 
 	return cs
@@ -391,6 +392,12 @@ func (cs *APIServerControllerSet) WithUnsupportedConfigPrefixForEncryptionContro
 	return cs
 }
 
+// WithEncryptionKeyRetention configures which unused encryption keys are kept instead of being pruned.
+func (cs *APIServerControllerSet) WithEncryptionKeyRetention(retention controllers.KeyRetention) *APIServerControllerSet {
+	cs.encryptionControllers.keyRetention = retention
+	return cs
+}
+
 func (cs *APIServerControllerSet) WithoutEncryptionControllers() *APIServerControllerSet {
 	cs.encryptionControllers.controller = nil
 	cs.encryptionControllers.emptyAllowed = true
@@ -491,6 +498,7 @@ type encryptionControllerBuilder struct {
 	resourceSyncer             *resourcesynccontroller.ResourceSyncController
 
 	unsupportedConfigPrefix []string
+	keyRetention            controllers.KeyRetention
 }
 
 func (e *encryptionControllerBuilder) build() []controllerWrapper {
@@ -498,7 +506,7 @@ func (e *encryptionControllerBuilder) build() []controllerWrapper {
 		return []controllerWrapper{e.controllerWrapper}
 	}
 
-	controllers, err := encryption.NewControllers(
+	controllers, err := encryption.NewControllersWithKeyRetention(
 		e.component,
 		e.unsupportedConfigPrefix,
 		e.provider,
@@ -511,6 +519,7 @@ func (e *encryptionControllerBuilder) build() []controllerWrapper {
 		e.secretsClient,
 		e.eventRecorder,
 		e.resourceSyncer,
+		e.keyRetention,
 	)
 	if err != nil {
 		e.creationError = err
@@ -1,12 +1,19 @@
 package certrotation
 
 import (
+	"time"
+
 	"github.com/openshift/api/annotations"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
 	AutoRegenerateAfterOfflineExpiryAnnotation string = "certificates.openshift.io/auto-regenerate-after-offline-expiry"
+	// CertificateRefreshPolicyAnnotation records when the rotated certificate of a secret is refreshed: RefreshOnExpiry,
+	// or the refresh period, e.g. "720h0m0s".
+	CertificateRefreshPolicyAnnotation string = "certificates.openshift.io/refresh-policy"
+	// RefreshOnExpiry is the refresh policy of the certificates rotated only when they expire.
+	RefreshOnExpiry = "OnExpiry"
 )
 
 type AdditionalAnnotations struct {
@@ -39,6 +46,16 @@ func (a AdditionalAnnotations) EnsureTLSMetadataUpdate(meta *metav1.ObjectMeta)
 	return modified
 }
 
+// setRefreshPolicy sets the refresh policy annotation of a rotated certificate, unless it has no refresh period.
+func setRefreshPolicy(meta *metav1.ObjectMeta, refresh time.Duration, refreshOnlyWhenExpired bool) {
+	switch {
+	case refreshOnlyWhenExpired:
+		meta.Annotations[CertificateRefreshPolicyAnnotation] = RefreshOnExpiry
+	case refresh > 0:
+		meta.Annotations[CertificateRefreshPolicyAnnotation] = refresh.String()
+	}
+}
+
 func NewTLSArtifactObjectMeta(name, namespace string, annotations AdditionalAnnotations) metav1.ObjectMeta {
 	meta := metav1.ObjectMeta{
 		Namespace: namespace,
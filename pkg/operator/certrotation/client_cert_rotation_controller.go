@@ -2,13 +2,18 @@ package certrotation
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
+	"sync"
 	"time"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
 
 	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/crypto"
 	"github.com/openshift/library-go/pkg/operator/condition"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
@@ -25,6 +30,9 @@ const (
 	CertificateHostnames = "auth.openshift.io/certificate-hostnames"
 	// RunOnceContextKey is a context value key that can be used to call the controller Sync() and make it only run the syncWorker once and report error.
 	RunOnceContextKey = "cert-rotation-controller.openshift.io/run-once"
+
+	// servingEndpointsTimeout bounds the probe of all the serving endpoints.
+	servingEndpointsTimeout = 30 * time.Second
 )
 
 // StatusReporter knows how to report the status of cert rotation
@@ -32,7 +40,14 @@ type StatusReporter interface {
 	Report(ctx context.Context, controllerName string, syncErr error) (updated bool, updateErr error)
 }
 
+// ServingEndpointsStatusReporter knows how to report the certificates served by the serving endpoints. A StatusReporter
+// implementing it reports them, otherwise they are only logged.
+type ServingEndpointsStatusReporter interface {
+	ReportServingEndpoints(ctx context.Context, controllerName string, mismatchErr error) (updated bool, updateErr error)
+}
+
 var _ StatusReporter = (*StaticPodConditionStatusReporter)(nil)
+var _ ServingEndpointsStatusReporter = (*StaticPodConditionStatusReporter)(nil)
 
 type StaticPodConditionStatusReporter struct {
 	// Plumbing:
@@ -53,6 +68,20 @@ func (s *StaticPodConditionStatusReporter) Report(ctx context.Context, controlle
 	return updated, updateErr
 }
 
+func (s *StaticPodConditionStatusReporter) ReportServingEndpoints(ctx context.Context, controllerName string, mismatchErr error) (bool, error) {
+	newCondition := operatorv1.OperatorCondition{
+		Type:   fmt.Sprintf(condition.CertRotationServingEndpointsMismatchConditionTypeFmt, controllerName),
+		Status: operatorv1.ConditionFalse,
+	}
+	if mismatchErr != nil {
+		newCondition.Status = operatorv1.ConditionTrue
+		newCondition.Reason = "CertificateMismatch"
+		newCondition.Message = mismatchErr.Error()
+	}
+	_, updated, updateErr := v1helpers.UpdateStaticPodStatus(ctx, s.OperatorClient, v1helpers.UpdateStaticPodConditionFn(newCondition))
+	return updated, updateErr
+}
+
 // CertRotationController does:
 //
 // 1) continuously create a self-signed signing CA (via RotatedSigningCASecret) and store it in a secret.
@@ -67,9 +96,28 @@ type CertRotationController struct {
 	CABundleConfigMap CABundleConfigMap
 	// RotatedSelfSignedCertKeySecret rotates a key and cert signed by a signing CA and stores it in a secret.
 	RotatedSelfSignedCertKeySecret RotatedSelfSignedCertKeySecret
+	// ServingEndpoints are the host:port endpoints serving the target cert, whose certificates are checked against the
+	// CA bundle after every successful sync.
+	ServingEndpoints []string
 
 	// Plumbing:
 	StatusReporter StatusReporter
+
+	// probingEndpoints is held while the serving endpoints are probed, so that a slow endpoint does not pile probes up.
+	probingEndpoints *sync.Mutex
+}
+
+// Option configures the CertRotationController.
+type Option func(*CertRotationController)
+
+// WithServingEndpoints checks the certificates served by the host:port endpoints against the CA bundle after every
+// successful sync, in the background. The endpoints serving an expired certificate, a certificate for another hostname
+// or a certificate the bundle does not trust are reported in the ServingEndpointsMismatch condition, with the reason,
+// when the StatusReporter is a ServingEndpointsStatusReporter.
+func WithServingEndpoints(endpoints ...string) Option {
+	return func(c *CertRotationController) {
+		c.ServingEndpoints = append(c.ServingEndpoints, endpoints...)
+	}
 }
 
 func NewCertRotationController(
@@ -79,6 +127,7 @@ func NewCertRotationController(
 	rotatedSelfSignedCertKeySecret RotatedSelfSignedCertKeySecret,
 	recorder events.Recorder,
 	reporter StatusReporter,
+	options ...Option,
 ) factory.Controller {
 	c := &CertRotationController{
 		Name:                           name,
@@ -86,6 +135,10 @@ func NewCertRotationController(
 		CABundleConfigMap:              caBundleConfigMap,
 		RotatedSelfSignedCertKeySecret: rotatedSelfSignedCertKeySecret,
 		StatusReporter:                 reporter,
+		probingEndpoints:               &sync.Mutex{},
+	}
+	for _, option := range options {
+		option(c)
 	}
 	return factory.New().
 		ResyncEvery(time.Minute).
@@ -105,7 +158,7 @@ func NewCertRotationController(
 }
 
 func (c CertRotationController) Sync(ctx context.Context, syncCtx factory.SyncContext) error {
-	syncErr := c.SyncWorker(ctx)
+	caBundleCerts, syncErr := c.syncWorker(ctx)
 
 	// running this function with RunOnceContextKey value context will make this "run-once" without updating status.
 	isRunOnce, ok := ctx.Value(RunOnceContextKey).(bool)
@@ -120,6 +173,9 @@ func (c CertRotationController) Sync(ctx context.Context, syncCtx factory.SyncCo
 	if updated && syncErr != nil {
 		syncCtx.Recorder().Warningf("RotationError", syncErr.Error())
 	}
+	if syncErr == nil && len(c.ServingEndpoints) > 0 {
+		go c.probeServingEndpoints(ctx, syncCtx, caBundleCerts)
+	}
 
 	return syncErr
 }
@@ -129,21 +185,71 @@ func (c CertRotationController) getSigningCertKeyPairLocation() string {
 }
 
 func (c CertRotationController) SyncWorker(ctx context.Context) error {
+	_, err := c.syncWorker(ctx)
+	return err
+}
+
+// syncWorker rotates the certificates and returns the CA bundle.
+func (c CertRotationController) syncWorker(ctx context.Context) ([]*x509.Certificate, error) {
 	signingCertKeyPair, _, err := c.RotatedSigningCASecret.EnsureSigningCertKeyPair(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	cabundleCerts, err := c.CABundleConfigMap.EnsureConfigMapCABundle(ctx, signingCertKeyPair, c.getSigningCertKeyPairLocation())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if _, err := c.RotatedSelfSignedCertKeySecret.EnsureTargetCertKeyPair(ctx, signingCertKeyPair, cabundleCerts); err != nil {
-		return err
+		return nil, err
 	}
+	return cabundleCerts, nil
+}
 
-	return nil
+// probeServingEndpoints checks the certificates served by the serving endpoints and reports the mismatches. It is
+// skipped while a previous probe still runs.
+func (c CertRotationController) probeServingEndpoints(ctx context.Context, syncCtx factory.SyncContext, caBundleCerts []*x509.Certificate) {
+	if c.probingEndpoints != nil {
+		if !c.probingEndpoints.TryLock() {
+			return
+		}
+		defer c.probingEndpoints.Unlock()
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, servingEndpointsTimeout)
+	defer cancel()
+	mismatchErr := c.diagnoseServingEndpoints(probeCtx, caBundleCerts)
+	if ctx.Err() != nil {
+		return
+	}
+
+	reporter, ok := c.StatusReporter.(ServingEndpointsStatusReporter)
+	if !ok {
+		if mismatchErr != nil {
+			klog.Warningf("%s: %v", c.Name, mismatchErr)
+		}
+		return
+	}
+	updated, err := reporter.ReportServingEndpoints(ctx, c.Name, mismatchErr)
+	if err != nil {
+		klog.Warningf("Unable to report the serving endpoints of %s: %v", c.Name, err)
+		return
+	}
+	if updated && mismatchErr != nil {
+		syncCtx.Recorder().Warningf("ServingCertificateMismatch", mismatchErr.Error())
+	}
+}
+
+// diagnoseServingEndpoints returns the problems of the certificates served by the serving endpoints.
+func (c CertRotationController) diagnoseServingEndpoints(ctx context.Context, caBundleCerts []*x509.Certificate) error {
+	var errs []error
+	for _, endpoint := range c.ServingEndpoints {
+		if err := crypto.DiagnoseEndpoint(ctx, endpoint, caBundleCerts, crypto.ChainValidationOptions{}); err != nil {
+			errs = append(errs, fmt.Errorf("endpoint %s serves an invalid certificate: %w", endpoint, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
 }
 
 func (c CertRotationController) targetCertRecheckerPostRunHook(ctx context.Context, syncCtx factory.SyncContext) error {
@@ -0,0 +1,65 @@
+package certrotation
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+type fakeServingEndpointsReporter struct {
+	syncErrs     []error
+	mismatchErrs []error
+}
+
+func (r *fakeServingEndpointsReporter) Report(_ context.Context, _ string, syncErr error) (bool, error) {
+	r.syncErrs = append(r.syncErrs, syncErr)
+	return true, nil
+}
+
+func (r *fakeServingEndpointsReporter) ReportServingEndpoints(_ context.Context, _ string, mismatchErr error) (bool, error) {
+	r.mismatchErrs = append(r.mismatchErrs, mismatchErr)
+	return true, nil
+}
+
+func TestProbeServingEndpoints(t *testing.T) {
+	// nothing listens on the port of a closed listener
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+
+	reporter := &fakeServingEndpointsReporter{}
+	c := CertRotationController{
+		Name:             "test",
+		ServingEndpoints: []string{closed.Addr().String()},
+		StatusReporter:   reporter,
+		probingEndpoints: &sync.Mutex{},
+	}
+	recorder := events.NewInMemoryRecorder("test")
+	syncCtx := factory.NewSyncContext("test", recorder)
+
+	c.probeServingEndpoints(context.TODO(), syncCtx, nil)
+	if len(reporter.mismatchErrs) != 1 || reporter.mismatchErrs[0] == nil || !strings.Contains(reporter.mismatchErrs[0].Error(), closed.Addr().String()) {
+		t.Fatalf("expected the endpoint to be reported in the serving endpoints condition, got %v", reporter.mismatchErrs)
+	}
+	if len(reporter.syncErrs) != 0 {
+		t.Errorf("expected the endpoint not to be reported in the degraded condition, got %v", reporter.syncErrs)
+	}
+	if events := recorder.Events(); len(events) != 1 || events[0].Reason != "ServingCertificateMismatch" {
+		t.Errorf("expected a ServingCertificateMismatch event, got %v", events)
+	}
+
+	// a probe still running skips the next one
+	c.probingEndpoints.Lock()
+	c.probeServingEndpoints(context.TODO(), syncCtx, nil)
+	c.probingEndpoints.Unlock()
+	if len(reporter.mismatchErrs) != 1 {
+		t.Errorf("expected the probe to be skipped while another one runs, got %d reports", len(reporter.mismatchErrs))
+	}
+}
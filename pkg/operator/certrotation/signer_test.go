@@ -14,6 +14,7 @@ import (
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	clienttesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
 
 	"github.com/openshift/api/annotations"
 	"github.com/openshift/library-go/pkg/operator/events"
@@ -181,3 +182,47 @@ func TestEnsureSigningCertKeyPair(t *testing.T) {
 		})
 	}
 }
+
+func TestSigningCertKeyPairRotationWithClock(t *testing.T) {
+	fakeClock := clocktesting.NewFakePassiveClock(time.Now())
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	client := kubefake.NewSimpleClientset()
+	c := &RotatedSigningCASecret{
+		Namespace:     "ns",
+		Name:          "signer",
+		Validity:      24 * time.Hour,
+		Refresh:       12 * time.Hour,
+		Client:        client.CoreV1(),
+		Lister:        corev1listers.NewSecretLister(indexer),
+		EventRecorder: events.NewInMemoryRecorder("test"),
+		Clock:         fakeClock,
+	}
+
+	ensure := func() bool {
+		t.Helper()
+		_, updated, err := c.EnsureSigningCertKeyPair(context.TODO())
+		if err != nil {
+			t.Fatal(err)
+		}
+		secret, err := client.CoreV1().Secrets("ns").Get(context.TODO(), "signer", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := indexer.Update(secret); err != nil {
+			t.Fatal(err)
+		}
+		return updated
+	}
+
+	if !ensure() {
+		t.Fatal("expected the signer to be created")
+	}
+	fakeClock.SetTime(fakeClock.Now().Add(time.Hour))
+	if ensure() {
+		t.Error("expected the signer not to be rotated before the refresh")
+	}
+	fakeClock.SetTime(fakeClock.Now().Add(12 * time.Hour))
+	if !ensure() {
+		t.Error("expected the signer to be rotated after the refresh")
+	}
+}
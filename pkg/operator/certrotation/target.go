@@ -13,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	"github.com/openshift/library-go/pkg/certs"
 	"github.com/openshift/library-go/pkg/crypto"
@@ -69,6 +70,9 @@ type RotatedSelfSignedCertKeySecret struct {
 	Lister        corev1listers.SecretLister
 	Client        corev1client.SecretsGetter
 	EventRecorder events.Recorder
+	// Clock decides whether the certificate is due for rotation. It defaults to the real clock, tests can use a fake
+	// clock to simulate the expiry of the certificate.
+	Clock clock.PassiveClock
 }
 
 type TargetCertCreator interface {
@@ -80,6 +84,12 @@ type TargetCertCreator interface {
 	SetAnnotations(cert *crypto.TLSCertificateConfig, annotations map[string]string) map[string]string
 }
 
+// targetCertCreatorAt is implemented by the TargetCertCreators of this package, to decide whether a new cert-key pair is
+// needed at the time of the Clock of RotatedSelfSignedCertKeySecret.
+type targetCertCreatorAt interface {
+	needNewTargetCertKeyPairAt(now time.Time, currentCertSecret *corev1.Secret, signer *crypto.CA, caBundleCerts []*x509.Certificate, refresh time.Duration, refreshOnlyWhenExpired, creationRequired bool) string
+}
+
 // TargetCertRechecker is an optional interface to be implemented by the TargetCertCreator to enforce
 // a controller run.
 type TargetCertRechecker interface {
@@ -116,11 +126,19 @@ func (c RotatedSelfSignedCertKeySecret) EnsureTargetCertKeyPair(ctx context.Cont
 	needsTypeChange := ensureSecretTLSTypeSet(targetCertKeyPairSecret)
 	updateRequired = needsMetadataUpdate || needsTypeChange
 
-	if reason := c.CertCreator.NeedNewTargetCertKeyPair(targetCertKeyPairSecret, signingCertKeyPair, caBundleCerts, c.Refresh, c.RefreshOnlyWhenExpired, creationRequired); len(reason) > 0 {
+	now := nowFrom(c.Clock)
+	var reason string
+	if certCreator, ok := c.CertCreator.(targetCertCreatorAt); ok {
+		reason = certCreator.needNewTargetCertKeyPairAt(now, targetCertKeyPairSecret, signingCertKeyPair, caBundleCerts, c.Refresh, c.RefreshOnlyWhenExpired, creationRequired)
+	} else {
+		reason = c.CertCreator.NeedNewTargetCertKeyPair(targetCertKeyPairSecret, signingCertKeyPair, caBundleCerts, c.Refresh, c.RefreshOnlyWhenExpired, creationRequired)
+	}
+	if len(reason) > 0 {
 		c.EventRecorder.Eventf("TargetUpdateRequired", "%q in %q requires a new target cert/key pair: %v", c.Name, c.Namespace, reason)
-		if err := setTargetCertKeyPairSecret(targetCertKeyPairSecret, c.Validity, signingCertKeyPair, c.CertCreator, c.AdditionalAnnotations); err != nil {
+		if err := setTargetCertKeyPairSecret(now, targetCertKeyPairSecret, c.Validity, signingCertKeyPair, c.CertCreator, c.AdditionalAnnotations); err != nil {
 			return nil, err
 		}
+		setRefreshPolicy(&targetCertKeyPairSecret.ObjectMeta, c.Refresh, c.RefreshOnlyWhenExpired)
 
 		LabelAsManagedSecret(targetCertKeyPairSecret, CertificateTypeTarget)
 
@@ -147,13 +165,13 @@ func (c RotatedSelfSignedCertKeySecret) EnsureTargetCertKeyPair(ctx context.Cont
 	return targetCertKeyPairSecret, nil
 }
 
-func needNewTargetCertKeyPair(secret *corev1.Secret, signer *crypto.CA, caBundleCerts []*x509.Certificate, refresh time.Duration, refreshOnlyWhenExpired, creationRequired bool) string {
+func needNewTargetCertKeyPair(now time.Time, secret *corev1.Secret, signer *crypto.CA, caBundleCerts []*x509.Certificate, refresh time.Duration, refreshOnlyWhenExpired, creationRequired bool) string {
 	if creationRequired {
 		return "secret doesn't exist"
 	}
 
 	annotations := secret.Annotations
-	if reason := needNewTargetCertKeyPairForTime(annotations, signer, refresh, refreshOnlyWhenExpired); len(reason) > 0 {
+	if reason := needNewTargetCertKeyPairForTime(now, annotations, signer, refresh, refreshOnlyWhenExpired); len(reason) > 0 {
 		return reason
 	}
 
@@ -190,14 +208,14 @@ func needNewTargetCertKeyPair(secret *corev1.Secret, signer *crypto.CA, caBundle
 // Hence, if the CAs are rotated too fast (like CA percentage around 10% or smaller), we will not hit the time to make use of the CA. Or if the cert renewal percentage is at 90%, there is not much time either.
 //
 // So with a cert percentage of 75% and equally long CA and cert validities at the worst case we start at 85% of the cert to renew, trying again every minute.
-func needNewTargetCertKeyPairForTime(annotations map[string]string, signer *crypto.CA, refresh time.Duration, refreshOnlyWhenExpired bool) string {
+func needNewTargetCertKeyPairForTime(now time.Time, annotations map[string]string, signer *crypto.CA, refresh time.Duration, refreshOnlyWhenExpired bool) string {
 	notBefore, notAfter, reason := getValidityFromAnnotations(annotations)
 	if len(reason) > 0 {
 		return reason
 	}
 
 	// Is cert expired?
-	if time.Now().After(notAfter) {
+	if now.After(notAfter) {
 		return "already expired"
 	}
 
@@ -208,16 +226,16 @@ func needNewTargetCertKeyPairForTime(annotations map[string]string, signer *cryp
 	// Are we at 80% of validity?
 	validity := notAfter.Sub(notBefore)
 	at80Percent := notAfter.Add(-validity / 5)
-	if time.Now().After(at80Percent) {
+	if now.After(at80Percent) {
 		return fmt.Sprintf("past refresh time (80%% of validity): %v", at80Percent)
 	}
 
 	// If Certificate is past its refresh time, we may have action to take. We only do this if the signer is old enough.
 	refreshTime := notBefore.Add(refresh)
-	if time.Now().After(refreshTime) {
+	if now.After(refreshTime) {
 		// make sure the signer has been valid for more than 10% of the target's refresh time.
 		timeToWaitForTrustRotation := refresh / 10
-		if time.Now().After(signer.Config.Certs[0].NotBefore.Add(time.Duration(timeToWaitForTrustRotation))) {
+		if now.After(signer.Config.Certs[0].NotBefore.Add(time.Duration(timeToWaitForTrustRotation))) {
 			return fmt.Sprintf("past its refresh time %v", refreshTime)
 		}
 	}
@@ -227,7 +245,7 @@ func needNewTargetCertKeyPairForTime(annotations map[string]string, signer *cryp
 
 // setTargetCertKeyPairSecret creates a new cert/key pair and sets them in the secret.  Only one of client, serving, or signer rotation may be specified.
 // TODO refactor with an interface for actually signing and move the one-of check higher in the stack.
-func setTargetCertKeyPairSecret(targetCertKeyPairSecret *corev1.Secret, validity time.Duration, signer *crypto.CA, certCreator TargetCertCreator, annotations AdditionalAnnotations) error {
+func setTargetCertKeyPairSecret(now time.Time, targetCertKeyPairSecret *corev1.Secret, validity time.Duration, signer *crypto.CA, certCreator TargetCertCreator, annotations AdditionalAnnotations) error {
 	if targetCertKeyPairSecret.Annotations == nil {
 		targetCertKeyPairSecret.Annotations = map[string]string{}
 	}
@@ -237,7 +255,7 @@ func setTargetCertKeyPairSecret(targetCertKeyPairSecret *corev1.Secret, validity
 
 	// our annotation is based on our cert validity, so we want to make sure that we don't specify something past our signer
 	targetValidity := validity
-	remainingSignerValidity := signer.Config.Certs[0].NotAfter.Sub(time.Now())
+	remainingSignerValidity := signer.Config.Certs[0].NotAfter.Sub(now)
 	if remainingSignerValidity < validity {
 		targetValidity = remainingSignerValidity
 	}
@@ -270,7 +288,11 @@ func (r *ClientRotation) NewCertificate(signer *crypto.CA, validity time.Duratio
 }
 
 func (r *ClientRotation) NeedNewTargetCertKeyPair(currentCertSecret *corev1.Secret, signer *crypto.CA, caBundleCerts []*x509.Certificate, refresh time.Duration, refreshOnlyWhenExpired, exists bool) string {
-	return needNewTargetCertKeyPair(currentCertSecret, signer, caBundleCerts, refresh, refreshOnlyWhenExpired, exists)
+	return r.needNewTargetCertKeyPairAt(time.Now(), currentCertSecret, signer, caBundleCerts, refresh, refreshOnlyWhenExpired, exists)
+}
+
+func (r *ClientRotation) needNewTargetCertKeyPairAt(now time.Time, currentCertSecret *corev1.Secret, signer *crypto.CA, caBundleCerts []*x509.Certificate, refresh time.Duration, refreshOnlyWhenExpired, exists bool) string {
+	return needNewTargetCertKeyPair(now, currentCertSecret, signer, caBundleCerts, refresh, refreshOnlyWhenExpired, exists)
 }
 
 func (r *ClientRotation) SetAnnotations(cert *crypto.TLSCertificateConfig, annotations map[string]string) map[string]string {
@@ -295,7 +317,11 @@ func (r *ServingRotation) RecheckChannel() <-chan struct{} {
 }
 
 func (r *ServingRotation) NeedNewTargetCertKeyPair(currentCertSecret *corev1.Secret, signer *crypto.CA, caBundleCerts []*x509.Certificate, refresh time.Duration, refreshOnlyWhenExpired, creationRequired bool) string {
-	reason := needNewTargetCertKeyPair(currentCertSecret, signer, caBundleCerts, refresh, refreshOnlyWhenExpired, creationRequired)
+	return r.needNewTargetCertKeyPairAt(time.Now(), currentCertSecret, signer, caBundleCerts, refresh, refreshOnlyWhenExpired, creationRequired)
+}
+
+func (r *ServingRotation) needNewTargetCertKeyPairAt(now time.Time, currentCertSecret *corev1.Secret, signer *crypto.CA, caBundleCerts []*x509.Certificate, refresh time.Duration, refreshOnlyWhenExpired, creationRequired bool) string {
+	reason := needNewTargetCertKeyPair(now, currentCertSecret, signer, caBundleCerts, refresh, refreshOnlyWhenExpired, creationRequired)
 	if len(reason) > 0 {
 		return reason
 	}
@@ -341,7 +367,11 @@ func (r *SignerRotation) NewCertificate(signer *crypto.CA, validity time.Duratio
 }
 
 func (r *SignerRotation) NeedNewTargetCertKeyPair(currentCertSecret *corev1.Secret, signer *crypto.CA, caBundleCerts []*x509.Certificate, refresh time.Duration, refreshOnlyWhenExpired, exists bool) string {
-	return needNewTargetCertKeyPair(currentCertSecret, signer, caBundleCerts, refresh, refreshOnlyWhenExpired, exists)
+	return r.needNewTargetCertKeyPairAt(time.Now(), currentCertSecret, signer, caBundleCerts, refresh, refreshOnlyWhenExpired, exists)
+}
+
+func (r *SignerRotation) needNewTargetCertKeyPairAt(now time.Time, currentCertSecret *corev1.Secret, signer *crypto.CA, caBundleCerts []*x509.Certificate, refresh time.Duration, refreshOnlyWhenExpired, exists bool) string {
+	return needNewTargetCertKeyPair(now, currentCertSecret, signer, caBundleCerts, refresh, refreshOnlyWhenExpired, exists)
 }
 
 func (r *SignerRotation) SetAnnotations(cert *crypto.TLSCertificateConfig, annotations map[string]string) map[string]string {
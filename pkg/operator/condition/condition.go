@@ -47,6 +47,11 @@ const (
 	// validity can expire and without rotating/renewing them manual recovery might be required to fix the cluster.
 	CertRotationDegradedConditionTypeFmt = "CertRotation_%s_Degraded"
 
+	// CertRotationServingEndpointsMismatchConditionTypeFmt is true when one or more serving endpoints of a certificate rotation serve a certificate
+	// the CA bundle does not trust, an expired one or one for another hostname. The CertificateMismatch reason is given with the endpoints and
+	// the reasons. It does not degrade the operator: the endpoints usually pick the rotated certificate up with a delay.
+	CertRotationServingEndpointsMismatchConditionTypeFmt = "CertRotation_%s_ServingEndpointsMismatch"
+
 	// InstallerControllerDegradedConditionType is true when the operator is not able to create new installer pods so the new revisions
 	// cannot be rolled out. This might happen when one or more required secrets or config maps does not exists.
 	// In case the missing secret or config map is available, this condition is automatically set to false.
@@ -66,6 +71,19 @@ const (
 	// This condition mean no new revision will be created.
 	RevisionControllerDegradedConditionType = "RevisionControllerDegraded"
 
+	// RevisionRollbackProgressingConditionType is true when the operator is rolling the nodes back to the content of a
+	// previous revision requested by the rollback annotation.
+	RevisionRollbackProgressingConditionType = "RevisionRollbackProgressing"
+
+	// RevisionRollbackDegradedConditionType is true when the requested rollback failed its safety checks, e.g. the
+	// revision content no longer exists or the revision failed to install before.
+	RevisionRollbackDegradedConditionType = "RevisionRollbackDegraded"
+
+	// OperatorPausedConditionType is true when the reconciliation of the operator is paused by the
+	// operator.openshift.io/paused annotation on the operator resource. The message tells who paused the operator,
+	// when and why. It is set to false when the annotation is removed.
+	OperatorPausedConditionType = "Paused"
+
 	// NodeControllerDegradedConditionType is true when the operator observed a master node that is not ready.
 	// Note that a node is not ready when its Condition.NodeReady wasn't set to true
 	NodeControllerDegradedConditionType = "NodeControllerDegraded"
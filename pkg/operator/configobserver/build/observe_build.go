@@ -0,0 +1,79 @@
+package build
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+type BuildLister interface {
+	BuildLister() configlistersv1.BuildLister
+}
+
+// NewBuildObserveFunc returns an observer of the build.config.openshift.io/cluster object. It writes the build
+// defaults (env, proxies, image labels, resources) and overrides (image labels, node selector, tolerations, force
+// pull) in their API serialization to buildDefaults and buildOverrides under the config path.
+func NewBuildObserveFunc(configPath []string) configobserver.ObserveConfigFunc {
+	return (&observeBuild{
+		configPath: configPath,
+	}).ObserveBuildConfig
+}
+
+type observeBuild struct {
+	configPath []string
+}
+
+// ObserveBuildConfig observes the build.config.openshift.io/cluster object and writes its defaults and overrides to
+// the path from the constructor
+func (f *observeBuild) ObserveBuildConfig(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, _ []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, f.configPath)
+	}()
+
+	buildLister := genericListers.(BuildLister)
+
+	errs := []error{}
+	observedConfig := map[string]interface{}{}
+	build, err := buildLister.BuildLister().Get("cluster")
+	if errors.IsNotFound(err) {
+		return observedConfig, errs
+	}
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+
+	observed := map[string]interface{}{}
+	if !equality.Semantic.DeepEqual(build.Spec.BuildDefaults, configv1.BuildDefaults{}) {
+		observed["buildDefaults"] = &build.Spec.BuildDefaults
+	}
+	if !equality.Semantic.DeepEqual(build.Spec.BuildOverrides, configv1.BuildOverrides{}) {
+		observed["buildOverrides"] = &build.Spec.BuildOverrides
+	}
+	for field, value := range observed {
+		unstructuredValue, err := runtime.DefaultUnstructuredConverter.ToUnstructured(value)
+		if err != nil {
+			return existingConfig, append(errs, err)
+		}
+		if err := unstructured.SetNestedField(observedConfig, unstructuredValue, append(append([]string{}, f.configPath...), field)...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	currentConfig, _, err := unstructured.NestedMap(existingConfig, f.configPath...)
+	if err != nil {
+		errs = append(errs, err)
+		// keep going on read error from existing config
+	}
+	newConfig, _, _ := unstructured.NestedMap(observedConfig, f.configPath...)
+	if !equality.Semantic.DeepEqual(currentConfig, newConfig) {
+		recorder.Eventf("ObserveBuildConfig", "build defaults and overrides changed to %v", newConfig)
+	}
+
+	return observedConfig, errs
+}
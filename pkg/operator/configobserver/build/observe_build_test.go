@@ -0,0 +1,138 @@
+package build
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
+)
+
+type testLister struct {
+	lister configlistersv1.BuildLister
+}
+
+func (l testLister) BuildLister() configlistersv1.BuildLister {
+	return l.lister
+}
+
+func (l testLister) ResourceSyncer() resourcesynccontroller.ResourceSyncer {
+	return nil
+}
+
+func (l testLister) PreRunHasSynced() []cache.InformerSynced {
+	return nil
+}
+
+func TestObserveBuildConfig(t *testing.T) {
+	configPath := []string{"build"}
+
+	tests := []struct {
+		name           string
+		build          *configv1.Build
+		existing       map[string]interface{}
+		expected       map[string]interface{}
+		eventsExpected int
+	}{
+		{
+			name:     "no build config",
+			expected: map[string]interface{}{},
+		},
+		{
+			name:     "all unset",
+			build:    &configv1.Build{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
+			expected: map[string]interface{}{},
+		},
+		{
+			name: "defaults and overrides",
+			build: &configv1.Build{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec: configv1.BuildSpec{
+					BuildDefaults: configv1.BuildDefaults{
+						DefaultProxy: &configv1.ProxySpec{HTTPProxy: "http://proxy.example.com"},
+						Env:          []corev1.EnvVar{{Name: "FOO", Value: "bar"}},
+						Resources: corev1.ResourceRequirements{
+							Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+						},
+					},
+					BuildOverrides: configv1.BuildOverrides{
+						NodeSelector: map[string]string{"node-role.kubernetes.io/builder": ""},
+					},
+				},
+			},
+			expected: map[string]interface{}{
+				"build": map[string]interface{}{
+					"buildDefaults": map[string]interface{}{
+						"defaultProxy": map[string]interface{}{
+							"httpProxy": "http://proxy.example.com",
+							"trustedCA": map[string]interface{}{"name": ""},
+						},
+						"env": []interface{}{map[string]interface{}{"name": "FOO", "value": "bar"}},
+						"resources": map[string]interface{}{
+							"limits": map[string]interface{}{"memory": "1Gi"},
+						},
+					},
+					"buildOverrides": map[string]interface{}{
+						"nodeSelector": map[string]interface{}{"node-role.kubernetes.io/builder": ""},
+					},
+				},
+			},
+			eventsExpected: 1,
+		},
+		{
+			name: "unchanged",
+			build: &configv1.Build{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec: configv1.BuildSpec{
+					BuildOverrides: configv1.BuildOverrides{ForcePull: ptr.To(true)},
+				},
+			},
+			existing: map[string]interface{}{
+				"build": map[string]interface{}{
+					"buildOverrides": map[string]interface{}{"forcePull": true},
+				},
+			},
+			expected: map[string]interface{}{
+				"build": map[string]interface{}{
+					"buildOverrides": map[string]interface{}{"forcePull": true},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if tt.build != nil {
+				indexer.Add(tt.build)
+			}
+			listers := testLister{
+				lister: configlistersv1.NewBuildLister(indexer),
+			}
+			eventRecorder := events.NewInMemoryRecorder("")
+
+			existing := tt.existing
+			if existing == nil {
+				existing = map[string]interface{}{}
+			}
+
+			got, errs := NewBuildObserveFunc(configPath)(listers, eventRecorder, existing)
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ObserveBuildConfig() got = %v, want %v", got, tt.expected)
+			}
+			if events := eventRecorder.Events(); len(events) != tt.eventsExpected {
+				t.Errorf("expected %d events, but got %d: %v", tt.eventsExpected, len(events), events)
+			}
+		})
+	}
+}
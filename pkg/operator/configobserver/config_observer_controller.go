@@ -33,6 +33,7 @@ import (
 )
 
 // Listers is an interface which will be passed to the config observer funcs.  It is expected to be hard-cast to the "correct" type
+// Listers of arbitrary resources, e.g. third-party CRs, are provided by embedding *DynamicListers, see GetDynamicLister.
 type Listers interface {
 	// ResourceSyncer can be used to copy content from one namespace to another
 	ResourceSyncer() resourcesynccontroller.ResourceSyncer
@@ -59,6 +60,31 @@ type ConfigObserver struct {
 
 	nestedConfigPath      []string
 	degradedConditionType string
+	pinnedConditionType   string
+
+	// schema, if set, prunes the fields of the observed config not owned by any observer
+	schema *ObservedConfigSchema
+	// history, if set, records the written observed configs and allows to pin a previous one
+	history *ObservedConfigHistory
+}
+
+// ConfigObserverOption configures optional behaviour of the config observer.
+type ConfigObserverOption func(*ConfigObserver)
+
+// WithObservedConfigSchema prunes the fields of the observed config not owned by any observer registered in the schema.
+func WithObservedConfigSchema(schema *ObservedConfigSchema) ConfigObserverOption {
+	return func(c *ConfigObserver) {
+		c.schema = schema
+	}
+}
+
+// WithObservedConfigHistory records the written observed configs in the history. While the observed config is pinned
+// to a history entry, the entry is written instead of the observed config and <prefix>ObservedConfigPinnedDegraded
+// is set.
+func WithObservedConfigHistory(history *ObservedConfigHistory) ConfigObserverOption {
+	return func(c *ConfigObserver) {
+		c.history = history
+	}
 }
 
 func NewConfigObserver(
@@ -81,6 +107,41 @@ func NewConfigObserver(
 	)
 }
 
+// NewConfigObserverWithSchema creates a config observer that prunes the fields of the observed config that are not
+// owned by any observer registered in the schema, after the grace period of the schema.
+//
+// Example:
+//
+//	schema := NewObservedConfigSchema(10 * time.Minute)
+//	NewConfigObserverWithSchema(..., schema,
+//	  schema.Owns(proxy.NewProxyObserveFunc([]string{"proxy"}), []string{"proxy"}),
+//	)
+func NewConfigObserverWithSchema(
+	name string,
+	operatorClient v1helpers.OperatorClient,
+	eventRecorder events.Recorder,
+	listers Listers,
+	informers []factory.Informer,
+	schema *ObservedConfigSchema,
+	observers ...ObserveConfigFunc,
+) factory.Controller {
+	return newConfigObserver(name, operatorClient, eventRecorder, listers, informers, nil, "", []ConfigObserverOption{WithObservedConfigSchema(schema)}, observers...)
+}
+
+// NewConfigObserverWithOptions creates a config observer with the optional behaviour of the options, e.g.
+// WithObservedConfigSchema or WithObservedConfigHistory.
+func NewConfigObserverWithOptions(
+	name string,
+	operatorClient v1helpers.OperatorClient,
+	eventRecorder events.Recorder,
+	listers Listers,
+	informers []factory.Informer,
+	options []ConfigObserverOption,
+	observers ...ObserveConfigFunc,
+) factory.Controller {
+	return newConfigObserver(name, operatorClient, eventRecorder, listers, informers, nil, "", options, observers...)
+}
+
 // NewNestedConfigObserver creates a config observer that watches changes to a nested field (nestedConfigPath) in the config.
 // Useful when the config is shared across multiple controllers in the same process.
 //
@@ -109,6 +170,20 @@ func NewNestedConfigObserver(
 	nestedConfigPath []string,
 	degradedConditionPrefix string,
 	observers ...ObserveConfigFunc,
+) factory.Controller {
+	return newConfigObserver(name, operatorClient, eventRecorder, listers, informers, nestedConfigPath, degradedConditionPrefix, nil, observers...)
+}
+
+func newConfigObserver(
+	name string,
+	operatorClient v1helpers.OperatorClient,
+	eventRecorder events.Recorder,
+	listers Listers,
+	informers []factory.Informer,
+	nestedConfigPath []string,
+	degradedConditionPrefix string,
+	options []ConfigObserverOption,
+	observers ...ObserveConfigFunc,
 ) factory.Controller {
 	c := &ConfigObserver{
 		controllerInstanceName: factory.ControllerInstanceName(name, "ConfigObserver"),
@@ -117,6 +192,16 @@ func NewNestedConfigObserver(
 		listers:                listers,
 		nestedConfigPath:       nestedConfigPath,
 		degradedConditionType:  degradedConditionPrefix + condition.ConfigObservationDegradedConditionType,
+		pinnedConditionType:    degradedConditionPrefix + "ObservedConfigPinned" + operatorv1.OperatorStatusTypeDegraded,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	if c.history != nil {
+		informers = append(informers, c.history.informer)
+	}
+	if dynamicListers, ok := listers.(dynamicInformerSource); ok {
+		informers = append(informers, dynamicListers.dynamicInformers()...)
 	}
 
 	return factory.New().
@@ -140,11 +225,12 @@ func (c ConfigObserver) sync(ctx context.Context, syncCtx factory.SyncContext) e
 		return err
 	}
 	spec := originalSpec.DeepCopy()
+	logger := klog.FromContext(ctx)
 
 	// don't worry about errors.  If we can't decode, we'll simply stomp over the field.
 	existingConfig := map[string]interface{}{}
 	if err := json.NewDecoder(bytes.NewBuffer(spec.ObservedConfig.Raw)).Decode(&existingConfig); err != nil {
-		klog.V(4).Infof("decode of existing config failed with error: %v", err)
+		logger.V(4).Info("Decode of existing config failed", "err", err)
 	}
 
 	var errs []error
@@ -159,14 +245,14 @@ func (c ConfigObserver) sync(ctx context.Context, syncCtx factory.SyncContext) e
 	mergedObservedConfig := map[string]interface{}{}
 	for _, observedConfig := range observedConfigs {
 		if err := mergo.Merge(&mergedObservedConfig, observedConfig); err != nil {
-			klog.Warningf("merging observed config failed: %v", err)
+			logger.Error(err, "Merging observed config failed")
 		}
 	}
 
 	reverseMergedObservedConfig := map[string]interface{}{}
 	for i := len(observedConfigs) - 1; i >= 0; i-- {
 		if err := mergo.Merge(&reverseMergedObservedConfig, observedConfigs[i]); err != nil {
-			klog.Warningf("merging observed config failed: %v", err)
+			logger.Error(err, "Merging observed config failed")
 		}
 	}
 
@@ -174,8 +260,42 @@ func (c ConfigObserver) sync(ctx context.Context, syncCtx factory.SyncContext) e
 		errs = append(errs, errors.New("non-deterministic config observation detected"))
 	}
 
+	if c.schema != nil {
+		mergedObservedConfig = c.schema.prune(existingConfig, mergedObservedConfig, syncCtx.Recorder())
+	}
+
+	var conditions []*applyoperatorv1.OperatorConditionApplyConfiguration
+	pinned := false
+	if c.history != nil {
+		pinnedCondition := applyoperatorv1.OperatorCondition().
+			WithType(c.pinnedConditionType).
+			WithStatus(operatorv1.ConditionFalse)
+		switch entry, err := c.history.Pinned(); {
+		case err != nil:
+			// keep the existing config until the pin is fixed or removed
+			pinned = true
+			mergedObservedConfig = existingConfig
+			pinnedCondition = pinnedCondition.
+				WithStatus(operatorv1.ConditionTrue).
+				WithReason("PinInvalid").
+				WithMessage(err.Error())
+		case entry != nil:
+			pinned = true
+			mergedObservedConfig = entry.ObservedConfig
+			pinnedCondition = pinnedCondition.
+				WithStatus(operatorv1.ConditionTrue).
+				WithReason("Pinned").
+				WithMessage(fmt.Sprintf("The observed config is pinned to %s recorded at %s. Remove annotation %s from configmap %s/%s to resume config observation.", entry.Hash, entry.Timestamp.UTC().Format(time.RFC3339), PinObservedConfigAnnotation, c.history.namespace, c.history.name))
+		}
+		conditions = append(conditions, pinnedCondition)
+	}
+
 	if err := c.updateObservedConfig(ctx, syncCtx, existingConfig, mergedObservedConfig); err != nil {
 		errs = []error{err}
+	} else if c.history != nil && !pinned {
+		if err := c.history.Record(ctx, syncCtx.Recorder(), mergedObservedConfig); err != nil {
+			errs = append(errs, fmt.Errorf("error recording observed config history: %w", err))
+		}
 	}
 	configError := v1helpers.NewMultiLineAggregate(errs)
 
@@ -189,7 +309,7 @@ func (c ConfigObserver) sync(ctx context.Context, syncCtx factory.SyncContext) e
 			WithReason("Error").
 			WithMessage(configError.Error())
 	}
-	status := applyoperatorv1.OperatorStatus().WithConditions(condition)
+	status := applyoperatorv1.OperatorStatus().WithConditions(append(conditions, condition)...)
 	updateError := c.operatorClient.ApplyOperatorStatus(ctx, c.controllerInstanceName, status)
 	if updateError != nil {
 		return updateError
@@ -0,0 +1,116 @@
+package configobserver
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+)
+
+// DynamicListerGetter is implemented by Listers providing listers of arbitrary resources, e.g. third-party CRs without
+// typed clients. Listers implement it by embedding *DynamicListers.
+type DynamicListerGetter interface {
+	DynamicLister(gvr schema.GroupVersionResource) (cache.GenericLister, bool)
+}
+
+// DynamicListers holds informer-backed listers of arbitrary resources. Embed it into the Listers passed to the config
+// observer:
+//
+//	type Listers struct {
+//	  *configobserver.DynamicListers
+//	  ...
+//	}
+//
+//	listers := Listers{DynamicListers: configobserver.NewDynamicListers(dynamicInformers, fooGVR)}
+//
+// The config observer is triggered by changes of the registered resources and waits for their caches to sync before
+// the first observation. Observers get the listers with GetDynamicLister.
+type DynamicListers struct {
+	informerFactory dynamicinformer.DynamicSharedInformerFactory
+	informers       map[schema.GroupVersionResource]informers.GenericInformer
+	// gvrs are the registered resources in the order of registration
+	gvrs []schema.GroupVersionResource
+}
+
+var _ DynamicListerGetter = &DynamicListers{}
+
+// NewDynamicListers registers the resources in the informer factory. The factory must be started after the config
+// observer is created.
+func NewDynamicListers(informerFactory dynamicinformer.DynamicSharedInformerFactory, gvrs ...schema.GroupVersionResource) *DynamicListers {
+	l := &DynamicListers{
+		informerFactory: informerFactory,
+		informers:       map[schema.GroupVersionResource]informers.GenericInformer{},
+	}
+	for _, gvr := range gvrs {
+		l.AddResource(gvr)
+	}
+	return l
+}
+
+// AddResource registers a resource and returns its lister. Resources must be registered before the config observer is
+// created.
+func (l *DynamicListers) AddResource(gvr schema.GroupVersionResource) cache.GenericLister {
+	if informer, ok := l.informers[gvr]; ok {
+		return informer.Lister()
+	}
+	informer := l.informerFactory.ForResource(gvr)
+	l.informers[gvr] = informer
+	l.gvrs = append(l.gvrs, gvr)
+	return informer.Lister()
+}
+
+// DynamicLister returns the lister of a registered resource.
+func (l *DynamicListers) DynamicLister(gvr schema.GroupVersionResource) (cache.GenericLister, bool) {
+	if l == nil {
+		return nil, false
+	}
+	informer, ok := l.informers[gvr]
+	if !ok {
+		return nil, false
+	}
+	return informer.Lister(), true
+}
+
+// HasSynced returns true when the caches of all registered resources are synced.
+func (l *DynamicListers) HasSynced() bool {
+	for _, informer := range l.informers {
+		if !informer.Informer().HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// dynamicInformers returns the informers of the registered resources, which trigger the config observer.
+func (l *DynamicListers) dynamicInformers() []factory.Informer {
+	if l == nil {
+		return nil
+	}
+	ret := make([]factory.Informer, 0, len(l.gvrs))
+	for _, gvr := range l.gvrs {
+		ret = append(ret, l.informers[gvr].Informer())
+	}
+	return ret
+}
+
+// dynamicInformerSource is implemented by Listers embedding *DynamicListers.
+type dynamicInformerSource interface {
+	dynamicInformers() []factory.Informer
+}
+
+// GetDynamicLister returns the lister of a resource registered in the DynamicListers embedded in the listers.
+func GetDynamicLister(listers Listers, gvr schema.GroupVersionResource) (cache.GenericLister, error) {
+	getter, ok := listers.(DynamicListerGetter)
+	if !ok {
+		return nil, fmt.Errorf("listers %T do not provide dynamic listers", listers)
+	}
+	lister, ok := getter.DynamicLister(gvr)
+	if !ok {
+		return nil, fmt.Errorf("no dynamic lister registered for %s", gvr.String())
+	}
+	return lister, nil
+}
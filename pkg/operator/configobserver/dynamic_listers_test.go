@@ -0,0 +1,78 @@
+package configobserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+type fakeDynamicListers struct {
+	fakeLister
+	*DynamicListers
+}
+
+func TestDynamicListers(t *testing.T) {
+	fooGVR := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "foos"}
+	barGVR := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "bars"}
+	foo := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Foo",
+		"metadata":   map[string]interface{}{"name": "cluster"},
+		"spec":       map[string]interface{}{"logLevel": "Debug"},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{fooGVR: "FooList"}, foo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	informerFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, time.Minute)
+	listers := &fakeDynamicListers{DynamicListers: NewDynamicListers(informerFactory, fooGVR)}
+	if informers := listers.dynamicInformers(); len(informers) != 1 {
+		t.Fatalf("expected the informer of the registered resource, got %d", len(informers))
+	}
+	informerFactory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), listers.HasSynced) {
+		t.Fatal("caches did not sync")
+	}
+
+	observe := func(listers Listers, _ events.Recorder, _ map[string]interface{}) (map[string]interface{}, []error) {
+		lister, err := GetDynamicLister(listers, fooGVR)
+		if err != nil {
+			return nil, []error{err}
+		}
+		obj, err := lister.Get("cluster")
+		if err != nil {
+			return nil, []error{err}
+		}
+		logLevel, _, err := unstructured.NestedString(obj.(*unstructured.Unstructured).Object, "spec", "logLevel")
+		if err != nil {
+			return nil, []error{err}
+		}
+		return map[string]interface{}{"logLevel": logLevel}, nil
+	}
+	observed, errs := observe(listers, events.NewInMemoryRecorder("test"), nil)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if observed["logLevel"] != "Debug" {
+		t.Errorf("unexpected observed config %v", observed)
+	}
+
+	if _, err := GetDynamicLister(listers, barGVR); err == nil {
+		t.Errorf("expected an error for an unregistered resource")
+	}
+	if _, err := GetDynamicLister(&fakeLister{}, fooGVR); err == nil {
+		t.Errorf("expected an error for listers without dynamic listers")
+	}
+	if _, err := GetDynamicLister(&fakeDynamicListers{}, fooGVR); err == nil {
+		t.Errorf("expected an error for listers with nil dynamic listers")
+	}
+}
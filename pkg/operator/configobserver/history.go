@@ -0,0 +1,164 @@
+package configobserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+const (
+	// PinObservedConfigAnnotation on the history configmap pins the observed config to the history entry with the
+	// hash in the annotation value. Remove the annotation to resume config observation.
+	PinObservedConfigAnnotation = "operator.openshift.io/pin-observed-config"
+
+	historyKey = "history.json"
+)
+
+// ObservedConfigHistoryEntry is an observed config written by the config observer.
+type ObservedConfigHistoryEntry struct {
+	Hash           string                 `json:"hash"`
+	Timestamp      metav1.Time            `json:"timestamp"`
+	ObservedConfig map[string]interface{} `json:"observedConfig"`
+}
+
+// ObservedConfigHistory records the observed configs written by a config observer in a configmap, the newest entry
+// first. When a bad observation breaks the operand, cluster admins can pin the observed config to a previous entry
+// by setting PinObservedConfigAnnotation on the configmap.
+type ObservedConfigHistory struct {
+	namespace  string
+	name       string
+	maxEntries int
+	clock      clock.PassiveClock
+
+	client   coreclientv1.ConfigMapsGetter
+	lister   corev1listers.ConfigMapLister
+	informer factory.Informer
+}
+
+// NewObservedConfigHistory returns a history keeping up to maxEntries observed configs in the configmap. The
+// informers must contain the namespace of the configmap.
+func NewObservedConfigHistory(
+	namespace, name string,
+	maxEntries int,
+	client coreclientv1.ConfigMapsGetter,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+) *ObservedConfigHistory {
+	configMaps := kubeInformersForNamespaces.InformersFor(namespace).Core().V1().ConfigMaps()
+	return &ObservedConfigHistory{
+		namespace:  namespace,
+		name:       name,
+		maxEntries: maxEntries,
+		clock:      clock.RealClock{},
+		client:     client,
+		lister:     configMaps.Lister(),
+		informer:   configMaps.Informer(),
+	}
+}
+
+// Entries returns the recorded observed configs, the newest first.
+func (h *ObservedConfigHistory) Entries() ([]ObservedConfigHistoryEntry, error) {
+	configMap, err := h.lister.ConfigMaps(h.namespace).Get(h.name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return h.entries(configMap)
+}
+
+func (h *ObservedConfigHistory) entries(configMap *corev1.ConfigMap) ([]ObservedConfigHistoryEntry, error) {
+	data, ok := configMap.Data[historyKey]
+	if !ok {
+		return nil, nil
+	}
+	var entries []ObservedConfigHistoryEntry
+	if err := json.Unmarshal([]byte(data), &entries); err != nil {
+		return nil, fmt.Errorf("invalid observed config history in configmap %s/%s: %w", h.namespace, h.name, err)
+	}
+	return entries, nil
+}
+
+// Pinned returns the history entry the observed config is pinned to, or nil if it is not pinned. It fails if the
+// pinned hash is not in the history.
+func (h *ObservedConfigHistory) Pinned() (*ObservedConfigHistoryEntry, error) {
+	configMap, err := h.lister.ConfigMaps(h.namespace).Get(h.name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	hash, ok := configMap.Annotations[PinObservedConfigAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	entries, err := h.entries(configMap)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].Hash == hash {
+			return &entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("observed config %q pinned by annotation %s on configmap %s/%s is not in the history", hash, PinObservedConfigAnnotation, h.namespace, h.name)
+}
+
+// Record adds the observed config to the history unless it is the newest entry already. The oldest entries are
+// dropped beyond the maximum number of entries.
+func (h *ObservedConfigHistory) Record(ctx context.Context, recorder events.Recorder, observedConfig map[string]interface{}) error {
+	hash, err := ObservedConfigHash(observedConfig)
+	if err != nil {
+		return err
+	}
+	entries, err := h.Entries()
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 && entries[0].Hash == hash {
+		return nil
+	}
+
+	entries = append([]ObservedConfigHistoryEntry{{
+		Hash:           hash,
+		Timestamp:      metav1.NewTime(h.clock.Now()),
+		ObservedConfig: observedConfig,
+	}}, entries...)
+	if len(entries) > h.maxEntries {
+		entries = entries[:h.maxEntries]
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = resourceapply.ApplyConfigMap(ctx, h.client, recorder, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: h.namespace, Name: h.name},
+		Data:       map[string]string{historyKey: string(data)},
+	})
+	return err
+}
+
+// ObservedConfigHash returns the hash identifying the observed config in the history.
+func ObservedConfigHash(observedConfig map[string]interface{}) (string, error) {
+	// map keys are sorted in JSON, the hash is stable
+	data, err := json.Marshal(observedConfig)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data))[:16], nil
+}
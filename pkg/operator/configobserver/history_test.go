@@ -0,0 +1,145 @@
+package configobserver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestObservedConfigHistory(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	kubeInformers := v1helpers.NewKubeInformersForNamespaces(kubeClient, "operator")
+	indexer := kubeInformers.InformersFor("operator").Core().V1().ConfigMaps().Informer().GetIndexer()
+	history := NewObservedConfigHistory("operator", "observed-config-history", 2, kubeClient.CoreV1(), kubeInformers)
+	clock := clocktesting.NewFakePassiveClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	history.clock = clock
+	recorder := events.NewInMemoryRecorder("test")
+
+	// records and syncs the informer cache with the client
+	record := func(observedConfig map[string]interface{}) {
+		t.Helper()
+		if err := history.Record(context.TODO(), recorder, observedConfig); err != nil {
+			t.Fatal(err)
+		}
+		configMap, err := kubeClient.CoreV1().ConfigMaps("operator").Get(context.TODO(), "observed-config-history", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := indexer.Update(configMap); err != nil {
+			t.Fatal(err)
+		}
+		clock.SetTime(clock.Now().Add(time.Minute))
+	}
+
+	first := map[string]interface{}{"servingInfo": map[string]interface{}{"minTLSVersion": "VersionTLS12"}}
+	second := map[string]interface{}{"servingInfo": map[string]interface{}{"minTLSVersion": "VersionTLS13"}}
+	third := map[string]interface{}{}
+	record(first)
+	record(first)
+	record(second)
+	entries, err := history.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || !reflect.DeepEqual(entries[0].ObservedConfig, second) || !reflect.DeepEqual(entries[1].ObservedConfig, first) {
+		t.Fatalf("unexpected history %#v", entries)
+	}
+	firstHash := entries[1].Hash
+
+	record(third)
+	entries, err = history.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[1].Hash == firstHash {
+		t.Fatalf("expected the oldest entry to be dropped, got %#v", entries)
+	}
+	secondHash := entries[1].Hash
+
+	// pin to the second config
+	configMap, err := kubeClient.CoreV1().ConfigMaps("operator").Get(context.TODO(), "observed-config-history", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	configMap.Annotations = map[string]string{PinObservedConfigAnnotation: secondHash}
+	if err := indexer.Update(configMap); err != nil {
+		t.Fatal(err)
+	}
+
+	operatorClient := &fakeOperatorClient{startingSpec: &operatorv1.OperatorSpec{}}
+	configObserver := ConfigObserver{
+		listers:        &fakeLister{},
+		operatorClient: operatorClient,
+		observers: []ObserveConfigFunc{
+			func(Listers, events.Recorder, map[string]interface{}) (map[string]interface{}, []error) {
+				return map[string]interface{}{"bad": "observation"}, nil
+			},
+		},
+		degradedConditionType: "ConfigObservationDegraded",
+		pinnedConditionType:   "ObservedConfigPinnedDegraded",
+		history:               history,
+	}
+	syncContext := factory.NewSyncContext("test", recorder)
+	if err := configObserver.sync(context.TODO(), syncContext); err != nil {
+		t.Fatal(err)
+	}
+	if operatorClient.spec == nil || !reflect.DeepEqual(operatorClient.spec.ObservedConfig.Object, &unstructured.Unstructured{Object: second}) {
+		t.Errorf("expected the pinned config to be written, got %#v", operatorClient.spec)
+	}
+	if condition := v1helpers.FindOperatorCondition(operatorClient.status.Conditions, "ObservedConfigPinnedDegraded"); condition == nil || condition.Reason != "Pinned" {
+		t.Errorf("expected pinned condition, got %#v", condition)
+	}
+
+	// an unknown pin keeps the existing config
+	configMap.Annotations[PinObservedConfigAnnotation] = "unknown"
+	if err := indexer.Update(configMap); err != nil {
+		t.Fatal(err)
+	}
+	operatorClient.spec = nil
+	if err := configObserver.sync(context.TODO(), syncContext); err != nil {
+		t.Fatal(err)
+	}
+	if operatorClient.spec != nil {
+		t.Errorf("expected no observed config update, got %#v", operatorClient.spec)
+	}
+	if condition := v1helpers.FindOperatorCondition(operatorClient.status.Conditions, "ObservedConfigPinnedDegraded"); condition == nil || condition.Reason != "PinInvalid" {
+		t.Errorf("expected invalid pin condition, got %#v", condition)
+	}
+
+	// without the pin the observation is written and recorded
+	delete(configMap.Annotations, PinObservedConfigAnnotation)
+	if err := indexer.Update(configMap); err != nil {
+		t.Fatal(err)
+	}
+	if err := configObserver.sync(context.TODO(), syncContext); err != nil {
+		t.Fatal(err)
+	}
+	if condition := v1helpers.FindOperatorCondition(operatorClient.status.Conditions, "ObservedConfigPinnedDegraded"); condition == nil || condition.Status != operatorv1.ConditionFalse {
+		t.Errorf("expected pinned condition to be false, got %#v", condition)
+	}
+	configMap, err = kubeClient.CoreV1().ConfigMaps("operator").Get(context.TODO(), "observed-config-history", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := indexer.Update(configMap); err != nil {
+		t.Fatal(err)
+	}
+	entries, err = history.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(entries[0].ObservedConfig, map[string]interface{}{"bad": "observation"}) {
+		t.Errorf("expected the observation to be recorded, got %#v", entries[0])
+	}
+}
@@ -0,0 +1,89 @@
+package node
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// LatencyProfileArgs declares the arguments of an operand for every worker latency profile, e.g. for the
+// kube-controller-manager:
+//
+//	LatencyProfileArgs{
+//		ConfigPath: []string{"extendedArguments"},
+//		Profiles: map[configv1.WorkerLatencyProfileType]map[string]string{
+//			configv1.DefaultUpdateDefaultReaction: {"node-monitor-grace-period": "40s"},
+//			configv1.MediumUpdateAverageReaction:  {"node-monitor-grace-period": "2m"},
+//			configv1.LowUpdateSlowReaction:        {"node-monitor-grace-period": "5m"},
+//		},
+//	}
+//
+// Its LatencyConfigs are used with the latency profile observer, matcher and rejection checker like the hand written
+// ones.
+type LatencyProfileArgs struct {
+	// ConfigPath is the path to the arguments in the observed config, e.g. []string{"apiServerArguments"}.
+	ConfigPath []string
+	// Profiles maps every profile to the values of the arguments, by argument name. Every profile must set the same
+	// arguments, so switching profiles replaces all of them.
+	Profiles map[configv1.WorkerLatencyProfileType]map[string]string
+}
+
+// LatencyConfigs returns the arg value pairs of the table, sorted by argument name. It fails when the profiles do not
+// set the same arguments.
+func (a LatencyProfileArgs) LatencyConfigs() ([]LatencyConfigProfileTuple, error) {
+	var argNames sets.Set[string]
+	var firstProfile configv1.WorkerLatencyProfileType
+	profiles := sets.List(sets.KeySet(a.Profiles))
+	for _, profile := range profiles {
+		names := sets.KeySet(a.Profiles[profile])
+		if argNames == nil {
+			argNames, firstProfile = names, profile
+			continue
+		}
+		if !names.Equal(argNames) {
+			return nil, fmt.Errorf("latency profile %q sets the arguments %v, but profile %q sets %v", profile, sets.List(names), firstProfile, sets.List(argNames))
+		}
+	}
+
+	var ret []LatencyConfigProfileTuple
+	for _, argName := range sets.List(argNames) {
+		values := map[configv1.WorkerLatencyProfileType]string{}
+		for _, profile := range profiles {
+			values[profile] = a.Profiles[profile][argName]
+		}
+		ret = append(ret, LatencyConfigProfileTuple{
+			ConfigPath:          append(append([]string{}, a.ConfigPath...), argName),
+			ProfileConfigValues: values,
+		})
+	}
+	return ret, nil
+}
+
+// LatencyConfigsFor returns the arg value pairs of all the tables, sorted by config path. It fails when the tables
+// set the same argument.
+func LatencyConfigsFor(args ...LatencyProfileArgs) ([]LatencyConfigProfileTuple, error) {
+	var ret []LatencyConfigProfileTuple
+	paths := sets.New[string]()
+	for _, table := range args {
+		configs, err := table.LatencyConfigs()
+		if err != nil {
+			return nil, err
+		}
+		for _, config := range configs {
+			path := strings.Join(config.ConfigPath, ".")
+			if paths.Has(path) {
+				return nil, fmt.Errorf("the argument %s is set by several tables", path)
+			}
+			paths.Insert(path)
+		}
+		ret = append(ret, configs...)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return strings.Join(ret[i].ConfigPath, ".") < strings.Join(ret[j].ConfigPath, ".")
+	})
+	return ret, nil
+}
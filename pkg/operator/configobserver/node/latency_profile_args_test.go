@@ -0,0 +1,61 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyProfileArgs(t *testing.T) {
+	kcmArgs := LatencyProfileArgs{
+		ConfigPath: []string{"extendedArguments"},
+		Profiles: map[configv1.WorkerLatencyProfileType]map[string]string{
+			configv1.DefaultUpdateDefaultReaction: {"node-monitor-grace-period": configv1.DefaultNodeMonitorGracePeriod.String()},
+			configv1.MediumUpdateAverageReaction:  {"node-monitor-grace-period": configv1.MediumNodeMonitorGracePeriod.String()},
+			configv1.LowUpdateSlowReaction:        {"node-monitor-grace-period": configv1.LowNodeMonitorGracePeriod.String()},
+		},
+	}
+	configs, err := kcmArgs.LatencyConfigs()
+	require.NoError(t, err)
+	if diff := cmp.Diff(kcmLatencyConfigs, configs); diff != "" {
+		t.Errorf("unexpected latency configs (-want +got):\n%s", diff)
+	}
+
+	kasArgs := LatencyProfileArgs{
+		ConfigPath: []string{"apiServerArguments"},
+		Profiles: map[configv1.WorkerLatencyProfileType]map[string]string{
+			configv1.DefaultUpdateDefaultReaction: {"default-not-ready-toleration-seconds": "300", "default-unreachable-toleration-seconds": "300"},
+			configv1.LowUpdateSlowReaction:        {"default-not-ready-toleration-seconds": "60", "default-unreachable-toleration-seconds": "60"},
+		},
+	}
+	configs, err = LatencyConfigsFor(kcmArgs, kasArgs)
+	require.NoError(t, err)
+	var paths [][]string
+	for _, config := range configs {
+		paths = append(paths, config.ConfigPath)
+	}
+	expectedPaths := [][]string{
+		{"apiServerArguments", "default-not-ready-toleration-seconds"},
+		{"apiServerArguments", "default-unreachable-toleration-seconds"},
+		{"extendedArguments", "node-monitor-grace-period"},
+	}
+	if diff := cmp.Diff(expectedPaths, paths); diff != "" {
+		t.Errorf("unexpected config paths (-want +got):\n%s", diff)
+	}
+	require.Equal(t, "60", configs[1].ProfileConfigValues[configv1.LowUpdateSlowReaction])
+
+	_, err = LatencyConfigsFor(kcmArgs, kcmArgs)
+	require.EqualError(t, err, "the argument extendedArguments.node-monitor-grace-period is set by several tables")
+
+	incomplete := LatencyProfileArgs{
+		ConfigPath: []string{"apiServerArguments"},
+		Profiles: map[configv1.WorkerLatencyProfileType]map[string]string{
+			configv1.DefaultUpdateDefaultReaction: {"default-not-ready-toleration-seconds": "300", "default-unreachable-toleration-seconds": "300"},
+			configv1.LowUpdateSlowReaction:        {"default-not-ready-toleration-seconds": "60"},
+		},
+	}
+	_, err = incomplete.LatencyConfigs()
+	require.Error(t, err)
+}
@@ -0,0 +1,165 @@
+package configobserver
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// ObservedConfigSchema is the registry of the observed config paths owned by the observers of a config observer.
+// Fields outside of all owned paths are stale, e.g. left behind by an observer that was removed or moved in an
+// operator upgrade. Stale fields are kept for the grace period after they were first seen, then pruned.
+//
+// All observers of the config observer must be registered with Owns, the output of unregistered observers is stale.
+type ObservedConfigSchema struct {
+	gracePeriod time.Duration
+	clock       clock.PassiveClock
+
+	lock       sync.Mutex
+	paths      [][]string
+	staleSince map[string]time.Time
+}
+
+// NewObservedConfigSchema returns an empty schema pruning stale fields after the grace period.
+func NewObservedConfigSchema(gracePeriod time.Duration) *ObservedConfigSchema {
+	return &ObservedConfigSchema{
+		gracePeriod: gracePeriod,
+		clock:       clock.RealClock{},
+		staleSince:  map[string]time.Time{},
+	}
+}
+
+// Owns registers the paths owned by the observer and returns the observer with its output pruned to these paths.
+func (s *ObservedConfigSchema) Owns(observer ObserveConfigFunc, paths ...[]string) ObserveConfigFunc {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.paths = append(s.paths, paths...)
+
+	return func(listers Listers, recorder events.Recorder, existingConfig map[string]interface{}) (map[string]interface{}, []error) {
+		observedConfig, errs := observer(listers, recorder, existingConfig)
+		if observedConfig == nil {
+			return nil, errs
+		}
+		return Pruned(observedConfig, paths...), errs
+	}
+}
+
+// Paths returns the registered paths.
+func (s *ObservedConfigSchema) Paths() [][]string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([][]string{}, s.paths...)
+}
+
+// prune returns the observed config restricted to the owned paths, plus the stale fields of the observed or existing
+// config still in their grace period.
+func (s *ObservedConfigSchema) prune(existingConfig, observedConfig map[string]interface{}, recorder events.Recorder) map[string]interface{} {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ret := map[string]interface{}{}
+	if len(s.paths) > 0 {
+		ret = Pruned(observedConfig, s.paths...)
+	}
+
+	stale := map[string][]string{}
+	for _, config := range []map[string]interface{}{existingConfig, observedConfig} {
+		for _, path := range s.staleFields(config, nil) {
+			stale[strings.Join(path, ".")] = path
+		}
+	}
+	for key := range s.staleSince {
+		if _, ok := stale[key]; !ok {
+			delete(s.staleSince, key)
+		}
+	}
+
+	keys := make([]string, 0, len(stale))
+	for key := range stale {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	now := s.clock.Now()
+	for _, key := range keys {
+		path := stale[key]
+		since, ok := s.staleSince[key]
+		if !ok {
+			since = now
+			s.staleSince[key] = now
+		}
+		if !now.Before(since.Add(s.gracePeriod)) {
+			recorder.Eventf("ObservedConfigPruned", "Pruning %q from the observed config, it is not owned by any config observer", key)
+			continue
+		}
+
+		value, found, err := unstructured.NestedFieldNoCopy(observedConfig, path...)
+		if err != nil || !found {
+			value, found, err = unstructured.NestedFieldNoCopy(existingConfig, path...)
+		}
+		if err != nil || !found {
+			continue
+		}
+		if err := unstructured.SetNestedField(ret, runtime.DeepCopyJSONValue(value), path...); err != nil {
+			continue
+		}
+	}
+
+	return ret
+}
+
+// staleFields returns the paths of the fields of the config that are neither in nor above an owned path.
+func (s *ObservedConfigSchema) staleFields(config map[string]interface{}, prefix []string) [][]string {
+	var ret [][]string
+	for key, value := range config {
+		path := append(append([]string{}, prefix...), key)
+		switch {
+		case s.isOwned(path):
+		case s.isAboveOwned(path):
+			if nested, ok := value.(map[string]interface{}); ok {
+				ret = append(ret, s.staleFields(nested, path)...)
+				continue
+			}
+			ret = append(ret, path)
+		default:
+			ret = append(ret, path)
+		}
+	}
+	return ret
+}
+
+// isOwned returns true if an owned path is a prefix of the path.
+func (s *ObservedConfigSchema) isOwned(path []string) bool {
+	for _, owned := range s.paths {
+		if len(owned) <= len(path) && hasPrefix(path, owned) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAboveOwned returns true if the path is a strict prefix of an owned path.
+func (s *ObservedConfigSchema) isAboveOwned(path []string) bool {
+	for _, owned := range s.paths {
+		if len(owned) > len(path) && hasPrefix(owned, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPrefix(path, prefix []string) bool {
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package configobserver
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestObservedConfigSchema(t *testing.T) {
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	schema := NewObservedConfigSchema(time.Minute)
+	schema.clock = clock
+
+	observer := schema.Owns(func(Listers, events.Recorder, map[string]interface{}) (map[string]interface{}, []error) {
+		return map[string]interface{}{
+			"servingInfo": map[string]interface{}{
+				"cipherSuites": []interface{}{"TLS_AES_128_GCM_SHA256"},
+				// not owned by this observer
+				"minTLSVersion": "VersionTLS12",
+			},
+		}, nil
+	}, []string{"servingInfo", "cipherSuites"})
+	if got, _ := observer(nil, nil, nil); !reflect.DeepEqual(got, map[string]interface{}{
+		"servingInfo": map[string]interface{}{"cipherSuites": []interface{}{"TLS_AES_128_GCM_SHA256"}},
+	}) {
+		t.Errorf("expected the observer output to be pruned to its paths, got %v", got)
+	}
+	schema.Owns(nil, []string{"proxy"})
+
+	existing := map[string]interface{}{
+		"servingInfo": map[string]interface{}{
+			"cipherSuites":     []interface{}{"TLS_AES_256_GCM_SHA384"},
+			"namedCertificate": "removed-in-upgrade",
+		},
+		"oldObserver": map[string]interface{}{"key": "value"},
+		"proxy":       map[string]interface{}{"HTTP_PROXY": "http://proxy"},
+	}
+	observed := map[string]interface{}{
+		"servingInfo": map[string]interface{}{"cipherSuites": []interface{}{"TLS_AES_128_GCM_SHA256"}},
+	}
+
+	recorder := events.NewInMemoryRecorder("test")
+	got := schema.prune(existing, observed, recorder)
+	expected := map[string]interface{}{
+		"servingInfo": map[string]interface{}{
+			"cipherSuites":     []interface{}{"TLS_AES_128_GCM_SHA256"},
+			"namedCertificate": "removed-in-upgrade",
+		},
+		"oldObserver": map[string]interface{}{"key": "value"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected stale fields to be kept in the grace period, got %v", got)
+	}
+	if len(recorder.Events()) != 0 {
+		t.Errorf("unexpected events %v", recorder.Events())
+	}
+
+	clock.SetTime(clock.Now().Add(time.Minute))
+	got = schema.prune(existing, observed, recorder)
+	if !reflect.DeepEqual(got, observed) {
+		t.Errorf("expected stale fields to be pruned after the grace period, got %v", got)
+	}
+	if len(recorder.Events()) != 2 {
+		t.Errorf("expected 2 events, got %v", recorder.Events())
+	}
+
+	// the grace period starts over for new stale fields
+	existing = map[string]interface{}{"anotherOldObserver": "value"}
+	got = schema.prune(existing, observed, events.NewInMemoryRecorder("test"))
+	if _, ok := got["anotherOldObserver"]; !ok {
+		t.Errorf("expected new stale field to be kept, got %v", got)
+	}
+}
@@ -0,0 +1,204 @@
+// Package conformance checks that controllers built with this library follow its conventions: they report their
+// conditions, emit events, settle instead of hot-looping and do not write anything once they converged. Operators run
+// their controllers against fake clients, or clients of a test API server like envtest, in unit tests to gate changes
+// on these contracts.
+//
+// Example:
+//
+//	h := conformance.NewHarness(t, controller, operatorClient, recorder, kubeClient)
+//	h.ExpectNoHotloop(ctx, 5)
+//	h.ExpectIdempotent(ctx, 3)
+//	h.ExpectConditions("FooDegraded")
+//	h.ExpectEvents("ConfigMapCreated")
+package conformance
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// ActionTracker records the requests sent by a client, e.g. a fake clientset.
+type ActionTracker interface {
+	Actions() []clienttesting.Action
+	ClearActions()
+}
+
+// SyncResult is the outcome of a single sync of the controller.
+type SyncResult struct {
+	Err error
+	// Requeued is true when the sync asked to be run again right away, by returning factory.SyntheticRequeueError or by
+	// adding to the queue without a delay.
+	Requeued bool
+	// Writes are the mutating requests of the sync sent by the tracked clients, except for events.
+	Writes []clienttesting.Action
+	// Status is the operator status after the sync.
+	Status *operatorv1.OperatorStatus
+}
+
+// Harness syncs a controller and checks the results against the conventions of the library.
+type Harness struct {
+	t              testing.TB
+	controller     factory.Controller
+	operatorClient v1helpers.OperatorClient
+	recorder       events.InMemoryRecorder
+	trackers       []ActionTracker
+}
+
+// NewHarness returns a harness syncing the controller, which must be constructed with the given operator client and
+// recorder. The requests of the clients given as trackers are checked for writes.
+func NewHarness(t testing.TB, controller factory.Controller, operatorClient v1helpers.OperatorClient, recorder events.InMemoryRecorder, trackers ...ActionTracker) *Harness {
+	return &Harness{
+		t:              t,
+		controller:     controller,
+		operatorClient: operatorClient,
+		recorder:       recorder,
+		trackers:       trackers,
+	}
+}
+
+// Sync runs a single sync of the controller.
+func (h *Harness) Sync(ctx context.Context) SyncResult {
+	h.t.Helper()
+
+	for _, tracker := range h.trackers {
+		tracker.ClearActions()
+	}
+	syncCtx := factory.NewSyncContext(h.controller.Name(), h.recorder)
+	err := h.controller.Sync(ctx, syncCtx)
+
+	result := SyncResult{
+		Err:      err,
+		Requeued: errors.Is(err, factory.SyntheticRequeueError) || syncCtx.Queue().Len() > 0,
+	}
+	for _, tracker := range h.trackers {
+		for _, action := range tracker.Actions() {
+			if isWrite(action) {
+				result.Writes = append(result.Writes, action)
+			}
+		}
+	}
+	_, status, _, err := h.operatorClient.GetOperatorState()
+	if err != nil {
+		h.t.Fatalf("failed to get the operator status: %v", err)
+	}
+	result.Status = status.DeepCopy()
+	return result
+}
+
+// ExpectNoHotloop syncs the controller up to the given number of times and fails when it does not settle, i.e. every
+// sync asks to be run again right away, or when it asks so again after it settled.
+func (h *Harness) ExpectNoHotloop(ctx context.Context, syncs int) {
+	h.t.Helper()
+
+	settled := false
+	for i := 0; i < syncs; i++ {
+		result := h.Sync(ctx)
+		switch {
+		case !result.Requeued:
+			settled = true
+		case settled:
+			h.t.Errorf("controller %s asked to be requeued in sync %d after it settled: %v", h.controller.Name(), i+1, result.Err)
+			return
+		}
+	}
+	if !settled {
+		h.t.Errorf("controller %s did not settle within %d syncs", h.controller.Name(), syncs)
+	}
+}
+
+// ExpectIdempotent syncs the controller once to converge, and then the given number of times, failing on any write or
+// change of the operator status by the later syncs.
+func (h *Harness) ExpectIdempotent(ctx context.Context, syncs int) {
+	h.t.Helper()
+
+	converged := h.Sync(ctx)
+	for i := 0; i < syncs; i++ {
+		result := h.Sync(ctx)
+		for _, action := range result.Writes {
+			h.t.Errorf("controller %s is not idempotent, sync %d after it converged sent %s", h.controller.Name(), i+1, describe(action))
+		}
+		if !equalStatus(converged.Status, result.Status) {
+			h.t.Errorf("controller %s is not idempotent, sync %d after it converged changed the operator status:\n%#v\nto:\n%#v", h.controller.Name(), i+1, converged.Status, result.Status)
+		}
+	}
+}
+
+// ExpectConditions fails unless the operator status has the conditions with a valid status, and true conditions have a
+// reason.
+func (h *Harness) ExpectConditions(conditionTypes ...string) {
+	h.t.Helper()
+
+	_, status, _, err := h.operatorClient.GetOperatorState()
+	if err != nil {
+		h.t.Fatalf("failed to get the operator status: %v", err)
+	}
+	for _, conditionType := range conditionTypes {
+		condition := v1helpers.FindOperatorCondition(status.Conditions, conditionType)
+		switch {
+		case condition == nil:
+			h.t.Errorf("condition %s is not set", conditionType)
+		case condition.Status != operatorv1.ConditionTrue && condition.Status != operatorv1.ConditionFalse && condition.Status != operatorv1.ConditionUnknown:
+			h.t.Errorf("condition %s has an invalid status %q", conditionType, condition.Status)
+		case condition.Status == operatorv1.ConditionTrue && len(condition.Reason) == 0:
+			h.t.Errorf("condition %s is true without a reason", conditionType)
+		}
+	}
+}
+
+// ExpectEvents fails unless events with the reasons were emitted by the syncs so far.
+func (h *Harness) ExpectEvents(reasons ...string) {
+	h.t.Helper()
+
+	emitted := map[string]bool{}
+	for _, event := range h.recorder.Events() {
+		emitted[event.Reason] = true
+	}
+	for _, reason := range reasons {
+		if !emitted[reason] {
+			h.t.Errorf("no event with reason %s was emitted", reason)
+		}
+	}
+}
+
+func isWrite(action clienttesting.Action) bool {
+	if action.GetResource().Resource == "events" {
+		return false
+	}
+	switch action.GetVerb() {
+	case "create", "update", "patch", "delete", "delete-collection":
+		return true
+	}
+	return false
+}
+
+func describe(action clienttesting.Action) string {
+	description := action.GetVerb() + " " + action.GetResource().Resource
+	if len(action.GetSubresource()) > 0 {
+		description += "/" + action.GetSubresource()
+	}
+	if len(action.GetNamespace()) > 0 {
+		description += " in " + action.GetNamespace()
+	}
+	return description
+}
+
+// equalStatus compares operator statuses ignoring the transition times of the conditions.
+func equalStatus(a, b *operatorv1.OperatorStatus) bool {
+	a, b = a.DeepCopy(), b.DeepCopy()
+	for _, status := range []*operatorv1.OperatorStatus{a, b} {
+		for i := range status.Conditions {
+			status.Conditions[i].LastTransitionTime = metav1.Time{}
+		}
+	}
+	return equality.Semantic.DeepEqual(a, b)
+}
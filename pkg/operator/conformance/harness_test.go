@@ -0,0 +1,169 @@
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/staticresourcecontroller"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// recordingTB records the failures instead of failing the test.
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestStaticResourceControllerConforms(t *testing.T) {
+	manifest := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: operand-config
+  namespace: operand-namespace
+data:
+  key: value
+`
+	kubeClient := fake.NewSimpleClientset()
+	operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{ManagementState: operatorv1.Managed}, &operatorv1.OperatorStatus{}, nil)
+	recorder := events.NewInMemoryRecorder("test")
+	controller := staticresourcecontroller.NewStaticResourceController(
+		"Operand",
+		func(string) ([]byte, error) { return []byte(manifest), nil },
+		[]string{"configmap.yaml"},
+		resourceapply.NewKubeClientHolder(kubeClient),
+		operatorClient,
+		recorder,
+	)
+
+	h := NewHarness(t, controller, operatorClient, recorder, kubeClient)
+	ctx := context.Background()
+	h.ExpectNoHotloop(ctx, 3)
+	h.ExpectIdempotent(ctx, 3)
+	h.ExpectConditions("OperandDegraded")
+	h.ExpectEvents("ConfigMapCreated")
+}
+
+type fakeController struct {
+	sync func(ctx context.Context, syncCtx factory.SyncContext) error
+}
+
+func (c *fakeController) Run(ctx context.Context, workers int) {}
+
+func (c *fakeController) Sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	return c.sync(ctx, syncCtx)
+}
+
+func (c *fakeController) Name() string {
+	return "FakeController"
+}
+
+func TestViolations(t *testing.T) {
+	tests := []struct {
+		name        string
+		sync        func(kubeClient *fake.Clientset, operatorClient v1helpers.OperatorClient) factory.SyncFunc
+		check       func(ctx context.Context, h *Harness)
+		expFailures int
+	}{
+		{
+			name: "hotloop",
+			sync: func(*fake.Clientset, v1helpers.OperatorClient) factory.SyncFunc {
+				return func(ctx context.Context, syncCtx factory.SyncContext) error {
+					syncCtx.Queue().Add(syncCtx.QueueKey())
+					return nil
+				}
+			},
+			check:       func(ctx context.Context, h *Harness) { h.ExpectNoHotloop(ctx, 3) },
+			expFailures: 1,
+		},
+		{
+			name: "requeue after settling",
+			sync: func(*fake.Clientset, v1helpers.OperatorClient) factory.SyncFunc {
+				var syncs int
+				return func(ctx context.Context, syncCtx factory.SyncContext) error {
+					if syncs++; syncs == 3 {
+						return factory.SyntheticRequeueError
+					}
+					return nil
+				}
+			},
+			check:       func(ctx context.Context, h *Harness) { h.ExpectNoHotloop(ctx, 3) },
+			expFailures: 1,
+		},
+		{
+			name: "write in every sync",
+			sync: func(kubeClient *fake.Clientset, _ v1helpers.OperatorClient) factory.SyncFunc {
+				var syncs int
+				return func(ctx context.Context, syncCtx factory.SyncContext) error {
+					syncs++
+					_, err := kubeClient.CoreV1().ConfigMaps("ns").Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: fmt.Sprintf("cm-%d", syncs)}}, metav1.CreateOptions{})
+					return err
+				}
+			},
+			check:       func(ctx context.Context, h *Harness) { h.ExpectIdempotent(ctx, 2) },
+			expFailures: 2,
+		},
+		{
+			name: "status changes in every sync",
+			sync: func(_ *fake.Clientset, operatorClient v1helpers.OperatorClient) factory.SyncFunc {
+				var syncs int
+				return func(ctx context.Context, syncCtx factory.SyncContext) error {
+					syncs++
+					_, _, err := v1helpers.UpdateStatus(ctx, operatorClient, v1helpers.UpdateConditionFn(operatorv1.OperatorCondition{
+						Type:    "FakeDegraded",
+						Status:  operatorv1.ConditionFalse,
+						Message: fmt.Sprintf("sync %d", syncs),
+					}))
+					return err
+				}
+			},
+			check:       func(ctx context.Context, h *Harness) { h.ExpectIdempotent(ctx, 1) },
+			expFailures: 1,
+		},
+		{
+			name: "missing condition and event",
+			sync: func(_ *fake.Clientset, operatorClient v1helpers.OperatorClient) factory.SyncFunc {
+				return func(ctx context.Context, syncCtx factory.SyncContext) error {
+					_, _, err := v1helpers.UpdateStatus(ctx, operatorClient, v1helpers.UpdateConditionFn(operatorv1.OperatorCondition{
+						Type:   "FakeDegraded",
+						Status: operatorv1.ConditionTrue,
+					}))
+					return err
+				}
+			},
+			check: func(ctx context.Context, h *Harness) {
+				h.Sync(ctx)
+				h.ExpectConditions("FakeDegraded", "FakeAvailable")
+				h.ExpectEvents("FakeEvent")
+			},
+			expFailures: 3,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			kubeClient := fake.NewSimpleClientset()
+			operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil)
+			tb := &recordingTB{TB: t}
+			h := NewHarness(tb, &fakeController{sync: tc.sync(kubeClient, operatorClient)}, operatorClient, events.NewInMemoryRecorder("test"), kubeClient)
+
+			tc.check(context.Background(), h)
+			if len(tb.failures) != tc.expFailures {
+				t.Errorf("expected %d failures, got %d: %v", tc.expFailures, len(tb.failures), tb.failures)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package crdlifecycle
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclientv1 "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	apiextensionsinformersv1 "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+	migrationv1alpha1 "sigs.k8s.io/kube-storage-version-migrator/pkg/apis/migration/v1alpha1"
+	kubemigratorclient "sigs.k8s.io/kube-storage-version-migrator/pkg/clients/clientset"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/management"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// CRDLifecycleController rolls out new schemas of the operator's CRDs. Changing the storage version of a CRD
+// cannot be done in a single apply, the controller sequences it:
+//
+//  1. the new versions are added as served, the storage version is kept and versions that are going away stay served
+//  2. the storage version is flipped to the new storage version
+//  3. the stored objects are migrated to the new storage version through a StorageVersionMigration
+//  4. the old versions are dropped from status.storedVersions
+//  5. the required CRD is applied as is, which removes the old versions
+//
+// Every sync does at most one step per CRD, the next step is taken once the previous one is observed. While a CRD
+// is in transition, the <name>CRDLifecycleUpgradeable condition is false.
+type CRDLifecycleController struct {
+	controllerInstanceName string
+	conditionPrefix        string
+	crds                   []*apiextensionsv1.CustomResourceDefinition
+
+	operatorClient  v1helpers.OperatorClient
+	crdClient       apiextensionsclientv1.CustomResourceDefinitionsGetter
+	migrationClient kubemigratorclient.Interface
+}
+
+// NewCRDLifecycleController returns a controller rolling out the required CRDs.
+func NewCRDLifecycleController(
+	instanceName string,
+	crds []*apiextensionsv1.CustomResourceDefinition,
+	operatorClient v1helpers.OperatorClient,
+	crdClient apiextensionsclientv1.CustomResourceDefinitionsGetter,
+	crdInformer apiextensionsinformersv1.CustomResourceDefinitionInformer,
+	migrationClient kubemigratorclient.Interface,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &CRDLifecycleController{
+		controllerInstanceName: factory.ControllerInstanceName(instanceName, "CRDLifecycle"),
+		conditionPrefix:        instanceName + "CRDLifecycle",
+		crds:                   crds,
+		operatorClient:         operatorClient,
+		crdClient:              crdClient,
+		migrationClient:        migrationClient,
+	}
+
+	names := make([]string, 0, len(crds))
+	for _, crd := range crds {
+		names = append(names, crd.Name)
+	}
+	return factory.New().
+		WithInformers(operatorClient.Informer()).
+		WithFilteredEventsInformers(factory.NamesFilter(names...), crdInformer.Informer()).
+		// migrations are polled, the migrator informer is rarely available to operators
+		ResyncEvery(30*time.Second).
+		WithSync(c.sync).
+		ToController(
+			c.controllerInstanceName,
+			eventRecorder.WithComponentSuffix("crd-lifecycle-controller"),
+		)
+}
+
+func (c *CRDLifecycleController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	operatorSpec, _, _, err := c.operatorClient.GetOperatorState()
+	if err != nil {
+		return err
+	}
+	if !management.IsOperatorManaged(operatorSpec.ManagementState) {
+		return nil
+	}
+
+	var inTransition []string
+	var errs []error
+	for _, required := range c.crds {
+		step, err := c.syncCRD(ctx, syncCtx.Recorder(), required)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", required.Name, err))
+		}
+		if len(step) > 0 {
+			inTransition = append(inTransition, fmt.Sprintf("%s: %s", required.Name, step))
+		}
+	}
+	sort.Strings(inTransition)
+
+	upgradeable := applyoperatorv1.OperatorCondition().
+		WithType(c.conditionPrefix + operatorv1.OperatorStatusTypeUpgradeable).
+		WithStatus(operatorv1.ConditionTrue).
+		WithReason("AsExpected")
+	if len(inTransition) > 0 {
+		upgradeable = upgradeable.
+			WithStatus(operatorv1.ConditionFalse).
+			WithReason("CRDTransitionInProgress").
+			WithMessage(strings.Join(inTransition, "\n"))
+	}
+	if err := c.operatorClient.ApplyOperatorStatus(
+		ctx,
+		c.controllerInstanceName,
+		applyoperatorv1.OperatorStatus().WithConditions(upgradeable),
+	); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// syncCRD takes the next step of the rollout of the required CRD. It returns a description of the step in flight,
+// or an empty string when the CRD is not in transition.
+func (c *CRDLifecycleController) syncCRD(ctx context.Context, recorder events.Recorder, required *apiextensionsv1.CustomResourceDefinition) (string, error) {
+	existing, err := c.crdClient.CustomResourceDefinitions().Get(ctx, required.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, _, err := resourceapply.ApplyCustomResourceDefinitionV1(ctx, c.crdClient, recorder, required)
+		return "", err
+	}
+	if err != nil {
+		return "", err
+	}
+
+	requiredStorage := storageVersion(required)
+	if len(requiredStorage) == 0 {
+		return "", fmt.Errorf("required CRD has no storage version")
+	}
+	existingStorage := storageVersion(existing)
+
+	if len(existingStorage) > 0 && !isServed(existing, requiredStorage) {
+		step := fmt.Sprintf("serving version %s", requiredStorage)
+		_, _, err := resourceapply.ApplyCustomResourceDefinitionV1(ctx, c.crdClient, recorder, transitionalCRD(required, existing, existingStorage))
+		return step, err
+	}
+
+	if len(existingStorage) > 0 && existingStorage != requiredStorage {
+		step := fmt.Sprintf("switching storage version from %s to %s", existingStorage, requiredStorage)
+		_, _, err := resourceapply.ApplyCustomResourceDefinitionV1(ctx, c.crdClient, recorder, transitionalCRD(required, existing, requiredStorage))
+		return step, err
+	}
+
+	if storesOtherVersions(existing, requiredStorage) {
+		step := fmt.Sprintf("migrating stored objects from %s to %s", strings.Join(existing.Status.StoredVersions, ","), requiredStorage)
+		migrated, err := c.ensureMigration(ctx, recorder, existing, requiredStorage)
+		if err != nil || !migrated {
+			return step, err
+		}
+
+		klog.V(2).Infof("CRD %s migrated to %s, dropping stored versions %v", existing.Name, requiredStorage, existing.Status.StoredVersions)
+		existingCopy := existing.DeepCopy()
+		existingCopy.Status.StoredVersions = []string{requiredStorage}
+		if _, err := c.crdClient.CustomResourceDefinitions().UpdateStatus(ctx, existingCopy, metav1.UpdateOptions{}); err != nil {
+			return step, err
+		}
+		recorder.Eventf("CRDStoredVersionsUpdated", "Stored versions of CRD %s reduced to %s", existing.Name, requiredStorage)
+		// a later transition to the same version must not find the finished migration
+		_, _, err = resourceapply.DeleteStorageVersionMigration(ctx, c.migrationClient, recorder, migrationFor(existing, requiredStorage))
+		return step, err
+	}
+
+	_, _, err = resourceapply.ApplyCustomResourceDefinitionV1(ctx, c.crdClient, recorder, required)
+	return "", err
+}
+
+// ensureMigration creates the migration of the CRD resource to the version and returns whether it succeeded.
+// Failed migrations are deleted, so they are restarted on the next sync.
+func (c *CRDLifecycleController) ensureMigration(ctx context.Context, recorder events.Recorder, crd *apiextensionsv1.CustomResourceDefinition, version string) (bool, error) {
+	migration, _, err := resourceapply.ApplyStorageVersionMigration(ctx, c.migrationClient, recorder, migrationFor(crd, version))
+	if err != nil {
+		return false, err
+	}
+
+	for _, condition := range migration.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case migrationv1alpha1.MigrationSucceeded:
+			return true, nil
+		case migrationv1alpha1.MigrationFailed:
+			recorder.Warningf("CRDMigrationFailed", "Migration of CRD %s to %s failed, restarting: %s", crd.Name, version, condition.Message)
+			if _, _, err := resourceapply.DeleteStorageVersionMigration(ctx, c.migrationClient, recorder, migration); err != nil {
+				return false, err
+			}
+			return false, fmt.Errorf("migration %s failed: %s", migration.Name, condition.Message)
+		}
+	}
+	return false, nil
+}
+
+func migrationFor(crd *apiextensionsv1.CustomResourceDefinition, version string) *migrationv1alpha1.StorageVersionMigration {
+	return &migrationv1alpha1.StorageVersionMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%s", crd.Name, version)},
+		Spec: migrationv1alpha1.StorageVersionMigrationSpec{
+			Resource: migrationv1alpha1.GroupVersionResource{
+				Group:    crd.Spec.Group,
+				Version:  version,
+				Resource: crd.Spec.Names.Plural,
+			},
+		},
+	}
+}
+
+// transitionalCRD returns the required CRD extended by the versions of the existing CRD that are going away, and
+// with the storage flag on the given version.
+func transitionalCRD(required, existing *apiextensionsv1.CustomResourceDefinition, storage string) *apiextensionsv1.CustomResourceDefinition {
+	ret := required.DeepCopy()
+	for _, version := range existing.Spec.Versions {
+		if !hasVersion(ret, version.Name) {
+			ret.Spec.Versions = append(ret.Spec.Versions, *version.DeepCopy())
+		}
+	}
+	for i := range ret.Spec.Versions {
+		version := &ret.Spec.Versions[i]
+		version.Storage = version.Name == storage
+		if version.Storage {
+			version.Served = true
+		}
+	}
+	return ret
+}
+
+func storageVersion(crd *apiextensionsv1.CustomResourceDefinition) string {
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			return version.Name
+		}
+	}
+	return ""
+}
+
+func hasVersion(crd *apiextensionsv1.CustomResourceDefinition, name string) bool {
+	for _, version := range crd.Spec.Versions {
+		if version.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isServed(crd *apiextensionsv1.CustomResourceDefinition, name string) bool {
+	for _, version := range crd.Spec.Versions {
+		if version.Name == name {
+			return version.Served
+		}
+	}
+	return false
+}
+
+func storesOtherVersions(crd *apiextensionsv1.CustomResourceDefinition, storage string) bool {
+	for _, version := range crd.Status.StoredVersions {
+		if version != storage {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package crdlifecycle
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	migrationv1alpha1 "sigs.k8s.io/kube-storage-version-migrator/pkg/apis/migration/v1alpha1"
+	migrationfake "sigs.k8s.io/kube-storage-version-migrator/pkg/clients/clientset/fake"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func newCRD(storage string, versions ...string) *apiextensionsv1.CustomResourceDefinition {
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "widgets.example.com"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: "example.com",
+			Names: apiextensionsv1.CustomResourceDefinitionNames{Plural: "widgets", Kind: "Widget"},
+			Scope: apiextensionsv1.NamespaceScoped,
+		},
+	}
+	for _, version := range versions {
+		crd.Spec.Versions = append(crd.Spec.Versions, apiextensionsv1.CustomResourceDefinitionVersion{
+			Name:    version,
+			Served:  true,
+			Storage: version == storage,
+		})
+	}
+	return crd
+}
+
+func TestCRDLifecycleSync(t *testing.T) {
+	existing := newCRD("v1", "v1")
+	existing.Status.StoredVersions = []string{"v1"}
+	required := newCRD("v2", "v2")
+
+	crdClient := apiextensionsfake.NewSimpleClientset(existing)
+	migrationClient := migrationfake.NewSimpleClientset()
+	operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{ManagementState: operatorv1.Managed}, &operatorv1.OperatorStatus{}, nil)
+	c := &CRDLifecycleController{
+		controllerInstanceName: "test-CRDLifecycle",
+		conditionPrefix:        "TestCRDLifecycle",
+		crds:                   []*apiextensionsv1.CustomResourceDefinition{required},
+		operatorClient:         operatorClient,
+		crdClient:              crdClient.ApiextensionsV1(),
+		migrationClient:        migrationClient,
+	}
+	syncCtx := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+
+	sync := func() *apiextensionsv1.CustomResourceDefinition {
+		t.Helper()
+		if err := c.sync(context.TODO(), syncCtx); err != nil {
+			t.Fatal(err)
+		}
+		crd, err := crdClient.ApiextensionsV1().CustomResourceDefinitions().Get(context.TODO(), required.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return crd
+	}
+	expectUpgradeable := func(expected operatorv1.ConditionStatus) {
+		t.Helper()
+		_, status, _, _ := operatorClient.GetOperatorState()
+		if actual := v1helpers.FindOperatorCondition(status.Conditions, "TestCRDLifecycleUpgradeable"); actual == nil || actual.Status != expected {
+			t.Errorf("expected upgradeable %s, got %v", expected, actual)
+		}
+	}
+	expectVersions := func(crd *apiextensionsv1.CustomResourceDefinition, storage string, versions ...string) {
+		t.Helper()
+		if actual := storageVersion(crd); actual != storage {
+			t.Errorf("expected storage version %s, got %s", storage, actual)
+		}
+		if len(crd.Spec.Versions) != len(versions) {
+			t.Fatalf("expected versions %v, got %#v", versions, crd.Spec.Versions)
+		}
+		for _, version := range versions {
+			if !isServed(crd, version) {
+				t.Errorf("expected %s to be served", version)
+			}
+		}
+	}
+
+	// the new version is served first
+	crd := sync()
+	expectVersions(crd, "v1", "v2", "v1")
+	expectUpgradeable(operatorv1.ConditionFalse)
+
+	// then it becomes the storage version
+	crd = sync()
+	expectVersions(crd, "v2", "v2", "v1")
+	expectUpgradeable(operatorv1.ConditionFalse)
+
+	// the API server adds the new storage version to the stored versions
+	crd.Status.StoredVersions = []string{"v1", "v2"}
+	if _, err := crdClient.ApiextensionsV1().CustomResourceDefinitions().UpdateStatus(context.TODO(), crd, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the stored objects are migrated
+	crd = sync()
+	expectVersions(crd, "v2", "v2", "v1")
+	expectUpgradeable(operatorv1.ConditionFalse)
+	migration, err := migrationClient.MigrationV1alpha1().StorageVersionMigrations().Get(context.TODO(), "widgets.example.com-v2", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migration.Spec.Resource != (migrationv1alpha1.GroupVersionResource{Group: "example.com", Version: "v2", Resource: "widgets"}) {
+		t.Errorf("unexpected migration resource %#v", migration.Spec.Resource)
+	}
+	crd = sync()
+	if len(crd.Status.StoredVersions) != 2 {
+		t.Errorf("expected stored versions to be kept while migrating, got %v", crd.Status.StoredVersions)
+	}
+
+	migration.Status.Conditions = []migrationv1alpha1.MigrationCondition{{Type: migrationv1alpha1.MigrationSucceeded, Status: corev1.ConditionTrue}}
+	if _, err := migrationClient.MigrationV1alpha1().StorageVersionMigrations().Update(context.TODO(), migration, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// old versions are dropped from the stored versions, the migration is removed
+	crd = sync()
+	if len(crd.Status.StoredVersions) != 1 || crd.Status.StoredVersions[0] != "v2" {
+		t.Errorf("expected stored versions [v2], got %v", crd.Status.StoredVersions)
+	}
+	if _, err := migrationClient.MigrationV1alpha1().StorageVersionMigrations().Get(context.TODO(), migration.Name, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the migration to be removed, got %v", err)
+	}
+
+	// and finally the old version is removed
+	crd = sync()
+	expectVersions(crd, "v2", "v2")
+	expectUpgradeable(operatorv1.ConditionTrue)
+}
+
+func TestTransitionalCRD(t *testing.T) {
+	existing := newCRD("v1", "v1", "v1beta1")
+	existing.Spec.Versions[1].Served = false
+	required := newCRD("v2", "v2")
+
+	actual := transitionalCRD(required, existing, "v1")
+	if len(actual.Spec.Versions) != 3 {
+		t.Fatalf("expected three versions, got %#v", actual.Spec.Versions)
+	}
+	if storageVersion(actual) != "v1" {
+		t.Errorf("expected v1 to stay the storage version")
+	}
+	if isServed(actual, "v1beta1") {
+		t.Errorf("expected v1beta1 to stay unserved")
+	}
+	if len(required.Spec.Versions) != 1 {
+		t.Errorf("required CRD was mutated")
+	}
+}
@@ -0,0 +1,167 @@
+// Package credentialsrequest manages the CredentialsRequest of an operator and checks the secret minted for it by the
+// cloud-credential-operator, or created by cluster admins in manual credentials mode.
+package credentialsrequest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	opv1 "github.com/openshift/api/operator/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/management"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+const infraConfigName = "cluster"
+
+// CredentialsRequestController applies a CredentialsRequest, waits for its secret and validates the secret for the
+// platform of the cluster. It produces the following conditions:
+// <name>CredentialsProgressing: the secret is yet to be provisioned.
+// <name>CredentialsDegraded: the secret is missing although the CredentialsRequest was provisioned, or is malformed.
+type CredentialsRequestController struct {
+	name                   string
+	controllerInstanceName string
+	manifest               []byte
+	secretNamespace        string
+	secretName             string
+
+	operatorClient v1helpers.OperatorClient
+	dynamicClient  dynamic.Interface
+	secretLister   corev1listers.SecretLister
+	infraLister    configv1listers.InfrastructureLister
+}
+
+// NewCredentialsRequestController returns a controller managing the CredentialsRequest in the manifest. The informers
+// must contain the namespace of the secret referenced by the CredentialsRequest.
+func NewCredentialsRequestController(
+	name string,
+	manifest []byte,
+	operatorClient v1helpers.OperatorClient,
+	dynamicClient dynamic.Interface,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	infraInformer configv1informers.InfrastructureInformer,
+	recorder events.Recorder,
+) factory.Controller {
+	cr := resourceread.ReadCredentialRequestsOrDie(manifest)
+	secretNamespace, _, _ := unstructured.NestedString(cr.Object, "spec", "secretRef", "namespace")
+	secretName, _, _ := unstructured.NestedString(cr.Object, "spec", "secretRef", "name")
+	if len(secretNamespace) == 0 || len(secretName) == 0 {
+		panic(fmt.Sprintf("CredentialsRequest %s/%s has no spec.secretRef", cr.GetNamespace(), cr.GetName()))
+	}
+	secrets := kubeInformersForNamespaces.InformersFor(secretNamespace).Core().V1().Secrets()
+
+	c := &CredentialsRequestController{
+		name:                   name,
+		controllerInstanceName: factory.ControllerInstanceName(name, "CredentialsRequest"),
+		manifest:               manifest,
+		secretNamespace:        secretNamespace,
+		secretName:             secretName,
+		operatorClient:         operatorClient,
+		dynamicClient:          dynamicClient,
+		secretLister:           secrets.Lister(),
+		infraLister:            infraInformer.Lister(),
+	}
+
+	return factory.New().
+		WithInformers(operatorClient.Informer(), infraInformer.Informer()).
+		WithFilteredEventsInformers(factory.NamesFilter(secretName), secrets.Informer()).
+		WithSync(c.sync).
+		WithSyncDegradedOnError(operatorClient).
+		ResyncEvery(time.Minute).
+		ToController(c.controllerInstanceName, recorder.WithComponentSuffix("credentials-request-controller-"+strings.ToLower(name)))
+}
+
+func (c *CredentialsRequestController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	operatorSpec, operatorStatus, _, err := c.operatorClient.GetOperatorState()
+	if err != nil {
+		return err
+	}
+	if !management.IsOperatorManaged(operatorSpec.ManagementState) {
+		return nil
+	}
+
+	required := resourceread.ReadCredentialRequestsOrDie(c.manifest)
+	var expectedGeneration int64 = -1
+	if generation := resourcemerge.GenerationFor(
+		operatorStatus.Generations,
+		schema.GroupResource{Group: resourceapply.CredentialsRequestGroup, Resource: resourceapply.CredentialsRequestResource},
+		required.GetNamespace(),
+		required.GetName(),
+	); generation != nil {
+		expectedGeneration = generation.LastGeneration
+	}
+	cr, _, err := resourceapply.ApplyCredentialsRequest(ctx, c.dynamicClient, syncCtx.Recorder(), required, expectedGeneration)
+	if err != nil {
+		return err
+	}
+	provisioned, _, err := unstructured.NestedBool(cr.Object, "status", "provisioned")
+	if err != nil {
+		return fmt.Errorf("invalid status.provisioned field in CredentialsRequest %s/%s: %w", cr.GetNamespace(), cr.GetName(), err)
+	}
+
+	infra, err := c.infraLister.Get(infraConfigName)
+	if err != nil {
+		return err
+	}
+	platform := infra.Status.Platform
+	if infra.Status.PlatformStatus != nil && len(infra.Status.PlatformStatus.Type) > 0 {
+		platform = infra.Status.PlatformStatus.Type
+	}
+
+	degraded := applyoperatorv1.OperatorCondition().
+		WithType(c.name + "Credentials" + opv1.OperatorStatusTypeDegraded).
+		WithStatus(opv1.ConditionFalse)
+	progressing := applyoperatorv1.OperatorCondition().
+		WithType(c.name + "Credentials" + opv1.OperatorStatusTypeProgressing).
+		WithStatus(opv1.ConditionFalse)
+
+	secret, err := c.secretLister.Secrets(c.secretNamespace).Get(c.secretName)
+	switch {
+	case apierrors.IsNotFound(err) && !provisioned:
+		progressing = progressing.
+			WithStatus(opv1.ConditionTrue).
+			WithReason("CredentialsNotProvisionedYet").
+			WithMessage(fmt.Sprintf("Waiting for the cloud-credential-operator to provision secret %s/%s for CredentialsRequest %s/%s. In manual credentials mode, the secret must be created by the cluster admin, e.g. with ccoctl.", c.secretNamespace, c.secretName, cr.GetNamespace(), cr.GetName()))
+	case apierrors.IsNotFound(err):
+		degraded = degraded.
+			WithStatus(opv1.ConditionTrue).
+			WithReason("CredentialsMissing").
+			WithMessage(fmt.Sprintf("CredentialsRequest %s/%s is provisioned, but secret %s/%s does not exist. Check the status of the CredentialsRequest and the logs of the cloud-credential-operator.", cr.GetNamespace(), cr.GetName(), c.secretNamespace, c.secretName))
+	case err != nil:
+		return err
+	default:
+		if err := ValidateSecret(platform, secret); err != nil {
+			degraded = degraded.
+				WithStatus(opv1.ConditionTrue).
+				WithReason("CredentialsMalformed").
+				WithMessage(fmt.Sprintf("%v. Recreate the secret with the credentials of the %s platform, or delete it to have it minted again.", err, platform))
+		}
+	}
+
+	status := applyoperatorv1.OperatorStatus().
+		WithConditions(degraded, progressing).
+		WithGenerations(&applyoperatorv1.GenerationStatusApplyConfiguration{
+			Group:          ptr.To(resourceapply.CredentialsRequestGroup),
+			Resource:       ptr.To(resourceapply.CredentialsRequestResource),
+			Namespace:      ptr.To(cr.GetNamespace()),
+			Name:           ptr.To(cr.GetName()),
+			LastGeneration: ptr.To(cr.GetGeneration()),
+		})
+	return c.operatorClient.ApplyOperatorStatus(ctx, c.controllerInstanceName, status)
+}
@@ -0,0 +1,148 @@
+package credentialsrequest
+
+import (
+	"context"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	opv1 "github.com/openshift/api/operator/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+const manifest = `apiVersion: cloudcredential.openshift.io/v1
+kind: CredentialsRequest
+metadata:
+  name: operand
+  namespace: openshift-cloud-credential-operator
+spec:
+  secretRef:
+    name: cloud-credentials
+    namespace: operand
+`
+
+func TestValidateSecret(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		platform    configv1.PlatformType
+		data        map[string]string
+		expectError bool
+	}{
+		{name: "AWS keys", platform: configv1.AWSPlatformType, data: map[string]string{"aws_access_key_id": "id", "aws_secret_access_key": "key"}},
+		{name: "AWS STS", platform: configv1.AWSPlatformType, data: map[string]string{"credentials": "[default]"}},
+		{name: "AWS missing key", platform: configv1.AWSPlatformType, data: map[string]string{"aws_access_key_id": "id"}, expectError: true},
+		{
+			name:     "Azure workload identity",
+			platform: configv1.AzurePlatformType,
+			data:     map[string]string{"azure_client_id": "a", "azure_tenant_id": "b", "azure_subscription_id": "c", "azure_region": "d", "azure_federated_token_file": "e"},
+		},
+		{
+			name:        "Azure without secret",
+			platform:    configv1.AzurePlatformType,
+			data:        map[string]string{"azure_client_id": "a", "azure_tenant_id": "b", "azure_subscription_id": "c", "azure_region": "d"},
+			expectError: true,
+		},
+		{name: "GCP", platform: configv1.GCPPlatformType, data: map[string]string{"service_account.json": `{"type": "service_account"}`}},
+		{name: "GCP invalid JSON", platform: configv1.GCPPlatformType, data: map[string]string{"service_account.json": `{`}, expectError: true},
+		{name: "vSphere", platform: configv1.VSpherePlatformType, data: map[string]string{"vcenter.example.com.username": "u", "vcenter.example.com.password": "p"}},
+		{name: "vSphere missing password", platform: configv1.VSpherePlatformType, data: map[string]string{"vcenter.example.com.username": "u"}, expectError: true},
+		{name: "other platform", platform: configv1.BareMetalPlatformType, data: map[string]string{"token": "t"}},
+		{name: "other platform empty", platform: configv1.BareMetalPlatformType, expectError: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "operand", Name: "cloud-credentials"}}
+			secret.Data = map[string][]byte{}
+			for k, v := range tc.data {
+				secret.Data[k] = []byte(v)
+			}
+			if err := ValidateSecret(tc.platform, secret); (err != nil) != tc.expectError {
+				t.Errorf("expected error: %v, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestCredentialsRequestController(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "cloudcredential.openshift.io", Version: "v1", Resource: "credentialsrequests"}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "CredentialsRequestList"})
+	kubeClient := fake.NewSimpleClientset()
+	kubeInformers := v1helpers.NewKubeInformersForNamespaces(kubeClient, "operand")
+	secretIndexer := kubeInformers.InformersFor("operand").Core().V1().Secrets().Informer().GetIndexer()
+	infraIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := infraIndexer.Add(&configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status:     configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	operatorClient := v1helpers.NewFakeOperatorClient(&opv1.OperatorSpec{ManagementState: opv1.Managed}, &opv1.OperatorStatus{}, nil)
+
+	c := &CredentialsRequestController{
+		name:                   "Operand",
+		controllerInstanceName: "OperandCredentialsRequest",
+		manifest:               []byte(manifest),
+		secretNamespace:        "operand",
+		secretName:             "cloud-credentials",
+		operatorClient:         operatorClient,
+		dynamicClient:          dynamicClient,
+		secretLister:           kubeInformers.InformersFor("operand").Core().V1().Secrets().Lister(),
+		infraLister:            configv1listers.NewInfrastructureLister(infraIndexer),
+	}
+	syncContext := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+
+	expectConditions := func(degradedReason, progressingReason string) {
+		t.Helper()
+		if err := c.sync(context.TODO(), syncContext); err != nil {
+			t.Fatal(err)
+		}
+		_, status, _, _ := operatorClient.GetOperatorState()
+		if condition := v1helpers.FindOperatorCondition(status.Conditions, "OperandCredentialsDegraded"); condition.Reason != degradedReason {
+			t.Errorf("expected Degraded reason %q, got %#v", degradedReason, condition)
+		}
+		if condition := v1helpers.FindOperatorCondition(status.Conditions, "OperandCredentialsProgressing"); condition.Reason != progressingReason {
+			t.Errorf("expected Progressing reason %q, got %#v", progressingReason, condition)
+		}
+	}
+
+	// the CredentialsRequest is created and not provisioned yet
+	expectConditions("", "CredentialsNotProvisionedYet")
+	cr, err := dynamicClient.Resource(gvr).Namespace("openshift-cloud-credential-operator").Get(context.TODO(), "operand", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// provisioned, but the secret is gone
+	if err := unstructured.SetNestedField(cr.Object, true, "status", "provisioned"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dynamicClient.Resource(gvr).Namespace("openshift-cloud-credential-operator").Update(context.TODO(), cr, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	expectConditions("CredentialsMissing", "")
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "operand", Name: "cloud-credentials"},
+		Data:       map[string][]byte{"aws_access_key_id": []byte("id")},
+	}
+	if err := secretIndexer.Add(secret); err != nil {
+		t.Fatal(err)
+	}
+	expectConditions("CredentialsMalformed", "")
+
+	secret.Data["aws_secret_access_key"] = []byte("key")
+	if err := secretIndexer.Update(secret); err != nil {
+		t.Fatal(err)
+	}
+	expectConditions("", "")
+}
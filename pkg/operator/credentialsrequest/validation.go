@@ -0,0 +1,103 @@
+package credentialsrequest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ValidateSecret checks that the secret minted for a CredentialsRequest has the keys the credentials of the platform
+// are read from. The returned error names the missing or malformed keys.
+func ValidateSecret(platform configv1.PlatformType, secret *corev1.Secret) error {
+	switch platform {
+	case configv1.AWSPlatformType:
+		// long-lived keys, or a credentials file with a role to assume via STS
+		if hasKeys(secret, "credentials") {
+			return nil
+		}
+		return requireKeys(platform, secret, "aws_access_key_id", "aws_secret_access_key")
+	case configv1.AzurePlatformType:
+		if err := requireKeys(platform, secret, "azure_client_id", "azure_tenant_id", "azure_subscription_id", "azure_region"); err != nil {
+			return err
+		}
+		// a client secret, or a federated token with workload identity
+		if !hasKeys(secret, "azure_client_secret") && !hasKeys(secret, "azure_federated_token_file") {
+			return fmt.Errorf("secret %s/%s needs either azure_client_secret or azure_federated_token_file on %s", secret.Namespace, secret.Name, platform)
+		}
+		return nil
+	case configv1.GCPPlatformType:
+		if err := requireKeys(platform, secret, "service_account.json"); err != nil {
+			return err
+		}
+		if !json.Valid(secret.Data["service_account.json"]) {
+			return fmt.Errorf("secret %s/%s: service_account.json is not valid JSON", secret.Namespace, secret.Name)
+		}
+		return nil
+	case configv1.IBMCloudPlatformType, configv1.PowerVSPlatformType:
+		return requireKeys(platform, secret, "ibmcloud_api_key")
+	case configv1.OpenStackPlatformType:
+		return requireKeys(platform, secret, "clouds.yaml")
+	case configv1.NutanixPlatformType:
+		return requireKeys(platform, secret, "credentials")
+	case configv1.VSpherePlatformType:
+		return validateVSphereSecret(secret)
+	default:
+		for _, value := range secret.Data {
+			if len(value) > 0 {
+				return nil
+			}
+		}
+		return fmt.Errorf("secret %s/%s is empty", secret.Namespace, secret.Name)
+	}
+}
+
+// validateVSphereSecret requires the <vCenter>.username and <vCenter>.password keys of at least one vCenter.
+func validateVSphereSecret(secret *corev1.Secret) error {
+	var servers []string
+	var incomplete []string
+	for key := range secret.Data {
+		server, found := strings.CutSuffix(key, ".username")
+		if !found {
+			continue
+		}
+		if hasKeys(secret, key, server+".password") {
+			servers = append(servers, server)
+		} else {
+			incomplete = append(incomplete, server)
+		}
+	}
+	if len(incomplete) > 0 {
+		sort.Strings(incomplete)
+		return fmt.Errorf("secret %s/%s is missing the username or password of vCenters %v", secret.Namespace, secret.Name, incomplete)
+	}
+	if len(servers) == 0 {
+		return fmt.Errorf("secret %s/%s has no <vCenter>.username and <vCenter>.password keys", secret.Namespace, secret.Name)
+	}
+	return nil
+}
+
+func requireKeys(platform configv1.PlatformType, secret *corev1.Secret, keys ...string) error {
+	var missing []string
+	for _, key := range keys {
+		if !hasKeys(secret, key) {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("secret %s/%s is missing keys %v required on %s", secret.Namespace, secret.Name, missing, platform)
+	}
+	return nil
+}
+
+func hasKeys(secret *corev1.Secret, keys ...string) bool {
+	for _, key := range keys {
+		if len(secret.Data[key]) == 0 {
+			return false
+		}
+	}
+	return true
+}
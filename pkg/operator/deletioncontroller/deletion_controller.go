@@ -0,0 +1,180 @@
+// Package deletioncontroller tears down the resources managed by an operator when its operator CR is deleted, before
+// the finalizer of the CR is removed.
+package deletioncontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	opv1 "github.com/openshift/api/operator/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/management"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// recheckInterval is how often the controller checks whether the resources of the current stage are gone.
+const recheckInterval = 5 * time.Second
+
+// Resource references a resource managed by the operator. Namespace is empty for cluster-scoped resources.
+type Resource struct {
+	Resource  schema.GroupVersionResource
+	Namespace string
+	Name      string
+}
+
+func (r Resource) String() string {
+	if len(r.Namespace) == 0 {
+		return fmt.Sprintf("%s %s", r.Resource.GroupResource(), r.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", r.Resource.GroupResource(), r.Namespace, r.Name)
+}
+
+// Stage is a set of resources deleted together.
+type Stage struct {
+	Name      string
+	Resources []Resource
+}
+
+// DeletionController adds a finalizer to the operator CR. Once the CR is marked for deletion, it deletes the stages in
+// reverse order, waiting for all resources of a stage to be gone before deleting the previous one, and removes the
+// finalizer afterwards. It produces the following conditions:
+// <name>-DeletionProgressing: the resources of a stage are being deleted.
+// <name>-DeletionDegraded: a resource could not be deleted.
+type DeletionController struct {
+	controllerInstanceName string
+	progressingType        string
+	stages                 []Stage
+
+	operatorClient v1helpers.OperatorClientWithFinalizers
+	dynamicClient  dynamic.Interface
+}
+
+// NewDeletionController returns a controller deleting the resources of the stages when the operator CR is deleted. The
+// stages are listed in dependency order, i.e. the order the resources are created in, e.g. a namespace before the
+// deployment in it.
+func NewDeletionController(
+	instanceName string,
+	stages []Stage,
+	operatorClient v1helpers.OperatorClientWithFinalizers,
+	dynamicClient dynamic.Interface,
+	recorder events.Recorder,
+) factory.Controller {
+	c := &DeletionController{
+		controllerInstanceName: factory.ControllerInstanceName(instanceName, "Deletion"),
+		progressingType:        factory.ControllerInstanceName(instanceName, "Deletion") + opv1.OperatorStatusTypeProgressing,
+		stages:                 stages,
+		operatorClient:         operatorClient,
+		dynamicClient:          dynamicClient,
+	}
+
+	return factory.New().
+		WithInformers(operatorClient.Informer()).
+		WithSync(c.sync).
+		WithSyncDegradedOnError(operatorClient).
+		ResyncEvery(time.Minute).
+		ToController(c.controllerInstanceName, recorder.WithComponentSuffix("deletion-controller"))
+}
+
+func (c *DeletionController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	operatorSpec, _, _, err := c.operatorClient.GetOperatorState()
+	if err != nil {
+		return err
+	}
+	if !management.IsOperatorRemovable() {
+		return nil
+	}
+	meta, err := c.operatorClient.GetObjectMeta()
+	if err != nil {
+		return err
+	}
+
+	if meta.DeletionTimestamp == nil {
+		if !management.IsOperatorManaged(operatorSpec.ManagementState) {
+			return nil
+		}
+		return v1helpers.EnsureFinalizer(ctx, c.operatorClient, c.controllerInstanceName)
+	}
+	return c.syncDeleting(ctx, syncCtx)
+}
+
+func (c *DeletionController) syncDeleting(ctx context.Context, syncCtx factory.SyncContext) error {
+	for i := len(c.stages) - 1; i >= 0; i-- {
+		stage := c.stages[i]
+		pending, err := c.deleteStage(ctx, syncCtx.Recorder(), stage)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			continue
+		}
+
+		progressing := applyoperatorv1.OperatorCondition().
+			WithType(c.progressingType).
+			WithStatus(opv1.ConditionTrue).
+			WithReason("WaitingForDeletion").
+			WithMessage(fmt.Sprintf("Waiting for deletion of stage %q (%d of %d remaining): %s", stage.Name, i+1, len(c.stages), strings.Join(pending, ", ")))
+		if err := c.operatorClient.ApplyOperatorStatus(ctx, c.controllerInstanceName, applyoperatorv1.OperatorStatus().WithConditions(progressing)); err != nil {
+			return err
+		}
+		syncCtx.Queue().AddAfter(syncCtx.QueueKey(), recheckInterval)
+		return nil
+	}
+
+	progressing := applyoperatorv1.OperatorCondition().
+		WithType(c.progressingType).
+		WithStatus(opv1.ConditionFalse).
+		WithReason("Deleted")
+	if err := c.operatorClient.ApplyOperatorStatus(ctx, c.controllerInstanceName, applyoperatorv1.OperatorStatus().WithConditions(progressing)); err != nil {
+		return err
+	}
+	klog.V(2).Infof("All resources of %s are deleted, removing the finalizer", c.controllerInstanceName)
+	return v1helpers.RemoveFinalizer(ctx, c.operatorClient, c.controllerInstanceName)
+}
+
+// deleteStage issues the deletion of all resources of the stage and returns the resources that still exist.
+func (c *DeletionController) deleteStage(ctx context.Context, recorder events.Recorder, stage Stage) ([]string, error) {
+	var pending []string
+	var errs []error
+	for _, resource := range stage.Resources {
+		client := c.dynamicClient.Resource(resource.Resource).Namespace(resource.Namespace)
+		obj, err := client.Get(ctx, resource.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get %s: %w", resource, err))
+			continue
+		}
+		pending = append(pending, resource.String())
+		if obj.GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		err = client.Delete(ctx, resource.Name, metav1.DeleteOptions{})
+		if apierrors.IsNotFound(err) {
+			pending = pending[:len(pending)-1]
+			continue
+		}
+		if err != nil {
+			recorder.Warningf("DeleteFailed", "Failed to delete %s: %v", resource, err)
+			errs = append(errs, fmt.Errorf("failed to delete %s: %w", resource, err))
+			continue
+		}
+		recorder.Eventf("Deleted", "Deleted %s", resource)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("stage %q: %w", stage.Name, utilerrors.NewAggregate(errs))
+	}
+	return pending, nil
+}
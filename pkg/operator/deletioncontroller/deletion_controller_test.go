@@ -0,0 +1,128 @@
+package deletioncontroller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	opv1 "github.com/openshift/api/operator/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+var (
+	namespacesGVR  = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	configMapsGVR  = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	deploymentsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+)
+
+func newObject(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func TestDeletionController(t *testing.T) {
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		namespacesGVR:  "NamespaceList",
+		configMapsGVR:  "ConfigMapList",
+		deploymentsGVR: "DeploymentList",
+	},
+		newObject("v1", "Namespace", "", "operand"),
+		newObject("v1", "ConfigMap", "operand", "config"),
+		newObject("apps/v1", "Deployment", "operand", "operand"),
+	)
+	// the namespace is terminating until released by the test
+	namespaceTerminating := true
+	dynamicClient.PrependReactor("delete", "namespaces", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return namespaceTerminating, nil, nil
+	})
+
+	operatorClient := v1helpers.NewFakeOperatorClientWithObjectMeta(&metav1.ObjectMeta{Name: "cluster"}, &opv1.OperatorSpec{ManagementState: opv1.Managed}, &opv1.OperatorStatus{}, nil)
+	c := &DeletionController{
+		controllerInstanceName: "Operand-Deletion",
+		progressingType:        "Operand-DeletionProgressing",
+		stages: []Stage{
+			{Name: "namespace", Resources: []Resource{{Resource: namespacesGVR, Name: "operand"}}},
+			{Name: "operand", Resources: []Resource{
+				{Resource: configMapsGVR, Namespace: "operand", Name: "config"},
+				{Resource: deploymentsGVR, Namespace: "operand", Name: "operand"},
+			}},
+		},
+		operatorClient: operatorClient,
+		dynamicClient:  dynamicClient,
+	}
+	syncContext := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+
+	sync := func() {
+		t.Helper()
+		if err := c.sync(context.TODO(), syncContext); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectProgressing := func(status opv1.ConditionStatus, messageContains string) {
+		t.Helper()
+		_, operatorStatus, _, _ := operatorClient.GetOperatorState()
+		condition := v1helpers.FindOperatorCondition(operatorStatus.Conditions, "Operand-DeletionProgressing")
+		if condition == nil || condition.Status != status || !strings.Contains(condition.Message, messageContains) {
+			t.Errorf("expected Progressing %s with message containing %q, got %#v", status, messageContains, condition)
+		}
+	}
+	finalizers := func() []string {
+		meta, _ := operatorClient.GetObjectMeta()
+		return meta.Finalizers
+	}
+
+	// the finalizer is added while the operator CR is live, nothing is deleted
+	sync()
+	if got := finalizers(); len(got) != 1 || !strings.HasSuffix(got[0], "/Operand-Deletion") {
+		t.Fatalf("expected the finalizer to be added, got %v", got)
+	}
+	for _, action := range dynamicClient.Actions() {
+		if action.GetVerb() == "delete" {
+			t.Fatalf("unexpected deletion %v", action)
+		}
+	}
+
+	meta, _ := operatorClient.GetObjectMeta()
+	meta.DeletionTimestamp = &metav1.Time{}
+
+	// the operand stage is deleted first, the namespace once the operand is gone
+	sync()
+	expectProgressing(opv1.ConditionTrue, "configmaps operand/config, deployments.apps operand/operand")
+	sync()
+	expectProgressing(opv1.ConditionTrue, "namespaces operand")
+	var deleted []string
+	for _, action := range dynamicClient.Actions() {
+		if action.GetVerb() == "delete" {
+			deleted = append(deleted, action.GetResource().Resource)
+		}
+	}
+	if strings.Join(deleted, ",") != "configmaps,deployments,namespaces" {
+		t.Errorf("unexpected deletion order %v", deleted)
+	}
+	if len(finalizers()) != 1 {
+		t.Errorf("expected the finalizer to be kept while the namespace is terminating")
+	}
+
+	// the namespace is gone
+	namespaceTerminating = false
+	sync()
+	expectProgressing(opv1.ConditionTrue, "namespaces operand")
+	sync()
+	expectProgressing(opv1.ConditionFalse, "")
+	if got := finalizers(); len(got) != 0 {
+		t.Errorf("expected the finalizer to be removed, got %v", got)
+	}
+}
@@ -68,6 +68,8 @@ type DeploymentController struct {
 	// fails indicating the ordinal position of the failed function.
 	// Also, in that scenario the Degraded status is set to True.
 	optionalDeploymentHooks []DeploymentHookFunc
+	// recreatePolicy tells what to do when the manifest changes the selector of the Deployment.
+	recreatePolicy resourceapply.RecreatePolicy
 	// errors contains any errors that occur during the configuration
 	// and setup of the DeploymentController.
 	errors []error
@@ -155,6 +157,13 @@ func (c *DeploymentController) WithDeploymentHooks(hooks ...DeploymentHookFunc)
 	return c
 }
 
+// WithRecreatePolicy recreates the Deployment according to the policy when the manifest changes its selector, which
+// cannot be updated. Without it, the updates of the Deployment fail until the selector is reverted.
+func (c *DeploymentController) WithRecreatePolicy(policy resourceapply.RecreatePolicy) *DeploymentController {
+	c.recreatePolicy = policy
+	return c
+}
+
 // WithConditions sets the operational conditions under which the DeploymentController will operate.
 // Only 'Available', 'Progressing' and 'Degraded' are valid conditions; other values are ignored.
 func (c *DeploymentController) WithConditions(conditions ...string) *DeploymentController {
@@ -246,12 +255,13 @@ func (c *DeploymentController) syncManaged(ctx context.Context, opSpec *opv1.Ope
 		return err
 	}
 
-	deployment, _, err := resourceapply.ApplyDeployment(
+	deployment, _, err := resourceapply.ApplyDeploymentWithRecreate(
 		ctx,
 		c.kubeClient.AppsV1(),
 		syncContext.Recorder(),
 		required,
 		resourcemerge.ExpectedDeploymentGeneration(required, opStatus.Generations),
+		c.recreatePolicy,
 	)
 	if err != nil {
 		return err
@@ -293,6 +303,12 @@ func (c *DeploymentController) syncManaged(ctx context.Context, opSpec *opv1.Ope
 				WithMessage(msg).
 				WithReason("Deploying")
 		}
+		if resourceapply.IsRecreating(deployment) {
+			progressingCondition = progressingCondition.
+				WithStatus(opv1.ConditionTrue).
+				WithMessage("Deployment was recreated because its selector changed, keeping the pods of the previous Deployment until it is available").
+				WithReason("Recreating")
+		}
 		status = status.WithConditions(progressingCondition)
 	}
 
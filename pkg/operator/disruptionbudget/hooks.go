@@ -0,0 +1,25 @@
+package disruptionbudget
+
+import (
+	opv1 "github.com/openshift/api/operator/v1"
+	appsv1 "k8s.io/api/apps/v1"
+
+	dc "github.com/openshift/library-go/pkg/operator/deploymentcontroller"
+)
+
+// WithDeploymentHook sets the replicas and the rollout strategy of the plan of the role in the current topology on the
+// deployment. The PodDisruptionBudget of the plan is applied separately, see Plan.PodDisruptionBudgetFor.
+func WithDeploymentHook(topology TopologyFunc, role Role) dc.DeploymentHookFunc {
+	return func(_ *opv1.OperatorSpec, deployment *appsv1.Deployment) error {
+		t, err := topology()
+		if err != nil {
+			return err
+		}
+		plan, err := PlanFor(t, role)
+		if err != nil {
+			return err
+		}
+		plan.Apply(deployment)
+		return nil
+	}
+}
@@ -0,0 +1,165 @@
+// Package disruptionbudget recommends the replicas, pod disruption budget and rollout strategy of an operand from the
+// topology of the cluster and the role of the operand, so that the operands survive node drains and rollouts the same
+// way across the platform.
+package disruptionbudget
+
+import (
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	masterNodeRoleLabel = "node-role.kubernetes.io/master"
+	workerNodeRoleLabel = "node-role.kubernetes.io/worker"
+
+	infraConfigName = "cluster"
+)
+
+// Topology is the shape of the cluster that matters to the availability of the operands.
+type Topology string
+
+const (
+	// SingleNodeTopology is a single node running everything, nothing survives its drain.
+	SingleNodeTopology Topology = "SingleNode"
+	// CompactTopology is three control plane nodes running the workloads too.
+	CompactTopology Topology = "Compact"
+	// HighlyAvailableTopology is three control plane nodes and dedicated workers.
+	HighlyAvailableTopology Topology = "HighlyAvailable"
+)
+
+// TopologyFunc returns the current topology.
+type TopologyFunc func() (Topology, error)
+
+// TopologyFor returns the topology of the cluster from its Infrastructure and its worker nodes. With an external
+// control plane the topology is the one of the workers. Compact clusters have no worker which is not a control plane
+// node.
+func TopologyFor(infra *configv1.Infrastructure, workerNodes []*corev1.Node) Topology {
+	switch {
+	case infra.Status.ControlPlaneTopology == configv1.SingleReplicaTopologyMode:
+		return SingleNodeTopology
+	case infra.Status.ControlPlaneTopology == configv1.ExternalTopologyMode:
+		if infra.Status.InfrastructureTopology == configv1.SingleReplicaTopologyMode {
+			return SingleNodeTopology
+		}
+		return HighlyAvailableTopology
+	}
+	for _, node := range workerNodes {
+		if _, ok := node.Labels[masterNodeRoleLabel]; !ok {
+			return HighlyAvailableTopology
+		}
+	}
+	return CompactTopology
+}
+
+// ClusterTopology returns a TopologyFunc reading the topology from the informers.
+func ClusterTopology(infraLister configv1listers.InfrastructureLister, nodeLister corev1listers.NodeLister) TopologyFunc {
+	return func() (Topology, error) {
+		infra, err := infraLister.Get(infraConfigName)
+		if err != nil {
+			return "", err
+		}
+		nodes, err := nodeLister.List(labels.SelectorFromSet(labels.Set{workerNodeRoleLabel: ""}))
+		if err != nil {
+			return "", err
+		}
+		return TopologyFor(infra, nodes), nil
+	}
+}
+
+// Role is what an operand does, which tells how much of it must stay available.
+type Role string
+
+const (
+	// APIServerRole serves requests on every control plane node, with a required anti-affinity so that no two
+	// replicas run on the same node. One replica at a time may be unavailable.
+	APIServerRole Role = "APIServer"
+	// ControllerRole is leader elected, a second replica takes over quickly when the leader goes away.
+	ControllerRole Role = "Controller"
+	// WorkerRole serves requests from the workers, e.g. a webhook or a registry, and must keep serving during drains
+	// and rollouts.
+	WorkerRole Role = "Worker"
+	// SingletonRole must never run twice, e.g. it holds a lock outside of the cluster. It is unavailable during
+	// drains and rollouts.
+	SingletonRole Role = "Singleton"
+)
+
+// Plan is the recommended availability configuration of an operand.
+type Plan struct {
+	Replicas int32
+	// MaxUnavailable of the PodDisruptionBudget of the operand, nil when it must have none: a budget allowing no
+	// disruption of a single replica blocks the drain of its node.
+	MaxUnavailable *intstr.IntOrString
+	Strategy       appsv1.DeploymentStrategy
+}
+
+// PlanFor returns the plan of an operand with the role in the topology.
+func PlanFor(topology Topology, role Role) (Plan, error) {
+	switch topology {
+	case SingleNodeTopology, CompactTopology, HighlyAvailableTopology:
+	default:
+		return Plan{}, fmt.Errorf("unknown topology %q", topology)
+	}
+
+	switch role {
+	case SingletonRole:
+		return Plan{Replicas: 1, Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}}, nil
+	case APIServerRole:
+		// the anti-affinity leaves no node to surge to
+		if topology == SingleNodeTopology {
+			return Plan{Replicas: 1, Strategy: rollingUpdate(1, 0)}, nil
+		}
+		return Plan{Replicas: 3, MaxUnavailable: ptr.To(intstr.FromInt32(1)), Strategy: rollingUpdate(1, 0)}, nil
+	case ControllerRole, WorkerRole:
+		// the new replica is started before the old one goes away, on a single node too
+		if topology == SingleNodeTopology {
+			return Plan{Replicas: 1, Strategy: rollingUpdate(0, 1)}, nil
+		}
+		return Plan{Replicas: 2, MaxUnavailable: ptr.To(intstr.FromInt32(1)), Strategy: rollingUpdate(0, 1)}, nil
+	default:
+		return Plan{}, fmt.Errorf("unknown operand role %q", role)
+	}
+}
+
+// PodDisruptionBudgetFor returns the PodDisruptionBudget of the plan for the pods of the deployment, to apply with
+// resourceapply.ApplyPodDisruptionBudget, or nil when the plan has none and an existing one must be deleted. Unhealthy
+// pods can always be evicted, so a crashing replica does not block the drains.
+func (p Plan) PodDisruptionBudgetFor(deployment *appsv1.Deployment) *policyv1.PodDisruptionBudget {
+	if p.MaxUnavailable == nil {
+		return nil
+	}
+	return &policyv1.PodDisruptionBudget{
+		TypeMeta:   metav1.TypeMeta{APIVersion: policyv1.SchemeGroupVersion.String(), Kind: "PodDisruptionBudget"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: deployment.Namespace, Name: deployment.Name + "-pdb"},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable:             p.MaxUnavailable,
+			Selector:                   deployment.Spec.Selector.DeepCopy(),
+			UnhealthyPodEvictionPolicy: ptr.To(policyv1.AlwaysAllow),
+		},
+	}
+}
+
+// Apply sets the replicas and the strategy of the plan on the deployment.
+func (p Plan) Apply(deployment *appsv1.Deployment) {
+	deployment.Spec.Replicas = ptr.To(p.Replicas)
+	deployment.Spec.Strategy = *p.Strategy.DeepCopy()
+}
+
+func rollingUpdate(maxUnavailable, maxSurge int32) appsv1.DeploymentStrategy {
+	return appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxUnavailable: ptr.To(intstr.FromInt32(maxUnavailable)),
+			MaxSurge:       ptr.To(intstr.FromInt32(maxSurge)),
+		},
+	}
+}
@@ -0,0 +1,101 @@
+package disruptionbudget
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	opv1 "github.com/openshift/api/operator/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+func TestTopologyFor(t *testing.T) {
+	master := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "master", Labels: map[string]string{masterNodeRoleLabel: "", workerNodeRoleLabel: ""}}}
+	worker := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker", Labels: map[string]string{workerNodeRoleLabel: ""}}}
+	infra := func(controlPlane, infrastructure configv1.TopologyMode) *configv1.Infrastructure {
+		return &configv1.Infrastructure{Status: configv1.InfrastructureStatus{ControlPlaneTopology: controlPlane, InfrastructureTopology: infrastructure}}
+	}
+
+	for _, tt := range []struct {
+		name     string
+		infra    *configv1.Infrastructure
+		nodes    []*corev1.Node
+		expected Topology
+	}{
+		{name: "single node", infra: infra(configv1.SingleReplicaTopologyMode, configv1.SingleReplicaTopologyMode), nodes: []*corev1.Node{master}, expected: SingleNodeTopology},
+		{name: "compact", infra: infra(configv1.HighlyAvailableTopologyMode, configv1.HighlyAvailableTopologyMode), nodes: []*corev1.Node{master}, expected: CompactTopology},
+		{name: "highly available", infra: infra(configv1.HighlyAvailableTopologyMode, configv1.HighlyAvailableTopologyMode), nodes: []*corev1.Node{master, worker}, expected: HighlyAvailableTopology},
+		{name: "hosted", infra: infra(configv1.ExternalTopologyMode, configv1.HighlyAvailableTopologyMode), nodes: []*corev1.Node{worker}, expected: HighlyAvailableTopology},
+		{name: "hosted on a single worker", infra: infra(configv1.ExternalTopologyMode, configv1.SingleReplicaTopologyMode), nodes: []*corev1.Node{worker}, expected: SingleNodeTopology},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if topology := TopologyFor(tt.infra, tt.nodes); topology != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, topology)
+			}
+		})
+	}
+}
+
+func TestPlanFor(t *testing.T) {
+	for _, tt := range []struct {
+		topology               Topology
+		role                   Role
+		expectedReplicas       int32
+		expectedMaxUnavailable *intstr.IntOrString
+		expectedStrategy       appsv1.DeploymentStrategyType
+	}{
+		{topology: HighlyAvailableTopology, role: APIServerRole, expectedReplicas: 3, expectedMaxUnavailable: ptr.To(intstr.FromInt32(1)), expectedStrategy: appsv1.RollingUpdateDeploymentStrategyType},
+		{topology: CompactTopology, role: ControllerRole, expectedReplicas: 2, expectedMaxUnavailable: ptr.To(intstr.FromInt32(1)), expectedStrategy: appsv1.RollingUpdateDeploymentStrategyType},
+		{topology: SingleNodeTopology, role: APIServerRole, expectedReplicas: 1, expectedStrategy: appsv1.RollingUpdateDeploymentStrategyType},
+		{topology: SingleNodeTopology, role: WorkerRole, expectedReplicas: 1, expectedStrategy: appsv1.RollingUpdateDeploymentStrategyType},
+		{topology: HighlyAvailableTopology, role: SingletonRole, expectedReplicas: 1, expectedStrategy: appsv1.RecreateDeploymentStrategyType},
+	} {
+		t.Run(string(tt.topology)+"/"+string(tt.role), func(t *testing.T) {
+			plan, err := PlanFor(tt.topology, tt.role)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plan.Replicas != tt.expectedReplicas || plan.Strategy.Type != tt.expectedStrategy {
+				t.Errorf("unexpected plan %#v", plan)
+			}
+			if (plan.MaxUnavailable == nil) != (tt.expectedMaxUnavailable == nil) || (plan.MaxUnavailable != nil && *plan.MaxUnavailable != *tt.expectedMaxUnavailable) {
+				t.Errorf("expected max unavailable %v, got %v", tt.expectedMaxUnavailable, plan.MaxUnavailable)
+			}
+		})
+	}
+
+	if _, err := PlanFor(HighlyAvailableTopology, "Unknown"); err == nil {
+		t.Error("expected an unknown role to fail")
+	}
+}
+
+func TestDeploymentHook(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "apiserver"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To[int32](1),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "apiserver"}},
+		},
+	}
+	topology := func() (Topology, error) { return HighlyAvailableTopology, nil }
+	if err := WithDeploymentHook(topology, APIServerRole)(&opv1.OperatorSpec{}, deployment); err != nil {
+		t.Fatal(err)
+	}
+	if *deployment.Spec.Replicas != 3 || deployment.Spec.Strategy.RollingUpdate.MaxSurge.IntValue() != 0 {
+		t.Errorf("unexpected deployment spec %#v", deployment.Spec)
+	}
+
+	plan, _ := PlanFor(HighlyAvailableTopology, APIServerRole)
+	pdb := plan.PodDisruptionBudgetFor(deployment)
+	if pdb.Name != "apiserver-pdb" || pdb.Spec.Selector.MatchLabels["app"] != "apiserver" || *pdb.Spec.UnhealthyPodEvictionPolicy != policyv1.AlwaysAllow {
+		t.Errorf("unexpected pdb %#v", pdb)
+	}
+	singleNodePlan, _ := PlanFor(SingleNodeTopology, APIServerRole)
+	if pdb := singleNodePlan.PodDisruptionBudgetFor(deployment); pdb != nil {
+		t.Errorf("expected no pdb on a single node, got %#v", pdb)
+	}
+}
@@ -36,6 +36,40 @@ func NewControllers(
 	secretsClient corev1.SecretsGetter,
 	eventRecorder events.Recorder,
 	resourceSyncer *resourcesynccontroller.ResourceSyncController,
+) (Controllers, error) {
+	return NewControllersWithKeyRetention(
+		component,
+		unsupportedConfigPrefix,
+		provider,
+		deployer,
+		migrator,
+		operatorClient,
+		apiServerClient,
+		apiServerInformer,
+		kubeInformersForNamespaces,
+		secretsClient,
+		eventRecorder,
+		resourceSyncer,
+		controllers.DefaultKeyRetention,
+	)
+}
+
+// NewControllersWithKeyRetention is like NewControllers, with the unused encryption keys kept by the retention
+// instead of controllers.DefaultKeyRetention.
+func NewControllersWithKeyRetention(
+	component string,
+	unsupportedConfigPrefix []string,
+	provider controllers.Provider,
+	deployer statemachine.Deployer,
+	migrator migrators.Migrator,
+	operatorClient operatorv1helpers.OperatorClient,
+	apiServerClient configv1client.APIServerInterface,
+	apiServerInformer configv1informers.APIServerInformer,
+	kubeInformersForNamespaces operatorv1helpers.KubeInformersForNamespaces,
+	secretsClient corev1.SecretsGetter,
+	eventRecorder events.Recorder,
+	resourceSyncer *resourcesynccontroller.ResourceSyncController,
+	keyRetention controllers.KeyRetention,
 ) (Controllers, error) {
 	// avoid using the CachedSecretGetter as we need strong guarantees that our encryptionSecretSelector works
 	// otherwise we could see secrets from a different component (which will break our keyID invariants)
@@ -86,7 +120,7 @@ func NewControllers(
 			encryptionSecretSelector,
 			eventRecorder,
 		),
-		controllers.NewPruneController(
+		controllers.NewPruneControllerWithKeyRetention(
 			component,
 			provider,
 			deployer,
@@ -96,6 +130,7 @@ func NewControllers(
 			kubeInformersForNamespaces,
 			secretsClient,
 			encryptionSecretSelector,
+			keyRetention,
 			eventRecorder,
 		),
 		controllers.NewMigrationController(
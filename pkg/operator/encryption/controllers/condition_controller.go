@@ -7,6 +7,7 @@ import (
 	"time"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -16,14 +17,21 @@ import (
 	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/encryption/encryptionconfig"
+	"github.com/openshift/library-go/pkg/operator/encryption/secrets"
 	"github.com/openshift/library-go/pkg/operator/encryption/state"
 	"github.com/openshift/library-go/pkg/operator/encryption/statemachine"
 	"github.com/openshift/library-go/pkg/operator/events"
 	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
 )
 
+// encryptionKeyRotationGracePeriod is the time a key may exceed the rotation interval, e.g. while the next key is
+// migrated, before the EncryptionKeyRotationOverdue condition turns true.
+const encryptionKeyRotationGracePeriod = 24 * time.Hour
+
 // conditionController maintains the Encrypted condition. It sets it to true iff there is a
 // fully migrated read-key in the current config, and no later key is of identity type.
+// It also maintains the EncryptionKeyRotationOverdue condition comparing the age of the latest key
+// with the rotation interval.
 type conditionController struct {
 	controllerInstanceName string
 	operatorClient         operatorv1helpers.OperatorClient
@@ -75,11 +83,15 @@ func NewConditionController(
 func (c *conditionController) sync(ctx context.Context, _ factory.SyncContext) (err error) {
 	// Status for this condition is left out to make sure it's correctly set in every branch
 	cond := applyoperatorv1.OperatorCondition().WithType("Encrypted")
+	var rotationCond *applyoperatorv1.OperatorConditionApplyConfiguration
 	defer func() {
 		if cond == nil {
 			return
 		}
 		status := applyoperatorv1.OperatorStatus().WithConditions(cond)
+		if rotationCond != nil {
+			status = status.WithConditions(rotationCond)
+		}
 		if applyError := c.operatorClient.ApplyOperatorStatus(ctx, c.controllerInstanceName, status); applyError != nil {
 			err = applyError
 		}
@@ -102,6 +114,7 @@ func (c *conditionController) sync(ctx context.Context, _ factory.SyncContext) (
 		return err
 	}
 	currentState, _ := encryptionconfig.ToEncryptionState(currentConfig, foundSecrets)
+	rotationCond = keyRotationCondition(foundSecrets, time.Now())
 
 	cond = cond.
 		WithStatus(operatorv1.ConditionTrue).
@@ -199,6 +212,40 @@ func allMigrated(toBeEncrypted, migrated []schema.GroupResource) bool {
 	return true
 }
 
+// keyRotationCondition reports the age of the latest key, and who requested it and why if it was created for an
+// external reason, compared to the rotation interval.
+func keyRotationCondition(keySecrets []*corev1.Secret, now time.Time) *applyoperatorv1.OperatorConditionApplyConfiguration {
+	cond := applyoperatorv1.OperatorCondition().
+		WithType("EncryptionKeyRotationOverdue").
+		WithStatus(operatorv1.ConditionFalse)
+
+	var latestKey *state.KeyState
+	var latestKeyID uint64
+	for _, s := range keySecrets {
+		ks, err := secrets.ToKeyState(s)
+		if err != nil {
+			continue
+		}
+		if keyID, _ := state.NameToKeyID(ks.Key.Name); latestKey == nil || keyID > latestKeyID {
+			latestKey, latestKeyID = &ks, keyID
+		}
+	}
+	if latestKey == nil || latestKey.Mode == state.Identity {
+		return cond.WithReason("EncryptionDisabled").WithMessage("Encryption keys are not rotated when encryption is not enabled")
+	}
+
+	age := now.Sub(latestKey.Created).Round(time.Minute)
+	message := fmt.Sprintf("Key %d was created %s ago, keys are rotated every %s", latestKeyID, age, encryptionSecretMigrationInterval)
+	if len(latestKey.ExternalReason) > 0 {
+		message = fmt.Sprintf("%s; the key was requested by %q because %q", message, latestKey.ExternalReasonRequestedBy, latestKey.ExternalReason)
+	}
+	cond = cond.WithReason("AsExpected").WithMessage(message)
+	if age > encryptionSecretMigrationInterval+encryptionKeyRotationGracePeriod {
+		cond = cond.WithStatus(operatorv1.ConditionTrue).WithReason("RotationOverdue")
+	}
+	return cond
+}
+
 func migratedSet(grs []schema.GroupResource) sets.Set[string] {
 	migrated := sets.New[string]()
 	for _, gr := range grs {
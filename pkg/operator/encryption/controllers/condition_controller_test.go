@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	encryptiontesting "github.com/openshift/library-go/pkg/operator/encryption/testing"
+)
+
+func TestKeyRotationCondition(t *testing.T) {
+	now := time.Now()
+	keySecret := func(keyID uint64, created time.Time, annotations map[string]string) *corev1.Secret {
+		s := encryptiontesting.CreateEncryptionKeySecretWithRawKey("kms", []schema.GroupResource{{Group: "", Resource: "secrets"}}, keyID, []byte("61def964fb967f5d7c44a2af8dab6865"))
+		s.CreationTimestamp = metav1.NewTime(created)
+		for k, v := range annotations {
+			s.Annotations[k] = v
+		}
+		return s
+	}
+
+	scenarios := []struct {
+		name            string
+		keySecrets      []*corev1.Secret
+		expectedStatus  operatorv1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:           "no keys",
+			expectedStatus: operatorv1.ConditionFalse,
+			expectedReason: "EncryptionDisabled",
+		},
+		{
+			name: "latest key is within the rotation interval",
+			keySecrets: []*corev1.Secret{
+				keySecret(1, now.Add(-30*24*time.Hour), nil),
+				keySecret(2, now.Add(-2*time.Hour), map[string]string{
+					"encryption.apiserver.operator.openshift.io/external-reason":              "compromised-key",
+					"encryption.apiserver.operator.openshift.io/external-reason-requested-by": "kubectl-annotate",
+				}),
+			},
+			expectedStatus:  operatorv1.ConditionFalse,
+			expectedReason:  "AsExpected",
+			expectedMessage: `Key 2 was created 2h0m0s ago, keys are rotated every 168h0m0s; the key was requested by "kubectl-annotate" because "compromised-key"`,
+		},
+		{
+			name:            "latest key is overdue",
+			keySecrets:      []*corev1.Secret{keySecret(1, now.Add(-9*24*time.Hour), nil)},
+			expectedStatus:  operatorv1.ConditionTrue,
+			expectedReason:  "RotationOverdue",
+			expectedMessage: "Key 1 was created 216h0m0s ago, keys are rotated every 168h0m0s",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			cond := keyRotationCondition(scenario.keySecrets, now)
+			if *cond.Status != scenario.expectedStatus || *cond.Reason != scenario.expectedReason {
+				t.Errorf("unexpected condition status %s and reason %s", *cond.Status, *cond.Reason)
+			}
+			if len(scenario.expectedMessage) > 0 && *cond.Message != scenario.expectedMessage {
+				t.Errorf("unexpected message %q, expected %q", *cond.Message, scenario.expectedMessage)
+			}
+		})
+	}
+}
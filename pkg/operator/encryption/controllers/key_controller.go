@@ -39,6 +39,11 @@ import (
 // greater than the last key's ID (the first key has a key ID of 1).
 const encryptionSecretMigrationInterval = time.Hour * 24 * 7 // one week
 
+// EncryptionKeyRotationReasonAnnotation can be set on the operator resource to request a new encryption key. A new
+// key is created whenever the reason changes. It takes precedence over .encryption.reason of
+// UnsupportedConfigOverrides.
+const EncryptionKeyRotationReasonAnnotation = "encryption.apiserver.operator.openshift.io/rotation-reason"
+
 // keyController creates new keys if necessary. It
 // * watches
 //   - secrets in openshift-config-managed
@@ -51,8 +56,9 @@ const encryptionSecretMigrationInterval = time.Hour * 24 * 7 // one week
 //   - a new to-be-encrypted resource shows up or
 //   - the EncryptionType in the API does not match with the newest existing key or
 //   - based on time (once a week is the proposed rotation interval) or
-//   - an external reason given as a string in .encryption.reason of UnsupportedConfigOverrides.
-//     It then creates it.
+//   - an external reason given as a string in .encryption.reason of UnsupportedConfigOverrides or
+//     in the encryption.apiserver.operator.openshift.io/rotation-reason annotation of the operator resource.
+//     It then creates it, recording the reason and the field manager that set it on the key secret.
 //
 // Note: the "based on time" reason for a new key is based on the annotation
 //
@@ -158,7 +164,7 @@ func (c *keyController) sync(ctx context.Context, syncCtx factory.SyncContext) (
 }
 
 func (c *keyController) checkAndCreateKeys(ctx context.Context, syncContext factory.SyncContext, encryptedGRs []schema.GroupResource) error {
-	currentMode, externalReason, err := c.getCurrentModeAndExternalReason(ctx)
+	currentMode, externalReason, requestedBy, err := c.getCurrentModeAndExternalReason(ctx)
 	if err != nil {
 		return err
 	}
@@ -217,7 +223,7 @@ func (c *keyController) checkAndCreateKeys(ctx context.Context, syncContext fact
 
 	sort.Sort(sort.StringSlice(reasons))
 	internalReason := strings.Join(reasons, ", ")
-	keySecret, err := c.generateKeySecret(newKeyID, currentMode, internalReason, externalReason)
+	keySecret, err := c.generateKeySecret(newKeyID, currentMode, internalReason, externalReason, requestedBy)
 	if err != nil {
 		return fmt.Errorf("failed to create key: %v", err)
 	}
@@ -230,6 +236,10 @@ func (c *keyController) checkAndCreateKeys(ctx context.Context, syncContext fact
 		return createErr
 	}
 
+	if len(externalReason) > 0 {
+		syncContext.Recorder().Eventf("EncryptionKeyCreated", "Secret %q successfully created: %q, external reason %q requested by %q", keySecret.Name, reasons, externalReason, requestedBy)
+		return nil
+	}
 	syncContext.Recorder().Eventf("EncryptionKeyCreated", "Secret %q successfully created: %q", keySecret.Name, reasons)
 
 	return nil
@@ -254,45 +264,80 @@ func (c *keyController) validateExistingSecret(ctx context.Context, keySecret *c
 	return nil // we made this key earlier
 }
 
-func (c *keyController) generateKeySecret(keyID uint64, currentMode state.Mode, internalReason, externalReason string) (*corev1.Secret, error) {
+func (c *keyController) generateKeySecret(keyID uint64, currentMode state.Mode, internalReason, externalReason, requestedBy string) (*corev1.Secret, error) {
 	bs := crypto.ModeToNewKeyFunc[currentMode]()
 	ks := state.KeyState{
 		Key: apiserverv1.Key{
 			Name:   fmt.Sprintf("%d", keyID),
 			Secret: base64.StdEncoding.EncodeToString(bs),
 		},
-		Mode:           currentMode,
-		InternalReason: internalReason,
-		ExternalReason: externalReason,
+		Mode:                      currentMode,
+		InternalReason:            internalReason,
+		ExternalReason:            externalReason,
+		ExternalReasonRequestedBy: requestedBy,
 	}
 	return secrets.FromKeyState(c.instanceName, ks)
 }
 
-func (c *keyController) getCurrentModeAndExternalReason(ctx context.Context) (state.Mode, string, error) {
+// getCurrentModeAndExternalReason returns the configured encryption mode, the external reason for a new key and the
+// field manager that set the external reason.
+func (c *keyController) getCurrentModeAndExternalReason(ctx context.Context) (state.Mode, string, string, error) {
 	apiServer, err := c.apiServerClient.Get(ctx, "cluster", metav1.GetOptions{})
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
 	}
 
 	operatorSpec, _, _, err := c.operatorClient.GetOperatorState()
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
+	}
+	operatorMeta, err := c.operatorClient.GetObjectMeta()
+	if err != nil {
+		return "", "", "", err
 	}
 
 	encryptionConfig, err := structuredUnsupportedConfigFrom(operatorSpec.UnsupportedConfigOverrides.Raw, c.unsupportedConfigPrefix)
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
 	}
 
 	reason := encryptionConfig.Encryption.Reason
+	requestedBy := ""
+	if len(reason) > 0 {
+		requestedBy = fieldManagerOf(operatorMeta, "f:spec", "f:unsupportedConfigOverrides")
+	}
+	if annotationReason := operatorMeta.Annotations[EncryptionKeyRotationReasonAnnotation]; len(annotationReason) > 0 {
+		reason = annotationReason
+		requestedBy = fieldManagerOf(operatorMeta, "f:metadata", "f:annotations", "f:"+EncryptionKeyRotationReasonAnnotation)
+	}
+
 	switch currentMode := state.Mode(apiServer.Spec.Encryption.Type); currentMode {
 	case state.AESCBC, state.AESGCM, state.Identity: // secretbox is disabled for now
-		return currentMode, reason, nil
+		return currentMode, reason, requestedBy, nil
 	case "": // unspecified means use the default (which can change over time)
-		return state.DefaultMode, reason, nil
+		return state.DefaultMode, reason, requestedBy, nil
 	default:
-		return "", "", fmt.Errorf("unknown encryption mode configured: %s", currentMode)
+		return "", "", "", fmt.Errorf("unknown encryption mode configured: %s", currentMode)
+	}
+}
+
+// fieldManagerOf returns the manager of the field with the given path in the managed fields of the object, or
+// "unknown" if it cannot be determined.
+func fieldManagerOf(meta *metav1.ObjectMeta, path ...string) string {
+	for _, entry := range meta.ManagedFields {
+		if entry.FieldsV1 == nil {
+			continue
+		}
+		fields := map[string]interface{}{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			klog.V(4).Infof("unable to decode managed fields of %s: %v", entry.Manager, err)
+			continue
+		}
+		if _, found, _ := unstructured.NestedFieldNoCopy(fields, path...); found {
+			return entry.Manager
+		}
 	}
+	return "unknown"
 }
 
 // needsNewKey checks whether a new key must be created for the given resource. If true, it also returns the latest
@@ -441,7 +441,9 @@ func TestGetCurrentModeAndExternalReason(t *testing.T) {
 		observedConfig        []byte
 		prefix                []string
 		apiServerObjects      []runtime.Object
+		operatorMeta          *metav1.ObjectMeta
 		expectedReasonFromCfg string
+		expectedRequestedBy   string
 	}{
 		{
 			name:                  "no prefix provided, flat observed config",
@@ -476,12 +478,41 @@ func TestGetCurrentModeAndExternalReason(t *testing.T) {
 			name:             "reading empty config works",
 			apiServerObjects: []runtime.Object{&configv1.APIServer{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}},
 		},
+
+		{
+			name:             "annotation takes precedence over the observed config",
+			observedConfig:   []byte(flatEncryptionJSON),
+			apiServerObjects: []runtime.Object{&configv1.APIServer{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}},
+			operatorMeta: &metav1.ObjectMeta{
+				Annotations: map[string]string{EncryptionKeyRotationReasonAnnotation: "compromised-key"},
+				ManagedFields: []metav1.ManagedFieldsEntry{
+					{Manager: "cluster-operator", FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:unsupportedConfigOverrides":{}}}`)}},
+					{Manager: "kubectl-annotate", FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{"f:` + EncryptionKeyRotationReasonAnnotation + `":{}}}}`)}},
+				},
+			},
+			expectedReasonFromCfg: "compromised-key",
+			expectedRequestedBy:   "kubectl-annotate",
+		},
+
+		{
+			name:             "requester of the observed config reason",
+			observedConfig:   []byte(flatEncryptionJSON),
+			apiServerObjects: []runtime.Object{&configv1.APIServer{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}},
+			operatorMeta: &metav1.ObjectMeta{
+				ManagedFields: []metav1.ManagedFieldsEntry{
+					{Manager: "kubectl-edit", FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:unsupportedConfigOverrides":{}}}`)}},
+				},
+			},
+			expectedReasonFromCfg: "need-a-new-key",
+			expectedRequestedBy:   "kubectl-edit",
+		},
 	}
 
 	for _, scenario := range scenarios {
 		t.Run(scenario.name, func(t *testing.T) {
 			// setup
-			fakeOperatorClient := v1helpers.NewFakeStaticPodOperatorClient(
+			fakeOperatorClient := v1helpers.NewFakeStaticPodOperatorClientWithObjectMeta(
+				scenario.operatorMeta,
 				&operatorv1.StaticPodOperatorSpec{
 					OperatorSpec: operatorv1.OperatorSpec{
 						UnsupportedConfigOverrides: runtime.RawExtension{Raw: scenario.observedConfig},
@@ -493,7 +524,7 @@ func TestGetCurrentModeAndExternalReason(t *testing.T) {
 
 			// act
 			target := keyController{unsupportedConfigPrefix: scenario.prefix, operatorClient: fakeOperatorClient, apiServerClient: fakeApiServerClient}
-			_, externalReason, err := target.getCurrentModeAndExternalReason(context.TODO())
+			_, externalReason, requestedBy, err := target.getCurrentModeAndExternalReason(context.TODO())
 
 			// validate
 			if err != nil {
@@ -502,6 +533,9 @@ func TestGetCurrentModeAndExternalReason(t *testing.T) {
 			if externalReason != scenario.expectedReasonFromCfg {
 				t.Errorf("unexpected reason read from the config: %q, expected: %q", externalReason, scenario.expectedReasonFromCfg)
 			}
+			if len(scenario.expectedRequestedBy) > 0 && requestedBy != scenario.expectedRequestedBy {
+				t.Errorf("unexpected requester of the reason: %q, expected: %q", requestedBy, scenario.expectedRequestedBy)
+			}
 		})
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"slices"
 	"sort"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -12,6 +13,8 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	k8smetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
 	"k8s.io/klog/v2"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
@@ -30,17 +33,47 @@ const (
 	keepNumberOfSecrets = 10
 )
 
+// DefaultKeyRetention keeps the ten most recent unused keys regardless of their age.
+var DefaultKeyRetention = KeyRetention{Count: keepNumberOfSecrets}
+
+// KeyRetention configures which unused keys the prune controller keeps to facilitate decryption of old backups.
+// A key is kept if it is one of the Count most recent unused keys or if it is younger than MinAge.
+type KeyRetention struct {
+	// Count is the number of most recent unused keys to keep. Zero keeps the ten most recent unused keys, like
+	// DefaultKeyRetention.
+	Count int
+	// MinAge is the minimum age of unused keys before they are pruned. Zero disables the age based retention.
+	MinAge time.Duration
+}
+
+var retainedKeysMetric = k8smetrics.NewGaugeVec(&k8smetrics.GaugeOpts{
+	Subsystem:      "encryption",
+	Name:           "retained_keys",
+	Help:           "Number of encryption keys the prune controller keeps, by the reason they are kept.",
+	StabilityLevel: k8smetrics.ALPHA,
+}, []string{"component", "reason"})
+
+func init() {
+	(&sync.Once{}).Do(func() {
+		legacyregistry.MustRegister(retainedKeysMetric)
+	})
+}
+
 // pruneController prevents an unbounded growth of old encryption keys.
-// For a given resource, if there are more than ten keys which have been migrated,
-// this controller will delete the oldest migrated keys until there are ten migrated
-// keys total.  These keys are safe to delete since no data in etcd is encrypted using
+// For a given resource, if there are more unused keys than the retention count allows,
+// this controller will delete the oldest unused keys that are older than the retention
+// age.  These keys are safe to delete since no data in etcd is encrypted using
 // them.  Keeping a small number of old keys around is meant to help facilitate
 // decryption of old backups (and general precaution).
+// As a safety interlock, keys are never pruned before every encrypted resource has been
+// migrated to a newer key.
 type pruneController struct {
 	controllerInstanceName string
+	component              string
 	operatorClient         operatorv1helpers.OperatorClient
 
 	encryptionSecretSelector metav1.ListOptions
+	retention                KeyRetention
 
 	deployer                 statemachine.Deployer
 	provider                 Provider
@@ -60,10 +93,45 @@ func NewPruneController(
 	encryptionSecretSelector metav1.ListOptions,
 	eventRecorder events.Recorder,
 ) factory.Controller {
+	return NewPruneControllerWithKeyRetention(
+		instanceName,
+		provider,
+		deployer,
+		preconditionsFulfilledFn,
+		operatorClient,
+		apiServerConfigInformer,
+		kubeInformersForNamespaces,
+		secretClient,
+		encryptionSecretSelector,
+		DefaultKeyRetention,
+		eventRecorder,
+	)
+}
+
+// NewPruneControllerWithKeyRetention is like NewPruneController, with the unused keys kept by the retention instead
+// of DefaultKeyRetention.
+func NewPruneControllerWithKeyRetention(
+	instanceName string,
+	provider Provider,
+	deployer statemachine.Deployer,
+	preconditionsFulfilledFn preconditionsFulfilled,
+	operatorClient operatorv1helpers.OperatorClient,
+	apiServerConfigInformer configv1informers.APIServerInformer,
+	kubeInformersForNamespaces operatorv1helpers.KubeInformersForNamespaces,
+	secretClient corev1client.SecretsGetter,
+	encryptionSecretSelector metav1.ListOptions,
+	retention KeyRetention,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	if retention.Count == 0 {
+		retention.Count = keepNumberOfSecrets
+	}
 	c := &pruneController{
 		operatorClient:           operatorClient,
 		controllerInstanceName:   factory.ControllerInstanceName(instanceName, "EncryptionPrune"),
+		component:                instanceName,
 		encryptionSecretSelector: encryptionSecretSelector,
+		retention:                retention,
 		deployer:                 deployer,
 		provider:                 provider,
 		preconditionsFulfilledFn: preconditionsFulfilledFn,
@@ -151,6 +219,14 @@ func (c *pruneController) deleteOldMigratedSecrets(ctx context.Context, syncCont
 		return iKeyID > jKeyID
 	})
 
+	safeToPruneBelow := safeToPruneBelowKeyID(encryptionSecrets, encryptedGRs)
+	retained := map[string]int{"in-use": 0, "count": 0, "age": 0, "unmigrated": 0}
+	defer func() {
+		for reason, count := range retained {
+			retainedKeysMetric.WithLabelValues(c.component, reason).Set(float64(count))
+		}
+	}()
+
 	var deleteErrs []error
 	skippedKeys := 0
 	deletedKeys := 0
@@ -161,14 +237,29 @@ NextEncryptionSecret:
 			// ignore invalid keys, check whether secret is used
 			for _, us := range allUsedKeys {
 				if state.EqualKeyAndEqualID(&us, &k) {
+					retained["in-use"]++
 					continue NextEncryptionSecret
 				}
 			}
 		}
 
 		// skip the most recent unused secrets around
-		if skippedKeys < keepNumberOfSecrets {
+		if skippedKeys < c.retention.Count {
 			skippedKeys++
+			retained["count"]++
+			continue
+		}
+
+		// skip unused secrets younger than the retention age
+		if c.retention.MinAge > 0 && time.Since(s.CreationTimestamp.Time) < c.retention.MinAge {
+			retained["age"]++
+			continue
+		}
+
+		// never prune keys data might still be encrypted with
+		if keyID, _ := state.NameToKeyID(s.Name); keyID >= safeToPruneBelow {
+			klog.V(2).Infof("Not pruning secret %s/%s because not all encrypted resources have been migrated to a newer key", s.Namespace, s.Name)
+			retained["unmigrated"]++
 			continue
 		}
 
@@ -201,3 +292,29 @@ NextEncryptionSecret:
 	}
 	return utilerrors.FilterOut(utilerrors.NewAggregate(deleteErrs), errors.IsNotFound)
 }
+
+// safeToPruneBelowKeyID returns the key ID below which no data can be encrypted with a key anymore, i.e. the oldest of
+// the latest keys each encrypted resource has been migrated to. It returns 0 if any resource has never been migrated.
+func safeToPruneBelowKeyID(encryptionSecrets []*corev1.Secret, encryptedGRs []schema.GroupResource) uint64 {
+	migratedTo := map[schema.GroupResource]uint64{}
+	for _, s := range encryptionSecrets {
+		k, err := secrets.ToKeyState(s)
+		if err != nil {
+			continue
+		}
+		keyID, _ := state.NameToKeyID(s.Name)
+		for _, gr := range k.Migrated.Resources {
+			if keyID > migratedTo[gr] {
+				migratedTo[gr] = keyID
+			}
+		}
+	}
+
+	var safeToPruneBelow uint64
+	for i, gr := range encryptedGRs {
+		if i == 0 || migratedTo[gr] < safeToPruneBelow {
+			safeToPruneBelow = migratedTo[gr]
+		}
+	}
+	return safeToPruneBelow
+}
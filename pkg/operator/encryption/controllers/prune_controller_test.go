@@ -36,6 +36,7 @@ func TestPruneController(t *testing.T) {
 		encryptionSecretSelector metav1.ListOptions
 		targetNamespace          string
 		targetGRs                []schema.GroupResource
+		retention                *KeyRetention
 		// expectedActions holds actions to be verified in the form of "verb:resource:namespace"
 		expectedActions       []string
 		expectedEncryptionCfg *apiserverconfigv1.EncryptionConfiguration
@@ -82,6 +83,54 @@ func TestPruneController(t *testing.T) {
 			},
 		},
 
+		{
+			name:            "15 keys were migrated, 2 of them are used, 5 are kept by count and the others by age",
+			targetNamespace: "kms",
+			targetGRs: []schema.GroupResource{
+				{Group: "", Resource: "secrets"},
+			},
+			initialSecrets: func() []*corev1.Secret {
+				all := createMigratedEncryptionKeySecretsWithRndKey(t, 15, "kms", "secrets")
+				for _, s := range all {
+					s.CreationTimestamp = metav1.Now()
+				}
+				return all
+			}(),
+			retention: &KeyRetention{Count: 5, MinAge: time.Hour},
+			expectedActions: []string{
+				"list:pods:kms",
+				"get:secrets:kms",
+				"list:secrets:openshift-config-managed",
+				"list:secrets:openshift-config-managed",
+			},
+		},
+
+		{
+			name:            "15 keys were migrated, 2 of them are used, 5 are kept, the 8 most oldest are pruned",
+			targetNamespace: "kms",
+			targetGRs: []schema.GroupResource{
+				{Group: "", Resource: "secrets"},
+			},
+			initialSecrets: createMigratedEncryptionKeySecretsWithRndKey(t, 15, "kms", "secrets"),
+			retention:      &KeyRetention{Count: 5, MinAge: time.Hour},
+			validateFunc: func(ts *testing.T, actions []clientgotesting.Action, initialSecrets []*corev1.Secret) {
+				validateSecretsWerePruned(ts, actions, initialSecrets[:8])
+			},
+		},
+
+		{
+			name:            "15 keys were migrated, 2 of them are used, a zero retention count keeps 10, the 3 most oldest are pruned",
+			targetNamespace: "kms",
+			targetGRs: []schema.GroupResource{
+				{Group: "", Resource: "secrets"},
+			},
+			initialSecrets: createMigratedEncryptionKeySecretsWithRndKey(t, 15, "kms", "secrets"),
+			retention:      &KeyRetention{},
+			validateFunc: func(ts *testing.T, actions []clientgotesting.Action, initialSecrets []*corev1.Secret) {
+				validateSecretsWerePruned(ts, actions, initialSecrets[:3])
+			},
+		},
+
 		{
 			name:            "no-op the migrated keys don't match the selector",
 			targetNamespace: "kms",
@@ -184,7 +233,11 @@ func TestPruneController(t *testing.T) {
 			}
 			provider := newTestProvider(scenario.targetGRs)
 
-			target := NewPruneController(
+			retention := DefaultKeyRetention
+			if scenario.retention != nil {
+				retention = *scenario.retention
+			}
+			target := NewPruneControllerWithKeyRetention(
 				"EncryptionPruneController",
 				provider,
 				deployer,
@@ -194,6 +247,7 @@ func TestPruneController(t *testing.T) {
 				kubeInformers,
 				fakeSecretClient,
 				scenario.encryptionSecretSelector,
+				retention,
 				eventRecorder,
 			)
 
@@ -204,8 +258,10 @@ func TestPruneController(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			if err := encryptiontesting.ValidateActionsVerbs(fakeKubeClient.Actions(), scenario.expectedActions); err != nil {
-				t.Fatalf("incorrect action(s) detected: %v", err)
+			if scenario.expectedActions != nil {
+				if err := encryptiontesting.ValidateActionsVerbs(fakeKubeClient.Actions(), scenario.expectedActions); err != nil {
+					t.Fatalf("incorrect action(s) detected: %v", err)
+				}
 			}
 			if scenario.validateFunc != nil {
 				scenario.validateFunc(t, fakeKubeClient.Actions(), scenario.initialSecrets)
@@ -214,6 +270,26 @@ func TestPruneController(t *testing.T) {
 	}
 }
 
+func TestSafeToPruneBelowKeyID(t *testing.T) {
+	secretsGR := schema.GroupResource{Resource: "secrets"}
+	configMapsGR := schema.GroupResource{Resource: "configmaps"}
+	keySecrets := []*corev1.Secret{
+		encryptiontesting.CreateEncryptionKeySecretWithRawKey("kms", []schema.GroupResource{secretsGR, configMapsGR}, 3, []byte("cfbbae883984944e48d25590abdfd300")),
+		encryptiontesting.CreateEncryptionKeySecretWithRawKey("kms", []schema.GroupResource{secretsGR}, 5, []byte("cfbbae883984944e48d25590abdfd301")),
+		encryptiontesting.CreateEncryptionKeySecretWithRawKey("kms", nil, 6, []byte("cfbbae883984944e48d25590abdfd302")),
+	}
+
+	if keyID := safeToPruneBelowKeyID(keySecrets, []schema.GroupResource{secretsGR}); keyID != 5 {
+		t.Errorf("expected keys below 5 to be safe to prune, got %d", keyID)
+	}
+	if keyID := safeToPruneBelowKeyID(keySecrets, []schema.GroupResource{secretsGR, configMapsGR}); keyID != 3 {
+		t.Errorf("expected keys below 3 to be safe to prune because configmaps were last migrated to key 3, got %d", keyID)
+	}
+	if keyID := safeToPruneBelowKeyID(keySecrets, []schema.GroupResource{secretsGR, {Resource: "routes"}}); keyID != 0 {
+		t.Errorf("expected no key to be safe to prune before all resources are migrated, got %d", keyID)
+	}
+}
+
 func validateSecretsWerePruned(ts *testing.T, actions []clientgotesting.Action, expectedDeletedSecrets []*corev1.Secret) {
 	ts.Helper()
 
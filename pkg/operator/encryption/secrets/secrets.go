@@ -57,6 +57,10 @@ func ToKeyState(s *corev1.Secret) (state.KeyState, error) {
 	if v, ok := s.Annotations[encryptionSecretExternalReason]; ok && len(v) > 0 {
 		key.ExternalReason = v
 	}
+	if v, ok := s.Annotations[encryptionSecretExternalReasonRequestedBy]; ok && len(v) > 0 {
+		key.ExternalReasonRequestedBy = v
+	}
+	key.Created = s.CreationTimestamp.Time
 
 	keyMode := state.Mode(s.Annotations[encryptionSecretMode])
 	switch keyMode {
@@ -101,6 +105,9 @@ func FromKeyState(component string, ks state.KeyState) (*corev1.Secret, error) {
 		Type: corev1.SecretTypeOpaque,
 	}
 
+	if len(ks.ExternalReasonRequestedBy) > 0 {
+		s.Annotations[encryptionSecretExternalReasonRequestedBy] = ks.ExternalReasonRequestedBy
+	}
 	if !ks.Migrated.Timestamp.IsZero() {
 		s.Annotations[EncryptionSecretMigratedTimestamp] = ks.Migrated.Timestamp.Format(time.RFC3339)
 	}
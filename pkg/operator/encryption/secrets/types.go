@@ -41,6 +41,10 @@ const (
 	// determine if a new key should be created even if encryptionSecretMigrationInterval has not been reached.
 	encryptionSecretExternalReason = "encryption.apiserver.operator.openshift.io/external-reason"
 
+	// encryptionSecretExternalReasonRequestedBy is the annotation that denotes who requested the external reason of
+	// the key, i.e. the field manager that set the reason on the operator resource.  It is tracked solely for auditing.
+	encryptionSecretExternalReasonRequestedBy = "encryption.apiserver.operator.openshift.io/external-reason-requested-by"
+
 	// In the data field of the secret API object, this (map) key is used to hold the actual encryption key
 	// (i.e. for AES-CBC mode the value associated with this map key is 32 bytes of random noise).
 	EncryptionSecretKeyDataKey = "encryption.apiserver.operator.openshift.io-key"
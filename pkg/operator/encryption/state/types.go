@@ -38,8 +38,12 @@ type KeyState struct {
 	Migrated MigrationState
 	// some controller logic caused this secret to be created by the key controller.
 	InternalReason string
-	// the user via unsupportConfigOverrides.encryption.reason triggered this key.
+	// the user via unsupportConfigOverrides.encryption.reason or the rotation reason annotation triggered this key.
 	ExternalReason string
+	// the field manager that set the external reason.
+	ExternalReasonRequestedBy string
+	// the creation timestamp of the key secret.
+	Created time.Time
 }
 
 type MigrationState struct {
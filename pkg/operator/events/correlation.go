@@ -0,0 +1,107 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+)
+
+// CorrelationIDAnnotation holds the correlation ID of the controller sync which recorded an event.
+const CorrelationIDAnnotation = "events.openshift.io/correlation-id"
+
+type correlationIDKey struct{}
+
+// annotatedRecorder is implemented by the recorders creating the events, which can annotate them, and by the
+// recorders wrapping them, which pass the annotations on.
+type annotatedRecorder interface {
+	annotatedEvent(eventType, reason, message string, annotations map[string]string)
+}
+
+// recordAnnotated records the event with the annotations when the recorder can annotate its events, and without
+// them otherwise.
+func recordAnnotated(recorder Recorder, eventType, reason, message string, annotations map[string]string) {
+	annotated, ok := recorder.(annotatedRecorder)
+	switch {
+	case !ok && eventType == corev1.EventTypeWarning:
+		recorder.Warning(reason, message)
+	case !ok:
+		recorder.Event(reason, message)
+	default:
+		annotated.annotatedEvent(eventType, reason, message, annotations)
+	}
+}
+
+// NewCorrelationID returns a new random correlation ID.
+func NewCorrelationID() string {
+	return utilrand.String(10)
+}
+
+// ContextWithCorrelationID returns a context carrying the correlation ID.
+func ContextWithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, correlationID)
+}
+
+// CorrelationIDFromContext returns the correlation ID of the context, or an empty string. The context of a controller
+// sync carries the correlation ID of the sync.
+func CorrelationIDFromContext(ctx context.Context) string {
+	correlationID, _ := ctx.Value(correlationIDKey{}).(string)
+	return correlationID
+}
+
+// WithCorrelationID returns a recorder annotating all events with the correlation ID, see CorrelationIDAnnotation, so
+// events recorded during one controller sync can be correlated with each other and with the logs of the sync. The
+// message is left as it is, so the same events of different syncs are still aggregated. Recorders which cannot
+// annotate their events, e.g. custom implementations of Recorder, record them without the correlation ID.
+func WithCorrelationID(recorder Recorder, correlationID string) Recorder {
+	if len(correlationID) == 0 {
+		return recorder
+	}
+	if correlated, ok := recorder.(*correlatedRecorder); ok {
+		recorder = correlated.Recorder
+	}
+	return &correlatedRecorder{Recorder: recorder, correlationID: correlationID}
+}
+
+// correlatedRecorder is an implementation of Recorder interface.
+type correlatedRecorder struct {
+	Recorder
+	correlationID string
+}
+
+func (r *correlatedRecorder) record(eventType, reason, message string) {
+	recordAnnotated(r.Recorder, eventType, reason, message, map[string]string{CorrelationIDAnnotation: r.correlationID})
+}
+
+func (r *correlatedRecorder) Event(reason, message string) {
+	r.record(corev1.EventTypeNormal, reason, message)
+}
+
+func (r *correlatedRecorder) Eventf(reason, messageFmt string, args ...interface{}) {
+	r.Event(reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *correlatedRecorder) Warning(reason, message string) {
+	r.record(corev1.EventTypeWarning, reason, message)
+}
+
+func (r *correlatedRecorder) Warningf(reason, messageFmt string, args ...interface{}) {
+	r.Warning(reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *correlatedRecorder) ForComponent(componentName string) Recorder {
+	return WithCorrelationID(r.Recorder.ForComponent(componentName), r.correlationID)
+}
+
+func (r *correlatedRecorder) WithComponentSuffix(componentNameSuffix string) Recorder {
+	return WithCorrelationID(r.Recorder.WithComponentSuffix(componentNameSuffix), r.correlationID)
+}
+
+func (r *correlatedRecorder) forNamespace(namespace string) Recorder {
+	return WithCorrelationID(WithTargetNamespace(r.Recorder, namespace), r.correlationID)
+}
+
+func (r *correlatedRecorder) WithContext(ctx context.Context) Recorder {
+	return WithCorrelationID(r.Recorder.WithContext(ctx), r.correlationID)
+}
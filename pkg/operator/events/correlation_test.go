@@ -0,0 +1,122 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/klog/v2"
+)
+
+func TestWithCorrelationID(t *testing.T) {
+	inMemory := NewInMemoryRecorder("test")
+	recorder := WithCorrelationID(inMemory, "abc")
+
+	recorder.Eventf("Reason", "message %d", 1)
+	recorder.WithComponentSuffix("sub").Warning("Reason", "message 2")
+	// wrapping again replaces the correlation ID instead of appending it twice
+	WithCorrelationID(recorder, "def").Event("Reason", "message 3")
+
+	expected := []struct {
+		message, correlationID string
+	}{
+		{message: "message 1", correlationID: "abc"},
+		{message: "message 2", correlationID: "abc"},
+		{message: "message 3", correlationID: "def"},
+	}
+	events := inMemory.Events()
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d", len(expected), len(events))
+	}
+	for i := range expected {
+		if events[i].Message != expected[i].message {
+			t.Errorf("expected message %q, got %q", expected[i].message, events[i].Message)
+		}
+		if id := events[i].Annotations[CorrelationIDAnnotation]; id != expected[i].correlationID {
+			t.Errorf("expected correlation ID %q, got %q", expected[i].correlationID, id)
+		}
+	}
+	if events[1].Type != "Warning" {
+		t.Errorf("expected a warning event, got %q", events[1].Type)
+	}
+
+	if WithCorrelationID(inMemory, "") != inMemory {
+		t.Errorf("expected an empty correlation ID to not wrap the recorder")
+	}
+	if id := CorrelationIDFromContext(ContextWithCorrelationID(context.TODO(), "abc")); id != "abc" {
+		t.Errorf("expected correlation ID abc in context, got %q", id)
+	}
+}
+
+func TestWithCorrelationIDWrappedRecorders(t *testing.T) {
+	tests := []struct {
+		name string
+		wrap func(Recorder) Recorder
+	}{
+		{
+			name: "sink",
+			wrap: func(recorder Recorder) Recorder { return WithSink(recorder, NewBatchingSink(&fakeSink{})) },
+		},
+		{
+			name: "operand namespace",
+			wrap: func(recorder Recorder) Recorder { return WithOperandNamespace(recorder, "operand", nil) },
+		},
+		{
+			name: "operand namespace and sink",
+			wrap: func(recorder Recorder) Recorder {
+				return WithSink(WithOperandNamespace(recorder, "operand", nil), NewBatchingSink(&fakeSink{}))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inMemory := NewInMemoryRecorder("test")
+			wrapped := test.wrap(inMemory)
+			recorder := WithCorrelationID(wrapped, "abc")
+
+			recorder.Event("FooCreated", "created")
+			recorder.Warning("FooFailed", "failed")
+
+			events := inMemory.Events()
+			if len(events) != 2 {
+				t.Fatalf("expected 2 events, got %d", len(events))
+			}
+			for _, event := range events {
+				if id := event.Annotations[CorrelationIDAnnotation]; id != "abc" {
+					t.Errorf("expected correlation ID abc of %s, got %q", event.Reason, id)
+				}
+			}
+			if events[1].Type != "Warning" {
+				t.Errorf("expected a warning event, got %q", events[1].Type)
+			}
+
+			forwarding, ok := wrapped.(*sinkRecorder)
+			if !ok {
+				return
+			}
+			for i := 0; i < 2; i++ {
+				if id := (<-forwarding.sink.queue).Annotations[CorrelationIDAnnotation]; id != "abc" {
+					t.Errorf("expected correlation ID abc of the forwarded event, got %q", id)
+				}
+			}
+		})
+	}
+}
+
+func TestWithCorrelationIDLoggingRecorder(t *testing.T) {
+	var output bytes.Buffer
+	klog.LogToStderr(false)
+	klog.SetOutput(&output)
+	defer func() {
+		klog.SetOutput(nil)
+		klog.LogToStderr(true)
+	}()
+
+	WithCorrelationID(NewLoggingEventRecorder("test"), "abc").Warning("FooFailed", "failed")
+	klog.Flush()
+
+	if logged := output.String(); !strings.Contains(logged, CorrelationIDAnnotation+":abc") {
+		t.Errorf("expected the logged event to carry correlation ID abc, got %q", logged)
+	}
+}
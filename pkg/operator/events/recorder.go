@@ -28,6 +28,7 @@ type Recorder interface {
 	ForComponent(componentName string) Recorder
 
 	// WithComponentSuffix is similar to ForComponent except it just suffix the current component name instead of overriding.
+	// The events.k8s.io recorder keeps the chain of suffixes as a hierarchy in the reportingController of its events.
 	WithComponentSuffix(componentNameSuffix string) Recorder
 
 	// WithContext allows to set a context for event create API calls.
@@ -158,6 +159,8 @@ type recorder struct {
 	eventClient       corev1client.EventInterface
 	involvedObjectRef *corev1.ObjectReference
 	sourceComponent   string
+	// targetNamespace is the namespace events are created in instead of the namespace of the event client
+	targetNamespace string
 
 	// TODO: This is not the right way to pass the context, but there is no other way without breaking event interface
 	ctx context.Context
@@ -184,6 +187,16 @@ func (r *recorder) WithComponentSuffix(suffix string) Recorder {
 	return r.ForComponent(fmt.Sprintf("%s-%s", r.ComponentName(), suffix))
 }
 
+func (r *recorder) forNamespace(namespace string) Recorder {
+	if r.involvedObjectRef.Namespace == namespace {
+		return r
+	}
+	newRecorderForNamespace := *r
+	newRecorderForNamespace.involvedObjectRef = getControllerReferenceForNamespace(namespace)
+	newRecorderForNamespace.targetNamespace = namespace
+	return &newRecorderForNamespace
+}
+
 // Event emits the normal type event and allow formatting of message.
 func (r *recorder) Eventf(reason, messageFmt string, args ...interface{}) {
 	r.Event(reason, fmt.Sprintf(messageFmt, args...))
@@ -196,28 +209,35 @@ func (r *recorder) Warningf(reason, messageFmt string, args ...interface{}) {
 
 // Event emits the normal type event.
 func (r *recorder) Event(reason, message string) {
-	event := makeEvent(r.involvedObjectRef, r.sourceComponent, corev1.EventTypeNormal, reason, message)
-	ctx := context.Background()
-	if r.ctx != nil {
-		ctx = r.ctx
-	}
-	if _, err := r.eventClient.Create(ctx, event, metav1.CreateOptions{}); err != nil {
-		klog.Warningf("Error creating event %+v: %v", event, err)
-	}
+	r.annotatedEvent(corev1.EventTypeNormal, reason, message, nil)
 }
 
 // Warning emits the warning type event.
 func (r *recorder) Warning(reason, message string) {
-	event := makeEvent(r.involvedObjectRef, r.sourceComponent, corev1.EventTypeWarning, reason, message)
+	r.annotatedEvent(corev1.EventTypeWarning, reason, message, nil)
+}
+
+func (r *recorder) annotatedEvent(eventType, reason, message string, annotations map[string]string) {
+	event := makeEvent(r.involvedObjectRef, r.sourceComponent, eventType, reason, message)
+	event.Annotations = annotations
 	ctx := context.Background()
 	if r.ctx != nil {
 		ctx = r.ctx
 	}
-	if _, err := r.eventClient.Create(ctx, event, metav1.CreateOptions{}); err != nil {
+	if _, err := r.create(ctx, event); err != nil {
 		klog.Warningf("Error creating event %+v: %v", event, err)
 	}
 }
 
+// create creates the event in the namespace of the event client, or in the target namespace of the recorders derived
+// through WithTargetNamespace.
+func (r *recorder) create(ctx context.Context, event *corev1.Event) (*corev1.Event, error) {
+	if len(r.targetNamespace) > 0 {
+		return r.eventClient.CreateWithEventNamespace(event)
+	}
+	return r.eventClient.Create(ctx, event, metav1.CreateOptions{})
+}
+
 func makeEvent(involvedObjRef *corev1.ObjectReference, sourceComponent string, eventType, reason, message string) *corev1.Event {
 	currentTime := metav1.Time{Time: time.Now()}
 	event := &corev1.Event{
@@ -0,0 +1,263 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	eventsv1client "k8s.io/client-go/kubernetes/typed/events/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+)
+
+// eventSeriesWindow is the time after the last occurrence when a repeated event starts a new series instead of
+// extending the existing one. It matches the interval used by the upstream events.k8s.io recorder.
+const eventSeriesWindow = 6 * time.Minute
+
+// maxReportingInstanceLength is the validation limit of the reportingInstance field.
+const maxReportingInstanceLength = 128
+
+// maxReportingControllerLength is the validation limit of the reportingController field.
+const maxReportingControllerLength = 63
+
+// maxNoteLength is the validation limit of the note field.
+const maxNoteLength = 1024
+
+// reportingControllerSeparator separates the components of the hierarchy in the reportingController field.
+const reportingControllerSeparator = "."
+
+// NewEventsV1Recorder returns a recorder emitting events.k8s.io/v1 Events about the regarding object, with an
+// optional related object. Repeated events are counted in the series of the first event instead of creating new
+// events, like the upstream events.k8s.io recorder does.
+//
+// The recorders derived through WithComponentSuffix keep the hierarchy of their components in the reportingController
+// field, e.g. "foo-operator.revision.installer", while ComponentName returns "foo-operator-revision-installer".
+func NewEventsV1Recorder(client eventsv1client.EventsGetter, sourceComponentName string, regarding, related *corev1.ObjectReference) Recorder {
+	hostname, _ := os.Hostname()
+	return &eventsV1Recorder{
+		client:            client,
+		component:         sourceComponentName,
+		hierarchy:         []string{sourceComponentName},
+		reportingInstance: hostname,
+		regarding:         regarding,
+		related:           related,
+		series:            &eventSeriesCache{series: map[eventSeriesKey]*eventSeries{}},
+		clock:             clock.RealClock{},
+	}
+}
+
+// eventsV1Recorder is an implementation of Recorder interface.
+type eventsV1Recorder struct {
+	client    eventsv1client.EventsGetter
+	component string
+	// hierarchy is the chain of components from the root recorder, the last one is the component of this recorder
+	hierarchy         []string
+	reportingInstance string
+	regarding         *corev1.ObjectReference
+	related           *corev1.ObjectReference
+
+	// series is shared by all recorders derived through ForComponent
+	series *eventSeriesCache
+	clock  clock.PassiveClock
+
+	ctx context.Context
+}
+
+type eventSeriesKey struct {
+	component string
+	eventType string
+	reason    string
+	note      string
+}
+
+type eventSeries struct {
+	namespace    string
+	name         string
+	count        int32
+	lastObserved time.Time
+}
+
+type eventSeriesCache struct {
+	lock   sync.Mutex
+	series map[eventSeriesKey]*eventSeries
+}
+
+func (r *eventsV1Recorder) ComponentName() string {
+	return r.component
+}
+
+func (r *eventsV1Recorder) Shutdown() {}
+
+func (r *eventsV1Recorder) ForComponent(componentName string) Recorder {
+	newRecorderForComponent := *r
+	newRecorderForComponent.component = componentName
+	newRecorderForComponent.hierarchy = []string{componentName}
+	return &newRecorderForComponent
+}
+
+func (r *eventsV1Recorder) WithComponentSuffix(suffix string) Recorder {
+	newRecorderForComponent := *r
+	newRecorderForComponent.component = fmt.Sprintf("%s-%s", r.ComponentName(), suffix)
+	newRecorderForComponent.hierarchy = append(append([]string{}, r.hierarchy...), suffix)
+	return &newRecorderForComponent
+}
+
+func (r *eventsV1Recorder) forNamespace(namespace string) Recorder {
+	if r.regarding.Namespace == namespace {
+		return r
+	}
+	newRecorderForNamespace := *r
+	newRecorderForNamespace.regarding = getControllerReferenceForNamespace(namespace)
+	return &newRecorderForNamespace
+}
+
+func (r *eventsV1Recorder) WithContext(ctx context.Context) Recorder {
+	r.ctx = ctx
+	return r
+}
+
+// Eventf emits the normal type event and allow formatting of message.
+func (r *eventsV1Recorder) Eventf(reason, messageFmt string, args ...interface{}) {
+	r.Event(reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// Warningf emits the warning type event and allow formatting of message.
+func (r *eventsV1Recorder) Warningf(reason, messageFmt string, args ...interface{}) {
+	r.Warning(reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// Event emits the normal type event.
+func (r *eventsV1Recorder) Event(reason, message string) {
+	r.annotatedEvent(corev1.EventTypeNormal, reason, message, nil)
+}
+
+// Warning emits the warning type event.
+func (r *eventsV1Recorder) Warning(reason, message string) {
+	r.annotatedEvent(corev1.EventTypeWarning, reason, message, nil)
+}
+
+// annotatedEvent records the event, the annotations are set on the event starting a series.
+func (r *eventsV1Recorder) annotatedEvent(eventType, reason, message string, annotations map[string]string) {
+	ctx := context.Background()
+	if r.ctx != nil {
+		ctx = r.ctx
+	}
+	now := r.clock.Now()
+	key := eventSeriesKey{component: r.component, eventType: eventType, reason: reason, note: message}
+
+	// the lock only guards the cache, the API calls are made without holding it
+	if series, ok := r.series.observe(key, now); ok {
+		err := r.patchSeries(ctx, series)
+		if err == nil {
+			return
+		}
+		klog.V(2).Infof("Unable to update series of event %s/%s, creating a new event: %v", series.namespace, series.name, err)
+	}
+
+	event := r.makeEvent(now, eventType, reason, message)
+	event.Annotations = annotations
+	if _, err := r.client.Events(event.Namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		klog.Warningf("Error creating event %+v: %v", event, err)
+		return
+	}
+	r.series.add(key, &eventSeries{namespace: event.Namespace, name: event.Name, count: 1, lastObserved: now}, now)
+}
+
+func (r *eventsV1Recorder) patchSeries(ctx context.Context, series eventSeries) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"series": eventsv1.EventSeries{
+			Count:            series.count,
+			LastObservedTime: metav1.NewMicroTime(series.lastObserved),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.client.Events(series.namespace).Patch(ctx, series.name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+func (r *eventsV1Recorder) makeEvent(now time.Time, eventType, reason, message string) *eventsv1.Event {
+	reportingInstance := truncateOnRune(r.component+"-"+r.reportingInstance, maxReportingInstanceLength)
+	event := &eventsv1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			// the random suffix avoids conflicts of events recorded at the same time by the derived recorders
+			Name:      fmt.Sprintf("%v.%x.%s", r.regarding.Name, now.UnixNano(), utilrand.String(5)),
+			Namespace: r.regarding.Namespace,
+		},
+		EventTime:           metav1.NewMicroTime(now),
+		ReportingController: shortenReportingController(strings.Join(r.hierarchy, reportingControllerSeparator)),
+		ReportingInstance:   reportingInstance,
+		// the Recorder interface has no notion of an action, the reason describes it best
+		Action:    reason,
+		Reason:    reason,
+		Regarding: *r.regarding,
+		Related:   r.related,
+		Note:      truncateOnRune(message, maxNoteLength),
+		Type:      eventType,
+	}
+	if len(event.Namespace) == 0 {
+		event.Namespace = metav1.NamespaceDefault
+	}
+	return event
+}
+
+// shortenReportingController keeps the reporting controller within the validation limit. Longer hierarchies keep their
+// beginning and get a hash of the full name, so different hierarchies stay apart.
+func shortenReportingController(reportingController string) string {
+	if len(reportingController) <= maxReportingControllerLength {
+		return reportingController
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(reportingController))
+	suffix := fmt.Sprintf("-%08x", hash.Sum32())
+	return truncateOnRune(reportingController, maxReportingControllerLength-len(suffix)) + suffix
+}
+
+// truncateOnRune shortens s to at most max bytes without splitting a multi-byte character.
+func truncateOnRune(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
+
+// observe counts another occurrence of the series and returns a copy of it, unless there is no series that can be
+// extended.
+func (c *eventSeriesCache) observe(key eventSeriesKey, now time.Time) (eventSeries, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	series, ok := c.series[key]
+	if !ok || now.Sub(series.lastObserved) > eventSeriesWindow {
+		return eventSeries{}, false
+	}
+	series.count++
+	series.lastObserved = now
+	return *series, true
+}
+
+// add stores the series and drops the ones that can no longer be extended, so the cache does not grow unbounded.
+func (c *eventSeriesCache) add(key eventSeriesKey, series *eventSeries, now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for k, s := range c.series {
+		if now.Sub(s.lastObserved) > eventSeriesWindow {
+			delete(c.series, k)
+		}
+	}
+	c.series[key] = series
+}
@@ -0,0 +1,150 @@
+package events
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestEventsV1RecorderSeries(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	regarding := &corev1.ObjectReference{Kind: "Deployment", Namespace: "test-namespace", Name: "operator", APIVersion: "apps/v1"}
+	related := &corev1.ObjectReference{Kind: "Secret", Namespace: "test-namespace", Name: "serving-cert", APIVersion: "v1"}
+	fakeClock := clocktesting.NewFakePassiveClock(time.Now())
+
+	recorder := NewEventsV1Recorder(client.EventsV1(), "test-operator", regarding, related).(*eventsV1Recorder)
+	recorder.clock = fakeClock
+
+	recorder.Eventf("CertRotated", "rotated %s", "serving-cert")
+	fakeClock.SetTime(fakeClock.Now().Add(time.Minute))
+	recorder.Eventf("CertRotated", "rotated %s", "serving-cert")
+	fakeClock.SetTime(fakeClock.Now().Add(time.Minute))
+	recorder.WithComponentSuffix("sub").Eventf("CertRotated", "rotated %s", "serving-cert")
+	recorder.Warning("CertRotated", "rotated serving-cert")
+
+	list, err := client.EventsV1().Events("test-namespace").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(list.Items))
+	}
+	var series int
+	for _, event := range list.Items {
+		if event.Regarding != *regarding || event.Related == nil || *event.Related != *related {
+			t.Errorf("unexpected object references %#v %#v", event.Regarding, event.Related)
+		}
+		if len(event.Action) == 0 || len(event.ReportingController) == 0 || len(event.ReportingInstance) == 0 {
+			t.Errorf("expected action and reporting fields to be set, got %#v", event)
+		}
+		if event.Series != nil {
+			series++
+			if event.Series.Count != 2 || event.ReportingController != "test-operator" || event.Type != corev1.EventTypeNormal {
+				t.Errorf("unexpected series event %#v", event)
+			}
+		}
+	}
+	if series != 1 {
+		t.Errorf("expected exactly one event with a series, got %d", series)
+	}
+
+	// after the series window, a new event is created
+	fakeClock.SetTime(fakeClock.Now().Add(eventSeriesWindow + time.Second))
+	recorder.Eventf("CertRotated", "rotated %s", "serving-cert")
+	list, err = client.EventsV1().Events("test-namespace").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 4 {
+		t.Errorf("expected a new event after the series window, got %d events", len(list.Items))
+	}
+}
+
+func TestEventsV1RecorderComponentHierarchy(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	regarding := &corev1.ObjectReference{Kind: "Deployment", Namespace: "test-namespace", Name: "operator", APIVersion: "apps/v1"}
+
+	recorder := NewEventsV1Recorder(client.EventsV1(), "test-operator", regarding, nil)
+	child := recorder.WithComponentSuffix("revision").WithComponentSuffix("installer")
+	if child.ComponentName() != "test-operator-revision-installer" {
+		t.Errorf("unexpected component name %q", child.ComponentName())
+	}
+	child.Event("Installed", "installed revision 3")
+	child.ForComponent("other").Event("Other", "other component")
+
+	list, err := client.EventsV1().Events("test-namespace").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	controllers := map[string]string{}
+	for _, event := range list.Items {
+		controllers[event.Reason] = event.ReportingController
+	}
+	if controllers["Installed"] != "test-operator.revision.installer" || controllers["Other"] != "other" {
+		t.Errorf("unexpected reporting controllers %v", controllers)
+	}
+}
+
+func TestEventsV1RecorderLimits(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	regarding := &corev1.ObjectReference{Kind: "Deployment", Namespace: "test-namespace", Name: "operator", APIVersion: "apps/v1"}
+
+	recorder := NewEventsV1Recorder(client.EventsV1(), "test-operator", regarding, nil)
+	for i := 0; i < 10; i++ {
+		recorder = recorder.WithComponentSuffix("controller")
+	}
+	recorder.Event("Long", strings.Repeat("ü", 1000))
+	recorder.ForComponent("other").Event("Short", "short")
+
+	list, err := client.EventsV1().Events("test-namespace").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, event := range list.Items {
+		if len(event.Note) > maxNoteLength || !utf8.ValidString(event.Note) {
+			t.Errorf("expected the note to be truncated on a character boundary, got %d bytes", len(event.Note))
+		}
+		if len(event.ReportingController) > maxReportingControllerLength {
+			t.Errorf("expected the reporting controller to be shortened, got %q", event.ReportingController)
+		}
+		if event.Reason == "Long" && !strings.HasPrefix(event.ReportingController, "test-operator.controller.") {
+			t.Errorf("expected the reporting controller to keep its beginning, got %q", event.ReportingController)
+		}
+		if event.Reason == "Short" && event.ReportingController != "other" {
+			t.Errorf("expected a short reporting controller to be kept, got %q", event.ReportingController)
+		}
+	}
+}
+
+func TestEventsV1RecorderDoesNotHoldLockDuringCalls(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	regarding := &corev1.ObjectReference{Kind: "Deployment", Namespace: "test-namespace", Name: "operator", APIVersion: "apps/v1"}
+	recorder := NewEventsV1Recorder(client.EventsV1(), "test-operator", regarding, nil).(*eventsV1Recorder)
+
+	calls := 0
+	client.PrependReactor("*", "events", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		calls++
+		if !recorder.series.lock.TryLock() {
+			t.Errorf("expected the series lock not to be held during %s", action.GetVerb())
+			return false, nil, nil
+		}
+		recorder.series.lock.Unlock()
+		return false, nil, nil
+	})
+
+	// the first event is created, the second one patches the series
+	recorder.Event("Rotated", "rotated")
+	recorder.Event("Rotated", "rotated")
+	if calls != 2 {
+		t.Errorf("expected a create and a patch, got %d calls", calls)
+	}
+}
@@ -63,9 +63,17 @@ func (r *inMemoryEventRecorder) Events() []*corev1.Event {
 }
 
 func (r *inMemoryEventRecorder) Event(reason, message string) {
+	r.annotatedEvent(corev1.EventTypeNormal, reason, message, nil)
+}
+
+func (r *inMemoryEventRecorder) annotatedEvent(eventType, reason, message string, annotations map[string]string) {
 	r.Lock()
 	defer r.Unlock()
-	event := makeEvent(&inMemoryDummyObjectReference, r.source, corev1.EventTypeNormal, reason, message)
+	event := makeEvent(&inMemoryDummyObjectReference, r.source, eventType, reason, message)
+	event.Annotations = annotations
+	if eventType == corev1.EventTypeWarning {
+		klog.Info(event.String())
+	}
 	r.events = append(r.events, event)
 }
 
@@ -74,11 +82,7 @@ func (r *inMemoryEventRecorder) Eventf(reason, messageFmt string, args ...interf
 }
 
 func (r *inMemoryEventRecorder) Warning(reason, message string) {
-	r.Lock()
-	defer r.Unlock()
-	event := makeEvent(&inMemoryDummyObjectReference, r.source, corev1.EventTypeWarning, reason, message)
-	klog.Info(event.String())
-	r.events = append(r.events, event)
+	r.annotatedEvent(corev1.EventTypeWarning, reason, message, nil)
 }
 
 func (r *inMemoryEventRecorder) Warningf(reason, messageFmt string, args ...interface{}) {
@@ -40,8 +40,7 @@ func (r *LoggingEventRecorder) WithComponentSuffix(suffix string) Recorder {
 }
 
 func (r *LoggingEventRecorder) Event(reason, message string) {
-	event := makeEvent(&inMemoryDummyObjectReference, "", corev1.EventTypeNormal, reason, message)
-	klog.Info(event.String())
+	r.annotatedEvent(corev1.EventTypeNormal, reason, message, nil)
 }
 
 func (r *LoggingEventRecorder) Eventf(reason, messageFmt string, args ...interface{}) {
@@ -49,8 +48,17 @@ func (r *LoggingEventRecorder) Eventf(reason, messageFmt string, args ...interfa
 }
 
 func (r *LoggingEventRecorder) Warning(reason, message string) {
-	event := makeEvent(&inMemoryDummyObjectReference, "", corev1.EventTypeWarning, reason, message)
-	klog.Warning(event.String())
+	r.annotatedEvent(corev1.EventTypeWarning, reason, message, nil)
+}
+
+func (r *LoggingEventRecorder) annotatedEvent(eventType, reason, message string, annotations map[string]string) {
+	event := makeEvent(&inMemoryDummyObjectReference, "", eventType, reason, message)
+	event.Annotations = annotations
+	if eventType == corev1.EventTypeWarning {
+		klog.Warning(event.String())
+		return
+	}
+	klog.Info(event.String())
 }
 
 func (r *LoggingEventRecorder) Warningf(reason, messageFmt string, args ...interface{}) {
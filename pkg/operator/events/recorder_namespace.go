@@ -0,0 +1,114 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// namespaceTargeter is implemented by the recorders able to emit their events into another namespace.
+type namespaceTargeter interface {
+	forNamespace(namespace string) Recorder
+}
+
+// WithTargetNamespace returns a child recorder emitting its events into the namespace, about the namespace itself,
+// e.g. to report the changes of an operand in the namespace of the operand, next to the events of its workload.
+// Recorders that cannot target another namespace, like the in memory and logging recorders, are returned as they are.
+func WithTargetNamespace(recorder Recorder, namespace string) Recorder {
+	if len(namespace) == 0 {
+		return recorder
+	}
+	if targeter, ok := recorder.(namespaceTargeter); ok {
+		return targeter.forNamespace(namespace)
+	}
+	return recorder
+}
+
+// IsResourceChangeEvent returns true for the reasons of the events reporting the creation, update or deletion of a
+// resource by resourceapply, e.g. DeploymentUpdated or SecretCreateFailed.
+func IsResourceChangeEvent(reason string) bool {
+	for _, suffix := range []string{"Created", "Updated", "Deleted", "CreateFailed", "UpdateFailed", "DeleteFailed"} {
+		if strings.HasSuffix(reason, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithOperandNamespace returns a recorder emitting the events affecting the operand into the operand namespace, see
+// WithTargetNamespace, and the other events like the recorder does. isOperandEvent tells from the reason whether an
+// event affects the operand, it defaults to IsResourceChangeEvent.
+//
+// The recorders derived from the returned recorder keep the mapping.
+func WithOperandNamespace(recorder Recorder, operandNamespace string, isOperandEvent func(reason string) bool) Recorder {
+	if isOperandEvent == nil {
+		isOperandEvent = IsResourceChangeEvent
+	}
+	if mapped, ok := recorder.(*operandNamespaceRecorder); ok {
+		recorder = mapped.Recorder
+	}
+	return &operandNamespaceRecorder{
+		Recorder:         recorder,
+		operandRecorder:  WithTargetNamespace(recorder, operandNamespace),
+		operandNamespace: operandNamespace,
+		isOperandEvent:   isOperandEvent,
+	}
+}
+
+// operandNamespaceRecorder is an implementation of Recorder interface.
+type operandNamespaceRecorder struct {
+	Recorder
+	operandRecorder  Recorder
+	operandNamespace string
+	isOperandEvent   func(reason string) bool
+}
+
+func (r *operandNamespaceRecorder) recorderFor(reason string) Recorder {
+	if r.isOperandEvent(reason) {
+		return r.operandRecorder
+	}
+	return r.Recorder
+}
+
+func (r *operandNamespaceRecorder) Event(reason, message string) {
+	r.recorderFor(reason).Event(reason, message)
+}
+
+func (r *operandNamespaceRecorder) Eventf(reason, messageFmt string, args ...interface{}) {
+	r.Event(reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *operandNamespaceRecorder) Warning(reason, message string) {
+	r.recorderFor(reason).Warning(reason, message)
+}
+
+func (r *operandNamespaceRecorder) annotatedEvent(eventType, reason, message string, annotations map[string]string) {
+	recordAnnotated(r.recorderFor(reason), eventType, reason, message, annotations)
+}
+
+func (r *operandNamespaceRecorder) Warningf(reason, messageFmt string, args ...interface{}) {
+	r.Warning(reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *operandNamespaceRecorder) ForComponent(componentName string) Recorder {
+	return WithOperandNamespace(r.Recorder.ForComponent(componentName), r.operandNamespace, r.isOperandEvent)
+}
+
+func (r *operandNamespaceRecorder) WithComponentSuffix(componentNameSuffix string) Recorder {
+	return WithOperandNamespace(r.Recorder.WithComponentSuffix(componentNameSuffix), r.operandNamespace, r.isOperandEvent)
+}
+
+func (r *operandNamespaceRecorder) WithContext(ctx context.Context) Recorder {
+	return WithOperandNamespace(r.Recorder.WithContext(ctx), r.operandNamespace, r.isOperandEvent)
+}
+
+func (r *operandNamespaceRecorder) forNamespace(namespace string) Recorder {
+	return WithTargetNamespace(r.Recorder, namespace)
+}
+
+func (r *operandNamespaceRecorder) Shutdown() {
+	r.Recorder.Shutdown()
+	if r.operandRecorder != r.Recorder {
+		r.operandRecorder.Shutdown()
+	}
+}
@@ -0,0 +1,65 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestWithOperandNamespace(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	regarding := &corev1.ObjectReference{Kind: "Deployment", Namespace: "openshift-foo-operator", Name: "foo-operator", APIVersion: "apps/v1"}
+
+	recorder := WithOperandNamespace(NewEventsV1Recorder(client.EventsV1(), "foo-operator", regarding, nil), "openshift-foo", nil)
+	child := WithCorrelationID(recorder.WithComponentSuffix("workload"), "abc")
+	child.Eventf("DeploymentUpdated", "Updated Deployment.apps/foo -n openshift-foo because it changed")
+	child.Warning("ProgressingTooLong", "the rollout is stuck")
+
+	operandEvents, err := client.EventsV1().Events("openshift-foo").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(operandEvents.Items) != 1 {
+		t.Fatalf("expected one event in the operand namespace, got %d", len(operandEvents.Items))
+	}
+	event := operandEvents.Items[0]
+	if event.Reason != "DeploymentUpdated" || event.Regarding.Kind != "Namespace" || event.Regarding.Name != "openshift-foo" {
+		t.Errorf("unexpected operand event %#v", event)
+	}
+	if event.ReportingController != "foo-operator.workload" {
+		t.Errorf("expected the component hierarchy to be kept, got %q", event.ReportingController)
+	}
+
+	operatorEvents, err := client.EventsV1().Events("openshift-foo-operator").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(operatorEvents.Items) != 1 || operatorEvents.Items[0].Reason != "ProgressingTooLong" || operatorEvents.Items[0].Regarding != *regarding {
+		t.Errorf("expected the other event in the operator namespace, got %#v", operatorEvents.Items)
+	}
+}
+
+func TestRecorderWithTargetNamespace(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	involved := &corev1.ObjectReference{Kind: "Deployment", Namespace: "openshift-foo-operator", Name: "foo-operator", APIVersion: "apps/v1"}
+
+	recorder := NewRecorder(client.CoreV1().Events(""), "foo-operator", involved)
+	WithTargetNamespace(recorder, "openshift-foo").Event("Reason", "message")
+	recorder.Event("Reason", "message")
+
+	var namespaces []string
+	for _, action := range client.Actions() {
+		event := action.(clienttesting.CreateAction).GetObject().(*corev1.Event)
+		if event.Namespace != event.InvolvedObject.Namespace {
+			t.Errorf("expected the event in the namespace of the involved object, got %#v", event)
+		}
+		namespaces = append(namespaces, event.Namespace)
+	}
+	if len(namespaces) != 2 || namespaces[0] != "openshift-foo" || namespaces[1] != "openshift-foo-operator" {
+		t.Errorf("unexpected event namespaces %v", namespaces)
+	}
+}
@@ -0,0 +1,212 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// SinkEvent is an operator event forwarded to a Sink.
+type SinkEvent struct {
+	Time      time.Time `json:"time"`
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	Component string    `json:"component"`
+	// Annotations are the annotations of the event, e.g. its CorrelationIDAnnotation.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Sink receives the operator events for an external system, e.g. a fleet wide event aggregator. Send must return an
+// error when the events were not delivered, they are sent again.
+type Sink interface {
+	Send(ctx context.Context, events []SinkEvent) error
+}
+
+// BatchingSink queues the events recorded by the recorders returned by WithSink and sends them in batches to a Sink,
+// retrying failed sends. Events are dropped when the queue is full, the sink must not slow down the operator.
+type BatchingSink struct {
+	sink          Sink
+	queue         chan SinkEvent
+	maxBatchSize  int
+	flushInterval time.Duration
+	retryBackoff  wait.Backoff
+}
+
+// BatchingOption configures a BatchingSink.
+type BatchingOption func(*BatchingSink)
+
+// WithQueueSize sets the number of events queued before new events are dropped, 1000 by default.
+func WithQueueSize(size int) BatchingOption {
+	return func(s *BatchingSink) {
+		s.queue = make(chan SinkEvent, size)
+	}
+}
+
+// WithMaxBatchSize sets the number of events sent at most at once, 100 by default.
+func WithMaxBatchSize(size int) BatchingOption {
+	return func(s *BatchingSink) {
+		s.maxBatchSize = size
+	}
+}
+
+// WithFlushInterval sets how long events are batched at most before they are sent, 10 seconds by default.
+func WithFlushInterval(interval time.Duration) BatchingOption {
+	return func(s *BatchingSink) {
+		s.flushInterval = interval
+	}
+}
+
+// WithRetryBackoff sets the backoff of the retries of a failed send. The batch is dropped when the steps are exhausted.
+func WithRetryBackoff(backoff wait.Backoff) BatchingOption {
+	return func(s *BatchingSink) {
+		s.retryBackoff = backoff
+	}
+}
+
+// NewBatchingSink returns a BatchingSink sending the events to the sink once it runs.
+func NewBatchingSink(sink Sink, options ...BatchingOption) *BatchingSink {
+	s := &BatchingSink{
+		sink:          sink,
+		queue:         make(chan SinkEvent, 1000),
+		maxBatchSize:  100,
+		flushInterval: 10 * time.Second,
+		retryBackoff:  wait.Backoff{Duration: time.Second, Factor: 2, Jitter: 0.1, Steps: 5},
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Add queues the event, or drops it when the queue is full.
+func (s *BatchingSink) Add(event SinkEvent) {
+	select {
+	case s.queue <- event:
+	default:
+		klog.V(2).Infof("Dropping event %s for the event sink, its queue is full", event.Reason)
+	}
+}
+
+// Run sends the queued events until the context is done, then sends the events left once.
+func (s *BatchingSink) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	var batch []SinkEvent
+	for {
+		select {
+		case event := <-s.queue:
+			batch = append(batch, event)
+			if len(batch) < s.maxBatchSize {
+				continue
+			}
+		case <-ticker.C:
+		case <-ctx.Done():
+			s.flush(batch)
+			return
+		}
+		if len(batch) > 0 {
+			s.send(ctx, batch)
+			batch = nil
+		}
+	}
+}
+
+// flush sends the batch and the queued events once, shortly.
+func (s *BatchingSink) flush(batch []SinkEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for drained := false; !drained; {
+		select {
+		case event := <-s.queue:
+			batch = append(batch, event)
+		default:
+			drained = true
+		}
+	}
+	for len(batch) > 0 {
+		n := min(len(batch), s.maxBatchSize)
+		if err := s.sink.Send(ctx, batch[:n]); err != nil {
+			klog.Warningf("Dropping %d events not sent to the event sink on shutdown: %v", len(batch), err)
+			return
+		}
+		batch = batch[n:]
+	}
+}
+
+func (s *BatchingSink) send(ctx context.Context, batch []SinkEvent) {
+	var lastErr error
+	err := wait.ExponentialBackoffWithContext(ctx, s.retryBackoff, func(ctx context.Context) (bool, error) {
+		if lastErr = s.sink.Send(ctx, batch); lastErr != nil {
+			klog.V(2).Infof("Failed to send %d events to the event sink, retrying: %v", len(batch), lastErr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		klog.Warningf("Dropping %d events not sent to the event sink: %v", len(batch), lastErr)
+	}
+}
+
+// WithSink returns a recorder recording the events like the recorder does and forwarding them to the sink, which must
+// be running. The recorders derived from the returned recorder forward their events too.
+func WithSink(recorder Recorder, sink *BatchingSink) Recorder {
+	if forwarding, ok := recorder.(*sinkRecorder); ok {
+		recorder = forwarding.Recorder
+	}
+	return &sinkRecorder{Recorder: recorder, sink: sink}
+}
+
+// sinkRecorder is an implementation of Recorder interface.
+type sinkRecorder struct {
+	Recorder
+	sink *BatchingSink
+}
+
+func (r *sinkRecorder) forward(eventType, reason, message string, annotations map[string]string) {
+	r.sink.Add(SinkEvent{Time: time.Now(), Type: eventType, Reason: reason, Message: message, Component: r.ComponentName(), Annotations: annotations})
+}
+
+func (r *sinkRecorder) Event(reason, message string) {
+	r.Recorder.Event(reason, message)
+	r.forward(corev1.EventTypeNormal, reason, message, nil)
+}
+
+func (r *sinkRecorder) Eventf(reason, messageFmt string, args ...interface{}) {
+	r.Event(reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *sinkRecorder) Warning(reason, message string) {
+	r.Recorder.Warning(reason, message)
+	r.forward(corev1.EventTypeWarning, reason, message, nil)
+}
+
+func (r *sinkRecorder) annotatedEvent(eventType, reason, message string, annotations map[string]string) {
+	recordAnnotated(r.Recorder, eventType, reason, message, annotations)
+	r.forward(eventType, reason, message, annotations)
+}
+
+func (r *sinkRecorder) Warningf(reason, messageFmt string, args ...interface{}) {
+	r.Warning(reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *sinkRecorder) ForComponent(componentName string) Recorder {
+	return WithSink(r.Recorder.ForComponent(componentName), r.sink)
+}
+
+func (r *sinkRecorder) WithComponentSuffix(componentNameSuffix string) Recorder {
+	return WithSink(r.Recorder.WithComponentSuffix(componentNameSuffix), r.sink)
+}
+
+func (r *sinkRecorder) forNamespace(namespace string) Recorder {
+	return WithSink(WithTargetNamespace(r.Recorder, namespace), r.sink)
+}
+
+func (r *sinkRecorder) WithContext(ctx context.Context) Recorder {
+	return WithSink(r.Recorder.WithContext(ctx), r.sink)
+}
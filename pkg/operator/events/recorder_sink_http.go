@@ -0,0 +1,148 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NewWebhookSink returns a sink posting the events to the URL as a JSON list of SinkEvent. The client defaults to
+// http.DefaultClient.
+func NewWebhookSink(url string, client *http.Client) Sink {
+	return &httpSink{url: url, client: client, encode: func(events []SinkEvent) ([]byte, error) {
+		return json.Marshal(events)
+	}}
+}
+
+// NewOTLPLogsSink returns a sink exporting the events as OTLP log records, with the resource attributes, e.g.
+// service.name, to the OTLP/HTTP logs endpoint of a collector, e.g. http://collector:4318/v1/logs. Warning events have
+// the WARN severity, the other events INFO. The client defaults to http.DefaultClient.
+func NewOTLPLogsSink(endpoint string, client *http.Client, resourceAttributes map[string]string) Sink {
+	return &httpSink{url: endpoint, client: client, encode: func(events []SinkEvent) ([]byte, error) {
+		return json.Marshal(otlpLogs(events, resourceAttributes))
+	}}
+}
+
+type httpSink struct {
+	url    string
+	client *http.Client
+	encode func([]SinkEvent) ([]byte, error)
+}
+
+func (s *httpSink) Send(ctx context.Context, events []SinkEvent) error {
+	body, err := s.encode(events)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := s.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s returned %s: %s", s.url, resp.Status, message)
+	}
+	return nil
+}
+
+// the OTLP/HTTP JSON encoding of the logs, see https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type otlpLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes,omitempty"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano   string          `json:"timeUnixNano"`
+	SeverityNumber int             `json:"severityNumber"`
+	SeverityText   string          `json:"severityText"`
+	Body           otlpValue       `json:"body"`
+	Attributes     []otlpAttribute `json:"attributes"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+const (
+	otlpSeverityInfo = 9
+	otlpSeverityWarn = 13
+)
+
+func otlpLogs(events []SinkEvent, resourceAttributes map[string]string) otlpLogsRequest {
+	var resource otlpResource
+	for key, value := range resourceAttributes {
+		resource.Attributes = append(resource.Attributes, otlpAttribute{Key: key, Value: otlpValue{StringValue: value}})
+	}
+	sort.Slice(resource.Attributes, func(i, j int) bool { return resource.Attributes[i].Key < resource.Attributes[j].Key })
+
+	var records []otlpLogRecord
+	for _, event := range events {
+		severity, severityText := otlpSeverityInfo, "INFO"
+		if event.Type == corev1.EventTypeWarning {
+			severity, severityText = otlpSeverityWarn, "WARN"
+		}
+		attributes := []otlpAttribute{
+			{Key: "k8s.event.reason", Value: otlpValue{StringValue: event.Reason}},
+			{Key: "k8s.event.type", Value: otlpValue{StringValue: event.Type}},
+			{Key: "k8s.event.component", Value: otlpValue{StringValue: event.Component}},
+		}
+		annotationKeys := make([]string, 0, len(event.Annotations))
+		for key := range event.Annotations {
+			annotationKeys = append(annotationKeys, key)
+		}
+		sort.Strings(annotationKeys)
+		for _, key := range annotationKeys {
+			attributes = append(attributes, otlpAttribute{Key: "k8s.event.annotation." + key, Value: otlpValue{StringValue: event.Annotations[key]}})
+		}
+		records = append(records, otlpLogRecord{
+			TimeUnixNano:   strconv.FormatInt(event.Time.UnixNano(), 10),
+			SeverityNumber: severity,
+			SeverityText:   severityText,
+			Body:           otlpValue{StringValue: event.Message},
+			Attributes:     attributes,
+		})
+	}
+	return otlpLogsRequest{ResourceLogs: []otlpResourceLogs{{
+		Resource:  resource,
+		ScopeLogs: []otlpScopeLogs{{Scope: otlpScope{Name: "github.com/openshift/library-go/pkg/operator/events"}, LogRecords: records}},
+	}}}
+}
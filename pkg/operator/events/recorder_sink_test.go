@@ -0,0 +1,141 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+type fakeSink struct {
+	lock     sync.Mutex
+	failures int
+	batches  [][]SinkEvent
+}
+
+func (s *fakeSink) Send(_ context.Context, events []SinkEvent) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("unavailable")
+	}
+	s.batches = append(s.batches, append([]SinkEvent{}, events...))
+	return nil
+}
+
+func (s *fakeSink) sent() [][]SinkEvent {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.batches
+}
+
+func TestWithSink(t *testing.T) {
+	sink := &fakeSink{failures: 1}
+	batching := NewBatchingSink(sink, WithMaxBatchSize(2), WithFlushInterval(time.Hour), WithRetryBackoff(wait.Backoff{Duration: time.Millisecond, Steps: 3}))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		batching.Run(ctx)
+	}()
+
+	inMemory := NewInMemoryRecorder("operator")
+	recorder := WithSink(inMemory, batching).WithComponentSuffix("foo")
+	recorder.Eventf("FooCreated", "created %s", "foo")
+	recorder.Warning("FooFailed", "failed")
+	recorder.Event("FooUpdated", "updated")
+
+	// the first batch is full, it is sent after a failure
+	if err := wait.PollUntilContextTimeout(context.Background(), time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return len(sink.sent()) == 1, nil
+	}); err != nil {
+		t.Fatalf("expected the first batch to be sent: %v", err)
+	}
+	// the rest is sent on shutdown
+	cancel()
+	<-done
+
+	batches := sink.sent()
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 {
+		t.Fatalf("unexpected batches %v", batches)
+	}
+	first := batches[0][0]
+	if first.Type != "Normal" || first.Reason != "FooCreated" || first.Message != "created foo" || first.Component != "operator-foo" {
+		t.Errorf("unexpected event %#v", first)
+	}
+	if batches[0][1].Type != "Warning" {
+		t.Errorf("expected a warning, got %#v", batches[0][1])
+	}
+	if len(inMemory.Events()) != 3 {
+		t.Errorf("expected the events to be recorded too, got %v", inMemory.Events())
+	}
+}
+
+func TestBatchingSinkDropsWhenFull(t *testing.T) {
+	sink := &fakeSink{}
+	batching := NewBatchingSink(sink, WithQueueSize(1))
+	batching.Add(SinkEvent{Reason: "First"})
+	batching.Add(SinkEvent{Reason: "Dropped"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	batching.Run(ctx)
+	if batches := sink.sent(); len(batches) != 1 || len(batches[0]) != 1 || batches[0][0].Reason != "First" {
+		t.Errorf("unexpected batches %v", batches)
+	}
+}
+
+func TestHTTPSinks(t *testing.T) {
+	events := []SinkEvent{
+		{Time: time.Unix(10, 0), Type: "Normal", Reason: "FooCreated", Message: "created", Component: "operator"},
+		{Time: time.Unix(20, 0), Type: "Warning", Reason: "FooFailed", Message: "failed", Component: "operator"},
+	}
+
+	var body []byte
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	if err := NewWebhookSink(server.URL, nil).Send(context.Background(), events); err != nil {
+		t.Fatal(err)
+	}
+	var received []SinkEvent
+	if err := json.Unmarshal(body, &received); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 2 || received[1].Reason != "FooFailed" || !received[0].Time.Equal(events[0].Time) {
+		t.Errorf("unexpected webhook events %v", received)
+	}
+
+	if err := NewOTLPLogsSink(server.URL, server.Client(), map[string]string{"service.name": "foo-operator"}).Send(context.Background(), events); err != nil {
+		t.Fatal(err)
+	}
+	var logs otlpLogsRequest
+	if err := json.Unmarshal(body, &logs); err != nil {
+		t.Fatal(err)
+	}
+	resourceLogs := logs.ResourceLogs[0]
+	if resourceLogs.Resource.Attributes[0].Value.StringValue != "foo-operator" {
+		t.Errorf("unexpected resource %v", resourceLogs.Resource)
+	}
+	records := resourceLogs.ScopeLogs[0].LogRecords
+	if len(records) != 2 || records[0].TimeUnixNano != "10000000000" || records[0].SeverityText != "INFO" || records[1].SeverityNumber != otlpSeverityWarn || records[1].Body.StringValue != "failed" {
+		t.Errorf("unexpected log records %v", records)
+	}
+
+	status = http.StatusServiceUnavailable
+	if err := NewWebhookSink(server.URL, nil).Send(context.Background(), events); err == nil {
+		t.Error("expected the failed send to return an error")
+	}
+}
@@ -123,6 +123,17 @@ func (r *upstreamRecorder) Shutdown() {
 	r.broadcaster.Shutdown()
 }
 
+func (r *upstreamRecorder) forNamespace(namespace string) Recorder {
+	if r.involvedObjectRef != nil && r.involvedObjectRef.Namespace == namespace {
+		return r
+	}
+	// the event sink creates the events in the namespace of the involved object
+	newRecorderForNamespace := r.ForComponent(r.component).(*upstreamRecorder)
+	newRecorderForNamespace.involvedObjectRef = getControllerReferenceForNamespace(namespace)
+	newRecorderForNamespace.fallbackRecorder = WithTargetNamespace(r.fallbackRecorder, namespace)
+	return newRecorderForNamespace
+}
+
 func (r *upstreamRecorder) WithComponentSuffix(suffix string) Recorder {
 	return r.ForComponent(fmt.Sprintf("%s-%s", r.ComponentName(), suffix))
 }
@@ -150,24 +161,29 @@ func (r *upstreamRecorder) incrementEventsCounter(severity string) {
 
 // Event emits the normal type event.
 func (r *upstreamRecorder) Event(reason, message string) {
-	r.shutdownMutex.RLock()
-	defer r.shutdownMutex.RUnlock()
-	defer r.incrementEventsCounter(corev1.EventTypeNormal)
-	if r.shuttingDown {
-		r.fallbackRecorder.Event(reason, message)
-		return
-	}
-	r.eventRecorder.Event(r.involvedObjectRef, corev1.EventTypeNormal, reason, message)
+	r.annotatedEvent(corev1.EventTypeNormal, reason, message, nil)
 }
 
 // Warning emits the warning type event.
 func (r *upstreamRecorder) Warning(reason, message string) {
+	r.annotatedEvent(corev1.EventTypeWarning, reason, message, nil)
+}
+
+func (r *upstreamRecorder) annotatedEvent(eventType, reason, message string, annotations map[string]string) {
 	r.shutdownMutex.RLock()
 	defer r.shutdownMutex.RUnlock()
-	defer r.incrementEventsCounter(corev1.EventTypeWarning)
+	defer r.incrementEventsCounter(eventType)
 	if r.shuttingDown {
-		r.fallbackRecorder.Warning(reason, message)
+		if eventType == corev1.EventTypeWarning {
+			r.fallbackRecorder.Warning(reason, message)
+		} else {
+			r.fallbackRecorder.Event(reason, message)
+		}
+		return
+	}
+	if len(annotations) == 0 {
+		r.eventRecorder.Event(r.involvedObjectRef, eventType, reason, message)
 		return
 	}
-	r.eventRecorder.Event(r.involvedObjectRef, corev1.EventTypeWarning, reason, message)
+	r.eventRecorder.AnnotatedEventf(r.involvedObjectRef, annotations, eventType, reason, "%s", message)
 }
@@ -10,6 +10,7 @@ import (
 	operatorv1 "github.com/openshift/api/operator/v1"
 	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
 	"github.com/openshift/library-go/pkg/apiserver/jsonpatch"
+	"github.com/openshift/library-go/pkg/operator/objectbudget"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -289,6 +290,9 @@ func (c dynamicOperatorClient) applyOperatorStatus(ctx context.Context, fieldMan
 			if len(ptr.Deref(curr.Status, "")) == 0 {
 				panic(fmt.Sprintf(".status.conditions[%q].status is missing", *curr.Type))
 			}
+			if curr.Message != nil {
+				desiredConfiguration.Conditions[i].Message = ptr.To(objectbudget.TruncateConditionMessage(*curr.Message))
+			}
 		}
 	}
 
@@ -3,9 +3,12 @@ package latencyprofilecontroller
 import (
 	"context"
 	"fmt"
-	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	"sort"
+	"strings"
 	"time"
 
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -42,6 +45,8 @@ type CheckProfileRejectionFunc func(
 // The current state of the operand is by watching the configs applied
 // to different static pod revisions that are active and uses the information to
 // update status as either progressing or completed or degraded.
+// While progressing, the nodes not running a revision with the profile yet are listed.
+// The arguments of the profiles can be declared with nodeobserver.LatencyProfileArgs.
 // Note: In case new latency profiles are added in the future in openshift/api
 // this could break cluster upgrades and set this controller into degraded state
 // because of an "unknown latency profile" error.
@@ -174,6 +179,13 @@ func (c *LatencyProfileController) sync(ctx context.Context, syncCtx factory.Syn
 	if err != nil {
 		return err
 	}
+	if !revisionsHaveSynced {
+		rollout, err := c.nodeRollout(configNodeObj.Spec.WorkerLatencyProfile, operatorStatus.NodeStatuses)
+		if err != nil {
+			return err
+		}
+		syncMsg = fmt.Sprintf("%s: %s", syncMsg, rollout)
+	}
 
 	if revisionsHaveSynced {
 		err = c.updateStatus(
@@ -193,6 +205,29 @@ func (c *LatencyProfileController) sync(ctx context.Context, syncCtx factory.Syn
 	return err
 }
 
+// nodeRollout describes which nodes run a revision with the arguments of the profile, and which do not yet.
+func (c *LatencyProfileController) nodeRollout(profile configv1.WorkerLatencyProfileType, nodeStatuses []operatorv1.NodeStatus) (string, error) {
+	revisionMatches := map[int32]bool{}
+	var pendingNodes []string
+	for _, nodeStatus := range nodeStatuses {
+		match, ok := revisionMatches[nodeStatus.CurrentRevision]
+		if !ok {
+			var err error
+			match, _, err = c.matchRevisionsFn(profile, []int32{nodeStatus.CurrentRevision})
+			if err != nil {
+				return "", err
+			}
+			revisionMatches[nodeStatus.CurrentRevision] = match
+		}
+		if !match {
+			pendingNodes = append(pendingNodes, fmt.Sprintf("%s at revision %d", nodeStatus.NodeName, nodeStatus.CurrentRevision))
+		}
+	}
+	sort.Strings(pendingNodes)
+	return fmt.Sprintf("%d of %d nodes run the %q latency profile, waiting for %s",
+		len(nodeStatuses)-len(pendingNodes), len(nodeStatuses), profile, strings.Join(pendingNodes, ", ")), nil
+}
+
 func (c *LatencyProfileController) updateStatus(ctx context.Context, isProgressing, isComplete bool, reason, message string) error {
 	progressingCondition := applyoperatorv1.OperatorCondition().
 		WithType(workerLatencyProfileProgressing).
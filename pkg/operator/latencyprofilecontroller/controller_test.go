@@ -0,0 +1,59 @@
+package latencyprofilecontroller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	listerv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestSyncTracksNodeRollout(t *testing.T) {
+	configNodeIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, configNodeIndexer.Add(&configv1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       configv1.NodeSpec{WorkerLatencyProfile: configv1.MediumUpdateAverageReaction},
+	}))
+	operatorClient := v1helpers.NewFakeStaticPodOperatorClient(
+		&operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{ManagementState: operatorv1.Managed}},
+		&operatorv1.StaticPodOperatorStatus{NodeStatuses: []operatorv1.NodeStatus{
+			{NodeName: "master-0", CurrentRevision: 3},
+			{NodeName: "master-1", CurrentRevision: 2},
+			{NodeName: "master-2", CurrentRevision: 3},
+		}},
+		nil, nil,
+	)
+	// the profile is set in revision 3
+	matchRevisions := func(profile configv1.WorkerLatencyProfileType, revisions []int32) (bool, string, error) {
+		for _, revision := range revisions {
+			if revision < 3 {
+				return false, revisionsHaveNotSyncedMessage, nil
+			}
+		}
+		return true, revisionsHaveSyncedMessage, nil
+	}
+	c := &LatencyProfileController{
+		controllerInstanceName: "test-LatencyProfile",
+		operatorClient:         operatorClient,
+		configNodeLister:       listerv1.NewNodeLister(configNodeIndexer),
+		matchRevisionsFn:       matchRevisions,
+	}
+
+	require.NoError(t, c.sync(context.TODO(), factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))))
+
+	_, status, _, err := operatorClient.GetStaticPodOperatorState()
+	require.NoError(t, err)
+	progressing := v1helpers.FindOperatorCondition(status.Conditions, workerLatencyProfileProgressing)
+	require.NotNil(t, progressing)
+	require.Equal(t, operatorv1.ConditionTrue, progressing.Status)
+	require.Equal(t, revisionsHaveNotSyncedMessage+`: 2 of 3 nodes run the "MediumUpdateAverageReaction" latency profile, waiting for master-1 at revision 2`, progressing.Message)
+}
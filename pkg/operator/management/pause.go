@@ -0,0 +1,109 @@
+package management
+
+import (
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+)
+
+const (
+	// PausedAnnotation on the operator resource pauses the reconciliation of the controllers using the PauseGate.
+	// Its value is the reason of the pause, e.g. "debugging the etcd quorum loss, see INC-1234".
+	PausedAnnotation = "operator.openshift.io/paused"
+	// PausedByAnnotation optionally names who paused the operator.
+	PausedByAnnotation = "operator.openshift.io/paused-by"
+	// PausedAtAnnotation optionally records when the operator was paused, in RFC 3339 format. Without it the pause is
+	// dated to when the gate first observed it.
+	PausedAtAnnotation = "operator.openshift.io/paused-at"
+)
+
+// ObjectMetaGetter returns the metadata of the operator resource, it is implemented by the operator clients.
+type ObjectMetaGetter interface {
+	GetObjectMeta() (*metav1.ObjectMeta, error)
+}
+
+// PauseState describes a pause of the reconciliation.
+type PauseState struct {
+	Paused bool
+	// By is who paused the operator, if known.
+	By string
+	// Reason is why the operator was paused.
+	Reason string
+	// Since is when the operator was paused.
+	Since time.Time
+}
+
+// PauseGate tells whether the reconciliation of an operator is paused by the PausedAnnotation on its operator
+// resource. Controllers built with factory.WithPauseGate skip their syncs while the operator is paused, so nothing is
+// written to the cluster, which is safer for maintenance than setting the operator Unmanaged: the operator keeps
+// running, no operand is considered unmanaged and removing the annotation resumes the reconciliation right away.
+//
+// A single gate is meant to be shared by all controllers of an operator.
+type PauseGate struct {
+	operator ObjectMetaGetter
+	clock    clock.PassiveClock
+
+	lock sync.Mutex
+	// observedSince is when the gate first observed a pause without the PausedAtAnnotation
+	observedSince time.Time
+}
+
+// NewPauseGate returns a gate checking the annotations of the operator resource.
+func NewPauseGate(operator ObjectMetaGetter) *PauseGate {
+	return &PauseGate{
+		operator: operator,
+		clock:    clock.RealClock{},
+	}
+}
+
+// WithClock sets the clock a pause without the PausedAtAnnotation is dated with.
+func (g *PauseGate) WithClock(clock clock.PassiveClock) *PauseGate {
+	g.clock = clock
+	return g
+}
+
+// State returns the current pause of the operator. An operator resource that cannot be read is not paused.
+func (g *PauseGate) State() PauseState {
+	objectMeta, err := g.operator.GetObjectMeta()
+	if err != nil {
+		klog.V(4).Infof("Unable to check the pause of the operator: %v", err)
+		return g.observe(nil)
+	}
+	return g.observe(objectMeta.Annotations)
+}
+
+// Paused returns true when the reconciliation of the operator is paused.
+func (g *PauseGate) Paused() bool {
+	return g.State().Paused
+}
+
+func (g *PauseGate) observe(annotations map[string]string) PauseState {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	reason, paused := annotations[PausedAnnotation]
+	if !paused {
+		g.observedSince = time.Time{}
+		return PauseState{}
+	}
+	if g.observedSince.IsZero() {
+		g.observedSince = g.clock.Now()
+	}
+	state := PauseState{
+		Paused: true,
+		By:     annotations[PausedByAnnotation],
+		Reason: reason,
+		Since:  g.observedSince,
+	}
+	if pausedAt, ok := annotations[PausedAtAnnotation]; ok {
+		if since, err := time.Parse(time.RFC3339, pausedAt); err == nil {
+			state.Since = since
+		} else {
+			klog.Warningf("Ignoring invalid %s annotation %q: %v", PausedAtAnnotation, pausedAt, err)
+		}
+	}
+	return state
+}
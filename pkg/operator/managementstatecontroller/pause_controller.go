@@ -0,0 +1,112 @@
+package managementstatecontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/utils/clock"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/condition"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/management"
+	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+var pausedDurationMetric = metrics.NewGaugeVec(&metrics.GaugeOpts{
+	Subsystem:      "operator",
+	Name:           "paused_duration_seconds",
+	Help:           "How long the reconciliation of the operator has been paused, 0 when it is not paused.",
+	StabilityLevel: metrics.ALPHA,
+}, []string{"name"})
+
+func init() {
+	(&sync.Once{}).Do(func() {
+		legacyregistry.MustRegister(pausedDurationMetric)
+	})
+}
+
+// PauseController reports the pause of the reconciliation by the management.PauseGate in the Paused condition of the
+// operator and in the operator_paused_duration_seconds metric. It must not be built with the gate itself.
+type PauseController struct {
+	controllerInstanceName string
+	operatorName           string
+	operatorClient         operatorv1helpers.OperatorClient
+	gate                   *management.PauseGate
+	clock                  clock.PassiveClock
+	// paused is the pause state reported last, to emit events when it changes
+	paused bool
+}
+
+func NewOperatorPauseController(
+	instanceName string,
+	operatorClient operatorv1helpers.OperatorClient,
+	gate *management.PauseGate,
+	recorder events.Recorder,
+) factory.Controller {
+	c := &PauseController{
+		controllerInstanceName: factory.ControllerInstanceName(instanceName, "Pause"),
+		operatorName:           instanceName,
+		operatorClient:         operatorClient,
+		gate:                   gate,
+		clock:                  clock.RealClock{},
+	}
+	return factory.New().
+		WithInformers(operatorClient.Informer()).
+		WithSync(c.sync).
+		ResyncEvery(time.Minute).
+		ToController(
+			c.controllerInstanceName,
+			recorder.WithComponentSuffix("pause-recorder"),
+		)
+}
+
+func (c *PauseController) sync(ctx context.Context, syncContext factory.SyncContext) error {
+	state := c.gate.State()
+
+	cond := applyoperatorv1.OperatorCondition().
+		WithType(condition.OperatorPausedConditionType).
+		WithStatus(operatorv1.ConditionFalse).
+		WithReason("AsExpected")
+	if !state.Paused {
+		pausedDurationMetric.WithLabelValues(c.operatorName).Set(0)
+		if c.paused {
+			syncContext.Recorder().Eventf("OperatorResumed", "The reconciliation of the %s operator was resumed", c.operatorName)
+		}
+	} else {
+		pausedDurationMetric.WithLabelValues(c.operatorName).Set(c.clock.Since(state.Since).Seconds())
+		message := pauseMessage(state)
+		cond = cond.
+			WithStatus(operatorv1.ConditionTrue).
+			WithReason("Paused").
+			WithMessage(message)
+		if !c.paused {
+			syncContext.Recorder().Warningf("OperatorPaused", "%s", message)
+		}
+	}
+	c.paused = state.Paused
+
+	status := applyoperatorv1.OperatorStatus().WithConditions(cond)
+	return c.operatorClient.ApplyOperatorStatus(ctx, c.controllerInstanceName, status)
+}
+
+func pauseMessage(state management.PauseState) string {
+	message := []string{"The reconciliation is paused"}
+	if len(state.By) > 0 {
+		message = append(message, fmt.Sprintf("by %s", state.By))
+	}
+	message = append(message, fmt.Sprintf("since %s", state.Since.UTC().Format(time.RFC3339)))
+	ret := strings.Join(message, " ")
+	if len(state.Reason) > 0 {
+		ret += ": " + state.Reason
+	}
+	return ret
+}
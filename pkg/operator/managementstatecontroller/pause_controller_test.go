@@ -0,0 +1,78 @@
+package managementstatecontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/condition"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/management"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestOperatorPauseController(t *testing.T) {
+	fakeClock := clocktesting.NewFakePassiveClock(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC))
+	objectMeta := &metav1.ObjectMeta{Name: "cluster"}
+	operatorClient := v1helpers.NewFakeOperatorClientWithObjectMeta(objectMeta, &operatorv1.OperatorSpec{ManagementState: operatorv1.Managed}, &operatorv1.OperatorStatus{}, nil)
+	recorder := events.NewInMemoryRecorder("pause")
+	controller := &PauseController{
+		controllerInstanceName: "OPERATOR_NAME-Pause",
+		operatorName:           "OPERATOR_NAME",
+		operatorClient:         operatorClient,
+		gate:                   management.NewPauseGate(operatorClient).WithClock(fakeClock),
+		clock:                  fakeClock,
+	}
+	sync := func() *operatorv1.OperatorCondition {
+		t.Helper()
+		if err := controller.sync(context.TODO(), factory.NewSyncContext("test", recorder)); err != nil {
+			t.Fatal(err)
+		}
+		_, status, _, err := operatorClient.GetOperatorState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v1helpers.FindOperatorCondition(status.Conditions, condition.OperatorPausedConditionType)
+	}
+
+	if cond := sync(); cond == nil || cond.Status != operatorv1.ConditionFalse {
+		t.Fatalf("expected Paused=False, got %v", cond)
+	}
+
+	objectMeta.Annotations = map[string]string{
+		management.PausedAnnotation:   "debugging the quorum loss",
+		management.PausedByAnnotation: "alice",
+	}
+	fakeClock.SetTime(fakeClock.Now().Add(time.Minute))
+	cond := sync()
+	if cond == nil || cond.Status != operatorv1.ConditionTrue || cond.Reason != "Paused" {
+		t.Fatalf("expected Paused=True, got %v", cond)
+	}
+	if expected := "The reconciliation is paused by alice since 2024-01-01T10:01:00Z: debugging the quorum loss"; cond.Message != expected {
+		t.Errorf("expected message %q, got %q", expected, cond.Message)
+	}
+
+	// the pause is dated to when it was first observed
+	fakeClock.SetTime(fakeClock.Now().Add(time.Minute))
+	if cond := sync(); cond.Message != "The reconciliation is paused by alice since 2024-01-01T10:01:00Z: debugging the quorum loss" {
+		t.Errorf("expected the pause to keep its time, got %q", cond.Message)
+	}
+
+	objectMeta.Annotations = nil
+	if cond := sync(); cond.Status != operatorv1.ConditionFalse {
+		t.Errorf("expected Paused=False after the annotation was removed, got %v", cond)
+	}
+
+	var reasons []string
+	for _, event := range recorder.Events() {
+		reasons = append(reasons, event.Reason)
+	}
+	if len(reasons) != 2 || reasons[0] != "OperatorPaused" || reasons[1] != "OperatorResumed" {
+		t.Errorf("expected a pause and a resume event, got %v", reasons)
+	}
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// DegradedTimeRecorder accumulates the time the Degraded conditions of an operator are true in the degraded seconds
+// metric. The conditions are sampled, the time between two samples is attributed to the conditions which were true
+// at the first one.
+type DegradedTimeRecorder struct {
+	operator string
+	clock    clock.PassiveClock
+
+	lock         sync.Mutex
+	lastObserved time.Time
+	degraded     sets.Set[string]
+}
+
+// NewDegradedTimeRecorder returns a recorder of the degraded time of the operator.
+func NewDegradedTimeRecorder(operator string) *DegradedTimeRecorder {
+	return &DegradedTimeRecorder{
+		operator: operator,
+		clock:    clock.RealClock{},
+		degraded: sets.New[string](),
+	}
+}
+
+// Observe samples the conditions of the operator.
+func (r *DegradedTimeRecorder) Observe(conditions []operatorv1.OperatorCondition) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := r.clock.Now()
+	if !r.lastObserved.IsZero() {
+		elapsed := now.Sub(r.lastObserved).Seconds()
+		for conditionType := range r.degraded {
+			degradedSecondsMetric.WithLabelValues(r.operator, conditionType).Add(elapsed)
+		}
+	}
+
+	r.lastObserved = now
+	r.degraded = sets.New[string]()
+	for _, condition := range conditions {
+		if isDegradedConditionType(condition.Type) && condition.Status == operatorv1.ConditionTrue {
+			r.degraded.Insert(condition.Type)
+		}
+	}
+}
+
+// Run samples the conditions of the operator status every interval until the context is done.
+func (r *DegradedTimeRecorder) Run(ctx context.Context, operatorClient v1helpers.OperatorClient, interval time.Duration) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		_, status, _, err := operatorClient.GetOperatorState()
+		if err != nil {
+			klog.V(4).Infof("Unable to get the operator status to record the degraded time of %s: %v", r.operator, err)
+			return
+		}
+		r.Observe(status.Conditions)
+	}, interval)
+}
@@ -0,0 +1,99 @@
+// Package metrics registers the metrics every operator built on library-go reports the same way, so fleet dashboards
+// and alerts do not depend on the operator:
+//
+//   - openshift_operator_build_info, the version the operator was built from,
+//   - openshift_operator_leader, whether this replica is the leader,
+//   - openshift_operator_reconcile_duration_seconds, the sync durations of the controllers in SLO buckets,
+//   - openshift_operator_degraded_seconds_total, how long every Degraded condition type has been true.
+//
+// All the metrics are labeled by operator name and registered in the legacy registry served by controllercmd.
+package metrics
+
+import (
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"github.com/openshift/library-go/pkg/config/leaderelection"
+)
+
+const (
+	namespace = "openshift"
+	subsystem = "operator"
+)
+
+var (
+	buildInfoMetric = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Namespace:      namespace,
+		Subsystem:      subsystem,
+		Name:           "build_info",
+		Help:           "A metric with a constant '1' value labeled by the version, git commit and Go version the operator was built from.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"operator", "git_version", "git_commit", "go_version"})
+
+	leaderMetric = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Namespace:      namespace,
+		Subsystem:      subsystem,
+		Name:           "leader",
+		Help:           "1 when this replica of the operator is the leader, 0 otherwise.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"operator"})
+
+	reconcileDurationMetric = metrics.NewHistogramVec(&metrics.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of the syncs of the operator controllers, by result.",
+		// the boundaries of the reconcile SLOs, a sync is expected to take less than a second and must not take
+		// more than a minute
+		Buckets:        []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300},
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"operator", "controller", "result"})
+
+	degradedSecondsMetric = metrics.NewCounterVec(&metrics.CounterOpts{
+		Namespace:      namespace,
+		Subsystem:      subsystem,
+		Name:           "degraded_seconds_total",
+		Help:           "Number of seconds the Degraded conditions of the operator have been true, by condition type.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"operator", "condition_type"})
+)
+
+func init() {
+	(&sync.Once{}).Do(func() {
+		legacyregistry.MustRegister(buildInfoMetric)
+		legacyregistry.MustRegister(leaderMetric)
+		legacyregistry.MustRegister(reconcileDurationMetric)
+		legacyregistry.MustRegister(degradedSecondsMetric)
+	})
+}
+
+// RecordBuildInfo reports the version the operator was built from.
+func RecordBuildInfo(operator string, info version.Info) {
+	buildInfoMetric.WithLabelValues(operator, info.GitVersion, info.GitCommit, info.GoVersion).Set(1)
+}
+
+// SetLeader reports whether this replica of the operator is the leader.
+func SetLeader(operator string, isLeader bool) {
+	value := 0.0
+	if isLeader {
+		value = 1
+	}
+	leaderMetric.WithLabelValues(operator).Set(value)
+}
+
+// LeaderChangeHandler returns a handler of the leader observer, e.g. ControllerContext.LeaderObserver, keeping the
+// leader metric of the operator up to date.
+func LeaderChangeHandler(operator string) leaderelection.LeaderChangeHandler {
+	return func(status leaderelection.LeaderStatus) {
+		SetLeader(operator, status.IsSelf)
+	}
+}
+
+// isDegradedConditionType returns true for the condition types reported as degraded time.
+func isDegradedConditionType(conditionType string) bool {
+	return strings.HasSuffix(conditionType, "Degraded")
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/component-base/metrics/testutil"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/library-go/pkg/config/leaderelection"
+	"github.com/openshift/library-go/pkg/controller/factory"
+)
+
+func TestLeaderChangeHandler(t *testing.T) {
+	handler := LeaderChangeHandler("leader-test")
+	for _, isSelf := range []bool{true, false} {
+		handler(leaderelection.LeaderStatus{Identity: "pod", IsSelf: isSelf})
+		expected := 0.0
+		if isSelf {
+			expected = 1
+		}
+		if value, err := testutil.GetGaugeMetricValue(leaderMetric.WithLabelValues("leader-test")); err != nil || value != expected {
+			t.Errorf("expected the leader metric to be %v, got %v (%v)", expected, value, err)
+		}
+	}
+}
+
+func TestWithReconcileMetrics(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Now())
+	sync := withReconcileMetrics(clock, "reconcile-test", "FooController", func(ctx context.Context, syncCtx factory.SyncContext) error {
+		clock.Step(2 * time.Second)
+		return errors.New("failed")
+	})
+	if err := sync(context.TODO(), nil); err == nil {
+		t.Fatal("expected the error of the sync to be returned")
+	}
+
+	count, err := testutil.GetHistogramMetricCount(reconcileDurationMetric.WithLabelValues("reconcile-test", "FooController", resultError))
+	if err != nil || count != 1 {
+		t.Errorf("expected one failed sync, got %d (%v)", count, err)
+	}
+	sum, err := testutil.GetHistogramMetricValue(reconcileDurationMetric.WithLabelValues("reconcile-test", "FooController", resultError))
+	if err != nil || sum != 2 {
+		t.Errorf("expected a sync of 2 seconds, got %v (%v)", sum, err)
+	}
+}
+
+func TestDegradedTimeRecorder(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Now())
+	recorder := &DegradedTimeRecorder{operator: "degraded-test", clock: clock, degraded: sets.New[string]()}
+	degraded := []operatorv1.OperatorCondition{
+		{Type: "FooDegraded", Status: operatorv1.ConditionTrue},
+		{Type: "BarDegraded", Status: operatorv1.ConditionFalse},
+		{Type: "FooProgressing", Status: operatorv1.ConditionTrue},
+	}
+
+	recorder.Observe(degraded)
+	clock.Step(30 * time.Second)
+	recorder.Observe(degraded)
+	clock.Step(10 * time.Second)
+	recorder.Observe(nil)
+	clock.Step(time.Minute)
+	recorder.Observe(degraded)
+
+	for conditionType, expected := range map[string]float64{"FooDegraded": 40, "BarDegraded": 0, "FooProgressing": 0} {
+		if value, err := testutil.GetCounterMetricValue(degradedSecondsMetric.WithLabelValues("degraded-test", conditionType)); err != nil || value != expected {
+			t.Errorf("expected %s to be degraded for %v seconds, got %v (%v)", conditionType, expected, value, err)
+		}
+	}
+}
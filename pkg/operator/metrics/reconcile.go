@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/utils/clock"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+)
+
+const (
+	resultSuccess = "success"
+	resultError   = "error"
+)
+
+// ObserveReconcile reports the duration of a sync of the controller and whether it failed.
+func ObserveReconcile(operator, controller string, duration time.Duration, err error) {
+	result := resultSuccess
+	if err != nil {
+		result = resultError
+	}
+	reconcileDurationMetric.WithLabelValues(operator, controller, result).Observe(duration.Seconds())
+}
+
+// WithReconcileMetrics wraps the sync function of a controller to report the duration of its syncs, e.g.
+//
+//	factory.New().WithSync(metrics.WithReconcileMetrics("foo-operator", "FooController", c.sync))
+func WithReconcileMetrics(operator, controller string, sync factory.SyncFunc) factory.SyncFunc {
+	return withReconcileMetrics(clock.RealClock{}, operator, controller, sync)
+}
+
+func withReconcileMetrics(clock clock.PassiveClock, operator, controller string, sync factory.SyncFunc) factory.SyncFunc {
+	return func(ctx context.Context, syncCtx factory.SyncContext) error {
+		start := clock.Now()
+		err := sync(ctx, syncCtx)
+		ObserveReconcile(operator, controller, clock.Since(start), err)
+		return err
+	}
+}
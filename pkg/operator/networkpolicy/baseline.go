@@ -0,0 +1,131 @@
+// Package networkpolicy applies a restrictive baseline of network policies to the operator and operand namespaces:
+// all traffic is denied except the egress to the kube-apiserver and DNS, and the ingress of metrics scraping.
+package networkpolicy
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// DefaultDenyPolicyName is the name of the policy denying all traffic not allowed by other policies.
+	DefaultDenyPolicyName = "default-deny"
+	// AllowAPIServerPolicyName is the name of the policy allowing the egress to the kube-apiserver.
+	AllowAPIServerPolicyName = "allow-egress-to-api-server"
+	// AllowDNSPolicyName is the name of the policy allowing the egress to the cluster DNS.
+	AllowDNSPolicyName = "allow-egress-to-dns"
+	// AllowMetricsPolicyName is the name of the policy allowing the monitoring stack to scrape metrics.
+	AllowMetricsPolicyName = "allow-ingress-to-metrics"
+
+	// BaselineLabel is set on all policies of the baseline.
+	BaselineLabel = "networkpolicy.operator.openshift.io/baseline"
+
+	// DefaultAPIServerPort is the port of the kube-apiserver on the control plane nodes.
+	DefaultAPIServerPort = 6443
+	// DefaultDNSNamespace is the namespace of the cluster DNS.
+	DefaultDNSNamespace = "openshift-dns"
+	// DefaultMonitoringNamespace is the namespace of the monitoring stack scraping the metrics.
+	DefaultMonitoringNamespace = "openshift-monitoring"
+)
+
+// Baseline describes the network policies of the operator and operand namespaces.
+type Baseline struct {
+	// Namespaces the baseline is applied to, usually the operator and the operand namespaces.
+	Namespaces []string
+
+	// APIServerPorts the pods connect to. Empty means DefaultAPIServerPort. The kube-apiserver runs in the host
+	// network, so the egress is allowed to these ports on any destination.
+	APIServerPorts []int32
+	// DNSNamespace is the namespace of the cluster DNS. Empty means DefaultDNSNamespace.
+	DNSNamespace string
+	// MonitoringNamespace is the namespace the metrics are scraped from. Empty means DefaultMonitoringNamespace.
+	MonitoringNamespace string
+	// MetricsPorts are the ports the metrics are served on. Empty means no metrics are scraped and no ingress is
+	// allowed at all.
+	MetricsPorts []int32
+}
+
+// Policies returns the network policies of the baseline for all namespaces.
+func (b Baseline) Policies() []*networkingv1.NetworkPolicy {
+	var policies []*networkingv1.NetworkPolicy
+	for _, namespace := range b.Namespaces {
+		policies = append(policies, b.policiesFor(namespace)...)
+	}
+	return policies
+}
+
+func (b Baseline) policiesFor(namespace string) []*networkingv1.NetworkPolicy {
+	apiServerPorts := b.APIServerPorts
+	if len(apiServerPorts) == 0 {
+		apiServerPorts = []int32{DefaultAPIServerPort}
+	}
+	dnsNamespace := b.DNSNamespace
+	if len(dnsNamespace) == 0 {
+		dnsNamespace = DefaultDNSNamespace
+	}
+	monitoringNamespace := b.MonitoringNamespace
+	if len(monitoringNamespace) == 0 {
+		monitoringNamespace = DefaultMonitoringNamespace
+	}
+
+	policies := []*networkingv1.NetworkPolicy{
+		newPolicy(namespace, DefaultDenyPolicyName, networkingv1.NetworkPolicySpec{
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		}),
+		newPolicy(namespace, AllowAPIServerPolicyName, networkingv1.NetworkPolicySpec{
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress: []networkingv1.NetworkPolicyEgressRule{{
+				Ports: ports(corev1.ProtocolTCP, apiServerPorts...),
+			}},
+		}),
+		newPolicy(namespace, AllowDNSPolicyName, networkingv1.NetworkPolicySpec{
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress: []networkingv1.NetworkPolicyEgressRule{{
+				To: []networkingv1.NetworkPolicyPeer{namespacePeer(dnsNamespace)},
+				// the DNS pods listen on 5353, the service maps 53 to it
+				Ports: append(ports(corev1.ProtocolTCP, 53, 5353), ports(corev1.ProtocolUDP, 53, 5353)...),
+			}},
+		}),
+	}
+	if len(b.MetricsPorts) > 0 {
+		policies = append(policies, newPolicy(namespace, AllowMetricsPolicyName, networkingv1.NetworkPolicySpec{
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From:  []networkingv1.NetworkPolicyPeer{namespacePeer(monitoringNamespace)},
+				Ports: ports(corev1.ProtocolTCP, b.MetricsPorts...),
+			}},
+		}))
+	}
+	return policies
+}
+
+func newPolicy(namespace, name string, spec networkingv1.NetworkPolicySpec) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    map[string]string{BaselineLabel: "true"},
+		},
+		// the empty pod selector of the spec selects all pods of the namespace
+		Spec: spec,
+	}
+}
+
+func namespacePeer(namespace string) networkingv1.NetworkPolicyPeer {
+	return networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{corev1.LabelMetadataName: namespace},
+		},
+	}
+}
+
+func ports(protocol corev1.Protocol, numbers ...int32) []networkingv1.NetworkPolicyPort {
+	var ret []networkingv1.NetworkPolicyPort
+	for _, number := range numbers {
+		protocol, port := protocol, intstr.FromInt32(number)
+		ret = append(ret, networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &port})
+	}
+	return ret
+}
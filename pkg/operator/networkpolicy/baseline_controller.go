@@ -0,0 +1,116 @@
+package networkpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/management"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// BaselineController applies the network policies of the baseline. Cluster admins opt out by setting
+//
+//	unsupportedConfigOverrides:
+//	  operator:
+//	    disableNetworkPolicyBaseline: "True"
+//
+// in the operator spec, which removes the policies of the baseline again.
+type BaselineController struct {
+	baseline Baseline
+
+	operatorClient v1helpers.OperatorClient
+	kubeClient     kubernetes.Interface
+	cache          resourceapply.ResourceCache
+}
+
+type unsupportedConfigOverrides struct {
+	Operator struct {
+		DisableNetworkPolicyBaseline string `json:"disableNetworkPolicyBaseline"`
+	} `json:"operator"`
+}
+
+// NewBaselineController returns a controller applying the network policies of the baseline. The informers must contain
+// the namespaces of the baseline.
+func NewBaselineController(
+	instanceName string,
+	baseline Baseline,
+	operatorClient v1helpers.OperatorClient,
+	kubeClient kubernetes.Interface,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &BaselineController{
+		baseline:       baseline,
+		operatorClient: operatorClient,
+		kubeClient:     kubeClient,
+		cache:          resourceapply.NewResourceCache(),
+	}
+
+	names := sets.New[string]()
+	informers := []factory.Informer{operatorClient.Informer()}
+	for _, policy := range baseline.Policies() {
+		names.Insert(policy.Name)
+	}
+	for _, namespace := range baseline.Namespaces {
+		informers = append(informers, kubeInformersForNamespaces.InformersFor(namespace).Networking().V1().NetworkPolicies().Informer())
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers(factory.NamesFilter(sets.List(names)...), informers...).
+		WithSync(c.sync).
+		WithSyncDegradedOnError(operatorClient).
+		ResyncEvery(time.Minute).
+		ToController(factory.ControllerInstanceName(instanceName, "NetworkPolicyBaseline"), eventRecorder.WithComponentSuffix("network-policy-baseline-controller"))
+}
+
+func (c *BaselineController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	operatorSpec, _, _, err := c.operatorClient.GetOperatorState()
+	if err != nil {
+		return err
+	}
+	if !management.IsOperatorManaged(operatorSpec.ManagementState) {
+		return nil
+	}
+
+	disabled, err := optedOut(operatorSpec)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, policy := range c.baseline.Policies() {
+		if disabled {
+			_, _, err = resourceapply.DeleteNetworkPolicy(ctx, c.kubeClient.NetworkingV1(), syncCtx.Recorder(), policy)
+		} else {
+			_, _, err = resourceapply.ApplyNetworkPolicy(ctx, c.kubeClient.NetworkingV1(), syncCtx.Recorder(), policy, c.cache)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func optedOut(operatorSpec *operatorv1.OperatorSpec) (bool, error) {
+	overrides := unsupportedConfigOverrides{}
+	if raw := operatorSpec.UnsupportedConfigOverrides.Raw; len(raw) > 0 {
+		jsonRaw, err := kyaml.ToJSON(raw)
+		if err != nil {
+			return false, err
+		}
+		if err := json.Unmarshal(jsonRaw, &overrides); err != nil {
+			return false, err
+		}
+	}
+	return overrides.Operator.DisableNetworkPolicyBaseline == "True", nil
+}
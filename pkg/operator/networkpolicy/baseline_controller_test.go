@@ -0,0 +1,90 @@
+package networkpolicy
+
+import (
+	"context"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestBaselinePolicies(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		baseline Baseline
+		expected []string
+	}{
+		{
+			name:     "without metrics",
+			baseline: Baseline{Namespaces: []string{"operator"}},
+			expected: []string{"operator/default-deny", "operator/allow-egress-to-api-server", "operator/allow-egress-to-dns"},
+		},
+		{
+			name:     "with metrics",
+			baseline: Baseline{Namespaces: []string{"operator", "operand"}, MetricsPorts: []int32{8443}},
+			expected: []string{
+				"operator/default-deny", "operator/allow-egress-to-api-server", "operator/allow-egress-to-dns", "operator/allow-ingress-to-metrics",
+				"operand/default-deny", "operand/allow-egress-to-api-server", "operand/allow-egress-to-dns", "operand/allow-ingress-to-metrics",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			policies := tc.baseline.Policies()
+			if len(policies) != len(tc.expected) {
+				t.Fatalf("expected %d policies, got %d", len(tc.expected), len(policies))
+			}
+			for i, policy := range policies {
+				if name := policy.Namespace + "/" + policy.Name; name != tc.expected[i] {
+					t.Errorf("expected policy %s, got %s", tc.expected[i], name)
+				}
+				if policy.Labels[BaselineLabel] != "true" {
+					t.Errorf("expected policy %s to be labeled", policy.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestBaselineController(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	operatorSpec := &operatorv1.OperatorSpec{ManagementState: operatorv1.Managed}
+	c := &BaselineController{
+		baseline:       Baseline{Namespaces: []string{"operator", "operand"}, MetricsPorts: []int32{8443}},
+		operatorClient: v1helpers.NewFakeOperatorClient(operatorSpec, &operatorv1.OperatorStatus{}, nil),
+		kubeClient:     kubeClient,
+		cache:          resourceapply.NewResourceCache(),
+	}
+	syncContext := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+
+	if err := c.sync(context.TODO(), syncContext); err != nil {
+		t.Fatal(err)
+	}
+	if policies := listPolicies(t, kubeClient); len(policies) != 8 {
+		t.Fatalf("expected 8 policies, got %d", len(policies))
+	}
+
+	operatorSpec.UnsupportedConfigOverrides = runtime.RawExtension{Raw: []byte(`{"operator":{"disableNetworkPolicyBaseline":"True"}}`)}
+	if err := c.sync(context.TODO(), syncContext); err != nil {
+		t.Fatal(err)
+	}
+	if policies := listPolicies(t, kubeClient); len(policies) != 0 {
+		t.Fatalf("expected the policies to be removed after the opt-out, got %d", len(policies))
+	}
+}
+
+func listPolicies(t *testing.T, kubeClient *fake.Clientset) []networkingv1.NetworkPolicy {
+	t.Helper()
+	policies, err := kubeClient.NetworkingV1().NetworkPolicies(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return policies.Items
+}
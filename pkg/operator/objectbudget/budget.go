@@ -0,0 +1,116 @@
+// Package objectbudget enforces size budgets on what controllers write, so that oversized objects are truncated with
+// an explicit marker or rejected with a clear error instead of failing in etcd with an opaque apply error.
+package objectbudget
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ConditionMessageBudget is the maximum length of an operator condition message in bytes.
+	ConditionMessageBudget = 4096
+	// DataBudget is the maximum size of the data of a configmap or secret accepted by the API server.
+	DataBudget = 1024 * 1024
+
+	truncationMarker = "... [truncated %d bytes]"
+)
+
+// RevisionPayloadBudget is the maximum total size of the configmaps and secrets copied into a revision.
+var RevisionPayloadBudget = 8 * 1024 * 1024
+
+// ExceededError is returned when an object is larger than its budget.
+type ExceededError struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Size      int
+	Budget    int
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("%s %s/%s is %d bytes, exceeding the budget of %d bytes", e.Kind, e.Namespace, e.Name, e.Size, e.Budget)
+}
+
+// Truncate shortens s to at most budget bytes including a marker with the number of dropped bytes. It does not split
+// multi-byte characters. The second return value is true if s was truncated.
+func Truncate(s string, budget int) (string, bool) {
+	if len(s) <= budget {
+		return s, false
+	}
+	// the marker is longest when the number of dropped bytes has the most digits
+	keep := budget - len(fmt.Sprintf(truncationMarker, len(s)))
+	if keep < 0 {
+		keep = 0
+	}
+	for keep > 0 && !utf8.RuneStart(s[keep]) {
+		keep--
+	}
+	return s[:keep] + fmt.Sprintf(truncationMarker, len(s)-keep), true
+}
+
+// TruncateConditionMessage shortens the message of an operator condition to ConditionMessageBudget.
+func TruncateConditionMessage(message string) string {
+	truncated, ok := Truncate(message, ConditionMessageBudget)
+	if ok {
+		recordTruncation("condition")
+	}
+	return truncated
+}
+
+// ConfigMapSize returns the size of the data of the configmap.
+func ConfigMapSize(configMap *corev1.ConfigMap) int {
+	size := 0
+	for key, value := range configMap.Data {
+		size += len(key) + len(value)
+	}
+	for key, value := range configMap.BinaryData {
+		size += len(key) + len(value)
+	}
+	return size
+}
+
+// SecretSize returns the size of the data of the secret, including the string data.
+func SecretSize(secret *corev1.Secret) int {
+	size := 0
+	for key, value := range secret.Data {
+		size += len(key) + len(value)
+	}
+	for key, value := range secret.StringData {
+		size += len(key) + len(value)
+	}
+	return size
+}
+
+// CheckConfigMap returns an ExceededError if the data of the configmap exceeds DataBudget.
+func CheckConfigMap(configMap *corev1.ConfigMap) error {
+	return check("configmap", configMap.Namespace, configMap.Name, ConfigMapSize(configMap), DataBudget)
+}
+
+// CheckSecret returns an ExceededError if the data of the secret exceeds DataBudget.
+func CheckSecret(secret *corev1.Secret) error {
+	return check("secret", secret.Namespace, secret.Name, SecretSize(secret), DataBudget)
+}
+
+// CheckRevisionPayload returns an ExceededError if the total size of the configmaps and secrets of a revision exceeds
+// RevisionPayloadBudget.
+func CheckRevisionPayload(namespace, revisionName string, configMaps []*corev1.ConfigMap, secrets []*corev1.Secret) error {
+	size := 0
+	for _, configMap := range configMaps {
+		size += ConfigMapSize(configMap)
+	}
+	for _, secret := range secrets {
+		size += SecretSize(secret)
+	}
+	return check("revision", namespace, revisionName, size, RevisionPayloadBudget)
+}
+
+func check(kind, namespace, name string, size, budget int) error {
+	if size <= budget {
+		return nil
+	}
+	recordExceeded(kind)
+	return &ExceededError{Kind: kind, Namespace: namespace, Name: name, Size: size, Budget: budget}
+}
@@ -0,0 +1,64 @@
+package objectbudget
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTruncate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		input  string
+		budget int
+		expect string
+	}{
+		{name: "within budget", input: "short", budget: 10, expect: "short"},
+		{name: "truncated", input: strings.Repeat("a", 100), budget: 40, expect: strings.Repeat("a", 15) + "... [truncated 85 bytes]"},
+		{name: "multi-byte characters are not split", input: strings.Repeat("ä", 50), budget: 40, expect: strings.Repeat("ä", 7) + "... [truncated 86 bytes]"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, truncated := Truncate(tc.input, tc.budget)
+			if got != tc.expect {
+				t.Errorf("expected %q, got %q", tc.expect, got)
+			}
+			if truncated != (tc.input != tc.expect) {
+				t.Errorf("unexpected truncated %v", truncated)
+			}
+			if len(got) > tc.budget || !utf8.ValidString(got) {
+				t.Errorf("invalid result %q", got)
+			}
+		})
+	}
+
+	if message := TruncateConditionMessage(strings.Repeat("x", 2*ConditionMessageBudget)); len(message) > ConditionMessageBudget {
+		t.Errorf("expected the condition message to be truncated to %d bytes, got %d", ConditionMessageBudget, len(message))
+	}
+}
+
+func TestCheck(t *testing.T) {
+	small := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "small"}, Data: map[string]string{"key": "value"}}
+	large := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "large"}, Data: map[string]string{"key": strings.Repeat("x", DataBudget)}}
+	if err := CheckConfigMap(small); err != nil {
+		t.Error(err)
+	}
+	var exceeded *ExceededError
+	if err := CheckConfigMap(large); !errors.As(err, &exceeded) || exceeded.Name != "large" || exceeded.Size != DataBudget+3 {
+		t.Errorf("expected the budget to be exceeded, got %v", err)
+	}
+	if err := CheckSecret(&corev1.Secret{StringData: map[string]string{"key": strings.Repeat("x", DataBudget)}}); err == nil {
+		t.Error("expected string data to count")
+	}
+
+	configMaps := []*corev1.ConfigMap{large, large, large, large, large, large, large, large}
+	if err := CheckRevisionPayload("ns", "revision-1", configMaps[:1], nil); err != nil {
+		t.Error(err)
+	}
+	if err := CheckRevisionPayload("ns", "revision-1", configMaps, nil); !errors.As(err, &exceeded) || exceeded.Kind != "revision" {
+		t.Errorf("expected the revision payload budget to be exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package objectbudget
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	truncationsMetric = metrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      "object_budget",
+		Name:           "truncations_total",
+		Help:           "Number of values truncated to fit their size budget.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"kind"})
+
+	exceededMetric = metrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      "object_budget",
+		Name:           "exceeded_total",
+		Help:           "Number of objects rejected because they exceed their size budget.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"kind"})
+)
+
+func init() {
+	(&sync.Once{}).Do(func() {
+		legacyregistry.MustRegister(truncationsMetric)
+		legacyregistry.MustRegister(exceededMetric)
+	})
+}
+
+func recordTruncation(kind string) {
+	truncationsMetric.WithLabelValues(kind).Inc()
+}
+
+func recordExceeded(kind string) {
+	exceededMetric.WithLabelValues(kind).Inc()
+}
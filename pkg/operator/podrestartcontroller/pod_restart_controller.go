@@ -0,0 +1,280 @@
+package podrestartcontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	coreinformersv1 "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	corev1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// RestartRequester tells since when the operand pods must run to pick up the current certificates or config. The pods
+// created before are restarted, a zero time requests no restart.
+type RestartRequester interface {
+	RestartRequiredSince(ctx context.Context) (time.Time, error)
+}
+
+// RestartRequesterFunc is a function implementing RestartRequester.
+type RestartRequesterFunc func(ctx context.Context) (time.Time, error)
+
+func (f RestartRequesterFunc) RestartRequiredSince(ctx context.Context) (time.Time, error) {
+	return f(ctx)
+}
+
+// Option configures the PodRestartController.
+type Option func(*PodRestartController)
+
+// WithMaxUnavailable sets how many operand pods may be unavailable at once, including the restarting ones. It
+// defaults to 1.
+func WithMaxUnavailable(maxUnavailable int) Option {
+	return func(c *PodRestartController) {
+		c.maxUnavailable = maxUnavailable
+	}
+}
+
+// WithMinReadySeconds sets how long a pod must be ready to be available, it should match the workload of the pods.
+func WithMinReadySeconds(minReadySeconds int32) Option {
+	return func(c *PodRestartController) {
+		c.minReadySeconds = minReadySeconds
+	}
+}
+
+// WithTopology restarts the pods one topology domain at a time, the domain of a pod is the value of the topologyKey
+// label of its node, e.g. topology.kubernetes.io/zone. Without it, the pods are restarted in the order of their nodes.
+func WithTopology(nodeInformer coreinformersv1.NodeInformer, topologyKey string) Option {
+	return func(c *PodRestartController) {
+		c.nodeLister = nodeInformer.Lister()
+		c.topologyKey = topologyKey
+		c.informers = append(c.informers, nodeInformer.Informer())
+	}
+}
+
+// WithClock sets the clock the availability of the pods is checked with.
+func WithClock(clock clock.PassiveClock) Option {
+	return func(c *PodRestartController) {
+		c.clock = clock
+	}
+}
+
+// PodRestartController restarts the operand pods created before the time requested by a RestartRequester, e.g. to
+// reload certificates or config the operand does not reload on its own. The pods are evicted, so PodDisruptionBudgets
+// are respected, and no more than maxUnavailable pods are unavailable at once. The workload of the pods recreates them.
+//
+// The progress is reported in the <name>PodRestartProgressing condition, the failures to evict pods in the
+// <name>PodRestartDegraded condition.
+type PodRestartController struct {
+	controllerInstanceName string
+	conditionType          string
+	namespace              string
+	podSelector            labels.Selector
+	requester              RestartRequester
+	maxUnavailable         int
+	minReadySeconds        int32
+	topologyKey            string
+
+	operatorClient v1helpers.OperatorClient
+	kubeClient     kubernetes.Interface
+	podLister      corev1lister.PodLister
+	nodeLister     corev1lister.NodeLister
+	informers      []factory.Informer
+	clock          clock.PassiveClock
+}
+
+// NewPodRestartController returns a controller restarting the pods of the namespace matching the selector.
+func NewPodRestartController(
+	name string,
+	namespace string,
+	podSelector *metav1.LabelSelector,
+	requester RestartRequester,
+	operatorClient v1helpers.OperatorClient,
+	kubeClient kubernetes.Interface,
+	podInformer coreinformersv1.PodInformer,
+	recorder events.Recorder,
+	options ...Option,
+) factory.Controller {
+	selector, err := metav1.LabelSelectorAsSelector(podSelector)
+	if err != nil {
+		panic(err)
+	}
+
+	c := &PodRestartController{
+		controllerInstanceName: factory.ControllerInstanceName(name, "PodRestart"),
+		conditionType:          name + "PodRestartProgressing",
+		namespace:              namespace,
+		podSelector:            selector,
+		requester:              requester,
+		maxUnavailable:         1,
+		operatorClient:         operatorClient,
+		kubeClient:             kubeClient,
+		podLister:              podInformer.Lister(),
+		informers:              []factory.Informer{podInformer.Informer(), operatorClient.Informer()},
+		clock:                  clock.RealClock{},
+	}
+	for _, option := range options {
+		option(c)
+	}
+
+	return factory.New().
+		WithInformers(c.informers...).
+		WithSync(c.sync).
+		ResyncEvery(30*time.Second).
+		WithSyncDegradedOnError(operatorClient).
+		WithControllerInstanceName(c.controllerInstanceName).
+		ToController(
+			name+"PodRestart",
+			recorder.WithComponentSuffix(strings.ToLower(name)+"-pod-restart"),
+		)
+}
+
+type restartCandidate struct {
+	pod       *corev1.Pod
+	domain    string
+	available bool
+}
+
+func (c *PodRestartController) sync(ctx context.Context, syncContext factory.SyncContext) error {
+	since, err := c.requester.RestartRequiredSince(ctx)
+	if err != nil {
+		return err
+	}
+	pods, err := c.podLister.Pods(c.namespace).List(c.podSelector)
+	if err != nil {
+		return err
+	}
+
+	now := metav1.NewTime(c.clock.Now())
+	unavailable := 0
+	var candidates []restartCandidate
+	for _, pod := range pods {
+		// terminating pods are being restarted already
+		if pod.DeletionTimestamp != nil {
+			unavailable++
+			continue
+		}
+		available := isPodAvailable(pod, c.minReadySeconds, now)
+		if !available {
+			unavailable++
+		}
+		if !since.IsZero() && pod.CreationTimestamp.Time.Before(since) {
+			candidates = append(candidates, restartCandidate{pod: pod, domain: c.topologyDomain(pod), available: available})
+		}
+	}
+
+	cond := applyoperatorv1.OperatorCondition().
+		WithType(c.conditionType).
+		WithStatus(operatorv1.ConditionFalse).
+		WithReason("AsExpected")
+	if len(candidates) == 0 {
+		return c.operatorClient.ApplyOperatorStatus(ctx, c.controllerInstanceName, applyoperatorv1.OperatorStatus().WithConditions(cond))
+	}
+
+	// restart a single topology domain at a time, starting with the unavailable pods as they cost no availability
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].domain != candidates[j].domain {
+			return candidates[i].domain < candidates[j].domain
+		}
+		if candidates[i].available != candidates[j].available {
+			return !candidates[i].available
+		}
+		if candidates[i].pod.Spec.NodeName != candidates[j].pod.Spec.NodeName {
+			return candidates[i].pod.Spec.NodeName < candidates[j].pod.Spec.NodeName
+		}
+		return candidates[i].pod.Name < candidates[j].pod.Name
+	})
+	domain := candidates[0].domain
+	budget := c.maxUnavailable - unavailable
+
+	var errs []error
+	blocked := false
+	for _, candidate := range candidates {
+		if candidate.domain != domain {
+			break
+		}
+		if candidate.available {
+			if budget <= 0 {
+				break
+			}
+			budget--
+		}
+		pod := candidate.pod
+		err := c.kubeClient.CoreV1().Pods(pod.Namespace).EvictV1(ctx, &policyv1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{Namespace: pod.Namespace, Name: pod.Name},
+		})
+		switch {
+		case err == nil:
+			syncContext.Recorder().Eventf("PodEvicted", "Evicted pod/%s on node/%s to restart it, it was created before %s", pod.Name, pod.Spec.NodeName, since.UTC().Format(time.RFC3339))
+		case apierrors.IsNotFound(err):
+		case apierrors.IsTooManyRequests(err):
+			// the eviction would violate a PodDisruptionBudget, try again later
+			klog.V(2).Infof("Eviction of pod %s/%s is blocked: %v", pod.Namespace, pod.Name, err)
+			blocked = true
+		default:
+			errs = append(errs, fmt.Errorf("unable to evict pod %s/%s: %w", pod.Namespace, pod.Name, err))
+		}
+		if blocked {
+			break
+		}
+	}
+
+	message := fmt.Sprintf("%d of %d pods in %s are restarting to pick up the changes since %s", len(candidates), len(pods), c.namespace, since.UTC().Format(time.RFC3339))
+	if len(domain) > 0 {
+		message += fmt.Sprintf(", restarting the pods in %s", domain)
+	}
+	if blocked {
+		message += ", waiting for a PodDisruptionBudget to allow the eviction"
+	}
+	cond = cond.
+		WithStatus(operatorv1.ConditionTrue).
+		WithReason("RestartingPods").
+		WithMessage(message)
+	if err := c.operatorClient.ApplyOperatorStatus(ctx, c.controllerInstanceName, applyoperatorv1.OperatorStatus().WithConditions(cond)); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// topologyDomain returns the topology domain of the node of the pod, or an empty string for the controllers without
+// topology.
+func (c *PodRestartController) topologyDomain(pod *corev1.Pod) string {
+	if c.nodeLister == nil || len(pod.Spec.NodeName) == 0 {
+		return ""
+	}
+	node, err := c.nodeLister.Get(pod.Spec.NodeName)
+	if err != nil {
+		return ""
+	}
+	return node.Labels[c.topologyKey]
+}
+
+// isPodAvailable returns true when the pod has been ready for at least minReadySeconds.
+func isPodAvailable(pod *corev1.Pod, minReadySeconds int32, now metav1.Time) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type != corev1.PodReady {
+			continue
+		}
+		if condition.Status != corev1.ConditionTrue {
+			return false
+		}
+		minReadySecondsDuration := time.Duration(minReadySeconds) * time.Second
+		return minReadySeconds == 0 || (!condition.LastTransitionTime.IsZero() && condition.LastTransitionTime.Add(minReadySecondsDuration).Before(now.Time))
+	}
+	return false
+}
@@ -0,0 +1,215 @@
+package podrestartcontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1lister "k8s.io/client-go/listers/core/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestPodRestartController(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	restartSince := now.Add(-time.Hour)
+
+	pod := func(name, node string, created time.Time, ready bool, terminating bool) *corev1.Pod {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		ret := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "openshift-foo",
+				Name:              name,
+				Labels:            map[string]string{"app": "foo"},
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: status, LastTransitionTime: metav1.NewTime(created)},
+			}},
+		}
+		if terminating {
+			ret.DeletionTimestamp = &metav1.Time{Time: now}
+		}
+		return ret
+	}
+	node := func(name, zone string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{corev1.LabelTopologyZone: zone}}}
+	}
+	old := now.Add(-2 * time.Hour)
+
+	tests := []struct {
+		name              string
+		pods              []*corev1.Pod
+		maxUnavailable    int
+		evictionErr       error
+		expectedEvictions []string
+		expectedStatus    operatorv1.ConditionStatus
+		expectedMessage   string
+	}{
+		{
+			name:           "nothing to restart",
+			pods:           []*corev1.Pod{pod("a1", "n1", now.Add(-time.Minute), true, false)},
+			maxUnavailable: 1,
+			expectedStatus: operatorv1.ConditionFalse,
+		},
+		{
+			name: "restart one pod of the first zone",
+			pods: []*corev1.Pod{
+				pod("b1", "n3", old, true, false),
+				pod("a2", "n2", old, true, false),
+				pod("a1", "n1", old, true, false),
+			},
+			maxUnavailable:    1,
+			expectedEvictions: []string{"a1"},
+			expectedStatus:    operatorv1.ConditionTrue,
+			expectedMessage:   "3 of 3 pods in openshift-foo are restarting to pick up the changes since 2024-01-01T09:00:00Z, restarting the pods in zone-a",
+		},
+		{
+			name: "restart the unavailable pods first and stay within the zone",
+			pods: []*corev1.Pod{
+				pod("b1", "n3", old, true, false),
+				pod("a2", "n2", old, false, false),
+				pod("a1", "n1", old, true, false),
+			},
+			maxUnavailable:    3,
+			expectedEvictions: []string{"a2", "a1"},
+			expectedStatus:    operatorv1.ConditionTrue,
+		},
+		{
+			name: "wait for the terminating pods",
+			pods: []*corev1.Pod{
+				pod("a1", "n1", old, true, true),
+				pod("a2", "n2", old, true, false),
+			},
+			maxUnavailable: 1,
+			expectedStatus: operatorv1.ConditionTrue,
+		},
+		{
+			name: "eviction blocked by a PodDisruptionBudget",
+			pods: []*corev1.Pod{
+				pod("a1", "n1", old, true, false),
+				pod("a2", "n2", old, true, false),
+			},
+			maxUnavailable:    2,
+			evictionErr:       apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 10),
+			expectedEvictions: []string{"a1"},
+			expectedStatus:    operatorv1.ConditionTrue,
+			expectedMessage:   "2 of 2 pods in openshift-foo are restarting to pick up the changes since 2024-01-01T09:00:00Z, restarting the pods in zone-a, waiting for a PodDisruptionBudget to allow the eviction",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			podIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, p := range test.pods {
+				if err := podIndexer.Add(p); err != nil {
+					t.Fatal(err)
+				}
+			}
+			nodeIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, n := range []*corev1.Node{node("n1", "zone-a"), node("n2", "zone-a"), node("n3", "zone-b")} {
+				if err := nodeIndexer.Add(n); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			kubeClient := fake.NewSimpleClientset()
+			var evictions []string
+			kubeClient.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				evictions = append(evictions, action.(clienttesting.CreateAction).GetObject().(*policyv1.Eviction).Name)
+				return true, nil, test.evictionErr
+			})
+			operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{ManagementState: operatorv1.Managed}, &operatorv1.OperatorStatus{}, nil)
+
+			c := &PodRestartController{
+				controllerInstanceName: "foo-PodRestart",
+				conditionType:          "FooPodRestartProgressing",
+				namespace:              "openshift-foo",
+				podSelector:            labels.SelectorFromSet(labels.Set{"app": "foo"}),
+				requester: RestartRequesterFunc(func(context.Context) (time.Time, error) {
+					return restartSince, nil
+				}),
+				maxUnavailable: test.maxUnavailable,
+				topologyKey:    corev1.LabelTopologyZone,
+				operatorClient: operatorClient,
+				kubeClient:     kubeClient,
+				podLister:      corev1lister.NewPodLister(podIndexer),
+				nodeLister:     corev1lister.NewNodeLister(nodeIndexer),
+				clock:          clocktesting.NewFakePassiveClock(now),
+			}
+			if err := c.sync(context.TODO(), factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))); err != nil {
+				t.Fatal(err)
+			}
+
+			if fmt.Sprint(evictions) != fmt.Sprint(test.expectedEvictions) {
+				t.Errorf("expected evictions %v, got %v", test.expectedEvictions, evictions)
+			}
+			_, status, _, err := operatorClient.GetOperatorState()
+			if err != nil {
+				t.Fatal(err)
+			}
+			cond := v1helpers.FindOperatorCondition(status.Conditions, "FooPodRestartProgressing")
+			if cond == nil || cond.Status != test.expectedStatus {
+				t.Fatalf("expected status %s, got %v", test.expectedStatus, cond)
+			}
+			if len(test.expectedMessage) > 0 && cond.Message != test.expectedMessage {
+				t.Errorf("expected message %q, got %q", test.expectedMessage, cond.Message)
+			}
+		})
+	}
+}
+
+func TestPodRestartControllerEvictionError(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-foo", Name: "a1", Labels: map[string]string{"app": "foo"}},
+		Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+	}
+	podIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	if err := podIndexer.Add(pod); err != nil {
+		t.Fatal(err)
+	}
+	kubeClient := fake.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(corev1.Resource("pods"), "a1", fmt.Errorf("not allowed"))
+	})
+
+	c := &PodRestartController{
+		conditionType: "FooPodRestartProgressing",
+		namespace:     "openshift-foo",
+		podSelector:   labels.Everything(),
+		requester: RestartRequesterFunc(func(context.Context) (time.Time, error) {
+			return time.Now(), nil
+		}),
+		maxUnavailable: 1,
+		operatorClient: v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil),
+		kubeClient:     kubeClient,
+		podLister:      corev1lister.NewPodLister(podIndexer),
+		clock:          clocktesting.NewFakePassiveClock(time.Now()),
+	}
+	err := c.sync(context.TODO(), factory.NewSyncContext("test", events.NewInMemoryRecorder("test")))
+	if err == nil || !strings.Contains(err.Error(), "unable to evict pod openshift-foo/a1") {
+		t.Errorf("expected the eviction error, got %v", err)
+	}
+}
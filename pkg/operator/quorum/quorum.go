@@ -0,0 +1,97 @@
+// Package quorum provides preconditions blocking disruptive actions, like rolling out a new revision of a static pod,
+// while they would endanger the quorum of a consensus based operand like etcd.
+package quorum
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// Member is a voting member of the quorum.
+type Member struct {
+	Name    string
+	Healthy bool
+	// NodeName is the node the member runs on.
+	NodeName string
+}
+
+// MemberLister lists the voting members of the quorum, e.g. the etcd members with their health.
+type MemberLister interface {
+	ListMembers(ctx context.Context) ([]Member, error)
+}
+
+// MemberListerFunc adapts a function to a MemberLister.
+type MemberListerFunc func(ctx context.Context) ([]Member, error)
+
+func (f MemberListerFunc) ListMembers(ctx context.Context) ([]Member, error) {
+	return f(ctx)
+}
+
+// CheckDisruption returns an error naming the unhealthy members if the quorum would be lost when the member on the
+// node were disrupted in addition to the unhealthy ones. Disrupting an unhealthy member, or a node without a member,
+// does not lose another healthy member, so the rollout repairing an unhealthy member is not blocked by its own
+// health. An empty nodeName stands for any single healthy member. Members that cannot tolerate the loss of any
+// member, i.e. one or two members, are never blocked, as their disruption is unavoidable.
+func CheckDisruption(members []Member, nodeName string) error {
+	if len(members) == 0 {
+		return fmt.Errorf("no members")
+	}
+	var unhealthy []string
+	disrupted := 1
+	for _, member := range members {
+		if !member.Healthy {
+			unhealthy = append(unhealthy, member.Name)
+			if len(nodeName) > 0 && member.NodeName == nodeName {
+				disrupted = 0
+			}
+		}
+	}
+	if len(nodeName) > 0 && !hasMemberOn(members, nodeName) {
+		disrupted = 0
+	}
+	quorum := len(members)/2 + 1
+	if len(members) == quorum {
+		return nil
+	}
+	if remaining := len(members) - len(unhealthy) - disrupted; remaining < quorum {
+		target := "a healthy member"
+		if len(nodeName) > 0 {
+			target = fmt.Sprintf("the member on node %q", nodeName)
+		}
+		if len(unhealthy) == 0 {
+			return fmt.Errorf("disrupting %s of %d members would leave %d healthy members, the quorum is %d", target, len(members), remaining, quorum)
+		}
+		return fmt.Errorf("disrupting %s of %d members would leave %d healthy members, the quorum is %d, unhealthy members: %s", target, len(members), remaining, quorum, strings.Join(unhealthy, ", "))
+	}
+	return nil
+}
+
+func hasMemberOn(members []Member, nodeName string) bool {
+	for _, member := range members {
+		if member.NodeName == nodeName {
+			return true
+		}
+	}
+	return false
+}
+
+// NewQuorumPrecondition returns a precondition met when the quorum of the listed members survives the disruption of
+// the member on the node, e.g. by the installer replacing the static pod on the node. An empty node name stands for
+// any single member, e.g. for the pruner pods. When it is not met, the returned message explains why. Pass it to
+// InstallerController.WithPrecondition or the WithDisruptionPrecondition of the static pod operator builder.
+func NewQuorumPrecondition(lister MemberLister) func(ctx context.Context, nodeName string) (bool, string, error) {
+	return func(ctx context.Context, nodeName string) (bool, string, error) {
+		members, err := lister.ListMembers(ctx)
+		if err != nil {
+			return false, "", err
+		}
+		if err := CheckDisruption(members, nodeName); err != nil {
+			klog.V(2).Infof("Quorum precondition not met: %v", err)
+			return false, err.Error(), nil
+		}
+		return true, "", nil
+	}
+}
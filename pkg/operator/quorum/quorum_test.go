@@ -0,0 +1,69 @@
+package quorum
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func members(healthy, unhealthy int) []Member {
+	var ret []Member
+	for i := 0; i < healthy; i++ {
+		ret = append(ret, Member{Name: fmt.Sprintf("healthy-%d", i), Healthy: true, NodeName: fmt.Sprintf("healthy-node-%d", i)})
+	}
+	for i := 0; i < unhealthy; i++ {
+		ret = append(ret, Member{Name: fmt.Sprintf("unhealthy-%d", i), NodeName: fmt.Sprintf("unhealthy-node-%d", i)})
+	}
+	return ret
+}
+
+func TestCheckDisruption(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		members     []Member
+		nodeName    string
+		expectError string
+	}{
+		{name: "no members", expectError: "no members"},
+		{name: "single member", members: members(1, 0)},
+		{name: "two members", members: members(1, 1)},
+		{name: "three healthy members", members: members(3, 0)},
+		{name: "three members, one unhealthy", members: members(2, 1), expectError: "unhealthy members: unhealthy-0"},
+		{name: "three members, healthy one disrupted", members: members(2, 1), nodeName: "healthy-node-0", expectError: `disrupting the member on node "healthy-node-0"`},
+		{name: "three members, unhealthy one disrupted", members: members(2, 1), nodeName: "unhealthy-node-0"},
+		{name: "three members, node without member disrupted", members: members(2, 1), nodeName: "other-node"},
+		{name: "five members, one unhealthy", members: members(4, 1)},
+		{name: "five members, two unhealthy", members: members(3, 2), expectError: "unhealthy members: unhealthy-0, unhealthy-1"},
+		{name: "five members, two unhealthy, unhealthy one disrupted", members: members(3, 2), nodeName: "unhealthy-node-1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckDisruption(tc.members, tc.nodeName)
+			switch {
+			case len(tc.expectError) == 0 && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case len(tc.expectError) > 0 && (err == nil || !strings.Contains(err.Error(), tc.expectError)):
+				t.Errorf("expected error containing %q, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestQuorumPrecondition(t *testing.T) {
+	var listed []Member
+	precondition := NewQuorumPrecondition(MemberListerFunc(func(ctx context.Context) ([]Member, error) {
+		return listed, nil
+	}))
+
+	listed = members(3, 0)
+	if met, message, err := precondition(context.TODO(), "healthy-node-0"); err != nil || !met || len(message) > 0 {
+		t.Errorf("expected the precondition to be met, got %v, %q, %v", met, message, err)
+	}
+	listed = members(2, 1)
+	if met, message, err := precondition(context.TODO(), "healthy-node-0"); err != nil || met || !strings.Contains(message, "unhealthy-0") {
+		t.Errorf("expected the precondition not to be met, got %v, %q, %v", met, message, err)
+	}
+	if met, _, err := precondition(context.TODO(), "unhealthy-node-0"); err != nil || !met {
+		t.Errorf("expected the repair of the unhealthy member to be allowed, got %v, %v", met, err)
+	}
+}
@@ -0,0 +1,279 @@
+// Package rbacgen generates the minimal RBAC manifests a set of library controllers needs. The controllers are built
+// with fake clients, their informers are started and every controller is synced once, then the requests made to the
+// fake clients are turned into a ClusterRole for the cluster scoped requests and a Role per namespace:
+//
+//	kubeClient := fake.NewSimpleClientset()
+//	kubeInformers := v1helpers.NewKubeInformersForNamespaces(kubeClient, "openshift-foo")
+//	controller := revisioncontroller.NewRevisionController(..., kubeClient.CoreV1(), ...)
+//
+//	manifests, err := rbacgen.NewGenerator(kubeClient).
+//	  WithInformers(kubeInformers).
+//	  WithControllers(controller).
+//	  Generate(ctx, "foo-operator")
+//	...
+//	manifests.Write(os.Stdout)
+//
+// A sync against empty fake clients usually stops at the first missing object, so seed the fake clients with the
+// objects the controllers expect and add the permissions of the code paths not taken with WithRules.
+package rbacgen
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// ActionSource is a fake client recording the requests made through it, e.g. the fake clientsets of client-go and of
+// the OpenShift APIs or the fake dynamic client.
+type ActionSource interface {
+	Actions() []clienttesting.Action
+}
+
+// InformerFactory is a factory of informers, e.g. informers.SharedInformerFactory or
+// v1helpers.KubeInformersForNamespaces.
+type InformerFactory interface {
+	Start(stopCh <-chan struct{})
+}
+
+// Generator generates the RBAC manifests of controllers built with fake clients.
+type Generator struct {
+	sources     []ActionSource
+	informers   []InformerFactory
+	controllers []factory.Controller
+	// rules are the rules added explicitly, by namespace
+	rules map[string][]rbacv1.PolicyRule
+}
+
+// NewGenerator returns a generator recording the requests made to the fake clients.
+func NewGenerator(sources ...ActionSource) *Generator {
+	return &Generator{
+		sources: sources,
+		rules:   map[string][]rbacv1.PolicyRule{},
+	}
+}
+
+// WithInformers adds the informer factories the controllers were built with. They are started before the controllers
+// are synced, so the list and watch requests of the informers the controllers use are recorded.
+func (g *Generator) WithInformers(informers ...InformerFactory) *Generator {
+	g.informers = append(g.informers, informers...)
+	return g
+}
+
+// WithControllers adds the controllers to sync.
+func (g *Generator) WithControllers(controllers ...factory.Controller) *Generator {
+	g.controllers = append(g.controllers, controllers...)
+	return g
+}
+
+// WithRules adds rules to the Role in the namespace, or to the ClusterRole for an empty namespace, for the
+// permissions the syncs did not exercise.
+func (g *Generator) WithRules(namespace string, rules ...rbacv1.PolicyRule) *Generator {
+	g.rules[namespace] = append(g.rules[namespace], rules...)
+	return g
+}
+
+// Manifests are the generated RBAC manifests.
+type Manifests struct {
+	// ClusterRole holds the rules of the cluster scoped requests and of the requests across all namespaces, it is nil
+	// when there are none.
+	ClusterRole *rbacv1.ClusterRole
+	// Roles hold the rules of the namespaced requests, sorted by namespace.
+	Roles []*rbacv1.Role
+}
+
+// Generate starts the informers, syncs every controller once and returns the manifests named name granting the
+// requests made to the fake clients. The sync errors are only logged, as syncs against fake clients often fail.
+func (g *Generator) Generate(ctx context.Context, name string) (*Manifests, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for _, informers := range g.informers {
+		informers.Start(ctx.Done())
+	}
+	for _, informers := range g.informers {
+		switch informers := informers.(type) {
+		case interface {
+			WaitForCacheSync(stopCh <-chan struct{}) map[reflect.Type]bool
+		}:
+			informers.WaitForCacheSync(ctx.Done())
+		case interface {
+			WaitForCacheSync(stopCh <-chan struct{}) map[string]map[reflect.Type]bool
+		}:
+			informers.WaitForCacheSync(ctx.Done())
+		}
+	}
+	// an informer watches right after it synced its cache, wait for the watches of all listed resources
+	if err := wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, 10*time.Second, true, func(context.Context) (bool, error) {
+		watched := map[resourceInNamespace]bool{}
+		for _, action := range g.actions() {
+			key := keyOf(action)
+			switch action.GetVerb() {
+			case "list":
+				if _, ok := watched[key]; !ok {
+					watched[key] = false
+				}
+			case "watch":
+				watched[key] = true
+			}
+		}
+		for _, ok := range watched {
+			if !ok {
+				return false, nil
+			}
+		}
+		return true, nil
+	}); err != nil {
+		return nil, fmt.Errorf("informers did not start: %w", err)
+	}
+
+	for _, controller := range g.controllers {
+		syncCtx := factory.NewSyncContext(controller.Name(), events.NewInMemoryRecorder(controller.Name()))
+		if err := controller.Sync(ctx, syncCtx); err != nil {
+			klog.V(2).Infof("Sync of %s failed, its permissions may be incomplete: %v", controller.Name(), err)
+		}
+	}
+
+	verbs := map[resourceInNamespace]sets.Set[string]{}
+	for _, action := range g.actions() {
+		key := keyOf(action)
+		if verbs[key] == nil {
+			verbs[key] = sets.New[string]()
+		}
+		verbs[key].Insert(rbacVerb(action.GetVerb()))
+	}
+	rules := map[string][]rbacv1.PolicyRule{}
+	for namespace, namespaceRules := range g.rules {
+		rules[namespace] = append(rules[namespace], namespaceRules...)
+	}
+	for namespace, namespaceRules := range policyRules(verbs) {
+		rules[namespace] = append(rules[namespace], namespaceRules...)
+	}
+
+	ret := &Manifests{}
+	namespaces := sets.List(sets.KeySet(rules))
+	for _, namespace := range namespaces {
+		if len(namespace) == 0 {
+			ret.ClusterRole = &rbacv1.ClusterRole{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Rules:      rules[namespace],
+			}
+			continue
+		}
+		ret.Roles = append(ret.Roles, &rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Rules:      rules[namespace],
+		})
+	}
+	return ret, nil
+}
+
+// Write writes the manifests as a YAML stream.
+func (m *Manifests) Write(w io.Writer) error {
+	var objs []interface{}
+	if m.ClusterRole != nil {
+		objs = append(objs, m.ClusterRole)
+	}
+	for _, role := range m.Roles {
+		objs = append(objs, role)
+	}
+	for i, obj := range objs {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Generator) actions() []clienttesting.Action {
+	var ret []clienttesting.Action
+	for _, source := range g.sources {
+		ret = append(ret, source.Actions()...)
+	}
+	return ret
+}
+
+type resourceInNamespace struct {
+	namespace string
+	group     string
+	resource  string
+}
+
+func keyOf(action clienttesting.Action) resourceInNamespace {
+	resource := action.GetResource().Resource
+	if len(action.GetSubresource()) > 0 {
+		resource += "/" + action.GetSubresource()
+	}
+	return resourceInNamespace{
+		namespace: action.GetNamespace(),
+		group:     action.GetResource().Group,
+		resource:  resource,
+	}
+}
+
+// rbacVerb returns the RBAC verb of the verb of a fake client action.
+func rbacVerb(verb string) string {
+	if verb == "delete-collection" {
+		return "deletecollection"
+	}
+	return verb
+}
+
+// policyRules returns the rules granting the verbs, by namespace. The resources of a group with the same verbs share
+// a rule, the rules are sorted by group and resources.
+func policyRules(verbs map[resourceInNamespace]sets.Set[string]) map[string][]rbacv1.PolicyRule {
+	type groupVerbs struct {
+		namespace string
+		group     string
+		verbs     string
+	}
+	resources := map[groupVerbs][]string{}
+	for key, resourceVerbs := range verbs {
+		rule := groupVerbs{namespace: key.namespace, group: key.group, verbs: strings.Join(sets.List(resourceVerbs), ",")}
+		resources[rule] = append(resources[rule], key.resource)
+	}
+
+	ret := map[string][]rbacv1.PolicyRule{}
+	for rule, ruleResources := range resources {
+		sort.Strings(ruleResources)
+		ret[rule.namespace] = append(ret[rule.namespace], rbacv1.PolicyRule{
+			APIGroups: []string{rule.group},
+			Resources: ruleResources,
+			Verbs:     strings.Split(rule.verbs, ","),
+		})
+	}
+	for _, rules := range ret {
+		sort.Slice(rules, func(i, j int) bool {
+			if rules[i].APIGroups[0] != rules[j].APIGroups[0] {
+				return rules[i].APIGroups[0] < rules[j].APIGroups[0]
+			}
+			return strings.Join(rules[i].Resources, ",") < strings.Join(rules[j].Resources, ",")
+		})
+	}
+	return ret
+}
@@ -0,0 +1,75 @@
+package rbacgen
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestGenerate(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	kubeInformers := v1helpers.NewKubeInformersForNamespaces(kubeClient, "openshift-foo", "")
+	secrets := kubeInformers.InformersFor("openshift-foo").Core().V1().Secrets()
+	nodes := kubeInformers.InformersFor("").Core().V1().Nodes()
+
+	controller := factory.New().
+		WithInformers(secrets.Informer(), nodes.Informer()).
+		WithSync(func(ctx context.Context, syncCtx factory.SyncContext) error {
+			_, err := kubeClient.CoreV1().ConfigMaps("openshift-foo").Get(ctx, "foo", metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				_, err = kubeClient.CoreV1().ConfigMaps("openshift-foo").Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}, metav1.CreateOptions{})
+			}
+			return err
+		}).
+		ToController("FooController", events.NewInMemoryRecorder("foo"))
+
+	manifests, err := NewGenerator(kubeClient).
+		WithInformers(kubeInformers).
+		WithControllers(controller).
+		WithRules("openshift-foo", rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"events"}, Verbs: []string{"create"}}).
+		Generate(context.TODO(), "foo-operator")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedClusterRules := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"list", "watch"}},
+	}
+	if manifests.ClusterRole == nil || manifests.ClusterRole.Name != "foo-operator" {
+		t.Fatalf("expected the foo-operator cluster role, got %v", manifests.ClusterRole)
+	}
+	if diff := cmp.Diff(expectedClusterRules, manifests.ClusterRole.Rules); len(diff) > 0 {
+		t.Errorf("unexpected cluster role rules: %s", diff)
+	}
+
+	expectedRules := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"events"}, Verbs: []string{"create"}},
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"create", "get"}},
+		{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"list", "watch"}},
+	}
+	if len(manifests.Roles) != 1 || manifests.Roles[0].Namespace != "openshift-foo" {
+		t.Fatalf("expected a role in openshift-foo, got %v", manifests.Roles)
+	}
+	if diff := cmp.Diff(expectedRules, manifests.Roles[0].Rules); len(diff) > 0 {
+		t.Errorf("unexpected role rules: %s", diff)
+	}
+
+	out := &bytes.Buffer{}
+	if err := manifests.Write(out); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(out.Bytes(), []byte("kind: ClusterRole\n")) || !bytes.Contains(out.Bytes(), []byte("---\n")) || !bytes.Contains(out.Bytes(), []byte("kind: Role\n")) {
+		t.Errorf("unexpected manifests:\n%s", out.String())
+	}
+}
@@ -0,0 +1,143 @@
+package redeploytrigger
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	opv1 "github.com/openshift/api/operator/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	dc "github.com/openshift/library-go/pkg/operator/deploymentcontroller"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcehash"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// DefaultHashAnnotation is the pod template annotation the combined hash of the referenced resources is stored in.
+const DefaultHashAnnotation = "operator.openshift.io/referenced-resources-hash"
+
+// WorkloadKind is the kind of the operand workload that gets rolled out.
+type WorkloadKind string
+
+const (
+	Deployment WorkloadKind = "Deployment"
+	DaemonSet  WorkloadKind = "DaemonSet"
+)
+
+// Workload references the operand Deployment or DaemonSet.
+type Workload struct {
+	Kind      WorkloadKind
+	Namespace string
+	Name      string
+}
+
+// ReferencedInformers returns the informers of the configmaps and secrets referenced by an operand workload. They must
+// be passed to the controller applying the workload, e.g. as the optional informers of the deployment controller,
+// so that it syncs and runs the hooks whenever a referenced resource changes. The informers must contain the
+// namespaces of all references.
+func ReferencedInformers(informers v1helpers.KubeInformersForNamespaces, references ...*resourcehash.ObjectReference) ([]factory.Informer, error) {
+	var ret []factory.Informer
+	for _, ref := range references {
+		switch {
+		case isConfigMap(ref.Resource):
+			ret = append(ret, informers.InformersFor(ref.Namespace).Core().V1().ConfigMaps().Informer())
+		case isSecret(ref.Resource):
+			ret = append(ret, informers.InformersFor(ref.Namespace).Core().V1().Secrets().Informer())
+		default:
+			return nil, fmt.Errorf("%v is not handled", ref.Resource)
+		}
+	}
+	return ret, nil
+}
+
+// WithDeploymentHook stamps a combined hash of the content of the referenced configmaps and secrets on the pod template
+// of the deployment. Every change of the referenced content changes the pod template and so triggers a rollout when
+// the deployment is applied. Missing resources are part of the hash, creating or deleting a referenced resource
+// triggers a rollout as well. See ReferencedInformers for the informers to sync the deployment on.
+func WithDeploymentHook(informers v1helpers.KubeInformersForNamespaces, references ...*resourcehash.ObjectReference) dc.DeploymentHookFunc {
+	return func(_ *opv1.OperatorSpec, deployment *appsv1.Deployment) error {
+		return setHashAnnotation(&deployment.Spec.Template, informers, references)
+	}
+}
+
+// WithDaemonSetHook is WithDeploymentHook for daemonsets. It is meant to be used as
+// csidrivernodeservicecontroller.DaemonSetHookFunc.
+func WithDaemonSetHook(informers v1helpers.KubeInformersForNamespaces, references ...*resourcehash.ObjectReference) func(*opv1.OperatorSpec, *appsv1.DaemonSet) error {
+	return func(_ *opv1.OperatorSpec, daemonSet *appsv1.DaemonSet) error {
+		return setHashAnnotation(&daemonSet.Spec.Template, informers, references)
+	}
+}
+
+func setHashAnnotation(template *corev1.PodTemplateSpec, informers v1helpers.KubeInformersForNamespaces, references []*resourcehash.ObjectReference) error {
+	hash, err := ReferencesHash(informers, references...)
+	if err != nil {
+		return err
+	}
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[DefaultHashAnnotation] = hash
+	return nil
+}
+
+// ReferencesHash returns the combined hash of the content of all references. Missing references hash as empty.
+func ReferencesHash(informers v1helpers.KubeInformersForNamespaces, references ...*resourcehash.ObjectReference) (string, error) {
+	hashes := map[string]string{}
+	for _, ref := range references {
+		hash, err := referenceHash(informers, ref)
+		if err != nil {
+			return "", err
+		}
+		hashes[fmt.Sprintf("%s/%s/%s", ref.Resource.Resource, ref.Namespace, ref.Name)] = hash
+	}
+
+	keys := make([]string, 0, len(hashes))
+	for key := range hashes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	combined := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(combined, "%s=%s\n", key, hashes[key])
+	}
+	return fmt.Sprintf("%x", combined.Sum(nil)), nil
+}
+
+func referenceHash(informers v1helpers.KubeInformersForNamespaces, ref *resourcehash.ObjectReference) (string, error) {
+	var (
+		configMap *corev1.ConfigMap
+		secret    *corev1.Secret
+		err       error
+	)
+	core := informers.InformersFor(ref.Namespace).Core().V1()
+	switch {
+	case isConfigMap(ref.Resource):
+		configMap, err = core.ConfigMaps().Lister().ConfigMaps(ref.Namespace).Get(ref.Name)
+	case isSecret(ref.Resource):
+		secret, err = core.Secrets().Lister().Secrets(ref.Namespace).Get(ref.Name)
+	default:
+		return "", fmt.Errorf("%v is not handled", ref.Resource)
+	}
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if configMap != nil {
+		return resourcehash.GetConfigMapCanonicalHash(configMap)
+	}
+	return resourcehash.GetSecretCanonicalHash(secret)
+}
+
+func isConfigMap(resource schema.GroupResource) bool {
+	return resource == schema.GroupResource{Resource: "configmap"} || resource == schema.GroupResource{Resource: "configmaps"}
+}
+
+func isSecret(resource schema.GroupResource) bool {
+	return resource == schema.GroupResource{Resource: "secret"} || resource == schema.GroupResource{Resource: "secrets"}
+}
@@ -0,0 +1,92 @@
+package redeploytrigger
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourcehash"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestDeploymentHook(t *testing.T) {
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "operand", Name: "config"}, Data: map[string]string{"a": "b"}}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "config", Name: "serving-cert"}, Data: map[string][]byte{"tls.crt": []byte("cert")}}
+
+	kubeClient := fake.NewSimpleClientset(configMap, secret)
+	informers := v1helpers.NewKubeInformersForNamespaces(kubeClient, "operand", "config")
+	configMapIndexer := informers.InformersFor("operand").Core().V1().ConfigMaps().Informer().GetIndexer()
+	secretIndexer := informers.InformersFor("config").Core().V1().Secrets().Informer().GetIndexer()
+	for _, err := range []error{configMapIndexer.Add(configMap), secretIndexer.Add(secret)} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hook := WithDeploymentHook(informers,
+		resourcehash.NewObjectRef().ForConfigMap().InNamespace("operand").Named("config"),
+		resourcehash.NewObjectRef().ForSecret().InNamespace("config").Named("serving-cert"),
+	)
+	// hash runs the hook on a fresh required deployment, as the deployment controller does on every sync
+	hash := func() string {
+		t.Helper()
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "operand", Name: "server"}}
+		if err := hook(nil, deployment); err != nil {
+			t.Fatal(err)
+		}
+		return deployment.Spec.Template.Annotations[DefaultHashAnnotation]
+	}
+
+	initial := hash()
+	if len(initial) == 0 {
+		t.Fatalf("expected the hash annotation to be set")
+	}
+	if again := hash(); again != initial {
+		t.Errorf("expected stable hash, got %q and %q", initial, again)
+	}
+
+	// metadata changes do not trigger a rollout
+	labeled := configMap.DeepCopy()
+	labeled.Labels = map[string]string{"foo": "bar"}
+	if err := configMapIndexer.Update(labeled); err != nil {
+		t.Fatal(err)
+	}
+	if again := hash(); again != initial {
+		t.Errorf("expected metadata changes to be ignored")
+	}
+
+	rotated := secret.DeepCopy()
+	rotated.Data["tls.crt"] = []byte("new-cert")
+	if err := secretIndexer.Update(rotated); err != nil {
+		t.Fatal(err)
+	}
+	changed := hash()
+	if changed == initial {
+		t.Errorf("expected the secret content change to change the hash")
+	}
+
+	if err := secretIndexer.Delete(rotated); err != nil {
+		t.Fatal(err)
+	}
+	if deleted := hash(); deleted == changed || deleted == initial {
+		t.Errorf("expected the secret removal to change the hash")
+	}
+}
+
+func TestReferencedInformers(t *testing.T) {
+	informers := v1helpers.NewKubeInformersForNamespaces(fake.NewSimpleClientset(), "operand")
+
+	if _, err := ReferencedInformers(informers, &resourcehash.ObjectReference{Namespace: "operand", Name: "foo"}); err == nil {
+		t.Errorf("expected an error for an unsupported reference")
+	}
+	referenced, err := ReferencedInformers(informers, resourcehash.NewObjectRef().ForConfigMap().InNamespace("operand").Named("config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(referenced) != 1 {
+		t.Errorf("expected the config map informer, got %d informers", len(referenced))
+	}
+}
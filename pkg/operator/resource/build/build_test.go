@@ -0,0 +1,109 @@
+package build
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/openshift/library-go/pkg/operator/staticpod/controller/common"
+)
+
+func TestDeployment(t *testing.T) {
+	proxy := &configv1.Proxy{Status: configv1.ProxyStatus{HTTPSProxy: "https://proxy:3128", NoProxy: ".cluster.local"}}
+	builder := Deployment("openshift-foo", "foo-operator").
+		WithReplicas(2).
+		WithPodTemplate(PodTemplate().
+			WithServiceAccount("foo-operator").
+			WithControlPlaneNodes(common.ControlPlaneNodeSelector{Roles: []string{common.MasterNodeRole, common.ArbiterNodeRole}}).
+			WithProxy(proxy).
+			WithContainers(Container("operator", "quay.io/foo:latest").
+				WithArgs("-v=2").
+				WithEnv("NO_PROXY", "overridden").
+				WithMetricsPort(8443).
+				WithHTTPSProbes("/healthz", 8443).
+				WithRequests("10m", "50Mi")))
+	deployment := builder.Build()
+
+	if !equality.Semantic.DeepEqual(deployment, builder.Build()) {
+		t.Fatal("expected the builds to be equal")
+	}
+	if *deployment.Spec.Replicas != 2 {
+		t.Errorf("expected 2 replicas, got %d", *deployment.Spec.Replicas)
+	}
+	if diff := cmp.Diff(&metav1.LabelSelector{MatchLabels: map[string]string{"app": "foo-operator"}}, deployment.Spec.Selector); diff != "" {
+		t.Errorf("unexpected selector (-want +got):\n%s", diff)
+	}
+	template := deployment.Spec.Template
+	if template.Labels["app"] != "foo-operator" {
+		t.Errorf("expected the pods to have the app label, got %v", template.Labels)
+	}
+	if template.Annotations[RequiredSCCAnnotation] != "restricted-v2" {
+		t.Errorf("expected the pods to require the restricted-v2 SCC, got %v", template.Annotations)
+	}
+	if !ptr.Deref(template.Spec.SecurityContext.RunAsNonRoot, false) || template.Spec.SecurityContext.SeccompProfile.Type != corev1.SeccompProfileTypeRuntimeDefault {
+		t.Errorf("unexpected pod security context %v", template.Spec.SecurityContext)
+	}
+	expectedTerms := []corev1.NodeSelectorTerm{
+		{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "node-role.kubernetes.io/master", Operator: corev1.NodeSelectorOpExists}}},
+		{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "node-role.kubernetes.io/arbiter", Operator: corev1.NodeSelectorOpExists}}},
+	}
+	if diff := cmp.Diff(expectedTerms, template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms); diff != "" || len(template.Spec.Tolerations) != 2 {
+		t.Errorf("expected the pods to run on the control plane nodes, got %v (-want +got):\n%s", template.Spec.Tolerations, diff)
+	}
+
+	container := template.Spec.Containers[0]
+	if ptr.Deref(container.SecurityContext.AllowPrivilegeEscalation, true) || len(container.SecurityContext.Capabilities.Drop) != 1 {
+		t.Errorf("unexpected container security context %v", container.SecurityContext)
+	}
+	if container.TerminationMessagePolicy != corev1.TerminationMessageFallbackToLogsOnError {
+		t.Errorf("unexpected termination message policy %q", container.TerminationMessagePolicy)
+	}
+	if container.Ports[0].Name != MetricsPortName || container.Ports[0].ContainerPort != 8443 {
+		t.Errorf("unexpected ports %v", container.Ports)
+	}
+	if container.ReadinessProbe.HTTPGet.Scheme != corev1.URISchemeHTTPS || container.LivenessProbe.HTTPGet.Path != "/healthz" {
+		t.Errorf("unexpected probes %v and %v", container.ReadinessProbe, container.LivenessProbe)
+	}
+	expectedEnv := []corev1.EnvVar{
+		{Name: "NO_PROXY", Value: ".cluster.local"},
+		{Name: "HTTPS_PROXY", Value: "https://proxy:3128"},
+	}
+	if diff := cmp.Diff(expectedEnv, container.Env); diff != "" {
+		t.Errorf("unexpected env (-want +got):\n%s", diff)
+	}
+}
+
+func TestDaemonSet(t *testing.T) {
+	daemonSet := DaemonSet("openshift-foo", "foo-node").
+		WithPodTemplate(PodTemplate().WithContainers(Container("node", "quay.io/foo:latest"))).
+		Build()
+	if daemonSet.Spec.Selector.MatchLabels["app"] != "foo-node" || daemonSet.Spec.Template.Labels["app"] != "foo-node" {
+		t.Errorf("expected the daemonset to select its pods by app label, got %v", daemonSet.Spec.Selector)
+	}
+	if daemonSet.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable.StrVal != "10%" {
+		t.Errorf("unexpected update strategy %v", daemonSet.Spec.UpdateStrategy)
+	}
+}
+
+func TestService(t *testing.T) {
+	service := Service("openshift-foo", "metrics").
+		WithSelector("foo-operator").
+		WithMetricsPort(443).
+		WithServingCertSecret("foo-metrics-tls").
+		Build()
+	if service.Spec.Selector["app"] != "foo-operator" {
+		t.Errorf("unexpected selector %v", service.Spec.Selector)
+	}
+	if port := service.Spec.Ports[0]; port.Name != MetricsPortName || port.Port != 443 || port.TargetPort.StrVal != MetricsPortName {
+		t.Errorf("unexpected port %v", port)
+	}
+	if service.Annotations[ServingCertSecretAnnotation] != "foo-metrics-tls" {
+		t.Errorf("unexpected annotations %v", service.Annotations)
+	}
+}
@@ -0,0 +1,125 @@
+// Package build constructs workloads and services in code, with the platform conventions applied, so the operators do
+// not keep asset YAMLs drifting from the conventions:
+//
+//	deployment := build.Deployment("openshift-foo", "foo-operator").
+//		WithReplicas(2).
+//		WithPodTemplate(build.PodTemplate().
+//			WithServiceAccount("foo-operator").
+//			WithProxy(proxy).
+//			WithContainers(build.Container("operator", image).
+//				WithArgs("--config=/var/run/configmaps/config/config.yaml").
+//				WithMetricsPort(8443).
+//				WithHTTPSProbes("/healthz", 8443))).
+//		Build()
+//
+// The conventions are: the app label selects the pods, the pods run as non root with the runtime default seccomp
+// profile under the restricted-v2 SCC, the containers drop all capabilities and cannot escalate privileges, and their
+// termination message falls back to their logs. The built objects can be changed further before they are applied.
+package build
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+// MetricsPortName is the name of the container and service ports serving metrics.
+const MetricsPortName = "metrics"
+
+// ContainerBuilder builds a container.
+type ContainerBuilder struct {
+	container corev1.Container
+}
+
+// Container returns a builder of the container running the image.
+func Container(name, image string) *ContainerBuilder {
+	return &ContainerBuilder{container: corev1.Container{
+		Name:                     name,
+		Image:                    image,
+		ImagePullPolicy:          corev1.PullIfNotPresent,
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: ptr.To(false),
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		},
+	}}
+}
+
+// WithCommand sets the command of the container.
+func (b *ContainerBuilder) WithCommand(command ...string) *ContainerBuilder {
+	b.container.Command = command
+	return b
+}
+
+// WithArgs appends the arguments of the container.
+func (b *ContainerBuilder) WithArgs(args ...string) *ContainerBuilder {
+	b.container.Args = append(b.container.Args, args...)
+	return b
+}
+
+// WithEnv sets the environment variable, replacing a variable with the same name.
+func (b *ContainerBuilder) WithEnv(name, value string) *ContainerBuilder {
+	setEnv(&b.container, corev1.EnvVar{Name: name, Value: value})
+	return b
+}
+
+// WithPort adds a TCP port.
+func (b *ContainerBuilder) WithPort(name string, port int32) *ContainerBuilder {
+	b.container.Ports = append(b.container.Ports, corev1.ContainerPort{Name: name, ContainerPort: port, Protocol: corev1.ProtocolTCP})
+	return b
+}
+
+// WithMetricsPort adds the port serving metrics, named MetricsPortName.
+func (b *ContainerBuilder) WithMetricsPort(port int32) *ContainerBuilder {
+	return b.WithPort(MetricsPortName, port)
+}
+
+// WithHTTPSProbes sets readiness and liveness probes getting the path on the port over HTTPS. The liveness probe
+// tolerates more failures, so a slow container is taken out of the endpoints before it is restarted.
+func (b *ContainerBuilder) WithHTTPSProbes(path string, port int32) *ContainerBuilder {
+	handler := corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{
+		Path:   path,
+		Port:   intstr.FromInt32(port),
+		Scheme: corev1.URISchemeHTTPS,
+	}}
+	b.container.ReadinessProbe = &corev1.Probe{ProbeHandler: handler, PeriodSeconds: 10, TimeoutSeconds: 10, FailureThreshold: 3}
+	b.container.LivenessProbe = &corev1.Probe{ProbeHandler: handler, PeriodSeconds: 10, TimeoutSeconds: 10, FailureThreshold: 6}
+	return b
+}
+
+// WithRequests sets the CPU and memory requests, e.g. "10m" and "50Mi". Platform containers set no limits.
+func (b *ContainerBuilder) WithRequests(cpu, memory string) *ContainerBuilder {
+	b.container.Resources.Requests = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}
+	return b
+}
+
+// WithVolumeMount mounts the volume of the pod at the path.
+func (b *ContainerBuilder) WithVolumeMount(volumeName, mountPath string, readOnly bool) *ContainerBuilder {
+	b.container.VolumeMounts = append(b.container.VolumeMounts, corev1.VolumeMount{Name: volumeName, MountPath: mountPath, ReadOnly: readOnly})
+	return b
+}
+
+// WithReadOnlyRootFilesystem makes the root filesystem of the container read only.
+func (b *ContainerBuilder) WithReadOnlyRootFilesystem() *ContainerBuilder {
+	b.container.SecurityContext.ReadOnlyRootFilesystem = ptr.To(true)
+	return b
+}
+
+// Build returns the container.
+func (b *ContainerBuilder) Build() corev1.Container {
+	return *b.container.DeepCopy()
+}
+
+func setEnv(container *corev1.Container, env corev1.EnvVar) {
+	for i := range container.Env {
+		if container.Env[i].Name == env.Name {
+			container.Env[i] = env
+			return
+		}
+	}
+	container.Env = append(container.Env, env)
+}
@@ -0,0 +1,64 @@
+package build
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// ServingCertSecretAnnotation asks the service CA operator for a serving certificate of the service, stored in the
+// named secret.
+const ServingCertSecretAnnotation = "service.beta.openshift.io/serving-cert-secret-name"
+
+// ServiceBuilder builds a Service.
+type ServiceBuilder struct {
+	service corev1.Service
+}
+
+// Service returns a builder of a ClusterIP service selecting the pods of the app of the same name, see AppLabel.
+func Service(namespace, name string) *ServiceBuilder {
+	return &ServiceBuilder{service: corev1.Service{
+		TypeMeta:   metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "Service"},
+		ObjectMeta: objectMeta(namespace, name),
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: map[string]string{AppLabel: name},
+		},
+	}}
+}
+
+// WithSelector replaces the selector of the service, to select the pods of another app.
+func (b *ServiceBuilder) WithSelector(app string) *ServiceBuilder {
+	b.service.Spec.Selector = map[string]string{AppLabel: app}
+	return b
+}
+
+// WithPort adds a TCP port forwarding to the named container port.
+func (b *ServiceBuilder) WithPort(name string, port int32, targetPort string) *ServiceBuilder {
+	b.service.Spec.Ports = append(b.service.Spec.Ports, corev1.ServicePort{
+		Name:       name,
+		Port:       port,
+		TargetPort: intstr.FromString(targetPort),
+		Protocol:   corev1.ProtocolTCP,
+	})
+	return b
+}
+
+// WithMetricsPort adds the port serving metrics, forwarding to the MetricsPortName container port.
+func (b *ServiceBuilder) WithMetricsPort(port int32) *ServiceBuilder {
+	return b.WithPort(MetricsPortName, port, MetricsPortName)
+}
+
+// WithServingCertSecret requests a serving certificate for the service, stored in the secret.
+func (b *ServiceBuilder) WithServingCertSecret(secretName string) *ServiceBuilder {
+	if b.service.Annotations == nil {
+		b.service.Annotations = map[string]string{}
+	}
+	b.service.Annotations[ServingCertSecretAnnotation] = secretName
+	return b
+}
+
+// Build returns the service.
+func (b *ServiceBuilder) Build() *corev1.Service {
+	return b.service.DeepCopy()
+}
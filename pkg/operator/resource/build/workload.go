@@ -0,0 +1,213 @@
+package build
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/openshift/library-go/pkg/operator/staticpod/controller/common"
+)
+
+const (
+	// AppLabel is the label selecting the pods of a workload, its value is the name of the workload.
+	AppLabel = "app"
+	// RequiredSCCAnnotation pins the SCC the pods are admitted with.
+	RequiredSCCAnnotation = "openshift.io/required-scc"
+)
+
+// PodTemplateBuilder builds the pod template of a workload.
+type PodTemplateBuilder struct {
+	template   corev1.PodTemplateSpec
+	containers []*ContainerBuilder
+	proxy      *configv1.Proxy
+}
+
+// PodTemplate returns a builder of a pod template running under the restricted-v2 SCC with the
+// system-cluster-critical priority.
+func PodTemplate() *PodTemplateBuilder {
+	return &PodTemplateBuilder{template: corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{RequiredSCCAnnotation: "restricted-v2"},
+		},
+		Spec: corev1.PodSpec{
+			PriorityClassName: "system-cluster-critical",
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsNonRoot:   ptr.To(true),
+				SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+			},
+		},
+	}}
+}
+
+// WithContainers appends the containers.
+func (b *PodTemplateBuilder) WithContainers(containers ...*ContainerBuilder) *PodTemplateBuilder {
+	b.containers = append(b.containers, containers...)
+	return b
+}
+
+// WithServiceAccount sets the service account the pods run as.
+func (b *PodTemplateBuilder) WithServiceAccount(name string) *PodTemplateBuilder {
+	b.template.Spec.ServiceAccountName = name
+	return b
+}
+
+// WithPriorityClass replaces the system-cluster-critical priority class.
+func (b *PodTemplateBuilder) WithPriorityClass(name string) *PodTemplateBuilder {
+	b.template.Spec.PriorityClassName = name
+	return b
+}
+
+// WithControlPlaneNodes runs the pods on the nodes of the selector, tolerating their taint. The zero selector selects
+// the master nodes.
+func (b *PodTemplateBuilder) WithControlPlaneNodes(nodeSelector common.ControlPlaneNodeSelector) *PodTemplateBuilder {
+	if b.template.Spec.Affinity == nil {
+		b.template.Spec.Affinity = &corev1.Affinity{}
+	}
+	b.template.Spec.Affinity.NodeAffinity = nodeSelector.NodeAffinity()
+	b.template.Spec.Tolerations = append(b.template.Spec.Tolerations, nodeSelector.Tolerations()...)
+	return b
+}
+
+// WithTolerations appends the tolerations.
+func (b *PodTemplateBuilder) WithTolerations(tolerations ...corev1.Toleration) *PodTemplateBuilder {
+	b.template.Spec.Tolerations = append(b.template.Spec.Tolerations, tolerations...)
+	return b
+}
+
+// WithVolume adds a volume.
+func (b *PodTemplateBuilder) WithVolume(volume corev1.Volume) *PodTemplateBuilder {
+	b.template.Spec.Volumes = append(b.template.Spec.Volumes, volume)
+	return b
+}
+
+// WithAnnotation sets an annotation of the pods, e.g. the hash of a config to roll the pods out when it changes.
+func (b *PodTemplateBuilder) WithAnnotation(key, value string) *PodTemplateBuilder {
+	b.template.Annotations[key] = value
+	return b
+}
+
+// WithProxy sets the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables of all the containers from the
+// status of the cluster proxy config, when they are set.
+func (b *PodTemplateBuilder) WithProxy(proxy *configv1.Proxy) *PodTemplateBuilder {
+	b.proxy = proxy
+	return b
+}
+
+// build returns the pod template of the app.
+func (b *PodTemplateBuilder) build(app string) corev1.PodTemplateSpec {
+	template := *b.template.DeepCopy()
+	template.Labels = map[string]string{AppLabel: app}
+	for _, container := range b.containers {
+		c := container.Build()
+		if b.proxy != nil {
+			// in a stable order, so the spec does not change between builds
+			for _, env := range []corev1.EnvVar{
+				{Name: "HTTP_PROXY", Value: b.proxy.Status.HTTPProxy},
+				{Name: "HTTPS_PROXY", Value: b.proxy.Status.HTTPSProxy},
+				{Name: "NO_PROXY", Value: b.proxy.Status.NoProxy},
+			} {
+				if len(env.Value) > 0 {
+					setEnv(&c, env)
+				}
+			}
+		}
+		template.Spec.Containers = append(template.Spec.Containers, c)
+	}
+	return template
+}
+
+// DeploymentBuilder builds a Deployment.
+type DeploymentBuilder struct {
+	deployment  appsv1.Deployment
+	podTemplate *PodTemplateBuilder
+}
+
+// Deployment returns a builder of a deployment with a single replica rolled out one pod at a time.
+func Deployment(namespace, name string) *DeploymentBuilder {
+	maxUnavailable := intstr.FromInt32(1)
+	maxSurge := intstr.FromInt32(0)
+	return &DeploymentBuilder{
+		deployment: appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "Deployment"},
+			ObjectMeta: objectMeta(namespace, name),
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To[int32](1),
+				Strategy: appsv1.DeploymentStrategy{
+					Type:          appsv1.RollingUpdateDeploymentStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateDeployment{MaxUnavailable: &maxUnavailable, MaxSurge: &maxSurge},
+				},
+			},
+		},
+		podTemplate: PodTemplate(),
+	}
+}
+
+// WithReplicas sets the number of replicas.
+func (b *DeploymentBuilder) WithReplicas(replicas int32) *DeploymentBuilder {
+	b.deployment.Spec.Replicas = ptr.To(replicas)
+	return b
+}
+
+// WithPodTemplate sets the pod template.
+func (b *DeploymentBuilder) WithPodTemplate(podTemplate *PodTemplateBuilder) *DeploymentBuilder {
+	b.podTemplate = podTemplate
+	return b
+}
+
+// Build returns the deployment.
+func (b *DeploymentBuilder) Build() *appsv1.Deployment {
+	ret := b.deployment.DeepCopy()
+	ret.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{AppLabel: ret.Name}}
+	ret.Spec.Template = b.podTemplate.build(ret.Name)
+	return ret
+}
+
+// DaemonSetBuilder builds a DaemonSet.
+type DaemonSetBuilder struct {
+	daemonSet   appsv1.DaemonSet
+	podTemplate *PodTemplateBuilder
+}
+
+// DaemonSet returns a builder of a daemonset rolled out on 10% of the nodes at a time.
+func DaemonSet(namespace, name string) *DaemonSetBuilder {
+	maxUnavailable := intstr.FromString("10%")
+	return &DaemonSetBuilder{
+		daemonSet: appsv1.DaemonSet{
+			TypeMeta:   metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "DaemonSet"},
+			ObjectMeta: objectMeta(namespace, name),
+			Spec: appsv1.DaemonSetSpec{
+				UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+					Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &maxUnavailable},
+				},
+			},
+		},
+		podTemplate: PodTemplate(),
+	}
+}
+
+// WithPodTemplate sets the pod template.
+func (b *DaemonSetBuilder) WithPodTemplate(podTemplate *PodTemplateBuilder) *DaemonSetBuilder {
+	b.podTemplate = podTemplate
+	return b
+}
+
+// Build returns the daemonset.
+func (b *DaemonSetBuilder) Build() *appsv1.DaemonSet {
+	ret := b.daemonSet.DeepCopy()
+	ret.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{AppLabel: ret.Name}}
+	ret.Spec.Template = b.podTemplate.build(ret.Name)
+	return ret
+}
+
+func objectMeta(namespace, name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: namespace,
+		Name:      name,
+		Labels:    map[string]string{AppLabel: name},
+	}
+}
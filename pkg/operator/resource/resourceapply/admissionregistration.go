@@ -34,7 +34,7 @@ func ApplyMutatingWebhookConfigurationImproved(ctx context.Context, client admis
 	if apierrors.IsNotFound(err) {
 		required := requiredOriginal.DeepCopy()
 		actual, err := client.MutatingWebhookConfigurations().Create(
-			ctx, resourcemerge.WithCleanLabelsAndAnnotations(required).(*admissionregistrationv1.MutatingWebhookConfiguration), metav1.CreateOptions{})
+			ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, required.DeepCopy())).(*admissionregistrationv1.MutatingWebhookConfiguration), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, required, err)
 		if err != nil {
 			return nil, false, err
@@ -55,6 +55,9 @@ func ApplyMutatingWebhookConfigurationImproved(ctx context.Context, client admis
 	existingCopy := existing.DeepCopy()
 
 	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
 	copyMutatingWebhookCABundle(existing, required)
 	webhooksEquivalent := equality.Semantic.DeepEqual(existingCopy.Webhooks, required.Webhooks)
 	if webhooksEquivalent && !modified {
@@ -66,7 +69,10 @@ func ApplyMutatingWebhookConfigurationImproved(ctx context.Context, client admis
 	toWrite := existingCopy // shallow copy so the code reads easier
 	toWrite.Webhooks = required.Webhooks
 
-	klog.V(2).Infof("MutatingWebhookConfiguration %q changes: %v", required.GetNamespace()+"/"+required.GetName(), JSONPatchNoError(existing, toWrite))
+	if klogV := klog.V(2); klogV.Enabled() {
+		klogV.Infof("MutatingWebhookConfiguration %q changes: %v", required.GetNamespace()+"/"+required.GetName(), JSONPatchNoError(existing, toWrite))
+	}
+	reportChanges(ctx, recorder, existing, toWrite)
 
 	actual, err := client.MutatingWebhookConfigurations().Update(ctx, toWrite, metav1.UpdateOptions{})
 	resourcehelper.ReportUpdateEvent(recorder, required, err)
@@ -109,7 +115,7 @@ func ApplyValidatingWebhookConfigurationImproved(ctx context.Context, client adm
 	if apierrors.IsNotFound(err) {
 		required := requiredOriginal.DeepCopy()
 		actual, err := client.ValidatingWebhookConfigurations().Create(
-			ctx, resourcemerge.WithCleanLabelsAndAnnotations(required).(*admissionregistrationv1.ValidatingWebhookConfiguration), metav1.CreateOptions{})
+			ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, required.DeepCopy())).(*admissionregistrationv1.ValidatingWebhookConfiguration), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, required, err)
 		if err != nil {
 			return nil, false, err
@@ -130,6 +136,9 @@ func ApplyValidatingWebhookConfigurationImproved(ctx context.Context, client adm
 	existingCopy := existing.DeepCopy()
 
 	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
 	copyValidatingWebhookCABundle(existing, required)
 	webhooksEquivalent := equality.Semantic.DeepEqual(existingCopy.Webhooks, required.Webhooks)
 	if webhooksEquivalent && !modified {
@@ -141,7 +150,10 @@ func ApplyValidatingWebhookConfigurationImproved(ctx context.Context, client adm
 	toWrite := existingCopy // shallow copy so the code reads easier
 	toWrite.Webhooks = required.Webhooks
 
-	klog.V(2).Infof("ValidatingWebhookConfiguration %q changes: %v", required.GetNamespace()+"/"+required.GetName(), JSONPatchNoError(existing, toWrite))
+	if klogV := klog.V(2); klogV.Enabled() {
+		klogV.Infof("ValidatingWebhookConfiguration %q changes: %v", required.GetNamespace()+"/"+required.GetName(), JSONPatchNoError(existing, toWrite))
+	}
+	reportChanges(ctx, recorder, existing, toWrite)
 
 	actual, err := client.ValidatingWebhookConfigurations().Update(ctx, toWrite, metav1.UpdateOptions{})
 	resourcehelper.ReportUpdateEvent(recorder, required, err)
@@ -196,7 +208,7 @@ func ApplyValidatingAdmissionPolicyV1beta1(ctx context.Context, client admission
 	if apierrors.IsNotFound(err) {
 		required := requiredOriginal.DeepCopy()
 		actual, err := client.ValidatingAdmissionPolicies().Create(
-			ctx, resourcemerge.WithCleanLabelsAndAnnotations(required).(*admissionregistrationv1beta1.ValidatingAdmissionPolicy), metav1.CreateOptions{})
+			ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, required.DeepCopy())).(*admissionregistrationv1beta1.ValidatingAdmissionPolicy), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, required, err)
 		if err != nil {
 			return nil, false, err
@@ -217,6 +229,9 @@ func ApplyValidatingAdmissionPolicyV1beta1(ctx context.Context, client admission
 	existingCopy := existing.DeepCopy()
 
 	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
 	specEquivalent := equality.Semantic.DeepEqual(existingCopy.Spec, required.Spec)
 	if specEquivalent && !modified {
 		// need to store the original so that the early comparison of hashes is done based on the original, not a mutated copy
@@ -227,7 +242,10 @@ func ApplyValidatingAdmissionPolicyV1beta1(ctx context.Context, client admission
 	toWrite := existingCopy // shallow copy so the code reads easier
 	toWrite.Spec = required.Spec
 
-	klog.V(2).Infof("ValidatingAdmissionPolicyConfigurationV1beta1 %q changes: %v", required.GetNamespace()+"/"+required.GetName(), JSONPatchNoError(existing, toWrite))
+	if klogV := klog.V(2); klogV.Enabled() {
+		klogV.Infof("ValidatingAdmissionPolicyConfigurationV1beta1 %q changes: %v", required.GetNamespace()+"/"+required.GetName(), JSONPatchNoError(existing, toWrite))
+	}
+	reportChanges(ctx, recorder, existing, toWrite)
 
 	actual, err := client.ValidatingAdmissionPolicies().Update(ctx, toWrite, metav1.UpdateOptions{})
 	resourcehelper.ReportUpdateEvent(recorder, required, err)
@@ -255,7 +273,7 @@ func ApplyValidatingAdmissionPolicyV1(ctx context.Context, client admissionregis
 	if apierrors.IsNotFound(err) {
 		required := requiredOriginal.DeepCopy()
 		actual, err := client.ValidatingAdmissionPolicies().Create(
-			ctx, resourcemerge.WithCleanLabelsAndAnnotations(required).(*admissionregistrationv1.ValidatingAdmissionPolicy), metav1.CreateOptions{})
+			ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, required.DeepCopy())).(*admissionregistrationv1.ValidatingAdmissionPolicy), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, required, err)
 		if err != nil {
 			return nil, false, err
@@ -276,6 +294,9 @@ func ApplyValidatingAdmissionPolicyV1(ctx context.Context, client admissionregis
 	existingCopy := existing.DeepCopy()
 
 	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
 	specEquivalent := equality.Semantic.DeepEqual(existingCopy.Spec, required.Spec)
 	if specEquivalent && !modified {
 		// need to store the original so that the early comparison of hashes is done based on the original, not a mutated copy
@@ -286,7 +307,10 @@ func ApplyValidatingAdmissionPolicyV1(ctx context.Context, client admissionregis
 	toWrite := existingCopy // shallow copy so the code reads easier
 	toWrite.Spec = required.Spec
 
-	klog.V(2).Infof("ValidatingAdmissionPolicyConfigurationV1 %q changes: %v", required.GetNamespace()+"/"+required.GetName(), JSONPatchNoError(existing, toWrite))
+	if klogV := klog.V(2); klogV.Enabled() {
+		klogV.Infof("ValidatingAdmissionPolicyConfigurationV1 %q changes: %v", required.GetNamespace()+"/"+required.GetName(), JSONPatchNoError(existing, toWrite))
+	}
+	reportChanges(ctx, recorder, existing, toWrite)
 
 	actual, err := client.ValidatingAdmissionPolicies().Update(ctx, toWrite, metav1.UpdateOptions{})
 	resourcehelper.ReportUpdateEvent(recorder, required, err)
@@ -314,7 +338,7 @@ func ApplyValidatingAdmissionPolicyBindingV1beta1(ctx context.Context, client ad
 	if apierrors.IsNotFound(err) {
 		required := requiredOriginal.DeepCopy()
 		actual, err := client.ValidatingAdmissionPolicyBindings().Create(
-			ctx, resourcemerge.WithCleanLabelsAndAnnotations(required).(*admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding), metav1.CreateOptions{})
+			ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, required.DeepCopy())).(*admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, required, err)
 		if err != nil {
 			return nil, false, err
@@ -335,6 +359,9 @@ func ApplyValidatingAdmissionPolicyBindingV1beta1(ctx context.Context, client ad
 	existingCopy := existing.DeepCopy()
 
 	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
 	specEquivalent := equality.Semantic.DeepEqual(existingCopy.Spec, required.Spec)
 	if specEquivalent && !modified {
 		// need to store the original so that the early comparison of hashes is done based on the original, not a mutated copy
@@ -345,7 +372,10 @@ func ApplyValidatingAdmissionPolicyBindingV1beta1(ctx context.Context, client ad
 	toWrite := existingCopy // shallow copy so the code reads easier
 	toWrite.Spec = required.Spec
 
-	klog.V(2).Infof("ValidatingAdmissionPolicyBindingConfigurationV1beta1 %q changes: %v", required.GetNamespace()+"/"+required.GetName(), JSONPatchNoError(existing, toWrite))
+	if klogV := klog.V(2); klogV.Enabled() {
+		klogV.Infof("ValidatingAdmissionPolicyBindingConfigurationV1beta1 %q changes: %v", required.GetNamespace()+"/"+required.GetName(), JSONPatchNoError(existing, toWrite))
+	}
+	reportChanges(ctx, recorder, existing, toWrite)
 
 	actual, err := client.ValidatingAdmissionPolicyBindings().Update(ctx, toWrite, metav1.UpdateOptions{})
 	resourcehelper.ReportUpdateEvent(recorder, required, err)
@@ -373,7 +403,7 @@ func ApplyValidatingAdmissionPolicyBindingV1(ctx context.Context, client admissi
 	if apierrors.IsNotFound(err) {
 		required := requiredOriginal.DeepCopy()
 		actual, err := client.ValidatingAdmissionPolicyBindings().Create(
-			ctx, resourcemerge.WithCleanLabelsAndAnnotations(required).(*admissionregistrationv1.ValidatingAdmissionPolicyBinding), metav1.CreateOptions{})
+			ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, required.DeepCopy())).(*admissionregistrationv1.ValidatingAdmissionPolicyBinding), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, required, err)
 		if err != nil {
 			return nil, false, err
@@ -394,6 +424,9 @@ func ApplyValidatingAdmissionPolicyBindingV1(ctx context.Context, client admissi
 	existingCopy := existing.DeepCopy()
 
 	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
 	specEquivalent := equality.Semantic.DeepEqual(existingCopy.Spec, required.Spec)
 	if specEquivalent && !modified {
 		// need to store the original so that the early comparison of hashes is done based on the original, not a mutated copy
@@ -404,7 +437,10 @@ func ApplyValidatingAdmissionPolicyBindingV1(ctx context.Context, client admissi
 	toWrite := existingCopy // shallow copy so the code reads easier
 	toWrite.Spec = required.Spec
 
-	klog.V(2).Infof("ValidatingAdmissionPolicyBindingConfigurationV1 %q changes: %v", required.GetNamespace()+"/"+required.GetName(), JSONPatchNoError(existing, toWrite))
+	if klogV := klog.V(2); klogV.Enabled() {
+		klogV.Infof("ValidatingAdmissionPolicyBindingConfigurationV1 %q changes: %v", required.GetNamespace()+"/"+required.GetName(), JSONPatchNoError(existing, toWrite))
+	}
+	reportChanges(ctx, recorder, existing, toWrite)
 
 	actual, err := client.ValidatingAdmissionPolicyBindings().Update(ctx, toWrite, metav1.UpdateOptions{})
 	resourcehelper.ReportUpdateEvent(recorder, required, err)
@@ -415,3 +451,27 @@ func ApplyValidatingAdmissionPolicyBindingV1(ctx context.Context, client admissi
 	cache.UpdateCachedResourceMetadata(requiredOriginal, actual)
 	return actual, true, nil
 }
+
+func DeleteValidatingAdmissionPolicyV1(ctx context.Context, client admissionregistrationclientv1.ValidatingAdmissionPoliciesGetter, recorder events.Recorder, required *admissionregistrationv1.ValidatingAdmissionPolicy) (*admissionregistrationv1.ValidatingAdmissionPolicy, bool, error) {
+	err := client.ValidatingAdmissionPolicies().Delete(ctx, required.Name, metav1.DeleteOptions{})
+	if err != nil && apierrors.IsNotFound(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	resourcehelper.ReportDeleteEvent(recorder, required, err)
+	return nil, true, nil
+}
+
+func DeleteValidatingAdmissionPolicyBindingV1(ctx context.Context, client admissionregistrationclientv1.ValidatingAdmissionPolicyBindingsGetter, recorder events.Recorder, required *admissionregistrationv1.ValidatingAdmissionPolicyBinding) (*admissionregistrationv1.ValidatingAdmissionPolicyBinding, bool, error) {
+	err := client.ValidatingAdmissionPolicyBindings().Delete(ctx, required.Name, metav1.DeleteOptions{})
+	if err != nil && apierrors.IsNotFound(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	resourcehelper.ReportDeleteEvent(recorder, required, err)
+	return nil, true, nil
+}
@@ -19,7 +19,7 @@ func ApplyCustomResourceDefinitionV1(ctx context.Context, client apiextclientv1.
 	if apierrors.IsNotFound(err) {
 		requiredCopy := required.DeepCopy()
 		actual, err := client.CustomResourceDefinitions().Create(
-			ctx, resourcemerge.WithCleanLabelsAndAnnotations(requiredCopy).(*apiextensionsv1.CustomResourceDefinition), metav1.CreateOptions{})
+			ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, requiredCopy)).(*apiextensionsv1.CustomResourceDefinition), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, required, err)
 		return actual, true, err
 	}
@@ -30,6 +30,9 @@ func ApplyCustomResourceDefinitionV1(ctx context.Context, client apiextclientv1.
 	modified := false
 	existingCopy := existing.DeepCopy()
 	resourcemerge.EnsureCustomResourceDefinitionV1(&modified, existingCopy, *required)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
 	if !modified {
 		return existing, false, nil
 	}
@@ -37,6 +40,7 @@ func ApplyCustomResourceDefinitionV1(ctx context.Context, client apiextclientv1.
 	if klog.V(2).Enabled() {
 		klog.Infof("CustomResourceDefinition %q changes: %s", existing.Name, JSONPatchNoError(existing, existingCopy))
 	}
+	reportChanges(ctx, recorder, existing, existingCopy)
 
 	actual, err := client.CustomResourceDefinitions().Update(ctx, existingCopy, metav1.UpdateOptions{})
 	resourcehelper.ReportUpdateEvent(recorder, required, err)
@@ -16,12 +16,13 @@ import (
 )
 
 // ApplyAPIService merges objectmeta and requires apiservice coordinates.  It does not touch CA bundles, which should be managed via service CA controller.
+// An empty CA bundle in required keeps the injected CA bundle. The status maintained by the aggregator is ignored.
 func ApplyAPIService(ctx context.Context, client apiregistrationv1client.APIServicesGetter, recorder events.Recorder, required *apiregistrationv1.APIService) (*apiregistrationv1.APIService, bool, error) {
 	existing, err := client.APIServices().Get(ctx, required.Name, metav1.GetOptions{})
 	if apierrors.IsNotFound(err) {
 		requiredCopy := required.DeepCopy()
 		actual, err := client.APIServices().Create(
-			ctx, resourcemerge.WithCleanLabelsAndAnnotations(requiredCopy).(*apiregistrationv1.APIService), metav1.CreateOptions{})
+			ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, requiredCopy)).(*apiregistrationv1.APIService), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, required, err)
 		return actual, true, err
 	}
@@ -33,6 +34,9 @@ func ApplyAPIService(ctx context.Context, client apiregistrationv1client.APIServ
 	existingCopy := existing.DeepCopy()
 
 	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
 	serviceSame := equality.Semantic.DeepEqual(existingCopy.Spec.Service, required.Spec.Service)
 	prioritySame := existingCopy.Spec.VersionPriority == required.Spec.VersionPriority && existingCopy.Spec.GroupPriorityMinimum == required.Spec.GroupPriorityMinimum
 	insecureSame := existingCopy.Spec.InsecureSkipTLSVerify == required.Spec.InsecureSkipTLSVerify
@@ -41,11 +45,16 @@ func ApplyAPIService(ctx context.Context, client apiregistrationv1client.APIServ
 		return existingCopy, false, nil
 	}
 
+	caBundle := existingCopy.Spec.CABundle
 	existingCopy.Spec = required.Spec
+	if len(existingCopy.Spec.CABundle) == 0 {
+		existingCopy.Spec.CABundle = caBundle
+	}
 
 	if klog.V(2).Enabled() {
 		klog.Infof("APIService %q changes: %s", existing.Name, JSONPatchNoError(existing, existingCopy))
 	}
+	reportChanges(ctx, recorder, existing, existingCopy)
 	actual, err := client.APIServices().Update(ctx, existingCopy, metav1.UpdateOptions{})
 	resourcehelper.ReportUpdateEvent(recorder, required, err)
 	return actual, true, err
@@ -0,0 +1,55 @@
+package resourceapply
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiregistrationv1 "k8s.io/kube-aggregator/pkg/apis/apiregistration/v1"
+	aggregatorfake "k8s.io/kube-aggregator/pkg/client/clientset_generated/clientset/fake"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestApplyAPIService(t *testing.T) {
+	required := &apiregistrationv1.APIService{
+		ObjectMeta: metav1.ObjectMeta{Name: "v1.apps.openshift.io"},
+		Spec: apiregistrationv1.APIServiceSpec{
+			Group:                "apps.openshift.io",
+			Version:              "v1",
+			Service:              &apiregistrationv1.ServiceReference{Namespace: "openshift-apiserver", Name: "api"},
+			GroupPriorityMinimum: 9900,
+			VersionPriority:      15,
+		},
+	}
+	client := aggregatorfake.NewSimpleClientset()
+	recorder := events.NewInMemoryRecorder("test")
+
+	if _, modified, err := ApplyAPIService(context.TODO(), client.ApiregistrationV1(), recorder, required); err != nil || !modified {
+		t.Fatalf("expected create, got modified=%v err=%v", modified, err)
+	}
+
+	// the CA bundle is injected and the status is populated
+	existing, err := client.ApiregistrationV1().APIServices().Get(context.TODO(), required.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing.Spec.CABundle = []byte("ca")
+	existing.Status.Conditions = []apiregistrationv1.APIServiceCondition{{Type: apiregistrationv1.Available, Status: apiregistrationv1.ConditionTrue}}
+	if _, err := client.ApiregistrationV1().APIServices().Update(context.TODO(), existing, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, modified, err := ApplyAPIService(context.TODO(), client.ApiregistrationV1(), recorder, required); err != nil || modified {
+		t.Fatalf("expected no change, got modified=%v err=%v", modified, err)
+	}
+
+	changed := required.DeepCopy()
+	changed.Spec.VersionPriority = 20
+	actual, modified, err := ApplyAPIService(context.TODO(), client.ApiregistrationV1(), recorder, changed)
+	if err != nil || !modified {
+		t.Fatalf("expected update, got modified=%v err=%v", modified, err)
+	}
+	if string(actual.Spec.CABundle) != "ca" {
+		t.Errorf("expected the injected CA bundle to be kept, got %q", actual.Spec.CABundle)
+	}
+}
@@ -0,0 +1,156 @@
+package resourceapply
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// FailurePolicy decides whether ApplyAll goes on with the remaining objects after an object failed to apply.
+type FailurePolicy string
+
+const (
+	// ContinueOnError applies all the objects, whatever fails.
+	ContinueOnError FailurePolicy = "Continue"
+	// StopOnError stops at the first object which fails to apply.
+	StopOnError FailurePolicy = "Stop"
+	// StopOnFatalError stops at the first object which fails with an error retrying will not fix, e.g. an invalid
+	// or forbidden object, or a type without a client. Conflicts, timeouts and unavailable servers do not stop it.
+	StopOnFatalError FailurePolicy = "StopOnFatal"
+)
+
+// ApplyStatus is the outcome of the apply of a single object.
+type ApplyStatus string
+
+const (
+	ApplyStatusUnchanged ApplyStatus = "Unchanged"
+	ApplyStatusChanged   ApplyStatus = "Changed"
+	ApplyStatusFailed    ApplyStatus = "Failed"
+	// ApplyStatusSkipped is the status of the objects which were not applied because an earlier one stopped ApplyAll.
+	ApplyStatusSkipped ApplyStatus = "Skipped"
+)
+
+// ApplyAllOptions configures ApplyAll.
+type ApplyAllOptions struct {
+	// FailurePolicy defaults to ContinueOnError.
+	FailurePolicy FailurePolicy
+	// Cache is passed to the Apply functions which use one, it is optional.
+	Cache ResourceCache
+}
+
+// ObjectApplyResult is the outcome of the apply of a single object in an ApplyAllReport.
+type ObjectApplyResult struct {
+	Kind      string      `json:"kind"`
+	Namespace string      `json:"namespace,omitempty"`
+	Name      string      `json:"name"`
+	Status    ApplyStatus `json:"status"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// RollbackNote describes how an object applied by ApplyAll differs from its state before the apply, so it can be
+// rolled back by hand. Like the change reports, it only contains field paths, never values.
+type RollbackNote struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Created is true when the object did not exist before, it is then enough to delete it.
+	Created bool     `json:"created,omitempty"`
+	Added   []string `json:"added,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// ApplyAllReport is the machine-readable outcome of ApplyAll. It serializes to JSON.
+type ApplyAllReport struct {
+	// Results has a result for every object, in the order they were given.
+	Results []ObjectApplyResult `json:"results"`
+	// Stopped is true when the failure policy stopped ApplyAll before all the objects were applied.
+	Stopped bool `json:"stopped,omitempty"`
+	// RollbackNotes lists the objects which were changed, when some objects failed to apply. It is empty when all
+	// the objects were applied.
+	RollbackNotes []RollbackNote `json:"rollbackNotes,omitempty"`
+
+	errs []error
+}
+
+// Err returns the errors of the objects which failed to apply, or nil.
+func (r *ApplyAllReport) Err() error {
+	return utilerrors.NewAggregate(r.errs)
+}
+
+// ApplyAll applies the objects in order with the Apply function of their types, like ApplyDirectly does for manifest
+// files, and reports the outcome of every object. Whether it goes on after a failure is decided by the failure policy
+// of the options. When some objects failed, the report notes which of the already applied objects differ from their
+// state before the apply.
+func ApplyAll(ctx context.Context, clients *ClientHolder, recorder events.Recorder, objects []runtime.Object, opts ApplyAllOptions) *ApplyAllReport {
+	report := &ApplyAllReport{Results: []ObjectApplyResult{}}
+	var notes []RollbackNote
+
+	for _, obj := range objects {
+		result := ObjectApplyResult{Kind: objectKind(obj)}
+		if accessor, err := meta.Accessor(obj); err == nil {
+			result.Namespace = accessor.GetNamespace()
+			result.Name = accessor.GetName()
+		}
+		if report.Stopped {
+			result.Status = ApplyStatusSkipped
+			report.Results = append(report.Results, result)
+			continue
+		}
+
+		// the change reports are only collected for the rollback notes, the Apply functions already emit events
+		objCtx, changes := WithChangeReports(ctx)
+		changes.quiet = true
+		_, changed, err := applyObject(objCtx, clients, recorder, opts.Cache, obj)
+		switch {
+		case err != nil:
+			result.Status = ApplyStatusFailed
+			result.Error = err.Error()
+			report.errs = append(report.errs, fmt.Errorf("%s %q: %w", result.Kind, namespacedName(result.Namespace, result.Name), err))
+			report.Stopped = opts.FailurePolicy == StopOnError || (opts.FailurePolicy == StopOnFatalError && isFatalApplyError(err))
+		case changed:
+			result.Status = ApplyStatusChanged
+			notes = append(notes, rollbackNoteFor(result, changes))
+		default:
+			result.Status = ApplyStatusUnchanged
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	if len(report.errs) > 0 {
+		report.RollbackNotes = notes
+	}
+	return report
+}
+
+// rollbackNoteFor returns the note of a changed object from the change reports collected during its apply. The object
+// was created when the apply did not find an existing one; recreated objects existed before and are not created.
+func rollbackNoteFor(result ObjectApplyResult, changes *ChangeReports) RollbackNote {
+	note := RollbackNote{Kind: result.Kind, Namespace: result.Namespace, Name: result.Name, Created: changes.createdOnly()}
+	for _, report := range changes.Reports() {
+		note.Added = append(note.Added, report.Added...)
+		note.Changed = append(note.Changed, report.Changed...)
+		note.Removed = append(note.Removed, report.Removed...)
+	}
+	return note
+}
+
+// isFatalApplyError returns false for the errors which may go away when the apply is retried.
+func isFatalApplyError(err error) bool {
+	switch {
+	case errors.IsConflict(err),
+		errors.IsServerTimeout(err),
+		errors.IsTimeout(err),
+		errors.IsTooManyRequests(err),
+		errors.IsServiceUnavailable(err),
+		errors.IsInternalError(err):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,143 @@
+package resourceapply
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestApplyAll(t *testing.T) {
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "config"},
+		Data:       map[string]string{"key": "old"},
+	}
+	objects := func() []runtime.Object {
+		return []runtime.Object{
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "config"}, Data: map[string]string{"key": "new"}},
+			&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "sa"}},
+			&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "claim"}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "other"}},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		objects          []runtime.Object
+		policy           FailurePolicy
+		expectedStatuses []ApplyStatus
+		expectedStopped  bool
+		expectedNotes    []RollbackNote
+	}{
+		{
+			name:             "continue on error",
+			objects:          objects(),
+			policy:           ContinueOnError,
+			expectedStatuses: []ApplyStatus{ApplyStatusChanged, ApplyStatusChanged, ApplyStatusFailed, ApplyStatusChanged},
+			expectedNotes: []RollbackNote{
+				{Kind: "ConfigMap", Namespace: "ns", Name: "config", Changed: []string{"data.key"}},
+				{Kind: "ServiceAccount", Namespace: "ns", Name: "sa", Created: true},
+				{Kind: "ConfigMap", Namespace: "ns", Name: "other", Created: true},
+			},
+		},
+		{
+			name:             "stop on fatal error",
+			objects:          objects(),
+			policy:           StopOnFatalError,
+			expectedStatuses: []ApplyStatus{ApplyStatusChanged, ApplyStatusChanged, ApplyStatusFailed, ApplyStatusSkipped},
+			expectedStopped:  true,
+			expectedNotes: []RollbackNote{
+				{Kind: "ConfigMap", Namespace: "ns", Name: "config", Changed: []string{"data.key"}},
+				{Kind: "ServiceAccount", Namespace: "ns", Name: "sa", Created: true},
+			},
+		},
+		{
+			name:             "no notes without failures",
+			objects:          objects()[:2],
+			policy:           StopOnError,
+			expectedStatuses: []ApplyStatus{ApplyStatusChanged, ApplyStatusChanged},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(existing.DeepCopy())
+			recorder := events.NewInMemoryRecorder("test")
+			report := ApplyAll(context.TODO(), NewKubeClientHolder(client), recorder, tt.objects, ApplyAllOptions{FailurePolicy: tt.policy, Cache: noCache})
+
+			var statuses []ApplyStatus
+			for _, result := range report.Results {
+				statuses = append(statuses, result.Status)
+			}
+			if !reflect.DeepEqual(statuses, tt.expectedStatuses) {
+				t.Errorf("expected statuses %v, got %v", tt.expectedStatuses, statuses)
+			}
+			if report.Stopped != tt.expectedStopped {
+				t.Errorf("expected stopped to be %v", tt.expectedStopped)
+			}
+			if !reflect.DeepEqual(report.RollbackNotes, tt.expectedNotes) {
+				t.Errorf("expected rollback notes %#v, got %#v", tt.expectedNotes, report.RollbackNotes)
+			}
+			if hasFailure := len(tt.expectedNotes) > 0; (report.Err() != nil) != hasFailure {
+				t.Errorf("unexpected error %v", report.Err())
+			}
+			for _, event := range recorder.Events() {
+				if event.Reason == "ConfigMapChangeReport" {
+					t.Errorf("unexpected change report event %q", event.Message)
+				}
+			}
+			if _, err := json.Marshal(report); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestRollbackNoteFor(t *testing.T) {
+	result := ObjectApplyResult{Kind: "ConfigMap", Namespace: "ns", Name: "config"}
+	tests := []struct {
+		name            string
+		targets         []bool
+		reports         []ChangeReport
+		expectedCreated bool
+	}{
+		{
+			name:            "created",
+			targets:         []bool{false},
+			expectedCreated: true,
+		},
+		{
+			name:    "updated without a change report",
+			targets: []bool{true},
+		},
+		{
+			name:    "recreated",
+			targets: []bool{true, false},
+		},
+		{
+			name:    "updated",
+			targets: []bool{true},
+			reports: []ChangeReport{{Changed: []string{"data.key"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, changes := WithChangeReports(context.TODO())
+			for _, existed := range tt.targets {
+				noteApplyTarget(ctx, existed)
+			}
+			for _, report := range tt.reports {
+				changes.add(report)
+			}
+			if note := rollbackNoteFor(result, changes); note.Created != tt.expectedCreated {
+				t.Errorf("expected created to be %v, got %#v", tt.expectedCreated, note)
+			}
+		})
+	}
+}
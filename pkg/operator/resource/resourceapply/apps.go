@@ -1,6 +1,7 @@
 package resourceapply
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/json"
@@ -58,11 +59,14 @@ const specHashAnnotation = "operator.openshift.io/spec-hash"
 // is exposed to support testing with fake clients that need to know the mutated form of the
 // resource resulting from an Apply<type> call.
 func SetSpecHashAnnotation(objMeta *metav1.ObjectMeta, spec interface{}) error {
-	jsonBytes, err := json.Marshal(spec)
-	if err != nil {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	// the encoder produces the same JSON as json.Marshal plus a trailing newline, which is not hashed to keep the
+	// hashes of existing objects stable
+	if err := json.NewEncoder(buf).Encode(spec); err != nil {
 		return err
 	}
-	specHash := fmt.Sprintf("%x", sha256.Sum256(jsonBytes))
+	specHash := fmt.Sprintf("%x", sha256.Sum256(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))))
 	if objMeta.Annotations == nil {
 		objMeta.Annotations = map[string]string{}
 	}
@@ -98,7 +102,7 @@ func ApplyDeployment(ctx context.Context, client appsclientv1.DeploymentsGetter,
 		return nil, false, err
 	}
 
-	return ApplyDeploymentWithForce(ctx, client, recorder, required, expectedGeneration, false)
+	return applyDeployment(ctx, client, recorder, required, expectedGeneration, false)
 }
 
 // ApplyDeploymentWithForce merges objectmeta and requires matching generation. It returns the final Object, whether any change as made, and an error.
@@ -107,7 +111,14 @@ func ApplyDeployment(ctx context.Context, client appsclientv1.DeploymentsGetter,
 func ApplyDeploymentWithForce(ctx context.Context, client appsclientv1.DeploymentsGetter, recorder events.Recorder, requiredOriginal *appsv1.Deployment, expectedGeneration int64,
 	forceRollout bool) (*appsv1.Deployment, bool, error) {
 
-	required := requiredOriginal.DeepCopy()
+	return applyDeployment(ctx, client, recorder, requiredOriginal.DeepCopy(), expectedGeneration, forceRollout)
+}
+
+// applyDeployment is ApplyDeploymentWithForce for a required deployment the caller owns, sparing the apply another
+// deep copy of it.
+func applyDeployment(ctx context.Context, client appsclientv1.DeploymentsGetter, recorder events.Recorder, required *appsv1.Deployment, expectedGeneration int64,
+	forceRollout bool) (*appsv1.Deployment, bool, error) {
+
 	if required.Annotations == nil {
 		required.Annotations = map[string]string{}
 	}
@@ -118,7 +129,7 @@ func ApplyDeploymentWithForce(ctx context.Context, client appsclientv1.Deploymen
 	}
 	existing, err := client.Deployments(required.Namespace).Get(ctx, required.Name, metav1.GetOptions{})
 	if apierrors.IsNotFound(err) {
-		actual, err := client.Deployments(required.Namespace).Create(ctx, required, metav1.CreateOptions{})
+		actual, err := client.Deployments(required.Namespace).Create(ctx, ownedForCreate(ctx, recorder, required), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, required, err)
 		return actual, true, err
 	}
@@ -130,6 +141,9 @@ func ApplyDeploymentWithForce(ctx context.Context, client appsclientv1.Deploymen
 	existingCopy := existing.DeepCopy()
 
 	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
 	// there was no change to metadata, the generation was right, and we weren't asked for force the deployment
 	if !modified && existingCopy.ObjectMeta.Generation == expectedGeneration && !forceRollout {
 		return existingCopy, false, nil
@@ -154,6 +168,7 @@ func ApplyDeploymentWithForce(ctx context.Context, client appsclientv1.Deploymen
 	if klog.V(2).Enabled() {
 		klog.Infof("Deployment %q changes: %v", required.Namespace+"/"+required.Name, JSONPatchNoError(existing, toWrite))
 	}
+	reportChanges(ctx, recorder, existing, toWrite)
 
 	actual, err := client.Deployments(required.Namespace).Update(ctx, toWrite, metav1.UpdateOptions{})
 	resourcehelper.ReportUpdateEvent(recorder, required, err)
@@ -188,13 +203,18 @@ func ApplyDaemonSet(ctx context.Context, client appsclientv1.DaemonSetsGetter, r
 		return nil, false, err
 	}
 
-	return ApplyDaemonSetWithForce(ctx, client, recorder, required, expectedGeneration, false)
+	return applyDaemonSet(ctx, client, recorder, required, expectedGeneration, false)
 }
 
 // ApplyDaemonSetWithForce merges objectmeta and requires matching generation. It returns the final Object, whether any change as made, and an error
 // DEPRECATED - This method will be removed in 4.6 and callers will need to migrate to ApplyDaemonSet before then.
 func ApplyDaemonSetWithForce(ctx context.Context, client appsclientv1.DaemonSetsGetter, recorder events.Recorder, requiredOriginal *appsv1.DaemonSet, expectedGeneration int64, forceRollout bool) (*appsv1.DaemonSet, bool, error) {
-	required := requiredOriginal.DeepCopy()
+	return applyDaemonSet(ctx, client, recorder, requiredOriginal.DeepCopy(), expectedGeneration, forceRollout)
+}
+
+// applyDaemonSet is ApplyDaemonSetWithForce for a required daemonset the caller owns, sparing the apply another deep
+// copy of it.
+func applyDaemonSet(ctx context.Context, client appsclientv1.DaemonSetsGetter, recorder events.Recorder, required *appsv1.DaemonSet, expectedGeneration int64, forceRollout bool) (*appsv1.DaemonSet, bool, error) {
 	if required.Annotations == nil {
 		required.Annotations = map[string]string{}
 	}
@@ -205,7 +225,7 @@ func ApplyDaemonSetWithForce(ctx context.Context, client appsclientv1.DaemonSets
 	}
 	existing, err := client.DaemonSets(required.Namespace).Get(ctx, required.Name, metav1.GetOptions{})
 	if apierrors.IsNotFound(err) {
-		actual, err := client.DaemonSets(required.Namespace).Create(ctx, required, metav1.CreateOptions{})
+		actual, err := client.DaemonSets(required.Namespace).Create(ctx, ownedForCreate(ctx, recorder, required), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, required, err)
 		return actual, true, err
 	}
@@ -217,6 +237,9 @@ func ApplyDaemonSetWithForce(ctx context.Context, client appsclientv1.DaemonSets
 	existingCopy := existing.DeepCopy()
 
 	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
 	// there was no change to metadata, the generation was right, and we weren't asked for force the deployment
 	if !modified && existingCopy.ObjectMeta.Generation == expectedGeneration && !forceRollout {
 		return existingCopy, false, nil
@@ -241,6 +264,7 @@ func ApplyDaemonSetWithForce(ctx context.Context, client appsclientv1.DaemonSets
 	if klog.V(2).Enabled() {
 		klog.Infof("DaemonSet %q changes: %v", required.Namespace+"/"+required.Name, JSONPatchNoError(existing, toWrite))
 	}
+	reportChanges(ctx, recorder, existing, toWrite)
 	actual, err := client.DaemonSets(required.Namespace).Update(ctx, toWrite, metav1.UpdateOptions{})
 	resourcehelper.ReportUpdateEvent(recorder, required, err)
 	return actual, true, err
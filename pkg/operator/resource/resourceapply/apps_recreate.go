@@ -0,0 +1,288 @@
+package resourceapply
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	appsclientv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcehelper"
+)
+
+// RecreatePolicy tells what to do when a required workload changes the selector of the existing workload, which
+// cannot be updated.
+type RecreatePolicy string
+
+const (
+	// RecreateNever keeps the existing workload, its updates fail like those of ApplyDeployment and ApplyDaemonSet.
+	RecreateNever RecreatePolicy = ""
+	// RecreateKeepingPods deletes the existing workload orphaning its pods and creates the required workload. The
+	// orphaned pods keep serving until the required workload is available, then they are deleted. The pods of both
+	// workloads must be able to run side by side, e.g. they must not bind the same host ports.
+	RecreateKeepingPods RecreatePolicy = "KeepingPods"
+	// RecreateDeletingPods deletes the existing workload with its pods and creates the required workload. The workload
+	// is unavailable until the new pods are ready.
+	RecreateDeletingPods RecreatePolicy = "DeletingPods"
+)
+
+// recreatedSelectorAnnotation holds the selector of the pods orphaned by the recreation of a workload, until they are
+// deleted.
+const recreatedSelectorAnnotation = "operator.openshift.io/recreated-selector"
+
+// IsRecreating returns true while the pods orphaned by the recreation of the workload with RecreateKeepingPods are
+// kept, waiting for the workload to be available.
+func IsRecreating(workload metav1.Object) bool {
+	_, ok := workload.GetAnnotations()[recreatedSelectorAnnotation]
+	return ok
+}
+
+// ApplyDeploymentWithRecreate is ApplyDeployment recreating the deployment according to the policy when the required
+// deployment changes its selector. With RecreateKeepingPods, the orphaned replica sets are deleted by the applies
+// following the recreation, once the deployment is available.
+func ApplyDeploymentWithRecreate(ctx context.Context, client appsclientv1.AppsV1Interface, recorder events.Recorder,
+	requiredOriginal *appsv1.Deployment, expectedGeneration int64, policy RecreatePolicy) (*appsv1.Deployment, bool, error) {
+
+	required := requiredOriginal.DeepCopy()
+	if err := SetSpecHashAnnotation(&required.ObjectMeta, required.Spec); err != nil {
+		return nil, false, err
+	}
+	if policy == RecreateNever {
+		return applyDeployment(ctx, client, recorder, required, expectedGeneration, false)
+	}
+
+	existing, err := client.Deployments(required.Namespace).Get(ctx, required.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return nil, false, err
+	case !apiequality.Semantic.DeepEqual(existing.Spec.Selector, required.Spec.Selector):
+		return recreateDeployment(ctx, client, recorder, existing, required, policy)
+	case IsRecreating(existing) && isDeploymentAvailable(existing):
+		if err := deleteOrphanedReplicaSets(ctx, client, recorder, existing); err != nil {
+			return nil, false, err
+		}
+		required.Annotations[recreatedSelectorAnnotation+"-"] = ""
+	}
+	return applyDeployment(ctx, client, recorder, required, expectedGeneration, false)
+}
+
+func recreateDeployment(ctx context.Context, client appsclientv1.DeploymentsGetter, recorder events.Recorder, existing, required *appsv1.Deployment, policy RecreatePolicy) (*appsv1.Deployment, bool, error) {
+	propagation := metav1.DeletePropagationBackground
+	if policy == RecreateKeepingPods {
+		propagation = metav1.DeletePropagationOrphan
+		if err := setRecreatedSelector(&required.ObjectMeta, existing.Spec.Selector); err != nil {
+			return nil, false, err
+		}
+	}
+
+	// the required workload replaces the existing one, refuse to delete a workload owned by someone else
+	if err := enforceOwnership(ctx, recorder, existing, required, new(bool)); err != nil {
+		return nil, false, err
+	}
+
+	if existing.DeletionTimestamp == nil {
+		recorder.Warningf("DeploymentRecreating", "Recreating deployment %s/%s because its selector changed from %q to %q",
+			existing.Namespace, existing.Name, metav1.FormatLabelSelector(existing.Spec.Selector), metav1.FormatLabelSelector(required.Spec.Selector))
+		err := client.Deployments(existing.Namespace).Delete(ctx, existing.Name, metav1.DeleteOptions{
+			Preconditions:     &metav1.Preconditions{UID: &existing.UID},
+			PropagationPolicy: &propagation,
+		})
+		if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+			resourcehelper.ReportDeleteEvent(recorder, existing, err)
+			return nil, false, err
+		}
+		resourcehelper.ReportDeleteEvent(recorder, existing, nil)
+	}
+
+	actual, err := client.Deployments(required.Namespace).Create(ctx, ownedForCreate(ctx, recorder, required), metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		// the garbage collector has not finished deleting the existing deployment yet
+		return nil, true, fmt.Errorf("deployment %s/%s is being deleted to be recreated", required.Namespace, required.Name)
+	}
+	resourcehelper.ReportCreateEvent(recorder, required, err)
+	return actual, true, err
+}
+
+// isDeploymentAvailable returns true when all the replicas of the deployment are updated and available.
+func isDeploymentAvailable(deployment *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	return deployment.Status.ObservedGeneration >= deployment.Generation &&
+		deployment.Status.UpdatedReplicas >= replicas &&
+		deployment.Status.AvailableReplicas >= replicas &&
+		deployment.Status.UnavailableReplicas == 0
+}
+
+// deleteOrphanedReplicaSets deletes the replica sets orphaned by the recreation of the deployment, with their pods.
+func deleteOrphanedReplicaSets(ctx context.Context, client appsclientv1.ReplicaSetsGetter, recorder events.Recorder, deployment *appsv1.Deployment) error {
+	selector, err := recreatedSelector(deployment)
+	if err != nil {
+		return err
+	}
+	replicaSets, err := client.ReplicaSets(deployment.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i := range replicaSets.Items {
+		replicaSet := &replicaSets.Items[i]
+		if metav1.GetControllerOf(replicaSet) != nil {
+			continue
+		}
+		err := client.ReplicaSets(replicaSet.Namespace).Delete(ctx, replicaSet.Name, metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{UID: &replicaSet.UID},
+		})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		resourcehelper.ReportDeleteEvent(recorder, replicaSet, err)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// ApplyDaemonSetWithRecreate is ApplyDaemonSet recreating the daemonset according to the policy when the required
+// daemonset changes its selector. With RecreateKeepingPods, the orphaned pods are deleted by the applies following
+// the recreation, once the daemonset is available.
+func ApplyDaemonSetWithRecreate(ctx context.Context, client appsclientv1.DaemonSetsGetter, podClient corev1client.PodsGetter, recorder events.Recorder,
+	requiredOriginal *appsv1.DaemonSet, expectedGeneration int64, policy RecreatePolicy) (*appsv1.DaemonSet, bool, error) {
+
+	required := requiredOriginal.DeepCopy()
+	if err := SetSpecHashAnnotation(&required.ObjectMeta, required.Spec); err != nil {
+		return nil, false, err
+	}
+	if policy == RecreateNever {
+		return applyDaemonSet(ctx, client, recorder, required, expectedGeneration, false)
+	}
+
+	existing, err := client.DaemonSets(required.Namespace).Get(ctx, required.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return nil, false, err
+	case !apiequality.Semantic.DeepEqual(existing.Spec.Selector, required.Spec.Selector):
+		return recreateDaemonSet(ctx, client, recorder, existing, required, policy)
+	case IsRecreating(existing) && isDaemonSetAvailable(existing):
+		if err := deleteOrphanedPods(ctx, podClient, recorder, existing); err != nil {
+			return nil, false, err
+		}
+		required.Annotations[recreatedSelectorAnnotation+"-"] = ""
+	}
+	return applyDaemonSet(ctx, client, recorder, required, expectedGeneration, false)
+}
+
+func recreateDaemonSet(ctx context.Context, client appsclientv1.DaemonSetsGetter, recorder events.Recorder, existing, required *appsv1.DaemonSet, policy RecreatePolicy) (*appsv1.DaemonSet, bool, error) {
+	propagation := metav1.DeletePropagationBackground
+	if policy == RecreateKeepingPods {
+		propagation = metav1.DeletePropagationOrphan
+		if err := setRecreatedSelector(&required.ObjectMeta, existing.Spec.Selector); err != nil {
+			return nil, false, err
+		}
+	}
+
+	// the required workload replaces the existing one, refuse to delete a workload owned by someone else
+	if err := enforceOwnership(ctx, recorder, existing, required, new(bool)); err != nil {
+		return nil, false, err
+	}
+
+	if existing.DeletionTimestamp == nil {
+		recorder.Warningf("DaemonSetRecreating", "Recreating daemonset %s/%s because its selector changed from %q to %q",
+			existing.Namespace, existing.Name, metav1.FormatLabelSelector(existing.Spec.Selector), metav1.FormatLabelSelector(required.Spec.Selector))
+		err := client.DaemonSets(existing.Namespace).Delete(ctx, existing.Name, metav1.DeleteOptions{
+			Preconditions:     &metav1.Preconditions{UID: &existing.UID},
+			PropagationPolicy: &propagation,
+		})
+		if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+			resourcehelper.ReportDeleteEvent(recorder, existing, err)
+			return nil, false, err
+		}
+		resourcehelper.ReportDeleteEvent(recorder, existing, nil)
+	}
+
+	actual, err := client.DaemonSets(required.Namespace).Create(ctx, ownedForCreate(ctx, recorder, required), metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		// the garbage collector has not finished deleting the existing daemonset yet
+		return nil, true, fmt.Errorf("daemonset %s/%s is being deleted to be recreated", required.Namespace, required.Name)
+	}
+	resourcehelper.ReportCreateEvent(recorder, required, err)
+	return actual, true, err
+}
+
+// isDaemonSetAvailable returns true when the pods of the daemonset are updated and available on all its nodes.
+func isDaemonSetAvailable(daemonSet *appsv1.DaemonSet) bool {
+	return daemonSet.Status.ObservedGeneration >= daemonSet.Generation &&
+		daemonSet.Status.UpdatedNumberScheduled >= daemonSet.Status.DesiredNumberScheduled &&
+		daemonSet.Status.NumberAvailable >= daemonSet.Status.DesiredNumberScheduled &&
+		daemonSet.Status.NumberUnavailable == 0
+}
+
+// deleteOrphanedPods deletes the pods orphaned by the recreation of the daemonset.
+func deleteOrphanedPods(ctx context.Context, client corev1client.PodsGetter, recorder events.Recorder, daemonSet *appsv1.DaemonSet) error {
+	selector, err := recreatedSelector(daemonSet)
+	if err != nil {
+		return err
+	}
+	pods, err := client.Pods(daemonSet.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if metav1.GetControllerOf(pod) != nil {
+			continue
+		}
+		err := client.Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{UID: &pod.UID},
+		})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		resourcehelper.ReportDeleteEvent(recorder, pod, err)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func setRecreatedSelector(objMeta *metav1.ObjectMeta, selector *metav1.LabelSelector) error {
+	data, err := json.Marshal(selector)
+	if err != nil {
+		return err
+	}
+	if objMeta.Annotations == nil {
+		objMeta.Annotations = map[string]string{}
+	}
+	objMeta.Annotations[recreatedSelectorAnnotation] = string(data)
+	return nil
+}
+
+func recreatedSelector(workload metav1.Object) (labels.Selector, error) {
+	selector := &metav1.LabelSelector{}
+	if err := json.Unmarshal([]byte(workload.GetAnnotations()[recreatedSelectorAnnotation]), selector); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation of %s/%s: %w", recreatedSelectorAnnotation, workload.GetNamespace(), workload.GetName(), err)
+	}
+	ret, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	// an empty selector matches everything, never delete the whole namespace
+	if ret.Empty() {
+		return nil, fmt.Errorf("empty %s annotation of %s/%s", recreatedSelectorAnnotation, workload.GetNamespace(), workload.GetName())
+	}
+	return ret, nil
+}
@@ -7,9 +7,12 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/diff"
 	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
 	"k8s.io/utils/ptr"
 
 	"github.com/openshift/library-go/pkg/operator/events"
@@ -310,6 +313,182 @@ func TestApplyDeploymentWithForce(t *testing.T) {
 	}
 }
 
+func TestApplyDeploymentWithRecreate(t *testing.T) {
+	withSelector := func(d *appsv1.Deployment, app string) *appsv1.Deployment {
+		d.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}}
+		d.Spec.Template.Labels = map[string]string{"app": app}
+		return d
+	}
+	existing := func() *appsv1.Deployment {
+		d := withSelector(workload(), "old")
+		d.UID = "old"
+		return d
+	}
+	recreated := func(available bool) *appsv1.Deployment {
+		d := withSelector(workload(), "new")
+		d.UID = "new"
+		d.Generation = 1
+		d.Annotations["operator.openshift.io/recreated-selector"] = `{"matchLabels":{"app":"old"}}`
+		d.Status = appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3}
+		if !available {
+			d.Status.AvailableReplicas = 1
+			d.Status.UnavailableReplicas = 2
+		}
+		return d
+	}
+	replicaSet := func(name, app string, owned bool) *appsv1.ReplicaSet {
+		rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-apiserver",
+			Name:      name,
+			Labels:    map[string]string{"app": app},
+		}}
+		if owned {
+			rs.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "apiserver", UID: "new", Controller: ptr.To(true)}}
+		}
+		return rs
+	}
+
+	tests := []struct {
+		name     string
+		existing []runtime.Object
+		policy   resourceapply.RecreatePolicy
+
+		expectError          bool
+		expectedPropagation  metav1.DeletionPropagation
+		expectedRecreating   bool
+		expectedReplicaSets  []string
+		expectedNotRecreated bool
+	}{
+		{
+			name:                 "the selector change is applied as an update without a policy",
+			existing:             []runtime.Object{existing()},
+			policy:               resourceapply.RecreateNever,
+			expectedNotRecreated: true,
+		},
+		{
+			name:                "the deployment is recreated orphaning its replica sets",
+			existing:            []runtime.Object{existing()},
+			policy:              resourceapply.RecreateKeepingPods,
+			expectedPropagation: metav1.DeletePropagationOrphan,
+			expectedRecreating:  true,
+		},
+		{
+			name:                "the deployment is recreated with its replica sets",
+			existing:            []runtime.Object{existing()},
+			policy:              resourceapply.RecreateDeletingPods,
+			expectedPropagation: metav1.DeletePropagationBackground,
+		},
+		{
+			name:                 "the orphaned replica sets are kept until the deployment is available",
+			existing:             []runtime.Object{recreated(false), replicaSet("orphan", "old", false), replicaSet("owned", "new", true)},
+			policy:               resourceapply.RecreateKeepingPods,
+			expectedRecreating:   true,
+			expectedReplicaSets:  []string{"orphan", "owned"},
+			expectedNotRecreated: true,
+		},
+		{
+			name:                 "the orphaned replica sets are deleted once the deployment is available",
+			existing:             []runtime.Object{recreated(true), replicaSet("orphan", "old", false), replicaSet("owned", "new", true), replicaSet("adopted", "old", true), replicaSet("other", "other", false)},
+			policy:               resourceapply.RecreateKeepingPods,
+			expectedReplicaSets:  []string{"adopted", "other", "owned"},
+			expectedNotRecreated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(tt.existing...)
+			required := withSelector(workload(), "new")
+
+			_, _, err := resourceapply.ApplyDeploymentWithRecreate(context.TODO(), client.AppsV1(), events.NewInMemoryRecorder(""), required, 1, tt.policy)
+			if tt.expectError != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tt.expectError, err)
+			}
+
+			var deleted, created bool
+			for _, action := range client.Actions() {
+				if action.GetResource().Resource != "deployments" {
+					continue
+				}
+				switch action.GetVerb() {
+				case "delete":
+					deleted = true
+					propagation := action.(clienttesting.DeleteAction).GetDeleteOptions().PropagationPolicy
+					if propagation == nil || *propagation != tt.expectedPropagation {
+						t.Errorf("expected the deployment to be deleted with the %q propagation, got %v", tt.expectedPropagation, propagation)
+					}
+				case "create":
+					created = true
+				}
+			}
+			if deleted != !tt.expectedNotRecreated || created != !tt.expectedNotRecreated {
+				t.Errorf("expected the deployment to be recreated: %v, got deleted: %v, created: %v", !tt.expectedNotRecreated, deleted, created)
+			}
+
+			actual, err := client.AppsV1().Deployments("openshift-apiserver").Get(context.TODO(), "apiserver", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !equality.Semantic.DeepEqual(actual.Spec.Selector, required.Spec.Selector) {
+				t.Errorf("expected the selector %v, got %v", required.Spec.Selector, actual.Spec.Selector)
+			}
+			if recreating := resourceapply.IsRecreating(actual); recreating != tt.expectedRecreating {
+				t.Errorf("expected the deployment to be recreating: %v, got %v", tt.expectedRecreating, recreating)
+			}
+
+			replicaSets, err := client.AppsV1().ReplicaSets("openshift-apiserver").List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, rs := range replicaSets.Items {
+				names = append(names, rs.Name)
+			}
+			if !equality.Semantic.DeepEqual(names, tt.expectedReplicaSets) {
+				t.Errorf("expected the replica sets %v, got %v", tt.expectedReplicaSets, names)
+			}
+		})
+	}
+}
+
+func TestApplyDaemonSetWithRecreate(t *testing.T) {
+	required := daemonSet()
+	required.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "new"}}
+	existing := daemonSet()
+	existing.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "old"}}
+	existing.Status = appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, UpdatedNumberScheduled: 2, NumberAvailable: 2}
+	orphan := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-apiserver", Name: "orphan", Labels: map[string]string{"app": "old"}}}
+	client := fake.NewSimpleClientset(existing, orphan)
+	recorder := events.NewInMemoryRecorder("")
+
+	// the first apply recreates the daemonset, keeping the orphaned pod
+	actual, _, err := resourceapply.ApplyDaemonSetWithRecreate(context.TODO(), client.AppsV1(), client.CoreV1(), recorder, required, 0, resourceapply.RecreateKeepingPods)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resourceapply.IsRecreating(actual) {
+		t.Fatal("expected the daemonset to be recreating")
+	}
+	if _, err := client.CoreV1().Pods("openshift-apiserver").Get(context.TODO(), "orphan", metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected the orphaned pod to be kept: %v", err)
+	}
+
+	// the daemonset becomes available, the next apply deletes the orphaned pod
+	actual.Status = existing.Status
+	if _, err := client.AppsV1().DaemonSets("openshift-apiserver").UpdateStatus(context.TODO(), actual, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	actual, _, err = resourceapply.ApplyDaemonSetWithRecreate(context.TODO(), client.AppsV1(), client.CoreV1(), recorder, required, 0, resourceapply.RecreateKeepingPods)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resourceapply.IsRecreating(actual) {
+		t.Error("expected the daemonset to be recreated")
+	}
+	if _, err := client.CoreV1().Pods("openshift-apiserver").Get(context.TODO(), "orphan", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the orphaned pod to be deleted, got %v", err)
+	}
+}
+
 func workload() *appsv1.Deployment {
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,81 @@
+package resourceapply
+
+import (
+	"context"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	autoscalingclientv2 "k8s.io/client-go/kubernetes/typed/autoscaling/v2"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcehelper"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+)
+
+// ApplyHorizontalPodAutoscaler merges objectmeta and requires the spec. Fields the API server defaults, like the
+// minimum replicas, metrics and scaling behavior, are only updated when the required spec sets them to other values.
+// The status is left to the autoscaler.
+func ApplyHorizontalPodAutoscaler(ctx context.Context, client autoscalingclientv2.HorizontalPodAutoscalersGetter, recorder events.Recorder,
+	requiredOriginal *autoscalingv2.HorizontalPodAutoscaler, cache ResourceCache) (*autoscalingv2.HorizontalPodAutoscaler, bool, error) {
+
+	existing, err := client.HorizontalPodAutoscalers(requiredOriginal.Namespace).Get(ctx, requiredOriginal.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		required := requiredOriginal.DeepCopy()
+		actual, err := client.HorizontalPodAutoscalers(required.Namespace).Create(
+			ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, required.DeepCopy())).(*autoscalingv2.HorizontalPodAutoscaler), metav1.CreateOptions{})
+		resourcehelper.ReportCreateEvent(recorder, required, err)
+		if err != nil {
+			return nil, false, err
+		}
+		cache.UpdateCachedResourceMetadata(requiredOriginal, actual)
+		return actual, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if cache.SafeToSkipApply(requiredOriginal, existing) {
+		return existing, false, nil
+	}
+
+	required := requiredOriginal.DeepCopy()
+	modified := false
+	existingCopy := existing.DeepCopy()
+
+	// the spec is compared with the defaults of the API server applied to the required one
+	resourcemerge.EnsureHorizontalPodAutoscaler(&modified, existingCopy, *required)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
+	if !modified {
+		cache.UpdateCachedResourceMetadata(requiredOriginal, existingCopy)
+		return existingCopy, false, nil
+	}
+
+	if klog.V(2).Enabled() {
+		klog.Infof("HorizontalPodAutoscaler %q changes: %v", required.Namespace+"/"+required.Name, JSONPatchNoError(existing, existingCopy))
+	}
+	reportChanges(ctx, recorder, existing, existingCopy)
+
+	actual, err := client.HorizontalPodAutoscalers(required.Namespace).Update(ctx, existingCopy, metav1.UpdateOptions{})
+	resourcehelper.ReportUpdateEvent(recorder, required, err)
+	if err != nil {
+		return nil, false, err
+	}
+	cache.UpdateCachedResourceMetadata(requiredOriginal, actual)
+	return actual, true, nil
+}
+
+func DeleteHorizontalPodAutoscaler(ctx context.Context, client autoscalingclientv2.HorizontalPodAutoscalersGetter, recorder events.Recorder, required *autoscalingv2.HorizontalPodAutoscaler) (*autoscalingv2.HorizontalPodAutoscaler, bool, error) {
+	err := client.HorizontalPodAutoscalers(required.Namespace).Delete(ctx, required.Name, metav1.DeleteOptions{})
+	if err != nil && apierrors.IsNotFound(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	resourcehelper.ReportDeleteEvent(recorder, required, err)
+	return nil, true, nil
+}
@@ -0,0 +1,117 @@
+package resourceapply
+
+import (
+	"context"
+	"testing"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestApplyHorizontalPodAutoscaler(t *testing.T) {
+	required := &autoscalingv2.HorizontalPodAutoscaler{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "autoscaling/v2", Kind: "HorizontalPodAutoscaler"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "hpa", Labels: map[string]string{"app": "foo"}},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "foo"},
+			MinReplicas:    ptr.To[int32](1),
+			MaxReplicas:    3,
+			// the fake client does not default the metrics
+			Metrics: []autoscalingv2.MetricSpec{{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{
+					Name:   corev1.ResourceMemory,
+					Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: ptr.To[int32](70)},
+				},
+			}},
+		},
+	}
+	client := fake.NewSimpleClientset()
+	cache := NewResourceCache()
+	recorder := events.NewInMemoryRecorder("test")
+
+	actual, modified, err := ApplyHorizontalPodAutoscaler(context.TODO(), client.AutoscalingV2(), recorder, required, cache)
+	if err != nil || !modified {
+		t.Fatalf("expected create, got modified=%v err=%v", modified, err)
+	}
+
+	// the autoscaler updates the status, which is left alone
+	actual.Status.CurrentReplicas = 2
+	if _, err := client.AutoscalingV2().HorizontalPodAutoscalers("ns").UpdateStatus(context.TODO(), actual, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	client.ClearActions()
+	if _, modified, err := ApplyHorizontalPodAutoscaler(context.TODO(), client.AutoscalingV2(), recorder, required, cache); err != nil || modified {
+		t.Fatalf("expected no change, got modified=%v err=%v", modified, err)
+	}
+
+	changed := required.DeepCopy()
+	changed.Spec.MaxReplicas = 5
+	actual, modified, err = ApplyHorizontalPodAutoscaler(context.TODO(), client.AutoscalingV2(), recorder, changed, cache)
+	if err != nil || !modified {
+		t.Fatalf("expected update, got modified=%v err=%v", modified, err)
+	}
+	if actual.Spec.MaxReplicas != 5 || actual.Status.CurrentReplicas != 2 {
+		t.Errorf("unexpected result: %#v", actual)
+	}
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "update" && action.GetSubresource() != "" {
+			t.Errorf("unexpected subresource update: %v", action.(clienttesting.UpdateAction))
+		}
+	}
+}
+
+func TestApplyHorizontalPodAutoscalerWithServerDefaults(t *testing.T) {
+	required := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "hpa"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "foo"},
+			MaxReplicas:    3,
+			Behavior: &autoscalingv2.HorizontalPodAutoscalerBehavior{
+				ScaleDown: &autoscalingv2.HPAScalingRules{StabilizationWindowSeconds: ptr.To[int32](60)},
+			},
+		},
+	}
+	// the existing autoscaler as the API server defaults it
+	existing := required.DeepCopy()
+	existing.Spec.MinReplicas = ptr.To[int32](1)
+	existing.Spec.Metrics = []autoscalingv2.MetricSpec{{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name:   corev1.ResourceCPU,
+			Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: ptr.To[int32](80)},
+		},
+	}}
+	existing.Spec.Behavior.ScaleUp = &autoscalingv2.HPAScalingRules{
+		StabilizationWindowSeconds: ptr.To[int32](0),
+		SelectPolicy:               ptr.To(autoscalingv2.MaxChangePolicySelect),
+		Policies: []autoscalingv2.HPAScalingPolicy{
+			{Type: autoscalingv2.PodsScalingPolicy, Value: 4, PeriodSeconds: 15},
+			{Type: autoscalingv2.PercentScalingPolicy, Value: 100, PeriodSeconds: 15},
+		},
+	}
+	existing.Spec.Behavior.ScaleDown.SelectPolicy = ptr.To(autoscalingv2.MaxChangePolicySelect)
+	existing.Spec.Behavior.ScaleDown.Policies = []autoscalingv2.HPAScalingPolicy{{Type: autoscalingv2.PercentScalingPolicy, Value: 100, PeriodSeconds: 15}}
+	client := fake.NewSimpleClientset(existing)
+	recorder := events.NewInMemoryRecorder("test")
+
+	if _, modified, err := ApplyHorizontalPodAutoscaler(context.TODO(), client.AutoscalingV2(), recorder, required, noCache); err != nil || modified {
+		t.Fatalf("expected no change of the defaulted autoscaler, got modified=%v err=%v", modified, err)
+	}
+
+	changed := required.DeepCopy()
+	changed.Spec.MinReplicas = ptr.To[int32](2)
+	actual, modified, err := ApplyHorizontalPodAutoscaler(context.TODO(), client.AutoscalingV2(), recorder, changed, noCache)
+	if err != nil || !modified {
+		t.Fatalf("expected update, got modified=%v err=%v", modified, err)
+	}
+	if *actual.Spec.MinReplicas != 2 || len(actual.Spec.Metrics) != 1 {
+		t.Errorf("unexpected spec: %#v", actual.Spec)
+	}
+}
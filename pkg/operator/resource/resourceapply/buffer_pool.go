@@ -0,0 +1,30 @@
+package resourceapply
+
+import (
+	"bytes"
+	"sync"
+)
+
+// bufferPool holds the buffers objects are serialized into for hashing and diffing. Operators applying hundreds of
+// manifests per sync would otherwise allocate a fresh buffer for each of them.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return &bytes.Buffer{}
+	},
+}
+
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// maxPooledBufferSize keeps exceptionally large buffers, e.g. of huge CRDs, from being pinned by the pool.
+const maxPooledBufferSize = 1 << 20
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	bufferPool.Put(buf)
+}
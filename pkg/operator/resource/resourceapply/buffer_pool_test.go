@@ -0,0 +1,90 @@
+package resourceapply
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetSpecHashAnnotationIsStable(t *testing.T) {
+	deployment := benchmarkDeployment()
+	jsonBytes, err := json.Marshal(deployment.Spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := fmt.Sprintf("%x", sha256.Sum256(jsonBytes))
+
+	for i := 0; i < 2; i++ {
+		if err := SetSpecHashAnnotation(&deployment.ObjectMeta, deployment.Spec); err != nil {
+			t.Fatal(err)
+		}
+		if actual := deployment.Annotations[specHashAnnotation]; actual != expected {
+			t.Errorf("expected the hash of the marshalled spec %s, got %s", expected, actual)
+		}
+	}
+}
+
+func benchmarkDeployment() *appsv1.Deployment {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "operand", ResourceVersion: "42", Labels: map[string]string{"app": "operand"}},
+	}
+	for i := 0; i < 5; i++ {
+		container := corev1.Container{
+			Name:    fmt.Sprintf("container-%d", i),
+			Image:   "registry.example.com/operand:latest",
+			Command: []string{"operand", "--config=/etc/operand/config.yaml", "--v=2"},
+		}
+		for j := 0; j < 10; j++ {
+			container.Env = append(container.Env, corev1.EnvVar{Name: fmt.Sprintf("ENV_%d", j), Value: strings.Repeat("x", 64)})
+		}
+		deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers, container)
+	}
+	return deployment
+}
+
+func BenchmarkSetSpecHashAnnotation(b *testing.B) {
+	deployment := benchmarkDeployment()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := SetSpecHashAnnotation(&deployment.ObjectMeta, deployment.Spec); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHashOfResourceStruct(b *testing.B) {
+	deployment := benchmarkDeployment()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		hashOfResourceStruct(deployment)
+	}
+}
+
+func BenchmarkJSONPatch(b *testing.B) {
+	existing := benchmarkDeployment()
+	modified := existing.DeepCopy()
+	modified.Spec.Template.Spec.Containers[0].Image = "registry.example.com/operand:next"
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		JSONPatchNoError(existing, modified)
+	}
+}
+
+func BenchmarkNewChangeReport(b *testing.B) {
+	existing := benchmarkDeployment()
+	modified := existing.DeepCopy()
+	modified.Spec.Template.Spec.Containers[0].Image = "registry.example.com/operand:next"
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewChangeReport(existing, modified); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
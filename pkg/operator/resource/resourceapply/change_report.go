@@ -0,0 +1,210 @@
+package resourceapply
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// ChangeReport lists the fields an apply changed on an existing object. It only contains field paths, never values,
+// so it is safe to report for secrets as well.
+type ChangeReport struct {
+	Kind      string
+	Namespace string
+	Name      string
+
+	Added   []string
+	Changed []string
+	Removed []string
+}
+
+// Empty returns true when no field was changed.
+func (r ChangeReport) Empty() bool {
+	return len(r.Added) == 0 && len(r.Changed) == 0 && len(r.Removed) == 0
+}
+
+func (r ChangeReport) String() string {
+	name := r.Name
+	if len(r.Namespace) > 0 {
+		name = r.Namespace + "/" + r.Name
+	}
+	var changes []string
+	if len(r.Added) > 0 {
+		changes = append(changes, fmt.Sprintf("added %s", strings.Join(r.Added, ",")))
+	}
+	if len(r.Changed) > 0 {
+		changes = append(changes, fmt.Sprintf("changed %s", strings.Join(r.Changed, ",")))
+	}
+	if len(r.Removed) > 0 {
+		changes = append(changes, fmt.Sprintf("removed %s", strings.Join(r.Removed, ",")))
+	}
+	if len(changes) == 0 {
+		return fmt.Sprintf("%s %q: no changes", r.Kind, name)
+	}
+	return fmt.Sprintf("%s %q: %s", r.Kind, name, strings.Join(changes, "; "))
+}
+
+// NewChangeReport compares the original and the modified object. Maps are compared key by key, lists are reported
+// as a single changed field.
+func NewChangeReport(original, modified runtime.Object) (ChangeReport, error) {
+	report := ChangeReport{Kind: objectKind(modified)}
+	if accessor, err := meta.Accessor(modified); err == nil {
+		report.Namespace = accessor.GetNamespace()
+		report.Name = accessor.GetName()
+	}
+	originalContent, err := toUnstructuredContent(original)
+	if err != nil {
+		return report, err
+	}
+	modifiedContent, err := toUnstructuredContent(modified)
+	if err != nil {
+		return report, err
+	}
+	diffFields("", originalContent, modifiedContent, &report)
+	sort.Strings(report.Added)
+	sort.Strings(report.Changed)
+	sort.Strings(report.Removed)
+	return report, nil
+}
+
+// ChangeReports collects the change reports of all applies done with a context returned by WithChangeReports.
+type ChangeReports struct {
+	lock    sync.Mutex
+	reports []ChangeReport
+	// quiet reports are only collected, not emitted as events.
+	quiet bool
+	// created and existed record whether an apply created an object or found an existing one.
+	created bool
+	existed bool
+}
+
+// Reports returns the collected change reports.
+func (c *ChangeReports) Reports() []ChangeReport {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]ChangeReport{}, c.reports...)
+}
+
+func (c *ChangeReports) add(report ChangeReport) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.reports = append(c.reports, report)
+}
+
+// createdOnly returns true when the applies only created objects, i.e. none of them found an existing object. It is
+// independent of the reports, since an update may change nothing reportable or fail to compute its report.
+func (c *ChangeReports) createdOnly() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.created && !c.existed
+}
+
+type changeReportsKey struct{}
+
+// noteApplyTarget records on the change reports of the context, if any, whether the apply is about to create an object
+// or to write an existing one.
+func noteApplyTarget(ctx context.Context, existed bool) {
+	reports, _ := ctx.Value(changeReportsKey{}).(*ChangeReports)
+	if reports == nil {
+		return
+	}
+	reports.lock.Lock()
+	defer reports.lock.Unlock()
+	if existed {
+		reports.existed = true
+	} else {
+		reports.created = true
+	}
+}
+
+// WithChangeReports returns a context which makes the Apply* functions collect a change report for every update
+// they do and emit it as an event. The reports are available through the returned ChangeReports.
+func WithChangeReports(ctx context.Context) (context.Context, *ChangeReports) {
+	reports := &ChangeReports{}
+	return context.WithValue(ctx, changeReportsKey{}, reports), reports
+}
+
+// reportChanges logs the changes the apply is about to make at V(4). When change reports were requested through
+// the context, the report is also collected and emitted as an event. It also feeds the conflict detector, if any.
+func reportChanges(ctx context.Context, recorder events.Recorder, original, modified runtime.Object) {
+	detectConflicts(ctx, recorder, original)
+
+	reports, _ := ctx.Value(changeReportsKey{}).(*ChangeReports)
+	if reports == nil && !klog.V(4).Enabled() {
+		return
+	}
+	report, err := NewChangeReport(original, modified)
+	if err != nil {
+		klog.V(4).Infof("Unable to compute change report: %v", err)
+		return
+	}
+	klog.V(4).Infof("Change report: %s", report)
+	if reports == nil {
+		return
+	}
+	reports.add(report)
+	if !reports.quiet {
+		recorder.Eventf(report.Kind+"ChangeReport", "%s", report)
+	}
+}
+
+func objectKind(obj runtime.Object) string {
+	if kind := obj.GetObjectKind().GroupVersionKind().Kind; len(kind) > 0 {
+		return kind
+	}
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+func toUnstructuredContent(obj runtime.Object) (map[string]interface{}, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u.UnstructuredContent(), nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
+
+func diffFields(prefix string, original, modified map[string]interface{}, report *ChangeReport) {
+	for key, modifiedValue := range modified {
+		path := joinFieldPath(prefix, key)
+		originalValue, exists := original[key]
+		if !exists {
+			report.Added = append(report.Added, path)
+			continue
+		}
+		originalMap, originalIsMap := originalValue.(map[string]interface{})
+		modifiedMap, modifiedIsMap := modifiedValue.(map[string]interface{})
+		if originalIsMap && modifiedIsMap {
+			diffFields(path, originalMap, modifiedMap, report)
+			continue
+		}
+		if !equality.Semantic.DeepEqual(originalValue, modifiedValue) {
+			report.Changed = append(report.Changed, path)
+		}
+	}
+	for key := range original {
+		if _, exists := modified[key]; !exists {
+			report.Removed = append(report.Removed, joinFieldPath(prefix, key))
+		}
+	}
+}
+
+func joinFieldPath(prefix, key string) string {
+	if len(prefix) == 0 {
+		return key
+	}
+	return prefix + "." + key
+}
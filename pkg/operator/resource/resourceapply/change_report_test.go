@@ -0,0 +1,102 @@
+package resourceapply
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestNewChangeReport(t *testing.T) {
+	original := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cm", Labels: map[string]string{"a": "1", "b": "2"}},
+		Data:       map[string]string{"keep": "x", "change": "x", "remove": "x"},
+	}
+	modified := original.DeepCopy()
+	modified.Labels = map[string]string{"a": "1", "c": "3"}
+	modified.Data = map[string]string{"keep": "x", "change": "y", "add": "x"}
+
+	report, err := NewChangeReport(original, modified)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := ChangeReport{
+		Kind:      "ConfigMap",
+		Namespace: "ns",
+		Name:      "cm",
+		Added:     []string{"data.add", "metadata.labels.c"},
+		Changed:   []string{"data.change"},
+		Removed:   []string{"data.remove", "metadata.labels.b"},
+	}
+	if !reflect.DeepEqual(expected, report) {
+		t.Errorf("expected %#v, got %#v", expected, report)
+	}
+	if report.Empty() {
+		t.Errorf("expected report to not be empty")
+	}
+}
+
+func TestApplyWithChangeReports(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "secret"},
+		Data:       map[string][]byte{"key": []byte("old")},
+	})
+	recorder := events.NewInMemoryRecorder("test")
+	ctx, reports := WithChangeReports(context.TODO())
+
+	_, modified, err := ApplySecret(ctx, client.CoreV1(), recorder, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "secret"},
+		Data:       map[string][]byte{"key": []byte("new")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !modified {
+		t.Fatal("expected the secret to be modified")
+	}
+
+	actual := reports.Reports()
+	if len(actual) != 1 || !reflect.DeepEqual(actual[0].Changed, []string{"data.key"}) {
+		t.Fatalf("unexpected reports: %#v", actual)
+	}
+	found := false
+	for _, event := range recorder.Events() {
+		if event.Reason == "SecretChangeReport" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a SecretChangeReport event, got %v", recorder.Events())
+	}
+}
+
+func TestApplyConfigMapReportsChangesBeforeUpdate(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "config"},
+		Data:       map[string]string{"key": "old"},
+	})
+	ctx, reports := WithChangeReports(context.TODO())
+	reportedBeforeUpdate := false
+	client.PrependReactor("update", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		reportedBeforeUpdate = len(reports.Reports()) == 1
+		return false, nil, nil
+	})
+
+	_, modified, err := ApplyConfigMap(ctx, client.CoreV1(), events.NewInMemoryRecorder("test"), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "config"},
+		Data:       map[string]string{"key": "new"},
+	})
+	if err != nil || !modified {
+		t.Fatalf("expected the config map to be updated, got %v, %v", modified, err)
+	}
+	if !reportedBeforeUpdate {
+		t.Error("expected the changes to be reported before the update")
+	}
+}
@@ -0,0 +1,185 @@
+package resourceapply
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+var conflictingFieldManagerMetric = metrics.NewCounterVec(&metrics.CounterOpts{
+	Subsystem:      "resourceapply",
+	Name:           "conflicting_field_manager_total",
+	Help:           "Number of times an applied object was modified by another field manager shortly after it was applied.",
+	StabilityLevel: metrics.ALPHA,
+}, []string{"kind", "manager"})
+
+func init() {
+	(&sync.Once{}).Do(func() {
+		legacyregistry.MustRegister(conflictingFieldManagerMetric)
+	})
+}
+
+// maxConflicts is the number of most recent conflicts kept by a ConflictDetector.
+const maxConflicts = 100
+
+// ConflictingActor describes another field manager that modified an object shortly after it was applied.
+type ConflictingActor struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Managers  []string
+	// SinceApply is the time between the last apply and the detection.
+	SinceApply time.Duration
+}
+
+// ConflictDetector detects other actors fighting over applied objects. Every time an Apply* function is about to
+// update an object it was applied within the window before, the managedFields of the existing object are checked
+// for other field managers that wrote the object since. These are reported as a ConflictingFieldManager event and
+// counted in the resourceapply_conflicting_field_manager_total metric.
+type ConflictDetector struct {
+	window        time.Duration
+	fieldManagers sets.Set[string]
+	clock         clock.PassiveClock
+
+	lock        sync.Mutex
+	lastApplies map[conflictKey]time.Time
+	// lastExpiry is when the applies older than the window were last dropped from lastApplies.
+	lastExpiry time.Time
+	// conflicts is a ring buffer of the most recent conflicts, next is the index of the oldest one once it is full.
+	conflicts []ConflictingActor
+	next      int
+}
+
+type conflictKey struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// NewConflictDetector returns a detector reporting changes by other managers within the window after an apply.
+// fieldManagers are the managers used by this process, by default the binary name which is what client-go uses.
+func NewConflictDetector(window time.Duration, fieldManagers ...string) *ConflictDetector {
+	if len(fieldManagers) == 0 {
+		fieldManagers = []string{filepath.Base(os.Args[0])}
+	}
+	return &ConflictDetector{
+		window:        window,
+		fieldManagers: sets.New(fieldManagers...),
+		clock:         clock.RealClock{},
+		lastApplies:   map[conflictKey]time.Time{},
+	}
+}
+
+// Conflicts returns the most recent conflicts detected so far, oldest first.
+func (d *ConflictDetector) Conflicts() []ConflictingActor {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return append(append([]ConflictingActor{}, d.conflicts[d.next:]...), d.conflicts[:d.next]...)
+}
+
+// addConflict records the conflict, replacing the oldest one when maxConflicts are recorded. Must be called with the
+// lock held.
+func (d *ConflictDetector) addConflict(conflict ConflictingActor) {
+	if len(d.conflicts) < maxConflicts {
+		d.conflicts = append(d.conflicts, conflict)
+		return
+	}
+	d.conflicts[d.next] = conflict
+	d.next = (d.next + 1) % maxConflicts
+}
+
+// expireApplies drops the applies older than the window, they cannot be part of a conflict anymore. The applies are
+// checked at most once per window. Must be called with the lock held.
+func (d *ConflictDetector) expireApplies(now time.Time) {
+	if now.Sub(d.lastExpiry) <= d.window {
+		return
+	}
+	for key, lastApply := range d.lastApplies {
+		if now.Sub(lastApply) > d.window {
+			delete(d.lastApplies, key)
+		}
+	}
+	d.lastExpiry = now
+}
+
+// observeApply checks the existing object for changes by other managers since the last apply and records the
+// upcoming apply.
+func (d *ConflictDetector) observeApply(recorder events.Recorder, existing runtime.Object) {
+	accessor, err := meta.Accessor(existing)
+	if err != nil {
+		return
+	}
+	key := conflictKey{kind: objectKind(existing), namespace: accessor.GetNamespace(), name: accessor.GetName()}
+	now := d.clock.Now()
+
+	d.lock.Lock()
+	d.expireApplies(now)
+	lastApply, applied := d.lastApplies[key]
+	d.lastApplies[key] = now
+	d.lock.Unlock()
+
+	if !applied || now.Sub(lastApply) > d.window {
+		return
+	}
+
+	// managedFields timestamps have a second precision
+	since := lastApply.Truncate(time.Second)
+	managers := sets.New[string]()
+	for _, entry := range accessor.GetManagedFields() {
+		if entry.Time == nil || entry.Time.Time.Before(since) || d.fieldManagers.Has(entry.Manager) {
+			continue
+		}
+		managers.Insert(entry.Manager)
+	}
+	if managers.Len() == 0 {
+		return
+	}
+
+	conflict := ConflictingActor{
+		Kind:       key.kind,
+		Namespace:  key.namespace,
+		Name:       key.name,
+		Managers:   sets.List(managers),
+		SinceApply: now.Sub(lastApply),
+	}
+	d.lock.Lock()
+	d.addConflict(conflict)
+	d.lock.Unlock()
+
+	name := key.name
+	if len(key.namespace) > 0 {
+		name = key.namespace + "/" + key.name
+	}
+	for _, manager := range conflict.Managers {
+		conflictingFieldManagerMetric.WithLabelValues(key.kind, manager).Inc()
+	}
+	klog.Warningf("%s %q was modified by %s %s after it was applied", key.kind, name, strings.Join(conflict.Managers, ","), conflict.SinceApply)
+	recorder.Warningf("ConflictingFieldManager", "%s %q was modified by %s %s after it was applied", key.kind, name, strings.Join(conflict.Managers, ","), conflict.SinceApply.Round(time.Second))
+}
+
+type conflictDetectorKey struct{}
+
+// WithConflictDetector returns a context which makes the Apply* functions report other actors fighting over the
+// applied objects through the detector.
+func WithConflictDetector(ctx context.Context, detector *ConflictDetector) context.Context {
+	return context.WithValue(ctx, conflictDetectorKey{}, detector)
+}
+
+func detectConflicts(ctx context.Context, recorder events.Recorder, existing runtime.Object) {
+	if detector, ok := ctx.Value(conflictDetectorKey{}).(*ConflictDetector); ok && detector != nil {
+		detector.observeApply(recorder, existing)
+	}
+}
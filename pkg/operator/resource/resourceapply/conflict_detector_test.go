@@ -0,0 +1,127 @@
+package resourceapply
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestConflictDetector(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	fakeClock := clocktesting.NewFakePassiveClock(now)
+	detector := NewConflictDetector(time.Minute, "my-operator")
+	detector.clock = fakeClock
+
+	client := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cm"},
+		Data:       map[string]string{"key": "other"},
+	})
+	recorder := events.NewInMemoryRecorder("test")
+	ctx := WithConflictDetector(context.TODO(), detector)
+	required := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cm"},
+		Data:       map[string]string{"key": "ours"},
+	}
+
+	if _, _, err := ApplyConfigMap(ctx, client.CoreV1(), recorder, required); err != nil {
+		t.Fatal(err)
+	}
+	if conflicts := detector.Conflicts(); len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts after the first apply, got %v", conflicts)
+	}
+
+	// another actor reverts the change
+	fakeClock.SetTime(now.Add(10 * time.Second))
+	reverted, err := client.CoreV1().ConfigMaps("ns").Get(context.TODO(), "cm", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reverted.Data["key"] = "other"
+	reverted.ManagedFields = []metav1.ManagedFieldsEntry{
+		{Manager: "my-operator", Operation: metav1.ManagedFieldsOperationUpdate, Time: &metav1.Time{Time: now}},
+		{Manager: "kubectl-edit", Operation: metav1.ManagedFieldsOperationUpdate, Time: &metav1.Time{Time: now.Add(5 * time.Second)}},
+		{Manager: "old-manager", Operation: metav1.ManagedFieldsOperationUpdate, Time: &metav1.Time{Time: now.Add(-time.Hour)}},
+	}
+	if _, err := client.CoreV1().ConfigMaps("ns").Update(context.TODO(), reverted, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := ApplyConfigMap(ctx, client.CoreV1(), recorder, required); err != nil {
+		t.Fatal(err)
+	}
+	conflicts := detector.Conflicts()
+	if len(conflicts) != 1 {
+		t.Fatalf("expected one conflict, got %v", conflicts)
+	}
+	if !reflect.DeepEqual(conflicts[0].Managers, []string{"kubectl-edit"}) || conflicts[0].Kind != "ConfigMap" || conflicts[0].SinceApply != 10*time.Second {
+		t.Errorf("unexpected conflict: %#v", conflicts[0])
+	}
+	found := false
+	for _, event := range recorder.Events() {
+		if event.Reason == "ConflictingFieldManager" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a ConflictingFieldManager event")
+	}
+
+	// outside of the window nothing is reported
+	fakeClock.SetTime(now.Add(time.Hour))
+	reverted, _ = client.CoreV1().ConfigMaps("ns").Get(context.TODO(), "cm", metav1.GetOptions{})
+	reverted.Data["key"] = "other"
+	if _, err := client.CoreV1().ConfigMaps("ns").Update(context.TODO(), reverted, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ApplyConfigMap(ctx, client.CoreV1(), recorder, required); err != nil {
+		t.Fatal(err)
+	}
+	if conflicts := detector.Conflicts(); len(conflicts) != 1 {
+		t.Errorf("expected no new conflict outside of the window, got %v", conflicts)
+	}
+}
+
+func TestConflictDetectorBounds(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	fakeClock := clocktesting.NewFakePassiveClock(now)
+	detector := NewConflictDetector(time.Minute, "my-operator")
+	detector.clock = fakeClock
+	recorder := events.NewInMemoryRecorder("test")
+
+	configMap := func(name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      name,
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "kubectl-edit", Operation: metav1.ManagedFieldsOperationUpdate, Time: &metav1.Time{Time: fakeClock.Now()}},
+			},
+		}}
+	}
+	for i := 0; i < maxConflicts+10; i++ {
+		name := strconv.Itoa(i)
+		detector.observeApply(recorder, configMap(name))
+		detector.observeApply(recorder, configMap(name))
+	}
+	conflicts := detector.Conflicts()
+	if len(conflicts) != maxConflicts {
+		t.Fatalf("expected %d conflicts, got %d", maxConflicts, len(conflicts))
+	}
+	if conflicts[0].Name != "10" || conflicts[maxConflicts-1].Name != strconv.Itoa(maxConflicts+9) {
+		t.Errorf("expected the most recent conflicts oldest first, got %q to %q", conflicts[0].Name, conflicts[maxConflicts-1].Name)
+	}
+
+	fakeClock.SetTime(now.Add(2 * time.Minute))
+	detector.observeApply(recorder, configMap("new"))
+	if len(detector.lastApplies) != 1 {
+		t.Errorf("expected the applies outside of the window to expire, got %d", len(detector.lastApplies))
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/objectbudget"
 	"github.com/openshift/library-go/pkg/operator/resource/resourcehelper"
 	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
 	corev1 "k8s.io/api/core/v1"
@@ -69,6 +70,82 @@ func ApplyService(ctx context.Context, client coreclientv1.ServicesGetter, recor
 	return ApplyServiceImproved(ctx, client, recorder, required, noCache)
 }
 
+// preserveAllocatedServiceFields copies the values allocated by the API server from the existing spec into the required
+// spec, wherever the required spec leaves them unset and the values still apply to the required service:
+//   - the cluster IPs, unless the service becomes an ExternalName service,
+//   - the node ports of the ports with the same port number and protocol, and the health check node port, as long as
+//     the service keeps needing them,
+//   - the IP families and the IP family policy.
+//
+// When the required IP family policy is SingleStack, only the primary IP family and cluster IP are kept, which
+// converts a dual-stack service to single-stack. When it is PreferDualStack or RequireDualStack, the existing primary
+// family and cluster IP are kept and the API server allocates the secondary ones.
+func preserveAllocatedServiceFields(existing, required *corev1.ServiceSpec) {
+	requiredType := required.Type
+	if len(requiredType) == 0 {
+		requiredType = corev1.ServiceTypeClusterIP
+	}
+	if requiredType == corev1.ServiceTypeExternalName {
+		return
+	}
+
+	preservedClusterIPs := false
+	if len(required.ClusterIP) == 0 && len(required.ClusterIPs) == 0 && len(existing.ClusterIP) > 0 {
+		required.ClusterIP = existing.ClusterIP
+		required.ClusterIPs = append([]string(nil), existing.ClusterIPs...)
+		preservedClusterIPs = true
+	}
+	preservedIPFamilies := false
+	if len(required.IPFamilies) == 0 && len(existing.IPFamilies) > 0 {
+		required.IPFamilies = append([]corev1.IPFamily(nil), existing.IPFamilies...)
+		preservedIPFamilies = true
+	}
+	if required.IPFamilyPolicy == nil && existing.IPFamilyPolicy != nil {
+		policy := *existing.IPFamilyPolicy
+		required.IPFamilyPolicy = &policy
+	}
+
+	if required.IPFamilyPolicy != nil && *required.IPFamilyPolicy == corev1.IPFamilyPolicySingleStack {
+		if preservedIPFamilies && len(required.IPFamilies) > 1 {
+			required.IPFamilies = required.IPFamilies[:1]
+		}
+		if preservedClusterIPs && len(required.ClusterIPs) > 1 {
+			required.ClusterIPs = required.ClusterIPs[:1]
+		}
+	}
+	// the cluster IPs must not outnumber the IP families set by the caller
+	if preservedClusterIPs && !preservedIPFamilies && len(required.IPFamilies) > 0 && len(required.ClusterIPs) > len(required.IPFamilies) {
+		required.ClusterIPs = required.ClusterIPs[:len(required.IPFamilies)]
+	}
+
+	if requiredType != corev1.ServiceTypeNodePort && requiredType != corev1.ServiceTypeLoadBalancer {
+		return
+	}
+	for i := range required.Ports {
+		if required.Ports[i].NodePort != 0 {
+			continue
+		}
+		for _, existingPort := range existing.Ports {
+			if existingPort.Port == required.Ports[i].Port && serviceProtocol(existingPort.Protocol) == serviceProtocol(required.Ports[i].Protocol) {
+				required.Ports[i].NodePort = existingPort.NodePort
+				break
+			}
+		}
+	}
+	if requiredType == corev1.ServiceTypeLoadBalancer && required.HealthCheckNodePort == 0 &&
+		required.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyLocal {
+		required.HealthCheckNodePort = existing.HealthCheckNodePort
+	}
+}
+
+// serviceProtocol returns the protocol of a service port, defaulted like the API server does.
+func serviceProtocol(protocol corev1.Protocol) corev1.Protocol {
+	if len(protocol) == 0 {
+		return corev1.ProtocolTCP
+	}
+	return protocol
+}
+
 // ApplyPod merges objectmeta, does not worry about anything else
 func ApplyPod(ctx context.Context, client coreclientv1.PodsGetter, recorder events.Recorder, required *corev1.Pod) (*corev1.Pod, bool, error) {
 	return ApplyPodImproved(ctx, client, recorder, required, noCache)
@@ -95,7 +172,7 @@ func ApplyNamespaceImproved(ctx context.Context, client coreclientv1.NamespacesG
 	if apierrors.IsNotFound(err) {
 		requiredCopy := required.DeepCopy()
 		actual, err := client.Namespaces().
-			Create(ctx, resourcemerge.WithCleanLabelsAndAnnotations(requiredCopy).(*corev1.Namespace), metav1.CreateOptions{})
+			Create(ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, requiredCopy)).(*corev1.Namespace), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, requiredCopy, err)
 		cache.UpdateCachedResourceMetadata(required, actual)
 		return actual, true, err
@@ -112,6 +189,9 @@ func ApplyNamespaceImproved(ctx context.Context, client coreclientv1.NamespacesG
 	existingCopy := existing.DeepCopy()
 
 	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
 	if !modified {
 		cache.UpdateCachedResourceMetadata(required, existingCopy)
 		return existingCopy, false, nil
@@ -120,6 +200,7 @@ func ApplyNamespaceImproved(ctx context.Context, client coreclientv1.NamespacesG
 	if klog.V(2).Enabled() {
 		klog.Infof("Namespace %q changes: %v", required.Name, JSONPatchNoError(existing, existingCopy))
 	}
+	reportChanges(ctx, recorder, existing, existingCopy)
 
 	actual, err := client.Namespaces().Update(ctx, existingCopy, metav1.UpdateOptions{})
 	resourcehelper.ReportUpdateEvent(recorder, required, err)
@@ -129,6 +210,8 @@ func ApplyNamespaceImproved(ctx context.Context, client coreclientv1.NamespacesG
 
 // ApplyService merges objectmeta and requires.
 // It detects changes in `required`, i.e. an operator needs .spec changes and overwrites existing .spec with those.
+// The fields allocated by the API server (cluster IPs, node ports, IP families) are kept when `required` leaves them
+// unset, see preserveAllocatedServiceFields.
 // TODO, since this cannot determine whether changes in `existing` are due to legitimate actors (api server) or illegitimate ones (users), we cannot update.
 // TODO I've special cased the selector for now
 func ApplyServiceImproved(ctx context.Context, client coreclientv1.ServicesGetter, recorder events.Recorder, requiredOriginal *corev1.Service, cache ResourceCache) (*corev1.Service, bool, error) {
@@ -142,7 +225,7 @@ func ApplyServiceImproved(ctx context.Context, client coreclientv1.ServicesGette
 	if apierrors.IsNotFound(err) {
 		requiredCopy := required.DeepCopy()
 		actual, err := client.Services(requiredCopy.Namespace).
-			Create(ctx, resourcemerge.WithCleanLabelsAndAnnotations(requiredCopy).(*corev1.Service), metav1.CreateOptions{})
+			Create(ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, requiredCopy)).(*corev1.Service), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, requiredCopy, err)
 		cache.UpdateCachedResourceMetadata(required, actual)
 		return actual, true, err
@@ -161,6 +244,9 @@ func ApplyServiceImproved(ctx context.Context, client coreclientv1.ServicesGette
 	// This will catch also changes between old `required.spec` and current `required.spec`, because
 	// the annotation from SetSpecHashAnnotation will be different.
 	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
 	selectorSame := equality.Semantic.DeepEqual(existingCopy.Spec.Selector, required.Spec.Selector)
 
 	typeSame := false
@@ -176,11 +262,13 @@ func ApplyServiceImproved(ctx context.Context, client coreclientv1.ServicesGette
 	}
 
 	// Either (user changed selector or type) or metadata changed (incl. spec hash). Stomp over
-	// any user *and* Kubernetes changes, hoping that Kubernetes will restore its values.
-	existingCopy.Spec = required.Spec
+	// any user changes, but keep the values allocated by Kubernetes, which it would have to allocate again otherwise.
+	existingCopy.Spec = *required.Spec.DeepCopy()
+	preserveAllocatedServiceFields(&existing.Spec, &existingCopy.Spec)
 	if klog.V(4).Enabled() {
 		klog.Infof("Service %q changes: %v", required.Namespace+"/"+required.Name, JSONPatchNoError(existing, required))
 	}
+	reportChanges(ctx, recorder, existing, existingCopy)
 
 	actual, err := client.Services(required.Namespace).Update(ctx, existingCopy, metav1.UpdateOptions{})
 	resourcehelper.ReportUpdateEvent(recorder, required, err)
@@ -194,7 +282,7 @@ func ApplyPodImproved(ctx context.Context, client coreclientv1.PodsGetter, recor
 	if apierrors.IsNotFound(err) {
 		requiredCopy := required.DeepCopy()
 		actual, err := client.Pods(requiredCopy.Namespace).
-			Create(ctx, resourcemerge.WithCleanLabelsAndAnnotations(requiredCopy).(*corev1.Pod), metav1.CreateOptions{})
+			Create(ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, requiredCopy)).(*corev1.Pod), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, requiredCopy, err)
 		cache.UpdateCachedResourceMetadata(required, actual)
 		return actual, true, err
@@ -211,6 +299,9 @@ func ApplyPodImproved(ctx context.Context, client coreclientv1.PodsGetter, recor
 	existingCopy := existing.DeepCopy()
 
 	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
 	if !modified {
 		cache.UpdateCachedResourceMetadata(required, existingCopy)
 		return existingCopy, false, nil
@@ -219,6 +310,7 @@ func ApplyPodImproved(ctx context.Context, client coreclientv1.PodsGetter, recor
 	if klog.V(2).Enabled() {
 		klog.Infof("Pod %q changes: %v", required.Namespace+"/"+required.Name, JSONPatchNoError(existing, required))
 	}
+	reportChanges(ctx, recorder, existing, existingCopy)
 
 	actual, err := client.Pods(required.Namespace).Update(ctx, existingCopy, metav1.UpdateOptions{})
 	resourcehelper.ReportUpdateEvent(recorder, required, err)
@@ -232,7 +324,7 @@ func ApplyServiceAccountImproved(ctx context.Context, client coreclientv1.Servic
 	if apierrors.IsNotFound(err) {
 		requiredCopy := required.DeepCopy()
 		actual, err := client.ServiceAccounts(requiredCopy.Namespace).
-			Create(ctx, resourcemerge.WithCleanLabelsAndAnnotations(requiredCopy).(*corev1.ServiceAccount), metav1.CreateOptions{})
+			Create(ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, requiredCopy)).(*corev1.ServiceAccount), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, requiredCopy, err)
 		cache.UpdateCachedResourceMetadata(required, actual)
 		return actual, true, err
@@ -249,6 +341,9 @@ func ApplyServiceAccountImproved(ctx context.Context, client coreclientv1.Servic
 	existingCopy := existing.DeepCopy()
 
 	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
 	if !modified {
 		cache.UpdateCachedResourceMetadata(required, existingCopy)
 		return existingCopy, false, nil
@@ -256,6 +351,7 @@ func ApplyServiceAccountImproved(ctx context.Context, client coreclientv1.Servic
 	if klog.V(2).Enabled() {
 		klog.Infof("ServiceAccount %q changes: %v", required.Namespace+"/"+required.Name, JSONPatchNoError(existing, required))
 	}
+	reportChanges(ctx, recorder, existing, existingCopy)
 	actual, err := client.ServiceAccounts(required.Namespace).Update(ctx, existingCopy, metav1.UpdateOptions{})
 	resourcehelper.ReportUpdateEvent(recorder, required, err)
 	cache.UpdateCachedResourceMetadata(required, actual)
@@ -264,11 +360,14 @@ func ApplyServiceAccountImproved(ctx context.Context, client coreclientv1.Servic
 
 // ApplyConfigMap merges objectmeta, requires data
 func ApplyConfigMapImproved(ctx context.Context, client coreclientv1.ConfigMapsGetter, recorder events.Recorder, required *corev1.ConfigMap, cache ResourceCache) (*corev1.ConfigMap, bool, error) {
+	if err := objectbudget.CheckConfigMap(required); err != nil {
+		return nil, false, err
+	}
 	existing, err := client.ConfigMaps(required.Namespace).Get(ctx, required.Name, metav1.GetOptions{})
 	if apierrors.IsNotFound(err) {
 		requiredCopy := required.DeepCopy()
 		actual, err := client.ConfigMaps(requiredCopy.Namespace).
-			Create(ctx, resourcemerge.WithCleanLabelsAndAnnotations(requiredCopy).(*corev1.ConfigMap), metav1.CreateOptions{})
+			Create(ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, requiredCopy)).(*corev1.ConfigMap), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, requiredCopy, err)
 		cache.UpdateCachedResourceMetadata(required, actual)
 		return actual, true, err
@@ -285,6 +384,9 @@ func ApplyConfigMapImproved(ctx context.Context, client coreclientv1.ConfigMapsG
 	existingCopy := existing.DeepCopy()
 
 	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
 
 	// injected by cluster-network-operator: https://github.com/openshift/cluster-network-operator/blob/acc819ee0f3424a341b9ad4e1e83ca0a742c230a/docs/architecture.md?L192#configmap-ca-injector
 	caBundleInjected := required.Labels["config.openshift.io/inject-trusted-cabundle"] == "true"
@@ -341,8 +443,6 @@ func ApplyConfigMapImproved(ctx context.Context, client coreclientv1.ConfigMapsG
 		existingCopy.Data["ca-bundle.crt"] = existingCABundle
 	}
 
-	actual, err := client.ConfigMaps(required.Namespace).Update(ctx, existingCopy, metav1.UpdateOptions{})
-
 	var details string
 	if !dataSame {
 		sort.Sort(sort.StringSlice(modifiedKeys))
@@ -351,6 +451,8 @@ func ApplyConfigMapImproved(ctx context.Context, client coreclientv1.ConfigMapsG
 	if klog.V(2).Enabled() {
 		klog.Infof("ConfigMap %q changes: %v", required.Namespace+"/"+required.Name, JSONPatchNoError(existing, required))
 	}
+	reportChanges(ctx, recorder, existing, existingCopy)
+	actual, err := client.ConfigMaps(required.Namespace).Update(ctx, existingCopy, metav1.UpdateOptions{})
 	resourcehelper.ReportUpdateEvent(recorder, required, err, details)
 	cache.UpdateCachedResourceMetadata(required, actual)
 	return actual, true, err
@@ -358,6 +460,9 @@ func ApplyConfigMapImproved(ctx context.Context, client coreclientv1.ConfigMapsG
 
 // ApplySecret merges objectmeta, requires data
 func ApplySecretImproved(ctx context.Context, client coreclientv1.SecretsGetter, recorder events.Recorder, requiredInput *corev1.Secret, cache ResourceCache) (*corev1.Secret, bool, error) {
+	if err := objectbudget.CheckSecret(requiredInput); err != nil {
+		return nil, false, err
+	}
 	// copy the stringData to data.  Error on a data content conflict inside required.  This is usually a bug.
 
 	existing, err := client.Secrets(requiredInput.Namespace).Get(ctx, requiredInput.Name, metav1.GetOptions{})
@@ -386,7 +491,7 @@ func ApplySecretImproved(ctx context.Context, client coreclientv1.SecretsGetter,
 	if apierrors.IsNotFound(err) {
 		requiredCopy := required.DeepCopy()
 		actual, err := client.Secrets(requiredCopy.Namespace).
-			Create(ctx, resourcemerge.WithCleanLabelsAndAnnotations(requiredCopy).(*corev1.Secret), metav1.CreateOptions{})
+			Create(ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, requiredCopy)).(*corev1.Secret), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, requiredCopy, err)
 		cache.UpdateCachedResourceMetadata(requiredInput, actual)
 		return actual, true, err
@@ -398,6 +503,10 @@ func ApplySecretImproved(ctx context.Context, client coreclientv1.SecretsGetter,
 	existingCopy := existing.DeepCopy()
 
 	resourcemerge.EnsureObjectMeta(ptr.To(false), &existingCopy.ObjectMeta, required.ObjectMeta)
+	// the changes are detected by comparing the copy to the existing secret below
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, ptr.To(false)); err != nil {
+		return nil, false, err
+	}
 
 	switch required.Type {
 	case corev1.SecretTypeServiceAccountToken:
@@ -430,6 +539,7 @@ func ApplySecretImproved(ctx context.Context, client coreclientv1.SecretsGetter,
 	if klog.V(4).Enabled() {
 		klog.Infof("Secret %s/%s changes: %v", required.Namespace, required.Name, JSONPatchSecretNoError(existing, existingCopy))
 	}
+	reportChanges(ctx, recorder, existing, existingCopy)
 
 	var actual *corev1.Secret
 	/*
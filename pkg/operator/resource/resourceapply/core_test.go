@@ -17,6 +17,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes/fake"
 	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
 
 	"github.com/openshift/library-go/pkg/operator/events"
 )
@@ -77,6 +78,33 @@ func TestApplyConfigMap(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "update value of the same length",
+			existing: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "one-ns", Name: "foo"},
+					Data:       map[string]string{"ca-bundle.crt": "edited"},
+				},
+			},
+			input: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "one-ns", Name: "foo"},
+				Data:       map[string]string{"ca-bundle.crt": "bundle"},
+			},
+
+			expectedModified: true,
+			verifyActions: func(actions []clienttesting.Action, t *testing.T) {
+				if len(actions) != 2 {
+					t.Fatal(spew.Sdump(actions))
+				}
+				if !actions[1].Matches("update", "configmaps") {
+					t.Error(spew.Sdump(actions))
+				}
+				actual := actions[1].(clienttesting.UpdateAction).GetObject().(*corev1.ConfigMap)
+				if actual.Data["ca-bundle.crt"] != "bundle" {
+					t.Error(spew.Sdump(actual))
+				}
+			},
+		},
 		{
 			name: "don't mutate CA bundle if injected",
 			existing: []runtime.Object{
@@ -1606,6 +1634,40 @@ func TestApplyService(t *testing.T) {
 		},
 	}
 
+	// srv1Port with the values allocated by the API server, on a dual-stack cluster
+	allocatedSrv1Port := withSpecHash(srv1Port)
+	allocatedSrv1Port.Spec.ClusterIP = "172.30.0.10"
+	allocatedSrv1Port.Spec.ClusterIPs = []string{"172.30.0.10", "fd02::10"}
+	allocatedSrv1Port.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}
+	allocatedSrv1Port.Spec.IPFamilyPolicy = ptr.To(corev1.IPFamilyPolicyPreferDualStack)
+	allocatedSrv1Port.Spec.Ports[0].Protocol = corev1.ProtocolTCP
+	allocatedSrv1Port.Spec.Ports[0].NodePort = 30080
+	// srv2Ports with the values of allocatedSrv1Port the API server keeps
+	allocatedSrv2Ports := withSpecHash(srv2Ports)
+	allocatedSrv2Ports.Spec.ClusterIP = "172.30.0.10"
+	allocatedSrv2Ports.Spec.ClusterIPs = []string{"172.30.0.10", "fd02::10"}
+	allocatedSrv2Ports.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}
+	allocatedSrv2Ports.Spec.IPFamilyPolicy = ptr.To(corev1.IPFamilyPolicyPreferDualStack)
+	allocatedSrv2Ports.Spec.Ports[0].NodePort = 30080
+
+	// srv1Port converted to single-stack
+	singleStackSrv1Port := srv1Port.DeepCopy()
+	singleStackSrv1Port.Spec.IPFamilyPolicy = ptr.To(corev1.IPFamilyPolicySingleStack)
+	allocatedSingleStackSrv1Port := withSpecHash(singleStackSrv1Port)
+	allocatedSingleStackSrv1Port.Spec.ClusterIP = "172.30.0.10"
+	allocatedSingleStackSrv1Port.Spec.ClusterIPs = []string{"172.30.0.10"}
+	allocatedSingleStackSrv1Port.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv4Protocol}
+	allocatedSingleStackSrv1Port.Spec.Ports[0].NodePort = 30080
+
+	// srv1Port converted to a ClusterIP service, which has no node ports
+	clusterIPSrv1Port := srv1Port.DeepCopy()
+	clusterIPSrv1Port.Spec.Type = corev1.ServiceTypeClusterIP
+	allocatedClusterIPSrv1Port := withSpecHash(clusterIPSrv1Port)
+	allocatedClusterIPSrv1Port.Spec.ClusterIP = "172.30.0.10"
+	allocatedClusterIPSrv1Port.Spec.ClusterIPs = []string{"172.30.0.10", "fd02::10"}
+	allocatedClusterIPSrv1Port.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}
+	allocatedClusterIPSrv1Port.Spec.IPFamilyPolicy = ptr.To(corev1.IPFamilyPolicyPreferDualStack)
+
 	tt := []struct {
 		name             string
 		existingObjects  []runtime.Object
@@ -1696,6 +1758,74 @@ func TestApplyService(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:             "update keeps the allocated values",
+			existingObjects:  []runtime.Object{allocatedSrv1Port},
+			input:            srv2Ports,
+			expectedModified: true,
+			verifyActions: func(actions []clienttesting.Action, t *testing.T) {
+				if len(actions) != 2 {
+					t.Fatal(spew.Sdump(actions))
+				}
+				if !actions[1].Matches("update", "services") {
+					t.Error(spew.Sdump(actions))
+				}
+
+				actual := actions[1].(clienttesting.UpdateAction).GetObject().(*corev1.Service)
+				if !equality.Semantic.DeepEqual(allocatedSrv2Ports, actual) {
+					t.Error(JSONPatchNoError(allocatedSrv2Ports, actual))
+				}
+			},
+		},
+		{
+			name:             "update converts a dual-stack service to single-stack",
+			existingObjects:  []runtime.Object{allocatedSrv1Port},
+			input:            singleStackSrv1Port,
+			expectedModified: true,
+			verifyActions: func(actions []clienttesting.Action, t *testing.T) {
+				if len(actions) != 2 {
+					t.Fatal(spew.Sdump(actions))
+				}
+				if !actions[1].Matches("update", "services") {
+					t.Error(spew.Sdump(actions))
+				}
+
+				actual := actions[1].(clienttesting.UpdateAction).GetObject().(*corev1.Service)
+				if !equality.Semantic.DeepEqual(allocatedSingleStackSrv1Port, actual) {
+					t.Error(JSONPatchNoError(allocatedSingleStackSrv1Port, actual))
+				}
+			},
+		},
+		{
+			name:             "update drops the node ports of a service changed to ClusterIP",
+			existingObjects:  []runtime.Object{allocatedSrv1Port},
+			input:            clusterIPSrv1Port,
+			expectedModified: true,
+			verifyActions: func(actions []clienttesting.Action, t *testing.T) {
+				if len(actions) != 2 {
+					t.Fatal(spew.Sdump(actions))
+				}
+				if !actions[1].Matches("update", "services") {
+					t.Error(spew.Sdump(actions))
+				}
+
+				actual := actions[1].(clienttesting.UpdateAction).GetObject().(*corev1.Service)
+				if !equality.Semantic.DeepEqual(allocatedClusterIPSrv1Port, actual) {
+					t.Error(JSONPatchNoError(allocatedClusterIPSrv1Port, actual))
+				}
+			},
+		},
+		{
+			name:             "no update when only the allocated values differ",
+			existingObjects:  []runtime.Object{allocatedSrv1Port},
+			input:            srv1Port,
+			expectedModified: false,
+			verifyActions: func(actions []clienttesting.Action, t *testing.T) {
+				if len(actions) != 1 {
+					t.Fatal(spew.Sdump(actions))
+				}
+			},
+		},
 		{
 			name:             "no overwrite when user changes an untracked field",
 			existingObjects:  []runtime.Object{userChangedSrv1Untracked},
@@ -61,7 +61,7 @@ func ApplyCredentialsRequest(
 	crClient := client.Resource(credentialsRequestResourceGVR).Namespace(required.GetNamespace())
 	existing, err := crClient.Get(ctx, required.GetName(), metav1.GetOptions{})
 	if apierrors.IsNotFound(err) {
-		actual, err := crClient.Create(ctx, required, metav1.CreateOptions{})
+		actual, err := crClient.Create(ctx, ownedForCreate(ctx, recorder, required.DeepCopy()), metav1.CreateOptions{})
 		if err == nil {
 			recorder.Eventf(
 				fmt.Sprintf("%sCreated", required.GetKind()),
@@ -92,6 +92,11 @@ func ApplyCredentialsRequest(
 		needApply = true
 	}
 
+	// the update writes the existing object with the required spec
+	if err := enforceOwnership(ctx, recorder, existing, existing, &needApply); err != nil {
+		return nil, false, err
+	}
+
 	if !needApply {
 		return existing, false, nil
 	}
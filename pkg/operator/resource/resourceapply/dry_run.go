@@ -0,0 +1,73 @@
+package resourceapply
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+)
+
+// dryRunFieldManager is the field manager of the dry-run applies. Nothing is persisted, so it does not take over any
+// fields.
+const dryRunFieldManager = "library-go-dry-run"
+
+// DryRunApplyDirectly validates the given manifest files with a server-side dry-run apply, so that admission and
+// validation of all objects can be checked before any of them is applied. Nothing is persisted. It requires a dynamic
+// client. The resources of the objects are looked up with the mapper when given, and guessed from their kinds
+// otherwise.
+func DryRunApplyDirectly(ctx context.Context, clients *ClientHolder, mapper meta.RESTMapper, manifests AssetFunc, files ...string) []ApplyResult {
+	ret := []ApplyResult{}
+
+	for _, file := range files {
+		result := ApplyResult{File: file}
+		objBytes, err := manifests(file)
+		if err != nil {
+			result.Error = fmt.Errorf("missing %q: %v", file, err)
+			ret = append(ret, result)
+			continue
+		}
+		requiredObj, err := resourceread.ReadGenericWithUnstructured(objBytes)
+		if err != nil {
+			result.Error = fmt.Errorf("cannot decode %q: %v", file, err)
+			ret = append(ret, result)
+			continue
+		}
+		result.Type = fmt.Sprintf("%T", requiredObj)
+
+		required, err := resourceread.ReadUnstructured(objBytes)
+		if err != nil {
+			result.Error = fmt.Errorf("cannot decode %q: %v", file, err)
+			ret = append(ret, result)
+			continue
+		}
+		if clients.dynamicClient == nil {
+			result.Error = fmt.Errorf("missing dynamicClient")
+			ret = append(ret, result)
+			continue
+		}
+
+		gvk := required.GroupVersionKind()
+		gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+		if mapper != nil {
+			mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+			if err != nil {
+				result.Error = fmt.Errorf("cannot map %s: %w", gvk, err)
+				ret = append(ret, result)
+				continue
+			}
+			gvr = mapping.Resource
+		}
+
+		result.Result, result.Error = clients.dynamicClient.Resource(gvr).Namespace(required.GetNamespace()).Apply(ctx, required.GetName(), required, metav1.ApplyOptions{
+			DryRun:       []string{metav1.DryRunAll},
+			Force:        true,
+			FieldManager: dryRunFieldManager,
+		})
+		ret = append(ret, result)
+	}
+
+	return ret
+}
@@ -0,0 +1,124 @@
+package resourceapply
+
+import (
+	"context"
+
+	flowcontrolv1 "k8s.io/api/flowcontrol/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	flowcontrolclientv1 "k8s.io/client-go/kubernetes/typed/flowcontrol/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcehelper"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+)
+
+// ApplyFlowSchema merges objectmeta and requires the spec. Spec fields not set in required, which the API server
+// defaults, and the status maintained by the API server are ignored in the comparison.
+func ApplyFlowSchema(ctx context.Context, client flowcontrolclientv1.FlowSchemasGetter, recorder events.Recorder, required *flowcontrolv1.FlowSchema) (*flowcontrolv1.FlowSchema, bool, error) {
+	existing, err := client.FlowSchemas().Get(ctx, required.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		requiredCopy := required.DeepCopy()
+		actual, err := client.FlowSchemas().Create(
+			ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, requiredCopy)).(*flowcontrolv1.FlowSchema), metav1.CreateOptions{})
+		resourcehelper.ReportCreateEvent(recorder, required, err)
+		return actual, true, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	modified := false
+	existingCopy := existing.DeepCopy()
+
+	requiredSpec := required.Spec.DeepCopy()
+	if requiredSpec.MatchingPrecedence == 0 {
+		// defaulted by the API server
+		requiredSpec.MatchingPrecedence = existingCopy.Spec.MatchingPrecedence
+	}
+
+	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
+	contentSame := equality.Semantic.DeepDerivative(*requiredSpec, existingCopy.Spec)
+	if contentSame && !modified {
+		return existingCopy, false, nil
+	}
+
+	existingCopy.Spec = *requiredSpec
+
+	if klog.V(2).Enabled() {
+		klog.Infof("FlowSchema %q changes: %v", required.Name, JSONPatchNoError(existing, existingCopy))
+	}
+	reportChanges(ctx, recorder, existing, existingCopy)
+
+	actual, err := client.FlowSchemas().Update(ctx, existingCopy, metav1.UpdateOptions{})
+	resourcehelper.ReportUpdateEvent(recorder, required, err)
+	return actual, true, err
+}
+
+// ApplyPriorityLevelConfiguration merges objectmeta and requires the spec. Spec fields not set in required, which the
+// API server defaults, and the status maintained by the API server are ignored in the comparison.
+func ApplyPriorityLevelConfiguration(ctx context.Context, client flowcontrolclientv1.PriorityLevelConfigurationsGetter, recorder events.Recorder, required *flowcontrolv1.PriorityLevelConfiguration) (*flowcontrolv1.PriorityLevelConfiguration, bool, error) {
+	existing, err := client.PriorityLevelConfigurations().Get(ctx, required.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		requiredCopy := required.DeepCopy()
+		actual, err := client.PriorityLevelConfigurations().Create(
+			ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, requiredCopy)).(*flowcontrolv1.PriorityLevelConfiguration), metav1.CreateOptions{})
+		resourcehelper.ReportCreateEvent(recorder, required, err)
+		return actual, true, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	modified := false
+	existingCopy := existing.DeepCopy()
+
+	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
+	contentSame := equality.Semantic.DeepDerivative(required.Spec, existingCopy.Spec)
+	if contentSame && !modified {
+		return existingCopy, false, nil
+	}
+
+	existingCopy.Spec = required.Spec
+
+	if klog.V(2).Enabled() {
+		klog.Infof("PriorityLevelConfiguration %q changes: %v", required.Name, JSONPatchNoError(existing, existingCopy))
+	}
+	reportChanges(ctx, recorder, existing, existingCopy)
+
+	actual, err := client.PriorityLevelConfigurations().Update(ctx, existingCopy, metav1.UpdateOptions{})
+	resourcehelper.ReportUpdateEvent(recorder, required, err)
+	return actual, true, err
+}
+
+func DeleteFlowSchema(ctx context.Context, client flowcontrolclientv1.FlowSchemasGetter, recorder events.Recorder, required *flowcontrolv1.FlowSchema) (*flowcontrolv1.FlowSchema, bool, error) {
+	err := client.FlowSchemas().Delete(ctx, required.Name, metav1.DeleteOptions{})
+	if err != nil && apierrors.IsNotFound(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	resourcehelper.ReportDeleteEvent(recorder, required, err)
+	return nil, true, nil
+}
+
+func DeletePriorityLevelConfiguration(ctx context.Context, client flowcontrolclientv1.PriorityLevelConfigurationsGetter, recorder events.Recorder, required *flowcontrolv1.PriorityLevelConfiguration) (*flowcontrolv1.PriorityLevelConfiguration, bool, error) {
+	err := client.PriorityLevelConfigurations().Delete(ctx, required.Name, metav1.DeleteOptions{})
+	if err != nil && apierrors.IsNotFound(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	resourcehelper.ReportDeleteEvent(recorder, required, err)
+	return nil, true, nil
+}
@@ -0,0 +1,104 @@
+package resourceapply
+
+import (
+	"context"
+	"testing"
+
+	flowcontrolv1 "k8s.io/api/flowcontrol/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestApplyFlowSchema(t *testing.T) {
+	required := &flowcontrolv1.FlowSchema{
+		ObjectMeta: metav1.ObjectMeta{Name: "openshift-operator"},
+		Spec: flowcontrolv1.FlowSchemaSpec{
+			PriorityLevelConfiguration: flowcontrolv1.PriorityLevelConfigurationReference{Name: "workload-high"},
+			Rules: []flowcontrolv1.PolicyRulesWithSubjects{{
+				Subjects: []flowcontrolv1.Subject{{
+					Kind:           flowcontrolv1.SubjectKindServiceAccount,
+					ServiceAccount: &flowcontrolv1.ServiceAccountSubject{Namespace: "openshift-operator", Name: "operator"},
+				}},
+				ResourceRules: []flowcontrolv1.ResourcePolicyRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}, Namespaces: []string{"*"}}},
+			}},
+		},
+	}
+	client := fake.NewSimpleClientset()
+	recorder := events.NewInMemoryRecorder("test")
+
+	if _, modified, err := ApplyFlowSchema(context.TODO(), client.FlowcontrolV1(), recorder, required); err != nil || !modified {
+		t.Fatalf("expected create, got modified=%v err=%v", modified, err)
+	}
+
+	// the server defaults the spec and populates the status
+	existing, err := client.FlowcontrolV1().FlowSchemas().Get(context.TODO(), required.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing.Spec.MatchingPrecedence = 1000
+	existing.Spec.DistinguisherMethod = &flowcontrolv1.FlowDistinguisherMethod{Type: flowcontrolv1.FlowDistinguisherMethodByUserType}
+	existing.Status.Conditions = []flowcontrolv1.FlowSchemaCondition{{Type: flowcontrolv1.FlowSchemaConditionDangling, Status: flowcontrolv1.ConditionFalse}}
+	if _, err := client.FlowcontrolV1().FlowSchemas().Update(context.TODO(), existing, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, modified, err := ApplyFlowSchema(context.TODO(), client.FlowcontrolV1(), recorder, required); err != nil || modified {
+		t.Fatalf("expected no change for defaulted fields and status, got modified=%v err=%v", modified, err)
+	}
+
+	changed := required.DeepCopy()
+	changed.Spec.PriorityLevelConfiguration.Name = "workload-low"
+	actual, modified, err := ApplyFlowSchema(context.TODO(), client.FlowcontrolV1(), recorder, changed)
+	if err != nil || !modified {
+		t.Fatalf("expected update, got modified=%v err=%v", modified, err)
+	}
+	if actual.Spec.PriorityLevelConfiguration.Name != "workload-low" || len(actual.Status.Conditions) != 1 {
+		t.Errorf("unexpected flow schema %#v", actual)
+	}
+}
+
+func TestApplyPriorityLevelConfiguration(t *testing.T) {
+	required := &flowcontrolv1.PriorityLevelConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "openshift-operator"},
+		Spec: flowcontrolv1.PriorityLevelConfigurationSpec{
+			Type: flowcontrolv1.PriorityLevelEnablementLimited,
+			Limited: &flowcontrolv1.LimitedPriorityLevelConfiguration{
+				NominalConcurrencyShares: ptr.To[int32](20),
+			},
+		},
+	}
+	client := fake.NewSimpleClientset()
+	recorder := events.NewInMemoryRecorder("test")
+
+	if _, modified, err := ApplyPriorityLevelConfiguration(context.TODO(), client.FlowcontrolV1(), recorder, required); err != nil || !modified {
+		t.Fatalf("expected create, got modified=%v err=%v", modified, err)
+	}
+
+	existing, err := client.FlowcontrolV1().PriorityLevelConfigurations().Get(context.TODO(), required.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing.Spec.Limited.LendablePercent = ptr.To[int32](0)
+	existing.Spec.Limited.LimitResponse = flowcontrolv1.LimitResponse{Type: flowcontrolv1.LimitResponseTypeReject}
+	if _, err := client.FlowcontrolV1().PriorityLevelConfigurations().Update(context.TODO(), existing, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, modified, err := ApplyPriorityLevelConfiguration(context.TODO(), client.FlowcontrolV1(), recorder, required); err != nil || modified {
+		t.Fatalf("expected no change for defaulted fields, got modified=%v err=%v", modified, err)
+	}
+
+	// drift of a required field is reverted
+	existing.Spec.Limited.NominalConcurrencyShares = ptr.To[int32](5)
+	if _, err := client.FlowcontrolV1().PriorityLevelConfigurations().Update(context.TODO(), existing, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	actual, modified, err := ApplyPriorityLevelConfiguration(context.TODO(), client.FlowcontrolV1(), recorder, required)
+	if err != nil || !modified {
+		t.Fatalf("expected update, got modified=%v err=%v", modified, err)
+	}
+	if *actual.Spec.Limited.NominalConcurrencyShares != 20 {
+		t.Errorf("unexpected spec %#v", actual.Spec.Limited)
+	}
+}
@@ -7,8 +7,11 @@ import (
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 
 	corev1 "k8s.io/api/core/v1"
+	flowcontrolv1 "k8s.io/api/flowcontrol/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	storagev1 "k8s.io/api/storage/v1"
@@ -102,140 +105,154 @@ func ApplyDirectly(ctx context.Context, clients *ClientHolder, recorder events.R
 		}
 		result.Type = fmt.Sprintf("%T", requiredObj)
 
-		// NOTE: Do not add CR resources into this switch otherwise the protobuf client can cause problems.
-		switch t := requiredObj.(type) {
-		case *corev1.Namespace:
-			if clients.kubeClient == nil {
-				result.Error = fmt.Errorf("missing kubeClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplyNamespaceImproved(ctx, clients.kubeClient.CoreV1(), recorder, t, cache)
-			}
-		case *corev1.Service:
-			if clients.kubeClient == nil {
-				result.Error = fmt.Errorf("missing kubeClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplyServiceImproved(ctx, clients.kubeClient.CoreV1(), recorder, t, cache)
-			}
-		case *corev1.Pod:
-			if clients.kubeClient == nil {
-				result.Error = fmt.Errorf("missing kubeClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplyPodImproved(ctx, clients.kubeClient.CoreV1(), recorder, t, cache)
-			}
-		case *corev1.ServiceAccount:
-			if clients.kubeClient == nil {
-				result.Error = fmt.Errorf("missing kubeClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplyServiceAccountImproved(ctx, clients.kubeClient.CoreV1(), recorder, t, cache)
-			}
-		case *corev1.ConfigMap:
-			client := clients.configMapsGetter()
-			if client == nil {
-				result.Error = fmt.Errorf("missing kubeClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplyConfigMapImproved(ctx, client, recorder, t, cache)
-			}
-		case *corev1.Secret:
-			client := clients.secretsGetter()
-			if client == nil {
-				result.Error = fmt.Errorf("missing kubeClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplySecretImproved(ctx, client, recorder, t, cache)
-			}
-		case *rbacv1.ClusterRole:
-			if clients.kubeClient == nil {
-				result.Error = fmt.Errorf("missing kubeClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplyClusterRole(ctx, clients.kubeClient.RbacV1(), recorder, t)
-			}
-		case *rbacv1.ClusterRoleBinding:
-			if clients.kubeClient == nil {
-				result.Error = fmt.Errorf("missing kubeClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplyClusterRoleBinding(ctx, clients.kubeClient.RbacV1(), recorder, t)
-			}
-		case *rbacv1.Role:
-			if clients.kubeClient == nil {
-				result.Error = fmt.Errorf("missing kubeClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplyRole(ctx, clients.kubeClient.RbacV1(), recorder, t)
-			}
-		case *rbacv1.RoleBinding:
-			if clients.kubeClient == nil {
-				result.Error = fmt.Errorf("missing kubeClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplyRoleBinding(ctx, clients.kubeClient.RbacV1(), recorder, t)
-			}
-		case *policyv1.PodDisruptionBudget:
-			if clients.kubeClient == nil {
-				result.Error = fmt.Errorf("missing kubeClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplyPodDisruptionBudget(ctx, clients.kubeClient.PolicyV1(), recorder, t)
-			}
-		case *apiextensionsv1.CustomResourceDefinition:
-			if clients.apiExtensionsClient == nil {
-				result.Error = fmt.Errorf("missing apiExtensionsClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplyCustomResourceDefinitionV1(ctx, clients.apiExtensionsClient.ApiextensionsV1(), recorder, t)
-			}
-		case *storagev1.StorageClass:
-			if clients.kubeClient == nil {
-				result.Error = fmt.Errorf("missing kubeClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplyStorageClass(ctx, clients.kubeClient.StorageV1(), recorder, t)
-			}
-		case *admissionregistrationv1.ValidatingWebhookConfiguration:
-			if clients.kubeClient == nil {
-				result.Error = fmt.Errorf("missing kubeClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplyValidatingWebhookConfigurationImproved(ctx, clients.kubeClient.AdmissionregistrationV1(), recorder, t, cache)
-			}
-		case *admissionregistrationv1.MutatingWebhookConfiguration:
-			if clients.kubeClient == nil {
-				result.Error = fmt.Errorf("missing kubeClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplyMutatingWebhookConfigurationImproved(ctx, clients.kubeClient.AdmissionregistrationV1(), recorder, t, cache)
-			}
-		case *admissionregistrationv1beta1.ValidatingAdmissionPolicy:
-			if clients.kubeClient == nil {
-				result.Error = fmt.Errorf("missing kubeClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplyValidatingAdmissionPolicyV1beta1(ctx, clients.kubeClient.AdmissionregistrationV1beta1(), recorder, t, cache)
-			}
-		case *admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding:
-			if clients.kubeClient == nil {
-				result.Error = fmt.Errorf("missing kubeClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplyValidatingAdmissionPolicyBindingV1beta1(ctx, clients.kubeClient.AdmissionregistrationV1beta1(), recorder, t, cache)
-			}
-		case *storagev1.CSIDriver:
-			if clients.kubeClient == nil {
-				result.Error = fmt.Errorf("missing kubeClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplyCSIDriver(ctx, clients.kubeClient.StorageV1(), recorder, t)
-			}
-		case *migrationv1alpha1.StorageVersionMigration:
-			if clients.migrationClient == nil {
-				result.Error = fmt.Errorf("missing migrationClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplyStorageVersionMigration(ctx, clients.migrationClient, recorder, t)
-			}
-		case *unstructured.Unstructured:
-			if clients.dynamicClient == nil {
-				result.Error = fmt.Errorf("missing dynamicClient")
-			} else {
-				result.Result, result.Changed, result.Error = ApplyKnownUnstructured(ctx, clients.dynamicClient, recorder, t)
-			}
-		default:
-			result.Error = fmt.Errorf("unhandled type %T", requiredObj)
-		}
-
+		result.Result, result.Changed, result.Error = applyObject(ctx, clients, recorder, cache, requiredObj)
 		ret = append(ret, result)
 	}
 
 	return ret
 }
 
+// applyObject applies the object with the Apply function of its type.
+func applyObject(ctx context.Context, clients *ClientHolder, recorder events.Recorder, cache ResourceCache, requiredObj runtime.Object) (runtime.Object, bool, error) {
+	// NOTE: Do not add CR resources into this switch otherwise the protobuf client can cause problems.
+	switch t := requiredObj.(type) {
+	case *corev1.Namespace:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyNamespaceImproved(ctx, clients.kubeClient.CoreV1(), recorder, t, cache)
+	case *corev1.Service:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyServiceImproved(ctx, clients.kubeClient.CoreV1(), recorder, t, cache)
+	case *corev1.Pod:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyPodImproved(ctx, clients.kubeClient.CoreV1(), recorder, t, cache)
+	case *corev1.ServiceAccount:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyServiceAccountImproved(ctx, clients.kubeClient.CoreV1(), recorder, t, cache)
+	case *corev1.ConfigMap:
+		client := clients.configMapsGetter()
+		if client == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyConfigMapImproved(ctx, client, recorder, t, cache)
+	case *corev1.Secret:
+		client := clients.secretsGetter()
+		if client == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplySecretImproved(ctx, client, recorder, t, cache)
+	case *rbacv1.ClusterRole:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyClusterRole(ctx, clients.kubeClient.RbacV1(), recorder, t)
+	case *rbacv1.ClusterRoleBinding:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyClusterRoleBinding(ctx, clients.kubeClient.RbacV1(), recorder, t)
+	case *rbacv1.Role:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyRole(ctx, clients.kubeClient.RbacV1(), recorder, t)
+	case *rbacv1.RoleBinding:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyRoleBinding(ctx, clients.kubeClient.RbacV1(), recorder, t)
+	case *policyv1.PodDisruptionBudget:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyPodDisruptionBudget(ctx, clients.kubeClient.PolicyV1(), recorder, t)
+	case *autoscalingv2.HorizontalPodAutoscaler:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyHorizontalPodAutoscaler(ctx, clients.kubeClient.AutoscalingV2(), recorder, t, cache)
+	case *networkingv1.NetworkPolicy:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyNetworkPolicy(ctx, clients.kubeClient.NetworkingV1(), recorder, t, cache)
+	case *flowcontrolv1.FlowSchema:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyFlowSchema(ctx, clients.kubeClient.FlowcontrolV1(), recorder, t)
+	case *flowcontrolv1.PriorityLevelConfiguration:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyPriorityLevelConfiguration(ctx, clients.kubeClient.FlowcontrolV1(), recorder, t)
+	case *apiextensionsv1.CustomResourceDefinition:
+		if clients.apiExtensionsClient == nil {
+			return nil, false, fmt.Errorf("missing apiExtensionsClient")
+		}
+		return ApplyCustomResourceDefinitionV1(ctx, clients.apiExtensionsClient.ApiextensionsV1(), recorder, t)
+	case *storagev1.StorageClass:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyStorageClass(ctx, clients.kubeClient.StorageV1(), recorder, t)
+	case *admissionregistrationv1.ValidatingWebhookConfiguration:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyValidatingWebhookConfigurationImproved(ctx, clients.kubeClient.AdmissionregistrationV1(), recorder, t, cache)
+	case *admissionregistrationv1.MutatingWebhookConfiguration:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyMutatingWebhookConfigurationImproved(ctx, clients.kubeClient.AdmissionregistrationV1(), recorder, t, cache)
+	case *admissionregistrationv1beta1.ValidatingAdmissionPolicy:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyValidatingAdmissionPolicyV1beta1(ctx, clients.kubeClient.AdmissionregistrationV1beta1(), recorder, t, cache)
+	case *admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyValidatingAdmissionPolicyBindingV1beta1(ctx, clients.kubeClient.AdmissionregistrationV1beta1(), recorder, t, cache)
+	case *admissionregistrationv1.ValidatingAdmissionPolicy:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyValidatingAdmissionPolicyV1(ctx, clients.kubeClient.AdmissionregistrationV1(), recorder, t, cache)
+	case *admissionregistrationv1.ValidatingAdmissionPolicyBinding:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyValidatingAdmissionPolicyBindingV1(ctx, clients.kubeClient.AdmissionregistrationV1(), recorder, t, cache)
+	case *storagev1.CSIDriver:
+		if clients.kubeClient == nil {
+			return nil, false, fmt.Errorf("missing kubeClient")
+		}
+		return ApplyCSIDriver(ctx, clients.kubeClient.StorageV1(), recorder, t)
+	case *migrationv1alpha1.StorageVersionMigration:
+		if clients.migrationClient == nil {
+			return nil, false, fmt.Errorf("missing migrationClient")
+		}
+		return ApplyStorageVersionMigration(ctx, clients.migrationClient, recorder, t)
+	case *unstructured.Unstructured:
+		if clients.dynamicClient == nil {
+			return nil, false, fmt.Errorf("missing dynamicClient")
+		}
+		return ApplyKnownUnstructured(ctx, clients.dynamicClient, recorder, t)
+	default:
+		return nil, false, fmt.Errorf("unhandled type %T", requiredObj)
+	}
+}
+
 func DeleteAll(ctx context.Context, clients *ClientHolder, recorder events.Recorder, manifests AssetFunc,
 	files ...string) []ApplyResult {
 	ret := []ApplyResult{}
@@ -337,6 +354,30 @@ func DeleteAll(ctx context.Context, clients *ClientHolder, recorder events.Recor
 			} else {
 				_, result.Changed, result.Error = DeletePodDisruptionBudget(ctx, clients.kubeClient.PolicyV1(), recorder, t)
 			}
+		case *autoscalingv2.HorizontalPodAutoscaler:
+			if clients.kubeClient == nil {
+				result.Error = fmt.Errorf("missing kubeClient")
+			} else {
+				_, result.Changed, result.Error = DeleteHorizontalPodAutoscaler(ctx, clients.kubeClient.AutoscalingV2(), recorder, t)
+			}
+		case *networkingv1.NetworkPolicy:
+			if clients.kubeClient == nil {
+				result.Error = fmt.Errorf("missing kubeClient")
+			} else {
+				_, result.Changed, result.Error = DeleteNetworkPolicy(ctx, clients.kubeClient.NetworkingV1(), recorder, t)
+			}
+		case *flowcontrolv1.FlowSchema:
+			if clients.kubeClient == nil {
+				result.Error = fmt.Errorf("missing kubeClient")
+			} else {
+				_, result.Changed, result.Error = DeleteFlowSchema(ctx, clients.kubeClient.FlowcontrolV1(), recorder, t)
+			}
+		case *flowcontrolv1.PriorityLevelConfiguration:
+			if clients.kubeClient == nil {
+				result.Error = fmt.Errorf("missing kubeClient")
+			} else {
+				_, result.Changed, result.Error = DeletePriorityLevelConfiguration(ctx, clients.kubeClient.FlowcontrolV1(), recorder, t)
+			}
 		case *apiextensionsv1.CustomResourceDefinition:
 			if clients.apiExtensionsClient == nil {
 				result.Error = fmt.Errorf("missing apiExtensionsClient")
@@ -355,6 +396,18 @@ func DeleteAll(ctx context.Context, clients *ClientHolder, recorder events.Recor
 			} else {
 				_, result.Changed, result.Error = DeleteValidatingWebhookConfiguration(ctx, clients.kubeClient.AdmissionregistrationV1(), recorder, t)
 			}
+		case *admissionregistrationv1.ValidatingAdmissionPolicy:
+			if clients.kubeClient == nil {
+				result.Error = fmt.Errorf("missing kubeClient")
+			} else {
+				_, result.Changed, result.Error = DeleteValidatingAdmissionPolicyV1(ctx, clients.kubeClient.AdmissionregistrationV1(), recorder, t)
+			}
+		case *admissionregistrationv1.ValidatingAdmissionPolicyBinding:
+			if clients.kubeClient == nil {
+				result.Error = fmt.Errorf("missing kubeClient")
+			} else {
+				_, result.Changed, result.Error = DeleteValidatingAdmissionPolicyBindingV1(ctx, clients.kubeClient.AdmissionregistrationV1(), recorder, t)
+			}
 		case *storagev1.CSIDriver:
 			if clients.kubeClient == nil {
 				result.Error = fmt.Errorf("missing kubeClient")
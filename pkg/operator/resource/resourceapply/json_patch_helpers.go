@@ -22,15 +22,17 @@ func JSONPatchNoError(original, modified runtime.Object) string {
 	if modified == nil {
 		return "modified object is nil"
 	}
-	originalJSON, err := runtime.Encode(unstructured.UnstructuredJSONScheme, original)
-	if err != nil {
+	originalBuf := getBuffer()
+	defer putBuffer(originalBuf)
+	if err := unstructured.UnstructuredJSONScheme.Encode(original, originalBuf); err != nil {
 		return fmt.Sprintf("unable to decode original to JSON: %v", err)
 	}
-	modifiedJSON, err := runtime.Encode(unstructured.UnstructuredJSONScheme, modified)
-	if err != nil {
+	modifiedBuf := getBuffer()
+	defer putBuffer(modifiedBuf)
+	if err := unstructured.UnstructuredJSONScheme.Encode(modified, modifiedBuf); err != nil {
 		return fmt.Sprintf("unable to decode modified to JSON: %v", err)
 	}
-	patchBytes, err := patch.CreateMergePatch(originalJSON, modifiedJSON)
+	patchBytes, err := patch.CreateMergePatch(originalBuf.Bytes(), modifiedBuf.Bytes())
 	if err != nil {
 		return fmt.Sprintf("unable to create JSON patch: %v", err)
 	}
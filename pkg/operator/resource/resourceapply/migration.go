@@ -21,7 +21,7 @@ func ApplyStorageVersionMigration(ctx context.Context, client migrationclientv1a
 	existing, err := clientInterface.Get(ctx, required.Name, metav1.GetOptions{})
 	if apierrors.IsNotFound(err) {
 		requiredCopy := required.DeepCopy()
-		actual, err := clientInterface.Create(ctx, resourcemerge.WithCleanLabelsAndAnnotations(requiredCopy).(*v1alpha1.StorageVersionMigration), metav1.CreateOptions{})
+		actual, err := clientInterface.Create(ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, requiredCopy)).(*v1alpha1.StorageVersionMigration), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, requiredCopy, err)
 		return actual, true, err
 	}
@@ -32,6 +32,9 @@ func ApplyStorageVersionMigration(ctx context.Context, client migrationclientv1a
 	modified := false
 	existingCopy := existing.DeepCopy()
 	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
 	if !modified && reflect.DeepEqual(existingCopy.Spec, required.Spec) {
 		return existingCopy, false, nil
 	}
@@ -41,6 +44,7 @@ func ApplyStorageVersionMigration(ctx context.Context, client migrationclientv1a
 	}
 
 	required.Spec.Resource.DeepCopyInto(&existingCopy.Spec.Resource)
+	reportChanges(ctx, recorder, existing, existingCopy)
 	actual, err := clientInterface.Update(ctx, existingCopy, metav1.UpdateOptions{})
 	resourcehelper.ReportUpdateEvent(recorder, required, err)
 	return actual, true, err
@@ -50,9 +50,41 @@ func DeletePrometheusRule(ctx context.Context, client dynamic.Interface, recorde
 	return DeleteUnstructuredResource(ctx, client, recorder, required, prometheusRuleGVR)
 }
 
-// ApplyServiceMonitor applies the ServiceMonitor.
+// ApplyServiceMonitor applies the ServiceMonitor. The action of the relabelings is defaulted like the CRD does, so
+// sparse manifests do not cause endless updates.
 func ApplyServiceMonitor(ctx context.Context, client dynamic.Interface, recorder events.Recorder, required *unstructured.Unstructured) (*unstructured.Unstructured, bool, error) {
-	return ApplyUnstructuredResourceImproved(ctx, client, recorder, required, noCache, serviceMonitorGVR, nil, nil)
+	return ApplyUnstructuredResourceImproved(ctx, client, recorder, required.DeepCopy(), noCache, serviceMonitorGVR, defaultUnstructuredServiceMonitor, nil)
+}
+
+// defaultUnstructuredServiceMonitor sets the action of the relabelings of the endpoints in place, leaving the other
+// fields as they are.
+func defaultUnstructuredServiceMonitor(obj *unstructured.Unstructured) {
+	endpoints, ok, err := unstructured.NestedSlice(obj.UnstructuredContent(), "spec", "endpoints")
+	if err != nil || !ok {
+		return
+	}
+	for _, endpoint := range endpoints {
+		endpointMap, ok := endpoint.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, field := range []string{"relabelings", "metricRelabelings"} {
+			configs, ok := endpointMap[field].([]interface{})
+			if !ok {
+				continue
+			}
+			for _, config := range configs {
+				configMap, ok := config.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if action, _ := configMap["action"].(string); len(action) == 0 {
+					configMap["action"] = "replace"
+				}
+			}
+		}
+	}
+	_ = unstructured.SetNestedSlice(obj.UnstructuredContent(), endpoints, "spec", "endpoints")
 }
 
 // DeleteServiceMonitor deletes the ServiceMonitor.
@@ -82,7 +114,7 @@ func ApplyUnstructuredResourceImproved(
 	}
 	existing, err := client.Resource(resourceGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
 	if errors.IsNotFound(err) {
-		want, errCreate := client.Resource(resourceGVR).Namespace(namespace).Create(ctx, required, metav1.CreateOptions{})
+		want, errCreate := client.Resource(resourceGVR).Namespace(namespace).Create(ctx, ownedForCreate(ctx, recorder, required.DeepCopy()), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, required, errCreate)
 		cache.UpdateCachedResourceMetadata(required, want)
 		return want, true, errCreate
@@ -104,6 +136,9 @@ func ApplyUnstructuredResourceImproved(
 	if err != nil {
 		return nil, false, err
 	}
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &didMetadataModify); err != nil {
+		return nil, false, err
+	}
 
 	// Deep-check the spec objects for equality, and update the cache in either case.
 	if defaultingFunc == nil {
@@ -128,6 +163,7 @@ func ApplyUnstructuredResourceImproved(
 	if klog.V(4).Enabled() {
 		klog.Infof("%s %q changes: %v", resourceGVR.String(), namespace+"/"+name, JSONPatchNoError(existing, existingCopy))
 	}
+	reportChanges(ctx, recorder, existing, existingCopy)
 	actual, errUpdate := client.Resource(resourceGVR).Namespace(namespace).Update(ctx, existingCopy, metav1.UpdateOptions{})
 	resourcehelper.ReportUpdateEvent(recorder, existingCopy, errUpdate)
 	cache.UpdateCachedResourceMetadata(existingCopy, actual)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -12,9 +13,11 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/json"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
 	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
 
 	pov1api "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 
@@ -167,3 +170,147 @@ func structuredToUnstructuredServiceMonitor(monitor *pov1api.ServiceMonitor) *un
 
 	return &unstructuredMonitor
 }
+
+func TestApplyServiceMonitorDefaulting(t *testing.T) {
+	dynamicScheme := runtime.NewScheme()
+	dynamicScheme.AddKnownTypeWithName(schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}, &unstructured.Unstructured{})
+
+	required := structuredToUnstructuredServiceMonitor(&structuredServiceMonitor)
+	endpoints := []interface{}{map[string]interface{}{
+		"port": "https",
+		// a field unknown to the vendored types must be kept as it is
+		"futureField": "value",
+		"metricRelabelings": []interface{}{map[string]interface{}{
+			"sourceLabels": []interface{}{"__name__"},
+			"regex":        "go_.*",
+		}},
+	}}
+	if err := unstructured.SetNestedSlice(required.Object, endpoints, "spec", "endpoints"); err != nil {
+		t.Fatal(err)
+	}
+
+	existing := required.DeepCopy()
+	existingEndpoints, _, _ := unstructured.NestedSlice(existing.Object, "spec", "endpoints")
+	existingEndpoints[0].(map[string]interface{})["metricRelabelings"].([]interface{})[0].(map[string]interface{})["action"] = "replace"
+	if err := unstructured.SetNestedSlice(existing.Object, existingEndpoints, "spec", "endpoints"); err != nil {
+		t.Fatal(err)
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(dynamicScheme, existing)
+	got, modified, err := ApplyServiceMonitor(context.TODO(), dynamicClient, events.NewInMemoryRecorder("monitor-test"), required)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modified {
+		t.Errorf("expected the defaulted service monitor not to be modified, actions: %v", dynamicClient.Actions())
+	}
+	if value, _, _ := unstructured.NestedSlice(got.Object, "spec", "endpoints"); value[0].(map[string]interface{})["futureField"] != "value" {
+		t.Errorf("expected the unknown field to be kept, got %v", value)
+	}
+	if requiredEndpoints, _, _ := unstructured.NestedSlice(required.Object, "spec", "endpoints"); !equality.Semantic.DeepEqual(requiredEndpoints, endpoints) {
+		t.Errorf("expected the required service monitor not to be mutated, got %v", requiredEndpoints)
+	}
+}
+
+func TestApplyTypedServiceMonitor(t *testing.T) {
+	dynamicScheme := runtime.NewScheme()
+	dynamicScheme.AddKnownTypeWithName(schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}, &unstructured.Unstructured{})
+
+	required := structuredServiceMonitor.DeepCopy()
+	required.Spec.Endpoints = []pov1api.Endpoint{{
+		Port:     "https",
+		Interval: "30s",
+		MetricRelabelConfigs: []pov1api.RelabelConfig{{
+			SourceLabels: []pov1api.LabelName{"__name__"},
+			Regex:        "go_.*",
+		}},
+	}}
+
+	// the API server defaults the relabeling action and prunes the fields the CRD does not know
+	stored := required.DeepCopy()
+	stored.Spec.Endpoints[0].MetricRelabelConfigs[0].Action = "replace"
+	existing := structuredToUnstructuredServiceMonitor(stored)
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(dynamicScheme, existing)
+	got, modified, err := ApplyTypedServiceMonitor(context.TODO(), dynamicClient, events.NewInMemoryRecorder("monitor-test"), required)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modified {
+		t.Errorf("expected the defaulted service monitor not to be modified, actions: %v", dynamicClient.Actions())
+	}
+	if got.Spec.Endpoints[0].MetricRelabelConfigs[0].Action != "replace" {
+		t.Errorf("expected the stored service monitor to be returned, got %#v", got.Spec)
+	}
+
+	required.Spec.Endpoints[0].Interval = "1m"
+	got, modified, err = ApplyTypedServiceMonitor(context.TODO(), dynamicClient, events.NewInMemoryRecorder("monitor-test"), required)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !modified || got.Spec.Endpoints[0].Interval != "1m" {
+		t.Errorf("expected the interval to be updated, got modified=%t and %#v", modified, got.Spec)
+	}
+
+	required.Spec.Endpoints[0].MetricRelabelConfigs[0].Action = "rename"
+	required.Spec.Endpoints[0].Interval = "1 minute"
+	if _, _, err := ApplyTypedServiceMonitor(context.TODO(), dynamicClient, events.NewInMemoryRecorder("monitor-test"), required); err == nil {
+		t.Errorf("expected an invalid service monitor to be rejected")
+	} else if !strings.Contains(err.Error(), "spec.endpoints[0].interval") || !strings.Contains(err.Error(), "spec.endpoints[0].metricRelabelings[0].action") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestApplyTypedPrometheusRule(t *testing.T) {
+	dynamicScheme := runtime.NewScheme()
+	dynamicScheme.AddKnownTypeWithName(schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule"}, &unstructured.Unstructured{})
+	dynamicScheme.AddKnownTypeWithName(schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRuleList"}, &unstructured.UnstructuredList{})
+
+	required := &pov1api.PrometheusRule{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-rules", Namespace: "test-ns"},
+		Spec: pov1api.PrometheusRuleSpec{
+			Groups: []pov1api.RuleGroup{{
+				Name: "test.rules",
+				Rules: []pov1api.Rule{{
+					Alert:       "TestDown",
+					Expr:        intstr.FromString(`up{job="test"} == 0`),
+					For:         ptr.To(pov1api.Duration("5m")),
+					Annotations: map[string]string{"summary": "test is down"},
+				}},
+			}},
+		},
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(dynamicScheme)
+	recorder := events.NewInMemoryRecorder("rule-test")
+	got, modified, err := ApplyTypedPrometheusRule(context.TODO(), dynamicClient, recorder, required)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !modified || got.Spec.Groups[0].Rules[0].Alert != "TestDown" {
+		t.Errorf("expected the rule to be created, got modified=%t and %#v", modified, got)
+	}
+
+	if _, modified, err := ApplyTypedPrometheusRule(context.TODO(), dynamicClient, recorder, required); err != nil || modified {
+		t.Errorf("expected no update, got modified=%t and error %v", modified, err)
+	}
+
+	invalid := required.DeepCopy()
+	invalid.Spec.Groups = append(invalid.Spec.Groups, pov1api.RuleGroup{
+		Name: "test.rules",
+		Rules: []pov1api.Rule{{
+			Record:      "job:up:sum",
+			Alert:       "TestDown",
+			Annotations: map[string]string{"summary": "test"},
+		}},
+	})
+	_, _, err = ApplyTypedPrometheusRule(context.TODO(), dynamicClient, recorder, invalid)
+	if err == nil {
+		t.Fatal("expected an invalid rule to be rejected")
+	}
+	for _, expected := range []string{"spec.groups[1].name", "spec.groups[1].rules[0].alert", "spec.groups[1].rules[0].expr", "spec.groups[1].rules[0].annotations"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error %q to mention %q", err, expected)
+		}
+	}
+}
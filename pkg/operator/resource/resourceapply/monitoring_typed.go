@@ -0,0 +1,236 @@
+package resourceapply
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// durationPattern is the pattern of the monitoringv1.Duration fields in the CRDs.
+var durationPattern = regexp.MustCompile(`^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$`)
+
+var relabelActions = sets.New[string]("replace", "keep", "drop", "hashmod", "labelmap", "labeldrop", "labelkeep", "lowercase", "uppercase", "keepequal", "dropequal")
+
+// ApplyTypedServiceMonitor validates and applies the typed ServiceMonitor. Unlike ApplyServiceMonitor, the spec is
+// round-tripped through the vendored typed ServiceMonitorSpec and compared in that form, so the fields the vendored
+// types do not know are dropped.
+func ApplyTypedServiceMonitor(ctx context.Context, client dynamic.Interface, recorder events.Recorder, required *monitoringv1.ServiceMonitor) (*monitoringv1.ServiceMonitor, bool, error) {
+	if err := validateServiceMonitor(required); err != nil {
+		return nil, false, fmt.Errorf("invalid ServiceMonitor %s/%s: %w", required.Namespace, required.Name, err)
+	}
+	requiredUnstructured, err := toMonitoringUnstructured(required, monitoringv1.ServiceMonitorsKind)
+	if err != nil {
+		return nil, false, err
+	}
+	actual, modified, err := ApplyUnstructuredResourceImproved(ctx, client, recorder, requiredUnstructured, noCache, serviceMonitorGVR,
+		typedSpecDefaulting(defaultServiceMonitor), typedSpecEquality[monitoringv1.ServiceMonitorSpec]{defaulter: defaultServiceMonitor})
+	if actual == nil {
+		return nil, modified, err
+	}
+	ret := &monitoringv1.ServiceMonitor{}
+	if convertErr := runtime.DefaultUnstructuredConverter.FromUnstructured(actual.UnstructuredContent(), ret); convertErr != nil {
+		return nil, modified, convertErr
+	}
+	return ret, modified, err
+}
+
+// ApplyTypedPrometheusRule validates and applies the typed PrometheusRule. Unlike ApplyPrometheusRule, the spec is
+// round-tripped through the vendored typed PrometheusRuleSpec and compared in that form.
+func ApplyTypedPrometheusRule(ctx context.Context, client dynamic.Interface, recorder events.Recorder, required *monitoringv1.PrometheusRule) (*monitoringv1.PrometheusRule, bool, error) {
+	if err := validatePrometheusRule(required); err != nil {
+		return nil, false, fmt.Errorf("invalid PrometheusRule %s/%s: %w", required.Namespace, required.Name, err)
+	}
+	requiredUnstructured, err := toMonitoringUnstructured(required, monitoringv1.PrometheusRuleKind)
+	if err != nil {
+		return nil, false, err
+	}
+	actual, modified, err := ApplyUnstructuredResourceImproved(ctx, client, recorder, requiredUnstructured, noCache, prometheusRuleGVR,
+		typedSpecDefaulting(func(*monitoringv1.PrometheusRuleSpec) {}), typedSpecEquality[monitoringv1.PrometheusRuleSpec]{})
+	if actual == nil {
+		return nil, modified, err
+	}
+	ret := &monitoringv1.PrometheusRule{}
+	if convertErr := runtime.DefaultUnstructuredConverter.FromUnstructured(actual.UnstructuredContent(), ret); convertErr != nil {
+		return nil, modified, convertErr
+	}
+	return ret, modified, err
+}
+
+func toMonitoringUnstructured(obj runtime.Object, kind string) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	ret := &unstructured.Unstructured{Object: content}
+	ret.SetAPIVersion(monitoringv1.SchemeGroupVersion.String())
+	ret.SetKind(kind)
+	// the converter keeps the zero creation timestamp, which the API server drops
+	unstructured.RemoveNestedField(ret.Object, "metadata", "creationTimestamp")
+	return ret, nil
+}
+
+// defaultServiceMonitor sets the defaults of the ServiceMonitor CRD.
+func defaultServiceMonitor(spec *monitoringv1.ServiceMonitorSpec) {
+	for i := range spec.Endpoints {
+		defaultRelabelConfigs(spec.Endpoints[i].RelabelConfigs)
+		defaultRelabelConfigs(spec.Endpoints[i].MetricRelabelConfigs)
+	}
+}
+
+func defaultRelabelConfigs(configs []monitoringv1.RelabelConfig) {
+	for i := range configs {
+		if len(configs[i].Action) == 0 {
+			configs[i].Action = "replace"
+		}
+	}
+}
+
+// typedSpecDefaulting returns a defaulting func setting the defaults of the typed spec T. The spec is rewritten from
+// its typed form, which also drops the fields T does not know, as the API server prunes them.
+func typedSpecDefaulting[T any](defaulter func(spec *T)) mimicDefaultingFunc {
+	return func(obj *unstructured.Unstructured) {
+		spec, ok, err := unstructured.NestedMap(obj.UnstructuredContent(), "spec")
+		if err != nil || !ok {
+			return
+		}
+		typed := new(T)
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(spec, typed); err != nil {
+			return
+		}
+		defaulter(typed)
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(typed)
+		if err != nil {
+			return
+		}
+		obj.Object["spec"] = content
+	}
+}
+
+// typedSpecEquality compares unstructured specs in their typed form T with the defaults set, so the representations
+// of the same spec compare equal. Specs that cannot be decoded are compared as they are.
+type typedSpecEquality[T any] struct {
+	defaulter func(spec *T)
+}
+
+func (e typedSpecEquality[T]) DeepEqual(existing, required interface{}) bool {
+	existingSpec, existingErr := e.decode(existing)
+	requiredSpec, requiredErr := e.decode(required)
+	if existingErr != nil || requiredErr != nil {
+		return equality.Semantic.DeepEqual(existing, required)
+	}
+	return equality.Semantic.DeepEqual(existingSpec, requiredSpec)
+}
+
+func (e typedSpecEquality[T]) decode(spec interface{}) (*T, error) {
+	ret := new(T)
+	content, ok := spec.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected spec type %T", spec)
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, ret); err != nil {
+		return nil, err
+	}
+	if e.defaulter != nil {
+		e.defaulter(ret)
+	}
+	return ret, nil
+}
+
+func validateServiceMonitor(serviceMonitor *monitoringv1.ServiceMonitor) error {
+	allErrs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+
+	if _, err := metav1.LabelSelectorAsSelector(&serviceMonitor.Spec.Selector); err != nil {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("selector"), serviceMonitor.Spec.Selector, err.Error()))
+	}
+	for i, endpoint := range serviceMonitor.Spec.Endpoints {
+		endpointPath := specPath.Child("endpoints").Index(i)
+		if len(endpoint.Scheme) > 0 && endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+			allErrs = append(allErrs, field.NotSupported(endpointPath.Child("scheme"), endpoint.Scheme, []string{"http", "https"}))
+		}
+		allErrs = append(allErrs, validateDuration(endpointPath.Child("interval"), string(endpoint.Interval))...)
+		allErrs = append(allErrs, validateDuration(endpointPath.Child("scrapeTimeout"), string(endpoint.ScrapeTimeout))...)
+		allErrs = append(allErrs, validateRelabelConfigs(endpointPath.Child("relabelings"), endpoint.RelabelConfigs)...)
+		allErrs = append(allErrs, validateRelabelConfigs(endpointPath.Child("metricRelabelings"), endpoint.MetricRelabelConfigs)...)
+	}
+	return allErrs.ToAggregate()
+}
+
+func validateRelabelConfigs(fldPath *field.Path, configs []monitoringv1.RelabelConfig) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, config := range configs {
+		if len(config.Action) > 0 && !relabelActions.Has(strings.ToLower(config.Action)) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Index(i).Child("action"), config.Action, sets.List(relabelActions)))
+		}
+		if len(config.Regex) > 0 {
+			if _, err := regexp.Compile(config.Regex); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("regex"), config.Regex, err.Error()))
+			}
+		}
+	}
+	return allErrs
+}
+
+func validatePrometheusRule(rule *monitoringv1.PrometheusRule) error {
+	allErrs := field.ErrorList{}
+	groupsPath := field.NewPath("spec", "groups")
+
+	groupNames := sets.New[string]()
+	for i, group := range rule.Spec.Groups {
+		groupPath := groupsPath.Index(i)
+		switch {
+		case len(group.Name) == 0:
+			allErrs = append(allErrs, field.Required(groupPath.Child("name"), ""))
+		case groupNames.Has(group.Name):
+			allErrs = append(allErrs, field.Duplicate(groupPath.Child("name"), group.Name))
+		}
+		groupNames.Insert(group.Name)
+		if group.Interval != nil {
+			allErrs = append(allErrs, validateDuration(groupPath.Child("interval"), string(*group.Interval))...)
+		}
+		if strategy := strings.ToLower(group.PartialResponseStrategy); len(strategy) > 0 && strategy != "abort" && strategy != "warn" {
+			allErrs = append(allErrs, field.NotSupported(groupPath.Child("partial_response_strategy"), group.PartialResponseStrategy, []string{"abort", "warn"}))
+		}
+
+		for j, r := range group.Rules {
+			rulePath := groupPath.Child("rules").Index(j)
+			switch {
+			case len(r.Record) > 0 && len(r.Alert) > 0:
+				allErrs = append(allErrs, field.Forbidden(rulePath.Child("alert"), "cannot be set together with record"))
+			case len(r.Record) == 0 && len(r.Alert) == 0:
+				allErrs = append(allErrs, field.Required(rulePath, "one of record and alert must be set"))
+			}
+			if r.Expr == (intstr.IntOrString{}) || (r.Expr.Type == intstr.String && len(r.Expr.StrVal) == 0) {
+				allErrs = append(allErrs, field.Required(rulePath.Child("expr"), ""))
+			}
+			if len(r.Record) > 0 && len(r.Annotations) > 0 {
+				allErrs = append(allErrs, field.Forbidden(rulePath.Child("annotations"), "recording rules cannot have annotations"))
+			}
+			if r.For != nil {
+				allErrs = append(allErrs, validateDuration(rulePath.Child("for"), string(*r.For))...)
+			}
+		}
+	}
+	return allErrs.ToAggregate()
+}
+
+func validateDuration(fldPath *field.Path, duration string) field.ErrorList {
+	if len(duration) == 0 || durationPattern.MatchString(duration) {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(fldPath, duration, "must be a duration like 30s or 1h30m")}
+}
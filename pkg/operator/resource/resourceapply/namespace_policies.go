@@ -0,0 +1,104 @@
+package resourceapply
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+const (
+	podSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+	podSecurityAuditLabel   = "pod-security.kubernetes.io/audit"
+	podSecurityWarnLabel    = "pod-security.kubernetes.io/warn"
+	// podSecurityLabelSyncLabel stops the cluster-policy-controller from syncing the pod security labels from SCCs.
+	podSecurityLabelSyncLabel = "security.openshift.io/scc.podSecurityLabelSync"
+	clusterMonitoringLabel    = "openshift.io/cluster-monitoring"
+
+	workloadPartitioningAnnotation = "workload.openshift.io/allowed"
+)
+
+// NamespacePolicies are the labels and annotations operator and operand namespaces are required to carry.
+type NamespacePolicies struct {
+	// PodSecurityLevel is the pod security admission level ("privileged", "baseline" or "restricted") enforced,
+	// audited and warned about in the namespace. Empty leaves the pod security labels alone.
+	PodSecurityLevel string
+	// WorkloadPartitioning allows the pods of the namespace to run on the reserved management CPUs.
+	WorkloadPartitioning bool
+	// ClusterMonitoring makes the platform monitoring stack scrape the namespace.
+	ClusterMonitoring bool
+}
+
+func (p NamespacePolicies) labels() map[string]string {
+	labels := map[string]string{}
+	if len(p.PodSecurityLevel) > 0 {
+		labels[podSecurityEnforceLabel] = p.PodSecurityLevel
+		labels[podSecurityAuditLabel] = p.PodSecurityLevel
+		labels[podSecurityWarnLabel] = p.PodSecurityLevel
+		labels[podSecurityLabelSyncLabel] = "false"
+	}
+	if p.ClusterMonitoring {
+		labels[clusterMonitoringLabel] = "true"
+	}
+	return labels
+}
+
+func (p NamespacePolicies) annotations() map[string]string {
+	annotations := map[string]string{}
+	if p.WorkloadPartitioning {
+		annotations[workloadPartitioningAnnotation] = "management"
+	}
+	return annotations
+}
+
+// ApplyNamespaceWithPolicies applies the namespace carrying the labels and annotations of the policies on top of the
+// required ones. Labels and annotations of the policies changed by someone else are reset, with a warning event for
+// each of them, so that overrides by cluster admins do not go unnoticed.
+func ApplyNamespaceWithPolicies(ctx context.Context, client coreclientv1.NamespacesGetter, recorder events.Recorder, requiredOriginal *corev1.Namespace, policies NamespacePolicies) (*corev1.Namespace, bool, error) {
+	required := requiredOriginal.DeepCopy()
+	policyLabels, policyAnnotations := policies.labels(), policies.annotations()
+	if required.Labels == nil && len(policyLabels) > 0 {
+		required.Labels = map[string]string{}
+	}
+	for k, v := range policyLabels {
+		required.Labels[k] = v
+	}
+	if required.Annotations == nil && len(policyAnnotations) > 0 {
+		required.Annotations = map[string]string{}
+	}
+	for k, v := range policyAnnotations {
+		required.Annotations[k] = v
+	}
+
+	existing, err := client.Namespaces().Get(ctx, required.Name, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, false, err
+	}
+	if err == nil {
+		for _, key := range overridden(existing.Labels, policyLabels) {
+			recorder.Warningf("NamespacePolicyOverridden", "Label %s of namespace %s was changed to %q, resetting it to %q", key, required.Name, existing.Labels[key], policyLabels[key])
+		}
+		for _, key := range overridden(existing.Annotations, policyAnnotations) {
+			recorder.Warningf("NamespacePolicyOverridden", "Annotation %s of namespace %s was changed to %q, resetting it to %q", key, required.Name, existing.Annotations[key], policyAnnotations[key])
+		}
+	}
+
+	return ApplyNamespace(ctx, client, recorder, required)
+}
+
+// overridden returns the sorted keys set to a different value than required. Missing keys are not overrides.
+func overridden(existing, required map[string]string) []string {
+	var keys []string
+	for k, v := range required {
+		if existingValue, ok := existing[k]; ok && existingValue != v {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
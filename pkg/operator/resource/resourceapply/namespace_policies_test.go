@@ -0,0 +1,108 @@
+package resourceapply
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestApplyNamespaceWithPolicies(t *testing.T) {
+	policies := NamespacePolicies{PodSecurityLevel: "restricted", WorkloadPartitioning: true, ClusterMonitoring: true}
+	expectedLabels := map[string]string{
+		"owner":                                          "operator",
+		"pod-security.kubernetes.io/enforce":             "restricted",
+		"pod-security.kubernetes.io/audit":               "restricted",
+		"pod-security.kubernetes.io/warn":                "restricted",
+		"security.openshift.io/scc.podSecurityLabelSync": "false",
+		"openshift.io/cluster-monitoring":                "true",
+	}
+	expectedAnnotations := map[string]string{"workload.openshift.io/allowed": "management"}
+
+	tests := []struct {
+		name     string
+		existing []runtime.Object
+
+		expectedModified bool
+		expectedWarnings int
+	}{
+		{
+			name:             "create",
+			expectedModified: true,
+		},
+		{
+			name: "up to date",
+			existing: []runtime.Object{&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "operand",
+				Labels:      expectedLabels,
+				Annotations: expectedAnnotations,
+			}}},
+		},
+		{
+			name: "missing policies are added without warnings",
+			existing: []runtime.Object{&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   "operand",
+				Labels: map[string]string{"owner": "operator"},
+			}}},
+			expectedModified: true,
+		},
+		{
+			name: "overrides are reset with warnings",
+			existing: []runtime.Object{&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name: "operand",
+				Labels: map[string]string{
+					"owner":                                          "operator",
+					"pod-security.kubernetes.io/enforce":             "privileged",
+					"pod-security.kubernetes.io/audit":               "restricted",
+					"pod-security.kubernetes.io/warn":                "restricted",
+					"security.openshift.io/scc.podSecurityLabelSync": "true",
+					"openshift.io/cluster-monitoring":                "true",
+				},
+				Annotations: expectedAnnotations,
+			}}},
+			expectedModified: true,
+			expectedWarnings: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(test.existing...)
+			recorder := events.NewInMemoryRecorder("test")
+			required := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "operand", Labels: map[string]string{"owner": "operator"}}}
+
+			actual, modified, err := ApplyNamespaceWithPolicies(context.TODO(), client.CoreV1(), recorder, required, policies)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if modified != test.expectedModified {
+				t.Errorf("expected modified %v, got %v", test.expectedModified, modified)
+			}
+			if diff := cmp.Diff(expectedLabels, actual.Labels); len(diff) > 0 {
+				t.Errorf("unexpected labels: %s", diff)
+			}
+			if diff := cmp.Diff(expectedAnnotations, actual.Annotations); len(diff) > 0 {
+				t.Errorf("unexpected annotations: %s", diff)
+			}
+			warnings := 0
+			for _, event := range recorder.Events() {
+				if event.Reason == "NamespacePolicyOverridden" {
+					warnings++
+				}
+			}
+			if warnings != test.expectedWarnings {
+				t.Errorf("expected %d warnings, got %d", test.expectedWarnings, warnings)
+			}
+			if len(required.Labels) != 1 {
+				t.Errorf("expected the required namespace not to be mutated, got %v", required.Labels)
+			}
+		})
+	}
+}
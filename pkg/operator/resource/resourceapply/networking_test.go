@@ -0,0 +1,79 @@
+package resourceapply
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestApplyNetworkPolicy(t *testing.T) {
+	required := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "deny-all"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+	client := fake.NewSimpleClientset()
+	recorder := events.NewInMemoryRecorder("test")
+
+	if _, modified, err := ApplyNetworkPolicy(context.TODO(), client.NetworkingV1(), recorder, required, noCache); err != nil || !modified {
+		t.Fatalf("expected create, got modified=%v err=%v", modified, err)
+	}
+	if _, modified, err := ApplyNetworkPolicy(context.TODO(), client.NetworkingV1(), recorder, required, noCache); err != nil || modified {
+		t.Fatalf("expected no change, got modified=%v err=%v", modified, err)
+	}
+
+	changed := required.DeepCopy()
+	changed.Spec.PolicyTypes = append(changed.Spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+	actual, modified, err := ApplyNetworkPolicy(context.TODO(), client.NetworkingV1(), recorder, changed, noCache)
+	if err != nil || !modified {
+		t.Fatalf("expected update, got modified=%v err=%v", modified, err)
+	}
+	if len(actual.Spec.PolicyTypes) != 2 {
+		t.Errorf("expected both policy types, got %v", actual.Spec.PolicyTypes)
+	}
+
+	if _, deleted, err := DeleteNetworkPolicy(context.TODO(), client.NetworkingV1(), recorder, required); err != nil || !deleted {
+		t.Fatalf("expected delete, got deleted=%v err=%v", deleted, err)
+	}
+}
+
+func TestApplyNetworkPolicyWithServerDefaults(t *testing.T) {
+	required := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "allow-metrics"},
+		Spec: networkingv1.NetworkPolicySpec{
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				Ports: []networkingv1.NetworkPolicyPort{{Port: ptr.To(intstr.FromInt32(8443))}},
+			}},
+			Egress: []networkingv1.NetworkPolicyEgressRule{{}},
+		},
+	}
+	// the existing policy as the API server defaults it
+	existing := required.DeepCopy()
+	existing.Spec.Ingress[0].Ports[0].Protocol = ptr.To(corev1.ProtocolTCP)
+	existing.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}
+	client := fake.NewSimpleClientset(existing)
+	recorder := events.NewInMemoryRecorder("test")
+
+	if _, modified, err := ApplyNetworkPolicy(context.TODO(), client.NetworkingV1(), recorder, required, noCache); err != nil || modified {
+		t.Fatalf("expected no change of the defaulted policy, got modified=%v err=%v", modified, err)
+	}
+
+	changed := required.DeepCopy()
+	changed.Spec.Ingress[0].Ports[0].Protocol = ptr.To(corev1.ProtocolUDP)
+	actual, modified, err := ApplyNetworkPolicy(context.TODO(), client.NetworkingV1(), recorder, changed, noCache)
+	if err != nil || !modified {
+		t.Fatalf("expected update, got modified=%v err=%v", modified, err)
+	}
+	if *actual.Spec.Ingress[0].Ports[0].Protocol != corev1.ProtocolUDP || len(actual.Spec.PolicyTypes) != 2 {
+		t.Errorf("unexpected spec: %#v", actual.Spec)
+	}
+}
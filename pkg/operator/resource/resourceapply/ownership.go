@@ -0,0 +1,155 @@
+package resourceapply
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// OwnershipPolicy makes the Apply* functions manage the controller owner reference of the applied objects.
+type OwnershipPolicy struct {
+	// Owner is set as the controller owner reference of the created objects.
+	Owner metav1.OwnerReference
+	// OwnerNamespace is the namespace of the owner, empty for a cluster-scoped owner. A namespaced owner is only set on
+	// objects in its namespace, the garbage collector deletes cluster-scoped objects and objects in other namespaces
+	// with a namespaced owner. The ownership of the other objects is left alone with an OwnershipSkipped event.
+	OwnerNamespace string
+	// AdoptUnowned sets the owner on existing objects without a controller owner reference. Without it, these objects
+	// are updated but not adopted.
+	AdoptUnowned bool
+	// Force takes over existing objects controlled by another owner. Without it, updating these objects fails with an
+	// OwnershipConflictError.
+	Force bool
+}
+
+// OwnershipConflictError is returned when an applied object is controlled by another owner than the one of the
+// ownership policy.
+type OwnershipConflictError struct {
+	Kind      string
+	Namespace string
+	Name      string
+	// Controller is the controller owner reference of the object.
+	Controller metav1.OwnerReference
+}
+
+func (e *OwnershipConflictError) Error() string {
+	return fmt.Sprintf("%s %s is controlled by %s %s", e.Kind, namespacedName(e.Namespace, e.Name), e.Controller.Kind, e.Controller.Name)
+}
+
+type ownershipPolicyKey struct{}
+
+// WithOwnershipPolicy returns a context which makes the Apply* functions set the owner of the policy as the controller
+// of the objects they create, adopt existing unowned objects if the policy says so, and refuse to update objects
+// controlled by someone else unless forced.
+func WithOwnershipPolicy(ctx context.Context, policy OwnershipPolicy) context.Context {
+	return context.WithValue(ctx, ownershipPolicyKey{}, &policy)
+}
+
+func ownershipPolicyFrom(ctx context.Context) *OwnershipPolicy {
+	policy, _ := ctx.Value(ownershipPolicyKey{}).(*OwnershipPolicy)
+	return policy
+}
+
+// controllerRef returns the owner of the policy as a controller owner reference.
+func (p *OwnershipPolicy) controllerRef() metav1.OwnerReference {
+	ref := p.Owner
+	ref.Controller = ptr.To(true)
+	return ref
+}
+
+// canOwn returns true when the owner of the policy can own the object: a cluster-scoped owner can own any object, a
+// namespaced owner only objects in its namespace. Otherwise an OwnershipSkipped event is emitted.
+func (p *OwnershipPolicy) canOwn(recorder events.Recorder, obj metav1.Object) bool {
+	if len(p.OwnerNamespace) == 0 || obj.GetNamespace() == p.OwnerNamespace {
+		return true
+	}
+	kind := ""
+	if runtimeObj, ok := obj.(runtime.Object); ok {
+		kind = objectKind(runtimeObj)
+	}
+	scope := fmt.Sprintf("in namespace %q", obj.GetNamespace())
+	if len(obj.GetNamespace()) == 0 {
+		scope = "cluster-scoped"
+	}
+	recorder.Warningf("OwnershipSkipped", "%s %q is %s, it cannot be owned by %s %q in namespace %q", kind, namespacedName(obj.GetNamespace(), obj.GetName()), scope, p.Owner.Kind, p.Owner.Name, p.OwnerNamespace)
+	return false
+}
+
+// ownedForCreate sets the owner of the ownership policy of the context, if any, as the controller of the object about
+// to be created, unless it already has a controller or cannot be owned by it. The object must not be shared with the
+// caller.
+func ownedForCreate[T metav1.Object](ctx context.Context, recorder events.Recorder, obj T) T {
+	noteApplyTarget(ctx, false)
+	policy := ownershipPolicyFrom(ctx)
+	if policy == nil || metav1.GetControllerOfNoCopy(obj) != nil || !policy.canOwn(recorder, obj) {
+		return obj
+	}
+	obj.SetOwnerReferences(append(obj.GetOwnerReferences(), policy.controllerRef()))
+	return obj
+}
+
+// enforceOwnership applies the ownership policy of the context, if any, to the update of the existing object to
+// toWrite: the owner is set on adopted and taken over objects, and objects controlled by another owner are refused
+// unless the policy forces it. Objects the owner cannot own are left alone. modified is set when the owner references
+// of toWrite change.
+func enforceOwnership(ctx context.Context, recorder events.Recorder, existing, toWrite metav1.Object, modified *bool) error {
+	noteApplyTarget(ctx, true)
+	policy := ownershipPolicyFrom(ctx)
+	if policy == nil || !policy.canOwn(recorder, existing) {
+		return nil
+	}
+	kind := ""
+	if obj, ok := existing.(runtime.Object); ok {
+		kind = objectKind(obj)
+	}
+	name := namespacedName(existing.GetNamespace(), existing.GetName())
+
+	controller := metav1.GetControllerOfNoCopy(existing)
+	switch {
+	case controller != nil && isOwner(*controller, policy.Owner):
+		return nil
+	case controller != nil && !policy.Force:
+		recorder.Warningf("OwnershipConflict", "%s %q is controlled by %s %q, refusing to update it", kind, name, controller.Kind, controller.Name)
+		return &OwnershipConflictError{Kind: kind, Namespace: existing.GetNamespace(), Name: existing.GetName(), Controller: *controller}
+	case controller != nil:
+		klog.Warningf("Taking over %s %q from %s %q", kind, name, controller.Kind, controller.Name)
+		recorder.Warningf("OwnershipTakenOver", "Took over %s %q from %s %q", kind, name, controller.Kind, controller.Name)
+	case !policy.AdoptUnowned:
+		return nil
+	default:
+		recorder.Eventf("OwnershipAdopted", "Adopted %s %q", kind, name)
+	}
+
+	// drop the previous controller and any stale reference to the owner, then set the owner as the controller
+	var refs []metav1.OwnerReference
+	for _, ref := range toWrite.GetOwnerReferences() {
+		if ptr.Deref(ref.Controller, false) || isOwner(ref, policy.Owner) {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	toWrite.SetOwnerReferences(append(refs, policy.controllerRef()))
+	*modified = true
+	return nil
+}
+
+// isOwner returns true if the reference points to the owner, by UID when both have one.
+func isOwner(ref, owner metav1.OwnerReference) bool {
+	if len(ref.UID) > 0 && len(owner.UID) > 0 {
+		return ref.UID == owner.UID
+	}
+	return ref.APIVersion == owner.APIVersion && ref.Kind == owner.Kind && ref.Name == owner.Name
+}
+
+func namespacedName(namespace, name string) string {
+	if len(namespace) == 0 {
+		return name
+	}
+	return namespace + "/" + name
+}
@@ -0,0 +1,178 @@
+package resourceapply
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestOwnershipPolicy(t *testing.T) {
+	owner := metav1.OwnerReference{APIVersion: "operator.openshift.io/v1", Kind: "Foo", Name: "cluster", UID: "owner-uid"}
+	other := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "other", UID: "other-uid", Controller: ptr.To(true)}
+	unrelated := metav1.OwnerReference{APIVersion: "v1", Kind: "Namespace", Name: "ns", UID: "ns-uid"}
+	configMap := func(data string, ownerRefs ...metav1.OwnerReference) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cm", OwnerReferences: ownerRefs},
+			Data:       map[string]string{"key": data},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		existing        *corev1.ConfigMap
+		policy          OwnershipPolicy
+		expectConflict  bool
+		expectOwnerRefs []metav1.OwnerReference
+		expectEvent     string
+	}{
+		{
+			name:            "created objects are owned",
+			policy:          OwnershipPolicy{Owner: owner},
+			expectOwnerRefs: []metav1.OwnerReference{{APIVersion: owner.APIVersion, Kind: owner.Kind, Name: owner.Name, UID: owner.UID, Controller: ptr.To(true)}},
+		},
+		{
+			name:            "unowned objects are not adopted by default",
+			existing:        configMap("other", unrelated),
+			policy:          OwnershipPolicy{Owner: owner},
+			expectOwnerRefs: []metav1.OwnerReference{unrelated},
+		},
+		{
+			name:     "unowned objects are adopted",
+			existing: configMap("ours", unrelated),
+			policy:   OwnershipPolicy{Owner: owner, AdoptUnowned: true},
+			expectOwnerRefs: []metav1.OwnerReference{
+				unrelated,
+				{APIVersion: owner.APIVersion, Kind: owner.Kind, Name: owner.Name, UID: owner.UID, Controller: ptr.To(true)},
+			},
+			expectEvent: "OwnershipAdopted",
+		},
+		{
+			name:            "objects controlled by someone else are refused",
+			existing:        configMap("other", other),
+			policy:          OwnershipPolicy{Owner: owner, AdoptUnowned: true},
+			expectConflict:  true,
+			expectOwnerRefs: []metav1.OwnerReference{other},
+			expectEvent:     "OwnershipConflict",
+		},
+		{
+			name:            "objects controlled by someone else are taken over when forced",
+			existing:        configMap("other", other),
+			policy:          OwnershipPolicy{Owner: owner, Force: true},
+			expectOwnerRefs: []metav1.OwnerReference{{APIVersion: owner.APIVersion, Kind: owner.Kind, Name: owner.Name, UID: owner.UID, Controller: ptr.To(true)}},
+			expectEvent:     "OwnershipTakenOver",
+		},
+		{
+			name:            "objects controlled by the owner are updated",
+			existing:        configMap("other", metav1.OwnerReference{APIVersion: owner.APIVersion, Kind: owner.Kind, Name: owner.Name, UID: owner.UID, Controller: ptr.To(true)}),
+			policy:          OwnershipPolicy{Owner: owner},
+			expectOwnerRefs: []metav1.OwnerReference{{APIVersion: owner.APIVersion, Kind: owner.Kind, Name: owner.Name, UID: owner.UID, Controller: ptr.To(true)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			if tt.existing != nil {
+				client = fake.NewSimpleClientset(tt.existing)
+			}
+			recorder := events.NewInMemoryRecorder("test")
+			required := configMap("ours")
+
+			_, _, err := ApplyConfigMap(WithOwnershipPolicy(context.TODO(), tt.policy), client.CoreV1(), recorder, required)
+			var conflictErr *OwnershipConflictError
+			if tt.expectConflict != errors.As(err, &conflictErr) {
+				t.Fatalf("expected conflict %v, got %v", tt.expectConflict, err)
+			}
+			if !tt.expectConflict && err != nil {
+				t.Fatal(err)
+			}
+			if len(required.OwnerReferences) != 0 {
+				t.Errorf("the required object must not be changed, got %v", required.OwnerReferences)
+			}
+
+			actual, err := client.CoreV1().ConfigMaps("ns").Get(context.TODO(), "cm", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !equalOwnerRefs(actual.OwnerReferences, tt.expectOwnerRefs) {
+				t.Errorf("expected owner references %v, got %v", tt.expectOwnerRefs, actual.OwnerReferences)
+			}
+			if expectedData := "ours"; tt.expectConflict {
+				if actual.Data["key"] == expectedData {
+					t.Errorf("expected the object to be left alone")
+				}
+			} else if actual.Data["key"] != expectedData {
+				t.Errorf("expected the object to be updated, got %v", actual.Data)
+			}
+
+			if len(tt.expectEvent) > 0 {
+				found := false
+				for _, event := range recorder.Events() {
+					found = found || event.Reason == tt.expectEvent
+				}
+				if !found {
+					t.Errorf("expected a %s event", tt.expectEvent)
+				}
+			}
+		})
+	}
+}
+
+func TestOwnershipPolicyOwnerScope(t *testing.T) {
+	owner := metav1.OwnerReference{APIVersion: "operator.openshift.io/v1", Kind: "Foo", Name: "foo", UID: "owner-uid"}
+	ctx := WithOwnershipPolicy(context.TODO(), OwnershipPolicy{Owner: owner, OwnerNamespace: "ns", AdoptUnowned: true})
+	client := fake.NewSimpleClientset(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "existing"}})
+	recorder := events.NewInMemoryRecorder("test")
+
+	namespace, _, err := ApplyNamespace(ctx, client.CoreV1(), recorder, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(namespace.OwnerReferences) != 0 {
+		t.Errorf("expected a cluster-scoped object not to be owned by a namespaced owner, got %v", namespace.OwnerReferences)
+	}
+	for _, name := range []string{"created", "existing"} {
+		configMap, _, err := ApplyConfigMap(ctx, client.CoreV1(), recorder, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: name}, Data: map[string]string{"key": "value"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(configMap.OwnerReferences) != 0 {
+			t.Errorf("expected config map %q in another namespace not to be owned, got %v", name, configMap.OwnerReferences)
+		}
+	}
+	configMap, _, err := ApplyConfigMap(ctx, client.CoreV1(), recorder, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cm"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configMap.OwnerReferences) != 1 || configMap.OwnerReferences[0].UID != owner.UID {
+		t.Errorf("expected a config map in the namespace of the owner to be owned, got %v", configMap.OwnerReferences)
+	}
+
+	skipped := 0
+	for _, event := range recorder.Events() {
+		if event.Reason == "OwnershipSkipped" {
+			skipped++
+		}
+	}
+	if skipped != 3 {
+		t.Errorf("expected 3 OwnershipSkipped events, got %d", skipped)
+	}
+}
+
+func equalOwnerRefs(a, b []metav1.OwnerReference) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].UID != b[i].UID || ptr.Deref(a[i].Controller, false) != ptr.Deref(b[i].Controller, false) {
+			return false
+		}
+	}
+	return true
+}
@@ -20,7 +20,7 @@ func ApplyPodDisruptionBudget(ctx context.Context, client policyclientv1.PodDisr
 	if apierrors.IsNotFound(err) {
 		requiredCopy := required.DeepCopy()
 		actual, err := client.PodDisruptionBudgets(required.Namespace).Create(
-			ctx, resourcemerge.WithCleanLabelsAndAnnotations(requiredCopy).(*policyv1.PodDisruptionBudget), metav1.CreateOptions{})
+			ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, requiredCopy)).(*policyv1.PodDisruptionBudget), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, required, err)
 		return actual, true, err
 	}
@@ -32,6 +32,9 @@ func ApplyPodDisruptionBudget(ctx context.Context, client policyclientv1.PodDisr
 	existingCopy := existing.DeepCopy()
 
 	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
 	contentSame := equality.Semantic.DeepEqual(existingCopy.Spec, required.Spec)
 	if contentSame && !modified {
 		return existingCopy, false, nil
@@ -42,12 +45,72 @@ func ApplyPodDisruptionBudget(ctx context.Context, client policyclientv1.PodDisr
 	if klog.V(2).Enabled() {
 		klog.Infof("PodDisruptionBudget %q changes: %v", required.Name, JSONPatchNoError(existing, existingCopy))
 	}
+	reportChanges(ctx, recorder, existing, existingCopy)
 
 	actual, err := client.PodDisruptionBudgets(required.Namespace).Update(ctx, existingCopy, metav1.UpdateOptions{})
 	resourcehelper.ReportUpdateEvent(recorder, required, err)
 	return actual, true, err
 }
 
+// ApplyPodDisruptionBudgetWithGeneration merges objectmeta and requires matching generation, like ApplyDeployment.
+// The hash of the required spec is stored in an annotation, so any change of the required spec changes the metadata.
+// The update is skipped when the metadata is unchanged and the generation equals the expectedGeneration, which is
+// the generation of the object returned by the previous call. This avoids comparing against defaulted spec fields.
+func ApplyPodDisruptionBudgetWithGeneration(ctx context.Context, client policyclientv1.PodDisruptionBudgetsGetter, recorder events.Recorder,
+	requiredOriginal *policyv1.PodDisruptionBudget, expectedGeneration int64, cache ResourceCache) (*policyv1.PodDisruptionBudget, bool, error) {
+
+	required := requiredOriginal.DeepCopy()
+	if err := SetSpecHashAnnotation(&required.ObjectMeta, required.Spec); err != nil {
+		return nil, false, err
+	}
+
+	existing, err := client.PodDisruptionBudgets(required.Namespace).Get(ctx, required.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		actual, err := client.PodDisruptionBudgets(required.Namespace).Create(
+			ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, required.DeepCopy())).(*policyv1.PodDisruptionBudget), metav1.CreateOptions{})
+		resourcehelper.ReportCreateEvent(recorder, required, err)
+		if err != nil {
+			return nil, false, err
+		}
+		cache.UpdateCachedResourceMetadata(requiredOriginal, actual)
+		return actual, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if cache.SafeToSkipApply(requiredOriginal, existing) && existing.Generation == expectedGeneration {
+		return existing, false, nil
+	}
+
+	modified := false
+	existingCopy := existing.DeepCopy()
+
+	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
+	if !modified && existingCopy.Generation == expectedGeneration {
+		cache.UpdateCachedResourceMetadata(requiredOriginal, existingCopy)
+		return existingCopy, false, nil
+	}
+
+	existingCopy.Spec = required.Spec
+
+	if klog.V(2).Enabled() {
+		klog.Infof("PodDisruptionBudget %q changes: %v", required.Namespace+"/"+required.Name, JSONPatchNoError(existing, existingCopy))
+	}
+	reportChanges(ctx, recorder, existing, existingCopy)
+
+	actual, err := client.PodDisruptionBudgets(required.Namespace).Update(ctx, existingCopy, metav1.UpdateOptions{})
+	resourcehelper.ReportUpdateEvent(recorder, required, err)
+	if err != nil {
+		return nil, false, err
+	}
+	cache.UpdateCachedResourceMetadata(requiredOriginal, actual)
+	return actual, true, nil
+}
+
 func DeletePodDisruptionBudget(ctx context.Context, client policyclientv1.PodDisruptionBudgetsGetter, recorder events.Recorder, required *policyv1.PodDisruptionBudget) (*policyv1.PodDisruptionBudget, bool, error) {
 	err := client.PodDisruptionBudgets(required.Namespace).Delete(ctx, required.Name, metav1.DeleteOptions{})
 	if err != nil && apierrors.IsNotFound(err) {
@@ -0,0 +1,59 @@
+package resourceapply
+
+import (
+	"context"
+	"testing"
+
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestApplyPodDisruptionBudgetWithGeneration(t *testing.T) {
+	maxUnavailable := intstr.FromInt32(1)
+	required := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pdb"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{MaxUnavailable: &maxUnavailable},
+	}
+	client := fake.NewSimpleClientset()
+	recorder := events.NewInMemoryRecorder("test")
+
+	actual, modified, err := ApplyPodDisruptionBudgetWithGeneration(context.TODO(), client.PolicyV1(), recorder, required, -1, noCache)
+	if err != nil || !modified {
+		t.Fatalf("expected create, got modified=%v err=%v", modified, err)
+	}
+	if len(actual.Annotations[specHashAnnotation]) == 0 {
+		t.Errorf("expected the spec hash annotation to be set")
+	}
+
+	// the server defaults a field, the generation did not change
+	defaulted := actual.DeepCopy()
+	policy := policyv1.IfHealthyBudget
+	defaulted.Spec.UnhealthyPodEvictionPolicy = &policy
+	if _, err := client.PolicyV1().PodDisruptionBudgets("ns").Update(context.TODO(), defaulted, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, modified, err := ApplyPodDisruptionBudgetWithGeneration(context.TODO(), client.PolicyV1(), recorder, required, actual.Generation, noCache); err != nil || modified {
+		t.Fatalf("expected no change with matching generation, got modified=%v err=%v", modified, err)
+	}
+
+	// a different generation means someone else changed the spec
+	if _, modified, err := ApplyPodDisruptionBudgetWithGeneration(context.TODO(), client.PolicyV1(), recorder, required, actual.Generation+1, noCache); err != nil || !modified {
+		t.Fatalf("expected update with different generation, got modified=%v err=%v", modified, err)
+	}
+
+	// a changed spec changes the hash annotation
+	changed := required.DeepCopy()
+	minAvailable := intstr.FromInt32(2)
+	changed.Spec = policyv1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable}
+	actual, modified, err = ApplyPodDisruptionBudgetWithGeneration(context.TODO(), client.PolicyV1(), recorder, changed, actual.Generation, noCache)
+	if err != nil || !modified {
+		t.Fatalf("expected update, got modified=%v err=%v", modified, err)
+	}
+	if actual.Spec.MinAvailable == nil || actual.Spec.MaxUnavailable != nil {
+		t.Errorf("unexpected spec: %#v", actual.Spec)
+	}
+}
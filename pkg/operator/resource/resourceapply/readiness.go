@@ -0,0 +1,37 @@
+package resourceapply
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ObjectReady returns whether an applied object can be used by the objects depending on it: a namespace must be
+// Active, and a CRD must be Established before its custom resources can be created. The other objects are ready once
+// applied. It checks the status of the object returned by the apply, which is the existing object when nothing
+// changed, so it does not read the object again. An error is returned when the object will not become ready without
+// intervention: a namespace being terminated, or a CRD whose names were not accepted.
+func ObjectReady(obj runtime.Object) (bool, error) {
+	switch t := obj.(type) {
+	case *corev1.Namespace:
+		if t.Status.Phase == corev1.NamespaceTerminating {
+			return false, fmt.Errorf("namespace %s is terminating", t.Name)
+		}
+		return t.Status.Phase == corev1.NamespaceActive, nil
+	case *apiextensionsv1.CustomResourceDefinition:
+		established := false
+		for _, condition := range t.Status.Conditions {
+			switch {
+			case condition.Type == apiextensionsv1.NamesAccepted && condition.Status == apiextensionsv1.ConditionFalse:
+				return false, fmt.Errorf("names of customresourcedefinition %s not accepted: %s: %s", t.Name, condition.Reason, condition.Message)
+			case condition.Type == apiextensionsv1.Established:
+				established = condition.Status == apiextensionsv1.ConditionTrue
+			}
+		}
+		return established, nil
+	default:
+		return true, nil
+	}
+}
@@ -3,7 +3,6 @@ package resourceapply
 import (
 	"crypto/md5"
 	"fmt"
-	"io"
 	"reflect"
 
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -160,9 +159,8 @@ func (c *resourceCache) SafeToSkipApply(required runtime.Object, existing runtim
 // detect changes in a resource by caching a hash of the string representation of the resource
 // note: some changes in a resource e.g. nil vs empty, will not be detected this way
 func hashOfResourceStruct(o interface{}) string {
-	oString := fmt.Sprintf("%v", o)
-	h := md5.New()
-	io.WriteString(h, oString)
-	rval := fmt.Sprintf("%x", h.Sum(nil))
-	return rval
+	buf := getBuffer()
+	defer putBuffer(buf)
+	fmt.Fprintf(buf, "%v", o)
+	return fmt.Sprintf("%x", md5.Sum(buf.Bytes()))
 }
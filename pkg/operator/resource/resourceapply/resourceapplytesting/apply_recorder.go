@@ -0,0 +1,196 @@
+package resourceapplytesting
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// FakeClient is implemented by the fake clientsets the resourceapply functions are called with, e.g. the fakes of
+// kubernetes, apiextensions or the dynamic client.
+type FakeClient interface {
+	PrependReactor(verb, resource string, reaction clienttesting.ReactionFunc)
+	Tracker() clienttesting.ObjectTracker
+}
+
+// Write is a create, update, patch or delete sent to a fake client.
+type Write struct {
+	Verb      string
+	Resource  schema.GroupVersionResource
+	Namespace string
+	Name      string
+	// Diff is the difference to the existing object for creates and updates, and the patch for patches.
+	Diff string
+	// Err is the scripted failure returned for the write, if any.
+	Err error
+}
+
+func (w Write) String() string {
+	if len(w.Namespace) == 0 {
+		return fmt.Sprintf("%s %s %s", w.Verb, w.Resource.Resource, w.Name)
+	}
+	return fmt.Sprintf("%s %s %s/%s", w.Verb, w.Resource.Resource, w.Namespace, w.Name)
+}
+
+type scriptedFailure struct {
+	verb, resource, name string
+	nth                  int
+	err                  error
+	matched              int
+}
+
+// ApplyRecorder records the writes of the resourceapply functions, and of controllers in general, to fake clients,
+// and fails them as scripted, e.g. a conflict on the second update of a configmap, without replacing the fake
+// clients.
+type ApplyRecorder struct {
+	lock     sync.Mutex
+	writes   []Write
+	failures []*scriptedFailure
+}
+
+// NewApplyRecorder returns a recorder of the writes to the fake clients.
+func NewApplyRecorder(clients ...FakeClient) *ApplyRecorder {
+	r := &ApplyRecorder{}
+	for _, client := range clients {
+		client.PrependReactor("*", "*", r.reactor(client.Tracker()))
+	}
+	return r
+}
+
+// FailOn fails the nth, counting from 1, write with the verb to the named object of the resource with the error. An
+// empty name matches any object, and nth 0 matches every write.
+func (r *ApplyRecorder) FailOn(verb, resource, name string, nth int, err error) *ApplyRecorder {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.failures = append(r.failures, &scriptedFailure{verb: verb, resource: resource, name: name, nth: nth, err: err})
+	return r
+}
+
+// Writes returns the recorded writes in the order they were sent.
+func (r *ApplyRecorder) Writes() []Write {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]Write{}, r.writes...)
+}
+
+// WritesTo returns the recorded writes with the verb to the resource, in the order they were sent.
+func (r *ApplyRecorder) WritesTo(verb, resource string) []Write {
+	var ret []Write
+	for _, write := range r.Writes() {
+		if write.Verb == verb && write.Resource.Resource == resource {
+			ret = append(ret, write)
+		}
+	}
+	return ret
+}
+
+// Reset forgets the recorded writes, the scripted failures are kept.
+func (r *ApplyRecorder) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.writes = nil
+}
+
+// String describes the recorded writes, one per line.
+func (r *ApplyRecorder) String() string {
+	var lines []string
+	for _, write := range r.Writes() {
+		lines = append(lines, write.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *ApplyRecorder) reactor(tracker clienttesting.ObjectTracker) clienttesting.ReactionFunc {
+	return func(action clienttesting.Action) (bool, runtime.Object, error) {
+		write, ok := newWrite(action, tracker)
+		if !ok {
+			return false, nil, nil
+		}
+
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		write.Err = r.scriptedFailure(write)
+		r.writes = append(r.writes, write)
+		if write.Err != nil {
+			return true, nil, write.Err
+		}
+		// let the fake client handle the write
+		return false, nil, nil
+	}
+}
+
+func (r *ApplyRecorder) scriptedFailure(write Write) error {
+	for _, failure := range r.failures {
+		if failure.verb != write.Verb || failure.resource != write.Resource.Resource || (len(failure.name) > 0 && failure.name != write.Name) {
+			continue
+		}
+		failure.matched++
+		if failure.nth == 0 || failure.nth == failure.matched {
+			return failure.err
+		}
+	}
+	return nil
+}
+
+func newWrite(action clienttesting.Action, tracker clienttesting.ObjectTracker) (Write, bool) {
+	write := Write{
+		Verb:      action.GetVerb(),
+		Resource:  action.GetResource(),
+		Namespace: action.GetNamespace(),
+	}
+	switch action := action.(type) {
+	case clienttesting.CreateAction:
+		// updates implement CreateAction too
+		obj := action.GetObject()
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return Write{}, false
+		}
+		write.Name = accessor.GetName()
+		var existing runtime.Object
+		if write.Verb == "update" {
+			existing, _ = tracker.Get(write.Resource, write.Namespace, write.Name)
+		}
+		write.Diff = diff(existing, obj)
+	case clienttesting.PatchAction:
+		write.Name = action.GetName()
+		write.Diff = string(action.GetPatch())
+	case clienttesting.DeleteAction:
+		write.Name = action.GetName()
+	default:
+		return Write{}, false
+	}
+	if write.Resource.Resource == "events" {
+		return Write{}, false
+	}
+	return write, true
+}
+
+// diff compares the unstructured content of the objects, as the typed objects might have unexported fields.
+func diff(existing, required runtime.Object) string {
+	var existingContent, requiredContent map[string]interface{}
+	if existing != nil {
+		existingContent, _ = runtime.DefaultUnstructuredConverter.ToUnstructured(existing)
+	}
+	if required != nil {
+		requiredContent, _ = runtime.DefaultUnstructuredConverter.ToUnstructured(required)
+	}
+	return cmp.Diff(existingContent, requiredContent)
+}
+
+// NewConflict returns the error of a write conflicting with a concurrent one, for FailOn.
+func NewConflict(resource, name string) error {
+	return apierrors.NewConflict(schema.GroupResource{Resource: resource}, name, fmt.Errorf("the object has been modified; please apply your changes to the latest version and try again"))
+}
+
+// NewTimeout returns the error of a write timing out, for FailOn.
+func NewTimeout(verb, resource, name string) error {
+	return apierrors.NewTimeoutError(fmt.Sprintf("%s of %s %s timed out", verb, resource, name), 1)
+}
@@ -0,0 +1,79 @@
+package resourceapplytesting
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+)
+
+func TestApplyRecorder(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	recorder := NewApplyRecorder(client).
+		FailOn("update", "configmaps", "config", 2, NewConflict("configmaps", "config")).
+		FailOn("delete", "configmaps", "", 1, NewTimeout("delete", "configmaps", "config"))
+	eventRecorder := events.NewInMemoryRecorder("test")
+
+	configMap := func(value string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "config"},
+			Data:       map[string]string{"key": value},
+		}
+	}
+
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, client.CoreV1(), eventRecorder, configMap("a")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, client.CoreV1(), eventRecorder, configMap("b")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, client.CoreV1(), eventRecorder, configMap("c")); !apierrors.IsConflict(err) {
+		t.Fatalf("expected a conflict on the second update, got %v", err)
+	}
+	if _, _, err := resourceapply.DeleteConfigMap(ctx, client.CoreV1(), eventRecorder, configMap("c")); !apierrors.IsTimeout(err) {
+		t.Fatalf("expected a timeout on the delete, got %v", err)
+	}
+	if _, _, err := resourceapply.DeleteConfigMap(ctx, client.CoreV1(), eventRecorder, configMap("c")); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Join([]string{
+		"create configmaps ns/config",
+		"update configmaps ns/config",
+		"update configmaps ns/config",
+		"delete configmaps ns/config",
+		"delete configmaps ns/config",
+	}, "\n")
+	if recorder.String() != expected {
+		t.Errorf("unexpected writes:\n%s\nexpected:\n%s", recorder, expected)
+	}
+
+	updates := recorder.WritesTo("update", "configmaps")
+	if len(updates) != 2 {
+		t.Fatalf("expected 2 updates, got %d", len(updates))
+	}
+	if updates[0].Err != nil || !strings.Contains(updates[0].Diff, `"a"`) || !strings.Contains(updates[0].Diff, `"b"`) {
+		t.Errorf("unexpected first update %#v", updates[0])
+	}
+	if !apierrors.IsConflict(updates[1].Err) {
+		t.Errorf("expected the second update to be failed, got %v", updates[1].Err)
+	}
+
+	// the failed write was not persisted
+	if _, err := client.CoreV1().ConfigMaps("ns").Get(ctx, "config", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the configmap to be deleted, got %v", err)
+	}
+
+	recorder.Reset()
+	if len(recorder.Writes()) != 0 {
+		t.Errorf("expected no writes after reset")
+	}
+}
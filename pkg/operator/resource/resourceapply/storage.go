@@ -37,7 +37,7 @@ func ApplyStorageClass(ctx context.Context, client storageclientv1.StorageClasse
 	if apierrors.IsNotFound(err) {
 		requiredCopy := required.DeepCopy()
 		actual, err := client.StorageClasses().Create(
-			ctx, resourcemerge.WithCleanLabelsAndAnnotations(requiredCopy).(*storagev1.StorageClass), metav1.CreateOptions{})
+			ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, requiredCopy)).(*storagev1.StorageClass), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, required, err)
 		return actual, true, err
 	}
@@ -65,6 +65,9 @@ func ApplyStorageClass(ctx context.Context, client storageclientv1.StorageClasse
 	modified := false
 	existingCopy := existing.DeepCopy()
 	resourcemerge.EnsureObjectMeta(&modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &modified); err != nil {
+		return nil, false, err
+	}
 
 	// Second, let's compare the other fields. StorageClass doesn't have a spec and we don't
 	// want to miss fields, so we have to copy required to get all fields
@@ -81,6 +84,7 @@ func ApplyStorageClass(ctx context.Context, client storageclientv1.StorageClasse
 	if klog.V(2).Enabled() {
 		klog.Infof("StorageClass %q changes: %v", required.Name, JSONPatchNoError(existingCopy, requiredCopy))
 	}
+	reportChanges(ctx, recorder, existingCopy, requiredCopy)
 
 	if storageClassNeedsRecreate(existingCopy, requiredCopy) {
 		requiredCopy.ObjectMeta.ResourceVersion = ""
@@ -89,7 +93,7 @@ func ApplyStorageClass(ctx context.Context, client storageclientv1.StorageClasse
 		if err != nil && !apierrors.IsNotFound(err) {
 			return existing, false, err
 		}
-		actual, err := client.StorageClasses().Create(ctx, requiredCopy, metav1.CreateOptions{})
+		actual, err := client.StorageClasses().Create(ctx, ownedForCreate(ctx, recorder, requiredCopy), metav1.CreateOptions{})
 		if err != nil && apierrors.IsAlreadyExists(err) {
 			// Delete() few lines above did not really delete the object,
 			// the API server is probably waiting for a finalizer removal or so.
@@ -153,7 +157,7 @@ func ApplyCSIDriver(ctx context.Context, client storageclientv1.CSIDriversGetter
 	if apierrors.IsNotFound(err) {
 		requiredCopy := required.DeepCopy()
 		actual, err := client.CSIDrivers().Create(
-			ctx, resourcemerge.WithCleanLabelsAndAnnotations(requiredCopy).(*storagev1.CSIDriver), metav1.CreateOptions{})
+			ctx, resourcemerge.WithCleanLabelsAndAnnotations(ownedForCreate(ctx, recorder, requiredCopy)).(*storagev1.CSIDriver), metav1.CreateOptions{})
 		resourcehelper.ReportCreateEvent(recorder, required, err)
 		return actual, true, err
 	}
@@ -173,6 +177,9 @@ func ApplyCSIDriver(ctx context.Context, client storageclientv1.CSIDriversGetter
 	metadataModified := false
 	existingCopy := existing.DeepCopy()
 	resourcemerge.EnsureObjectMeta(&metadataModified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if err := enforceOwnership(ctx, recorder, existing, existingCopy, &metadataModified); err != nil {
+		return nil, false, err
+	}
 
 	requiredSpecHash := required.Annotations[specHashAnnotation]
 	existingSpecHash := existing.Annotations[specHashAnnotation]
@@ -184,6 +191,7 @@ func ApplyCSIDriver(ctx context.Context, client storageclientv1.CSIDriversGetter
 	if klog.V(2).Enabled() {
 		klog.Infof("CSIDriver %q changes: %v", required.Name, JSONPatchNoError(existing, existingCopy))
 	}
+	reportChanges(ctx, recorder, existing, existingCopy)
 
 	if sameSpec {
 		// Update metadata by a simple Update call
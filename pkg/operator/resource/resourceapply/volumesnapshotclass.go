@@ -81,7 +81,7 @@ func ensureGenericVolumeSnapshotClass(required, existing *unstructured.Unstructu
 func ApplyVolumeSnapshotClass(ctx context.Context, client dynamic.Interface, recorder events.Recorder, required *unstructured.Unstructured) (*unstructured.Unstructured, bool, error) {
 	existing, err := client.Resource(volumeSnapshotClassResourceGVR).Get(ctx, required.GetName(), metav1.GetOptions{})
 	if errors.IsNotFound(err) {
-		newObj, createErr := client.Resource(volumeSnapshotClassResourceGVR).Create(ctx, required, metav1.CreateOptions{})
+		newObj, createErr := client.Resource(volumeSnapshotClassResourceGVR).Create(ctx, ownedForCreate(ctx, recorder, required.DeepCopy()), metav1.CreateOptions{})
 		if createErr != nil {
 			recorder.Warningf("VolumeSnapshotClassCreateFailed", "Failed to create VolumeSnapshotClass.snapshot.storage.k8s.io/v1: %v", createErr)
 			return nil, true, createErr
@@ -97,6 +97,12 @@ func ApplyVolumeSnapshotClass(ctx context.Context, client dynamic.Interface, rec
 	if err != nil {
 		return nil, false, err
 	}
+	if !modified {
+		toUpdate = existing.DeepCopy()
+	}
+	if err := enforceOwnership(ctx, recorder, existing, toUpdate, &modified); err != nil {
+		return nil, false, err
+	}
 
 	if !modified {
 		return existing, false, nil
@@ -105,6 +111,7 @@ func ApplyVolumeSnapshotClass(ctx context.Context, client dynamic.Interface, rec
 	if klog.V(2).Enabled() {
 		klog.Infof("VolumeSnapshotClass %q changes: %v", required.GetName(), JSONPatchNoError(existing, toUpdate))
 	}
+	reportChanges(ctx, recorder, existing, toUpdate)
 
 	newObj, err := client.Resource(volumeSnapshotClassResourceGVR).Update(ctx, toUpdate, metav1.UpdateOptions{})
 	if err != nil {
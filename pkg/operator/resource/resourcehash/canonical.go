@@ -0,0 +1,191 @@
+package resourcehash
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultTerminationMessagePath       = "/dev/termination-log"
+	defaultSchedulerName                = "default-scheduler"
+	defaultVolumeMode             int32 = 0644
+	defaultGracePeriod            int64 = 30
+)
+
+// CanonicalPodTemplateSpec returns a copy of the pod template with all fields the API server sets to their defaults
+// cleared, so templates read from the server compare and hash equal to the templates they were created from.
+// Only fields set to their default values are cleared, an explicitly required non-default value is kept.
+func CanonicalPodTemplateSpec(template *corev1.PodTemplateSpec) *corev1.PodTemplateSpec {
+	ret := template.DeepCopy()
+	ret.CreationTimestamp = metav1.Time{}
+	ret.ManagedFields = nil
+	ret.ResourceVersion = ""
+	ret.Generation = 0
+
+	spec := &ret.Spec
+	if spec.RestartPolicy == corev1.RestartPolicyAlways {
+		spec.RestartPolicy = ""
+	}
+	if spec.DNSPolicy == corev1.DNSClusterFirst {
+		spec.DNSPolicy = ""
+	}
+	if spec.SchedulerName == defaultSchedulerName {
+		spec.SchedulerName = ""
+	}
+	if spec.TerminationGracePeriodSeconds != nil && *spec.TerminationGracePeriodSeconds == defaultGracePeriod {
+		spec.TerminationGracePeriodSeconds = nil
+	}
+	if spec.SecurityContext != nil && equality.Semantic.DeepEqual(*spec.SecurityContext, corev1.PodSecurityContext{}) {
+		spec.SecurityContext = nil
+	}
+	for i := range spec.InitContainers {
+		canonicalizeContainer(&spec.InitContainers[i])
+	}
+	for i := range spec.Containers {
+		canonicalizeContainer(&spec.Containers[i])
+	}
+	for i := range spec.Volumes {
+		canonicalizeVolume(&spec.Volumes[i])
+	}
+	return ret
+}
+
+func canonicalizeContainer(container *corev1.Container) {
+	if container.TerminationMessagePath == defaultTerminationMessagePath {
+		container.TerminationMessagePath = ""
+	}
+	if container.TerminationMessagePolicy == corev1.TerminationMessageReadFile {
+		container.TerminationMessagePolicy = ""
+	}
+	if container.ImagePullPolicy == defaultImagePullPolicy(container.Image) {
+		container.ImagePullPolicy = ""
+	}
+	for i := range container.Ports {
+		if container.Ports[i].Protocol == corev1.ProtocolTCP {
+			container.Ports[i].Protocol = ""
+		}
+	}
+	for _, probe := range []*corev1.Probe{container.LivenessProbe, container.ReadinessProbe, container.StartupProbe} {
+		canonicalizeProbe(probe)
+	}
+}
+
+// defaultImagePullPolicy mirrors the API server defaulting: images tagged latest or without a tag are always pulled.
+func defaultImagePullPolicy(image string) corev1.PullPolicy {
+	if strings.Contains(image, "@") {
+		return corev1.PullIfNotPresent
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if !strings.Contains(name, ":") || strings.HasSuffix(name, ":latest") {
+		return corev1.PullAlways
+	}
+	return corev1.PullIfNotPresent
+}
+
+func canonicalizeProbe(probe *corev1.Probe) {
+	if probe == nil {
+		return
+	}
+	if probe.TimeoutSeconds == 1 {
+		probe.TimeoutSeconds = 0
+	}
+	if probe.PeriodSeconds == 10 {
+		probe.PeriodSeconds = 0
+	}
+	if probe.SuccessThreshold == 1 {
+		probe.SuccessThreshold = 0
+	}
+	if probe.FailureThreshold == 3 {
+		probe.FailureThreshold = 0
+	}
+	if probe.HTTPGet != nil && probe.HTTPGet.Scheme == corev1.URISchemeHTTP {
+		probe.HTTPGet.Scheme = ""
+	}
+}
+
+func canonicalizeVolume(volume *corev1.Volume) {
+	clearDefaultMode := func(mode **int32) {
+		if *mode != nil && **mode == defaultVolumeMode {
+			*mode = nil
+		}
+	}
+	switch {
+	case volume.ConfigMap != nil:
+		clearDefaultMode(&volume.ConfigMap.DefaultMode)
+	case volume.Secret != nil:
+		clearDefaultMode(&volume.Secret.DefaultMode)
+	case volume.Projected != nil:
+		clearDefaultMode(&volume.Projected.DefaultMode)
+	case volume.DownwardAPI != nil:
+		clearDefaultMode(&volume.DownwardAPI.DefaultMode)
+	}
+}
+
+// GetPodTemplateHash returns a hash of the canonical pod template, which does not change when the API server
+// defaults fields of the template or adds managed fields.
+func GetPodTemplateHash(template *corev1.PodTemplateSpec) (string, error) {
+	return hashJSON(CanonicalPodTemplateSpec(template))
+}
+
+// GetConfigMapCanonicalHash returns a hash of the data and binary data of the configmap. Metadata is ignored.
+func GetConfigMapCanonicalHash(obj *corev1.ConfigMap) (string, error) {
+	return hashJSON(struct {
+		Data       map[string]string `json:"data,omitempty"`
+		BinaryData map[string][]byte `json:"binaryData,omitempty"`
+	}{
+		Data:       nonEmptyStringMap(obj.Data),
+		BinaryData: nonEmptyBytesMap(obj.BinaryData),
+	})
+}
+
+// GetSecretCanonicalHash returns a hash of the type and data of the secret. StringData is merged into the data the
+// same way the API server does it, metadata is ignored.
+func GetSecretCanonicalHash(obj *corev1.Secret) (string, error) {
+	data := map[string][]byte{}
+	for key, value := range obj.Data {
+		data[key] = value
+	}
+	for key, value := range obj.StringData {
+		data[key] = []byte(value)
+	}
+	secretType := obj.Type
+	if secretType == corev1.SecretTypeOpaque {
+		secretType = ""
+	}
+	return hashJSON(struct {
+		Type corev1.SecretType `json:"type,omitempty"`
+		Data map[string][]byte `json:"data,omitempty"`
+	}{
+		Type: secretType,
+		Data: nonEmptyBytesMap(data),
+	})
+}
+
+func nonEmptyStringMap(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+func nonEmptyBytesMap(m map[string][]byte) map[string][]byte {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// hashJSON hashes the JSON serialization of the object, which has sorted map keys.
+func hashJSON(obj interface{}) (string, error) {
+	jsonBytes, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(jsonBytes)), nil
+}
@@ -0,0 +1,96 @@
+package resourcehash
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+func TestGetPodTemplateHash(t *testing.T) {
+	required := &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "foo"}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "foo",
+				Image: "registry/foo:v1",
+				Ports: []corev1.ContainerPort{{ContainerPort: 8443}},
+				ReadinessProbe: &corev1.Probe{ProbeHandler: corev1.ProbeHandler{
+					HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt32(8443)},
+				}},
+			}},
+			Volumes: []corev1.Volume{{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{}}}},
+		},
+	}
+
+	defaulted := required.DeepCopy()
+	defaulted.CreationTimestamp = metav1.Now()
+	defaulted.Spec.RestartPolicy = corev1.RestartPolicyAlways
+	defaulted.Spec.DNSPolicy = corev1.DNSClusterFirst
+	defaulted.Spec.SchedulerName = "default-scheduler"
+	defaulted.Spec.TerminationGracePeriodSeconds = ptr.To[int64](30)
+	defaulted.Spec.SecurityContext = &corev1.PodSecurityContext{}
+	container := &defaulted.Spec.Containers[0]
+	container.TerminationMessagePath = "/dev/termination-log"
+	container.TerminationMessagePolicy = corev1.TerminationMessageReadFile
+	container.ImagePullPolicy = corev1.PullIfNotPresent
+	container.Ports[0].Protocol = corev1.ProtocolTCP
+	container.ReadinessProbe.TimeoutSeconds = 1
+	container.ReadinessProbe.PeriodSeconds = 10
+	container.ReadinessProbe.SuccessThreshold = 1
+	container.ReadinessProbe.FailureThreshold = 3
+	container.ReadinessProbe.HTTPGet.Scheme = corev1.URISchemeHTTP
+	defaulted.Spec.Volumes[0].ConfigMap.DefaultMode = ptr.To[int32](0644)
+
+	requiredHash, err := GetPodTemplateHash(required)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultedHash, err := GetPodTemplateHash(defaulted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requiredHash != defaultedHash {
+		t.Errorf("expected defaulting to not change the hash")
+	}
+
+	nonDefault := defaulted.DeepCopy()
+	nonDefault.Spec.Containers[0].ImagePullPolicy = corev1.PullAlways
+	nonDefaultHash, err := GetPodTemplateHash(nonDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonDefaultHash == requiredHash {
+		t.Errorf("expected a non default value to change the hash")
+	}
+}
+
+func TestDefaultImagePullPolicy(t *testing.T) {
+	for image, expected := range map[string]corev1.PullPolicy{
+		"foo":                     corev1.PullAlways,
+		"foo:latest":              corev1.PullAlways,
+		"registry:5000/foo":       corev1.PullAlways,
+		"registry:5000/foo:v1":    corev1.PullIfNotPresent,
+		"registry/foo@sha256:abc": corev1.PullIfNotPresent,
+	} {
+		if actual := defaultImagePullPolicy(image); actual != expected {
+			t.Errorf("%s: expected %s, got %s", image, expected, actual)
+		}
+	}
+}
+
+func TestCanonicalDataHashes(t *testing.T) {
+	first, _ := GetConfigMapCanonicalHash(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "a", ResourceVersion: "1"}, Data: map[string]string{"k": "v"}})
+	second, _ := GetConfigMapCanonicalHash(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "a", ResourceVersion: "2"}, Data: map[string]string{"k": "v"}, BinaryData: map[string][]byte{}})
+	if first != second {
+		t.Errorf("expected metadata and empty binary data to not change the configmap hash")
+	}
+
+	fromStringData, _ := GetSecretCanonicalHash(&corev1.Secret{StringData: map[string]string{"k": "v"}})
+	fromData, _ := GetSecretCanonicalHash(&corev1.Secret{Type: corev1.SecretTypeOpaque, Data: map[string][]byte{"k": []byte("v")}})
+	if fromStringData != fromData {
+		t.Errorf("expected string data and defaulted type to not change the secret hash")
+	}
+}
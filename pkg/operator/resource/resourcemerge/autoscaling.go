@@ -0,0 +1,76 @@
+package resourcemerge
+
+import (
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/utils/ptr"
+)
+
+// EnsureHorizontalPodAutoscaler ensures that the existing matches the required.
+// modified is set to true when existing had to be updated with required.
+func EnsureHorizontalPodAutoscaler(modified *bool, existing *autoscalingv2.HorizontalPodAutoscaler, required autoscalingv2.HorizontalPodAutoscaler) {
+	EnsureObjectMeta(modified, &existing.ObjectMeta, required.ObjectMeta)
+
+	// we need to match defaults
+	mimicHorizontalPodAutoscalerV2Defaulting(&required)
+	// we stomp everything
+	if !equality.Semantic.DeepEqual(existing.Spec, required.Spec) {
+		*modified = true
+		existing.Spec = required.Spec
+	}
+}
+
+// lifted from https://github.com/kubernetes/kubernetes/blob/v1.31.0/pkg/apis/autoscaling/v2/defaults.go
+func mimicHorizontalPodAutoscalerV2Defaulting(required *autoscalingv2.HorizontalPodAutoscaler) {
+	required.Spec = *required.Spec.DeepCopy()
+	if required.Spec.MinReplicas == nil {
+		required.Spec.MinReplicas = ptr.To[int32](1)
+	}
+	if len(required.Spec.Metrics) == 0 {
+		required.Spec.Metrics = []autoscalingv2.MetricSpec{
+			{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{
+					Name: corev1.ResourceCPU,
+					Target: autoscalingv2.MetricTarget{
+						Type:               autoscalingv2.UtilizationMetricType,
+						AverageUtilization: ptr.To[int32](80),
+					},
+				},
+			},
+		}
+	}
+	if required.Spec.Behavior != nil {
+		required.Spec.Behavior.ScaleUp = hpa_copyScalingRules(required.Spec.Behavior.ScaleUp, &autoscalingv2.HPAScalingRules{
+			StabilizationWindowSeconds: ptr.To[int32](0),
+			SelectPolicy:               ptr.To(autoscalingv2.MaxChangePolicySelect),
+			Policies: []autoscalingv2.HPAScalingPolicy{
+				{Type: autoscalingv2.PodsScalingPolicy, Value: 4, PeriodSeconds: 15},
+				{Type: autoscalingv2.PercentScalingPolicy, Value: 100, PeriodSeconds: 15},
+			},
+		})
+		required.Spec.Behavior.ScaleDown = hpa_copyScalingRules(required.Spec.Behavior.ScaleDown, &autoscalingv2.HPAScalingRules{
+			SelectPolicy: ptr.To(autoscalingv2.MaxChangePolicySelect),
+			Policies: []autoscalingv2.HPAScalingPolicy{
+				{Type: autoscalingv2.PercentScalingPolicy, Value: 100, PeriodSeconds: 15},
+			},
+		})
+	}
+}
+
+func hpa_copyScalingRules(from, to *autoscalingv2.HPAScalingRules) *autoscalingv2.HPAScalingRules {
+	if from == nil {
+		return to
+	}
+	if from.SelectPolicy != nil {
+		to.SelectPolicy = from.SelectPolicy
+	}
+	if from.StabilizationWindowSeconds != nil {
+		to.StabilizationWindowSeconds = from.StabilizationWindowSeconds
+	}
+	if from.Policies != nil {
+		to.Policies = from.Policies
+	}
+	return to
+}
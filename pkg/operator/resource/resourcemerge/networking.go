@@ -0,0 +1,48 @@
+package resourcemerge
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/utils/ptr"
+)
+
+// EnsureNetworkPolicy ensures that the existing matches the required.
+// modified is set to true when existing had to be updated with required.
+func EnsureNetworkPolicy(modified *bool, existing *networkingv1.NetworkPolicy, required networkingv1.NetworkPolicy) {
+	EnsureObjectMeta(modified, &existing.ObjectMeta, required.ObjectMeta)
+
+	// we need to match defaults
+	mimicNetworkPolicyV1Defaulting(&required)
+	// we stomp everything
+	if !equality.Semantic.DeepEqual(existing.Spec, required.Spec) {
+		*modified = true
+		existing.Spec = required.Spec
+	}
+}
+
+// lifted from https://github.com/kubernetes/kubernetes/blob/v1.31.0/pkg/apis/networking/v1/defaults.go
+func mimicNetworkPolicyV1Defaulting(required *networkingv1.NetworkPolicy) {
+	required.Spec = *required.Spec.DeepCopy()
+	for i := range required.Spec.Ingress {
+		networkPolicy_SetDefaults_Ports(required.Spec.Ingress[i].Ports)
+	}
+	for i := range required.Spec.Egress {
+		networkPolicy_SetDefaults_Ports(required.Spec.Egress[i].Ports)
+	}
+	if len(required.Spec.PolicyTypes) == 0 {
+		// any policy that does not specify policyTypes implies at least "Ingress"
+		required.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+		if len(required.Spec.Egress) != 0 {
+			required.Spec.PolicyTypes = append(required.Spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+		}
+	}
+}
+
+func networkPolicy_SetDefaults_Ports(ports []networkingv1.NetworkPolicyPort) {
+	for i := range ports {
+		if ports[i].Protocol == nil {
+			ports[i].Protocol = ptr.To(corev1.ProtocolTCP)
+		}
+	}
+}
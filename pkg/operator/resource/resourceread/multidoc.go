@@ -0,0 +1,176 @@
+package resourceread
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"sigs.k8s.io/yaml"
+)
+
+var strictGenericCodec = serializer.NewCodecFactory(genericScheme, serializer.EnableStrict).UniversalDeserializer()
+
+// documentSeparator matches the YAML document separator lines.
+var documentSeparator = regexp.MustCompile(`^---\s*(#.*)?$`)
+
+// DocumentError describes a document of a multi-document stream that failed to decode.
+type DocumentError struct {
+	// Index is the zero based index of the document in the stream.
+	Index int
+	// Item is the zero based index of the item when the document is a List, -1 otherwise.
+	Item int
+	// Line is the line the document starts at.
+	Line int
+	Err  error
+}
+
+func (e *DocumentError) Error() string {
+	if e.Item >= 0 {
+		return fmt.Sprintf("document %d (line %d), item %d: %v", e.Index, e.Line, e.Item, e.Err)
+	}
+	return fmt.Sprintf("document %d (line %d): %v", e.Index, e.Line, e.Err)
+}
+
+func (e *DocumentError) Unwrap() error {
+	return e.Err
+}
+
+// ReadGenericDocuments parses a multi-document YAML (or JSON) stream using the known scheme (see genericScheme).
+// Documents of kind List are expanded into their items. Known kinds are decoded strictly, unknown and duplicate
+// fields are errors. Kinds not registered in the scheme are returned as Unstructured. Empty documents are skipped.
+// The returned error is a *DocumentError pointing to the first document that failed to decode.
+func ReadGenericDocuments(data []byte) ([]runtime.Object, error) {
+	var ret []runtime.Object
+	for index, document := range splitDocuments(data) {
+		objs, err := readDocument(document.content)
+		if err != nil {
+			if documentErr, ok := err.(*DocumentError); ok {
+				documentErr.Index = index
+				documentErr.Line = document.line
+				return nil, documentErr
+			}
+			return nil, &DocumentError{Index: index, Item: -1, Line: document.line, Err: err}
+		}
+		ret = append(ret, objs...)
+	}
+	return ret, nil
+}
+
+// ReadGenericDocumentsOrDie is like ReadGenericDocuments but panics on error.
+func ReadGenericDocumentsOrDie(data []byte) []runtime.Object {
+	objs, err := ReadGenericDocuments(data)
+	if err != nil {
+		panic(err)
+	}
+	return objs
+}
+
+// ReadDocumentsAs parses a multi-document YAML stream like ReadGenericDocuments and returns the objects as typed
+// slice. It fails when any of the objects is not of type T.
+func ReadDocumentsAs[T runtime.Object](data []byte) ([]T, error) {
+	objs, err := ReadGenericDocuments(data)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]T, 0, len(objs))
+	for i, obj := range objs {
+		typed, ok := obj.(T)
+		if !ok {
+			var expected T
+			return nil, fmt.Errorf("object %d: expected %T, got %T", i, expected, obj)
+		}
+		ret = append(ret, typed)
+	}
+	return ret, nil
+}
+
+type document struct {
+	line    int
+	content []byte
+}
+
+// splitDocuments splits the stream on document separators and drops documents without content.
+func splitDocuments(data []byte) []document {
+	var documents []document
+	current := document{line: 1}
+	flush := func() {
+		if hasContent(current.content) {
+			documents = append(documents, current)
+		}
+	}
+	for i, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if documentSeparator.Match(bytes.TrimRight(line, "\r\n")) {
+			flush()
+			current = document{line: i + 2}
+			continue
+		}
+		current.content = append(current.content, line...)
+	}
+	flush()
+	return documents
+}
+
+func hasContent(content []byte) bool {
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) > 0 && trimmed[0] != '#' {
+			return true
+		}
+	}
+	return false
+}
+
+func readDocument(content []byte) ([]runtime.Object, error) {
+	jsonContent, err := yaml.YAMLToJSONStrict(content)
+	if err != nil {
+		return nil, err
+	}
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(jsonContent, &typeMeta); err != nil {
+		return nil, err
+	}
+	if typeMeta.Kind != "List" {
+		obj, err := readStrict(jsonContent)
+		if err != nil {
+			return nil, err
+		}
+		return []runtime.Object{obj}, nil
+	}
+
+	list := struct {
+		Items []json.RawMessage `json:"items"`
+	}{}
+	if err := json.Unmarshal(jsonContent, &list); err != nil {
+		return nil, err
+	}
+	ret := make([]runtime.Object, 0, len(list.Items))
+	for i, item := range list.Items {
+		obj, err := readStrict(item)
+		if err != nil {
+			return nil, &DocumentError{Item: i, Err: err}
+		}
+		ret = append(ret, obj)
+	}
+	return ret, nil
+}
+
+// readStrict decodes known kinds strictly and falls back to Unstructured for kinds unknown to the scheme.
+func readStrict(content []byte) (runtime.Object, error) {
+	obj, _, err := strictGenericCodec.Decode(content, nil, nil)
+	if err == nil {
+		return obj, nil
+	}
+	if !runtime.IsNotRegisteredError(err) {
+		return nil, err
+	}
+	unstructuredObj := &unstructured.Unstructured{}
+	if err := unstructuredObj.UnmarshalJSON(content); err != nil {
+		return nil, err
+	}
+	return unstructuredObj, nil
+}
@@ -0,0 +1,161 @@
+package resourceread
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestReadGenericDocuments(t *testing.T) {
+	objs, err := ReadGenericDocuments([]byte(`# leading comment
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: first
+---
+# only a comment
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: second
+    namespace: first
+- apiVersion: monitoring.coreos.com/v1
+  kind: PrometheusRule
+  metadata:
+    name: third
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 3 {
+		t.Fatalf("expected 3 objects, got %d", len(objs))
+	}
+	if _, ok := objs[0].(*corev1.Namespace); !ok {
+		t.Errorf("expected namespace, got %T", objs[0])
+	}
+	if cm, ok := objs[1].(*corev1.ConfigMap); !ok || cm.Name != "second" {
+		t.Errorf("expected configmap second, got %#v", objs[1])
+	}
+	if u, ok := objs[2].(*unstructured.Unstructured); !ok || u.GetName() != "third" {
+		t.Errorf("expected unstructured third, got %#v", objs[2])
+	}
+}
+
+func TestReadGenericDocumentsStrict(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expectedIndex int
+		expectedItem  int
+		expectedLine  int
+		expectedError string
+	}{
+		{
+			name: "unknown field",
+			input: `apiVersion: v1
+kind: Namespace
+metadata:
+  name: first
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: second
+unknownField: true
+`,
+			expectedIndex: 1,
+			expectedItem:  -1,
+			expectedLine:  6,
+			expectedError: "unknownField",
+		},
+		{
+			name: "unknown field in list item",
+			input: `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: first
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: second
+  dta: {}
+`,
+			expectedIndex: 0,
+			expectedItem:  1,
+			expectedLine:  1,
+			expectedError: "dta",
+		},
+		{
+			name: "duplicate key",
+			input: `apiVersion: v1
+kind: Namespace
+metadata:
+  name: first
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: second
+data:
+  key: first
+  key: second
+`,
+			expectedIndex: 1,
+			expectedItem:  -1,
+			expectedLine:  6,
+			expectedError: "key",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ReadGenericDocuments([]byte(test.input))
+			var documentErr *DocumentError
+			if !errors.As(err, &documentErr) {
+				t.Fatalf("expected document error, got %v", err)
+			}
+			if documentErr.Index != test.expectedIndex || documentErr.Item != test.expectedItem || documentErr.Line != test.expectedLine {
+				t.Errorf("unexpected error position: %v", documentErr)
+			}
+			if !strings.Contains(err.Error(), test.expectedError) {
+				t.Errorf("expected error to mention %q, got %v", test.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestReadDocumentsAs(t *testing.T) {
+	configMaps, err := ReadDocumentsAs[*corev1.ConfigMap]([]byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configMaps) != 2 || configMaps[1].Name != "b" {
+		t.Errorf("unexpected configmaps: %v", configMaps)
+	}
+
+	if _, err := ReadDocumentsAs[*corev1.Secret]([]byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+`)); err == nil {
+		t.Errorf("expected type mismatch error")
+	}
+}
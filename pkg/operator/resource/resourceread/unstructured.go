@@ -10,9 +10,17 @@ func ReadCredentialRequestsOrDie(objBytes []byte) *unstructured.Unstructured {
 }
 
 func ReadUnstructuredOrDie(objBytes []byte) *unstructured.Unstructured {
-	udi, _, err := scheme.Codecs.UniversalDecoder().Decode(objBytes, nil, &unstructured.Unstructured{})
+	udi, err := ReadUnstructured(objBytes)
 	if err != nil {
 		panic(err)
 	}
-	return udi.(*unstructured.Unstructured)
+	return udi
+}
+
+func ReadUnstructured(objBytes []byte) (*unstructured.Unstructured, error) {
+	udi, _, err := scheme.Codecs.UniversalDecoder().Decode(objBytes, nil, &unstructured.Unstructured{})
+	if err != nil {
+		return nil, err
+	}
+	return udi.(*unstructured.Unstructured), nil
 }
@@ -0,0 +1,164 @@
+package retry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+)
+
+// Predicate returns true if the operation that returned the error should be retried.
+type Predicate func(err error) bool
+
+// IsTooManyRequests is a predicate matching 429 API errors.
+func IsTooManyRequests(err error) bool {
+	return errors.IsTooManyRequests(err)
+}
+
+// IsServerError is a predicate matching 5xx API errors and connection errors.
+func IsServerError(err error) bool {
+	if status, ok := err.(errors.APIStatus); ok {
+		return status.Status().Code >= 500
+	}
+	return utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err)
+}
+
+// IsConflict is a predicate matching 409 API errors. It is only useful if the retried operation reads the latest
+// version of the object it updates.
+func IsConflict(err error) bool {
+	return errors.IsConflict(err)
+}
+
+// Any returns a predicate matching errors matched by any of the given predicates.
+func Any(predicates ...Predicate) Predicate {
+	return func(err error) bool {
+		for _, predicate := range predicates {
+			if predicate(err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// IsRetriableAPIError matches the API errors that are worth retrying: 429, 5xx, connection errors and conflicts.
+var IsRetriableAPIError = Any(IsTooManyRequests, IsServerError, IsConflict)
+
+// DefaultPolicy retries retriable API errors up to five times within 30 seconds, with jitter.
+var DefaultPolicy = Policy{
+	Backoff: wait.Backoff{
+		Steps:    5,
+		Duration: 100 * time.Millisecond,
+		Factor:   2.0,
+		Jitter:   0.2,
+		Cap:      10 * time.Second,
+	},
+	MaxElapsed: 30 * time.Second,
+	Retriable:  IsRetriableAPIError,
+}
+
+// Policy describes how an operation is retried.
+type Policy struct {
+	// Backoff is the delay between the attempts. Steps is the maximum number of attempts, Jitter adds a random
+	// amount to every delay and Cap limits it.
+	Backoff wait.Backoff
+	// MaxElapsed is the maximum time spent retrying. No retry is started if its delay would exceed it. Zero means
+	// no limit.
+	MaxElapsed time.Duration
+	// Retriable decides which errors are retried. Nil means IsRetriableAPIError.
+	Retriable Predicate
+	// Budget optionally limits the number of retries shared by all users of the budget.
+	Budget *Budget
+
+	clock clock.Clock
+}
+
+// WithBudget returns a copy of the policy sharing the given retry budget.
+func (p Policy) WithBudget(budget *Budget) Policy {
+	p.Budget = budget
+	return p
+}
+
+// Do runs the operation until it succeeds, returns an error that is not retriable, or the attempts, the maximum
+// elapsed time, the retry budget or the context are exhausted. In all cases the last error of the operation is
+// returned. The delay suggested by the server, e.g. in a 429 Retry-After header, is honoured if it is longer than
+// the backoff.
+func (p Policy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	retriable := p.Retriable
+	if retriable == nil {
+		retriable = IsRetriableAPIError
+	}
+	clk := p.clock
+	if clk == nil {
+		clk = clock.RealClock{}
+	}
+	start := clk.Now()
+	// Step() decrements the steps of the copy, the original value is the maximum number of attempts
+	backoff := p.Backoff
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || !retriable(err) || attempt >= p.Backoff.Steps {
+			return err
+		}
+
+		delay := backoff.Step()
+		if seconds, ok := errors.SuggestsClientDelay(err); ok && time.Duration(seconds)*time.Second > delay {
+			delay = time.Duration(seconds) * time.Second
+		}
+		if p.MaxElapsed > 0 && clk.Since(start)+delay > p.MaxElapsed {
+			return err
+		}
+		if p.Budget != nil && !p.Budget.take() {
+			klog.V(4).Infof("Retry budget exhausted, not retrying: %v", err)
+			return err
+		}
+
+		klog.V(4).Infof("Retrying after %v (attempt %d): %v", delay, attempt, err)
+		timer := clk.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C():
+		}
+	}
+}
+
+// Budget limits the number of retries within a time window. It is meant to be shared by all operations of a
+// controller, so a failing API server is not hammered by retries of every single request.
+type Budget struct {
+	lock        sync.Mutex
+	max         int
+	window      time.Duration
+	windowStart time.Time
+	used        int
+
+	clock clock.PassiveClock
+}
+
+// NewBudget returns a budget allowing maxRetries retries per window.
+func NewBudget(maxRetries int, window time.Duration) *Budget {
+	return &Budget{max: maxRetries, window: window, clock: clock.RealClock{}}
+}
+
+// take consumes one retry and returns false if the budget of the current window is exhausted.
+func (b *Budget) take() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if now := b.clock.Now(); now.Sub(b.windowStart) >= b.window {
+		b.windowStart = now
+		b.used = 0
+	}
+	if b.used >= b.max {
+		return false
+	}
+	b.used++
+	return true
+}
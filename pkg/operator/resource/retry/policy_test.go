@@ -0,0 +1,139 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestPredicates(t *testing.T) {
+	gr := schema.GroupResource{Resource: "pods"}
+	tests := []struct {
+		err       error
+		retriable bool
+	}{
+		{err: errors.NewTooManyRequests("slow down", 1), retriable: true},
+		{err: errors.NewInternalError(fmt.Errorf("internal error")), retriable: true},
+		{err: errors.NewServiceUnavailable("unavailable"), retriable: true},
+		{err: errors.NewConflict(gr, "test-pod", fmt.Errorf("conflict")), retriable: true},
+		{err: syscall.ECONNREFUSED, retriable: true},
+		{err: errors.NewNotFound(gr, "test-pod"), retriable: false},
+		{err: errors.NewForbidden(gr, "test-pod", fmt.Errorf("forbidden")), retriable: false},
+		{err: fmt.Errorf("random error"), retriable: false},
+	}
+	for _, test := range tests {
+		if retriable := IsRetriableAPIError(test.err); retriable != test.retriable {
+			t.Errorf("expected %v to be retriable=%v", test.err, test.retriable)
+		}
+	}
+}
+
+func TestPolicyDo(t *testing.T) {
+	backoff := wait.Backoff{Steps: 4, Duration: time.Millisecond, Factor: 2, Jitter: 0.5}
+	internalError := errors.NewInternalError(fmt.Errorf("internal error"))
+	notFoundError := errors.NewNotFound(schema.GroupResource{Resource: "pods"}, "test-pod")
+
+	tests := []struct {
+		name             string
+		policy           Policy
+		errors           []error
+		expectedAttempts int
+		expectedError    error
+	}{
+		{
+			name:             "succeeds after retries",
+			policy:           Policy{Backoff: backoff},
+			errors:           []error{internalError, internalError, nil},
+			expectedAttempts: 3,
+		},
+		{
+			name:             "does not retry non-retriable errors",
+			policy:           Policy{Backoff: backoff},
+			errors:           []error{notFoundError},
+			expectedAttempts: 1,
+			expectedError:    notFoundError,
+		},
+		{
+			name:             "gives up after the attempts",
+			policy:           Policy{Backoff: backoff},
+			errors:           []error{internalError, internalError, internalError, internalError, internalError},
+			expectedAttempts: 4,
+			expectedError:    internalError,
+		},
+		{
+			name:             "gives up after the max elapsed time",
+			policy:           Policy{Backoff: wait.Backoff{Steps: 4, Duration: time.Hour}, MaxElapsed: time.Minute},
+			errors:           []error{internalError, nil},
+			expectedAttempts: 1,
+			expectedError:    internalError,
+		},
+		{
+			name:             "gives up when the budget is exhausted",
+			policy:           Policy{Backoff: backoff}.WithBudget(NewBudget(1, time.Hour)),
+			errors:           []error{internalError, internalError, nil},
+			expectedAttempts: 2,
+			expectedError:    internalError,
+		},
+		{
+			name:             "custom predicate",
+			policy:           Policy{Backoff: backoff, Retriable: errors.IsNotFound},
+			errors:           []error{notFoundError, nil},
+			expectedAttempts: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attempts := 0
+			err := test.policy.Do(context.TODO(), func(ctx context.Context) error {
+				err := test.errors[attempts]
+				attempts++
+				return err
+			})
+			if err != test.expectedError {
+				t.Errorf("expected error %v, got %v", test.expectedError, err)
+			}
+			if attempts != test.expectedAttempts {
+				t.Errorf("expected %d attempts, got %d", test.expectedAttempts, attempts)
+			}
+		})
+	}
+}
+
+func TestPolicyDoContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	internalError := errors.NewInternalError(fmt.Errorf("internal error"))
+	attempts := 0
+	err := Policy{Backoff: wait.Backoff{Steps: 10, Duration: time.Hour}}.Do(ctx, func(ctx context.Context) error {
+		attempts++
+		cancel()
+		return internalError
+	})
+	if err != internalError || attempts != 1 {
+		t.Errorf("expected to stop after the context is cancelled, got %d attempts: %v", attempts, err)
+	}
+}
+
+func TestBudgetWindow(t *testing.T) {
+	fakeClock := clocktesting.NewFakePassiveClock(time.Now())
+	budget := NewBudget(2, time.Minute)
+	budget.clock = fakeClock
+
+	if !budget.take() || !budget.take() {
+		t.Fatal("expected two retries within the budget")
+	}
+	if budget.take() {
+		t.Fatal("expected the budget to be exhausted")
+	}
+	fakeClock.SetTime(fakeClock.Now().Add(time.Minute))
+	if !budget.take() {
+		t.Fatal("expected the budget to be refilled in the next window")
+	}
+}
@@ -0,0 +1,88 @@
+package revisioncontroller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DryRunError reports an object of a revision that was rejected by the server-side dry-run, e.g. by admission.
+type DryRunError struct {
+	Resource  string
+	Namespace string
+	Name      string
+	Err       error
+}
+
+func (e *DryRunError) Error() string {
+	return fmt.Sprintf("dry-run of %s %s/%s failed: %v", e.Resource, e.Namespace, e.Name, e.Err)
+}
+
+func (e *DryRunError) Unwrap() error {
+	return e.Err
+}
+
+// dryRunRevision creates all objects of the revision with a server-side dry-run, so that a revision is only written
+// when the server accepts all of its objects. Missing optional sources are skipped, missing required ones are reported
+// when the revision is written.
+func (c RevisionController) dryRunRevision(ctx context.Context, revision int32, statusConfigMap *corev1.ConfigMap) error {
+	dryRunCreate := metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}}
+	dryRunUpdate := metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}}
+	labels := map[string]string{"operator.openshift.io/controller-instance-name": c.controllerInstanceName}
+
+	if _, err := c.configMapGetter.ConfigMaps(c.targetNamespace).Create(ctx, statusConfigMap, dryRunCreate); err != nil && !apierrors.IsAlreadyExists(err) {
+		return &DryRunError{Resource: "configmaps", Namespace: statusConfigMap.Namespace, Name: statusConfigMap.Name, Err: err}
+	}
+
+	for _, cm := range c.configMaps {
+		configMaps, err := c.revisionConfigMaps(ctx, cm.Name, revision, nil)
+		if err != nil {
+			return err
+		}
+		for _, required := range configMaps {
+			_, err = c.configMapGetter.ConfigMaps(c.targetNamespace).Create(ctx, required, dryRunCreate)
+			if apierrors.IsAlreadyExists(err) {
+				// left over from a previous attempt to write the revision, it will be updated
+				var existing *corev1.ConfigMap
+				if existing, err = c.configMapGetter.ConfigMaps(c.targetNamespace).Get(ctx, required.Name, metav1.GetOptions{}); err == nil {
+					required.ResourceVersion = existing.ResourceVersion
+					_, err = c.configMapGetter.ConfigMaps(c.targetNamespace).Update(ctx, required, dryRunUpdate)
+				}
+			}
+			if err != nil {
+				return &DryRunError{Resource: "configmaps", Namespace: required.Namespace, Name: required.Name, Err: err}
+			}
+		}
+	}
+
+	for _, s := range c.secrets {
+		source, err := c.secretGetter.Secrets(c.targetNamespace).Get(ctx, s.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		required := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: c.targetNamespace, Name: nameFor(s.Name, revision), Labels: labels},
+			Type:       source.Type,
+			Data:       source.Data,
+		}
+		_, err = c.secretGetter.Secrets(c.targetNamespace).Create(ctx, required, dryRunCreate)
+		if apierrors.IsAlreadyExists(err) {
+			var existing *corev1.Secret
+			if existing, err = c.secretGetter.Secrets(c.targetNamespace).Get(ctx, required.Name, metav1.GetOptions{}); err == nil {
+				required.ResourceVersion = existing.ResourceVersion
+				_, err = c.secretGetter.Secrets(c.targetNamespace).Update(ctx, required, dryRunUpdate)
+			}
+		}
+		if err != nil {
+			return &DryRunError{Resource: "secrets", Namespace: required.Namespace, Name: required.Name, Err: err}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+// Package payload packs the data of configmaps too large for a single configmap, e.g. CA bundles and audit policies,
+// into a gzip compressed configmap followed by chunk configmaps, and unpacks them again. The revision controller packs
+// the large configmaps it copies into a revision, and the installer pod unpacks them, so revisions can carry payloads
+// of several MB.
+//
+// A packed configmap keeps its name. Its data is gzip compressed JSON, split into pieces of ChunkSize bytes: the first
+// piece is stored in the packed configmap, the following ones in the configmaps ChunkName(name, 1), ChunkName(name, 2)
+// and so on.
+package payload
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// EncodingAnnotation is set to "gzip" on packed configmaps.
+	EncodingAnnotation = "operator.openshift.io/payload-encoding"
+	// ChunksAnnotation is the number of chunk configmaps following a packed configmap.
+	ChunksAnnotation = "operator.openshift.io/payload-chunks"
+	// ChecksumAnnotation is the SHA-256 of the compressed payload, so chunks of different payloads are not mixed.
+	ChecksumAnnotation = "operator.openshift.io/payload-sha256"
+
+	gzipEncoding = "gzip"
+	payloadKey   = "payload.gz"
+)
+
+// ChunkSize is the maximum size of the piece of compressed payload stored in a configmap, leaving room for the
+// metadata below the size limit of configmaps.
+var ChunkSize = 900 * 1024
+
+// ChunkName returns the name of the i-th chunk of the packed configmap.
+func ChunkName(name string, i int) string {
+	return fmt.Sprintf("%s-chunk-%d", name, i)
+}
+
+// IsPacked returns true if the configmap was packed by Pack.
+func IsPacked(configMap *corev1.ConfigMap) bool {
+	return configMap.Annotations[EncodingAnnotation] == gzipEncoding
+}
+
+// Pack returns the packed configmap with the name, namespace, labels and annotations of the configmap, followed by its
+// chunks. The chunks have the same namespace, labels and owner references. The binary data of the configmap is not
+// supported.
+func Pack(configMap *corev1.ConfigMap) ([]*corev1.ConfigMap, error) {
+	if len(configMap.BinaryData) > 0 {
+		return nil, fmt.Errorf("configmap %s/%s has binary data, which cannot be packed", configMap.Namespace, configMap.Name)
+	}
+	data, err := json.Marshal(configMap.Data)
+	if err != nil {
+		return nil, err
+	}
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	payload := compressed.Bytes()
+	checksum := sha256.Sum256(payload)
+
+	var pieces [][]byte
+	for len(payload) > ChunkSize {
+		pieces = append(pieces, payload[:ChunkSize])
+		payload = payload[ChunkSize:]
+	}
+	pieces = append(pieces, payload)
+
+	packed := &corev1.ConfigMap{ObjectMeta: *configMap.ObjectMeta.DeepCopy()}
+	if packed.Annotations == nil {
+		packed.Annotations = map[string]string{}
+	}
+	packed.Annotations[EncodingAnnotation] = gzipEncoding
+	packed.Annotations[ChunksAnnotation] = strconv.Itoa(len(pieces) - 1)
+	packed.Annotations[ChecksumAnnotation] = hex.EncodeToString(checksum[:])
+	packed.BinaryData = map[string][]byte{payloadKey: pieces[0]}
+
+	ret := []*corev1.ConfigMap{packed}
+	for i, piece := range pieces[1:] {
+		chunk := &corev1.ConfigMap{}
+		chunk.Namespace = configMap.Namespace
+		chunk.Name = ChunkName(configMap.Name, i+1)
+		chunk.Labels = configMap.Labels
+		chunk.OwnerReferences = configMap.OwnerReferences
+		chunk.Annotations = map[string]string{ChecksumAnnotation: packed.Annotations[ChecksumAnnotation]}
+		chunk.BinaryData = map[string][]byte{payloadKey: piece}
+		ret = append(ret, chunk.DeepCopy())
+	}
+	return ret, nil
+}
+
+// Unpack returns a copy of the packed configmap with its original data, getting its chunks with getChunk. Configmaps
+// which are not packed are returned as they are.
+func Unpack(configMap *corev1.ConfigMap, getChunk func(name string) (*corev1.ConfigMap, error)) (*corev1.ConfigMap, error) {
+	if !IsPacked(configMap) {
+		return configMap, nil
+	}
+	chunks, err := strconv.Atoi(configMap.Annotations[ChunksAnnotation])
+	if err != nil || chunks < 0 {
+		return nil, fmt.Errorf("configmap %s/%s has an invalid %s annotation %q", configMap.Namespace, configMap.Name, ChunksAnnotation, configMap.Annotations[ChunksAnnotation])
+	}
+	checksum := configMap.Annotations[ChecksumAnnotation]
+
+	payload := append([]byte{}, configMap.BinaryData[payloadKey]...)
+	for i := 1; i <= chunks; i++ {
+		chunk, err := getChunk(ChunkName(configMap.Name, i))
+		if err != nil {
+			return nil, fmt.Errorf("unable to get chunk %d of configmap %s/%s: %w", i, configMap.Namespace, configMap.Name, err)
+		}
+		if chunk.Annotations[ChecksumAnnotation] != checksum {
+			return nil, fmt.Errorf("chunk %d of configmap %s/%s belongs to another payload", i, configMap.Namespace, configMap.Name)
+		}
+		payload = append(payload, chunk.BinaryData[payloadKey]...)
+	}
+	if actual := sha256.Sum256(payload); hex.EncodeToString(actual[:]) != checksum {
+		return nil, fmt.Errorf("the payload of configmap %s/%s does not match its checksum", configMap.Namespace, configMap.Name)
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress configmap %s/%s: %w", configMap.Namespace, configMap.Name, err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress configmap %s/%s: %w", configMap.Namespace, configMap.Name, err)
+	}
+	ret := configMap.DeepCopy()
+	ret.BinaryData = nil
+	ret.Data = map[string]string{}
+	if err := json.Unmarshal(data, &ret.Data); err != nil {
+		return nil, fmt.Errorf("unable to decode configmap %s/%s: %w", configMap.Namespace, configMap.Name, err)
+	}
+	delete(ret.Annotations, EncodingAnnotation)
+	delete(ret.Annotations, ChunksAnnotation)
+	delete(ret.Annotations, ChecksumAnnotation)
+	return ret, nil
+}
@@ -0,0 +1,109 @@
+package payload
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPackUnpack(t *testing.T) {
+	defer func(chunkSize int) { ChunkSize = chunkSize }(ChunkSize)
+	ChunkSize = 1024
+
+	// random data does not compress, so it is split into several chunks
+	random := make([]byte, 2500)
+	if _, err := rand.Read(random); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name          string
+		data          map[string]string
+		expectChunked bool
+	}{
+		{name: "compressible", data: map[string]string{"policy.yaml": strings.Repeat("rules: []\n", 10000)}},
+		{name: "chunked", data: map[string]string{"ca-bundle.crt": hex.EncodeToString(random), "other": "value"}, expectChunked: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:       "ns",
+					Name:            "config-3",
+					Labels:          map[string]string{"app": "foo"},
+					Annotations:     map[string]string{"foo": "bar"},
+					OwnerReferences: []metav1.OwnerReference{{Kind: "ConfigMap", Name: "revision-status-3"}},
+				},
+				Data: tt.data,
+			}
+			packed, err := Pack(configMap)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if chunked := len(packed) > 1; chunked != tt.expectChunked {
+				t.Fatalf("expected chunked %v, got %d chunks", tt.expectChunked, len(packed)-1)
+			}
+			objects := map[string]*corev1.ConfigMap{}
+			for i, cm := range packed {
+				if len(cm.Data) > 0 || len(cm.BinaryData[payloadKey]) > ChunkSize {
+					t.Errorf("unexpected data in %s", cm.Name)
+				}
+				if i > 0 && (cm.Name != ChunkName("config-3", i) || len(cm.OwnerReferences) != 1 || cm.Labels["app"] != "foo") {
+					t.Errorf("unexpected chunk metadata %v", cm.ObjectMeta)
+				}
+				objects[cm.Name] = cm
+			}
+			getChunk := func(name string) (*corev1.ConfigMap, error) {
+				if cm, ok := objects[name]; ok {
+					return cm, nil
+				}
+				return nil, apierrors.NewNotFound(corev1.Resource("configmaps"), name)
+			}
+
+			unpacked, err := Unpack(packed[0], getChunk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(configMap, unpacked); diff != "" {
+				t.Errorf("unexpected unpacked configmap (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnpackErrors(t *testing.T) {
+	defer func(chunkSize int) { ChunkSize = chunkSize }(ChunkSize)
+	ChunkSize = 512
+
+	pack := func(value string) []*corev1.ConfigMap {
+		random := make([]byte, 1000)
+		if _, err := rand.Read(random); err != nil {
+			t.Fatal(err)
+		}
+		packed, err := Pack(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config"}, Data: map[string]string{value: hex.EncodeToString(random)}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return packed
+	}
+	current, previous := pack("current"), pack("previous")
+
+	if _, err := Unpack(current[0], func(string) (*corev1.ConfigMap, error) { return previous[1], nil }); err == nil || !strings.Contains(err.Error(), "belongs to another payload") {
+		t.Errorf("expected the chunk of another payload to be rejected, got %v", err)
+	}
+	if _, err := Unpack(current[0], func(name string) (*corev1.ConfigMap, error) {
+		return nil, apierrors.NewNotFound(corev1.Resource("configmaps"), name)
+	}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected a missing chunk to be reported, got %v", err)
+	}
+
+	plain := &corev1.ConfigMap{Data: map[string]string{"a": "b"}}
+	if unpacked, err := Unpack(plain, nil); err != nil || unpacked != plain {
+		t.Errorf("expected a configmap which is not packed to be returned as is, got %v, %v", unpacked, err)
+	}
+}
@@ -2,6 +2,7 @@ package revisioncontroller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -13,7 +14,9 @@ import (
 	"github.com/openshift/library-go/pkg/operator/condition"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/management"
+	"github.com/openshift/library-go/pkg/operator/objectbudget"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/revisioncontroller/payload"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -42,6 +45,31 @@ type RevisionController struct {
 	secretGetter    corev1client.SecretsGetter
 
 	revisionPrecondition PreconditionFunc
+	dryRun               bool
+	// packThreshold is the size of the data above which the configmaps of a revision are packed, 0 disables packing.
+	packThreshold int
+}
+
+// Option configures optional behaviour of the revision controller.
+type Option func(*RevisionController)
+
+// WithDryRunValidation makes the controller create all objects of a new revision with a server-side dry-run before
+// writing any of them. When an object is rejected, e.g. by admission, no revision is written and the rejected object is
+// reported in the degraded condition, instead of leaving a partially written revision behind.
+func WithDryRunValidation() Option {
+	return func(c *RevisionController) {
+		c.dryRun = true
+	}
+}
+
+// WithPayloadPacking makes the controller pack the configmaps of a revision whose data is larger than threshold bytes
+// into compressed chunks, see the payload package, so revisions can carry payloads larger than a configmap. The
+// configmaps copied into a revision may be packed too, e.g. when an operator writes a large CA bundle. The installer
+// pods unpack the configmaps of the revision.
+func WithPayloadPacking(threshold int) Option {
+	return func(c *RevisionController) {
+		c.packThreshold = threshold
+	}
 }
 
 type RevisionResource struct {
@@ -64,6 +92,7 @@ func NewRevisionController(
 	secretGetter corev1client.SecretsGetter,
 	eventRecorder events.Recorder,
 	revisionPrecondition PreconditionFunc,
+	options ...Option,
 ) factory.Controller {
 	if revisionPrecondition == nil {
 		revisionPrecondition = func(ctx context.Context) (bool, error) {
@@ -82,6 +111,9 @@ func NewRevisionController(
 		secretGetter:         secretGetter,
 		revisionPrecondition: revisionPrecondition,
 	}
+	for _, option := range options {
+		option(c)
+	}
 
 	return factory.New().
 		WithInformers(
@@ -104,7 +136,7 @@ func (c RevisionController) createRevisionIfNeeded(ctx context.Context, recorder
 
 	// check to make sure that the latestRevision has the exact content we expect.  No mutation here, so we start creating the next Revision only when it is required
 	if isLatestRevisionCurrent {
-		klog.V(4).Infof("Returning early, %d triggered and up to date", currentLastAvailableRevision)
+		klog.FromContext(ctx).V(4).Info("Returning early, latest revision is up to date", "revision", currentLastAvailableRevision)
 		return false, false, nil
 	}
 
@@ -119,11 +151,16 @@ func (c RevisionController) createRevisionIfNeeded(ctx context.Context, recorder
 	}
 
 	if err != nil {
+		degradedReason := "ContentCreationError"
+		var dryRunErr *DryRunError
+		if errors.As(err, &dryRunErr) {
+			degradedReason = "DryRunValidationFailed"
+		}
 		status := applyoperatorv1.OperatorStatus().
 			WithConditions(applyoperatorv1.OperatorCondition().
 				WithType(condition.RevisionControllerDegradedConditionType).
 				WithStatus(operatorv1.ConditionTrue).
-				WithReason("ContentCreationError").
+				WithReason(degradedReason).
 				WithMessage(err.Error()),
 			).
 			WithLatestAvailableRevision(currentLastAvailableRevision)
@@ -136,7 +173,7 @@ func (c RevisionController) createRevisionIfNeeded(ctx context.Context, recorder
 	}
 
 	if !createdNewRevision {
-		klog.V(4).Infof("Revision %v not created", nextRevision)
+		klog.FromContext(ctx).V(4).Info("Revision not created", "revision", nextRevision)
 		return false, false, nil
 	}
 
@@ -167,11 +204,11 @@ func (c RevisionController) isLatestRevisionCurrent(ctx context.Context, revisio
 		requiredData := map[string]string{}
 		existingData := map[string]string{}
 
-		required, err := c.configMapGetter.ConfigMaps(c.targetNamespace).Get(ctx, cm.Name, metav1.GetOptions{})
+		required, err := c.getConfigMap(ctx, cm.Name)
 		if apierrors.IsNotFound(err) && !cm.Optional {
 			return false, true, err.Error()
 		}
-		existing, err := c.configMapGetter.ConfigMaps(c.targetNamespace).Get(ctx, nameFor(cm.Name, revision), metav1.GetOptions{})
+		existing, err := c.getConfigMap(ctx, nameFor(cm.Name, revision))
 		if apierrors.IsNotFound(err) && !cm.Optional {
 			return false, false, err.Error()
 		}
@@ -182,8 +219,8 @@ func (c RevisionController) isLatestRevisionCurrent(ctx context.Context, revisio
 			existingData = existing.Data
 		}
 		if !equality.Semantic.DeepEqual(existingData, requiredData) {
-			if klog.V(4).Enabled() {
-				klog.Infof("configmap %q changes for revision %d: %s", cm.Name, revision, resourceapply.JSONPatchNoError(existing, required))
+			if logger := klog.FromContext(ctx).V(4); logger.Enabled() {
+				logger.Info("Configmap changes for revision", "configmap", cm.Name, "revision", revision, "patch", resourceapply.JSONPatchNoError(existing, required))
 			}
 			// "configmap/foo has changed" when there is actual change in data
 			// "configmap/foo has been created" when the existing configmap was empty (iow. the configmap is optional)
@@ -219,8 +256,8 @@ func (c RevisionController) isLatestRevisionCurrent(ctx context.Context, revisio
 			existingData = existing.Data
 		}
 		if !equality.Semantic.DeepEqual(existingData, requiredData) {
-			if klog.V(4).Enabled() {
-				klog.Infof("Secret %q changes for revision %d: %s", s.Name, revision, resourceapply.JSONPatchSecretNoError(existing, required))
+			if logger := klog.FromContext(ctx).V(4); logger.Enabled() {
+				logger.Info("Secret changes for revision", "secret", s.Name, "revision", revision, "patch", resourceapply.JSONPatchSecretNoError(existing, required))
 			}
 			// "configmap/foo has changed" when there is actual change in data
 			// "configmap/foo has been created" when the existing configmap was empty (iow. the configmap is optional)
@@ -245,6 +282,10 @@ func (c RevisionController) isLatestRevisionCurrent(ctx context.Context, revisio
 
 // returns true if we created a revision
 func (c RevisionController) createNewRevision(ctx context.Context, recorder events.Recorder, revision int32, reason string) (bool, error) {
+	if err := c.checkPayloadBudget(ctx, revision); err != nil {
+		return false, err
+	}
+
 	// Create a new InProgress status configmap
 	desiredStatusConfigMap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -262,6 +303,11 @@ func (c RevisionController) createNewRevision(ctx context.Context, recorder even
 			"reason":   reason,
 		},
 	}
+	if c.dryRun {
+		if err := c.dryRunRevision(ctx, revision, desiredStatusConfigMap); err != nil {
+			return false, err
+		}
+	}
 	createdStatus, err := c.configMapGetter.ConfigMaps(desiredStatusConfigMap.Namespace).Create(ctx, desiredStatusConfigMap, metav1.CreateOptions{})
 	switch {
 	case apierrors.IsAlreadyExists(err):
@@ -272,7 +318,7 @@ func (c RevisionController) createNewRevision(ctx context.Context, recorder even
 		}
 		if createdStatus.Annotations["operator.openshift.io/revision-ready"] == "true" {
 			// no work to do because our cache is out of date and when we're updated, we will be able to see the result
-			klog.Infof("down the branch indicating that our cache was out of date and we're trying to recreate a revision.")
+			klog.FromContext(ctx).Info("Revision is already ready, our cache was out of date and we're trying to recreate a revision", "revision", revision)
 			return false, nil
 		}
 		// update the sync and continue
@@ -288,6 +334,21 @@ func (c RevisionController) createNewRevision(ctx context.Context, recorder even
 	}}
 
 	for _, cm := range c.configMaps {
+		if c.packThreshold > 0 {
+			configMaps, err := c.revisionConfigMaps(ctx, cm.Name, revision, ownerRefs)
+			if err != nil {
+				return false, err
+			}
+			if len(configMaps) == 0 && !cm.Optional {
+				return false, apierrors.NewNotFound(corev1.Resource("configmaps"), cm.Name)
+			}
+			for _, configMap := range configMaps {
+				if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapGetter, recorder, configMap); err != nil {
+					return false, err
+				}
+			}
+			continue
+		}
 		obj, _, err := resourceapply.SyncConfigMapWithLabels(
 			ctx,
 			c.configMapGetter,
@@ -341,6 +402,69 @@ func (c RevisionController) createNewRevision(ctx context.Context, recorder even
 	return true, nil
 }
 
+// checkPayloadBudget fails before anything of the revision is written when the copied configmaps and secrets exceed the
+// revision payload budget together.
+func (c RevisionController) checkPayloadBudget(ctx context.Context, revision int32) error {
+	var configMaps []*corev1.ConfigMap
+	for _, cm := range c.configMaps {
+		objs, err := c.revisionConfigMaps(ctx, cm.Name, revision, nil)
+		if err != nil {
+			return err
+		}
+		configMaps = append(configMaps, objs...)
+	}
+	var secrets []*corev1.Secret
+	for _, s := range c.secrets {
+		obj, err := c.secretGetter.Secrets(c.targetNamespace).Get(ctx, s.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		secrets = append(secrets, obj)
+	}
+	return objectbudget.CheckRevisionPayload(c.targetNamespace, nameFor("revision", revision), configMaps, secrets)
+}
+
+// getConfigMap returns the configmap, unpacked when it is packed.
+func (c RevisionController) getConfigMap(ctx context.Context, name string) (*corev1.ConfigMap, error) {
+	configMap, err := c.configMapGetter.ConfigMaps(c.targetNamespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return payload.Unpack(configMap, func(chunkName string) (*corev1.ConfigMap, error) {
+		return c.configMapGetter.ConfigMaps(c.targetNamespace).Get(ctx, chunkName, metav1.GetOptions{})
+	})
+}
+
+// revisionConfigMaps returns the configmaps copying the configmap into the revision: the copy, or the packed copy and
+// its chunks when packing is enabled and the copy is larger than the threshold. It returns none when the configmap does
+// not exist.
+func (c RevisionController) revisionConfigMaps(ctx context.Context, name string, revision int32, ownerRefs []metav1.OwnerReference) ([]*corev1.ConfigMap, error) {
+	source, err := c.getConfigMap(ctx, name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	required := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       c.targetNamespace,
+			Name:            nameFor(name, revision),
+			Labels:          map[string]string{"operator.openshift.io/controller-instance-name": c.controllerInstanceName},
+			OwnerReferences: ownerRefs,
+		},
+		Data:       source.Data,
+		BinaryData: source.BinaryData,
+	}
+	if c.packThreshold > 0 && objectbudget.ConfigMapSize(required) > c.packThreshold {
+		return payload.Pack(required)
+	}
+	return []*corev1.ConfigMap{required}, nil
+}
+
 // getLatestAvailableRevision returns the latest known revision to the operator
 // This is determined by checking revision status configmaps.
 func (c RevisionController) getLatestAvailableRevision(ctx context.Context) (int32, error) {
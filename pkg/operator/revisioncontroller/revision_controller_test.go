@@ -2,6 +2,8 @@ package revisioncontroller
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"reflect"
 	"strings"
@@ -9,6 +11,7 @@ import (
 	"time"
 
 	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/revisioncontroller/payload"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 	"github.com/stretchr/testify/require"
 
@@ -834,3 +837,155 @@ func TestSyncWithRevisionPrecondition(t *testing.T) {
 		})
 	}
 }
+
+func TestSyncWithDryRunValidation(t *testing.T) {
+	tests := []struct {
+		testName                          string
+		rejectedResource                  string
+		expDegradedReason                 string
+		expUpdatedLatestAvailableRevision int32
+	}{
+		{
+			testName:                          "all objects pass the dry-run",
+			expUpdatedLatestAvailableRevision: 2,
+		},
+		{
+			testName:                          "secret is rejected by the dry-run",
+			rejectedResource:                  "secrets",
+			expDegradedReason:                 "DryRunValidationFailed",
+			expUpdatedLatestAvailableRevision: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.testName, func(t *testing.T) {
+			kubeClient := fake.NewSimpleClientset(
+				&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "test-secret", Namespace: targetNamespace}},
+				&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: targetNamespace}},
+				&v1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "revision-status-1", Namespace: targetNamespace},
+					Data:       map[string]string{"revision": "1"},
+				},
+			)
+			var dryRuns int
+			kubeClient.PrependReactor("create", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				createAction := action.(clienttesting.CreateActionImpl)
+				if len(createAction.CreateOptions.DryRun) == 0 {
+					return false, nil, nil
+				}
+				dryRuns++
+				if action.GetResource().Resource == tc.rejectedResource {
+					return true, nil, fmt.Errorf("denied by admission webhook")
+				}
+				// the server does not persist dry-run objects
+				return true, createAction.GetObject(), nil
+			})
+			eventRecorder := events.NewRecorder(kubeClient.CoreV1().Events("test"), "test-operator", &v1.ObjectReference{})
+			staticPodOperatorClient := v1helpers.NewFakeStaticPodOperatorClient(
+				&operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{ManagementState: operatorv1.Managed}},
+				&operatorv1.StaticPodOperatorStatus{OperatorStatus: operatorv1.OperatorStatus{LatestAvailableRevision: 1}},
+				nil,
+				nil,
+			)
+
+			c := NewRevisionController(
+				"testing",
+				targetNamespace,
+				[]RevisionResource{{Name: "test-config"}},
+				[]RevisionResource{{Name: "test-secret"}},
+				informers.NewSharedInformerFactoryWithOptions(kubeClient, 1*time.Minute, informers.WithNamespace(targetNamespace)),
+				staticPodOperatorClient,
+				kubeClient.CoreV1(),
+				kubeClient.CoreV1(),
+				eventRecorder,
+				nil,
+				WithDryRunValidation(),
+			)
+			syncErr := c.Sync(context.TODO(), factory.NewSyncContext("RevisionController", eventRecorder))
+			if syncErr != nil && syncErr != factory.SyntheticRequeueError {
+				t.Fatal(syncErr)
+			}
+			if dryRuns == 0 {
+				t.Errorf("expected dry-run creates")
+			}
+
+			_, status, _, _ := staticPodOperatorClient.GetStaticPodOperatorState()
+			require.Equal(t, tc.expUpdatedLatestAvailableRevision, status.LatestAvailableRevision)
+			degraded := v1helpers.FindOperatorCondition(status.Conditions, "RevisionControllerDegraded")
+			if len(tc.expDegradedReason) > 0 {
+				require.NotNil(t, degraded)
+				require.Equal(t, tc.expDegradedReason, degraded.Reason)
+				require.Contains(t, degraded.Message, "secrets copy-resources/test-secret-2")
+			}
+
+			_, err := kubeClient.CoreV1().ConfigMaps(targetNamespace).Get(context.TODO(), "revision-status-2", metav1.GetOptions{})
+			if created := err == nil; created != (len(tc.rejectedResource) == 0) {
+				t.Errorf("unexpected revision-status-2 created=%v", created)
+			}
+		})
+	}
+}
+
+func TestSyncWithPayloadPacking(t *testing.T) {
+	defer func(chunkSize int) { payload.ChunkSize = chunkSize }(payload.ChunkSize)
+	payload.ChunkSize = 1024
+
+	// random data does not compress, so it is split into several chunks
+	random := make([]byte, 3000)
+	if _, err := rand.Read(random); err != nil {
+		t.Fatal(err)
+	}
+	largeData := map[string]string{"ca-bundle.crt": hex.EncodeToString(random)}
+	kubeClient := fake.NewSimpleClientset(
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: targetNamespace}, Data: map[string]string{"pod.yaml": "{}"}},
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: targetNamespace}, Data: largeData},
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "revision-status-1", Namespace: targetNamespace},
+			Data:       map[string]string{"revision": "1"},
+		},
+	)
+	eventRecorder := events.NewRecorder(kubeClient.CoreV1().Events("test"), "test-operator", &v1.ObjectReference{})
+	staticPodOperatorClient := v1helpers.NewFakeStaticPodOperatorClient(
+		&operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{ManagementState: operatorv1.Managed}},
+		&operatorv1.StaticPodOperatorStatus{OperatorStatus: operatorv1.OperatorStatus{LatestAvailableRevision: 1}},
+		nil,
+		nil,
+	)
+	c := NewRevisionController(
+		"testing",
+		targetNamespace,
+		[]RevisionResource{{Name: "test-pod"}, {Name: "test-config"}},
+		nil,
+		informers.NewSharedInformerFactoryWithOptions(kubeClient, 1*time.Minute, informers.WithNamespace(targetNamespace)),
+		staticPodOperatorClient,
+		kubeClient.CoreV1(),
+		kubeClient.CoreV1(),
+		eventRecorder,
+		nil,
+		WithPayloadPacking(2048),
+		WithDryRunValidation(),
+	)
+	for i := 0; i < 2; i++ {
+		// the second sync finds the packed revision up to date
+		if err := c.Sync(context.TODO(), factory.NewSyncContext("RevisionController", eventRecorder)); err != nil && err != factory.SyntheticRequeueError {
+			t.Fatal(err)
+		}
+	}
+	_, status, _, _ := staticPodOperatorClient.GetStaticPodOperatorState()
+	require.Equal(t, int32(2), status.LatestAvailableRevision)
+
+	getConfigMap := func(name string) (*v1.ConfigMap, error) {
+		return kubeClient.CoreV1().ConfigMaps(targetNamespace).Get(context.TODO(), name, metav1.GetOptions{})
+	}
+	pod, err := getConfigMap("test-pod-2")
+	require.NoError(t, err)
+	require.False(t, payload.IsPacked(pod), "expected the small configmap not to be packed")
+	packed, err := getConfigMap("test-config-2")
+	require.NoError(t, err)
+	require.True(t, payload.IsPacked(packed), "expected the large configmap to be packed")
+	chunk, err := getConfigMap(payload.ChunkName("test-config-2", 1))
+	require.NoError(t, err)
+	require.Equal(t, "revision-status-2", chunk.OwnerReferences[0].Name)
+	unpacked, err := payload.Unpack(packed, getConfigMap)
+	require.NoError(t, err)
+	require.Equal(t, largeData, unpacked.Data)
+}
@@ -0,0 +1,33 @@
+package schedulingpolicy
+
+import (
+	opv1 "github.com/openshift/api/operator/v1"
+	appsv1 "k8s.io/api/apps/v1"
+
+	dc "github.com/openshift/library-go/pkg/operator/deploymentcontroller"
+)
+
+// WithDeploymentHook enforces the policy on the pod template of the deployment.
+func WithDeploymentHook(policy PolicyFunc) dc.DeploymentHookFunc {
+	return func(_ *opv1.OperatorSpec, deployment *appsv1.Deployment) error {
+		p, err := policy()
+		if err != nil {
+			return err
+		}
+		p.Enforce(&deployment.Spec.Template.Spec)
+		return nil
+	}
+}
+
+// WithDaemonSetHook enforces the policy on the pod template of the daemonset. It is meant to be used as
+// csidrivernodeservicecontroller.DaemonSetHookFunc.
+func WithDaemonSetHook(policy PolicyFunc) func(*opv1.OperatorSpec, *appsv1.DaemonSet) error {
+	return func(_ *opv1.OperatorSpec, daemonSet *appsv1.DaemonSet) error {
+		p, err := policy()
+		if err != nil {
+			return err
+		}
+		p.Enforce(&daemonSet.Spec.Template.Spec)
+		return nil
+	}
+}
@@ -0,0 +1,134 @@
+// Package schedulingpolicy enforces the approved priority classes, tolerations and node selectors of control plane
+// operands and reports operands deviating from them.
+package schedulingpolicy
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// SystemClusterCritical is the priority class of control plane operands.
+	SystemClusterCritical = "system-cluster-critical"
+	// MasterNodeRoleLabel selects the control plane nodes.
+	MasterNodeRoleLabel = "node-role.kubernetes.io/master"
+
+	infraConfigName = "cluster"
+)
+
+// Policy is the scheduling configuration operand pods must have.
+type Policy struct {
+	// PriorityClassName is set on pods without a priority class or with one not in AllowedPriorityClasses.
+	PriorityClassName string
+	// AllowedPriorityClasses are accepted besides PriorityClassName.
+	AllowedPriorityClasses []string
+	// NodeSelector replaces the node selector of the pods. Nil leaves the node selector alone.
+	NodeSelector map[string]string
+	// Tolerations the pods must have. Additional tolerations of the pods are kept.
+	Tolerations []corev1.Toleration
+}
+
+// PolicyFunc returns the current policy, e.g. derived from the cluster topology.
+type PolicyFunc func() (Policy, error)
+
+// StaticPolicy returns a PolicyFunc always returning the policy.
+func StaticPolicy(policy Policy) PolicyFunc {
+	return func() (Policy, error) {
+		return policy, nil
+	}
+}
+
+// ControlPlanePolicy returns the defaults for control plane operands derived from the control plane topology: the
+// system-cluster-critical priority class, running on the control plane nodes unless they are external, and tolerating
+// the control plane taint and short node outages.
+func ControlPlanePolicy(infraLister configv1listers.InfrastructureLister) PolicyFunc {
+	return func() (Policy, error) {
+		infra, err := infraLister.Get(infraConfigName)
+		if err != nil {
+			return Policy{}, err
+		}
+		policy := Policy{
+			PriorityClassName: SystemClusterCritical,
+			NodeSelector:      map[string]string{MasterNodeRoleLabel: ""},
+			Tolerations: []corev1.Toleration{
+				{Key: MasterNodeRoleLabel, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+				{Key: corev1.TaintNodeNotReady, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute, TolerationSeconds: ptr.To[int64](120)},
+				{Key: corev1.TaintNodeUnreachable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute, TolerationSeconds: ptr.To[int64](120)},
+			},
+		}
+		if infra.Status.ControlPlaneTopology == configv1.ExternalTopologyMode {
+			policy.NodeSelector = map[string]string{}
+		}
+		return policy, nil
+	}
+}
+
+// Deviations returns human readable descriptions of how the pod spec deviates from the policy, or nil.
+func (p Policy) Deviations(spec *corev1.PodSpec) []string {
+	var deviations []string
+	if len(p.PriorityClassName) > 0 && spec.PriorityClassName != p.PriorityClassName && !slices.Contains(p.AllowedPriorityClasses, spec.PriorityClassName) {
+		deviations = append(deviations, fmt.Sprintf("priority class %q is not approved", spec.PriorityClassName))
+	}
+	if p.NodeSelector != nil && !equality.Semantic.DeepEqual(nonNil(spec.NodeSelector), p.NodeSelector) {
+		deviations = append(deviations, fmt.Sprintf("node selector %v is not %v", spec.NodeSelector, p.NodeSelector))
+	}
+	var missing []string
+	for _, toleration := range p.Tolerations {
+		if !hasToleration(spec.Tolerations, toleration) {
+			missing = append(missing, toleration.Key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		deviations = append(deviations, fmt.Sprintf("tolerations for %v are missing", missing))
+	}
+	return deviations
+}
+
+// Enforce changes the pod spec to follow the policy.
+func (p Policy) Enforce(spec *corev1.PodSpec) {
+	if len(p.PriorityClassName) > 0 && spec.PriorityClassName != p.PriorityClassName && !slices.Contains(p.AllowedPriorityClasses, spec.PriorityClassName) {
+		spec.PriorityClassName = p.PriorityClassName
+		// the priority is resolved from the class on admission
+		spec.Priority = nil
+	}
+	if p.NodeSelector != nil {
+		spec.NodeSelector = map[string]string{}
+		for k, v := range p.NodeSelector {
+			spec.NodeSelector[k] = v
+		}
+	}
+	for _, toleration := range p.Tolerations {
+		if hasToleration(spec.Tolerations, toleration) {
+			continue
+		}
+		// replace a toleration for the same taint with different parameters
+		spec.Tolerations = slices.DeleteFunc(spec.Tolerations, func(t corev1.Toleration) bool {
+			return t.Key == toleration.Key && t.Effect == toleration.Effect
+		})
+		spec.Tolerations = append(spec.Tolerations, toleration)
+	}
+}
+
+func hasToleration(tolerations []corev1.Toleration, toleration corev1.Toleration) bool {
+	for _, t := range tolerations {
+		if equality.Semantic.DeepEqual(t, toleration) {
+			return true
+		}
+	}
+	return false
+}
+
+func nonNil(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
@@ -0,0 +1,132 @@
+package schedulingpolicy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	opv1 "github.com/openshift/api/operator/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/management"
+	"github.com/openshift/library-go/pkg/operator/redeploytrigger"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// SchedulingPolicyController reports the <instance>SchedulingPolicyDegraded condition when the live operand workloads
+// deviate from the policy, e.g. because the hooks are not used or someone edited a workload.
+type SchedulingPolicyController struct {
+	controllerInstanceName string
+	conditionType          string
+	policy                 PolicyFunc
+	workloads              []redeploytrigger.Workload
+
+	operatorClient v1helpers.OperatorClient
+	informers      v1helpers.KubeInformersForNamespaces
+}
+
+// NewSchedulingPolicyController returns a controller checking the workloads against the policy. The informers must
+// contain the namespaces of the workloads.
+func NewSchedulingPolicyController(
+	instanceName string,
+	policy PolicyFunc,
+	workloads []redeploytrigger.Workload,
+	operatorClient v1helpers.OperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &SchedulingPolicyController{
+		controllerInstanceName: factory.ControllerInstanceName(instanceName, "SchedulingPolicy"),
+		conditionType:          instanceName + "SchedulingPolicy" + opv1.OperatorStatusTypeDegraded,
+		policy:                 policy,
+		workloads:              workloads,
+		operatorClient:         operatorClient,
+		informers:              kubeInformersForNamespaces,
+	}
+
+	names := sets.New[string]()
+	informers := []factory.Informer{operatorClient.Informer()}
+	for _, workload := range workloads {
+		names.Insert(workload.Name)
+		switch workload.Kind {
+		case redeploytrigger.Deployment:
+			informers = append(informers, kubeInformersForNamespaces.InformersFor(workload.Namespace).Apps().V1().Deployments().Informer())
+		case redeploytrigger.DaemonSet:
+			informers = append(informers, kubeInformersForNamespaces.InformersFor(workload.Namespace).Apps().V1().DaemonSets().Informer())
+		}
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers(factory.NamesFilter(sets.List(names)...), informers...).
+		WithSync(c.sync).
+		WithSyncDegradedOnError(operatorClient).
+		ResyncEvery(time.Minute).
+		ToController(c.controllerInstanceName, eventRecorder.WithComponentSuffix("scheduling-policy-controller"))
+}
+
+func (c *SchedulingPolicyController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	operatorSpec, _, _, err := c.operatorClient.GetOperatorState()
+	if err != nil {
+		return err
+	}
+	if !management.IsOperatorManaged(operatorSpec.ManagementState) {
+		return nil
+	}
+
+	policy, err := c.policy()
+	if err != nil {
+		return err
+	}
+
+	var deviations []string
+	for _, workload := range c.workloads {
+		spec, err := c.podSpec(workload)
+		if apierrors.IsNotFound(err) {
+			// not deployed yet, nothing to check
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, deviation := range policy.Deviations(spec) {
+			deviations = append(deviations, fmt.Sprintf("%s %s/%s: %s", workload.Kind, workload.Namespace, workload.Name, deviation))
+		}
+	}
+
+	condition := applyoperatorv1.OperatorCondition().
+		WithType(c.conditionType).
+		WithStatus(opv1.ConditionFalse)
+	if len(deviations) > 0 {
+		condition = condition.
+			WithStatus(opv1.ConditionTrue).
+			WithReason("PolicyViolated").
+			WithMessage(strings.Join(deviations, "\n"))
+	}
+	return c.operatorClient.ApplyOperatorStatus(ctx, c.controllerInstanceName, applyoperatorv1.OperatorStatus().WithConditions(condition))
+}
+
+func (c *SchedulingPolicyController) podSpec(workload redeploytrigger.Workload) (*corev1.PodSpec, error) {
+	apps := c.informers.InformersFor(workload.Namespace).Apps().V1()
+	switch workload.Kind {
+	case redeploytrigger.Deployment:
+		deployment, err := apps.Deployments().Lister().Deployments(workload.Namespace).Get(workload.Name)
+		if err != nil {
+			return nil, err
+		}
+		return &deployment.Spec.Template.Spec, nil
+	case redeploytrigger.DaemonSet:
+		daemonSet, err := apps.DaemonSets().Lister().DaemonSets(workload.Namespace).Get(workload.Name)
+		if err != nil {
+			return nil, err
+		}
+		return &daemonSet.Spec.Template.Spec, nil
+	default:
+		return nil, fmt.Errorf("unsupported workload kind %q", workload.Kind)
+	}
+}
@@ -0,0 +1,137 @@
+package schedulingpolicy
+
+import (
+	"context"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	opv1 "github.com/openshift/api/operator/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/redeploytrigger"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func controlPlanePolicy(t *testing.T, topology configv1.TopologyMode) Policy {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(&configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status:     configv1.InfrastructureStatus{ControlPlaneTopology: topology},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := ControlPlanePolicy(configv1listers.NewInfrastructureLister(indexer))()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return policy
+}
+
+func TestPolicyEnforce(t *testing.T) {
+	for _, tc := range []struct {
+		name                 string
+		topology             configv1.TopologyMode
+		spec                 corev1.PodSpec
+		expectedNodeSelector map[string]string
+		expectedDeviations   int
+	}{
+		{
+			name:                 "empty spec",
+			topology:             configv1.HighlyAvailableTopologyMode,
+			expectedNodeSelector: map[string]string{MasterNodeRoleLabel: ""},
+			expectedDeviations:   3,
+		},
+		{
+			name:     "external control plane",
+			topology: configv1.ExternalTopologyMode,
+			spec: corev1.PodSpec{
+				PriorityClassName: "openshift-user-critical",
+				NodeSelector:      map[string]string{MasterNodeRoleLabel: ""},
+				Tolerations: []corev1.Toleration{
+					{Key: corev1.TaintNodeNotReady, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+				},
+			},
+			expectedNodeSelector: map[string]string{},
+			expectedDeviations:   3,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := controlPlanePolicy(t, tc.topology)
+			spec := tc.spec.DeepCopy()
+			if deviations := policy.Deviations(spec); len(deviations) != tc.expectedDeviations {
+				t.Errorf("expected %d deviations, got %v", tc.expectedDeviations, deviations)
+			}
+
+			policy.Enforce(spec)
+			if deviations := policy.Deviations(spec); len(deviations) != 0 {
+				t.Errorf("expected no deviations after enforcing the policy, got %v", deviations)
+			}
+			if spec.PriorityClassName != SystemClusterCritical {
+				t.Errorf("expected priority class %s, got %s", SystemClusterCritical, spec.PriorityClassName)
+			}
+			if len(spec.NodeSelector) != len(tc.expectedNodeSelector) {
+				t.Errorf("expected node selector %v, got %v", tc.expectedNodeSelector, spec.NodeSelector)
+			}
+			if len(spec.Tolerations) != 3 {
+				t.Errorf("expected 3 tolerations, got %v", spec.Tolerations)
+			}
+		})
+	}
+}
+
+func TestSchedulingPolicyController(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	informers := v1helpers.NewKubeInformersForNamespaces(kubeClient, "operand")
+	deploymentIndexer := informers.InformersFor("operand").Apps().V1().Deployments().Informer().GetIndexer()
+	operatorClient := v1helpers.NewFakeOperatorClient(&opv1.OperatorSpec{ManagementState: opv1.Managed}, &opv1.OperatorStatus{}, nil)
+
+	policy := Policy{PriorityClassName: SystemClusterCritical}
+	c := &SchedulingPolicyController{
+		controllerInstanceName: "test",
+		conditionType:          "TestSchedulingPolicyDegraded",
+		policy:                 StaticPolicy(policy),
+		workloads:              []redeploytrigger.Workload{{Kind: redeploytrigger.Deployment, Namespace: "operand", Name: "server"}},
+		operatorClient:         operatorClient,
+		informers:              informers,
+	}
+	syncContext := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+
+	conditionStatus := func() opv1.ConditionStatus {
+		t.Helper()
+		if err := c.sync(context.TODO(), syncContext); err != nil {
+			t.Fatal(err)
+		}
+		_, status, _, _ := operatorClient.GetOperatorState()
+		return v1helpers.FindOperatorCondition(status.Conditions, "TestSchedulingPolicyDegraded").Status
+	}
+
+	// not deployed yet
+	if status := conditionStatus(); status != opv1.ConditionFalse {
+		t.Errorf("expected the condition to be False, got %s", status)
+	}
+
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "operand", Name: "server"}}
+	if err := deploymentIndexer.Add(deployment); err != nil {
+		t.Fatal(err)
+	}
+	if status := conditionStatus(); status != opv1.ConditionTrue {
+		t.Errorf("expected the condition to be True, got %s", status)
+	}
+
+	if err := WithDeploymentHook(StaticPolicy(policy))(&opv1.OperatorSpec{}, deployment); err != nil {
+		t.Fatal(err)
+	}
+	if err := deploymentIndexer.Update(deployment); err != nil {
+		t.Fatal(err)
+	}
+	if status := conditionStatus(); status != opv1.ConditionFalse {
+		t.Errorf("expected the condition to be False, got %s", status)
+	}
+}
@@ -69,7 +69,8 @@ func NewSecretRevisionPruneController(
 }
 
 func (c *SecretRevisionPruneController) sync(ctx context.Context, syncContext factory.SyncContext) error {
-	klog.V(5).Infof("revision pruner sync for ns/%s", c.targetNamespace)
+	logger := klog.FromContext(ctx)
+	logger.V(5).Info("Syncing secret revision pruner", "namespace", c.targetNamespace)
 
 	pods, err := c.podInformer.Lister().Pods(c.targetNamespace).List(c.podSelector)
 	if err != nil {
@@ -87,7 +88,7 @@ func (c *SecretRevisionPruneController) sync(ctx context.Context, syncContext fa
 	}
 
 	for _, s := range secretsToBePruned(minRevision, c.secretPrefixes, secrets) {
-		klog.V(4).Infof("Pruning old secret %q", s.Name)
+		logger.V(4).Info("Pruning old secret", "secret", s.Name)
 
 		// remove finalizer
 		retry.RetryOnConflict(retry.DefaultBackoff, func() error {
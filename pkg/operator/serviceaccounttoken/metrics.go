@@ -0,0 +1,40 @@
+package serviceaccounttoken
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	tokenExpirationMetric = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Subsystem:      "serviceaccount_token",
+		Name:           "expiration_timestamp_seconds",
+		Help:           "Expiration time of the service account token in the secret, in seconds since the epoch.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"namespace", "secret"})
+
+	tokenRotationsMetric = metrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      "serviceaccount_token",
+		Name:           "rotations_total",
+		Help:           "Number of service account tokens written to the secret.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"namespace", "secret", "reason"})
+)
+
+func init() {
+	(&sync.Once{}).Do(func() {
+		legacyregistry.MustRegister(tokenExpirationMetric)
+		legacyregistry.MustRegister(tokenRotationsMetric)
+	})
+}
+
+func recordTokenExpiration(namespace, secret string, expiration time.Time) {
+	tokenExpirationMetric.WithLabelValues(namespace, secret).Set(float64(expiration.Unix()))
+}
+
+func recordTokenRotation(namespace, secret, reason string) {
+	tokenRotationsMetric.WithLabelValues(namespace, secret, reason).Inc()
+}
@@ -0,0 +1,223 @@
+package serviceaccounttoken
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/management"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+const (
+	// TokenKey is the key of the token in the secret.
+	TokenKey = "token"
+
+	// ExpirationAnnotation is the RFC3339 expiration time of the token in the secret.
+	ExpirationAnnotation = "serviceaccounttoken.operator.openshift.io/expiration"
+	// RefreshAfterAnnotation is the RFC3339 time after which the token in the secret is replaced.
+	RefreshAfterAnnotation = "serviceaccounttoken.operator.openshift.io/refresh-after"
+	// AudiencesAnnotation is the comma separated list of audiences of the token in the secret.
+	AudiencesAnnotation = "serviceaccounttoken.operator.openshift.io/audiences"
+	// ServiceAccountUIDAnnotation is the UID of the service account the token in the secret is bound to.
+	ServiceAccountUIDAnnotation = "serviceaccounttoken.operator.openshift.io/service-account-uid"
+
+	// DefaultExpiration is the requested token lifetime when none is set.
+	DefaultExpiration = time.Hour
+	// refreshRatio is the fraction of the token lifetime after which a new token is minted.
+	refreshRatio = 0.8
+)
+
+// reasons a new token is minted
+const (
+	reasonTokenMissing            = "TokenMissing"
+	reasonServiceAccountRecreated = "ServiceAccountRecreated"
+	reasonAudiencesChanged        = "AudiencesChanged"
+	reasonInvalidAnnotations      = "InvalidAnnotations"
+	reasonTokenExpiring           = "TokenExpiring"
+)
+
+// Token describes the service account token minted for an operand.
+type Token struct {
+	// Namespace of the service account and the secret.
+	Namespace string
+	// ServiceAccountName is the name of the dedicated service account of the operand. It is created if missing.
+	ServiceAccountName string
+	// SecretName is the name of the secret the token is written to, under the TokenKey key.
+	SecretName string
+	// Audiences are the intended audiences of the token. Empty means the audiences of the API server.
+	Audiences []string
+	// Expiration is the requested lifetime of the token, at least 10 minutes. Zero means DefaultExpiration.
+	// The token is replaced once 80% of its lifetime has passed.
+	Expiration time.Duration
+}
+
+// ServiceAccountTokenController manages a dedicated service account of an operand and keeps a bound token of the
+// service account, minted with the TokenRequest API, in a secret. The token is replaced before it expires and when
+// the audiences or the service account change.
+type ServiceAccountTokenController struct {
+	controllerInstanceName string
+	token                  Token
+
+	operatorClient       v1helpers.OperatorClient
+	kubeClient           kubernetes.Interface
+	secretLister         corev1listers.SecretLister
+	serviceAccountLister corev1listers.ServiceAccountLister
+
+	now func() time.Time
+}
+
+// NewServiceAccountTokenController returns a controller keeping a token of the service account in the secret.
+// The informers must contain the namespace of the token.
+func NewServiceAccountTokenController(
+	instanceName string,
+	token Token,
+	operatorClient v1helpers.OperatorClient,
+	kubeClient kubernetes.Interface,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	if token.Expiration == 0 {
+		token.Expiration = DefaultExpiration
+	}
+	informers := kubeInformersForNamespaces.InformersFor(token.Namespace).Core().V1()
+	c := &ServiceAccountTokenController{
+		controllerInstanceName: factory.ControllerInstanceName(instanceName, "ServiceAccountToken"),
+		token:                  token,
+		operatorClient:         operatorClient,
+		kubeClient:             kubeClient,
+		secretLister:           informers.Secrets().Lister(),
+		serviceAccountLister:   informers.ServiceAccounts().Lister(),
+		now:                    time.Now,
+	}
+
+	return factory.New().
+		WithInformers(operatorClient.Informer()).
+		WithFilteredEventsInformers(
+			factory.NamesFilter(token.SecretName, token.ServiceAccountName),
+			informers.Secrets().Informer(),
+			informers.ServiceAccounts().Informer(),
+		).
+		WithSync(c.sync).
+		WithSyncDegradedOnError(operatorClient).
+		ResyncEvery(time.Minute).
+		ToController(c.controllerInstanceName, eventRecorder.WithComponentSuffix("service-account-token-controller"))
+}
+
+func (c *ServiceAccountTokenController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	operatorSpec, _, _, err := c.operatorClient.GetOperatorState()
+	if err != nil {
+		return err
+	}
+	if !management.IsOperatorManaged(operatorSpec.ManagementState) {
+		return nil
+	}
+
+	serviceAccount, err := c.ensureServiceAccount(ctx, syncCtx.Recorder())
+	if err != nil {
+		return err
+	}
+
+	secret, err := c.secretLister.Secrets(c.token.Namespace).Get(c.token.SecretName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	now := c.now()
+	reason, refreshAfter, expiration := c.rotationReason(secret, serviceAccount, now)
+	if len(reason) == 0 {
+		recordTokenExpiration(c.token.Namespace, c.token.SecretName, expiration)
+		syncCtx.Queue().AddAfter(syncCtx.QueueKey(), refreshAfter.Sub(now))
+		return nil
+	}
+
+	tokenRequest, err := c.kubeClient.CoreV1().ServiceAccounts(c.token.Namespace).CreateToken(ctx, c.token.ServiceAccountName, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			Audiences:         c.token.Audiences,
+			ExpirationSeconds: ptr.To(int64(c.token.Expiration.Seconds())),
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		syncCtx.Recorder().Warningf("ServiceAccountTokenFailed", "Failed to request a token of service account %s/%s: %v", c.token.Namespace, c.token.ServiceAccountName, err)
+		return fmt.Errorf("failed to request a token of service account %s/%s: %w", c.token.Namespace, c.token.ServiceAccountName, err)
+	}
+
+	expiration = tokenRequest.Status.ExpirationTimestamp.Time
+	refreshAfter = now.Add(time.Duration(float64(expiration.Sub(now)) * refreshRatio))
+	_, _, err = resourceapply.ApplySecret(ctx, c.kubeClient.CoreV1(), syncCtx.Recorder(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: c.token.Namespace,
+			Name:      c.token.SecretName,
+			Annotations: map[string]string{
+				ExpirationAnnotation:        expiration.Format(time.RFC3339),
+				RefreshAfterAnnotation:      refreshAfter.Format(time.RFC3339),
+				AudiencesAnnotation:         strings.Join(c.token.Audiences, ","),
+				ServiceAccountUIDAnnotation: string(serviceAccount.UID),
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{TokenKey: []byte(tokenRequest.Status.Token)},
+	})
+	if err != nil {
+		return err
+	}
+
+	recordTokenRotation(c.token.Namespace, c.token.SecretName, reason)
+	recordTokenExpiration(c.token.Namespace, c.token.SecretName, expiration)
+	syncCtx.Recorder().Eventf("ServiceAccountTokenRotated", "Wrote a new token of service account %s/%s to secret %s, expiring at %s, because of %s",
+		c.token.Namespace, c.token.ServiceAccountName, c.token.SecretName, expiration.Format(time.RFC3339), reason)
+	syncCtx.Queue().AddAfter(syncCtx.QueueKey(), refreshAfter.Sub(now))
+	return nil
+}
+
+func (c *ServiceAccountTokenController) ensureServiceAccount(ctx context.Context, recorder events.Recorder) (*corev1.ServiceAccount, error) {
+	serviceAccount, err := c.serviceAccountLister.ServiceAccounts(c.token.Namespace).Get(c.token.ServiceAccountName)
+	if err == nil {
+		return serviceAccount, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	serviceAccount, _, err = resourceapply.ApplyServiceAccount(ctx, c.kubeClient.CoreV1(), recorder, &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Namespace: c.token.Namespace, Name: c.token.ServiceAccountName},
+	})
+	return serviceAccount, err
+}
+
+// rotationReason returns why the token in the secret must be replaced, or an empty reason together with the time
+// the token must be refreshed and its expiration.
+func (c *ServiceAccountTokenController) rotationReason(secret *corev1.Secret, serviceAccount *corev1.ServiceAccount, now time.Time) (string, time.Time, time.Time) {
+	if secret == nil || len(secret.Data[TokenKey]) == 0 {
+		return reasonTokenMissing, time.Time{}, time.Time{}
+	}
+	if secret.Annotations[ServiceAccountUIDAnnotation] != string(serviceAccount.UID) {
+		return reasonServiceAccountRecreated, time.Time{}, time.Time{}
+	}
+	if secret.Annotations[AudiencesAnnotation] != strings.Join(c.token.Audiences, ",") {
+		return reasonAudiencesChanged, time.Time{}, time.Time{}
+	}
+	expiration, err := time.Parse(time.RFC3339, secret.Annotations[ExpirationAnnotation])
+	if err != nil {
+		return reasonInvalidAnnotations, time.Time{}, time.Time{}
+	}
+	refreshAfter, err := time.Parse(time.RFC3339, secret.Annotations[RefreshAfterAnnotation])
+	if err != nil {
+		return reasonInvalidAnnotations, time.Time{}, time.Time{}
+	}
+	if !now.Before(refreshAfter) {
+		return reasonTokenExpiring, time.Time{}, time.Time{}
+	}
+	return "", refreshAfter, expiration
+}
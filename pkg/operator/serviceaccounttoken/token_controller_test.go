@@ -0,0 +1,119 @@
+package serviceaccounttoken
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/component-base/metrics/testutil"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestServiceAccountTokenController(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	serviceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "operand", Name: "operand-sa", UID: "sa-uid"}}
+	kubeClient := fake.NewSimpleClientset(serviceAccount)
+
+	var tokenRequests []*authenticationv1.TokenRequest
+	kubeClient.PrependReactor("create", "serviceaccounts", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		request := action.(clienttesting.CreateAction).GetObject().(*authenticationv1.TokenRequest).DeepCopy()
+		request.Status = authenticationv1.TokenRequestStatus{
+			Token:               fmt.Sprintf("token-%d", len(tokenRequests)),
+			ExpirationTimestamp: metav1.NewTime(now.Add(time.Duration(*request.Spec.ExpirationSeconds) * time.Second)),
+		}
+		tokenRequests = append(tokenRequests, request)
+		return true, request, nil
+	})
+
+	kubeInformers := informers.NewSharedInformerFactoryWithOptions(kubeClient, 0, informers.WithNamespace("operand"))
+	serviceAccountIndexer := kubeInformers.Core().V1().ServiceAccounts().Informer().GetIndexer()
+	secretIndexer := kubeInformers.Core().V1().Secrets().Informer().GetIndexer()
+	if err := serviceAccountIndexer.Add(serviceAccount); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &ServiceAccountTokenController{
+		controllerInstanceName: "test-ServiceAccountToken",
+		token: Token{
+			Namespace:          "operand",
+			ServiceAccountName: "operand-sa",
+			SecretName:         "operand-token",
+			Audiences:          []string{"operand"},
+			Expiration:         time.Hour,
+		},
+		operatorClient:       v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{ManagementState: operatorv1.Managed}, &operatorv1.OperatorStatus{}, nil),
+		kubeClient:           kubeClient,
+		secretLister:         kubeInformers.Core().V1().Secrets().Lister(),
+		serviceAccountLister: kubeInformers.Core().V1().ServiceAccounts().Lister(),
+		now:                  func() time.Time { return now },
+	}
+	syncContext := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+
+	// syncAndGetSecret syncs and feeds the written secret back to the lister
+	syncAndGetSecret := func() *corev1.Secret {
+		t.Helper()
+		if err := c.sync(context.TODO(), syncContext); err != nil {
+			t.Fatal(err)
+		}
+		secret, err := kubeClient.CoreV1().Secrets("operand").Get(context.TODO(), "operand-token", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := secretIndexer.Update(secret); err != nil {
+			t.Fatal(err)
+		}
+		return secret
+	}
+
+	secret := syncAndGetSecret()
+	if len(tokenRequests) != 1 || string(secret.Data[TokenKey]) != "token-0" {
+		t.Fatalf("expected the first token to be written, got %d requests and secret %v", len(tokenRequests), secret.Data)
+	}
+	if audiences := tokenRequests[0].Spec.Audiences; len(audiences) != 1 || audiences[0] != "operand" {
+		t.Errorf("unexpected audiences %v", audiences)
+	}
+	if expected := now.Add(48 * time.Minute).Format(time.RFC3339); secret.Annotations[RefreshAfterAnnotation] != expected {
+		t.Errorf("expected refresh after %s, got %s", expected, secret.Annotations[RefreshAfterAnnotation])
+	}
+	if value, err := testutil.GetGaugeMetricValue(tokenExpirationMetric.WithLabelValues("operand", "operand-token")); err != nil || value != float64(now.Add(time.Hour).Unix()) {
+		t.Errorf("unexpected expiration metric %v: %v", value, err)
+	}
+
+	// the token is valid, nothing to do
+	syncAndGetSecret()
+	if len(tokenRequests) != 1 {
+		t.Errorf("expected the valid token to be kept, got %d requests", len(tokenRequests))
+	}
+
+	// the token is about to expire
+	now = now.Add(50 * time.Minute)
+	secret = syncAndGetSecret()
+	if len(tokenRequests) != 2 || string(secret.Data[TokenKey]) != "token-1" {
+		t.Errorf("expected the expiring token to be replaced, got %d requests and secret %v", len(tokenRequests), secret.Data)
+	}
+
+	// the audiences changed
+	c.token.Audiences = []string{"operand", "metrics"}
+	secret = syncAndGetSecret()
+	if len(tokenRequests) != 3 || secret.Annotations[AudiencesAnnotation] != "operand,metrics" {
+		t.Errorf("expected a new token for the new audiences, got %d requests and annotations %v", len(tokenRequests), secret.Annotations)
+	}
+	if value, err := testutil.GetCounterMetricValue(tokenRotationsMetric.WithLabelValues("operand", "operand-token", reasonTokenExpiring)); err != nil || value != 1 {
+		t.Errorf("expected 1 rotation of an expiring token, got %v: %v", value, err)
+	}
+}
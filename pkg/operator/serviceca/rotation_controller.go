@@ -0,0 +1,155 @@
+package serviceca
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	opv1 "github.com/openshift/api/operator/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	dc "github.com/openshift/library-go/pkg/operator/deploymentcontroller"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+const (
+	// ObservedCAHashAnnotation on the CA bundle configmap records the hash of the service CA bundle the rotation
+	// handlers were last run for. Storing it on the configmap makes rotations during operator restarts visible.
+	ObservedCAHashAnnotation = "serviceca.operator.openshift.io/observed-ca-hash"
+	// CAHashAnnotation is the pod template annotation set by WithDeploymentHook and WithDaemonSetHook.
+	CAHashAnnotation = "serviceca.operator.openshift.io/ca-hash"
+)
+
+// RotationFunc is called with the hash of the new service CA bundle when the service CA rotated.
+type RotationFunc func(ctx context.Context, caHash string) error
+
+// ServiceCARotationController ensures a configmap with the injected service CA bundle and runs the rotation handlers
+// whenever the injected bundle changes. Failed handlers are retried until all of them succeed for the new bundle.
+type ServiceCARotationController struct {
+	namespace  string
+	name       string
+	onRotation []RotationFunc
+
+	kubeClient      kubernetes.Interface
+	configMapLister corev1listers.ConfigMapLister
+}
+
+// NewServiceCARotationController returns a controller running the handlers on rotations of the service CA bundle
+// injected into the namespace/name configmap.
+func NewServiceCARotationController(
+	instanceName string,
+	namespace, name string,
+	kubeClient kubernetes.Interface,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	eventRecorder events.Recorder,
+	onRotation ...RotationFunc,
+) factory.Controller {
+	configMaps := kubeInformersForNamespaces.InformersFor(namespace).Core().V1().ConfigMaps()
+	c := &ServiceCARotationController{
+		namespace:       namespace,
+		name:            name,
+		onRotation:      onRotation,
+		kubeClient:      kubeClient,
+		configMapLister: configMaps.Lister(),
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers(factory.NamesFilter(name), configMaps.Informer()).
+		WithSync(c.sync).
+		ResyncEvery(time.Minute).
+		ToController(factory.ControllerInstanceName(instanceName, "ServiceCARotation"), eventRecorder.WithComponentSuffix("service-ca-rotation-controller"))
+}
+
+func (c *ServiceCARotationController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	configMap, err := c.configMapLister.ConfigMaps(c.namespace).Get(c.name)
+	if apierrors.IsNotFound(err) || (err == nil && configMap.Annotations[InjectCABundleAnnotation] != "true") {
+		_, _, err := resourceapply.ApplyConfigMap(ctx, c.kubeClient.CoreV1(), syncCtx.Recorder(), CABundleConfigMap(c.namespace, c.name))
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	caHash := CABundleHash(configMap)
+	if len(caHash) == 0 {
+		// the service-ca operator has not injected the bundle yet, we get an event when it does
+		return nil
+	}
+	observed := configMap.Annotations[ObservedCAHashAnnotation]
+	if observed == caHash {
+		return nil
+	}
+
+	// the first observed bundle is not a rotation
+	if len(observed) > 0 {
+		syncCtx.Recorder().Eventf("ServiceCARotated", "Service CA bundle in configmap %s/%s changed, running %d rotation handler(s)", c.namespace, c.name, len(c.onRotation))
+		var errs []error
+		for _, onRotation := range c.onRotation {
+			if err := onRotation(ctx, caHash); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if err := utilerrors.NewAggregate(errs); err != nil {
+			syncCtx.Recorder().Warningf("ServiceCARotationFailed", "Failed to handle the rotation of the service CA: %v", err)
+			return err
+		}
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{ObservedCAHashAnnotation: caHash},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.kubeClient.CoreV1().ConfigMaps(c.namespace).Patch(ctx, c.name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// WithDeploymentHook sets the hash of the service CA bundle last handled by the ServiceCARotationController for the
+// namespace/name configmap on the pod template of the deployment, so that it is rolled out on every rotation once the
+// rotation handlers succeeded. The controller applying the deployment must sync on changes of the configmap, e.g. by
+// passing the configmap informer as one of the optional informers of the deployment controller.
+func WithDeploymentHook(configMapLister corev1listers.ConfigMapLister, namespace, name string) dc.DeploymentHookFunc {
+	return func(_ *opv1.OperatorSpec, deployment *appsv1.Deployment) error {
+		setCAHashAnnotation(&deployment.Spec.Template, configMapLister, namespace, name)
+		return nil
+	}
+}
+
+// WithDaemonSetHook is WithDeploymentHook for daemonsets. It is meant to be used as
+// csidrivernodeservicecontroller.DaemonSetHookFunc.
+func WithDaemonSetHook(configMapLister corev1listers.ConfigMapLister, namespace, name string) func(*opv1.OperatorSpec, *appsv1.DaemonSet) error {
+	return func(_ *opv1.OperatorSpec, daemonSet *appsv1.DaemonSet) error {
+		setCAHashAnnotation(&daemonSet.Spec.Template, configMapLister, namespace, name)
+		return nil
+	}
+}
+
+// setCAHashAnnotation copies the observed CA hash to the pod template. A missing configmap or hash is not an error,
+// the workload is rolled out once the first bundle is observed.
+func setCAHashAnnotation(template *corev1.PodTemplateSpec, configMapLister corev1listers.ConfigMapLister, namespace, name string) {
+	configMap, err := configMapLister.ConfigMaps(namespace).Get(name)
+	if err != nil {
+		return
+	}
+	caHash, ok := configMap.Annotations[ObservedCAHashAnnotation]
+	if !ok {
+		return
+	}
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[CAHashAnnotation] = caHash
+}
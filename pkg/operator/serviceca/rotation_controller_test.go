@@ -0,0 +1,112 @@
+package serviceca
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestServiceCARotationController(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	informers := v1helpers.NewKubeInformersForNamespaces(kubeClient, "operand")
+	configMapIndexer := informers.InformersFor("operand").Core().V1().ConfigMaps().Informer().GetIndexer()
+
+	var rotations []string
+	failRotation := false
+	c := &ServiceCARotationController{
+		namespace:  "operand",
+		name:       "service-ca",
+		kubeClient: kubeClient,
+		onRotation: []RotationFunc{
+			func(ctx context.Context, caHash string) error {
+				if failRotation {
+					return fmt.Errorf("reload failed")
+				}
+				rotations = append(rotations, caHash)
+				return nil
+			},
+		},
+		configMapLister: informers.InformersFor("operand").Core().V1().ConfigMaps().Lister(),
+	}
+	syncContext := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+
+	// sync syncs and feeds the configmap back to the lister, with the given injected bundle
+	sync := func(caBundle string) error {
+		t.Helper()
+		err := c.sync(context.TODO(), syncContext)
+		configMap, getErr := kubeClient.CoreV1().ConfigMaps("operand").Get(context.TODO(), "service-ca", metav1.GetOptions{})
+		if getErr != nil {
+			t.Fatal(getErr)
+		}
+		if len(caBundle) > 0 {
+			configMap.Data = map[string]string{CABundleKey: caBundle}
+			if _, err := kubeClient.CoreV1().ConfigMaps("operand").Update(context.TODO(), configMap, metav1.UpdateOptions{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := configMapIndexer.Update(configMap); err != nil {
+			t.Fatal(err)
+		}
+		return err
+	}
+
+	// the configmap requesting the injection is created
+	if err := sync("first-ca"); err != nil {
+		t.Fatal(err)
+	}
+	configMap, err := kubeClient.CoreV1().ConfigMaps("operand").Get(context.TODO(), "service-ca", metav1.GetOptions{})
+	if err != nil || configMap.Annotations[InjectCABundleAnnotation] != "true" {
+		t.Fatalf("expected the CA bundle injection to be requested, got %v: %v", configMap, err)
+	}
+
+	// the first injected bundle is recorded without running the handlers
+	if err := sync(""); err != nil {
+		t.Fatal(err)
+	}
+	if len(rotations) != 0 {
+		t.Fatalf("expected no rotation for the first bundle, got %v", rotations)
+	}
+
+	// the service CA rotates, the handler fails and is retried
+	failRotation = true
+	if err := sync("second-ca"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sync(""); err == nil {
+		t.Fatal("expected the failed rotation handler to be reported")
+	}
+	failRotation = false
+	if err := sync(""); err != nil {
+		t.Fatal(err)
+	}
+	secondHash := CABundleHash(&corev1.ConfigMap{Data: map[string]string{CABundleKey: "second-ca"}})
+	if len(rotations) != 1 || rotations[0] != secondHash {
+		t.Fatalf("expected one rotation to %s, got %v", secondHash, rotations)
+	}
+	// the deployment applied after the rotation is rolled out
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "operand", Name: "server"}}
+	if err := WithDeploymentHook(c.configMapLister, "operand", "service-ca")(nil, deployment); err != nil || deployment.Spec.Template.Annotations[CAHashAnnotation] != secondHash {
+		t.Errorf("expected the deployment to be redeployed, got %v: %v", deployment.Spec.Template.Annotations, err)
+	}
+
+	// nothing changed
+	if err := sync(""); err != nil || len(rotations) != 1 {
+		t.Errorf("expected no further rotation, got %v: %v", rotations, err)
+	}
+}
+
+func TestWithServingCert(t *testing.T) {
+	service := WithServingCert(&corev1.Service{}, "serving-cert")
+	if !IsServingCertRequested(service, "serving-cert") {
+		t.Errorf("expected the serving certificate to be requested, got %v", service.Annotations)
+	}
+}
@@ -0,0 +1,56 @@
+// Package serviceca helps operators relying on the service-ca operator: requesting serving certificates and the
+// injection of the service CA bundle, and reacting to rotations of the service CA.
+package serviceca
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ServingCertSecretAnnotation on a service requests a serving certificate for the service in the named secret.
+	ServingCertSecretAnnotation = "service.beta.openshift.io/serving-cert-secret-name"
+	// InjectCABundleAnnotation on a configmap requests the injection of the service CA bundle.
+	InjectCABundleAnnotation = "service.beta.openshift.io/inject-cabundle"
+	// CABundleKey is the key of the injected service CA bundle.
+	CABundleKey = "service-ca.crt"
+)
+
+// WithServingCert annotates the service to request a serving certificate in the secret. The service is meant to be
+// applied with resourceapply afterwards.
+func WithServingCert(service *corev1.Service, secretName string) *corev1.Service {
+	if service.Annotations == nil {
+		service.Annotations = map[string]string{}
+	}
+	service.Annotations[ServingCertSecretAnnotation] = secretName
+	return service
+}
+
+// CABundleConfigMap returns a configmap requesting the injection of the service CA bundle. Applying it with
+// resourceapply.ApplyConfigMap keeps the injected bundle.
+func CABundleConfigMap(namespace, name string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        name,
+			Annotations: map[string]string{InjectCABundleAnnotation: "true"},
+		},
+	}
+}
+
+// IsServingCertRequested returns true if the service requests a serving certificate in the secret.
+func IsServingCertRequested(service *corev1.Service, secretName string) bool {
+	return service.Annotations[ServingCertSecretAnnotation] == secretName
+}
+
+// CABundleHash returns the hash of the injected service CA bundle, or an empty string if it was not injected yet.
+func CABundleHash(configMap *corev1.ConfigMap) string {
+	caBundle := configMap.Data[CABundleKey]
+	if len(caBundle) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(caBundle)))
+}
@@ -0,0 +1,71 @@
+package statesnapshot
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	// snapshotFile is the file of the snapshot in the archive.
+	snapshotFile = "snapshot.json"
+	// maxSnapshotSize bounds the snapshot read from an archive.
+	maxSnapshotSize = 64 << 20
+)
+
+// WriteArchive writes the snapshot as a gzipped tar archive.
+func WriteArchive(w io.Writer, snapshot *Snapshot) error {
+	content, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+	if err := tarWriter.WriteHeader(&tar.Header{
+		Name:    snapshotFile,
+		Mode:    0600,
+		Size:    int64(len(content)),
+		ModTime: snapshot.TakenAt.Time,
+	}); err != nil {
+		return err
+	}
+	if _, err := tarWriter.Write(content); err != nil {
+		return err
+	}
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// ReadArchive reads the snapshot of an archive written by WriteArchive.
+func ReadArchive(r io.Reader) (*Snapshot, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot archive: %w", err)
+	}
+	defer gzipReader.Close()
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("invalid snapshot archive: missing %s", snapshotFile)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshot archive: %w", err)
+		}
+		if header.Name != snapshotFile {
+			continue
+		}
+		if header.Size > maxSnapshotSize {
+			return nil, fmt.Errorf("invalid snapshot archive: %s is larger than %d bytes", snapshotFile, maxSnapshotSize)
+		}
+		snapshot := &Snapshot{}
+		if err := json.NewDecoder(io.LimitReader(tarReader, maxSnapshotSize)).Decode(snapshot); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", snapshotFile, err)
+		}
+		return snapshot, nil
+	}
+}
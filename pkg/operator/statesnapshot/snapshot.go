@@ -0,0 +1,235 @@
+// Package statesnapshot snapshots the state an operator manages, its revisions, encryption keys, certificates and
+// observed config, into an archive, and verifies a restored cluster against the snapshot. It supports the disaster
+// recovery runbooks of the control plane operators: take a snapshot before the etcd backup, and verify the cluster
+// after the restore. The snapshots have the metadata and the hashes of the secrets, never their data.
+package statesnapshot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openshift/api/annotations"
+
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+	encryptionsecrets "github.com/openshift/library-go/pkg/operator/encryption/secrets"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+const (
+	// defaultEncryptionKeysNamespace is the namespace of the encryption key secrets.
+	defaultEncryptionKeysNamespace = "openshift-config-managed"
+
+	revisionStatusPrefix    = "revision-status-"
+	revisionReadyAnnotation = "operator.openshift.io/revision-ready"
+)
+
+// secretAnnotations are the annotations of the secrets kept in a snapshot. The others, like the last applied
+// configuration, may carry the secret data.
+var secretAnnotations = []string{
+	annotations.OpenShiftComponent,
+	annotations.OpenShiftDescription,
+	certrotation.CertificateNotBeforeAnnotation,
+	certrotation.CertificateNotAfterAnnotation,
+	certrotation.CertificateIssuer,
+	certrotation.CertificateHostnames,
+	encryptionsecrets.EncryptionSecretMigratedTimestamp,
+	encryptionsecrets.EncryptionSecretMigratedResources,
+}
+
+// Options selects the state to snapshot.
+type Options struct {
+	// TargetNamespace is the namespace of the revision-status config maps of a static pod operator. No revisions are
+	// snapshotted without it.
+	TargetNamespace string
+	// EncryptionComponent is the component of the encryption keys, e.g. openshift-kube-apiserver. No encryption keys
+	// are snapshotted without it.
+	EncryptionComponent string
+	// EncryptionKeysNamespace is the namespace of the encryption key secrets, openshift-config-managed by default.
+	EncryptionKeysNamespace string
+	// CertNamespaces are the namespaces of the certificate secrets managed by certrotation.
+	CertNamespaces []string
+}
+
+// Snapshot is the state an operator manages.
+type Snapshot struct {
+	TakenAt metav1.Time `json:"takenAt"`
+	Options Options     `json:"options"`
+
+	ObservedConfig runtime.RawExtension `json:"observedConfig,omitempty"`
+
+	LatestAvailableRevision int32          `json:"latestAvailableRevision,omitempty"`
+	NodeStatuses            []NodeRevision `json:"nodeStatuses,omitempty"`
+	Revisions               []Revision     `json:"revisions,omitempty"`
+
+	EncryptionKeys []SecretMetadata `json:"encryptionKeys,omitempty"`
+	CertSecrets    []SecretMetadata `json:"certSecrets,omitempty"`
+}
+
+// NodeRevision is the revision a node of a static pod operator runs.
+type NodeRevision struct {
+	NodeName        string `json:"nodeName"`
+	CurrentRevision int32  `json:"currentRevision"`
+}
+
+// Revision is a revision-status config map.
+type Revision struct {
+	Revision int32  `json:"revision"`
+	Reason   string `json:"reason,omitempty"`
+	Status   string `json:"status,omitempty"`
+	Ready    string `json:"ready,omitempty"`
+}
+
+// SecretMetadata describes a secret without its data.
+type SecretMetadata struct {
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Type      corev1.SecretType `json:"type,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	// Annotations are only the certificate, encryption and ownership annotations of the secret.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	DataKeys    []string          `json:"dataKeys,omitempty"`
+	// DataHash is the sha256 of the data of the secret, it tells whether a restored secret has the same data.
+	DataHash string `json:"dataHash"`
+}
+
+// Take snapshots the state of the operator. A static pod operator client adds the revisions of the nodes.
+func Take(ctx context.Context, kubeClient kubernetes.Interface, operatorClient v1helpers.OperatorClient, options Options) (*Snapshot, error) {
+	if len(options.EncryptionKeysNamespace) == 0 {
+		options.EncryptionKeysNamespace = defaultEncryptionKeysNamespace
+	}
+	snapshot := &Snapshot{TakenAt: metav1.Now(), Options: options}
+
+	if staticPodOperatorClient, ok := operatorClient.(v1helpers.StaticPodOperatorClient); ok {
+		spec, status, _, err := staticPodOperatorClient.GetStaticPodOperatorStateWithQuorum(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the operator state: %w", err)
+		}
+		snapshot.ObservedConfig = spec.ObservedConfig
+		snapshot.LatestAvailableRevision = status.LatestAvailableRevision
+		for _, nodeStatus := range status.NodeStatuses {
+			snapshot.NodeStatuses = append(snapshot.NodeStatuses, NodeRevision{NodeName: nodeStatus.NodeName, CurrentRevision: nodeStatus.CurrentRevision})
+		}
+	} else {
+		spec, _, _, err := operatorClient.GetOperatorStateWithQuorum(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the operator state: %w", err)
+		}
+		snapshot.ObservedConfig = spec.ObservedConfig
+	}
+
+	revisions, err := listRevisions(ctx, kubeClient, options.TargetNamespace)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Revisions = revisions
+
+	if len(options.EncryptionComponent) > 0 {
+		snapshot.EncryptionKeys, err = listSecrets(ctx, kubeClient, options.EncryptionKeysNamespace, encryptionsecrets.EncryptionKeySecretsLabel+"="+options.EncryptionComponent)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, namespace := range options.CertNamespaces {
+		certSecrets, err := listSecrets(ctx, kubeClient, namespace, certrotation.ManagedCertificateTypeLabelName)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.CertSecrets = append(snapshot.CertSecrets, certSecrets...)
+	}
+	return snapshot, nil
+}
+
+// listRevisions lists the revision-status config maps, by revision.
+func listRevisions(ctx context.Context, kubeClient kubernetes.Interface, namespace string) ([]Revision, error) {
+	if len(namespace) == 0 {
+		return nil, nil
+	}
+	configMaps, err := kubeClient.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the revisions in %q: %w", namespace, err)
+	}
+	var revisions []Revision
+	for _, configMap := range configMaps.Items {
+		if !strings.HasPrefix(configMap.Name, revisionStatusPrefix) {
+			continue
+		}
+		revision, err := strconv.ParseInt(strings.TrimPrefix(configMap.Name, revisionStatusPrefix), 10, 32)
+		if err != nil {
+			continue
+		}
+		revisions = append(revisions, Revision{
+			Revision: int32(revision),
+			Reason:   configMap.Data["reason"],
+			Status:   configMap.Data["status"],
+			Ready:    configMap.Annotations[revisionReadyAnnotation],
+		})
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision < revisions[j].Revision })
+	return revisions, nil
+}
+
+// listSecrets lists the metadata of the secrets matching the label selector, by name.
+func listSecrets(ctx context.Context, kubeClient kubernetes.Interface, namespace, labelSelector string) ([]SecretMetadata, error) {
+	secrets, err := kubeClient.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the secrets in %q: %w", namespace, err)
+	}
+	var metadata []SecretMetadata
+	for i := range secrets.Items {
+		metadata = append(metadata, secretMetadata(&secrets.Items[i]))
+	}
+	sort.Slice(metadata, func(i, j int) bool { return metadata[i].Name < metadata[j].Name })
+	return metadata, nil
+}
+
+func secretMetadata(secret *corev1.Secret) SecretMetadata {
+	metadata := SecretMetadata{
+		Namespace: secret.Namespace,
+		Name:      secret.Name,
+		Type:      secret.Type,
+		Labels:    secret.Labels,
+		DataHash:  secretDataHash(secret),
+	}
+	for _, key := range secretAnnotations {
+		if value, ok := secret.Annotations[key]; ok {
+			if metadata.Annotations == nil {
+				metadata.Annotations = map[string]string{}
+			}
+			metadata.Annotations[key] = value
+		}
+	}
+	for key := range secret.Data {
+		metadata.DataKeys = append(metadata.DataKeys, key)
+	}
+	sort.Strings(metadata.DataKeys)
+	return metadata
+}
+
+// secretDataHash hashes the keys and values of the secret data, prefixed by their lengths.
+func secretDataHash(secret *corev1.Secret) string {
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, key := range keys {
+		for _, b := range [][]byte{[]byte(key), secret.Data[key]} {
+			var length [8]byte
+			binary.BigEndian.PutUint64(length[:], uint64(len(b)))
+			h.Write(length[:])
+			h.Write(b)
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -0,0 +1,131 @@
+package statesnapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+	encryptionsecrets "github.com/openshift/library-go/pkg/operator/encryption/secrets"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func revisionStatus(revision string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "operand",
+			Name:        "revision-status-" + revision,
+			Annotations: map[string]string{revisionReadyAnnotation: "true"},
+		},
+		Data: map[string]string{"revision": revision, "reason": "new revision"},
+	}
+}
+
+func TestSnapshotAndVerify(t *testing.T) {
+	encryptionKey := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-config-managed",
+			Name:      "encryption-key-operand-1",
+			Labels:    map[string]string{encryptionsecrets.EncryptionKeySecretsLabel: "operand"},
+		},
+		Data: map[string][]byte{encryptionsecrets.EncryptionSecretKeyDataKey: []byte("key")},
+	}
+	certSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "operand",
+			Name:      "serving-cert",
+			Labels:    map[string]string{certrotation.ManagedCertificateTypeLabelName: "target"},
+			Annotations: map[string]string{
+				certrotation.CertificateNotAfterAnnotation:         "2030-01-01T00:00:00Z",
+				"kubectl.kubernetes.io/last-applied-configuration": `{"data":{"tls.key":"a2V5"}}`,
+			},
+		},
+		Data: map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")},
+	}
+	otherSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "operand", Name: "other"}}
+	kubeClient := fake.NewSimpleClientset(revisionStatus("1"), revisionStatus("2"), encryptionKey, certSecret, otherSecret)
+	operatorClient := v1helpers.NewFakeStaticPodOperatorClient(
+		&operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{ObservedConfig: runtime.RawExtension{Raw: []byte(`{"a":1,"b":2}`)}}},
+		&operatorv1.StaticPodOperatorStatus{
+			OperatorStatus: operatorv1.OperatorStatus{LatestAvailableRevision: 2},
+			NodeStatuses:   []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 2}},
+		},
+		nil, nil,
+	)
+	options := Options{TargetNamespace: "operand", EncryptionComponent: "operand", CertNamespaces: []string{"operand"}}
+
+	snapshot, err := Take(context.TODO(), kubeClient, operatorClient, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.Revisions) != 2 || len(snapshot.EncryptionKeys) != 1 || len(snapshot.CertSecrets) != 1 || len(snapshot.NodeStatuses) != 1 {
+		t.Fatalf("unexpected snapshot %#v", snapshot)
+	}
+	if !reflect.DeepEqual(snapshot.CertSecrets[0].DataKeys, []string{"tls.crt", "tls.key"}) || len(snapshot.CertSecrets[0].DataHash) == 0 {
+		t.Errorf("expected the keys and the hash of the data, got %#v", snapshot.CertSecrets[0])
+	}
+	if expected := map[string]string{certrotation.CertificateNotAfterAnnotation: "2030-01-01T00:00:00Z"}; !reflect.DeepEqual(snapshot.CertSecrets[0].Annotations, expected) {
+		t.Errorf("expected only the allowed annotations %v, got %v", expected, snapshot.CertSecrets[0].Annotations)
+	}
+
+	if content, err := json.Marshal(snapshot); err != nil || bytes.Contains(content, []byte(base64.StdEncoding.EncodeToString([]byte("cert")))) {
+		t.Errorf("expected no secret data in the snapshot: %s", content)
+	}
+	archive := &bytes.Buffer{}
+	if err := WriteArchive(archive, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := ReadArchive(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Options.EncryptionKeysNamespace != "openshift-config-managed" || len(restored.Revisions) != 2 || restored.EncryptionKeys[0].DataHash != snapshot.EncryptionKeys[0].DataHash {
+		t.Fatalf("unexpected snapshot read from the archive %#v", restored)
+	}
+
+	verification, err := Verify(context.TODO(), kubeClient, operatorClient, restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verification.Err(); err != nil {
+		t.Fatalf("expected the cluster to match its snapshot: %v", err)
+	}
+
+	// restore an older cluster with a rotated encryption key and certificate
+	olderKey := encryptionKey.DeepCopy()
+	olderKey.Data[encryptionsecrets.EncryptionSecretKeyDataKey] = []byte("older key")
+	rotatedCert := certSecret.DeepCopy()
+	rotatedCert.Data["tls.crt"] = []byte("rotated cert")
+	olderClient := fake.NewSimpleClientset(revisionStatus("1"), olderKey, rotatedCert)
+	olderOperatorClient := v1helpers.NewFakeStaticPodOperatorClient(
+		&operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{ObservedConfig: runtime.RawExtension{Raw: []byte(`{"b":2}`)}}},
+		&operatorv1.StaticPodOperatorStatus{OperatorStatus: operatorv1.OperatorStatus{LatestAvailableRevision: 1}},
+		nil, nil,
+	)
+	verification, err = Verify(context.TODO(), olderClient, olderOperatorClient, restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, mismatch := range verification.Mismatches {
+		kinds = append(kinds, mismatch.Kind)
+	}
+	if expected := []string{"ObservedConfig", "Revision", "Revision", "EncryptionKey"}; !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("expected the mismatches %v, got %v", expected, verification.Mismatches)
+	}
+}
+
+func TestReadArchiveInvalid(t *testing.T) {
+	if _, err := ReadArchive(bytes.NewBufferString("not an archive")); err == nil {
+		t.Error("expected an invalid archive to be rejected")
+	}
+}
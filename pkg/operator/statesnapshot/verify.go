@@ -0,0 +1,125 @@
+package statesnapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// Mismatch is a difference between a restored cluster and the snapshot.
+type Mismatch struct {
+	// Kind is the kind of the state: ObservedConfig, Revision, EncryptionKey or CertSecret.
+	Kind    string `json:"kind"`
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
+
+func (m Mismatch) String() string {
+	if len(m.Name) == 0 {
+		return fmt.Sprintf("%s: %s", m.Kind, m.Message)
+	}
+	return fmt.Sprintf("%s %s: %s", m.Kind, m.Name, m.Message)
+}
+
+// Verification is the result of Verify.
+type Verification struct {
+	// Actual is the snapshot of the restored cluster.
+	Actual     *Snapshot  `json:"actual"`
+	Mismatches []Mismatch `json:"mismatches,omitempty"`
+}
+
+// Err returns the mismatches as an error, nil when the restored cluster matches the snapshot.
+func (v *Verification) Err() error {
+	var errs []error
+	for _, mismatch := range v.Mismatches {
+		errs = append(errs, fmt.Errorf("%s", mismatch))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// Verify checks a restored cluster against the snapshot, with the options of the snapshot. The restored cluster
+// matches when it has the observed config, the revisions and the encryption keys of the snapshot, and the certificate
+// secrets still exist. The newer revisions and the rotated certificates are expected after a restore.
+func Verify(ctx context.Context, kubeClient kubernetes.Interface, operatorClient v1helpers.OperatorClient, expected *Snapshot) (*Verification, error) {
+	actual, err := Take(ctx, kubeClient, operatorClient, expected.Options)
+	if err != nil {
+		return nil, err
+	}
+	return &Verification{Actual: actual, Mismatches: compare(expected, actual)}, nil
+}
+
+// compare returns the mismatches of the actual snapshot.
+func compare(expected, actual *Snapshot) []Mismatch {
+	var mismatches []Mismatch
+
+	if equal, err := jsonEqual(expected.ObservedConfig.Raw, actual.ObservedConfig.Raw); err != nil {
+		mismatches = append(mismatches, Mismatch{Kind: "ObservedConfig", Message: err.Error()})
+	} else if !equal {
+		mismatches = append(mismatches, Mismatch{Kind: "ObservedConfig", Message: "differs from the snapshot"})
+	}
+
+	if actual.LatestAvailableRevision < expected.LatestAvailableRevision {
+		mismatches = append(mismatches, Mismatch{
+			Kind:    "Revision",
+			Message: fmt.Sprintf("latest available revision %d is older than the revision %d of the snapshot", actual.LatestAvailableRevision, expected.LatestAvailableRevision),
+		})
+	}
+	actualRevisions := map[int32]Revision{}
+	for _, revision := range actual.Revisions {
+		actualRevisions[revision.Revision] = revision
+	}
+	for _, revision := range expected.Revisions {
+		if _, ok := actualRevisions[revision.Revision]; !ok {
+			mismatches = append(mismatches, Mismatch{Kind: "Revision", Name: fmt.Sprintf("%d", revision.Revision), Message: "missing"})
+		}
+	}
+
+	actualKeys := secretsByName(actual.EncryptionKeys)
+	for _, key := range expected.EncryptionKeys {
+		actualKey, ok := actualKeys[key.Namespace+"/"+key.Name]
+		switch {
+		case !ok:
+			mismatches = append(mismatches, Mismatch{Kind: "EncryptionKey", Name: key.Namespace + "/" + key.Name, Message: "missing, the resources encrypted with it cannot be read"})
+		case actualKey.DataHash != key.DataHash:
+			mismatches = append(mismatches, Mismatch{Kind: "EncryptionKey", Name: key.Namespace + "/" + key.Name, Message: "differs from the snapshot, the resources encrypted with it cannot be read"})
+		}
+	}
+
+	actualCertSecrets := secretsByName(actual.CertSecrets)
+	for _, secret := range expected.CertSecrets {
+		if _, ok := actualCertSecrets[secret.Namespace+"/"+secret.Name]; !ok {
+			mismatches = append(mismatches, Mismatch{Kind: "CertSecret", Name: secret.Namespace + "/" + secret.Name, Message: "missing"})
+		}
+	}
+	return mismatches
+}
+
+func secretsByName(secrets []SecretMetadata) map[string]SecretMetadata {
+	byName := map[string]SecretMetadata{}
+	for _, secret := range secrets {
+		byName[secret.Namespace+"/"+secret.Name] = secret
+	}
+	return byName
+}
+
+// jsonEqual compares two JSON documents semantically, an empty document equals null.
+func jsonEqual(a, b []byte) (bool, error) {
+	var aValue, bValue interface{}
+	if len(a) > 0 {
+		if err := json.Unmarshal(a, &aValue); err != nil {
+			return false, fmt.Errorf("invalid snapshot: %w", err)
+		}
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &bValue); err != nil {
+			return false, err
+		}
+	}
+	return reflect.DeepEqual(aValue, bValue), nil
+}
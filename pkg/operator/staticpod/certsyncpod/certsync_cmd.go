@@ -2,6 +2,7 @@ package certsyncpod
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"time"
 
@@ -29,6 +30,9 @@ type CertSyncControllerOptions struct {
 
 	kubeClient            kubernetes.Interface
 	tlsServerNameOverride string
+
+	reloadPIDFile string
+	reloadCommand string
 }
 
 func NewCertSyncControllerCommand(configmaps, secrets []installer.UnrevisionedResource) *cobra.Command {
@@ -53,6 +57,8 @@ func NewCertSyncControllerCommand(configmaps, secrets []installer.UnrevisionedRe
 	cmd.Flags().StringVarP(&o.Namespace, "namespace", "n", o.Namespace, "Namespace to read from (default to 'POD_NAMESPACE' environment variable)")
 	cmd.Flags().StringVar(&o.KubeConfigFile, "kubeconfig", o.KubeConfigFile, "Location of the master configuration file to run from.")
 	cmd.Flags().StringVar(&o.tlsServerNameOverride, "tls-server-name-override", o.tlsServerNameOverride, "Server name override used by TLS to negotiate the serving cert via SNI.")
+	cmd.Flags().StringVar(&o.reloadPIDFile, "reload-pid-file", o.reloadPIDFile, "File with the PID of the operand process to send SIGHUP to after certificates were updated. Requires a shared process namespace.")
+	cmd.Flags().StringVar(&o.reloadCommand, "reload-command", o.reloadCommand, "Shell command to run after certificates were updated, e.g. to make the operand reload them.")
 
 	return cmd
 }
@@ -89,6 +95,14 @@ func (o *CertSyncControllerOptions) Run() error {
 			Name:       os.Getenv("POD_NAME"),
 		})
 
+	var options []CertSyncControllerOption
+	switch {
+	case len(o.reloadPIDFile) > 0:
+		options = append(options, WithReloadHook(SignalReloadHook(o.reloadPIDFile)))
+	case len(o.reloadCommand) > 0:
+		options = append(options, WithReloadHook(ExecReloadHook("/bin/sh", "-c", o.reloadCommand)))
+	}
+
 	controller := NewCertSyncController(
 		o.DestinationDir,
 		o.Namespace,
@@ -97,6 +111,7 @@ func (o *CertSyncControllerOptions) Run() error {
 		o.kubeClient,
 		kubeInformers,
 		eventRecorder,
+		options...,
 	)
 
 	// start everything. WithInformers start after they have been requested.
@@ -116,6 +131,10 @@ func (o *CertSyncControllerOptions) Complete() error {
 		return err
 	}
 
+	if len(o.reloadPIDFile) > 0 && len(o.reloadCommand) > 0 {
+		return fmt.Errorf("--reload-pid-file and --reload-command are mutually exclusive")
+	}
+
 	if len(o.Namespace) == 0 && len(os.Getenv("POD_NAMESPACE")) > 0 {
 		o.Namespace = os.Getenv("POD_NAMESPACE")
 	}
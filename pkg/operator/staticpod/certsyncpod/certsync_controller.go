@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -32,9 +33,26 @@ type CertSyncController struct {
 	secretGetter    corev1interface.SecretInterface
 	secretLister    v1.SecretLister
 	eventRecorder   events.Recorder
+
+	reloadHook ReloadHook
+	// reloadPending is set until the reload hook succeeded after files were written or removed
+	reloadPending bool
+	now           func() time.Time
+}
+
+// CertSyncControllerOption configures optional behaviour of the CertSyncController.
+type CertSyncControllerOption func(*CertSyncController)
+
+// WithReloadHook calls the hook after synced files were written or removed, e.g. SignalReloadHook or ExecReloadHook.
+func WithReloadHook(hook ReloadHook) CertSyncControllerOption {
+	return func(c *CertSyncController) {
+		c.reloadHook = hook
+	}
 }
 
-func NewCertSyncController(targetDir, targetNamespace string, configmaps, secrets []installer.UnrevisionedResource, kubeClient kubernetes.Interface, informers informers.SharedInformerFactory, eventRecorder events.Recorder) factory.Controller {
+// NewCertSyncController returns a controller writing the configmaps and secrets to the target directory. The source of
+// each written file is recorded in the StateFileName file of the target directory.
+func NewCertSyncController(targetDir, targetNamespace string, configmaps, secrets []installer.UnrevisionedResource, kubeClient kubernetes.Interface, informers informers.SharedInformerFactory, eventRecorder events.Recorder, options ...CertSyncControllerOption) factory.Controller {
 	c := &CertSyncController{
 		destinationDir: targetDir,
 		namespace:      targetNamespace,
@@ -46,6 +64,10 @@ func NewCertSyncController(targetDir, targetNamespace string, configmaps, secret
 		configMapLister: informers.Core().V1().ConfigMaps().Lister(),
 		secretLister:    informers.Core().V1().Secrets().Lister(),
 		secretGetter:    kubeClient.CoreV1().Secrets(targetNamespace),
+		now:             time.Now,
+	}
+	for _, option := range options {
+		option(c)
 	}
 
 	return factory.New().
@@ -70,6 +92,8 @@ func getSecretDir(targetDir, secretName string) string {
 
 func (c *CertSyncController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
 	errors := []error{}
+	state := newSyncStateTracker(c.destinationDir, c.now())
+	filesChanged := false
 
 	klog.Infof("Syncing configmaps: %v", c.configMaps)
 	for _, cm := range c.configMaps {
@@ -104,6 +128,8 @@ func (c *CertSyncController) sync(ctx context.Context, syncCtx factory.SyncConte
 				c.eventRecorder.Warningf("CertificateUpdateFailed", "Failed removing file for configmap: %s/%s: %v", c.namespace, cm.Name, err)
 				errors = append(errors, err)
 			}
+			state.removed("configmaps", cm.Name)
+			filesChanged = true
 			c.eventRecorder.Eventf("CertificateRemoved", "Removed file for configmap: %s/%s", c.namespace, cm.Name)
 			continue
 
@@ -132,6 +158,9 @@ func (c *CertSyncController) sync(ctx context.Context, syncCtx factory.SyncConte
 
 		// Check if cached configmap differs
 		if reflect.DeepEqual(configMap.Data, data) {
+			for filename, content := range data {
+				state.inSync("configmaps", configMap.Name, filename, configMap.ResourceVersion, []byte(content))
+			}
 			continue
 		}
 
@@ -149,6 +178,9 @@ func (c *CertSyncController) sync(ctx context.Context, syncCtx factory.SyncConte
 		// Check if the live configmap differs
 		if reflect.DeepEqual(configMap.Data, data) {
 			klog.Infof("Caches are stale. The live configmap '%s/%s' is reflected on filesystem, but cached one differs", configMap.Namespace, configMap.Name)
+			for filename, content := range data {
+				state.inSync("configmaps", configMap.Name, filename, configMap.ResourceVersion, []byte(content))
+			}
 			continue
 		}
 
@@ -171,6 +203,8 @@ func (c *CertSyncController) sync(ctx context.Context, syncCtx factory.SyncConte
 				errors = append(errors, err)
 				continue
 			}
+			state.written("configmaps", configMap.Name, filename, configMap.ResourceVersion, []byte(content))
+			filesChanged = true
 		}
 		c.eventRecorder.Eventf("CertificateUpdated", "Wrote updated configmap: %s/%s", configMap.Namespace, configMap.Name)
 	}
@@ -209,6 +243,8 @@ func (c *CertSyncController) sync(ctx context.Context, syncCtx factory.SyncConte
 				errors = append(errors, err)
 				continue
 			}
+			state.removed("secrets", s.Name)
+			filesChanged = true
 			c.eventRecorder.Warningf("CertificateRemoved", "Removed file for missing secret: %s/%s", c.namespace, s.Name)
 			continue
 
@@ -237,6 +273,9 @@ func (c *CertSyncController) sync(ctx context.Context, syncCtx factory.SyncConte
 
 		// Check if cached secret differs
 		if reflect.DeepEqual(secret.Data, data) {
+			for filename, content := range data {
+				state.inSync("secrets", secret.Name, filename, secret.ResourceVersion, content)
+			}
 			continue
 		}
 
@@ -254,6 +293,9 @@ func (c *CertSyncController) sync(ctx context.Context, syncCtx factory.SyncConte
 		// Check if the live secret differs
 		if reflect.DeepEqual(secret.Data, data) {
 			klog.Infof("Caches are stale. The live secret '%s/%s' is reflected on filesystem, but cached one differs", secret.Namespace, secret.Name)
+			for filename, content := range data {
+				state.inSync("secrets", secret.Name, filename, secret.ResourceVersion, content)
+			}
 			continue
 		}
 
@@ -277,9 +319,28 @@ func (c *CertSyncController) sync(ctx context.Context, syncCtx factory.SyncConte
 				errors = append(errors, err)
 				continue
 			}
+			state.written("secrets", secret.Name, filename, secret.ResourceVersion, content)
+			filesChanged = true
 		}
 		c.eventRecorder.Eventf("CertificateUpdated", "Wrote updated secret: %s/%s", secret.Namespace, secret.Name)
 	}
 
+	if state.changed {
+		if err := state.state.write(c.destinationDir); err != nil {
+			klog.Warningf("Failed writing the state of synced files: %v", err)
+		}
+	}
+
+	if c.reloadHook != nil && (filesChanged || c.reloadPending) {
+		if err := c.reloadHook(ctx); err != nil {
+			c.eventRecorder.Warningf("CertificateReloadFailed", "Failed reloading certificates: %v", err)
+			errors = append(errors, err)
+			c.reloadPending = true
+		} else {
+			c.eventRecorder.Event("CertificateReloaded", "Reloaded certificates")
+			c.reloadPending = false
+		}
+	}
+
 	return utilerrors.NewAggregate(errors)
 }
@@ -0,0 +1,92 @@
+package certsyncpod
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/staticpod/controller/installer"
+)
+
+func TestCertSyncControllerStateAndReload(t *testing.T) {
+	destinationDir := t.TempDir()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "operand", Name: "serving-cert", ResourceVersion: "1"},
+		Data:       map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")},
+	}
+	kubeClient := fake.NewSimpleClientset(secret)
+	kubeInformers := informers.NewSharedInformerFactoryWithOptions(kubeClient, 0, informers.WithNamespace("operand"))
+	secretIndexer := kubeInformers.Core().V1().Secrets().Informer().GetIndexer()
+	if err := secretIndexer.Add(secret); err != nil {
+		t.Fatal(err)
+	}
+
+	reloads := 0
+	recorder := events.NewInMemoryRecorder("test")
+	c := NewCertSyncController(destinationDir, "operand", nil, []installer.UnrevisionedResource{{Name: "serving-cert"}}, kubeClient, kubeInformers, recorder,
+		WithReloadHook(func(ctx context.Context) error {
+			reloads++
+			return nil
+		}),
+	)
+	syncContext := factory.NewSyncContext("test", recorder)
+	sync := func() {
+		t.Helper()
+		if err := c.Sync(context.TODO(), syncContext); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sync()
+	content, err := os.ReadFile(filepath.Join(destinationDir, "secrets", "serving-cert", "tls.crt"))
+	if err != nil || string(content) != "cert" {
+		t.Fatalf("expected the certificate to be written, got %q: %v", content, err)
+	}
+	state, err := ReadSyncState(destinationDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := state.Files[filepath.Join("secrets", "serving-cert", "tls.crt")]
+	if record.ResourceVersion != "1" || len(record.SHA256) == 0 || record.SyncedTime.IsZero() {
+		t.Errorf("unexpected record %#v", record)
+	}
+	if reloads != 1 {
+		t.Errorf("expected one reload, got %d", reloads)
+	}
+
+	// nothing changed, no reload
+	sync()
+	if reloads != 1 {
+		t.Errorf("expected no reload without changes, got %d", reloads)
+	}
+
+	// rotated
+	secret = secret.DeepCopy()
+	secret.ResourceVersion = "2"
+	secret.Data["tls.crt"] = []byte("rotated")
+	if _, err := kubeClient.CoreV1().Secrets("operand").Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := secretIndexer.Update(secret); err != nil {
+		t.Fatal(err)
+	}
+	sync()
+	state, err = ReadSyncState(destinationDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record := state.Files[filepath.Join("secrets", "serving-cert", "tls.crt")]; record.ResourceVersion != "2" {
+		t.Errorf("expected the rotated certificate to be recorded, got %#v", record)
+	}
+	if reloads != 2 {
+		t.Errorf("expected a reload after rotation, got %d", reloads)
+	}
+}
@@ -0,0 +1,131 @@
+package certsyncpod
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/staticpod"
+)
+
+// StateFileName is the file in the destination directory recording the source of each synced file.
+const StateFileName = ".cert-sync-state.json"
+
+var fileLastSyncMetric = metrics.NewGaugeVec(&metrics.GaugeOpts{
+	Subsystem:      "cert_sync",
+	Name:           "file_last_sync_timestamp_seconds",
+	Help:           "Unix timestamp of the last time the synced file was seen matching its source configmap or secret. The difference to the current time is the staleness of the file.",
+	StabilityLevel: metrics.ALPHA,
+}, []string{"resource", "name", "file"})
+
+func init() {
+	(&sync.Once{}).Do(func() {
+		legacyregistry.MustRegister(fileLastSyncMetric)
+	})
+}
+
+// FileRecord describes the source of a synced file.
+type FileRecord struct {
+	// ResourceVersion of the configmap or secret the file was written from.
+	ResourceVersion string `json:"resourceVersion"`
+	// SHA256 of the file content.
+	SHA256 string `json:"sha256"`
+	// SyncedTime is when the file was written.
+	SyncedTime time.Time `json:"syncedTime"`
+}
+
+// SyncState maps the paths of the synced files, relative to the destination directory, to their records.
+type SyncState struct {
+	Files map[string]FileRecord `json:"files"`
+}
+
+// ReadSyncState reads the records of the files synced to the destination directory. A missing state file results in an
+// empty state.
+func ReadSyncState(destinationDir string) (*SyncState, error) {
+	state := &SyncState{Files: map[string]FileRecord{}}
+	content, err := os.ReadFile(filepath.Join(destinationDir, StateFileName))
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", StateFileName, err)
+	}
+	if state.Files == nil {
+		state.Files = map[string]FileRecord{}
+	}
+	return state, nil
+}
+
+func (s *SyncState) write(destinationDir string) error {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(destinationDir, 0755); err != nil && !os.IsExist(err) {
+		return err
+	}
+	return staticpod.WriteFileAtomic(content, 0644, filepath.Join(destinationDir, StateFileName))
+}
+
+// syncStateTracker records the synced files during a sync and reports their staleness.
+type syncStateTracker struct {
+	state   *SyncState
+	changed bool
+	now     time.Time
+}
+
+func newSyncStateTracker(destinationDir string, now time.Time) *syncStateTracker {
+	state, err := ReadSyncState(destinationDir)
+	if err != nil {
+		klog.Warningf("Discarding the state of synced files: %v", err)
+		state = &SyncState{Files: map[string]FileRecord{}}
+	}
+	return &syncStateTracker{state: state, now: now}
+}
+
+// inSync records that the file matches its source, and records the source if it is not known yet, e.g. for files
+// written before the state was tracked.
+func (t *syncStateTracker) inSync(resource, name, filename, resourceVersion string, content []byte) {
+	path := filepath.Join(resource, name, filename)
+	hash := fmt.Sprintf("%x", sha256.Sum256(content))
+	if record, ok := t.state.Files[path]; !ok || record.SHA256 != hash {
+		t.state.Files[path] = FileRecord{ResourceVersion: resourceVersion, SHA256: hash, SyncedTime: t.now}
+		t.changed = true
+	}
+	fileLastSyncMetric.WithLabelValues(resource, name, filename).Set(float64(t.now.Unix()))
+}
+
+// written records that the file was written from the source.
+func (t *syncStateTracker) written(resource, name, filename, resourceVersion string, content []byte) {
+	t.state.Files[filepath.Join(resource, name, filename)] = FileRecord{
+		ResourceVersion: resourceVersion,
+		SHA256:          fmt.Sprintf("%x", sha256.Sum256(content)),
+		SyncedTime:      t.now,
+	}
+	t.changed = true
+	fileLastSyncMetric.WithLabelValues(resource, name, filename).Set(float64(t.now.Unix()))
+}
+
+// removed forgets the files of a removed configmap or secret.
+func (t *syncStateTracker) removed(resource, name string) {
+	prefix := filepath.Join(resource, name) + string(filepath.Separator)
+	for path := range t.state.Files {
+		if strings.HasPrefix(path, prefix) {
+			delete(t.state.Files, path)
+			t.changed = true
+		}
+	}
+	fileLastSyncMetric.DeletePartialMatch(map[string]string{"resource": resource, "name": name})
+}
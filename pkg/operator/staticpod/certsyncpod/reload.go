@@ -0,0 +1,41 @@
+package certsyncpod
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ReloadHook is called after synced files were written or removed, so that the operand can reload its certificates
+// without a restart.
+type ReloadHook func(ctx context.Context) error
+
+// SignalReloadHook sends SIGHUP to the process whose PID is written in the given file. The containers of the static pod
+// must share their process namespace.
+func SignalReloadHook(pidFile string) ReloadHook {
+	return func(ctx context.Context) error {
+		content, err := os.ReadFile(pidFile)
+		if err != nil {
+			return err
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+		if err != nil {
+			return fmt.Errorf("invalid PID in %s: %w", pidFile, err)
+		}
+		return signalReload(pid)
+	}
+}
+
+// ExecReloadHook runs the given command.
+func ExecReloadHook(command ...string) ReloadHook {
+	return func(ctx context.Context) error {
+		output, err := exec.CommandContext(ctx, command[0], command[1:]...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%q failed: %w: %s", strings.Join(command, " "), err, output)
+		}
+		return nil
+	}
+}
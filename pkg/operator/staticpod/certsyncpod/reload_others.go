@@ -0,0 +1,11 @@
+//go:build !unix
+// +build !unix
+
+package certsyncpod
+
+import "fmt"
+
+// signalReload is not supported on this platform
+func signalReload(pid int) error {
+	return fmt.Errorf("signaling process %d is not supported on this platform", pid)
+}
@@ -0,0 +1,11 @@
+//go:build unix
+// +build unix
+
+package certsyncpod
+
+import "syscall"
+
+// signalReload sends SIGHUP to the process.
+func signalReload(pid int) error {
+	return syscall.Kill(pid, syscall.SIGHUP)
+}
@@ -5,6 +5,8 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+
+	"github.com/openshift/library-go/pkg/config/clusterstatus"
 )
 
 // NewIsSingleNodePlatformFn returns a function that checks if the cluster topology is single node (aka. SNO)
@@ -31,3 +33,19 @@ func NewIsSingleNodePlatformFn(infraInformer configv1informers.InfrastructureInf
 		return infraData.Status.ControlPlaneTopology == configv1.SingleReplicaTopologyMode, true, nil
 	}
 }
+
+// NewNotHostedControlPlaneFn wraps a conditional function like the one returned by NewIsSingleNodePlatformFn, so that it
+// returns false in hosted control planes, where the operands do not run as static pods on the nodes of the cluster.
+// It is meant to gate controllers like the guard controller.
+func NewNotHostedControlPlaneFn(deploymentMode clusterstatus.DeploymentModeFunc, conditionalFn func() (bool, bool, error)) func() (result, preconditionFulfilled bool, err error) {
+	return func() (bool, bool, error) {
+		mode, err := deploymentMode()
+		if err != nil {
+			return false, false, err
+		}
+		if mode.IsHosted() {
+			return false, true, nil
+		}
+		return conditionalFn()
+	}
+}
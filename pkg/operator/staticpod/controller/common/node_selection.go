@@ -0,0 +1,127 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	corelisterv1 "k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	// MasterNodeRole is the role of the control plane nodes.
+	MasterNodeRole = "master"
+	// ArbiterNodeRole is the role of the arbiter node of two node clusters with an arbiter (2+1), which runs a subset
+	// of the control plane, e.g. an etcd member, to preserve quorum.
+	ArbiterNodeRole = "arbiter"
+
+	nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+)
+
+// ControlPlaneNodeSelector selects the nodes static pods are installed on. The zero value selects the master nodes.
+type ControlPlaneNodeSelector struct {
+	// Roles of the selected nodes, a node with any of the roles is selected. Empty means MasterNodeRole.
+	Roles []string
+	// ExcludeLabels are keys of labels excluding a node, whatever their value.
+	ExcludeLabels []string
+	// RequireQuorum makes ValidateQuorum reject selections that cannot form a fault tolerant quorum, for etcd-like
+	// operands.
+	RequireQuorum bool
+}
+
+func (s ControlPlaneNodeSelector) roles() []string {
+	if len(s.Roles) == 0 {
+		return []string{MasterNodeRole}
+	}
+	return s.Roles
+}
+
+// Select returns the selected nodes sorted by name.
+func (s ControlPlaneNodeSelector) Select(nodeLister corelisterv1.NodeLister) ([]*corev1.Node, error) {
+	byName := map[string]*corev1.Node{}
+	for _, role := range s.roles() {
+		selector := labels.NewSelector()
+		requirement, err := labels.NewRequirement(nodeRoleLabelPrefix+role, selection.Exists, nil)
+		if err != nil {
+			return nil, err
+		}
+		selector = selector.Add(*requirement)
+		for _, key := range s.ExcludeLabels {
+			requirement, err := labels.NewRequirement(key, selection.DoesNotExist, nil)
+			if err != nil {
+				return nil, err
+			}
+			selector = selector.Add(*requirement)
+		}
+
+		nodes, err := nodeLister.List(selector)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			byName[node.Name] = node
+		}
+	}
+
+	nodes := make([]*corev1.Node, 0, len(byName))
+	for _, node := range byName {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes, nil
+}
+
+// NodeAffinity returns the node affinity of pods running on the selected nodes.
+func (s ControlPlaneNodeSelector) NodeAffinity() *corev1.NodeAffinity {
+	terms := make([]corev1.NodeSelectorTerm, 0, len(s.roles()))
+	for _, role := range s.roles() {
+		term := corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+			{Key: nodeRoleLabelPrefix + role, Operator: corev1.NodeSelectorOpExists},
+		}}
+		for _, key := range s.ExcludeLabels {
+			term.MatchExpressions = append(term.MatchExpressions, corev1.NodeSelectorRequirement{Key: key, Operator: corev1.NodeSelectorOpDoesNotExist})
+		}
+		terms = append(terms, term)
+	}
+	return &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: terms},
+	}
+}
+
+// Tolerations returns the tolerations of the NoSchedule taints of the roles of the selected nodes.
+func (s ControlPlaneNodeSelector) Tolerations() []corev1.Toleration {
+	tolerations := make([]corev1.Toleration, 0, len(s.roles()))
+	for _, role := range s.roles() {
+		tolerations = append(tolerations, corev1.Toleration{
+			Key:      nodeRoleLabelPrefix + role,
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		})
+	}
+	return tolerations
+}
+
+// ValidateQuorum returns an error if RequireQuorum is set and the members on the selected nodes cannot form a quorum
+// tolerating the loss of a member, unless there is a single one: two members, or more arbiters than other nodes.
+func (s ControlPlaneNodeSelector) ValidateQuorum(nodes []*corev1.Node) error {
+	if !s.RequireQuorum {
+		return nil
+	}
+	var arbiters int
+	for _, node := range nodes {
+		if _, ok := node.Labels[nodeRoleLabelPrefix+ArbiterNodeRole]; ok {
+			arbiters++
+		}
+	}
+	switch {
+	case len(nodes) == 0:
+		return fmt.Errorf("no control plane nodes selected")
+	case len(nodes) == 2:
+		return fmt.Errorf("2 control plane nodes selected, a quorum of 2 does not tolerate the loss of a node, add an arbiter node")
+	case arbiters > len(nodes)-arbiters:
+		return fmt.Errorf("%d arbiter nodes selected for %d other control plane nodes, arbiters must not outnumber them", arbiters, len(nodes)-arbiters)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package common
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisterv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func makeNode(name string, labels ...string) *corev1.Node {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}}}
+	for _, label := range labels {
+		node.Labels[label] = ""
+	}
+	return node
+}
+
+func TestControlPlaneNodeSelector(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, node := range []*corev1.Node{
+		makeNode("master-0", "node-role.kubernetes.io/master"),
+		makeNode("master-1", "node-role.kubernetes.io/master", "node-role.kubernetes.io/worker"),
+		makeNode("master-2", "node-role.kubernetes.io/master", "example.com/excluded"),
+		makeNode("arbiter-0", "node-role.kubernetes.io/arbiter"),
+		makeNode("worker-0", "node-role.kubernetes.io/worker"),
+	} {
+		if err := indexer.Add(node); err != nil {
+			t.Fatal(err)
+		}
+	}
+	nodeLister := corelisterv1.NewNodeLister(indexer)
+
+	for _, tc := range []struct {
+		name          string
+		selector      ControlPlaneNodeSelector
+		expectedNodes string
+		expectError   string
+	}{
+		{
+			name:          "default",
+			expectedNodes: "master-0,master-1,master-2",
+		},
+		{
+			name:          "arbiter",
+			selector:      ControlPlaneNodeSelector{Roles: []string{MasterNodeRole, ArbiterNodeRole}, RequireQuorum: true},
+			expectedNodes: "arbiter-0,master-0,master-1,master-2",
+		},
+		{
+			name:          "excluded label",
+			selector:      ControlPlaneNodeSelector{ExcludeLabels: []string{"example.com/excluded"}, RequireQuorum: true},
+			expectedNodes: "master-0,master-1",
+			expectError:   "2 control plane nodes",
+		},
+		{
+			name:          "excluded label with arbiter",
+			selector:      ControlPlaneNodeSelector{Roles: []string{MasterNodeRole, ArbiterNodeRole}, ExcludeLabels: []string{"example.com/excluded"}, RequireQuorum: true},
+			expectedNodes: "arbiter-0,master-0,master-1",
+		},
+		{
+			name:          "arbiters only",
+			selector:      ControlPlaneNodeSelector{Roles: []string{ArbiterNodeRole}, RequireQuorum: true},
+			expectedNodes: "arbiter-0",
+			expectError:   "arbiters must not outnumber",
+		},
+		{
+			name:        "nothing selected",
+			selector:    ControlPlaneNodeSelector{Roles: []string{"infra"}, RequireQuorum: true},
+			expectError: "no control plane nodes",
+		},
+		{
+			name:     "quorum not required",
+			selector: ControlPlaneNodeSelector{Roles: []string{"infra"}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			nodes, err := tc.selector.Select(nodeLister)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, node := range nodes {
+				names = append(names, node.Name)
+			}
+			if actual := strings.Join(names, ","); actual != tc.expectedNodes {
+				t.Errorf("expected nodes %q, got %q", tc.expectedNodes, actual)
+			}
+
+			err = tc.selector.ValidateQuorum(nodes)
+			switch {
+			case len(tc.expectError) == 0 && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case len(tc.expectError) > 0 && (err == nil || !strings.Contains(err.Error(), tc.expectError)):
+				t.Errorf("expected error containing %q, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
@@ -16,7 +16,6 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/selection"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/informers"
@@ -31,6 +30,7 @@ import (
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+	"github.com/openshift/library-go/pkg/operator/staticpod/controller/common"
 	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
 )
 
@@ -44,11 +44,12 @@ type GuardController struct {
 	operandPodLabelSelector            labels.Selector
 	pdbUnhealthyPodEvictionPolicy      *v1.UnhealthyPodEvictionPolicyType
 
-	nodeLister corelisterv1.NodeLister
-	podLister  corelisterv1.PodLister
-	podGetter  corev1client.PodsGetter
-	pdbGetter  policyclientv1.PodDisruptionBudgetsGetter
-	pdbLister  policylisterv1.PodDisruptionBudgetLister
+	nodeLister   corelisterv1.NodeLister
+	nodeSelector common.ControlPlaneNodeSelector
+	podLister    corelisterv1.PodLister
+	podGetter    corev1client.PodsGetter
+	pdbGetter    policyclientv1.PodDisruptionBudgetsGetter
+	pdbLister    policylisterv1.PodDisruptionBudgetLister
 
 	// installerPodImageFn returns the image name for the installer pod
 	installerPodImageFn   func() string
@@ -70,6 +71,7 @@ func NewGuardController(
 	pdbGetter policyclientv1.PodDisruptionBudgetsGetter,
 	eventRecorder events.Recorder,
 	createConditionalFunc func() (bool, bool, error),
+	options ...Option,
 ) (factory.Controller, error) {
 	if operandPodLabelSelector == nil {
 		return nil, fmt.Errorf("GuardController: missing required operandPodLabelSelector")
@@ -102,6 +104,9 @@ func NewGuardController(
 		installerPodImageFn:           getInstallerPodImageFromEnv,
 		createConditionalFunc:         createConditionalFunc,
 	}
+	for _, option := range options {
+		option(c)
+	}
 
 	return factory.New().
 		WithInformers(
@@ -116,6 +121,17 @@ func NewGuardController(
 		), nil
 }
 
+// Option configures optional behaviour of the GuardController.
+type Option func(*GuardController)
+
+// WithControlPlaneNodeSelector replaces the default selection of the master nodes the pod disruption budget is sized
+// for, e.g. to include arbiter nodes.
+func WithControlPlaneNodeSelector(nodeSelector common.ControlPlaneNodeSelector) Option {
+	return func(c *GuardController) {
+		c.nodeSelector = nodeSelector
+	}
+}
+
 func getInstallerPodImageFromEnv() string {
 	return os.Getenv("OPERATOR_IMAGE")
 }
@@ -224,11 +240,7 @@ func (c *GuardController) sync(ctx context.Context, syncCtx factory.SyncContext)
 			}
 		}
 	} else {
-		selector, err := labels.NewRequirement("node-role.kubernetes.io/master", selection.Equals, []string{""})
-		if err != nil {
-			panic(err)
-		}
-		nodes, err := c.nodeLister.List(labels.NewSelector().Add(*selector))
+		nodes, err := c.nodeSelector.Select(c.nodeLister)
 		if err != nil {
 			return err
 		}
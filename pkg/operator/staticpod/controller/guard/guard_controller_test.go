@@ -20,6 +20,7 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
 	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/config/clusterstatus"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
 	staticcontrollercommon "github.com/openshift/library-go/pkg/operator/staticpod/controller/common"
@@ -196,6 +197,19 @@ func TestIsSNOCheckFnc(t *testing.T) {
 	}
 }
 
+func TestNotHostedControlPlaneFn(t *testing.T) {
+	isSNO := func() (bool, bool, error) { return true, true, nil }
+	for mode, expected := range map[clusterstatus.DeploymentMode]bool{
+		clusterstatus.StandaloneDeploymentMode:         true,
+		clusterstatus.HostedControlPlaneDeploymentMode: false,
+	} {
+		result, precheckSucceeded, err := staticcontrollercommon.NewNotHostedControlPlaneFn(clusterstatus.StaticDeploymentMode(mode), isSNO)()
+		if err != nil || !precheckSucceeded || result != expected {
+			t.Errorf("%s: expected %v, got %v, precheckSucceeded %v: %v", mode, expected, result, precheckSucceeded, err)
+		}
+	}
+}
+
 func fakeMasterNode(name string) *corev1.Node {
 	n := &corev1.Node{
 		ObjectMeta: metav1.ObjectMeta{
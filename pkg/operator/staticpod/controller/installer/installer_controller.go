@@ -3,6 +3,7 @@ package installer
 import (
 	"context"
 	_ "embed"
+	"errors"
 	"fmt"
 	"math"
 	"os"
@@ -15,6 +16,7 @@ import (
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	"github.com/openshift/library-go/pkg/config/clusterstatus"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/condition"
 	"github.com/openshift/library-go/pkg/operator/events"
@@ -48,6 +50,9 @@ const (
 	nodeStatusOperandFailedReason         = "OperandFailed"
 	nodeStatusInstalledFailedReason       = "InstallerFailed"
 	nodeStatusOperandFailedFallbackReason = "OperandFailedFallback"
+
+	// preconditionRetryInterval is how often an unmet precondition is checked again
+	preconditionRetryInterval = 30 * time.Second
 )
 
 //go:embed manifests/installer-pod.yaml
@@ -98,6 +103,11 @@ type InstallerController struct {
 
 	startupMonitorEnabled func() (bool, error)
 
+	deploymentMode clusterstatus.DeploymentModeFunc
+
+	// precondition must be met before a node is moved to a new revision
+	precondition NodePreconditionFunc
+
 	factory          *factory.Factory
 	clock            clock.Clock
 	installerBackOff func(count int) time.Duration
@@ -132,6 +142,39 @@ func (c *InstallerController) WithStartupMonitorSupport(startupMonitorEnabled fu
 	return c
 }
 
+// WithDeploymentMode sets the function called on every sync to know whether the control plane
+// is hosted. Hosted control planes have no control plane nodes to install static pods on,
+// so no installer pods are created.
+func (c *InstallerController) WithDeploymentMode(deploymentMode clusterstatus.DeploymentModeFunc) *InstallerController {
+	c.deploymentMode = deploymentMode
+	return c
+}
+
+// NodePreconditionFunc checks whether the node can be moved to a new revision. When it cannot, it returns false and a
+// message explaining why.
+type NodePreconditionFunc func(ctx context.Context, nodeName string) (bool, string, error)
+
+// preconditionNotMetError is returned by manageInstallationPods when the precondition blocks moving a node to a new
+// revision.
+type preconditionNotMetError struct {
+	nodeName string
+	revision int32
+	message  string
+}
+
+func (e *preconditionNotMetError) Error() string {
+	return fmt.Sprintf("waiting to roll out revision %d to node %q: %s", e.revision, e.nodeName, e.message)
+}
+
+// WithPrecondition sets a precondition that must be met before a node is moved to a new revision, e.g. a
+// quorum.NewQuorumPrecondition for etcd-like operands. Installations in progress are not affected. While the
+// precondition is not met, NodeInstallerProgressing has the PreconditionNotMet reason with the message of the
+// precondition.
+func (c *InstallerController) WithPrecondition(precondition NodePreconditionFunc) *InstallerController {
+	c.precondition = precondition
+	return c
+}
+
 // staticPodState is the status of a static pod that has been installed to a node.
 type staticPodState int
 
@@ -439,6 +482,7 @@ func (c *InstallerController) timeToWaitBeforeInstallingNextPod(ctx context.Cont
 // manageInstallationPods takes care of creating content for the static pods to install.
 // returns whether or not requeue and if an error happened when updating status.  Normally it updates status itself.
 func (c *InstallerController) manageInstallationPods(ctx context.Context, operatorSpec *operatorv1.StaticPodOperatorSpec, originalOperatorStatus *operatorv1.StaticPodOperatorStatus) (bool, time.Duration, *operatorv1.NodeStatus, func(), error) {
+	logger := klog.FromContext(ctx)
 	operatorStatus := originalOperatorStatus.DeepCopy()
 
 	if len(operatorStatus.NodeStatuses) == 0 {
@@ -483,7 +527,7 @@ func (c *InstallerController) manageInstallationPods(ctx context.Context, operat
 					}
 					earliestRetry := currNodeState.LastFailedTime.Add(delay)
 					if !c.now().After(earliestRetry) {
-						klog.V(4).Infof("Backing off node %s installer retry %d until %v", currNodeState.NodeName, currNodeState.LastFailedCount+1, earliestRetry)
+						logger.V(4).Info("Backing off installer retry", "node", currNodeState.NodeName, "retry", currNodeState.LastFailedCount+1, "until", earliestRetry)
 						return true, earliestRetry.Sub(c.now()), nil, nil, nil
 					}
 				}
@@ -503,17 +547,20 @@ func (c *InstallerController) manageInstallationPods(ctx context.Context, operat
 				return true, 0, nil, nil, err
 			}
 			if newCurrNodeState.LastFailedReason == nodeStatusInstalledFailedReason && newCurrNodeState.LastFailedCount != currNodeState.LastFailedCount {
-				klog.Infof("Will retry %q for revision %d for the %s time because %s", currNodeState.NodeName, currNodeState.TargetRevision, nthTimeOr1st(newCurrNodeState.LastFailedCount), reason)
+				logger.Info(fmt.Sprintf("Will retry for the %s time", nthTimeOr1st(newCurrNodeState.LastFailedCount)), "node", currNodeState.NodeName, "revision", currNodeState.TargetRevision, "reason", reason)
 			}
 			if newCurrNodeState.LastFailedReason == nodeStatusOperandFailedFallbackReason && newCurrNodeState.LastFallbackCount != currNodeState.LastFallbackCount {
-				klog.Infof("Will fallback %q for revision %d to last-known-good revision for the %s time because %s", currNodeState.NodeName, currNodeState.TargetRevision, nthTimeOr1st(newCurrNodeState.LastFallbackCount), reason)
+				logger.Info(fmt.Sprintf("Will fallback to last-known-good revision for the %s time", nthTimeOr1st(newCurrNodeState.LastFallbackCount)), "node", currNodeState.NodeName, "revision", currNodeState.TargetRevision, "reason", reason)
 			}
 
 			// if we make a change to this status, we want to write it out to the API before we commence work on the next node.
 			// it's an extra write/read, but it makes the state debuggable from outside this process
 			if !equality.Semantic.DeepEqual(newCurrNodeState, currNodeState) {
-				klog.Infof("%q moving to %v because %s", currNodeState.NodeName, spew.Sdump(*newCurrNodeState), reason)
+				logger.Info("Node moving to new state", "node", currNodeState.NodeName, "state", spew.Sdump(*newCurrNodeState), "reason", reason)
 				nodeCurrentRevisionChangedFn := func() {
+					if newCurrNodeState.LastFailedReason == nodeStatusInstalledFailedReason && newCurrNodeState.LastFailedCount > currNodeState.LastFailedCount {
+						recordFailedInstallerPod(c.targetNamespace, newCurrNodeState.LastFailedRevision)
+					}
 					if currNodeState.CurrentRevision != newCurrNodeState.CurrentRevision {
 						c.eventRecorder.Eventf("NodeCurrentRevisionChanged", "Updated node %q from revision %d to %d because %s", currNodeState.NodeName,
 							currNodeState.CurrentRevision, newCurrNodeState.CurrentRevision, reason)
@@ -522,7 +569,7 @@ func (c *InstallerController) manageInstallationPods(ctx context.Context, operat
 				return false, 0, newCurrNodeState, nodeCurrentRevisionChangedFn, nil
 			}
 
-			klog.V(2).Infof("%q is in transition to %d, but has not made progress because %s", currNodeState.NodeName, currNodeState.TargetRevision, reasonWithBlame(reason))
+			logger.V(2).Info("Node is in transition, but has not made progress", "node", currNodeState.NodeName, "revision", currNodeState.TargetRevision, "reason", reasonWithBlame(reason))
 			return false, 0, nil, nil, nil
 		}
 
@@ -530,11 +577,22 @@ func (c *InstallerController) manageInstallationPods(ctx context.Context, operat
 
 		revisionToStart := c.getRevisionToStart(currNodeState, prevNodeState, operatorStatus)
 		if revisionToStart == 0 {
-			klog.V(4).Infof("%s, but node %s does not need update", nodeChoiceReason, currNodeState.NodeName)
+			logger.V(4).Info(nodeChoiceReason+", but node does not need update", "node", currNodeState.NodeName)
 			continue
 		}
 
-		klog.Infof("%s and needs new revision %d", nodeChoiceReason, revisionToStart)
+		if c.precondition != nil {
+			met, message, err := c.precondition(ctx, currNodeState.NodeName)
+			if err != nil {
+				return true, 0, nil, nil, fmt.Errorf("failed to check the precondition for rolling out revision %d to node %q: %w", revisionToStart, currNodeState.NodeName, err)
+			}
+			if !met {
+				logger.Info(nodeChoiceReason+" and needs new revision, but the precondition is not met", "node", currNodeState.NodeName, "revision", revisionToStart, "reason", message)
+				return true, preconditionRetryInterval, nil, nil, &preconditionNotMetError{nodeName: currNodeState.NodeName, revision: revisionToStart, message: message}
+			}
+		}
+
+		logger.Info(nodeChoiceReason+" and needs new revision", "node", currNodeState.NodeName, "revision", revisionToStart)
 
 		newCurrNodeState := currNodeState.DeepCopy()
 		newCurrNodeState.TargetRevision = revisionToStart
@@ -542,7 +600,7 @@ func (c *InstallerController) manageInstallationPods(ctx context.Context, operat
 		// if we make a change to this status, we want to write it out to the API before we commence work on the next node.
 		// it's an extra write/read, but it makes the state debuggable from outside this process
 		if !equality.Semantic.DeepEqual(newCurrNodeState, currNodeState) {
-			klog.Infof("%q moving to %v", currNodeState.NodeName, spew.Sdump(*newCurrNodeState))
+			logger.Info("Node moving to new state", "node", currNodeState.NodeName, "state", spew.Sdump(*newCurrNodeState))
 
 			nodeTargetRevisionChangedFn := func() {
 				if currNodeState.TargetRevision != newCurrNodeState.TargetRevision && newCurrNodeState.TargetRevision != 0 {
@@ -687,6 +745,21 @@ func prepareNodeInstallerConditionApplyConfiguration(nodeStatuses []*applyoperat
 	return []*applyoperatorv1.OperatorConditionApplyConfiguration{availableCondition, progressingCondition, degradedCondition}
 }
 
+// reportPreconditionNotMet sets the PreconditionNotMet reason on the NodeInstallerProgressing condition, so that a
+// rollout blocked by the precondition is visible in the operator status.
+func reportPreconditionNotMet(conditions []*applyoperatorv1.OperatorConditionApplyConfiguration, notMet *preconditionNotMetError) {
+	for _, c := range conditions {
+		if ptr.Deref(c.Type, "") != condition.NodeInstallerProgressingConditionType {
+			continue
+		}
+		message := notMet.Error()
+		if description := ptr.Deref(c.Message, ""); len(description) > 0 {
+			message = description + "; " + message
+		}
+		c.WithStatus(operatorv1.ConditionTrue).WithReason("PreconditionNotMet").WithMessage(message)
+	}
+}
+
 func prepareInstallerDegradedConditionApplyConfigurationFor(err error) *applyoperatorv1.OperatorConditionApplyConfiguration {
 	installerDegradedCondition := applyoperatorv1.OperatorCondition().
 		WithType(condition.InstallerControllerDegradedConditionType).
@@ -1109,17 +1182,33 @@ func (c InstallerController) Sync(ctx context.Context, syncCtx factory.SyncConte
 		return nil
 	}
 
+	if c.deploymentMode != nil {
+		mode, err := c.deploymentMode()
+		if err != nil {
+			return err
+		}
+		if mode.IsHosted() {
+			klog.V(4).Infof("Not installing static pods in a hosted control plane")
+			return nil
+		}
+	}
+
 	err = c.ensureRequiredResourcesExist(ctx, originalOperatorStatus.LatestAvailableRevision)
 
 	// Only manage installation pods when all required certs are present.
 	var updatedNode *operatorv1.NodeStatus
 	var updatedNodeReportOnSuccessfulUpdateFn func()
+	var preconditionNotMet *preconditionNotMetError
 	if err == nil {
 		var requeue bool
 		var after time.Duration
 		var syncErr error
 		requeue, after, updatedNode, updatedNodeReportOnSuccessfulUpdateFn, syncErr = c.manageInstallationPods(ctx, operatorSpec, operatorStatus)
-		if requeue && syncErr == nil {
+		if errors.As(syncErr, &preconditionNotMet) {
+			// the rollout is blocked rather than failing, which is reported as progressing below
+			syncCtx.Queue().AddAfter(syncCtx.QueueKey(), after)
+			syncErr = nil
+		} else if requeue && syncErr == nil {
 			syncCtx.Queue().AddAfter(syncCtx.QueueKey(), after)
 			return nil
 		}
@@ -1130,6 +1219,9 @@ func (c InstallerController) Sync(ctx context.Context, syncCtx factory.SyncConte
 	// If required certs are missing, this will report degraded as we can't create installer pods because of this pre-condition.
 	nodeStatusApplyConfigurations := prepareNodeStatusApplyConfigurationFor(originalOperatorStatus.NodeStatuses, updatedNode)
 	operatorConditionApplyConfigurations := prepareNodeInstallerConditionApplyConfiguration(nodeStatusApplyConfigurations, originalOperatorStatus.LatestAvailableRevision)
+	if preconditionNotMet != nil {
+		reportPreconditionNotMet(operatorConditionApplyConfigurations, preconditionNotMet)
+	}
 	operatorConditionApplyConfigurations = append(operatorConditionApplyConfigurations, prepareInstallerDegradedConditionApplyConfigurationFor(err))
 	status := applyoperatorv1.StaticPodOperatorStatus().
 		WithConditions(operatorConditionApplyConfigurations...).
@@ -1139,6 +1231,12 @@ func (c InstallerController) Sync(ctx context.Context, syncCtx factory.SyncConte
 	} else if updatedNodeReportOnSuccessfulUpdateFn != nil {
 		updatedNodeReportOnSuccessfulUpdateFn()
 	}
+
+	nodeStatuses := make([]operatorv1.NodeStatus, 0, len(nodeStatusApplyConfigurations))
+	for _, nodeStatus := range nodeStatusApplyConfigurations {
+		nodeStatuses = append(nodeStatuses, *nodeStatusApplyConfigToOperatorNodeStatus(nodeStatus))
+	}
+	recordRevisionMetrics(c.targetNamespace, nodeStatuses, originalOperatorStatus.Conditions, originalOperatorStatus.LatestAvailableRevision, c.now())
 	return err
 }
 
@@ -12,6 +12,7 @@ import (
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	"github.com/openshift/library-go/pkg/config/clusterstatus"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/condition"
 	"github.com/openshift/library-go/pkg/operator/events"
@@ -1163,6 +1164,67 @@ func TestCreateInstallerPod(t *testing.T) {
 	}
 }
 
+func TestCreateInstallerPodHostedControlPlane(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "test-config"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "test-secret"}},
+	)
+
+	var installerPod *corev1.Pod
+	kubeClient.PrependReactor("create", "pods", func(action ktesting.Action) (handled bool, ret runtime.Object, err error) {
+		installerPod = action.(ktesting.CreateAction).GetObject().(*corev1.Pod)
+		return false, nil, nil
+	})
+	kubeInformers := informers.NewSharedInformerFactoryWithOptions(kubeClient, 1*time.Minute, informers.WithNamespace("test"))
+
+	fakeStaticPodOperatorClient := v1helpers.NewFakeStaticPodOperatorClient(
+		&operatorv1.StaticPodOperatorSpec{
+			OperatorSpec: operatorv1.OperatorSpec{
+				ManagementState: operatorv1.Managed,
+			},
+		},
+		&operatorv1.StaticPodOperatorStatus{
+			OperatorStatus: operatorv1.OperatorStatus{
+				LatestAvailableRevision: 1,
+			},
+			NodeStatuses: []operatorv1.NodeStatus{
+				{
+					NodeName: "test-node-1",
+				},
+			},
+		},
+		nil,
+		nil,
+	)
+	eventRecorder := events.NewRecorder(kubeClient.CoreV1().Events("test"), "test-operator", &corev1.ObjectReference{})
+
+	c := NewInstallerController(
+		"unit-test", "test", "test-pod",
+		[]revision.RevisionResource{{Name: "test-config"}},
+		[]revision.RevisionResource{{Name: "test-secret"}},
+		[]string{"/bin/true"},
+		kubeInformers,
+		fakeStaticPodOperatorClient,
+		kubeClient.CoreV1(),
+		kubeClient.CoreV1(),
+		kubeClient.CoreV1(),
+		eventRecorder,
+	).WithDeploymentMode(clusterstatus.StaticDeploymentMode(clusterstatus.HostedControlPlaneDeploymentMode))
+	c.ownerRefsFn = func(ctx context.Context, revision int32) ([]metav1.OwnerReference, error) {
+		return []metav1.OwnerReference{}, nil
+	}
+	c.installerPodImageFn = func() string { return "docker.io/foo/bar" }
+	for i := 0; i < 2; i++ {
+		if err := c.Sync(context.TODO(), factory.NewSyncContext("InstallerController", eventRecorder)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if installerPod != nil {
+		t.Fatalf("expected no installer pod in a hosted control plane")
+	}
+}
+
 func TestEnsureInstallerPod(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -2651,3 +2713,27 @@ func metav1TimestampPtr(s string) *metav1.Time {
 	t := metav1Timestamp(s)
 	return &t
 }
+
+func TestReportPreconditionNotMet(t *testing.T) {
+	nodeStatuses := []*applyoperatorv1.NodeStatusApplyConfiguration{
+		applyoperatorv1.NodeStatus().WithNodeName("node-1").WithCurrentRevision(2),
+		applyoperatorv1.NodeStatus().WithNodeName("node-2").WithCurrentRevision(1),
+	}
+	conditions := prepareNodeInstallerConditionApplyConfiguration(nodeStatuses, 2)
+	reportPreconditionNotMet(conditions, &preconditionNotMetError{nodeName: "node-2", revision: 2, message: "quorum would be lost"})
+
+	for _, c := range conditions {
+		if *c.Type != condition.NodeInstallerProgressingConditionType {
+			if c.Reason != nil && *c.Reason == "PreconditionNotMet" {
+				t.Errorf("unexpected PreconditionNotMet reason on %s", *c.Type)
+			}
+			continue
+		}
+		if *c.Status != operatorv1.ConditionTrue || *c.Reason != "PreconditionNotMet" {
+			t.Errorf("expected progressing with reason PreconditionNotMet, got %s/%s", *c.Status, *c.Reason)
+		}
+		if !strings.Contains(*c.Message, `waiting to roll out revision 2 to node "node-2": quorum would be lost`) {
+			t.Errorf("unexpected message %q", *c.Message)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package installer
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"github.com/openshift/library-go/pkg/operator/condition"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+var (
+	nodeCurrentRevisionMetric = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Subsystem:      "staticpod_installer",
+		Name:           "node_current_revision",
+		Help:           "The revision of the static pods currently running on the node.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"namespace", "node"})
+
+	nodeTargetRevisionMetric = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Subsystem:      "staticpod_installer",
+		Name:           "node_target_revision",
+		Help:           "The revision the node is being moved to, or the current revision when the node is not in transition.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"namespace", "node"})
+
+	progressingDurationMetric = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Subsystem:      "staticpod_installer",
+		Name:           "progressing_seconds",
+		Help:           "Time in seconds since the nodes started progressing to the latest available revision, 0 when all nodes are at the latest revision.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"namespace"})
+
+	failedInstallerPodsMetric = metrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      "staticpod_installer",
+		Name:           "failed_installer_pods_total",
+		Help:           "Number of failed installer pods by revision.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"namespace", "revision"})
+)
+
+func init() {
+	(&sync.Once{}).Do(func() {
+		legacyregistry.MustRegister(nodeCurrentRevisionMetric)
+		legacyregistry.MustRegister(nodeTargetRevisionMetric)
+		legacyregistry.MustRegister(progressingDurationMetric)
+		legacyregistry.MustRegister(failedInstallerPodsMetric)
+	})
+}
+
+var (
+	// reportedNodesLock guards reportedNodes
+	reportedNodesLock sync.Mutex
+	// reportedNodes holds the nodes with revision metrics per namespace, so metrics of removed nodes can be deleted
+	reportedNodes = map[string]sets.Set[string]{}
+)
+
+// recordRevisionMetrics updates the per node revision metrics and the progressing duration. The progressing duration
+// is measured from the last transition of the existing progressing condition, which is only set once the status was written.
+func recordRevisionMetrics(namespace string, nodeStatuses []operatorv1.NodeStatus, conditions []operatorv1.OperatorCondition, latestAvailableRevision int32, now time.Time) {
+	reportedNodesLock.Lock()
+	defer reportedNodesLock.Unlock()
+
+	nodes := sets.New[string]()
+	progressing := false
+	for _, nodeStatus := range nodeStatuses {
+		targetRevision := nodeStatus.TargetRevision
+		if targetRevision == 0 {
+			targetRevision = nodeStatus.CurrentRevision
+		}
+		nodeCurrentRevisionMetric.WithLabelValues(namespace, nodeStatus.NodeName).Set(float64(nodeStatus.CurrentRevision))
+		nodeTargetRevisionMetric.WithLabelValues(namespace, nodeStatus.NodeName).Set(float64(targetRevision))
+		nodes.Insert(nodeStatus.NodeName)
+		if nodeStatus.CurrentRevision != latestAvailableRevision {
+			progressing = true
+		}
+	}
+	for _, removed := range sets.List(reportedNodes[namespace].Difference(nodes)) {
+		nodeCurrentRevisionMetric.Delete(map[string]string{"namespace": namespace, "node": removed})
+		nodeTargetRevisionMetric.Delete(map[string]string{"namespace": namespace, "node": removed})
+	}
+	reportedNodes[namespace] = nodes
+
+	progressingSeconds := 0.0
+	if progressingCondition := v1helpers.FindOperatorCondition(conditions, condition.NodeInstallerProgressingConditionType); progressing &&
+		progressingCondition != nil && progressingCondition.Status == operatorv1.ConditionTrue && !progressingCondition.LastTransitionTime.IsZero() {
+		progressingSeconds = now.Sub(progressingCondition.LastTransitionTime.Time).Seconds()
+	}
+	progressingDurationMetric.WithLabelValues(namespace).Set(progressingSeconds)
+}
+
+// recordFailedInstallerPod counts a failed installer pod of the given revision.
+func recordFailedInstallerPod(namespace string, revision int32) {
+	failedInstallerPodsMetric.WithLabelValues(namespace, strconv.Itoa(int(revision))).Inc()
+}
@@ -0,0 +1,68 @@
+package installer
+
+import (
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/testutil"
+
+	"github.com/openshift/library-go/pkg/operator/condition"
+)
+
+func TestRecordRevisionMetrics(t *testing.T) {
+	namespace := "test-revision-metrics"
+	now := time.Now()
+	conditions := []operatorv1.OperatorCondition{{
+		Type:               condition.NodeInstallerProgressingConditionType,
+		Status:             operatorv1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(now.Add(-time.Minute)),
+	}}
+
+	recordRevisionMetrics(namespace, []operatorv1.NodeStatus{
+		{NodeName: "node-a", CurrentRevision: 3},
+		{NodeName: "node-b", CurrentRevision: 2, TargetRevision: 3},
+	}, conditions, 3, now)
+
+	for _, tc := range []struct {
+		name     string
+		value    float64
+		expected float64
+	}{
+		{"node-a current", gaugeValue(t, nodeCurrentRevisionMetric.WithLabelValues(namespace, "node-a")), 3},
+		{"node-a target", gaugeValue(t, nodeTargetRevisionMetric.WithLabelValues(namespace, "node-a")), 3},
+		{"node-b current", gaugeValue(t, nodeCurrentRevisionMetric.WithLabelValues(namespace, "node-b")), 2},
+		{"node-b target", gaugeValue(t, nodeTargetRevisionMetric.WithLabelValues(namespace, "node-b")), 3},
+		{"progressing", gaugeValue(t, progressingDurationMetric.WithLabelValues(namespace)), 60},
+	} {
+		if tc.value != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, tc.value)
+		}
+	}
+
+	// node-b is gone and all nodes are at the latest revision
+	recordRevisionMetrics(namespace, []operatorv1.NodeStatus{{NodeName: "node-a", CurrentRevision: 3}}, conditions, 3, now)
+	if deleted := nodeCurrentRevisionMetric.Delete(map[string]string{"namespace": namespace, "node": "node-b"}); deleted {
+		t.Errorf("expected the metrics of removed nodes to be deleted")
+	}
+	if value := gaugeValue(t, progressingDurationMetric.WithLabelValues(namespace)); value != 0 {
+		t.Errorf("expected no progressing duration, got %v", value)
+	}
+
+	recordFailedInstallerPod(namespace, 3)
+	recordFailedInstallerPod(namespace, 3)
+	if value, err := testutil.GetCounterMetricValue(failedInstallerPodsMetric.WithLabelValues(namespace, "3")); err != nil || value != 2 {
+		t.Errorf("expected 2 failed installer pods, got %v: %v", value, err)
+	}
+}
+
+func gaugeValue(t *testing.T, gauge metrics.GaugeMetric) float64 {
+	t.Helper()
+	value, err := testutil.GetGaugeMetricValue(gauge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
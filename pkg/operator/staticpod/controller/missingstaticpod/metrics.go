@@ -0,0 +1,31 @@
+package missingstaticpodcontroller
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	missingStaticPodMetric = metrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      "static_pod",
+		Name:           "missing_total",
+		Help:           "Number of revisions whose static pod did not show up on a node after the installer completed.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"namespace", "static_pod", "node"})
+
+	remediationsMetric = metrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      "static_pod",
+		Name:           "missing_remediations_total",
+		Help:           "Number of remediation attempts for missing static pods by result.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"namespace", "static_pod", "node", "result"})
+)
+
+func init() {
+	(&sync.Once{}).Do(func() {
+		legacyregistry.MustRegister(missingStaticPodMetric)
+		legacyregistry.MustRegister(remediationsMetric)
+	})
+}
@@ -27,10 +27,45 @@ import (
 const (
 	timeoutMultiNode  = time.Second * 150
 	timeoutSingleNode = time.Second * 180
+
+	defaultRemediationInitialBackoff = time.Minute
+	defaultRemediationMaxBackoff     = 30 * time.Minute
 )
 
 type snoDeploymentFunc func() (bool, bool, error)
 
+// DetectionThresholds is how long after the installer pod completed the static pod must show up, in addition to the
+// terminationGracePeriodSeconds of the static pod. Zero values keep the defaults of 150s on multi node and 180s on
+// single node deployments.
+type DetectionThresholds struct {
+	MultiNode  time.Duration
+	SingleNode time.Duration
+}
+
+// RemediationFunc is called for a node whose static pod did not show up for the given revision, e.g. to request a
+// retry of the installer.
+type RemediationFunc func(ctx context.Context, node string, revision int) error
+
+// Option configures optional behaviour of the controller.
+type Option func(*missingStaticPodController)
+
+// WithDetectionThresholds overrides the default detection thresholds, e.g. for operands that take longer to start.
+func WithDetectionThresholds(thresholds DetectionThresholds) Option {
+	return func(c *missingStaticPodController) {
+		c.thresholds = thresholds
+	}
+}
+
+// WithRemediation calls the remediation for nodes with a missing static pod. Attempts for the same node and revision
+// are retried with an exponential backoff between initialBackoff and maxBackoff, zero values default to 1m and 30m.
+func WithRemediation(remediation RemediationFunc, initialBackoff, maxBackoff time.Duration) Option {
+	return func(c *missingStaticPodController) {
+		c.remediation = remediation
+		c.remediationInitialBackoff = initialBackoff
+		c.remediationMaxBackoff = maxBackoff
+	}
+}
+
 type missingStaticPodController struct {
 	operatorClient                    v1helpers.StaticPodOperatorClient
 	podListerForTargetNamespace       corelisterv1.PodNamespaceLister
@@ -40,8 +75,22 @@ type missingStaticPodController struct {
 	operandName                       string
 
 	lastEventEmissionPerNode lastEventEmissionPerNode
+	// detectedRevisionPerNode is the last revision counted as missing per node
+	detectedRevisionPerNode map[string]int
 
 	isSNODeployment snoDeploymentFunc
+	thresholds      DetectionThresholds
+
+	remediation               RemediationFunc
+	remediationInitialBackoff time.Duration
+	remediationMaxBackoff     time.Duration
+	remediationPerNode        map[string]remediationState
+}
+
+type remediationState struct {
+	revision    int
+	attempts    int
+	nextAttempt time.Time
 }
 
 type lastEventEmissionPerNode map[string]struct {
@@ -64,6 +113,7 @@ func New(
 	staticPodName string,
 	operandName string,
 	infraInformer configv1informers.InfrastructureInformer,
+	options ...Option,
 ) factory.Controller {
 	c := &missingStaticPodController{
 		operatorClient:                    operatorClient,
@@ -75,6 +125,9 @@ func New(
 		lastEventEmissionPerNode:          make(lastEventEmissionPerNode),
 		isSNODeployment:                   common.NewIsSingleNodePlatformFn(infraInformer),
 	}
+	for _, option := range options {
+		option(c)
+	}
 
 	return factory.New().
 		ResyncEvery(time.Minute).
@@ -129,13 +182,13 @@ func (c *missingStaticPodController) sync(ctx context.Context, syncCtx factory.S
 		if err != nil {
 			return err
 		}
-		maxTimeout := timeoutMultiNode
+		maxTimeout := c.multiNodeTimeout()
 		// In practice the preconditionFulfilled should always be true because the controller
 		// waits for the infra informer. If this is not the case and we get a failure (it only
 		// fails when the informer is not synced), we choose the conservative approach of
 		// selecting the longer timeout in case we dont know if we are running in SNO.
 		if !preconditionFulfilled || isSNO {
-			maxTimeout = timeoutSingleNode
+			maxTimeout = c.singleNodeTimeout()
 		}
 
 		threshold := gracePeriod + maxTimeout
@@ -173,6 +226,18 @@ func (c *missingStaticPodController) sync(ctx context.Context, syncCtx factory.S
 
 				errors = append(errors, fmt.Sprintf("static pod lifecycle failure - static pod: %q in namespace: %q for revision: %d on node: %q didn't show up, waited: %v",
 					c.staticPodName, c.targetNamespace, installerPodRevision, node, threshold))
+
+				if c.detectedRevisionPerNode == nil {
+					c.detectedRevisionPerNode = map[string]int{}
+				}
+				if detectedRevision, found := c.detectedRevisionPerNode[node]; !found || detectedRevision != installerPodRevision {
+					c.detectedRevisionPerNode[node] = installerPodRevision
+					missingStaticPodMetric.WithLabelValues(c.targetNamespace, c.staticPodName, node).Inc()
+				}
+
+				if err := c.remediate(ctx, syncCtx, node, installerPodRevision); err != nil {
+					errors = append(errors, fmt.Sprintf("remediation of the missing static pod %q for revision %d on node %q failed: %v", c.staticPodName, installerPodRevision, node, err))
+				}
 			}
 		}
 	}
@@ -184,6 +249,65 @@ func (c *missingStaticPodController) sync(ctx context.Context, syncCtx factory.S
 	return nil
 }
 
+func (c *missingStaticPodController) multiNodeTimeout() time.Duration {
+	if c.thresholds.MultiNode > 0 {
+		return c.thresholds.MultiNode
+	}
+	return timeoutMultiNode
+}
+
+func (c *missingStaticPodController) singleNodeTimeout() time.Duration {
+	if c.thresholds.SingleNode > 0 {
+		return c.thresholds.SingleNode
+	}
+	return timeoutSingleNode
+}
+
+// remediate calls the remediation for the node unless the previous attempt for the same revision is within its backoff.
+func (c *missingStaticPodController) remediate(ctx context.Context, syncCtx factory.SyncContext, node string, revision int) error {
+	if c.remediation == nil {
+		return nil
+	}
+	if c.remediationPerNode == nil {
+		c.remediationPerNode = map[string]remediationState{}
+	}
+	state, found := c.remediationPerNode[node]
+	if !found || state.revision != revision {
+		state = remediationState{revision: revision}
+	}
+	if time.Now().Before(state.nextAttempt) {
+		return nil
+	}
+
+	initialBackoff, maxBackoff := c.remediationInitialBackoff, c.remediationMaxBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = defaultRemediationInitialBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = defaultRemediationMaxBackoff
+	}
+	backoff := initialBackoff
+	for i := 0; i < state.attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	state.attempts++
+	state.nextAttempt = time.Now().Add(backoff)
+	c.remediationPerNode[node] = state
+
+	err := c.remediation(ctx, node, revision)
+	if err != nil {
+		remediationsMetric.WithLabelValues(c.targetNamespace, c.staticPodName, node, "error").Inc()
+		syncCtx.Recorder().Warningf("MissingStaticPodRemediationFailed", "remediation attempt %d of missing static pod %q for revision %d on node %q failed: %v", state.attempts, c.staticPodName, revision, node, err)
+		return err
+	}
+	remediationsMetric.WithLabelValues(c.targetNamespace, c.staticPodName, node, "success").Inc()
+	syncCtx.Recorder().Eventf("MissingStaticPodRemediated", "remediation attempt %d of missing static pod %q for revision %d on node %q, next attempt not before %v", state.attempts, c.staticPodName, revision, node, backoff)
+	return nil
+}
+
 // getStaticPodCurrentRevisionForNode reads the current revision from the static pod for the given node
 // since the names are uniques and we know how to construct the final pod's name we always expect to get the desired pod
 func (c *missingStaticPodController) getStaticPodCurrentRevisionForNode(nodeName string) (int, error) {
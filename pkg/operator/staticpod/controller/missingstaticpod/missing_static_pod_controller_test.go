@@ -332,6 +332,62 @@ func TestMissingStaticPodControllerSync(t *testing.T) {
 	}
 }
 
+func TestMissingStaticPodControllerThresholdsAndRemediation(t *testing.T) {
+	targetNamespace, operandName := "test", "test-operand"
+	now := metav1.Now()
+
+	podIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, pod := range []*corev1.Pod{
+		makeTerminatedInstallerPod(3, "node-1", targetNamespace, 0, metav1.NewTime(now.Add(-5*time.Minute))),
+		makeStaticPod(operandName, targetNamespace, "node-1", 2),
+	} {
+		if err := podIndexer.Add(pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := cmIndexer.Add(makeOperandConfigMap(targetNamespace, operandName, 3, makeValidPayloadWithGracefulTerminationPeriod(30))); err != nil {
+		t.Fatal(err)
+	}
+
+	var remediated []string
+	c := &missingStaticPodController{
+		podListerForTargetNamespace:       corev1listers.NewPodLister(podIndexer).Pods(targetNamespace),
+		configMapListerForTargetNamespace: corev1listers.NewConfigMapLister(cmIndexer).ConfigMaps(targetNamespace),
+		targetNamespace:                   targetNamespace,
+		staticPodName:                     operandName,
+		operandName:                       operandName,
+		lastEventEmissionPerNode:          make(lastEventEmissionPerNode),
+		isSNODeployment:                   makeMultiNodeDeploymentFunction(),
+	}
+	syncCtx := factory.NewSyncContext("MissingStaticPodController", events.NewInMemoryRecorder("test"))
+
+	// 5 minutes are within a threshold of 30s grace period + 10m
+	WithDetectionThresholds(DetectionThresholds{MultiNode: 10 * time.Minute})(c)
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatalf("expected no missing static pod within the threshold, got %v", err)
+	}
+
+	WithDetectionThresholds(DetectionThresholds{MultiNode: time.Minute})(c)
+	WithRemediation(func(ctx context.Context, node string, revision int) error {
+		remediated = append(remediated, fmt.Sprintf("%s-%d", node, revision))
+		return nil
+	}, time.Hour, 2*time.Hour)(c)
+	if err := c.sync(context.TODO(), syncCtx); err == nil {
+		t.Fatal("expected the missing static pod to be reported")
+	}
+	// the second attempt is within the backoff
+	if err := c.sync(context.TODO(), syncCtx); err == nil {
+		t.Fatal("expected the missing static pod to be reported")
+	}
+	if diff := cmp.Diff([]string{"node-1-3"}, remediated); diff != "" {
+		t.Errorf("unexpected remediations: %s", diff)
+	}
+	if state := c.remediationPerNode["node-1"]; state.attempts != 1 || time.Until(state.nextAttempt) < 59*time.Minute {
+		t.Errorf("unexpected remediation state %#v", state)
+	}
+}
+
 func makeMultiNodeDeploymentFunction() snoDeploymentFunc {
 	return func() (bool, bool, error) {
 		return false, true, nil
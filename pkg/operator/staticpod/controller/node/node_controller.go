@@ -6,8 +6,6 @@ import (
 	"strings"
 
 	coreapiv1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/client-go/informers"
 	corelisterv1 "k8s.io/client-go/listers/core/v1"
 
@@ -17,6 +15,7 @@ import (
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/condition"
 	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/staticpod/controller/common"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 )
 
@@ -25,6 +24,17 @@ type NodeController struct {
 	controllerInstanceName string
 	operatorClient         v1helpers.StaticPodOperatorClient
 	nodeLister             corelisterv1.NodeLister
+	nodeSelector           common.ControlPlaneNodeSelector
+}
+
+// Option configures optional behaviour of the NodeController.
+type Option func(*NodeController)
+
+// WithControlPlaneNodeSelector replaces the default selection of the master nodes, e.g. to include arbiter nodes.
+func WithControlPlaneNodeSelector(nodeSelector common.ControlPlaneNodeSelector) Option {
+	return func(c *NodeController) {
+		c.nodeSelector = nodeSelector
+	}
 }
 
 // NewNodeController creates a new node controller.
@@ -33,12 +43,16 @@ func NewNodeController(
 	operatorClient v1helpers.StaticPodOperatorClient,
 	kubeInformersClusterScoped informers.SharedInformerFactory,
 	eventRecorder events.Recorder,
+	options ...Option,
 ) factory.Controller {
 	c := &NodeController{
 		controllerInstanceName: factory.ControllerInstanceName(instanceName, "Node"),
 		operatorClient:         operatorClient,
 		nodeLister:             kubeInformersClusterScoped.Core().V1().Nodes().Lister(),
 	}
+	for _, option := range options {
+		option(c)
+	}
 	return factory.New().
 		WithInformers(
 			operatorClient.Informer(),
@@ -58,11 +72,7 @@ func (c *NodeController) sync(ctx context.Context, syncCtx factory.SyncContext)
 		return err
 	}
 
-	selector, err := labels.NewRequirement("node-role.kubernetes.io/master", selection.Equals, []string{""})
-	if err != nil {
-		panic(err)
-	}
-	nodes, err := c.nodeLister.List(labels.NewSelector().Add(*selector))
+	nodes, err := c.nodeSelector.Select(c.nodeLister)
 	if err != nil {
 		return err
 	}
@@ -155,7 +165,12 @@ func (c *NodeController) sync(ctx context.Context, syncCtx factory.SyncContext)
 		}
 	}
 
-	if len(notReadyNodes) > 0 {
+	if err := c.nodeSelector.ValidateQuorum(nodes); err != nil {
+		degradedCondition = degradedCondition.
+			WithStatus(operatorv1.ConditionTrue).
+			WithReason("ControlPlaneQuorumInvalid").
+			WithMessage(fmt.Sprintf("The selected control plane nodes cannot form a quorum: %v", err))
+	} else if len(notReadyNodes) > 0 {
 		degradedCondition = degradedCondition.
 			WithStatus(operatorv1.ConditionTrue).
 			WithReason("MasterNodesReady").
@@ -7,18 +7,21 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/informers"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+	"github.com/openshift/library-go/pkg/operator/revisioncontroller"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 )
 
@@ -40,11 +43,39 @@ type PruneController struct {
 
 	configMapGetter corev1client.ConfigMapsGetter
 	podGetter       corev1client.PodsGetter
+
+	// precondition must be met before pruner pods are started
+	precondition revisioncontroller.PreconditionFunc
+
+	// clock drives the resyncs and the retries of unmet preconditions, nil means the real clock
+	clock clock.WithTicker
+}
+
+// Option configures optional behaviour of the PruneController.
+type Option func(*PruneController)
+
+// WithPrecondition sets a precondition that must be met before pruner pods are started on the nodes, e.g. a
+// quorum.NewQuorumPrecondition called with an empty node name for etcd-like operands.
+func WithPrecondition(precondition revisioncontroller.PreconditionFunc) Option {
+	return func(c *PruneController) {
+		c.precondition = precondition
+	}
+}
+
+// WithClock sets the clock of the controller, tests can use a fake clock to trigger the retries of unmet
+// preconditions.
+func WithClock(clock clock.WithTicker) Option {
+	return func(c *PruneController) {
+		c.clock = clock
+	}
 }
 
 const (
 	statusConfigMapName  = "revision-status-"
 	defaultRevisionLimit = int32(5)
+
+	// preconditionRetryInterval is how often an unmet precondition is checked again
+	preconditionRetryInterval = 30 * time.Second
 )
 
 // NewPruneController creates a new pruning controller
@@ -58,6 +89,7 @@ func NewPruneController(
 	operatorClient v1helpers.StaticPodOperatorClient,
 	kubeInformersForTargetNamespace informers.SharedInformerFactory,
 	eventRecorder events.Recorder,
+	options ...Option,
 ) factory.Controller {
 	c := &PruneController{
 		targetNamespace:   targetNamespace,
@@ -72,8 +104,15 @@ func NewPruneController(
 		prunerPodImageFn: getPrunerPodImageFromEnv,
 	}
 	c.retrieveStatusConfigMapOwnerRefsFn = c.createStatusConfigMapOwnerRefs
+	for _, option := range options {
+		option(c)
+	}
 
-	return factory.New().
+	controllerFactory := factory.New()
+	if c.clock != nil {
+		controllerFactory = controllerFactory.WithClock(c.clock)
+	}
+	return controllerFactory.
 		WithInformers(
 			operatorClient.Informer(),
 			kubeInformersForTargetNamespace.Core().V1().ConfigMaps().Informer(),
@@ -259,14 +298,15 @@ func getPrunerPodImageFromEnv() string {
 }
 
 func (c *PruneController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
-	klog.V(5).Info("Syncing revision pruner")
+	logger := klog.FromContext(ctx)
+	logger.V(5).Info("Syncing revision pruner")
 	operatorSpec, operatorStatus, _, err := c.operatorClient.GetStaticPodOperatorState()
 	if err != nil {
 		return err
 	}
 
 	if len(operatorStatus.NodeStatuses) == 0 {
-		klog.Info("No nodes, nothing to prune")
+		logger.Info("No nodes, nothing to prune")
 		return nil
 	}
 
@@ -274,10 +314,22 @@ func (c *PruneController) sync(ctx context.Context, syncCtx factory.SyncContext)
 	failedLimit, succeededLimit := defaultedLimits(operatorSpec)
 	keepAll, toKeep := c.revisionsToKeep(operatorStatus, failedLimit, succeededLimit)
 	if keepAll {
-		klog.Info("Nothing to prune")
+		logger.Info("Nothing to prune")
 		return nil
 	}
 
+	if c.precondition != nil {
+		met, err := c.precondition(ctx)
+		if err != nil {
+			return err
+		}
+		if !met {
+			logger.Info("Precondition not met, not pruning")
+			syncCtx.Queue().AddAfter(syncCtx.QueueKey(), preconditionRetryInterval)
+			return nil
+		}
+	}
+
 	errs := []error{}
 	if diskErr := c.pruneDiskResources(ctx, syncCtx.Recorder(), operatorStatus, sets.List(toKeep)); diskErr != nil {
 		errs = append(errs, diskErr)
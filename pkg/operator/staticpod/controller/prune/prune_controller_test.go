@@ -378,6 +378,52 @@ func TestSync(t *testing.T) {
 	}
 }
 
+func TestSyncPrecondition(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "revision-status-1", Namespace: "prune-api"},
+		Data:       map[string]string{"revision": "1"},
+	})
+	fakeStaticPodOperatorClient := v1helpers.NewFakeStaticPodOperatorClient(
+		&operatorv1.StaticPodOperatorSpec{FailedRevisionLimit: 1, SucceededRevisionLimit: 1},
+		&operatorv1.StaticPodOperatorStatus{
+			OperatorStatus: operatorv1.OperatorStatus{LatestAvailableRevision: 4},
+			NodeStatuses:   []operatorv1.NodeStatus{{NodeName: "test-node-1", CurrentRevision: 4}},
+		},
+		nil,
+		nil,
+	)
+	met := false
+	c := &PruneController{
+		targetNamespace:   "prune-api",
+		podResourcePrefix: "test-pod",
+		command:           []string{"/bin/true"},
+		configMapGetter:   kubeClient.CoreV1(),
+		podGetter:         kubeClient.CoreV1(),
+		operatorClient:    fakeStaticPodOperatorClient,
+		prunerPodImageFn:  func() string { return "docker.io/foo/bar" },
+		retrieveStatusConfigMapOwnerRefsFn: func(ctx context.Context, revision int32) ([]metav1.OwnerReference, error) {
+			return []metav1.OwnerReference{}, nil
+		},
+	}
+	WithPrecondition(func(ctx context.Context) (bool, error) { return met, nil })(c)
+	syncCtx := factory.NewSyncContext("TestSync", events.NewInMemoryRecorder("test"))
+
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	if len(kubeClient.Actions()) != 0 {
+		t.Fatalf("expected no pruning while the precondition is not met, got %v", kubeClient.Actions())
+	}
+
+	met = true
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kubeClient.CoreV1().ConfigMaps("prune-api").Get(context.TODO(), "revision-status-1", metav1.GetOptions{}); err == nil {
+		t.Error("expected revision 1 to be pruned once the precondition is met")
+	}
+}
+
 func int32Range(from, to int32) []int32 {
 	ret := make([]int32, to-from+1)
 	for i := from; i <= to; i++ {
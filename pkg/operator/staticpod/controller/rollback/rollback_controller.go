@@ -0,0 +1,303 @@
+package rollback
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/condition"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/management"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+	"github.com/openshift/library-go/pkg/operator/revisioncontroller"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+const (
+	// RollbackToRevisionAnnotation is set on the operator resource to request rolling the operand back to the given
+	// previous revision. As long as it is set, no new revisions are created from the current configuration.
+	RollbackToRevisionAnnotation = "operator.openshift.io/rollback-to-revision"
+
+	// rolledBackFromRevisionAnnotation is set on the revision-status configmap of a revision created by a rollback,
+	// pointing to the revision its content was copied from.
+	rolledBackFromRevisionAnnotation = "operator.openshift.io/rolled-back-from-revision"
+
+	revisionReadyAnnotation = "operator.openshift.io/revision-ready"
+)
+
+// RollbackController rolls the operand back to a previous revision requested with RollbackToRevisionAnnotation.
+// Instead of moving the nodes backwards, it creates a new revision with the content of the requested revision, so the
+// installer rolls it out like any other revision.
+type RollbackController struct {
+	controllerInstanceName string
+	targetNamespace        string
+	// configMaps and secrets are the revisioned resources, the first configmap contains the static pod manifest
+	configMaps []revisioncontroller.RevisionResource
+	secrets    []revisioncontroller.RevisionResource
+
+	operatorClient  v1helpers.StaticPodOperatorClient
+	configMapGetter corev1client.ConfigMapsGetter
+	secretGetter    corev1client.SecretsGetter
+}
+
+// NewRollbackController creates a new revision rollback controller. The revision controller must be configured with
+// RevisionPrecondition, otherwise it immediately replaces the rolled back revision with the current configuration.
+func NewRollbackController(
+	instanceName string,
+	targetNamespace string,
+	configMaps []revisioncontroller.RevisionResource,
+	secrets []revisioncontroller.RevisionResource,
+	kubeInformersForTargetNamespace informers.SharedInformerFactory,
+	operatorClient v1helpers.StaticPodOperatorClient,
+	configMapGetter corev1client.ConfigMapsGetter,
+	secretGetter corev1client.SecretsGetter,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &RollbackController{
+		controllerInstanceName: factory.ControllerInstanceName(instanceName, "RevisionRollback"),
+		targetNamespace:        targetNamespace,
+		configMaps:             configMaps,
+		secrets:                secrets,
+		operatorClient:         operatorClient,
+		configMapGetter:        configMapGetter,
+		secretGetter:           secretGetter,
+	}
+
+	return factory.New().
+		WithInformers(
+			operatorClient.Informer(),
+			kubeInformersForTargetNamespace.Core().V1().ConfigMaps().Informer(),
+		).
+		WithSync(c.sync).
+		ResyncEvery(1*time.Minute).
+		ToController(
+			c.controllerInstanceName,
+			eventRecorder.WithComponentSuffix("revision-rollback-controller"),
+		)
+}
+
+// RevisionPrecondition returns a revision controller precondition preventing new revisions while a rollback is
+// requested, so the rolled back revision is kept until the annotation is removed.
+func RevisionPrecondition(operatorClient v1helpers.OperatorClient) revisioncontroller.PreconditionFunc {
+	return func(ctx context.Context) (bool, error) {
+		meta, err := operatorClient.GetObjectMeta()
+		if err != nil {
+			return false, err
+		}
+		_, rollbackRequested := meta.Annotations[RollbackToRevisionAnnotation]
+		return !rollbackRequested, nil
+	}
+}
+
+func (c *RollbackController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	operatorSpec, operatorStatus, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+	if !management.IsOperatorManaged(operatorSpec.ManagementState) {
+		return nil
+	}
+	meta, err := c.operatorClient.GetObjectMeta()
+	if err != nil {
+		return err
+	}
+
+	progressing := applyoperatorv1.OperatorCondition().
+		WithType(condition.RevisionRollbackProgressingConditionType).
+		WithStatus(operatorv1.ConditionFalse).
+		WithReason("AsExpected")
+	degraded := applyoperatorv1.OperatorCondition().
+		WithType(condition.RevisionRollbackDegradedConditionType).
+		WithStatus(operatorv1.ConditionFalse).
+		WithReason("AsExpected")
+
+	var syncErr error
+	if requested, ok := meta.Annotations[RollbackToRevisionAnnotation]; ok {
+		message, rollbackErr := c.rollback(ctx, syncCtx.Recorder(), requested, operatorStatus)
+		switch {
+		case rollbackErr != nil:
+			degraded = degraded.
+				WithStatus(operatorv1.ConditionTrue).
+				WithReason("RollbackFailed").
+				WithMessage(rollbackErr.Error())
+			syncErr = rollbackErr
+		case len(message) > 0:
+			progressing = progressing.
+				WithStatus(operatorv1.ConditionTrue).
+				WithReason("RollingBack").
+				WithMessage(message)
+		}
+	}
+
+	status := applyoperatorv1.StaticPodOperatorStatus().WithConditions(progressing, degraded)
+	if err := c.operatorClient.ApplyStaticPodOperatorStatus(ctx, c.controllerInstanceName, status); err != nil {
+		return err
+	}
+	return syncErr
+}
+
+// rollback creates the rollback revision if it does not exist yet and returns a message describing the rollout
+// progress, or an empty message when all nodes run the rolled back revision.
+func (c *RollbackController) rollback(ctx context.Context, recorder events.Recorder, requested string, operatorStatus *operatorv1.StaticPodOperatorStatus) (string, error) {
+	revision, err := strconv.ParseInt(requested, 10, 32)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s annotation %q: %w", RollbackToRevisionAnnotation, requested, err)
+	}
+	rollbackRevision := int32(revision)
+	latestRevision := operatorStatus.LatestAvailableRevision
+
+	latestStatus, err := c.configMapGetter.ConfigMaps(c.targetNamespace).Get(ctx, nameFor("revision-status", latestRevision), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "", err
+	}
+	if latestStatus != nil && latestStatus.Annotations[rolledBackFromRevisionAnnotation] == strconv.Itoa(int(rollbackRevision)) {
+		var pending []string
+		for _, nodeStatus := range operatorStatus.NodeStatuses {
+			if nodeStatus.CurrentRevision != latestRevision {
+				pending = append(pending, nodeStatus.NodeName)
+			}
+		}
+		if len(pending) == 0 {
+			return "", nil
+		}
+		return fmt.Sprintf("rolling back to the content of revision %d as revision %d, pending nodes: %s", rollbackRevision, latestRevision, strings.Join(pending, ", ")), nil
+	}
+
+	if err := c.validateRollbackRevision(ctx, rollbackRevision, operatorStatus); err != nil {
+		return "", fmt.Errorf("unable to roll back to revision %d: %w", rollbackRevision, err)
+	}
+
+	nextRevision := latestRevision + 1
+	if err := c.createRollbackRevision(ctx, recorder, rollbackRevision, nextRevision); err != nil {
+		return "", fmt.Errorf("unable to create revision %d from revision %d: %w", nextRevision, rollbackRevision, err)
+	}
+	recorder.Eventf("RevisionRollbackTriggered", "new revision %d created from the content of revision %d", nextRevision, rollbackRevision)
+	return fmt.Sprintf("created revision %d from the content of revision %d", nextRevision, rollbackRevision), nil
+}
+
+// validateRollbackRevision checks that the revision is older than the latest one, did not fail to install on any
+// node and that its content still exists and contains a valid static pod manifest.
+func (c *RollbackController) validateRollbackRevision(ctx context.Context, revision int32, operatorStatus *operatorv1.StaticPodOperatorStatus) error {
+	if revision <= 0 || revision >= operatorStatus.LatestAvailableRevision {
+		return fmt.Errorf("revision must be between 1 and %d", operatorStatus.LatestAvailableRevision-1)
+	}
+	for _, nodeStatus := range operatorStatus.NodeStatuses {
+		if nodeStatus.LastFailedRevision == revision {
+			return fmt.Errorf("revision failed on node %s: %s", nodeStatus.NodeName, nodeStatus.LastFailedReason)
+		}
+	}
+
+	revisionStatus, err := c.configMapGetter.ConfigMaps(c.targetNamespace).Get(ctx, nameFor("revision-status", revision), metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if revisionStatus.Annotations[revisionReadyAnnotation] != "true" {
+		return fmt.Errorf("revision was never completed")
+	}
+
+	for i, cm := range c.configMaps {
+		configMap, err := c.configMapGetter.ConfigMaps(c.targetNamespace).Get(ctx, nameFor(cm.Name, revision), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) && cm.Optional {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			continue
+		}
+		podManifest, ok := configMap.Data["pod.yaml"]
+		if !ok {
+			return fmt.Errorf("configmap %s has no pod.yaml", configMap.Name)
+		}
+		if _, err := resourceread.ReadPodV1([]byte(podManifest)); err != nil {
+			return fmt.Errorf("configmap %s has an invalid pod.yaml: %w", configMap.Name, err)
+		}
+	}
+	for _, s := range c.secrets {
+		_, err := c.secretGetter.Secrets(c.targetNamespace).Get(ctx, nameFor(s.Name, revision), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) && s.Optional {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createRollbackRevision creates the next revision by copying the revisioned resources of the rollback revision. Like
+// the revision controller, it marks the revision ready only after all resources are copied.
+func (c *RollbackController) createRollbackRevision(ctx context.Context, recorder events.Recorder, rollbackRevision, nextRevision int32) error {
+	labels := map[string]string{"operator.openshift.io/controller-instance-name": c.controllerInstanceName}
+	desiredStatusConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: c.targetNamespace,
+			Name:      nameFor("revision-status", nextRevision),
+			Annotations: map[string]string{
+				revisionReadyAnnotation:          "false",
+				rolledBackFromRevisionAnnotation: strconv.Itoa(int(rollbackRevision)),
+			},
+			Labels: labels,
+		},
+		Data: map[string]string{
+			"revision": strconv.Itoa(int(nextRevision)),
+			"reason":   fmt.Sprintf("rollback to revision %d", rollbackRevision),
+		},
+	}
+	createdStatus, err := c.configMapGetter.ConfigMaps(c.targetNamespace).Create(ctx, desiredStatusConfigMap, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		createdStatus, err = c.configMapGetter.ConfigMaps(c.targetNamespace).Get(ctx, desiredStatusConfigMap.Name, metav1.GetOptions{})
+		if err == nil && createdStatus.Annotations[rolledBackFromRevisionAnnotation] != strconv.Itoa(int(rollbackRevision)) {
+			// the revision controller created the revision in the meantime, retry with the next one
+			return fmt.Errorf("revision %d already exists", nextRevision)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	ownerRefs := []metav1.OwnerReference{{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Name:       createdStatus.Name,
+		UID:        createdStatus.UID,
+	}}
+	for _, cm := range c.configMaps {
+		if _, _, err := resourceapply.SyncConfigMapWithLabels(ctx, c.configMapGetter, recorder,
+			c.targetNamespace, nameFor(cm.Name, rollbackRevision), c.targetNamespace, nameFor(cm.Name, nextRevision), ownerRefs, labels); err != nil {
+			return err
+		}
+	}
+	for _, s := range c.secrets {
+		if _, _, err := resourceapply.SyncSecretWithLabels(ctx, c.secretGetter, recorder,
+			c.targetNamespace, nameFor(s.Name, rollbackRevision), c.targetNamespace, nameFor(s.Name, nextRevision), ownerRefs, labels); err != nil {
+			return err
+		}
+	}
+
+	createdStatus = createdStatus.DeepCopy()
+	createdStatus.Annotations[revisionReadyAnnotation] = "true"
+	if _, err := c.configMapGetter.ConfigMaps(c.targetNamespace).Update(ctx, createdStatus, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	klog.Infof("Created revision %d from the content of revision %d", nextRevision, rollbackRevision)
+	return nil
+}
+
+func nameFor(name string, revision int32) string {
+	return fmt.Sprintf("%s-%d", name, revision)
+}
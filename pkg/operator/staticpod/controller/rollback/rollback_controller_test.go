@@ -0,0 +1,199 @@
+package rollback
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/condition"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/revisioncontroller"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+const (
+	targetNamespace = "openshift-test"
+	validPod        = "apiVersion: v1\nkind: Pod\nmetadata:\n  name: test-pod\n"
+)
+
+func revisionObjects(revision string, podManifest string) []runtime.Object {
+	return []runtime.Object{
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   targetNamespace,
+				Name:        "revision-status-" + revision,
+				Annotations: map[string]string{revisionReadyAnnotation: "true"},
+			},
+			Data: map[string]string{"revision": revision},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: targetNamespace, Name: "test-pod-" + revision},
+			Data:       map[string]string{"pod.yaml": podManifest},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: targetNamespace, Name: "test-secret-" + revision},
+			Data:       map[string][]byte{"key": []byte(revision)},
+		},
+	}
+}
+
+func TestRollbackController(t *testing.T) {
+	tests := []struct {
+		name              string
+		annotation        string
+		objects           []runtime.Object
+		nodeStatuses      []operatorv1.NodeStatus
+		expectedRevision  bool
+		expectProgressing operatorv1.ConditionStatus
+		expectDegraded    operatorv1.ConditionStatus
+		expectedMessage   string
+	}{
+		{
+			name:              "no rollback requested",
+			expectProgressing: operatorv1.ConditionFalse,
+			expectDegraded:    operatorv1.ConditionFalse,
+		},
+		{
+			name:              "rollback to a valid revision",
+			annotation:        "2",
+			objects:           append(revisionObjects("2", validPod), revisionObjects("3", validPod)...),
+			nodeStatuses:      []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 3}},
+			expectedRevision:  true,
+			expectProgressing: operatorv1.ConditionTrue,
+			expectDegraded:    operatorv1.ConditionFalse,
+			expectedMessage:   "created revision 4 from the content of revision 2",
+		},
+		{
+			name:              "rollback to a newer revision",
+			annotation:        "3",
+			objects:           revisionObjects("3", validPod),
+			expectProgressing: operatorv1.ConditionFalse,
+			expectDegraded:    operatorv1.ConditionTrue,
+			expectedMessage:   "revision must be between 1 and 2",
+		},
+		{
+			name:              "rollback to a pruned revision",
+			annotation:        "1",
+			objects:           revisionObjects("3", validPod),
+			expectProgressing: operatorv1.ConditionFalse,
+			expectDegraded:    operatorv1.ConditionTrue,
+			expectedMessage:   `configmaps "revision-status-1" not found`,
+		},
+		{
+			name:              "rollback to a revision with an invalid manifest",
+			annotation:        "2",
+			objects:           append(revisionObjects("2", "kind: ["), revisionObjects("3", validPod)...),
+			expectProgressing: operatorv1.ConditionFalse,
+			expectDegraded:    operatorv1.ConditionTrue,
+			expectedMessage:   "configmap test-pod-2 has an invalid pod.yaml",
+		},
+		{
+			name:              "rollback to a failed revision",
+			annotation:        "2",
+			objects:           append(revisionObjects("2", validPod), revisionObjects("3", validPod)...),
+			nodeStatuses:      []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 3, LastFailedRevision: 2, LastFailedReason: "InstallerFailed"}},
+			expectProgressing: operatorv1.ConditionFalse,
+			expectDegraded:    operatorv1.ConditionTrue,
+			expectedMessage:   "revision failed on node master-0: InstallerFailed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kubeClient := fake.NewSimpleClientset(test.objects...)
+			meta := &metav1.ObjectMeta{Name: "cluster"}
+			if len(test.annotation) > 0 {
+				meta.Annotations = map[string]string{RollbackToRevisionAnnotation: test.annotation}
+			}
+			operatorClient := v1helpers.NewFakeStaticPodOperatorClientWithObjectMeta(
+				meta,
+				&operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{ManagementState: operatorv1.Managed}},
+				&operatorv1.StaticPodOperatorStatus{
+					OperatorStatus: operatorv1.OperatorStatus{LatestAvailableRevision: 3},
+					NodeStatuses:   test.nodeStatuses,
+				},
+				nil,
+				nil,
+			)
+			c := &RollbackController{
+				controllerInstanceName: "test-RevisionRollback",
+				targetNamespace:        targetNamespace,
+				configMaps:             []revisioncontroller.RevisionResource{{Name: "test-pod"}},
+				secrets:                []revisioncontroller.RevisionResource{{Name: "test-secret"}, {Name: "optional-secret", Optional: true}},
+				operatorClient:         operatorClient,
+				configMapGetter:        kubeClient.CoreV1(),
+				secretGetter:           kubeClient.CoreV1(),
+			}
+
+			err := c.sync(context.TODO(), factory.NewSyncContext("test", events.NewInMemoryRecorder("test")))
+			if (err != nil) != (test.expectDegraded == operatorv1.ConditionTrue) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_, status, _, _ := operatorClient.GetStaticPodOperatorState()
+			progressing := v1helpers.FindOperatorCondition(status.Conditions, condition.RevisionRollbackProgressingConditionType)
+			degraded := v1helpers.FindOperatorCondition(status.Conditions, condition.RevisionRollbackDegradedConditionType)
+			if progressing == nil || progressing.Status != test.expectProgressing {
+				t.Errorf("unexpected progressing condition %#v", progressing)
+			}
+			if degraded == nil || degraded.Status != test.expectDegraded {
+				t.Errorf("unexpected degraded condition %#v", degraded)
+			}
+			if message := progressing.Message + degraded.Message; !strings.Contains(message, test.expectedMessage) {
+				t.Errorf("expected message to contain %q, got %q", test.expectedMessage, message)
+			}
+
+			revisionStatus, err := kubeClient.CoreV1().ConfigMaps(targetNamespace).Get(context.TODO(), "revision-status-4", metav1.GetOptions{})
+			if !test.expectedRevision {
+				if err == nil {
+					t.Errorf("expected no new revision")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if revisionStatus.Annotations[revisionReadyAnnotation] != "true" || revisionStatus.Annotations[rolledBackFromRevisionAnnotation] != test.annotation {
+				t.Errorf("unexpected revision status annotations %v", revisionStatus.Annotations)
+			}
+			pod, err := kubeClient.CoreV1().ConfigMaps(targetNamespace).Get(context.TODO(), "test-pod-4", metav1.GetOptions{})
+			if err != nil || pod.Data["pod.yaml"] != validPod {
+				t.Errorf("expected the pod manifest to be copied: %v", err)
+			}
+			secret, err := kubeClient.CoreV1().Secrets(targetNamespace).Get(context.TODO(), "test-secret-4", metav1.GetOptions{})
+			if err != nil || string(secret.Data["key"]) != test.annotation {
+				t.Errorf("expected the secret of revision %s to be copied: %v", test.annotation, err)
+			}
+
+			// once the revision is created, the controller reports the rollout
+			status.LatestAvailableRevision = 4
+			status.NodeStatuses = test.nodeStatuses
+			if err := c.sync(context.TODO(), factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))); err != nil {
+				t.Fatal(err)
+			}
+			_, status, _, _ = operatorClient.GetStaticPodOperatorState()
+			progressing = v1helpers.FindOperatorCondition(status.Conditions, condition.RevisionRollbackProgressingConditionType)
+			if !strings.Contains(progressing.Message, "pending nodes: master-0") {
+				t.Errorf("unexpected progressing condition %#v", progressing)
+			}
+		})
+	}
+}
+
+func TestRevisionPrecondition(t *testing.T) {
+	operatorClient := v1helpers.NewFakeOperatorClientWithObjectMeta(
+		&metav1.ObjectMeta{Annotations: map[string]string{RollbackToRevisionAnnotation: "2"}}, &operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil)
+	if ok, err := RevisionPrecondition(operatorClient)(context.TODO()); err != nil || ok {
+		t.Errorf("expected no new revisions during a rollback, got %v: %v", ok, err)
+	}
+	if ok, err := RevisionPrecondition(v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil))(context.TODO()); err != nil || !ok {
+		t.Errorf("expected new revisions without a rollback, got %v: %v", ok, err)
+	}
+}
@@ -1,23 +1,27 @@
 package staticpod
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/client-go/config/informers/externalversions"
+	"github.com/openshift/library-go/pkg/config/clusterstatus"
 	"github.com/openshift/library-go/pkg/controller/manager"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/loglevel"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
 	"github.com/openshift/library-go/pkg/operator/revisioncontroller"
 	"github.com/openshift/library-go/pkg/operator/staticpod/controller/backingresource"
+	"github.com/openshift/library-go/pkg/operator/staticpod/controller/common"
 	"github.com/openshift/library-go/pkg/operator/staticpod/controller/guard"
 	"github.com/openshift/library-go/pkg/operator/staticpod/controller/installer"
 	"github.com/openshift/library-go/pkg/operator/staticpod/controller/installerstate"
 	missingstaticpodcontroller "github.com/openshift/library-go/pkg/operator/staticpod/controller/missingstaticpod"
 	"github.com/openshift/library-go/pkg/operator/staticpod/controller/node"
 	"github.com/openshift/library-go/pkg/operator/staticpod/controller/prune"
+	"github.com/openshift/library-go/pkg/operator/staticpod/controller/rollback"
 	"github.com/openshift/library-go/pkg/operator/staticpod/controller/startupmonitorcondition"
 	"github.com/openshift/library-go/pkg/operator/staticpod/controller/staticpodfallback"
 	"github.com/openshift/library-go/pkg/operator/staticpod/controller/staticpodstate"
@@ -77,6 +81,17 @@ type staticPodOperatorControllerBuilder struct {
 	guardCreateConditionalFunc    func() (bool, bool, error)
 
 	revisionControllerPrecondition revisioncontroller.PreconditionFunc
+	revisionRollback               bool
+
+	deploymentMode clusterstatus.DeploymentModeFunc
+
+	missingStaticPodOptions []missingstaticpodcontroller.Option
+
+	controlPlaneNodeSelector common.ControlPlaneNodeSelector
+
+	disruptionPrecondition installer.NodePreconditionFunc
+
+	revisionControllerOptions []revisioncontroller.Option
 }
 
 func NewBuilder(
@@ -117,6 +132,32 @@ type Builder interface {
 	// Use this with caution, as this option can disrupt perspective pods that have not yet had a chance to become healthy.
 	WithPodDisruptionBudgetGuard(operatorNamespace, operatorName, readyzPort, readyzEndpoint string, pdbUnhealthyPodEvictionPolicy *v1.UnhealthyPodEvictionPolicyType, createConditionalFunc func() (bool, bool, error)) Builder
 	WithRevisionControllerPrecondition(revisionControllerPrecondition revisioncontroller.PreconditionFunc) Builder
+
+	// WithRevisionRollback allows rolling back to the content of a previous revision by setting the
+	// operator.openshift.io/rollback-to-revision annotation on the operator resource. No new revisions are created from
+	// the current configuration while the annotation is set.
+	WithRevisionRollback() Builder
+
+	// WithDeploymentMode makes the installer and guard controllers skip hosted control planes, where there are no
+	// control plane nodes to run static pods on.
+	WithDeploymentMode(deploymentMode clusterstatus.DeploymentModeFunc) Builder
+
+	// WithMissingStaticPodOptions tunes the detection of static pods that did not show up after the installer
+	// completed, and optionally remediates them.
+	WithMissingStaticPodOptions(options ...missingstaticpodcontroller.Option) Builder
+
+	// WithControlPlaneNodeSelector replaces the default selection of the master nodes the static pods are installed on
+	// and guarded on, e.g. to include arbiter nodes of two node clusters.
+	WithControlPlaneNodeSelector(nodeSelector common.ControlPlaneNodeSelector) Builder
+
+	// WithDisruptionPrecondition blocks moving nodes to a new revision and pruning while the precondition is not met,
+	// e.g. a quorum.NewQuorumPrecondition for etcd-like operands. The installer passes the node it is about to move,
+	// pruning passes an empty node name.
+	WithDisruptionPrecondition(precondition installer.NodePreconditionFunc) Builder
+
+	// WithRevisionControllerOptions configures optional behaviour of the revision controller, e.g.
+	// revisioncontroller.WithDryRunValidation.
+	WithRevisionControllerOptions(options ...revisioncontroller.Option) Builder
 	ToControllers() (manager.ControllerManager, error)
 }
 
@@ -204,6 +245,36 @@ func (b *staticPodOperatorControllerBuilder) WithRevisionControllerPrecondition(
 	return b
 }
 
+func (b *staticPodOperatorControllerBuilder) WithRevisionRollback() Builder {
+	b.revisionRollback = true
+	return b
+}
+
+func (b *staticPodOperatorControllerBuilder) WithDeploymentMode(deploymentMode clusterstatus.DeploymentModeFunc) Builder {
+	b.deploymentMode = deploymentMode
+	return b
+}
+
+func (b *staticPodOperatorControllerBuilder) WithDisruptionPrecondition(precondition installer.NodePreconditionFunc) Builder {
+	b.disruptionPrecondition = precondition
+	return b
+}
+
+func (b *staticPodOperatorControllerBuilder) WithRevisionControllerOptions(options ...revisioncontroller.Option) Builder {
+	b.revisionControllerOptions = append(b.revisionControllerOptions, options...)
+	return b
+}
+
+func (b *staticPodOperatorControllerBuilder) WithControlPlaneNodeSelector(nodeSelector common.ControlPlaneNodeSelector) Builder {
+	b.controlPlaneNodeSelector = nodeSelector
+	return b
+}
+
+func (b *staticPodOperatorControllerBuilder) WithMissingStaticPodOptions(options ...missingstaticpodcontroller.Option) Builder {
+	b.missingStaticPodOptions = append(b.missingStaticPodOptions, options...)
+	return b
+}
+
 func (b *staticPodOperatorControllerBuilder) ToControllers() (manager.ControllerManager, error) {
 	manager := manager.NewControllerManager()
 
@@ -230,6 +301,21 @@ func (b *staticPodOperatorControllerBuilder) ToControllers() (manager.Controller
 	var errs []error
 
 	if len(b.operandNamespace) > 0 {
+		revisionControllerPrecondition := b.revisionControllerPrecondition
+		if b.revisionRollback {
+			revisionControllerPrecondition = allPreconditions(rollback.RevisionPrecondition(b.staticPodOperatorClient), b.revisionControllerPrecondition)
+			manager.WithController(rollback.NewRollbackController(
+				b.operandName,
+				b.operandNamespace,
+				b.revisionConfigMaps,
+				b.revisionSecrets,
+				operandInformers,
+				b.staticPodOperatorClient,
+				configMapClient,
+				secretClient,
+				eventRecorder,
+			), 1)
+		}
 		manager.WithController(revisioncontroller.NewRevisionController(
 			b.operandName,
 			b.operandNamespace,
@@ -240,7 +326,8 @@ func (b *staticPodOperatorControllerBuilder) ToControllers() (manager.Controller
 			configMapClient,
 			secretClient,
 			eventRecorder,
-			b.revisionControllerPrecondition,
+			revisionControllerPrecondition,
+			b.revisionControllerOptions...,
 		), 1)
 	} else {
 		errs = append(errs, fmt.Errorf("missing revisionController; cannot proceed"))
@@ -268,6 +355,10 @@ func (b *staticPodOperatorControllerBuilder) ToControllers() (manager.Controller
 			b.installerPodMutationFunc,
 		).WithMinReadyDuration(
 			b.minReadyDuration,
+		).WithDeploymentMode(
+			b.deploymentMode,
+		).WithPrecondition(
+			b.disruptionPrecondition,
 		), 1)
 
 		manager.WithController(installerstate.NewInstallerStateController(
@@ -311,6 +402,7 @@ func (b *staticPodOperatorControllerBuilder) ToControllers() (manager.Controller
 			b.staticPodOperatorClient,
 			operandInformers,
 			eventRecorder,
+			prune.WithPrecondition(anyNodePrecondition(b.disruptionPrecondition)),
 		), 1)
 	} else {
 		eventRecorder.Warning("PruningControllerMissing", "not enough information provided, not all functionality is present")
@@ -347,6 +439,7 @@ func (b *staticPodOperatorControllerBuilder) ToControllers() (manager.Controller
 		b.staticPodOperatorClient,
 		clusterInformers,
 		eventRecorder,
+		node.WithControlPlaneNodeSelector(b.controlPlaneNodeSelector),
 	), 1)
 
 	// this cleverly sets the same condition that used to be set because of the way that the names are constructed
@@ -366,6 +459,10 @@ func (b *staticPodOperatorControllerBuilder) ToControllers() (manager.Controller
 	manager.WithController(loglevel.NewClusterOperatorLoggingController(b.staticPodOperatorClient, eventRecorder), 1)
 
 	if len(b.operatorNamespace) > 0 && len(b.operatorName) > 0 && len(b.readyzPort) > 0 && len(b.readyzEndpoint) > 0 {
+		guardCreateConditionalFunc := b.guardCreateConditionalFunc
+		if b.deploymentMode != nil && guardCreateConditionalFunc != nil {
+			guardCreateConditionalFunc = common.NewNotHostedControlPlaneFn(b.deploymentMode, guardCreateConditionalFunc)
+		}
 		if guardController, err := guard.NewGuardController(
 			b.operandNamespace,
 			b.operandPodLabelSelector,
@@ -380,7 +477,8 @@ func (b *staticPodOperatorControllerBuilder) ToControllers() (manager.Controller
 			podClient,
 			pdbClient,
 			eventRecorder,
-			b.guardCreateConditionalFunc,
+			guardCreateConditionalFunc,
+			guard.WithControlPlaneNodeSelector(b.controlPlaneNodeSelector),
 		); err == nil {
 			manager.WithController(guardController, 1)
 		} else {
@@ -396,7 +494,34 @@ func (b *staticPodOperatorControllerBuilder) ToControllers() (manager.Controller
 		b.staticPodName,
 		b.operandName,
 		infraInformers,
+		b.missingStaticPodOptions...,
 	), 1)
 
 	return manager, errors.NewAggregate(errs)
 }
+
+// anyNodePrecondition adapts a node precondition to the pruning, which is not about a single node.
+func anyNodePrecondition(precondition installer.NodePreconditionFunc) revisioncontroller.PreconditionFunc {
+	if precondition == nil {
+		return nil
+	}
+	return func(ctx context.Context) (bool, error) {
+		met, _, err := precondition(ctx, "")
+		return met, err
+	}
+}
+
+// allPreconditions returns a precondition met when all the given, non-nil preconditions are met.
+func allPreconditions(preconditions ...revisioncontroller.PreconditionFunc) revisioncontroller.PreconditionFunc {
+	return func(ctx context.Context) (bool, error) {
+		for _, precondition := range preconditions {
+			if precondition == nil {
+				continue
+			}
+			if ok, err := precondition(ctx); err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
 	"github.com/openshift/library-go/pkg/operator/resource/retry"
+	"github.com/openshift/library-go/pkg/operator/revisioncontroller/payload"
 	"github.com/openshift/library-go/pkg/operator/staticpod"
 	"github.com/openshift/library-go/pkg/operator/staticpod/internal"
 	"github.com/openshift/library-go/pkg/operator/staticpod/internal/flock"
@@ -333,6 +334,12 @@ func (o *InstallOptions) copyContent(ctx context.Context) error {
 		if err != nil {
 			return false, err
 		}
+		podConfigMap, err = payload.Unpack(podConfigMap, func(chunkName string) (*corev1.ConfigMap, error) {
+			return o.KubeClient.CoreV1().ConfigMaps(o.Namespace).Get(ctx, chunkName, metav1.GetOptions{})
+		})
+		if err != nil {
+			return false, err
+		}
 		if _, exists := podConfigMap.Data["pod.yaml"]; !exists {
 			return true, fmt.Errorf("required 'pod.yaml' key does not exist in configmap")
 		}
@@ -12,9 +12,11 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+	"github.com/openshift/library-go/pkg/operator/revisioncontroller/payload"
 )
 
 const podYaml = `
@@ -185,6 +187,50 @@ func TestCopyContent(t *testing.T) {
 				checkFileContentMatchesPod(t, path.Join(podDir, "kube-apiserver-pod.yaml"), podYaml)
 			},
 		},
+		{
+			name: "packed configmaps",
+			o: InstallOptions{
+				Revision:               "006",
+				Namespace:              "some-ns",
+				PodConfigMapNamePrefix: "kube-apiserver-pod",
+				ConfigMapNamePrefixes:  []string{"alpha"},
+			},
+			client: func() *fake.Clientset {
+				// small chunks, so the configmaps are split
+				defer func(chunkSize int) { payload.ChunkSize = chunkSize }(payload.ChunkSize)
+				payload.ChunkSize = 16
+				var objects []runtime.Object
+				for _, configMap := range []*corev1.ConfigMap{
+					{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "some-ns", Name: "alpha-006"},
+						Data: map[string]string{
+							"apple-A.crt":  "apple",
+							"banana-A.crt": "banana",
+						},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "some-ns", Name: "kube-apiserver-pod-006"},
+						Data: map[string]string{
+							"pod.yaml": podYaml,
+						},
+					},
+				} {
+					packed, err := payload.Pack(configMap)
+					if err != nil {
+						panic(err)
+					}
+					for _, obj := range packed {
+						objects = append(objects, obj)
+					}
+				}
+				return fake.NewSimpleClientset(objects...)
+			},
+			expected: func(t *testing.T, resourceDir, podDir string) {
+				checkFileContent(t, path.Join(resourceDir, "kube-apiserver-pod-006", "configmaps", "alpha", "apple-A.crt"), "apple")
+				checkFileContent(t, path.Join(resourceDir, "kube-apiserver-pod-006", "configmaps", "alpha", "banana-A.crt"), "banana")
+				checkFileContentMatchesPod(t, path.Join(podDir, "kube-apiserver-pod.yaml"), podYaml)
+			},
+		},
 
 		{
 			name: "optional pod in pod cm",
@@ -9,6 +9,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/openshift/library-go/pkg/operator/resource/retry"
+	"github.com/openshift/library-go/pkg/operator/revisioncontroller/payload"
 )
 
 // getSecretWithRetry will attempt to get the secret from the API server and retry on any connection errors until
@@ -42,6 +43,7 @@ func (o *InstallOptions) getSecretWithRetry(ctx context.Context, name string, is
 // getConfigMapWithRetry will attempt to get the configMap from the API server and retry on any connection errors until
 // the context is not done or configMap is returned or a HTTP client error is returned.
 // In case the optional flag is set, the 404 error is not reported and a nil object is returned instead.
+// A configMap packed by the revision controller is returned unpacked.
 func (o *InstallOptions) getConfigMapWithRetry(ctx context.Context, name string, isOptional bool) (*v1.ConfigMap, error) {
 	var config *v1.ConfigMap
 
@@ -58,7 +60,9 @@ func (o *InstallOptions) getConfigMapWithRetry(ctx context.Context, name string,
 	switch {
 	case err == nil:
 		klog.Infof("Got configMap %s/%s", o.Namespace, name)
-		return config, nil
+		return payload.Unpack(config, func(chunkName string) (*v1.ConfigMap, error) {
+			return o.getConfigMapWithRetry(ctx, chunkName, false)
+		})
 	case errors.IsNotFound(err) && isOptional:
 		return nil, nil
 	default:
@@ -0,0 +1,82 @@
+package kubeconfig
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// DefaultServer is the endpoint of the kube-apiserver on the local node.
+	DefaultServer = "https://localhost:6443"
+	// KubeconfigKey is the key of the rendered kubeconfig in the secret.
+	KubeconfigKey = "kubeconfig"
+)
+
+// LocalKubeconfig describes the kubeconfig of a static pod operand talking to the kube-apiserver on the local node.
+type LocalKubeconfig struct {
+	// Server is the kube-apiserver endpoint. Empty means DefaultServer.
+	Server string
+	// TLSServerName is used to verify the serving certificate of the kube-apiserver, e.g. "localhost-recovery".
+	// Empty means the host of the server.
+	TLSServerName string
+	// UserName is the name of the user in the kubeconfig. Empty means "user".
+	UserName string
+
+	// CertificateAuthorityFile, ClientCertificateFile and ClientKeyFile reference files in the static pod
+	// resources, kept up to date by a cert syncer. When set, the corresponding data is not embedded, so the
+	// kubeconfig does not change when the certificates rotate.
+	CertificateAuthorityFile string
+	ClientCertificateFile    string
+	ClientKeyFile            string
+}
+
+// Render returns the kubeconfig with the CA bundle and the client certificate and key embedded, unless files are
+// referenced for them. The output is stable for the same input, so it can be compared to detect changes.
+func (k LocalKubeconfig) Render(caBundle, clientCert, clientKey []byte) ([]byte, error) {
+	server := k.Server
+	if len(server) == 0 {
+		server = DefaultServer
+	}
+	userName := k.UserName
+	if len(userName) == 0 {
+		userName = "user"
+	}
+
+	cluster := &clientcmdapi.Cluster{Server: server, TLSServerName: k.TLSServerName}
+	if len(k.CertificateAuthorityFile) > 0 {
+		cluster.CertificateAuthority = k.CertificateAuthorityFile
+	} else {
+		if len(caBundle) == 0 {
+			return nil, fmt.Errorf("CA bundle is missing")
+		}
+		cluster.CertificateAuthorityData = caBundle
+	}
+
+	authInfo := &clientcmdapi.AuthInfo{}
+	if len(k.ClientCertificateFile) > 0 {
+		authInfo.ClientCertificate = k.ClientCertificateFile
+	} else {
+		if len(clientCert) == 0 {
+			return nil, fmt.Errorf("client certificate is missing")
+		}
+		authInfo.ClientCertificateData = clientCert
+	}
+	if len(k.ClientKeyFile) > 0 {
+		authInfo.ClientKey = k.ClientKeyFile
+	} else {
+		if len(clientKey) == 0 {
+			return nil, fmt.Errorf("client key is missing")
+		}
+		authInfo.ClientKeyData = clientKey
+	}
+
+	config := clientcmdapi.Config{
+		Clusters:       map[string]*clientcmdapi.Cluster{"local": cluster},
+		AuthInfos:      map[string]*clientcmdapi.AuthInfo{userName: authInfo},
+		Contexts:       map[string]*clientcmdapi.Context{"local": {Cluster: "local", AuthInfo: userName}},
+		CurrentContext: "local",
+	}
+	return clientcmd.Write(config)
+}
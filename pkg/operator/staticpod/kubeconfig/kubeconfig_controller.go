@@ -0,0 +1,147 @@
+package kubeconfig
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/management"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/revisioncontroller"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// Source references a configmap or secret the kubeconfig is rendered from.
+type Source struct {
+	Namespace string
+	Name      string
+}
+
+// KubeconfigSecret describes the secret the kubeconfig of an operand is rendered to and its sources.
+type KubeconfigSecret struct {
+	// Namespace and Name of the secret, usually the operand namespace. The kubeconfig is stored under KubeconfigKey.
+	Namespace string
+	Name      string
+
+	Kubeconfig LocalKubeconfig
+
+	// CABundle is the configmap with the CA bundle of the kube-apiserver in the "ca-bundle.crt" key, as maintained
+	// by certrotation.CABundleConfigMap. Not needed if the kubeconfig references a CA file.
+	CABundle Source
+	// ClientCert is the secret with the client certificate and key in the "tls.crt" and "tls.key" keys, as maintained
+	// by certrotation.RotatedSelfSignedCertKeySecret. Not needed if the kubeconfig references certificate files.
+	ClientCert Source
+}
+
+// RevisionResource returns the resource to add to the revisioned secrets of the revision controller, so that every
+// change of the kubeconfig, e.g. after a certificate rotation, is rolled out as a new revision of the static pod.
+func (s KubeconfigSecret) RevisionResource() revisioncontroller.RevisionResource {
+	return revisioncontroller.RevisionResource{Name: s.Name}
+}
+
+// KubeconfigController keeps the kubeconfig of a static pod operand rendered from the current CA bundle and client
+// certificate, replacing kubeconfigs templated from static assets that go stale when the certificates rotate.
+type KubeconfigController struct {
+	controllerInstanceName string
+	secret                 KubeconfigSecret
+
+	operatorClient v1helpers.OperatorClient
+	kubeClient     kubernetes.Interface
+	informers      v1helpers.KubeInformersForNamespaces
+}
+
+// NewKubeconfigController returns a controller rendering the kubeconfig into the secret. The informers must contain
+// the namespaces of the secret and of its sources.
+func NewKubeconfigController(
+	instanceName string,
+	secret KubeconfigSecret,
+	operatorClient v1helpers.OperatorClient,
+	kubeClient kubernetes.Interface,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &KubeconfigController{
+		controllerInstanceName: factory.ControllerInstanceName(instanceName, "Kubeconfig"),
+		secret:                 secret,
+		operatorClient:         operatorClient,
+		kubeClient:             kubeClient,
+		informers:              kubeInformersForNamespaces,
+	}
+
+	names := []string{secret.Name}
+	watched := []factory.Informer{operatorClient.Informer(), kubeInformersForNamespaces.InformersFor(secret.Namespace).Core().V1().Secrets().Informer()}
+	if c.needsCABundle() {
+		names = append(names, secret.CABundle.Name)
+		watched = append(watched, kubeInformersForNamespaces.InformersFor(secret.CABundle.Namespace).Core().V1().ConfigMaps().Informer())
+	}
+	if c.needsClientCert() {
+		names = append(names, secret.ClientCert.Name)
+		watched = append(watched, kubeInformersForNamespaces.InformersFor(secret.ClientCert.Namespace).Core().V1().Secrets().Informer())
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers(factory.NamesFilter(names...), watched...).
+		WithSync(c.sync).
+		WithSyncDegradedOnError(operatorClient).
+		ResyncEvery(time.Minute).
+		ToController(c.controllerInstanceName, eventRecorder.WithComponentSuffix("kubeconfig-controller"))
+}
+
+func (c *KubeconfigController) needsCABundle() bool {
+	return len(c.secret.Kubeconfig.CertificateAuthorityFile) == 0
+}
+
+func (c *KubeconfigController) needsClientCert() bool {
+	return len(c.secret.Kubeconfig.ClientCertificateFile) == 0 || len(c.secret.Kubeconfig.ClientKeyFile) == 0
+}
+
+func (c *KubeconfigController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	operatorSpec, _, _, err := c.operatorClient.GetOperatorState()
+	if err != nil {
+		return err
+	}
+	if !management.IsOperatorManaged(operatorSpec.ManagementState) {
+		return nil
+	}
+
+	var caBundle, clientCert, clientKey []byte
+	if c.needsCABundle() {
+		configMap, err := c.informers.InformersFor(c.secret.CABundle.Namespace).Core().V1().ConfigMaps().Lister().ConfigMaps(c.secret.CABundle.Namespace).Get(c.secret.CABundle.Name)
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("waiting for the CA bundle configmap %s/%s", c.secret.CABundle.Namespace, c.secret.CABundle.Name)
+		}
+		if err != nil {
+			return err
+		}
+		caBundle = []byte(configMap.Data["ca-bundle.crt"])
+	}
+	if c.needsClientCert() {
+		secret, err := c.informers.InformersFor(c.secret.ClientCert.Namespace).Core().V1().Secrets().Lister().Secrets(c.secret.ClientCert.Namespace).Get(c.secret.ClientCert.Name)
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("waiting for the client certificate secret %s/%s", c.secret.ClientCert.Namespace, c.secret.ClientCert.Name)
+		}
+		if err != nil {
+			return err
+		}
+		clientCert, clientKey = secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]
+	}
+
+	kubeconfig, err := c.secret.Kubeconfig.Render(caBundle, clientCert, clientKey)
+	if err != nil {
+		return fmt.Errorf("unable to render kubeconfig %s/%s: %w", c.secret.Namespace, c.secret.Name, err)
+	}
+
+	_, _, err = resourceapply.ApplySecret(ctx, c.kubeClient.CoreV1(), syncCtx.Recorder(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: c.secret.Namespace, Name: c.secret.Name},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{KubeconfigKey: kubeconfig},
+	})
+	return err
+}
@@ -0,0 +1,128 @@
+package kubeconfig
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestRender(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		kubeconfig LocalKubeconfig
+		caBundle   string
+		expected   []string
+		expectErr  string
+	}{
+		{
+			name:       "embedded data",
+			kubeconfig: LocalKubeconfig{TLSServerName: "localhost-recovery"},
+			caBundle:   "ca",
+			expected:   []string{"server: https://localhost:6443", "tls-server-name: localhost-recovery", "certificate-authority-data: Y2E=", "client-certificate-data: Y2VydA==", "client-key-data: a2V5", "name: user"},
+		},
+		{
+			name: "file references",
+			kubeconfig: LocalKubeconfig{
+				Server:                   "https://localhost:6444",
+				UserName:                 "operand",
+				CertificateAuthorityFile: "/etc/kubernetes/static-pod-resources/configmaps/ca/ca-bundle.crt",
+				ClientCertificateFile:    "/etc/kubernetes/static-pod-certs/secrets/client/tls.crt",
+				ClientKeyFile:            "/etc/kubernetes/static-pod-certs/secrets/client/tls.key",
+			},
+			expected: []string{"server: https://localhost:6444", "certificate-authority: /etc/kubernetes/static-pod-resources/configmaps/ca/ca-bundle.crt", "client-certificate: /etc/kubernetes/static-pod-certs/secrets/client/tls.crt", "name: operand"},
+		},
+		{
+			name:      "missing CA bundle",
+			expectErr: "CA bundle is missing",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			rendered, err := test.kubeconfig.Render([]byte(test.caBundle), []byte("cert"), []byte("key"))
+			if len(test.expectErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.expectErr) {
+					t.Fatalf("expected error %q, got %v", test.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := clientcmd.Load(rendered); err != nil {
+				t.Fatalf("rendered kubeconfig does not load: %v", err)
+			}
+			for _, expected := range test.expected {
+				if !strings.Contains(string(rendered), expected) {
+					t.Errorf("expected %q in:\n%s", expected, rendered)
+				}
+			}
+		})
+	}
+}
+
+func TestKubeconfigController(t *testing.T) {
+	caBundle := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "operator", Name: "ca"}, Data: map[string]string{"ca-bundle.crt": "ca"}}
+	clientCert := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "operator", Name: "client"}, Data: map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")}}
+	kubeClient := fake.NewSimpleClientset(caBundle, clientCert)
+	informers := v1helpers.NewKubeInformersForNamespaces(kubeClient, "operator", "operand")
+	configMapIndexer := informers.InformersFor("operator").Core().V1().ConfigMaps().Informer().GetIndexer()
+	secretIndexer := informers.InformersFor("operator").Core().V1().Secrets().Informer().GetIndexer()
+	if err := configMapIndexer.Add(caBundle); err != nil {
+		t.Fatal(err)
+	}
+	if err := secretIndexer.Add(clientCert); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &KubeconfigController{
+		secret: KubeconfigSecret{
+			Namespace:  "operand",
+			Name:       "operand-kubeconfig",
+			CABundle:   Source{Namespace: "operator", Name: "ca"},
+			ClientCert: Source{Namespace: "operator", Name: "client"},
+		},
+		operatorClient: v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{ManagementState: operatorv1.Managed}, &operatorv1.OperatorStatus{}, nil),
+		kubeClient:     kubeClient,
+		informers:      informers,
+	}
+	syncContext := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+
+	syncAndGetKubeconfig := func() string {
+		t.Helper()
+		if err := c.sync(context.TODO(), syncContext); err != nil {
+			t.Fatal(err)
+		}
+		secret, err := kubeClient.CoreV1().Secrets("operand").Get(context.TODO(), "operand-kubeconfig", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(secret.Data[KubeconfigKey])
+	}
+
+	if kubeconfig := syncAndGetKubeconfig(); !strings.Contains(kubeconfig, "client-certificate-data: Y2VydA==") {
+		t.Errorf("expected the client certificate to be embedded:\n%s", kubeconfig)
+	}
+
+	// the client certificate rotates
+	rotated := clientCert.DeepCopy()
+	rotated.Data["tls.crt"] = []byte("rotated")
+	if err := secretIndexer.Update(rotated); err != nil {
+		t.Fatal(err)
+	}
+	if kubeconfig := syncAndGetKubeconfig(); !strings.Contains(kubeconfig, "client-certificate-data: cm90YXRlZA==") {
+		t.Errorf("expected the rotated client certificate to be embedded:\n%s", kubeconfig)
+	}
+
+	if resource := c.secret.RevisionResource(); resource.Name != "operand-kubeconfig" || resource.Optional {
+		t.Errorf("unexpected revision resource %#v", resource)
+	}
+}
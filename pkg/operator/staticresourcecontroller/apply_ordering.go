@@ -0,0 +1,172 @@
+package staticresourcecontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+)
+
+// ApplyClass orders the apply of the manifests, see WithApplyOrdering.
+type ApplyClass string
+
+const (
+	// NamespacesApplyClass is applied first, the namespaces must be Active before the next classes are applied.
+	NamespacesApplyClass ApplyClass = "namespaces"
+	// CRDsApplyClass has the CustomResourceDefinitions, they must be Established before the next classes are applied.
+	CRDsApplyClass ApplyClass = "crds"
+	// RBACApplyClass has the service accounts, roles and role bindings used by the workloads.
+	RBACApplyClass ApplyClass = "rbac"
+	// WorkloadsApplyClass has all the other manifests, including the custom resources.
+	WorkloadsApplyClass ApplyClass = "workloads"
+)
+
+// applyClasses are the classes in apply order.
+var applyClasses = []ApplyClass{NamespacesApplyClass, CRDsApplyClass, RBACApplyClass, WorkloadsApplyClass}
+
+// defaultReadinessRecheckInterval is how long after a sync the readiness of the objects of a class is checked again.
+const defaultReadinessRecheckInterval = 10 * time.Second
+
+// ApplyClassFor returns the class of an object from its kind.
+func ApplyClassFor(obj runtime.Object) ApplyClass {
+	switch obj.(type) {
+	case *corev1.Namespace:
+		return NamespacesApplyClass
+	case *apiextensionsv1.CustomResourceDefinition:
+		return CRDsApplyClass
+	case *corev1.ServiceAccount, *rbacv1.ClusterRole, *rbacv1.ClusterRoleBinding, *rbacv1.Role, *rbacv1.RoleBinding:
+		return RBACApplyClass
+	default:
+		return WorkloadsApplyClass
+	}
+}
+
+// WithApplyOrdering applies the manifests by class: namespaces, CRDs, RBAC and then workloads, instead of in the order
+// they were given. The namespaces must be Active and the CRDs Established before the next class is applied, so the
+// custom resources do not race the registration of their CRDs. When they are not ready yet, the sync stops there,
+// <name>Degraded is False with the WaitingForReadiness reason and the sync is requeued after the recheck interval,
+// 10s by default. Deletes are not ordered.
+func (c *StaticResourceController) WithApplyOrdering(readinessRecheckInterval time.Duration) *StaticResourceController {
+	c.applyOrdering = true
+	c.readinessRecheckInterval = readinessRecheckInterval
+	if c.readinessRecheckInterval <= 0 {
+		c.readinessRecheckInterval = defaultReadinessRecheckInterval
+	}
+	return c
+}
+
+// WithApplyClass declares the class of the files, instead of the class of their kinds, e.g. to apply a config map
+// read by an admission webhook with the RBAC. It only matters with WithApplyOrdering.
+func (c *StaticResourceController) WithApplyClass(class ApplyClass, files ...string) *StaticResourceController {
+	if c.applyClassOverrides == nil {
+		c.applyClassOverrides = map[string]ApplyClass{}
+	}
+	for _, file := range files {
+		c.applyClassOverrides[file] = class
+	}
+	return c
+}
+
+// classOf returns the class of a file of the manifest set. The class of a readable file is cached, the files which
+// cannot be read are applied with the workloads, which reports their errors.
+func (c *StaticResourceController) classOf(set int, manifests resourceapply.AssetFunc, file string) ApplyClass {
+	if class, ok := c.applyClassOverrides[file]; ok {
+		return class
+	}
+	key := manifestFile{set: set, file: file}
+	c.fileClassesLock.Lock()
+	defer c.fileClassesLock.Unlock()
+	if class, ok := c.fileClasses[key]; ok {
+		return class
+	}
+	objBytes, err := manifests(file)
+	if err != nil {
+		return WorkloadsApplyClass
+	}
+	requiredObj, err := resourceread.ReadGenericWithUnstructured(objBytes)
+	if err != nil {
+		return WorkloadsApplyClass
+	}
+	if c.fileClasses == nil {
+		c.fileClasses = map[manifestFile]ApplyClass{}
+	}
+	c.fileClasses[key] = ApplyClassFor(requiredObj)
+	return c.fileClasses[key]
+}
+
+// applyOrdered applies the manifests class by class and checks that the objects of every class are ready. It returns
+// the results of the applied manifests, and a non nil error when it stopped at a class which is not ready yet. The
+// objects which will not become ready, e.g. a terminating namespace, are returned as failures.
+func (c *StaticResourceController) applyOrdered(ctx context.Context, recorder events.Recorder, toApply []conditionalManifests) ([]resourceapply.ApplyResult, []error, error) {
+	filesByClass := map[ApplyClass][]conditionalManifests{}
+	for _, conditionalManifest := range toApply {
+		files := map[ApplyClass][]string{}
+		for _, file := range conditionalManifest.files {
+			class := c.classOf(conditionalManifest.set, conditionalManifest.manifests, file)
+			files[class] = append(files[class], file)
+		}
+		for class := range files {
+			classManifest := conditionalManifest
+			classManifest.files = files[class]
+			filesByClass[class] = append(filesByClass[class], classManifest)
+		}
+	}
+
+	var results []resourceapply.ApplyResult
+	for _, class := range applyClasses {
+		var classResults []resourceapply.ApplyResult
+		for _, conditionalManifest := range filesByClass[class] {
+			classResults = append(classResults, resourceapply.ApplyDirectly(ctx, c.clients, recorder, c.performanceCache, conditionalManifest.manifests, conditionalManifest.files...)...)
+		}
+		results = append(results, classResults...)
+		if notReady, failures := notReadyObjects(classResults); len(notReady) > 0 {
+			return results, failures, fmt.Errorf("waiting for the %s to be ready before applying the next manifests: %s not ready", class, strings.Join(notReady, ", "))
+		}
+	}
+	return results, nil, nil
+}
+
+// notReadyObjects returns the names of the successfully applied objects which are not ready, and the errors of those
+// which will not become ready.
+func notReadyObjects(results []resourceapply.ApplyResult) ([]string, []error) {
+	var notReady []string
+	var errs []error
+	for _, result := range results {
+		if result.Error != nil || result.Result == nil {
+			continue
+		}
+		ready, err := resourceapply.ObjectReady(result.Result)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%q (%T): %w", result.File, result.Type, err))
+		}
+		if !ready {
+			notReady = append(notReady, objectName(result.Result))
+		}
+	}
+	return notReady, errs
+}
+
+func objectName(obj runtime.Object) string {
+	metadata, err := meta.Accessor(obj)
+	if err != nil {
+		return fmt.Sprintf("%T", obj)
+	}
+	kind := fmt.Sprintf("%T", obj)
+	if i := strings.LastIndex(kind, "."); i >= 0 {
+		kind = kind[i+1:]
+	}
+	if len(metadata.GetNamespace()) > 0 {
+		return fmt.Sprintf("%s %s/%s", kind, metadata.GetNamespace(), metadata.GetName())
+	}
+	return fmt.Sprintf("%s %s", kind, metadata.GetName())
+}
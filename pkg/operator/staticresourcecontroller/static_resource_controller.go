@@ -2,9 +2,11 @@ package staticresourcecontroller
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
@@ -65,8 +67,12 @@ type StaticResourceController struct {
 	controllerInstanceName string
 	manifests              []conditionalManifests
 	ignoreNotFoundOnCreate bool
+	dryRun                 bool
 	preconditions          []StaticResourcesPreconditionsFuncType
 
+	// dryRunValidatedHash is the hash of the manifests which passed the dry-run validation last
+	dryRunValidatedHash string
+
 	operatorClient v1helpers.OperatorClient
 	clients        *resourceapply.ClientHolder
 
@@ -76,6 +82,19 @@ type StaticResourceController struct {
 	restMapper       meta.RESTMapper
 	categoryExpander restmapper.CategoryExpander
 	performanceCache resourceapply.ResourceCache
+
+	applyOrdering            bool
+	readinessRecheckInterval time.Duration
+	applyClassOverrides      map[string]ApplyClass
+	// fileClasses caches the class of the manifest files
+	fileClassesLock sync.Mutex
+	fileClasses     map[manifestFile]ApplyClass
+}
+
+// manifestFile identifies a file of a manifest set, different sets may have files of the same name.
+type manifestFile struct {
+	set  int
+	file string
 }
 
 type conditionalManifests struct {
@@ -87,6 +106,8 @@ type conditionalManifests struct {
 	shouldDeleteFn resourceapply.ConditionalFunction
 
 	manifests resourceapply.AssetFunc
+	// set is the index of the manifests in the controller
+	set int
 
 	files []string
 }
@@ -133,6 +154,19 @@ func (c *StaticResourceController) WithIgnoreNotFoundOnCreate() *StaticResourceC
 	return c
 }
 
+// WithDryRunValidation makes the controller validate all manifests to be applied with a server-side dry-run first.
+// When any of them is rejected, e.g. by admission, nothing is applied or deleted and the rejected manifests are
+// reported in <name>Degraded condition, instead of leaving a partially applied set of manifests behind.
+// Manifests whose namespace or resource does not exist yet, e.g. because another manifest of the set creates it,
+// cannot be validated before that one is applied and are not validated in that sync. The manifests are validated
+// again only when their content changes, not on every resync.
+// The dry-run requires a dynamic client in the client holder. Use .AddRESTMapper() unless the resources of all
+// manifests can be guessed from their kinds.
+func (c *StaticResourceController) WithDryRunValidation() *StaticResourceController {
+	c.dryRun = true
+	return c
+}
+
 // WithPrecondition adds a precondition, which blocks the sync method from being executed. Preconditions might be chained using:
 //
 //	WithPrecondition(a).WithPrecondition(b).WithPrecondition(c).
@@ -178,6 +212,7 @@ func (c *StaticResourceController) WithConditionalResources(manifests resourceap
 		shouldCreateFn: shouldCreateFn,
 		shouldDeleteFn: shouldDeleteFn,
 		manifests:      manifests,
+		set:            len(c.manifests),
 		files:          files,
 	})
 	return c
@@ -314,8 +349,39 @@ func (c *StaticResourceController) Sync(ctx context.Context, syncContext factory
 		}
 	}
 
+	if c.dryRun {
+		if dryRunErrors := c.dryRunManifests(ctx); len(dryRunErrors) > 0 {
+			message := ""
+			for _, err := range dryRunErrors {
+				message = message + err.Error() + "\n"
+			}
+			condition := applyoperatorv1.OperatorStatus().
+				WithConditions(applyoperatorv1.OperatorCondition().
+					WithType(fmt.Sprintf("%sDegraded", c.instanceName)).
+					WithStatus(operatorv1.ConditionTrue).
+					WithReason("DryRunValidationFailed").
+					WithMessage(message))
+			if err := c.operatorClient.ApplyOperatorStatus(ctx, c.controllerInstanceName, condition); err != nil {
+				dryRunErrors = append(dryRunErrors, err)
+			}
+			return utilerrors.NewAggregate(dryRunErrors)
+		}
+	}
+
 	errors := []error{}
 	var notFoundErrorsCount int
+	recordResults := func(directResourceResults []resourceapply.ApplyResult) {
+		for _, currResult := range directResourceResults {
+			if apierrors.IsNotFound(currResult.Error) {
+				notFoundErrorsCount++
+			}
+			if currResult.Error != nil {
+				errors = append(errors, fmt.Errorf("%q (%T): %v", currResult.File, currResult.Type, currResult.Error))
+				continue
+			}
+		}
+	}
+	var orderedManifests []conditionalManifests
 	for _, conditionalManifest := range c.manifests {
 		shouldCreate := conditionalManifest.shouldCreateFn()
 		shouldDelete := conditionalManifest.shouldDeleteFn()
@@ -330,20 +396,28 @@ func (c *StaticResourceController) Sync(ctx context.Context, syncContext factory
 			errors = append(errors, fmt.Errorf("cannot create and delete %v at the same time, skipping", strings.Join(conditionalManifest.files, ", ")))
 			continue
 
+		case shouldCreate && c.applyOrdering:
+			// applied by class once all the manifests are known
+			orderedManifests = append(orderedManifests, conditionalManifest)
+			continue
 		case shouldCreate:
 			directResourceResults = resourceapply.ApplyDirectly(ctx, c.clients, syncContext.Recorder(), c.performanceCache, conditionalManifest.manifests, conditionalManifest.files...)
 		case shouldDelete:
 			directResourceResults = resourceapply.DeleteAll(ctx, c.clients, syncContext.Recorder(), conditionalManifest.manifests, conditionalManifest.files...)
 		}
 
-		for _, currResult := range directResourceResults {
-			if apierrors.IsNotFound(currResult.Error) {
-				notFoundErrorsCount++
-			}
-			if currResult.Error != nil {
-				errors = append(errors, fmt.Errorf("%q (%T): %v", currResult.File, currResult.Type, currResult.Error))
-				continue
-			}
+		recordResults(directResourceResults)
+	}
+	var readinessErr error
+	if len(orderedManifests) > 0 {
+		var directResourceResults []resourceapply.ApplyResult
+		var readinessFailures []error
+		directResourceResults, readinessFailures, readinessErr = c.applyOrdered(ctx, syncContext.Recorder(), orderedManifests)
+		recordResults(directResourceResults)
+		// objects which will not become ready are errors, not progress
+		errors = append(errors, readinessFailures...)
+		if readinessErr != nil && len(errors) > 0 {
+			errors = append(errors, readinessErr)
 		}
 	}
 
@@ -368,15 +442,73 @@ func (c *StaticResourceController) Sync(ctx context.Context, syncContext factory
 			cnd = cnd.WithStatus(operatorv1.ConditionFalse)
 		}
 	}
+	if readinessErr != nil && len(errors) == 0 {
+		// the next manifests wait for the namespaces or the CRDs, which is progress rather than degradation
+		cnd = cnd.
+			WithReason("WaitingForReadiness").
+			WithMessage(readinessErr.Error())
+	}
 
 	status := applyoperatorv1.OperatorStatus().WithConditions(cnd)
 	err = c.operatorClient.ApplyOperatorStatus(ctx, c.controllerInstanceName, status)
 	if err != nil {
 		errors = append(errors, err)
 	}
+	if readinessErr != nil && len(errors) == 0 {
+		syncContext.Queue().AddAfter(syncContext.QueueKey(), c.readinessRecheckInterval)
+	}
 	return utilerrors.NewAggregate(errors)
 }
 
+// dryRunManifests validates the manifests that are going to be applied with a server-side dry-run, unless the same
+// manifests passed the validation before.
+func (c *StaticResourceController) dryRunManifests(ctx context.Context) []error {
+	var toValidate []conditionalManifests
+	contentHash := sha256.New()
+	// manifests which cannot be read have no content to compare, they are always validated
+	readable := true
+	for _, conditionalManifest := range c.manifests {
+		if !conditionalManifest.shouldCreateFn() || conditionalManifest.shouldDeleteFn() {
+			continue
+		}
+		toValidate = append(toValidate, conditionalManifest)
+		for _, file := range conditionalManifest.files {
+			objBytes, err := conditionalManifest.manifests(file)
+			if err != nil {
+				// the read error is reported by the dry-run
+				readable = false
+				continue
+			}
+			fmt.Fprintf(contentHash, "%d:%s%d:", len(file), file, len(objBytes))
+			contentHash.Write(objBytes)
+		}
+	}
+	hash := fmt.Sprintf("%x", contentHash.Sum(nil))
+	if readable && hash == c.dryRunValidatedHash {
+		return nil
+	}
+
+	errors := []error{}
+	complete := true
+	for _, conditionalManifest := range toValidate {
+		for _, currResult := range resourceapply.DryRunApplyDirectly(ctx, c.clients, c.restMapper, conditionalManifest.manifests, conditionalManifest.files...) {
+			switch {
+			case currResult.Error == nil:
+			case apierrors.IsNotFound(currResult.Error) || meta.IsNoMatchError(currResult.Error):
+				// the namespace or the CRD is not created yet, the manifest is validated once it is
+				klog.V(2).Infof("Unable to validate %q (%s) with a dry-run yet: %v", currResult.File, currResult.Type, currResult.Error)
+				complete = false
+			default:
+				errors = append(errors, fmt.Errorf("%q (%s): dry-run failed: %v", currResult.File, currResult.Type, currResult.Error))
+			}
+		}
+	}
+	if len(errors) == 0 && complete && readable {
+		c.dryRunValidatedHash = hash
+	}
+	return errors
+}
+
 func (c *StaticResourceController) Name() string {
 	return c.controllerInstanceName
 }
@@ -1,15 +1,30 @@
 package staticresourcecontroller
 
 import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/library-go/pkg/client/openshiftrestmapper"
+	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/restmapper"
-	"testing"
+	clienttesting "k8s.io/client-go/testing"
 )
 
 func TestRelatedObjects(t *testing.T) {
@@ -57,3 +72,310 @@ metadata:
 	res, _ := src.RelatedObjects()
 	assert.ElementsMatch(t, expected, res)
 }
+
+func TestSyncWithDryRunValidation(t *testing.T) {
+	assets := map[string]string{
+		"sa": `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: operand
+  namespace: operand-namespace
+`,
+		"configmap": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: operand-config
+  namespace: operand-namespace
+`,
+	}
+	readBytesFromString := func(filename string) ([]byte, error) {
+		return []byte(assets[filename]), nil
+	}
+
+	tests := []struct {
+		name              string
+		rejectedResource  string
+		expDegradedReason string
+		expApplied        bool
+	}{
+		{
+			name:              "all manifests pass the dry-run",
+			expDegradedReason: "AsExpected",
+			expApplied:        true,
+		},
+		{
+			name:              "configmap is rejected by the dry-run",
+			rejectedResource:  "configmaps",
+			expDegradedReason: "DryRunValidationFailed",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			kubeClient := kubefake.NewSimpleClientset()
+			dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+			var dryRuns int
+			dynamicClient.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				dryRuns++
+				if action.GetResource().Resource == tc.rejectedResource {
+					return true, nil, fmt.Errorf("denied by admission webhook")
+				}
+				return true, nil, nil
+			})
+			operatorClient := v1helpers.NewFakeOperatorClient(
+				&operatorv1.OperatorSpec{ManagementState: operatorv1.Managed},
+				&operatorv1.OperatorStatus{},
+				nil,
+			)
+			clients := resourceapply.NewKubeClientHolder(kubeClient).WithDynamicClient(dynamicClient)
+			recorder := events.NewInMemoryRecorder("")
+
+			c := NewStaticResourceController("Operand", readBytesFromString, []string{"sa", "configmap"}, clients, operatorClient, recorder).
+				WithDryRunValidation()
+			syncErr := c.Sync(context.TODO(), factory.NewSyncContext("StaticResourceController", recorder))
+			if tc.expApplied {
+				require.NoError(t, syncErr)
+			} else {
+				require.Error(t, syncErr)
+			}
+			require.Equal(t, 2, dryRuns)
+
+			_, status, _, _ := operatorClient.GetOperatorState()
+			degraded := v1helpers.FindOperatorCondition(status.Conditions, "OperandDegraded")
+			require.NotNil(t, degraded)
+			require.Equal(t, tc.expDegradedReason, degraded.Reason)
+
+			_, err := kubeClient.CoreV1().ServiceAccounts("operand-namespace").Get(context.TODO(), "operand", metav1.GetOptions{})
+			require.Equal(t, tc.expApplied, err == nil, "unexpected service account applied, err: %v", err)
+		})
+	}
+}
+
+func TestSyncWithApplyOrdering(t *testing.T) {
+	assets := map[string]string{
+		"cr": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: operand-config
+  namespace: operand-namespace
+`,
+		"crd": `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: operands.example.com
+spec:
+  group: example.com
+  names:
+    kind: Operand
+    plural: operands
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+`,
+	}
+	readBytesFromString := func(filename string) ([]byte, error) {
+		return []byte(assets[filename]), nil
+	}
+
+	kubeClient := kubefake.NewSimpleClientset()
+	apiExtensionsClient := apiextensionsfake.NewSimpleClientset()
+	operatorClient := v1helpers.NewFakeOperatorClient(
+		&operatorv1.OperatorSpec{ManagementState: operatorv1.Managed},
+		&operatorv1.OperatorStatus{},
+		nil,
+	)
+	clients := resourceapply.NewKubeClientHolder(kubeClient).WithAPIExtensionsClient(apiExtensionsClient)
+	recorder := events.NewInMemoryRecorder("")
+
+	c := NewStaticResourceController("Operand", readBytesFromString, []string{"cr", "crd"}, clients, operatorClient, recorder).
+		WithApplyOrdering(100 * time.Millisecond)
+	syncCtx := factory.NewSyncContext("StaticResourceController", recorder)
+	require.NoError(t, c.Sync(context.TODO(), syncCtx))
+	require.Eventually(t, func() bool { return syncCtx.Queue().Len() == 1 }, time.Second, 10*time.Millisecond, "expected the sync to be requeued")
+
+	_, status, _, _ := operatorClient.GetOperatorState()
+	degraded := v1helpers.FindOperatorCondition(status.Conditions, "OperandDegraded")
+	require.NotNil(t, degraded)
+	require.Equal(t, operatorv1.ConditionFalse, degraded.Status)
+	require.Equal(t, "WaitingForReadiness", degraded.Reason)
+	require.Contains(t, degraded.Message, "CustomResourceDefinition operands.example.com")
+	_, err := kubeClient.CoreV1().ConfigMaps("operand-namespace").Get(context.TODO(), "operand-config", metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err), "expected the workloads to wait for the CRD, err: %v", err)
+
+	crd, err := apiExtensionsClient.ApiextensionsV1().CustomResourceDefinitions().Get(context.TODO(), "operands.example.com", metav1.GetOptions{})
+	require.NoError(t, err)
+	crd.Status.Conditions = []apiextensionsv1.CustomResourceDefinitionCondition{{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionTrue}}
+	_, err = apiExtensionsClient.ApiextensionsV1().CustomResourceDefinitions().UpdateStatus(context.TODO(), crd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, c.Sync(context.TODO(), factory.NewSyncContext("StaticResourceController", recorder)))
+	_, status, _, _ = operatorClient.GetOperatorState()
+	degraded = v1helpers.FindOperatorCondition(status.Conditions, "OperandDegraded")
+	require.Equal(t, "AsExpected", degraded.Reason)
+	_, err = kubeClient.CoreV1().ConfigMaps("operand-namespace").Get(context.TODO(), "operand-config", metav1.GetOptions{})
+	require.NoError(t, err)
+}
+
+func TestSyncWithApplyOrderingNotBecomingReady(t *testing.T) {
+	crd := `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: operands.example.com
+spec:
+  group: example.com
+  names:
+    kind: Operand
+    plural: operands
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+`
+	configMap := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: operand-config
+  namespace: operand-namespace
+`
+	// both sets have a file of the same name, their classes must not be mixed up
+	crdAssets := func(string) ([]byte, error) { return []byte(crd), nil }
+	configMapAssets := func(string) ([]byte, error) { return []byte(configMap), nil }
+
+	kubeClient := kubefake.NewSimpleClientset()
+	apiExtensionsClient := apiextensionsfake.NewSimpleClientset(&apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "operands.example.com"},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{
+			Conditions: []apiextensionsv1.CustomResourceDefinitionCondition{{
+				Type:    apiextensionsv1.NamesAccepted,
+				Status:  apiextensionsv1.ConditionFalse,
+				Reason:  "KindConflict",
+				Message: "kind Operand is already in use",
+			}},
+		},
+	})
+	operatorClient := v1helpers.NewFakeOperatorClient(
+		&operatorv1.OperatorSpec{ManagementState: operatorv1.Managed},
+		&operatorv1.OperatorStatus{},
+		nil,
+	)
+	clients := resourceapply.NewKubeClientHolder(kubeClient).WithAPIExtensionsClient(apiExtensionsClient)
+	recorder := events.NewInMemoryRecorder("")
+
+	c := NewStaticResourceController("Operand", configMapAssets, []string{"manifest.yaml"}, clients, operatorClient, recorder).
+		WithConditionalResources(crdAssets, []string{"manifest.yaml"}, nil, nil).
+		WithApplyOrdering(100 * time.Millisecond)
+	require.Error(t, c.Sync(context.TODO(), factory.NewSyncContext("StaticResourceController", recorder)))
+
+	_, status, _, _ := operatorClient.GetOperatorState()
+	degraded := v1helpers.FindOperatorCondition(status.Conditions, "OperandDegraded")
+	require.NotNil(t, degraded)
+	require.Equal(t, operatorv1.ConditionTrue, degraded.Status)
+	require.Contains(t, degraded.Message, "names of customresourcedefinition operands.example.com not accepted")
+	_, err := kubeClient.CoreV1().ConfigMaps("operand-namespace").Get(context.TODO(), "operand-config", metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err), "expected the workloads to wait for the CRD, err: %v", err)
+}
+
+func TestSyncWithDryRunValidationOfNewNamespace(t *testing.T) {
+	assets := map[string]string{
+		"namespace": `apiVersion: v1
+kind: Namespace
+metadata:
+  name: operand-namespace
+`,
+		"configmap": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: operand-config
+  namespace: operand-namespace
+`,
+	}
+	readBytesFromString := func(filename string) ([]byte, error) {
+		return []byte(assets[filename]), nil
+	}
+
+	kubeClient := kubefake.NewSimpleClientset()
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	var dryRuns int
+	dynamicClient.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		dryRuns++
+		if namespace := action.GetNamespace(); len(namespace) > 0 {
+			if _, err := kubeClient.CoreV1().Namespaces().Get(context.TODO(), namespace, metav1.GetOptions{}); err != nil {
+				return true, nil, err
+			}
+		}
+		return true, nil, nil
+	})
+	operatorClient := v1helpers.NewFakeOperatorClient(
+		&operatorv1.OperatorSpec{ManagementState: operatorv1.Managed},
+		&operatorv1.OperatorStatus{},
+		nil,
+	)
+	clients := resourceapply.NewKubeClientHolder(kubeClient).WithDynamicClient(dynamicClient)
+	recorder := events.NewInMemoryRecorder("")
+
+	c := NewStaticResourceController("Operand", readBytesFromString, []string{"namespace", "configmap"}, clients, operatorClient, recorder).
+		WithDryRunValidation()
+
+	// the config map cannot be validated before its namespace exists, which must not block applying both
+	require.NoError(t, c.Sync(context.TODO(), factory.NewSyncContext("StaticResourceController", recorder)))
+	require.Equal(t, 2, dryRuns)
+	_, err := kubeClient.CoreV1().ConfigMaps("operand-namespace").Get(context.TODO(), "operand-config", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// the config map is validated once its namespace exists
+	require.NoError(t, c.Sync(context.TODO(), factory.NewSyncContext("StaticResourceController", recorder)))
+	require.Equal(t, 4, dryRuns)
+
+	// the validated manifests are not validated again on resync
+	require.NoError(t, c.Sync(context.TODO(), factory.NewSyncContext("StaticResourceController", recorder)))
+	require.Equal(t, 4, dryRuns)
+}
+
+func TestSyncWithDryRunValidationOfUnreadableManifest(t *testing.T) {
+	manifest := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: operand-config
+  namespace: operand-namespace
+`
+	var readErr error
+	readBytes := func(filename string) ([]byte, error) {
+		if readErr != nil {
+			return nil, readErr
+		}
+		return []byte(manifest), nil
+	}
+
+	kubeClient := kubefake.NewSimpleClientset()
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	var dryRuns int
+	dynamicClient.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		dryRuns++
+		return true, nil, nil
+	})
+	operatorClient := v1helpers.NewFakeOperatorClient(
+		&operatorv1.OperatorSpec{ManagementState: operatorv1.Managed},
+		&operatorv1.OperatorStatus{},
+		nil,
+	)
+	clients := resourceapply.NewKubeClientHolder(kubeClient).WithDynamicClient(dynamicClient)
+	recorder := events.NewInMemoryRecorder("")
+
+	c := NewStaticResourceController("Operand", readBytes, []string{"configmap"}, clients, operatorClient, recorder).
+		WithDryRunValidation()
+
+	require.NoError(t, c.Sync(context.TODO(), factory.NewSyncContext("StaticResourceController", recorder)))
+	require.Equal(t, 1, dryRuns)
+
+	// a manifest which cannot be read must not match the validated content
+	readErr = fmt.Errorf("transient read error")
+	err := c.Sync(context.TODO(), factory.NewSyncContext("StaticResourceController", recorder))
+	require.ErrorContains(t, err, "dry-run failed")
+
+	// once it can be read again, the unchanged content is not validated again
+	readErr = nil
+	require.NoError(t, c.Sync(context.TODO(), factory.NewSyncContext("StaticResourceController", recorder)))
+	require.Equal(t, 1, dryRuns)
+}
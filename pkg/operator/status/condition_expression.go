@@ -0,0 +1,232 @@
+package status
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+// MetricSource returns the current value of the named metric, and false when it has no value.
+type MetricSource func(name string) (value float64, ok bool)
+
+// conditionExpression is a parsed expression of a derived condition. The grammar is:
+//
+//	expression := or
+//	or         := and { "||" and }
+//	and        := unary { "&&" unary }
+//	unary      := "!" unary | "(" expression ")" | comparison | condition
+//	comparison := metric ( ">" | ">=" | "<" | "<=" | "==" | "!=" ) number
+//	condition  := the type of a condition, true when the condition status is True
+type conditionExpression interface {
+	eval(env *expressionEnv) (bool, error)
+}
+
+type expressionEnv struct {
+	conditions map[string]operatorv1.ConditionStatus
+	metrics    MetricSource
+}
+
+type conditionTerm struct {
+	conditionType string
+}
+
+func (e conditionTerm) eval(env *expressionEnv) (bool, error) {
+	return env.conditions[e.conditionType] == operatorv1.ConditionTrue, nil
+}
+
+type notTerm struct {
+	operand conditionExpression
+}
+
+func (e notTerm) eval(env *expressionEnv) (bool, error) {
+	value, err := e.operand.eval(env)
+	return !value, err
+}
+
+type binaryTerm struct {
+	and         bool
+	left, right conditionExpression
+}
+
+func (e binaryTerm) eval(env *expressionEnv) (bool, error) {
+	left, err := e.left.eval(env)
+	if err != nil {
+		return false, err
+	}
+	// short-circuit like CEL, so a missing metric does not matter when the result is already known
+	if left != e.and {
+		return left, nil
+	}
+	return e.right.eval(env)
+}
+
+type comparisonTerm struct {
+	metric   string
+	operator string
+	value    float64
+}
+
+func (e comparisonTerm) eval(env *expressionEnv) (bool, error) {
+	if env.metrics == nil {
+		return false, fmt.Errorf("metric %q is not available", e.metric)
+	}
+	value, ok := env.metrics(e.metric)
+	if !ok {
+		return false, fmt.Errorf("metric %q is not available", e.metric)
+	}
+	switch e.operator {
+	case ">":
+		return value > e.value, nil
+	case ">=":
+		return value >= e.value, nil
+	case "<":
+		return value < e.value, nil
+	case "<=":
+		return value <= e.value, nil
+	case "==":
+		return value == e.value, nil
+	default:
+		return value != e.value, nil
+	}
+}
+
+// parseConditionExpression parses the expression of a derived condition.
+func parseConditionExpression(expression string) (conditionExpression, error) {
+	tokens, err := tokenizeConditionExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &expressionParser{tokens: tokens}
+	ret, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	return ret, nil
+}
+
+func tokenizeConditionExpression(expression string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(expression); {
+		c := rune(expression[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case strings.HasPrefix(expression[i:], "&&"), strings.HasPrefix(expression[i:], "||"),
+			strings.HasPrefix(expression[i:], ">="), strings.HasPrefix(expression[i:], "<="),
+			strings.HasPrefix(expression[i:], "=="), strings.HasPrefix(expression[i:], "!="):
+			tokens = append(tokens, expression[i:i+2])
+			i += 2
+		case strings.ContainsRune("!()<>", c):
+			tokens = append(tokens, string(c))
+			i++
+		case isIdentifierRune(c, true) || unicode.IsDigit(c) || c == '.' || c == '-':
+			start := i
+			for i++; i < len(expression) && (isIdentifierRune(rune(expression[i]), false) || expression[i] == '.'); i++ {
+			}
+			tokens = append(tokens, expression[start:i])
+		default:
+			return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+		}
+	}
+	return tokens, nil
+}
+
+func isIdentifierRune(c rune, first bool) bool {
+	if first {
+		return unicode.IsLetter(c) || c == '_'
+	}
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == ':'
+}
+
+type expressionParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *expressionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *expressionParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *expressionParser) parseOr() (conditionExpression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryTerm{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseAnd() (conditionExpression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryTerm{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseUnary() (conditionExpression, error) {
+	token := p.next()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case token == "!":
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notTerm{operand: operand}, nil
+	case token == "(":
+		ret, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return ret, nil
+	case !isIdentifierRune(rune(token[0]), true):
+		return nil, fmt.Errorf("unexpected %q", token)
+	}
+
+	switch operator := p.peek(); operator {
+	case ">", ">=", "<", "<=", "==", "!=":
+		p.next()
+		number := p.next()
+		value, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s must be followed by a number, got %q", token, operator, number)
+		}
+		return comparisonTerm{metric: token, operator: operator, value: value}, nil
+	}
+	return conditionTerm{conditionType: token}, nil
+}
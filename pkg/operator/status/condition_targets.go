@@ -0,0 +1,115 @@
+package status
+
+import (
+	"context"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// ConditionTarget receives the aggregated ClusterOperator conditions, so that the same conditions can be
+// reported on additional objects, like the operator's own custom resource, next to the ClusterOperator.
+type ConditionTarget interface {
+	// Name identifies the target in errors.
+	Name() string
+	// SetConditions writes the aggregated conditions to the target.
+	SetConditions(ctx context.Context, conditions []configv1.ClusterOperatorStatusCondition) error
+	// OwnedConditionTypes returns the operator condition types written by this target. These are excluded
+	// from the aggregation, otherwise a target writing to the operator status would aggregate its own output.
+	OwnedConditionTypes() sets.Set[string]
+}
+
+// ConditionMapping maps an aggregated ClusterOperator condition type to the condition type written to a target.
+// Aggregated conditions without a mapping are not written.
+type ConditionMapping map[configv1.ClusterStatusConditionType]string
+
+// DefaultOperatorConditionMapping writes the aggregated conditions with a "ClusterOperator" prefix,
+// e.g. Degraded is written as ClusterOperatorDegraded.
+var DefaultOperatorConditionMapping = ConditionMapping{
+	configv1.OperatorAvailable:   "ClusterOperatorAvailable",
+	configv1.OperatorProgressing: "ClusterOperatorProgressing",
+	configv1.OperatorDegraded:    "ClusterOperatorDegraded",
+	configv1.OperatorUpgradeable: "ClusterOperatorUpgradeable",
+}
+
+type operatorClientConditionTarget struct {
+	name    string
+	client  operatorv1helpers.OperatorClient
+	mapping ConditionMapping
+}
+
+// NewOperatorClientConditionTarget returns a target writing the mapped aggregated conditions into the
+// status of the operator resource behind the client.
+func NewOperatorClientConditionTarget(name string, client operatorv1helpers.OperatorClient, mapping ConditionMapping) ConditionTarget {
+	return &operatorClientConditionTarget{
+		name:    name,
+		client:  client,
+		mapping: mapping,
+	}
+}
+
+func (t *operatorClientConditionTarget) Name() string {
+	return t.name
+}
+
+func (t *operatorClientConditionTarget) OwnedConditionTypes() sets.Set[string] {
+	owned := sets.New[string]()
+	for _, targetType := range t.mapping {
+		owned.Insert(targetType)
+	}
+	return owned
+}
+
+func (t *operatorClientConditionTarget) SetConditions(ctx context.Context, conditions []configv1.ClusterOperatorStatusCondition) error {
+	var updateFuncs []operatorv1helpers.UpdateStatusFunc
+	for _, condition := range conditions {
+		targetType, ok := t.mapping[condition.Type]
+		if !ok {
+			continue
+		}
+		updateFuncs = append(updateFuncs, operatorv1helpers.UpdateConditionFn(operatorv1.OperatorCondition{
+			Type:    targetType,
+			Status:  operatorv1.ConditionStatus(condition.Status),
+			Reason:  condition.Reason,
+			Message: condition.Message,
+		}))
+	}
+	if len(updateFuncs) == 0 {
+		return nil
+	}
+	_, _, err := operatorv1helpers.UpdateStatus(ctx, t.client, updateFuncs...)
+	return err
+}
+
+// filterTargetOwnedConditions drops the conditions written by the condition targets.
+func filterTargetOwnedConditions(targets []ConditionTarget, conditions []operatorv1.OperatorCondition) []operatorv1.OperatorCondition {
+	if len(targets) == 0 {
+		return conditions
+	}
+	owned := sets.New[string]()
+	for _, target := range targets {
+		owned = owned.Union(target.OwnedConditionTypes())
+	}
+	filtered := make([]operatorv1.OperatorCondition, 0, len(conditions))
+	for _, condition := range conditions {
+		if !owned.Has(condition.Type) {
+			filtered = append(filtered, condition)
+		}
+	}
+	return filtered
+}
+
+// setTargetConditions writes the conditions to all targets. Every target is attempted, failures are aggregated.
+func setTargetConditions(ctx context.Context, targets []ConditionTarget, conditions []configv1.ClusterOperatorStatusCondition) error {
+	var errs []error
+	for _, target := range targets {
+		if err := target.SetConditions(ctx, conditions); err != nil {
+			errs = append(errs, fmt.Errorf("unable to set conditions on %s: %w", target.Name(), err))
+		}
+	}
+	return operatorv1helpers.NewMultiLineAggregate(errs)
+}
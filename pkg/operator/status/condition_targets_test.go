@@ -0,0 +1,74 @@
+package status
+
+import (
+	"context"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/client-go/config/clientset/versioned/fake"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestConditionTargets(t *testing.T) {
+	clusterOperator := &configv1.ClusterOperator{ObjectMeta: metav1.ObjectMeta{Name: "OPERATOR_NAME", ResourceVersion: "12"}}
+	clusterOperatorClient := fake.NewSimpleClientset(clusterOperator)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(clusterOperator); err != nil {
+		t.Fatal(err)
+	}
+
+	operatorClient := operatorv1helpers.NewFakeOperatorClient(
+		&operatorv1.OperatorSpec{ManagementState: operatorv1.Managed},
+		&operatorv1.OperatorStatus{
+			Conditions: []operatorv1.OperatorCondition{
+				{Type: "FooDegraded", Status: operatorv1.ConditionTrue, Reason: "Broken", Message: "foo is broken"},
+				{Type: "FooAvailable", Status: operatorv1.ConditionTrue},
+			},
+		},
+		nil,
+	)
+	controller := (&StatusSyncer{
+		clusterOperatorName:   "OPERATOR_NAME",
+		clusterOperatorClient: clusterOperatorClient.ConfigV1(),
+		clusterOperatorLister: configv1listers.NewClusterOperatorLister(indexer),
+		operatorClient:        operatorClient,
+		versionGetter:         NewVersionGetter(),
+	}).WithConditionTargets(NewOperatorClientConditionTarget("operator", operatorClient, DefaultOperatorConditionMapping))
+
+	// syncing twice makes sure the target conditions are not aggregated into themselves
+	for i := 0; i < 2; i++ {
+		if err := controller.Sync(context.TODO(), factory.NewSyncContext("test", events.NewInMemoryRecorder("status"))); err != nil {
+			t.Fatalf("unexpected sync error: %v", err)
+		}
+	}
+
+	result, err := clusterOperatorClient.ConfigV1().ClusterOperators().Get(context.TODO(), "OPERATOR_NAME", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, status, _, err := operatorClient.GetOperatorState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for clusterOperatorType, operatorType := range DefaultOperatorConditionMapping {
+		expected := v1helpers.FindStatusCondition(result.Status.Conditions, clusterOperatorType)
+		actual := operatorv1helpers.FindOperatorCondition(status.Conditions, operatorType)
+		if expected == nil || actual == nil {
+			t.Fatalf("expected %s and %s to be set, got %v and %v", clusterOperatorType, operatorType, expected, actual)
+		}
+		if string(expected.Status) != string(actual.Status) || expected.Reason != actual.Reason || expected.Message != actual.Message {
+			t.Errorf("expected %s to match %s, got %#v and %#v", operatorType, clusterOperatorType, actual, expected)
+		}
+	}
+	if degraded := v1helpers.FindStatusCondition(result.Status.Conditions, configv1.OperatorDegraded); degraded.Reason != "Foo_Broken" {
+		t.Errorf("expected only FooDegraded to be aggregated, got %#v", degraded)
+	}
+}
@@ -0,0 +1,150 @@
+package status
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+)
+
+// ConditionConventionRule names a condition convention.
+type ConditionConventionRule string
+
+const (
+	// ConditionTypeSuffixRule requires condition types to end with one of the suffixes aggregated into the ClusterOperator.
+	ConditionTypeSuffixRule ConditionConventionRule = "TypeSuffix"
+	// ConditionReasonFormatRule requires reasons to be CamelCase.
+	ConditionReasonFormatRule ConditionConventionRule = "ReasonFormat"
+	// ConditionReasonRequiredRule requires a reason when the condition reports a problem.
+	ConditionReasonRequiredRule ConditionConventionRule = "ReasonRequired"
+	// ConditionMessageRequiredRule requires a message when the condition reports a problem.
+	ConditionMessageRequiredRule ConditionConventionRule = "MessageRequired"
+)
+
+// conditionSuffixProblemStatus maps the known condition type suffixes to the status which reports a problem.
+var conditionSuffixProblemStatus = map[string]operatorv1.ConditionStatus{
+	"Degraded":                     operatorv1.ConditionTrue,
+	"Progressing":                  operatorv1.ConditionTrue,
+	"Available":                    operatorv1.ConditionFalse,
+	"Upgradeable":                  operatorv1.ConditionFalse,
+	"EvaluationConditionsDetected": operatorv1.ConditionTrue,
+}
+
+var camelCaseRegex = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+// ConditionConventionViolation describes a condition not following the conventions.
+type ConditionConventionViolation struct {
+	ConditionType string
+	Rule          ConditionConventionRule
+	Message       string
+}
+
+func (v ConditionConventionViolation) String() string {
+	return fmt.Sprintf("condition %q violates %s: %s", v.ConditionType, v.Rule, v.Message)
+}
+
+// ValidateConditionConventions checks the conditions against the operator condition conventions:
+//   - the type ends with Available, Progressing, Degraded, Upgradeable or EvaluationConditionsDetected, otherwise
+//     the condition is never aggregated into the ClusterOperator
+//   - the reason, when set, is CamelCase
+//   - conditions reporting a problem (e.g. Degraded=True or Available=False) carry a reason and a message
+func ValidateConditionConventions(conditions ...operatorv1.OperatorCondition) []ConditionConventionViolation {
+	var violations []ConditionConventionViolation
+	for _, condition := range conditions {
+		problemStatus, knownSuffix := conditionProblemStatus(condition.Type)
+		if !knownSuffix {
+			violations = append(violations, ConditionConventionViolation{
+				ConditionType: condition.Type,
+				Rule:          ConditionTypeSuffixRule,
+				Message:       "type must end with one of Available, Progressing, Degraded, Upgradeable or EvaluationConditionsDetected",
+			})
+		}
+		if len(condition.Reason) > 0 && !camelCaseRegex.MatchString(condition.Reason) {
+			violations = append(violations, ConditionConventionViolation{
+				ConditionType: condition.Type,
+				Rule:          ConditionReasonFormatRule,
+				Message:       fmt.Sprintf("reason %q must be CamelCase", condition.Reason),
+			})
+		}
+		if !knownSuffix || condition.Status != problemStatus {
+			continue
+		}
+		if len(condition.Reason) == 0 {
+			violations = append(violations, ConditionConventionViolation{
+				ConditionType: condition.Type,
+				Rule:          ConditionReasonRequiredRule,
+				Message:       fmt.Sprintf("reason must be set when status is %s", condition.Status),
+			})
+		}
+		if len(strings.TrimSpace(condition.Message)) == 0 {
+			violations = append(violations, ConditionConventionViolation{
+				ConditionType: condition.Type,
+				Rule:          ConditionMessageRequiredRule,
+				Message:       fmt.Sprintf("message must be set when status is %s", condition.Status),
+			})
+		}
+	}
+	return violations
+}
+
+func conditionProblemStatus(conditionType string) (operatorv1.ConditionStatus, bool) {
+	for suffix, status := range conditionSuffixProblemStatus {
+		if strings.HasSuffix(conditionType, suffix) {
+			return status, true
+		}
+	}
+	return "", false
+}
+
+var conditionConventionViolationsMetric = metrics.NewCounterVec(&metrics.CounterOpts{
+	Subsystem:      "operator_status",
+	Name:           "condition_convention_violations_total",
+	Help:           "Number of distinct operator condition convention violations observed, by cluster operator, condition type and rule.",
+	StabilityLevel: metrics.ALPHA,
+}, []string{"clusteroperator", "condition_type", "rule"})
+
+func init() {
+	(&sync.Once{}).Do(func() {
+		legacyregistry.MustRegister(conditionConventionViolationsMetric)
+	})
+}
+
+// conditionConventionsChecker reports every distinct violation once, so that busy operators do not flood the logs.
+type conditionConventionsChecker struct {
+	clusterOperatorName string
+
+	lock     sync.Mutex
+	reported sets.Set[string]
+}
+
+func newConditionConventionsChecker(clusterOperatorName string) *conditionConventionsChecker {
+	return &conditionConventionsChecker{
+		clusterOperatorName: clusterOperatorName,
+		reported:            sets.New[string](),
+	}
+}
+
+func (c *conditionConventionsChecker) check(conditions []operatorv1.OperatorCondition) {
+	violations := ValidateConditionConventions(conditions...)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	current := sets.New[string]()
+	for _, violation := range violations {
+		key := violation.String()
+		current.Insert(key)
+		if c.reported.Has(key) {
+			continue
+		}
+		klog.Warningf("clusteroperator/%s: %s", c.clusterOperatorName, key)
+		conditionConventionViolationsMetric.WithLabelValues(c.clusterOperatorName, violation.ConditionType, string(violation.Rule)).Inc()
+	}
+	// forget violations that were fixed, so that they are reported again if they come back
+	c.reported = current
+}
@@ -0,0 +1,78 @@
+package status
+
+import (
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestValidateConditionConventions(t *testing.T) {
+	testCases := []struct {
+		name          string
+		conditions    []operatorv1.OperatorCondition
+		expectedRules []ConditionConventionRule
+	}{
+		{
+			name: "conforming",
+			conditions: []operatorv1.OperatorCondition{
+				{Type: "FooDegraded", Status: operatorv1.ConditionFalse},
+				{Type: "FooDegraded", Status: operatorv1.ConditionTrue, Reason: "SyncError", Message: "failed"},
+				{Type: "FooAvailable", Status: operatorv1.ConditionTrue, Reason: "AsExpected"},
+				{Type: "FooUpgradeable", Status: operatorv1.ConditionFalse, Reason: "Blocked", Message: "blocked"},
+			},
+		},
+		{
+			name: "unknown suffix",
+			conditions: []operatorv1.OperatorCondition{
+				{Type: "FooFailing", Status: operatorv1.ConditionTrue},
+			},
+			expectedRules: []ConditionConventionRule{ConditionTypeSuffixRule},
+		},
+		{
+			name: "reason format",
+			conditions: []operatorv1.OperatorCondition{
+				{Type: "FooProgressing", Status: operatorv1.ConditionFalse, Reason: "not_camel"},
+			},
+			expectedRules: []ConditionConventionRule{ConditionReasonFormatRule},
+		},
+		{
+			name: "problem without reason and message",
+			conditions: []operatorv1.OperatorCondition{
+				{Type: "FooAvailable", Status: operatorv1.ConditionFalse},
+			},
+			expectedRules: []ConditionConventionRule{ConditionReasonRequiredRule, ConditionMessageRequiredRule},
+		},
+		{
+			name: "unknown status is not a problem",
+			conditions: []operatorv1.OperatorCondition{
+				{Type: "FooDegraded", Status: operatorv1.ConditionUnknown},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actualRules []ConditionConventionRule
+			for _, violation := range ValidateConditionConventions(tc.conditions...) {
+				actualRules = append(actualRules, violation.Rule)
+			}
+			if !reflect.DeepEqual(tc.expectedRules, actualRules) {
+				t.Errorf("expected %v, got %v", tc.expectedRules, actualRules)
+			}
+		})
+	}
+}
+
+func TestConditionConventionsCheckerReportsOnce(t *testing.T) {
+	checker := newConditionConventionsChecker("test")
+	conditions := []operatorv1.OperatorCondition{{Type: "FooFailing"}}
+	checker.check(conditions)
+	checker.check(conditions)
+	if checker.reported.Len() != 1 {
+		t.Errorf("expected one reported violation, got %v", checker.reported)
+	}
+	checker.check(nil)
+	if checker.reported.Len() != 0 {
+		t.Errorf("expected fixed violations to be forgotten, got %v", checker.reported)
+	}
+}
@@ -0,0 +1,150 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// DerivedCondition computes a condition from the other conditions of the operator and from metrics, e.g.
+//
+//	DerivedCondition{
+//		Type:       "EtcdMembersDegraded",
+//		Expression: "EtcdMembersProgressing && !EtcdMembersAvailable || etcd_member_restarts > 3",
+//		For:        10 * time.Minute,
+//		Reason:     "MembersUnavailable",
+//	}
+//
+// A condition type in the expression is true when the status of the condition is True, a missing condition is
+// false. A metric can only be compared to a number. && binds tighter than ||, and parenthesis group.
+type DerivedCondition struct {
+	// Type is the type of the derived condition.
+	Type string
+	// Expression decides the status of the condition.
+	Expression string
+	// For is how long the expression must be true before the condition becomes True.
+	For time.Duration
+	// Reason is the reason of the condition when it is True. It defaults to ExpressionTrue.
+	Reason string
+	// Message is the message of the condition when it is True. It defaults to the expression.
+	Message string
+}
+
+type derivedConditionRule struct {
+	DerivedCondition
+	expression conditionExpression
+}
+
+// DerivedConditions computes the derived conditions of an operator. The conditions are computed in order, so a
+// derived condition can be used in the expressions of the ones following it. It remembers since when the expressions
+// are true, so it has to be kept for the lifetime of the operator.
+type DerivedConditions struct {
+	rules []derivedConditionRule
+	clock clock.PassiveClock
+
+	lock      sync.Mutex
+	trueSince map[string]time.Time
+}
+
+// NewDerivedConditions parses the expressions of the derived conditions.
+func NewDerivedConditions(conditions ...DerivedCondition) (*DerivedConditions, error) {
+	ret := &DerivedConditions{clock: clock.RealClock{}, trueSince: map[string]time.Time{}}
+	for _, condition := range conditions {
+		if len(condition.Type) == 0 {
+			return nil, fmt.Errorf("derived condition %q has no type", condition.Expression)
+		}
+		expression, err := parseConditionExpression(condition.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression of the derived condition %s: %w", condition.Type, err)
+		}
+		ret.rules = append(ret.rules, derivedConditionRule{DerivedCondition: condition, expression: expression})
+	}
+	return ret, nil
+}
+
+// MustNewDerivedConditions is like NewDerivedConditions but panics on error.
+func MustNewDerivedConditions(conditions ...DerivedCondition) *DerivedConditions {
+	ret, err := NewDerivedConditions(conditions...)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// WithClock replaces the clock used to measure how long the expressions are true.
+func (d *DerivedConditions) WithClock(clock clock.PassiveClock) *DerivedConditions {
+	d.clock = clock
+	return d
+}
+
+// Evaluate returns the derived conditions computed from the given conditions and metrics, which may be nil when no
+// expression uses a metric. A condition whose expression cannot be evaluated, e.g. because of a missing metric, is
+// Unknown.
+func (d *DerivedConditions) Evaluate(conditions []operatorv1.OperatorCondition, metrics MetricSource) []operatorv1.OperatorCondition {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	env := &expressionEnv{conditions: map[string]operatorv1.ConditionStatus{}, metrics: metrics}
+	for _, condition := range conditions {
+		env.conditions[condition.Type] = condition.Status
+	}
+
+	now := d.clock.Now()
+	var ret []operatorv1.OperatorCondition
+	for _, rule := range d.rules {
+		condition := operatorv1.OperatorCondition{Type: rule.Type, Status: operatorv1.ConditionFalse, Reason: "AsExpected"}
+		value, err := rule.expression.eval(env)
+		switch {
+		case err != nil:
+			delete(d.trueSince, rule.Type)
+			condition.Status = operatorv1.ConditionUnknown
+			condition.Reason = "EvaluationFailed"
+			condition.Message = err.Error()
+		case !value:
+			delete(d.trueSince, rule.Type)
+		default:
+			since, ok := d.trueSince[rule.Type]
+			if !ok {
+				since = now
+				d.trueSince[rule.Type] = since
+			}
+			if now.Sub(since) < rule.For {
+				condition.Message = fmt.Sprintf("%s has been true since %s, for less than %s", rule.Expression, since.Format(time.RFC3339), rule.For)
+				break
+			}
+			condition.Status = operatorv1.ConditionTrue
+			condition.Reason = rule.Reason
+			if len(condition.Reason) == 0 {
+				condition.Reason = "ExpressionTrue"
+			}
+			condition.Message = rule.Message
+			if len(condition.Message) == 0 {
+				condition.Message = rule.Expression
+			}
+		}
+		env.conditions[condition.Type] = condition.Status
+		ret = append(ret, condition)
+	}
+	return ret
+}
+
+// UpdateStatus evaluates the derived conditions from the operator status and sets them in the status.
+func (d *DerivedConditions) UpdateStatus(ctx context.Context, client operatorv1helpers.OperatorClient, metrics MetricSource) error {
+	_, status, _, err := client.GetOperatorState()
+	if err != nil {
+		return err
+	}
+	var updateFuncs []operatorv1helpers.UpdateStatusFunc
+	for _, condition := range d.Evaluate(status.Conditions, metrics) {
+		updateFuncs = append(updateFuncs, operatorv1helpers.UpdateConditionFn(condition))
+	}
+	_, _, err = operatorv1helpers.UpdateStatus(ctx, client, updateFuncs...)
+	return err
+}
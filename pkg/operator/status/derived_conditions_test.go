@@ -0,0 +1,135 @@
+package status
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestConditionExpression(t *testing.T) {
+	conditions := map[string]operatorv1.ConditionStatus{
+		"FooDegraded":  operatorv1.ConditionTrue,
+		"FooAvailable": operatorv1.ConditionFalse,
+		"BarDegraded":  operatorv1.ConditionUnknown,
+	}
+	metrics := func(name string) (float64, bool) {
+		value, ok := map[string]float64{"restarts_total": 3, "ratio:rate5m": 0.5}[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		expression    string
+		expected      bool
+		expectedError bool
+	}{
+		{expression: "FooDegraded", expected: true},
+		{expression: "BarDegraded", expected: false},
+		{expression: "Missing", expected: false},
+		{expression: "FooDegraded && !FooAvailable", expected: true},
+		{expression: "FooAvailable || BarDegraded", expected: false},
+		{expression: "FooAvailable && BarDegraded || FooDegraded", expected: true},
+		{expression: "FooAvailable && (BarDegraded || FooDegraded)", expected: false},
+		{expression: "!(FooDegraded && FooAvailable)", expected: true},
+		{expression: "restarts_total >= 3 && ratio:rate5m < 0.75", expected: true},
+		{expression: "restarts_total > 3", expected: false},
+		{expression: "restarts_total != -1", expected: true},
+		{expression: "FooAvailable && missing_metric > 1", expected: false},
+		{expression: "missing_metric > 1", expectedError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			expression, err := parseConditionExpression(tt.expression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			value, err := expression.eval(&expressionEnv{conditions: conditions, metrics: metrics})
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if value != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, value)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"", "FooDegraded &&", "(FooDegraded", "FooDegraded)", "restarts_total > Foo", "FooDegraded & BarDegraded", "3 > restarts_total"} {
+		if _, err := parseConditionExpression(invalid); err == nil {
+			t.Errorf("expected %q to be invalid", invalid)
+		}
+	}
+}
+
+func TestDerivedConditions(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Now())
+	derived := MustNewDerivedConditions(
+		DerivedCondition{Type: "FooDegraded", Expression: "FooProgressing && !FooAvailable", For: 10 * time.Minute, Reason: "Stuck"},
+		DerivedCondition{Type: "Degraded", Expression: "FooDegraded || BarDegraded"},
+	).WithClock(clock)
+
+	stuck := []operatorv1.OperatorCondition{
+		{Type: "FooProgressing", Status: operatorv1.ConditionTrue},
+		{Type: "FooAvailable", Status: operatorv1.ConditionFalse},
+	}
+	expectStatuses := func(conditions []operatorv1.OperatorCondition, expected ...operatorv1.ConditionStatus) {
+		t.Helper()
+		if len(conditions) != len(expected) {
+			t.Fatalf("expected %d conditions, got %v", len(expected), conditions)
+		}
+		for i := range conditions {
+			if conditions[i].Status != expected[i] {
+				t.Errorf("expected %s to be %s, got %s: %s", conditions[i].Type, expected[i], conditions[i].Status, conditions[i].Message)
+			}
+		}
+	}
+
+	expectStatuses(derived.Evaluate(stuck, nil), operatorv1.ConditionFalse, operatorv1.ConditionFalse)
+	clock.Step(5 * time.Minute)
+	expectStatuses(derived.Evaluate(stuck, nil), operatorv1.ConditionFalse, operatorv1.ConditionFalse)
+	clock.Step(5 * time.Minute)
+	conditions := derived.Evaluate(stuck, nil)
+	expectStatuses(conditions, operatorv1.ConditionTrue, operatorv1.ConditionTrue)
+	if conditions[0].Reason != "Stuck" || conditions[1].Reason != "ExpressionTrue" {
+		t.Errorf("unexpected reasons %v", conditions)
+	}
+
+	// the expression must be true for the whole duration again once it was false
+	expectStatuses(derived.Evaluate(nil, nil), operatorv1.ConditionFalse, operatorv1.ConditionFalse)
+	clock.Step(5 * time.Minute)
+	expectStatuses(derived.Evaluate(stuck, nil), operatorv1.ConditionFalse, operatorv1.ConditionFalse)
+
+	if _, err := NewDerivedConditions(DerivedCondition{Type: "Degraded", Expression: "Foo &&"}); err == nil {
+		t.Error("expected an invalid expression to fail")
+	}
+}
+
+func TestDerivedConditionsUpdateStatus(t *testing.T) {
+	client := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{
+		Conditions: []operatorv1.OperatorCondition{{Type: "FooDegraded", Status: operatorv1.ConditionTrue}},
+	}, nil)
+	derived := MustNewDerivedConditions(
+		DerivedCondition{Type: "Degraded", Expression: "FooDegraded && queue_depth > 10"},
+	)
+
+	if err := derived.UpdateStatus(context.TODO(), client, nil); err != nil {
+		t.Fatal(err)
+	}
+	_, status, _, _ := client.GetOperatorState()
+	if condition := v1helpers.FindOperatorCondition(status.Conditions, "Degraded"); condition == nil || condition.Status != operatorv1.ConditionUnknown || condition.Reason != "EvaluationFailed" {
+		t.Errorf("expected Degraded to be unknown without metrics, got %v", condition)
+	}
+
+	metrics := func(string) (float64, bool) { return 20, true }
+	if err := derived.UpdateStatus(context.TODO(), client, metrics); err != nil {
+		t.Fatal(err)
+	}
+	_, status, _, _ = client.GetOperatorState()
+	if condition := v1helpers.FindOperatorCondition(status.Conditions, "Degraded"); condition == nil || condition.Status != operatorv1.ConditionTrue {
+		t.Errorf("expected Degraded to be true, got %v", condition)
+	}
+}
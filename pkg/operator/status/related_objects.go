@@ -0,0 +1,74 @@
+package status
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+// RelatedObjectsPolicy controls the normalization and size of the related objects reported in the ClusterOperator.
+type RelatedObjectsPolicy struct {
+	// MaxEntries is the maximum number of related objects reported. Zero means no limit.
+	MaxEntries int
+	// LastDegraded returns when the object was last involved in a degraded condition, or the zero time if never.
+	// When the related objects exceed MaxEntries, the most recently degraded objects are kept first.
+	// Optional, when nil the first MaxEntries objects are kept.
+	LastDegraded func(configv1.ObjectReference) time.Time
+}
+
+// NormalizeRelatedObjects lowercases groups and resources, turns kinds accidentally used as resources
+// (e.g. "Deployment") into plural resources and removes duplicates, keeping the order of first occurrence.
+func NormalizeRelatedObjects(objs []configv1.ObjectReference) []configv1.ObjectReference {
+	seen := map[configv1.ObjectReference]bool{}
+	ret := make([]configv1.ObjectReference, 0, len(objs))
+	for _, obj := range objs {
+		obj.Group = strings.ToLower(obj.Group)
+		if strings.ToLower(obj.Resource) != obj.Resource {
+			plural, _ := meta.UnsafeGuessKindToResource(schema.GroupVersionKind{Kind: obj.Resource})
+			obj.Resource = plural.Resource
+		}
+		if seen[obj] {
+			continue
+		}
+		seen[obj] = true
+		ret = append(ret, obj)
+	}
+	return ret
+}
+
+// ApplyRelatedObjectsPolicy normalizes the related objects and limits them to policy.MaxEntries. The kept
+// objects stay in their original order to avoid needless ClusterOperator updates.
+func ApplyRelatedObjectsPolicy(policy RelatedObjectsPolicy, objs []configv1.ObjectReference) []configv1.ObjectReference {
+	normalized := NormalizeRelatedObjects(objs)
+	if policy.MaxEntries <= 0 || len(normalized) <= policy.MaxEntries {
+		return normalized
+	}
+
+	indexes := make([]int, len(normalized))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	if policy.LastDegraded != nil {
+		lastDegraded := make([]time.Time, len(normalized))
+		for i, obj := range normalized {
+			lastDegraded[i] = policy.LastDegraded(obj)
+		}
+		sort.SliceStable(indexes, func(i, j int) bool {
+			return lastDegraded[indexes[i]].After(lastDegraded[indexes[j]])
+		})
+	}
+	kept := indexes[:policy.MaxEntries]
+	sort.Ints(kept)
+
+	ret := make([]configv1.ObjectReference, 0, policy.MaxEntries)
+	for _, i := range kept {
+		ret = append(ret, normalized[i])
+	}
+	klog.V(2).Infof("dropped %d related objects exceeding the limit of %d", len(normalized)-policy.MaxEntries, policy.MaxEntries)
+	return ret
+}
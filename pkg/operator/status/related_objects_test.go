@@ -0,0 +1,84 @@
+package status
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func TestApplyRelatedObjectsPolicy(t *testing.T) {
+	ref := func(group, resource, name string) configv1.ObjectReference {
+		return configv1.ObjectReference{Group: group, Resource: resource, Namespace: "ns", Name: name}
+	}
+	now := time.Now()
+
+	testCases := []struct {
+		name     string
+		policy   RelatedObjectsPolicy
+		objs     []configv1.ObjectReference
+		expected []configv1.ObjectReference
+	}{
+		{
+			name: "normalize and deduplicate",
+			objs: []configv1.ObjectReference{
+				ref("Apps", "Deployment", "a"),
+				ref("apps", "deployments", "a"),
+				ref("", "ConfigMap", "b"),
+				ref("", "configmaps", "c"),
+			},
+			expected: []configv1.ObjectReference{
+				ref("apps", "deployments", "a"),
+				ref("", "configmaps", "b"),
+				ref("", "configmaps", "c"),
+			},
+		},
+		{
+			name:   "limit keeps first entries without prioritization",
+			policy: RelatedObjectsPolicy{MaxEntries: 2},
+			objs: []configv1.ObjectReference{
+				ref("", "secrets", "a"),
+				ref("", "secrets", "b"),
+				ref("", "secrets", "c"),
+			},
+			expected: []configv1.ObjectReference{
+				ref("", "secrets", "a"),
+				ref("", "secrets", "b"),
+			},
+		},
+		{
+			name: "limit keeps most recently degraded in original order",
+			policy: RelatedObjectsPolicy{
+				MaxEntries: 2,
+				LastDegraded: func(obj configv1.ObjectReference) time.Time {
+					switch obj.Name {
+					case "c":
+						return now
+					case "a":
+						return now.Add(-time.Hour)
+					}
+					return time.Time{}
+				},
+			},
+			objs: []configv1.ObjectReference{
+				ref("", "secrets", "a"),
+				ref("", "secrets", "b"),
+				ref("", "secrets", "c"),
+				ref("", "secrets", "d"),
+			},
+			expected: []configv1.ObjectReference{
+				ref("", "secrets", "a"),
+				ref("", "secrets", "c"),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := ApplyRelatedObjectsPolicy(tc.policy, tc.objs)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -16,8 +16,10 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/utils/clock"
 
 	configv1helpers "github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+	"github.com/openshift/library-go/pkg/config/clusterstatus"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/management"
@@ -37,9 +39,10 @@ type VersionGetter interface {
 type RelatedObjectsFunc func() (isset bool, objs []configv1.ObjectReference)
 
 type StatusSyncer struct {
-	clusterOperatorName string
-	relatedObjects      []configv1.ObjectReference
-	relatedObjectsFunc  RelatedObjectsFunc
+	clusterOperatorName  string
+	relatedObjects       []configv1.ObjectReference
+	relatedObjectsFunc   RelatedObjectsFunc
+	relatedObjectsPolicy *RelatedObjectsPolicy
 
 	versionGetter         VersionGetter
 	operatorClient        operatorv1helpers.OperatorClient
@@ -51,6 +54,14 @@ type StatusSyncer struct {
 	degradedInertia   Inertia
 
 	removeUnusedVersions bool
+
+	conditionConventionsChecker *conditionConventionsChecker
+	conditionTargets            []ConditionTarget
+
+	deploymentMode clusterstatus.DeploymentModeFunc
+
+	// clock is the time the degraded inertia is relative to, nil means the real clock
+	clock clock.PassiveClock
 }
 
 var _ factory.Controller = &StatusSyncer{}
@@ -99,6 +110,14 @@ func (c *StatusSyncer) WithRelatedObjectsFunc(f RelatedObjectsFunc) {
 	c.relatedObjectsFunc = f
 }
 
+// WithRelatedObjectsPolicy returns a copy of the StatusSyncer that normalizes,
+// deduplicates and limits the related objects according to the policy.
+func (c *StatusSyncer) WithRelatedObjectsPolicy(policy RelatedObjectsPolicy) *StatusSyncer {
+	output := *c
+	output.relatedObjectsPolicy = &policy
+	return &output
+}
+
 func (c *StatusSyncer) Run(ctx context.Context, workers int) {
 	c.controllerFactory.
 		WithPostStartHooks(c.watchVersionGetterPostRunHook).
@@ -126,9 +145,56 @@ func (c *StatusSyncer) WithVersionRemoval() *StatusSyncer {
 	return &output
 }
 
+// WithConditionConventionsCheck returns a copy of the StatusSyncer that checks the
+// operator conditions against the condition conventions on every sync and reports
+// violations in logs and metrics. See ValidateConditionConventions.
+func (c *StatusSyncer) WithConditionConventionsCheck() *StatusSyncer {
+	output := *c
+	output.conditionConventionsChecker = newConditionConventionsChecker(c.clusterOperatorName)
+	return &output
+}
+
+// WithConditionTargets returns a copy of the StatusSyncer that also writes the
+// aggregated conditions to the given targets, e.g. the operator's own custom resource.
+// The targets are written before the ClusterOperator, a failure to write a target
+// does not prevent the ClusterOperator from being updated.
+func (c *StatusSyncer) WithConditionTargets(targets ...ConditionTarget) *StatusSyncer {
+	output := *c
+	output.conditionTargets = append(append([]ConditionTarget{}, c.conditionTargets...), targets...)
+	return &output
+}
+
+// WithClock returns a copy of the StatusSyncer that applies the degraded
+// inertia relative to the time of the clock, e.g. a fake clock in tests.
+func (c *StatusSyncer) WithClock(clock clock.PassiveClock) *StatusSyncer {
+	output := *c
+	output.clock = clock
+	return &output
+}
+
+// WithDeploymentMode returns a copy of the StatusSyncer that does not write the
+// ClusterOperator when the control plane is hosted, where the ClusterOperators
+// are not owned by the control plane operators.
+func (c *StatusSyncer) WithDeploymentMode(deploymentMode clusterstatus.DeploymentModeFunc) *StatusSyncer {
+	output := *c
+	output.deploymentMode = deploymentMode
+	return &output
+}
+
 // sync reacts to a change in prereqs by finding information that is required to match another value in the cluster. This
 // must be information that is logically "owned" by another component.
 func (c StatusSyncer) Sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	if c.deploymentMode != nil {
+		mode, err := c.deploymentMode()
+		if err != nil {
+			return err
+		}
+		if mode.IsHosted() {
+			klog.V(4).Infof("Not writing clusteroperator/%s in a hosted control plane", c.clusterOperatorName)
+			return nil
+		}
+	}
+
 	detailedSpec, currentDetailedStatus, _, err := c.operatorClient.GetOperatorState()
 	if apierrors.IsNotFound(err) {
 		syncCtx.Recorder().Warningf("StatusNotFound", "Unable to determine current operator status for clusteroperator/%s", c.clusterOperatorName)
@@ -141,6 +207,11 @@ func (c StatusSyncer) Sync(ctx context.Context, syncCtx factory.SyncContext) err
 		return err
 	}
 
+	operatorConditions := filterTargetOwnedConditions(c.conditionTargets, currentDetailedStatus.Conditions)
+	if c.conditionConventionsChecker != nil {
+		c.conditionConventionsChecker.check(operatorConditions)
+	}
+
 	originalClusterOperatorObj, err := c.clusterOperatorLister.Get(c.clusterOperatorName)
 	if err != nil && !apierrors.IsNotFound(err) {
 		syncCtx.Recorder().Warningf("StatusFailed", "Unable to get current operator status for clusteroperator/%s: %v", c.clusterOperatorName, err)
@@ -174,8 +245,9 @@ func (c StatusSyncer) Sync(ctx context.Context, syncCtx factory.SyncContext) err
 		configv1helpers.SetStatusCondition(&clusterOperatorObj.Status.Conditions, configv1.ClusterOperatorStatusCondition{Type: configv1.OperatorUpgradeable, Status: configv1.ConditionUnknown, Reason: "Unmanaged"})
 		configv1helpers.SetStatusCondition(&clusterOperatorObj.Status.Conditions, configv1.ClusterOperatorStatusCondition{Type: configv1.EvaluationConditionsDetected, Status: configv1.ConditionUnknown, Reason: "Unmanaged"})
 
+		targetsErr := setTargetConditions(ctx, c.conditionTargets, clusterOperatorObj.Status.Conditions)
 		if equality.Semantic.DeepEqual(clusterOperatorObj, originalClusterOperatorObj) {
-			return nil
+			return targetsErr
 		}
 		if _, err := c.clusterOperatorClient.ClusterOperators().UpdateStatus(ctx, clusterOperatorObj, metav1.UpdateOptions{}); err != nil {
 			return err
@@ -183,7 +255,7 @@ func (c StatusSyncer) Sync(ctx context.Context, syncCtx factory.SyncContext) err
 		if !skipOperatorStatusChangedEvent(originalClusterOperatorObj.Status, clusterOperatorObj.Status) {
 			syncCtx.Recorder().Eventf("OperatorStatusChanged", "Status for operator %s changed: %s", c.clusterOperatorName, configv1helpers.GetStatusDiff(originalClusterOperatorObj.Status, clusterOperatorObj.Status))
 		}
-		return nil
+		return targetsErr
 	}
 
 	if c.relatedObjectsFunc != nil {
@@ -209,18 +281,28 @@ func (c StatusSyncer) Sync(ctx context.Context, syncCtx factory.SyncContext) err
 	} else {
 		clusterOperatorObj.Status.RelatedObjects = c.relatedObjects
 	}
+	if c.relatedObjectsPolicy != nil {
+		clusterOperatorObj.Status.RelatedObjects = ApplyRelatedObjectsPolicy(*c.relatedObjectsPolicy, clusterOperatorObj.Status.RelatedObjects)
+	}
 
-	configv1helpers.SetStatusCondition(&clusterOperatorObj.Status.Conditions, UnionClusterCondition(configv1.OperatorDegraded, operatorv1.ConditionFalse, c.degradedInertia, currentDetailedStatus.Conditions...))
-	configv1helpers.SetStatusCondition(&clusterOperatorObj.Status.Conditions, UnionClusterCondition(configv1.OperatorProgressing, operatorv1.ConditionFalse, nil, currentDetailedStatus.Conditions...))
-	configv1helpers.SetStatusCondition(&clusterOperatorObj.Status.Conditions, UnionClusterCondition(configv1.OperatorAvailable, operatorv1.ConditionTrue, nil, currentDetailedStatus.Conditions...))
-	configv1helpers.SetStatusCondition(&clusterOperatorObj.Status.Conditions, UnionClusterCondition(configv1.OperatorUpgradeable, operatorv1.ConditionTrue, nil, currentDetailedStatus.Conditions...))
-	configv1helpers.SetStatusCondition(&clusterOperatorObj.Status.Conditions, UnionClusterCondition(configv1.EvaluationConditionsDetected, operatorv1.ConditionFalse, nil, currentDetailedStatus.Conditions...))
+	now := time.Now()
+	if c.clock != nil {
+		now = c.clock.Now()
+	}
+	configv1helpers.SetStatusCondition(&clusterOperatorObj.Status.Conditions, unionClusterCondition(now, configv1.OperatorDegraded, operatorv1.ConditionFalse, c.degradedInertia, operatorConditions...))
+	configv1helpers.SetStatusCondition(&clusterOperatorObj.Status.Conditions, unionClusterCondition(now, configv1.OperatorProgressing, operatorv1.ConditionFalse, nil, operatorConditions...))
+	configv1helpers.SetStatusCondition(&clusterOperatorObj.Status.Conditions, unionClusterCondition(now, configv1.OperatorAvailable, operatorv1.ConditionTrue, nil, operatorConditions...))
+	configv1helpers.SetStatusCondition(&clusterOperatorObj.Status.Conditions, unionClusterCondition(now, configv1.OperatorUpgradeable, operatorv1.ConditionTrue, nil, operatorConditions...))
+	configv1helpers.SetStatusCondition(&clusterOperatorObj.Status.Conditions, unionClusterCondition(now, configv1.EvaluationConditionsDetected, operatorv1.ConditionFalse, nil, operatorConditions...))
 
 	c.syncStatusVersions(clusterOperatorObj, syncCtx)
 
+	// write the same conditions to the additional targets first, so they never lag behind the clusteroperator
+	targetsErr := setTargetConditions(ctx, c.conditionTargets, clusterOperatorObj.Status.Conditions)
+
 	// if we have no diff, just return
 	if equality.Semantic.DeepEqual(clusterOperatorObj, originalClusterOperatorObj) {
-		return nil
+		return targetsErr
 	}
 	klog.V(2).Infof("clusteroperator/%s diff %v", c.clusterOperatorName, resourceapply.JSONPatchNoError(originalClusterOperatorObj, clusterOperatorObj))
 
@@ -230,7 +312,7 @@ func (c StatusSyncer) Sync(ctx context.Context, syncCtx factory.SyncContext) err
 	if !skipOperatorStatusChangedEvent(originalClusterOperatorObj.Status, clusterOperatorObj.Status) {
 		syncCtx.Recorder().Eventf("OperatorStatusChanged", "Status for clusteroperator/%s changed: %s", c.clusterOperatorName, configv1helpers.GetStatusDiff(originalClusterOperatorObj.Status, clusterOperatorObj.Status))
 	}
-	return nil
+	return targetsErr
 }
 
 func skipOperatorStatusChangedEvent(originalStatus, newStatus configv1.ClusterOperatorStatus) bool {
@@ -18,12 +18,14 @@ import (
 	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
 	"github.com/openshift/library-go/pkg/apiserver/jsonpatch"
 	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+	"github.com/openshift/library-go/pkg/config/clusterstatus"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/diff"
 	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
 )
 
 func TestDegraded(t *testing.T) {
@@ -365,6 +367,49 @@ func TestDegraded(t *testing.T) {
 	}
 }
 
+func TestDegradedInertiaWithClock(t *testing.T) {
+	fakeClock := clocktesting.NewFakePassiveClock(time.Now())
+	statusClient := &statusClient{
+		t: t,
+		status: operatorv1.OperatorStatus{
+			Conditions: []operatorv1.OperatorCondition{
+				{Type: "TypeADegraded", Status: operatorv1.ConditionTrue, Reason: "Failing", LastTransitionTime: metav1.NewTime(fakeClock.Now())},
+			},
+		},
+	}
+
+	for _, scenario := range []struct {
+		elapsed        time.Duration
+		expectedStatus configv1.ConditionStatus
+	}{
+		{elapsed: time.Minute, expectedStatus: configv1.ConditionFalse},
+		{elapsed: 2 * time.Minute, expectedStatus: configv1.ConditionTrue},
+	} {
+		fakeClock.SetTime(statusClient.status.Conditions[0].LastTransitionTime.Add(scenario.elapsed + time.Second))
+
+		clusterOperatorClient := fake.NewSimpleClientset()
+		controller := (&StatusSyncer{
+			clusterOperatorName:   "OPERATOR_NAME",
+			clusterOperatorClient: clusterOperatorClient.ConfigV1(),
+			clusterOperatorLister: configv1listers.NewClusterOperatorLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
+			operatorClient:        statusClient,
+			versionGetter:         NewVersionGetter(),
+		}).WithDegradedInertia(MustNewInertia(2 * time.Minute).Inertia).WithClock(fakeClock)
+		if err := controller.Sync(context.TODO(), factory.NewSyncContext("test", events.NewInMemoryRecorder("status"))); err != nil {
+			t.Fatalf("unexpected sync error: %v", err)
+		}
+
+		result, err := clusterOperatorClient.ConfigV1().ClusterOperators().Get(context.TODO(), "OPERATOR_NAME", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		degraded := v1helpers.FindStatusCondition(result.Status.Conditions, configv1.OperatorDegraded)
+		if degraded == nil || degraded.Status != scenario.expectedStatus {
+			t.Errorf("expected Degraded=%s after %v, got %v", scenario.expectedStatus, scenario.elapsed, degraded)
+		}
+	}
+}
+
 func TestRelatedObjects(t *testing.T) {
 	// save typing
 	ref := func(name string) configv1.ObjectReference {
@@ -589,6 +634,31 @@ func TestVersions(t *testing.T) {
 	}
 }
 
+func TestDeploymentMode(t *testing.T) {
+	for _, mode := range []clusterstatus.DeploymentMode{clusterstatus.StandaloneDeploymentMode, clusterstatus.HostedControlPlaneDeploymentMode} {
+		t.Run(string(mode), func(t *testing.T) {
+			clusterOperatorClient := fake.NewSimpleClientset()
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+
+			controller := (&StatusSyncer{
+				clusterOperatorName:   "OPERATOR_NAME",
+				clusterOperatorClient: clusterOperatorClient.ConfigV1(),
+				clusterOperatorLister: configv1listers.NewClusterOperatorLister(indexer),
+				operatorClient:        &statusClient{t: t},
+				versionGetter:         NewVersionGetter(),
+			}).WithDeploymentMode(clusterstatus.StaticDeploymentMode(mode))
+			if err := controller.Sync(context.TODO(), factory.NewSyncContext("test", events.NewInMemoryRecorder("status"))); err != nil {
+				t.Fatalf("unexpected sync error: %v", err)
+			}
+
+			_, err := clusterOperatorClient.ConfigV1().ClusterOperators().Get(context.TODO(), "OPERATOR_NAME", metav1.GetOptions{})
+			if written := err == nil; written == mode.IsHosted() {
+				t.Errorf("expected the clusteroperator to be written: %v, got: %v", !mode.IsHosted(), written)
+			}
+		})
+	}
+}
+
 // OperatorStatusProvider
 type statusClient struct {
 	t      *testing.T
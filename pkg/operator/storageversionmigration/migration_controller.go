@@ -0,0 +1,305 @@
+package storageversionmigration
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/discovery"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+	migrationv1alpha1 "sigs.k8s.io/kube-storage-version-migrator/pkg/apis/migration/v1alpha1"
+	kubemigratorclient "sigs.k8s.io/kube-storage-version-migrator/pkg/clients/clientset"
+	migrationv1alpha1informer "sigs.k8s.io/kube-storage-version-migrator/pkg/clients/informer/migration/v1alpha1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/management"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+const (
+	// revisionAnnotation stores the revision of the target a migration was created for.
+	revisionAnnotation = "migration.operator.openshift.io/revision"
+	// retriesAnnotation stores how many times a failed migration was restarted.
+	retriesAnnotation = "migration.operator.openshift.io/retries"
+
+	defaultRetryInterval = 5 * time.Minute
+	defaultMaxRetries    = 5
+)
+
+// MigrationTarget is a resource whose stored objects must be rewritten in the current storage version.
+type MigrationTarget struct {
+	Resource schema.GroupResource
+	// Revision identifies the schema of the resource, e.g. a hash of the CRD manifest or the operator version.
+	// A migration is started every time the revision changes.
+	Revision string
+}
+
+// StorageVersionMigrationController creates a StorageVersionMigration for every target, through the
+// kube-storage-version-migrator, whenever the revision of the target changes. Migrations that failed are restarted
+// after a retry interval, up to a maximum number of retries. The progress is reported as
+// <name>StorageVersionMigrationProgressing and <name>StorageVersionMigrationDegraded conditions.
+type StorageVersionMigrationController struct {
+	controllerInstanceName string
+	conditionPrefix        string
+	targets                []MigrationTarget
+	operatorClient         v1helpers.OperatorClient
+	migrationClient        kubemigratorclient.Interface
+	migrationInformer      migrationv1alpha1informer.StorageVersionMigrationInformer
+	discoveryClient        discovery.ServerResourcesInterface
+
+	retryInterval time.Duration
+	maxRetries    int
+	clock         clock.PassiveClock
+}
+
+// NewStorageVersionMigrationController returns a controller migrating the storage of the targets.
+func NewStorageVersionMigrationController(
+	instanceName string,
+	targets []MigrationTarget,
+	operatorClient v1helpers.OperatorClient,
+	migrationClient kubemigratorclient.Interface,
+	migrationInformer migrationv1alpha1informer.Interface,
+	discoveryClient discovery.ServerResourcesInterface,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &StorageVersionMigrationController{
+		controllerInstanceName: factory.ControllerInstanceName(instanceName, "StorageVersionMigration"),
+		conditionPrefix:        instanceName + "StorageVersionMigration",
+		targets:                targets,
+		operatorClient:         operatorClient,
+		migrationClient:        migrationClient,
+		migrationInformer:      migrationInformer.StorageVersionMigrations(),
+		discoveryClient:        discoveryClient,
+		retryInterval:          defaultRetryInterval,
+		maxRetries:             defaultMaxRetries,
+		clock:                  clock.RealClock{},
+	}
+
+	names := make([]string, 0, len(targets))
+	for _, target := range targets {
+		names = append(names, c.migrationName(target.Resource))
+	}
+	return factory.New().
+		WithInformers(operatorClient.Informer()).
+		WithFilteredEventsInformers(factory.NamesFilter(names...), c.migrationInformer.Informer()).
+		ResyncEvery(time.Minute).
+		WithSync(c.sync).
+		ToController(
+			c.controllerInstanceName,
+			eventRecorder.WithComponentSuffix("storage-version-migration-controller"),
+		)
+}
+
+func (c *StorageVersionMigrationController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	operatorSpec, _, _, err := c.operatorClient.GetOperatorState()
+	if err != nil {
+		return err
+	}
+	if !management.IsOperatorManaged(operatorSpec.ManagementState) {
+		return nil
+	}
+
+	var running, failed []string
+	var errs []error
+	for _, target := range c.targets {
+		state, err := c.ensureMigration(ctx, syncCtx.Recorder(), target)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", target.Resource, err))
+			continue
+		}
+		switch state.phase {
+		case phaseRunning:
+			running = append(running, target.Resource.String())
+		case phaseFailed:
+			failed = append(failed, fmt.Sprintf("%s: %s", target.Resource, state.message))
+		}
+	}
+	sort.Strings(running)
+	sort.Strings(failed)
+
+	progressing := applyoperatorv1.OperatorCondition().
+		WithType(c.conditionType(operatorv1.OperatorStatusTypeProgressing)).
+		WithStatus(operatorv1.ConditionFalse).
+		WithReason("AsExpected")
+	if len(running) > 0 {
+		progressing = progressing.
+			WithStatus(operatorv1.ConditionTrue).
+			WithReason("MigrationInProgress").
+			WithMessage(fmt.Sprintf("migrating %s", strings.Join(running, ", ")))
+	}
+
+	degraded := applyoperatorv1.OperatorCondition().
+		WithType(c.conditionType(operatorv1.OperatorStatusTypeDegraded)).
+		WithStatus(operatorv1.ConditionFalse).
+		WithReason("AsExpected")
+	if len(failed) > 0 {
+		degraded = degraded.
+			WithStatus(operatorv1.ConditionTrue).
+			WithReason("MigrationFailed").
+			WithMessage(strings.Join(failed, "\n"))
+	}
+
+	if err := c.operatorClient.ApplyOperatorStatus(
+		ctx,
+		c.controllerInstanceName,
+		applyoperatorv1.OperatorStatus().WithConditions(progressing, degraded),
+	); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+type migrationPhase int
+
+const (
+	phaseRunning migrationPhase = iota
+	phaseSucceeded
+	phaseFailed
+)
+
+type migrationState struct {
+	phase   migrationPhase
+	message string
+}
+
+// ensureMigration makes sure a migration for the current revision of the target exists and returns its state.
+func (c *StorageVersionMigrationController) ensureMigration(ctx context.Context, recorder events.Recorder, target MigrationTarget) (migrationState, error) {
+	name := c.migrationName(target.Resource)
+	migration, err := c.migrationInformer.Lister().Get(name)
+	if apierrors.IsNotFound(err) {
+		return migrationState{phase: phaseRunning}, c.createMigration(ctx, recorder, target, 0)
+	}
+	if err != nil {
+		return migrationState{}, err
+	}
+
+	if migration.Annotations[revisionAnnotation] != target.Revision {
+		recorder.Eventf("StorageVersionMigrationStarted", "Revision of %s changed to %q, restarting migration", target.Resource, target.Revision)
+		if err := c.deleteMigration(ctx, migration); err != nil {
+			return migrationState{}, err
+		}
+		return migrationState{phase: phaseRunning}, c.createMigration(ctx, recorder, target, 0)
+	}
+
+	for _, condition := range migration.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case migrationv1alpha1.MigrationSucceeded:
+			return migrationState{phase: phaseSucceeded}, nil
+		case migrationv1alpha1.MigrationFailed:
+			return c.resumeFailedMigration(ctx, recorder, target, migration, condition)
+		}
+	}
+	return migrationState{phase: phaseRunning}, nil
+}
+
+// resumeFailedMigration restarts a failed migration once the retry interval passed, unless it ran out of retries.
+func (c *StorageVersionMigrationController) resumeFailedMigration(ctx context.Context, recorder events.Recorder, target MigrationTarget, migration *migrationv1alpha1.StorageVersionMigration, failure migrationv1alpha1.MigrationCondition) (migrationState, error) {
+	message := fmt.Sprintf("migration failed: %s", failure.Message)
+	retries, _ := strconv.Atoi(migration.Annotations[retriesAnnotation])
+	if retries >= c.maxRetries {
+		return migrationState{phase: phaseFailed, message: fmt.Sprintf("%s (gave up after %d retries)", message, retries)}, nil
+	}
+	if c.clock.Since(failure.LastUpdateTime.Time) < c.retryInterval {
+		return migrationState{phase: phaseFailed, message: message}, nil
+	}
+
+	klog.V(2).Infof("Restarting failed storage version migration %s (retry %d/%d)", migration.Name, retries+1, c.maxRetries)
+	recorder.Warningf("StorageVersionMigrationRetried", "Restarting failed migration of %s: %s", target.Resource, failure.Message)
+	if err := c.deleteMigration(ctx, migration); err != nil {
+		return migrationState{}, err
+	}
+	return migrationState{phase: phaseRunning}, c.createMigration(ctx, recorder, target, retries+1)
+}
+
+func (c *StorageVersionMigrationController) createMigration(ctx context.Context, recorder events.Recorder, target MigrationTarget, retries int) error {
+	version, err := preferredResourceVersion(c.discoveryClient, target.Resource)
+	if err != nil {
+		return err
+	}
+	migration := &migrationv1alpha1.StorageVersionMigration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: c.migrationName(target.Resource),
+			Annotations: map[string]string{
+				revisionAnnotation: target.Revision,
+				retriesAnnotation:  strconv.Itoa(retries),
+			},
+		},
+		Spec: migrationv1alpha1.StorageVersionMigrationSpec{
+			Resource: migrationv1alpha1.GroupVersionResource{
+				Group:    target.Resource.Group,
+				Version:  version,
+				Resource: target.Resource.Resource,
+			},
+		},
+	}
+	_, err = c.migrationClient.MigrationV1alpha1().StorageVersionMigrations().Create(ctx, migration, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		// the informer is behind, we get an event and sync again
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	recorder.Eventf("StorageVersionMigrationCreated", "Created storage version migration %s for %s in version %s", migration.Name, target.Resource, version)
+	return nil
+}
+
+func (c *StorageVersionMigrationController) deleteMigration(ctx context.Context, migration *migrationv1alpha1.StorageVersionMigration) error {
+	err := c.migrationClient.MigrationV1alpha1().StorageVersionMigrations().Delete(ctx, migration.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{ResourceVersion: &migration.ResourceVersion},
+	})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (c *StorageVersionMigrationController) migrationName(gr schema.GroupResource) string {
+	group := gr.Group
+	if len(group) == 0 {
+		group = "core"
+	}
+	return strings.ToLower(fmt.Sprintf("%s-%s-%s", c.controllerInstanceName, group, gr.Resource))
+}
+
+func (c *StorageVersionMigrationController) conditionType(suffix string) string {
+	return c.conditionPrefix + suffix
+}
+
+func preferredResourceVersion(c discovery.ServerResourcesInterface, gr schema.GroupResource) (string, error) {
+	resourceLists, discoveryErr := c.ServerPreferredResources() // safe to ignore error
+	for _, resourceList := range resourceLists {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return "", err
+		}
+		if groupVersion.Group != gr.Group {
+			continue
+		}
+		for _, resource := range resourceList.APIResources {
+			if (len(resource.Group) == 0 || resource.Group == gr.Group) && resource.Name == gr.Resource {
+				if len(resource.Version) > 0 {
+					return resource.Version, nil
+				}
+				return groupVersion.Version, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("failed to find version for %s, discoveryErr=%v", gr, discoveryErr)
+}
@@ -0,0 +1,145 @@
+package storageversionmigration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	kubetesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+	migrationv1alpha1 "sigs.k8s.io/kube-storage-version-migrator/pkg/apis/migration/v1alpha1"
+	migrationfake "sigs.k8s.io/kube-storage-version-migrator/pkg/clients/clientset/fake"
+	migrationlisters "sigs.k8s.io/kube-storage-version-migrator/pkg/clients/lister/migration/v1alpha1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+type fakeDiscovery struct {
+	*fakediscovery.FakeDiscovery
+}
+
+func (d fakeDiscovery) ServerPreferredResources() ([]*metav1.APIResourceList, error) {
+	return d.Resources, nil
+}
+
+type fakeMigrationInformer struct {
+	indexer cache.Indexer
+}
+
+func (i fakeMigrationInformer) Informer() cache.SharedIndexInformer {
+	panic("not implemented")
+}
+
+func (i fakeMigrationInformer) Lister() migrationlisters.StorageVersionMigrationLister {
+	return migrationlisters.NewStorageVersionMigrationLister(i.indexer)
+}
+
+func TestStorageVersionMigrationSync(t *testing.T) {
+	target := MigrationTarget{Resource: schema.GroupResource{Group: "example.com", Resource: "widgets"}, Revision: "1"}
+	fakeClock := clocktesting.NewFakePassiveClock(time.Now())
+
+	migrationClient := migrationfake.NewSimpleClientset()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{ManagementState: operatorv1.Managed}, &operatorv1.OperatorStatus{}, nil)
+	c := &StorageVersionMigrationController{
+		controllerInstanceName: "test-StorageVersionMigration",
+		conditionPrefix:        "TestStorageVersionMigration",
+		targets:                []MigrationTarget{target},
+		operatorClient:         operatorClient,
+		migrationClient:        migrationClient,
+		migrationInformer:      fakeMigrationInformer{indexer: indexer},
+		discoveryClient: fakeDiscovery{&fakediscovery.FakeDiscovery{Fake: &kubetesting.Fake{Resources: []*metav1.APIResourceList{
+			{GroupVersion: "example.com/v2", APIResources: []metav1.APIResource{{Name: "widgets"}}},
+		}}}},
+		retryInterval: time.Minute,
+		maxRetries:    1,
+		clock:         fakeClock,
+	}
+	syncCtx := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+
+	// sync and feed the stored migration back to the lister
+	sync := func() *migrationv1alpha1.StorageVersionMigration {
+		t.Helper()
+		if err := c.sync(context.TODO(), syncCtx); err != nil {
+			t.Fatal(err)
+		}
+		migration, err := migrationClient.MigrationV1alpha1().StorageVersionMigrations().Get(context.TODO(), "test-storageversionmigration-example.com-widgets", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := indexer.Update(migration); err != nil {
+			t.Fatal(err)
+		}
+		return migration
+	}
+	setMigrationStatus := func(migration *migrationv1alpha1.StorageVersionMigration, conditionType migrationv1alpha1.MigrationConditionType) {
+		t.Helper()
+		migration = migration.DeepCopy()
+		migration.Status.Conditions = []migrationv1alpha1.MigrationCondition{
+			{Type: conditionType, Status: corev1.ConditionTrue, LastUpdateTime: metav1.NewTime(fakeClock.Now()), Message: "boom"},
+		}
+		if _, err := migrationClient.MigrationV1alpha1().StorageVersionMigrations().Update(context.TODO(), migration, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := indexer.Update(migration); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectConditions := func(progressing, degraded operatorv1.ConditionStatus) {
+		t.Helper()
+		_, status, _, _ := operatorClient.GetOperatorState()
+		if actual := v1helpers.FindOperatorCondition(status.Conditions, "TestStorageVersionMigrationProgressing"); actual == nil || actual.Status != progressing {
+			t.Errorf("expected progressing %s, got %v", progressing, actual)
+		}
+		if actual := v1helpers.FindOperatorCondition(status.Conditions, "TestStorageVersionMigrationDegraded"); actual == nil || actual.Status != degraded {
+			t.Errorf("expected degraded %s, got %v", degraded, actual)
+		}
+	}
+
+	migration := sync()
+	if migration.Spec.Resource.Version != "v2" || migration.Annotations[revisionAnnotation] != "1" {
+		t.Fatalf("unexpected migration: %#v", migration)
+	}
+	expectConditions(operatorv1.ConditionTrue, operatorv1.ConditionFalse)
+
+	setMigrationStatus(migration, migrationv1alpha1.MigrationSucceeded)
+	sync()
+	expectConditions(operatorv1.ConditionFalse, operatorv1.ConditionFalse)
+
+	// a new revision restarts the migration
+	c.targets[0].Revision = "2"
+	migration = sync()
+	if migration.Annotations[revisionAnnotation] != "2" || len(migration.Status.Conditions) != 0 {
+		t.Fatalf("expected a new migration, got %#v", migration)
+	}
+	expectConditions(operatorv1.ConditionTrue, operatorv1.ConditionFalse)
+
+	// failures are retried after the retry interval
+	setMigrationStatus(migration, migrationv1alpha1.MigrationFailed)
+	sync()
+	expectConditions(operatorv1.ConditionFalse, operatorv1.ConditionTrue)
+	fakeClock.SetTime(fakeClock.Now().Add(2 * time.Minute))
+	migration = sync()
+	if migration.Annotations[retriesAnnotation] != "1" || len(migration.Status.Conditions) != 0 {
+		t.Fatalf("expected a retried migration, got %#v", migration)
+	}
+	expectConditions(operatorv1.ConditionTrue, operatorv1.ConditionFalse)
+
+	// until the retries are exhausted
+	setMigrationStatus(migration, migrationv1alpha1.MigrationFailed)
+	fakeClock.SetTime(fakeClock.Now().Add(2 * time.Minute))
+	migration = sync()
+	if migration.Annotations[retriesAnnotation] != "1" {
+		t.Fatalf("expected no more retries, got %#v", migration)
+	}
+	expectConditions(operatorv1.ConditionFalse, operatorv1.ConditionTrue)
+}
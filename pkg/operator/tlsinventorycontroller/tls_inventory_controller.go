@@ -0,0 +1,175 @@
+package tlsinventorycontroller
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/api/annotations"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1lister "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+const (
+	// InventoryLabel marks the inventory config maps, so reporting tools can list the inventories of all the operators.
+	InventoryLabel = "certificates.openshift.io/inventory"
+	// InventoryKey is the key of the inventory in the data of the config map, as a JSON list of Entry.
+	InventoryKey = "inventory.json"
+)
+
+// Entry describes a managed TLS artifact, from the labels and annotations set by the certrotation package.
+type Entry struct {
+	// Kind is Secret or ConfigMap.
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Type is the certrotation.CertificateType of the artifact.
+	Type certrotation.CertificateType `json:"type"`
+	// Owner is the component owning the artifact.
+	Owner       string `json:"owner,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Issuer is the common name of the issuer of the certificate.
+	Issuer    string `json:"issuer,omitempty"`
+	NotBefore string `json:"notBefore,omitempty"`
+	NotAfter  string `json:"notAfter,omitempty"`
+	// RefreshPolicy is certrotation.RefreshOnExpiry or the refresh period of the certificate.
+	RefreshPolicy string `json:"refreshPolicy,omitempty"`
+	// AutoRegenerateAfterOfflineExpiry links to the test checking the certificate is regenerated after it expired
+	// offline.
+	AutoRegenerateAfterOfflineExpiry string `json:"autoRegenerateAfterOfflineExpiry,omitempty"`
+}
+
+// TLSInventoryController collects the metadata of the TLS secrets and CA bundle config maps labeled with
+// certrotation.ManagedCertificateTypeLabelName in the namespaces of an operator into an inventory config map, so the
+// certificates of the cluster can be reported without reading the secrets.
+type TLSInventoryController struct {
+	namespace string
+	name      string
+
+	configMapClient corev1client.ConfigMapsGetter
+	secretLister    corev1lister.SecretLister
+	configMapLister corev1lister.ConfigMapLister
+}
+
+// NewTLSInventoryController returns a controller writing the inventory of the managed TLS artifacts of the namespaces
+// of the informers to the config map namespace/name, e.g. openshift-config-managed/kube-apiserver-tls-inventory.
+func NewTLSInventoryController(
+	name string,
+	namespace, configMapName string,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	configMapClient corev1client.ConfigMapsGetter,
+	recorder events.Recorder,
+) factory.Controller {
+	c := &TLSInventoryController{
+		namespace:       namespace,
+		name:            configMapName,
+		configMapClient: configMapClient,
+		secretLister:    kubeInformersForNamespaces.SecretLister(),
+		configMapLister: kubeInformersForNamespaces.ConfigMapLister(),
+	}
+
+	var informers []factory.Informer
+	for _, informerNamespace := range sets.List(kubeInformersForNamespaces.Namespaces()) {
+		namespaceInformers := kubeInformersForNamespaces.InformersFor(informerNamespace)
+		informers = append(informers,
+			namespaceInformers.Core().V1().Secrets().Informer(),
+			namespaceInformers.Core().V1().ConfigMaps().Informer(),
+		)
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers(isManagedCertificate, informers...).
+		WithSync(c.sync).
+		ResyncEvery(10*time.Minute).
+		WithControllerInstanceName(factory.ControllerInstanceName(name, "TLSInventory")).
+		ToController(
+			name+"TLSInventory",
+			recorder.WithComponentSuffix(strings.ToLower(name)+"-tls-inventory"),
+		)
+}
+
+func isManagedCertificate(obj interface{}) bool {
+	accessor, ok := obj.(metav1.Object)
+	if !ok {
+		return true
+	}
+	_, ok = accessor.GetLabels()[certrotation.ManagedCertificateTypeLabelName]
+	return ok
+}
+
+func (c *TLSInventoryController) sync(ctx context.Context, syncContext factory.SyncContext) error {
+	managed, err := labels.NewRequirement(certrotation.ManagedCertificateTypeLabelName, selection.Exists, nil)
+	if err != nil {
+		return err
+	}
+	selector := labels.NewSelector().Add(*managed)
+	secrets, err := c.secretLister.List(selector)
+	if err != nil {
+		return err
+	}
+	configMaps, err := c.configMapLister.List(selector)
+	if err != nil {
+		return err
+	}
+
+	entries := []Entry{}
+	for _, secret := range secrets {
+		entries = append(entries, newEntry("Secret", secret))
+	}
+	for _, configMap := range configMaps {
+		entries = append(entries, newEntry("ConfigMap", configMap))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Namespace != entries[j].Namespace {
+			return entries[i].Namespace < entries[j].Namespace
+		}
+		if entries[i].Name != entries[j].Name {
+			return entries[i].Name < entries[j].Name
+		}
+		return entries[i].Kind < entries[j].Kind
+	})
+	inventory, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncContext.Recorder(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: c.namespace,
+			Name:      c.name,
+			Labels:    map[string]string{InventoryLabel: "true"},
+		},
+		Data: map[string]string{InventoryKey: string(inventory)},
+	})
+	return err
+}
+
+func newEntry(kind string, obj metav1.Object) Entry {
+	objAnnotations := obj.GetAnnotations()
+	return Entry{
+		Kind:                             kind,
+		Namespace:                        obj.GetNamespace(),
+		Name:                             obj.GetName(),
+		Type:                             certrotation.CertificateType(obj.GetLabels()[certrotation.ManagedCertificateTypeLabelName]),
+		Owner:                            objAnnotations[annotations.OpenShiftComponent],
+		Description:                      objAnnotations[annotations.OpenShiftDescription],
+		Issuer:                           objAnnotations[certrotation.CertificateIssuer],
+		NotBefore:                        objAnnotations[certrotation.CertificateNotBeforeAnnotation],
+		NotAfter:                         objAnnotations[certrotation.CertificateNotAfterAnnotation],
+		RefreshPolicy:                    objAnnotations[certrotation.CertificateRefreshPolicyAnnotation],
+		AutoRegenerateAfterOfflineExpiry: objAnnotations[certrotation.AutoRegenerateAfterOfflineExpiryAnnotation],
+	}
+}
@@ -0,0 +1,115 @@
+package tlsinventorycontroller
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openshift/api/annotations"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestTLSInventoryController(t *testing.T) {
+	secretIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, secret := range []*corev1.Secret{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "openshift-foo",
+				Name:      "serving-cert",
+				Labels:    map[string]string{certrotation.ManagedCertificateTypeLabelName: string(certrotation.CertificateTypeTarget)},
+				Annotations: map[string]string{
+					annotations.OpenShiftComponent:                  "foo",
+					annotations.OpenShiftDescription:                "Serves the foo API.",
+					certrotation.CertificateIssuer:                  "foo-signer",
+					certrotation.CertificateNotBeforeAnnotation:     "2024-01-01T00:00:00Z",
+					certrotation.CertificateNotAfterAnnotation:      "2024-01-31T00:00:00Z",
+					certrotation.CertificateRefreshPolicyAnnotation: "360h0m0s",
+				},
+			},
+			Data: map[string][]byte{corev1.TLSPrivateKeyKey: []byte("secret")},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "openshift-foo",
+				Name:      "signer",
+				Labels:    map[string]string{certrotation.ManagedCertificateTypeLabelName: string(certrotation.CertificateTypeSigner)},
+				Annotations: map[string]string{
+					certrotation.CertificateRefreshPolicyAnnotation:         certrotation.RefreshOnExpiry,
+					certrotation.AutoRegenerateAfterOfflineExpiryAnnotation: "https://github.com/openshift/foo/pull/1,foo recovers",
+				},
+			},
+		},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-foo", Name: "unmanaged"}},
+	} {
+		if err := secretIndexer.Add(secret); err != nil {
+			t.Fatal(err)
+		}
+	}
+	configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	if err := configMapIndexer.Add(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "openshift-bar",
+		Name:      "ca-bundle",
+		Labels:    map[string]string{certrotation.ManagedCertificateTypeLabelName: string(certrotation.CertificateTypeCABundle)},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	client := fake.NewSimpleClientset()
+	c := &TLSInventoryController{
+		namespace:       "openshift-config-managed",
+		name:            "foo-tls-inventory",
+		configMapClient: client.CoreV1(),
+		secretLister:    corev1lister.NewSecretLister(secretIndexer),
+		configMapLister: corev1lister.NewConfigMapLister(configMapIndexer),
+	}
+	if err := c.sync(context.TODO(), factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))); err != nil {
+		t.Fatal(err)
+	}
+
+	inventory, err := client.CoreV1().ConfigMaps("openshift-config-managed").Get(context.TODO(), "foo-tls-inventory", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inventory.Labels[InventoryLabel] != "true" {
+		t.Errorf("expected the inventory to be labeled, got %v", inventory.Labels)
+	}
+	var entries []Entry
+	if err := json.Unmarshal([]byte(inventory.Data[InventoryKey]), &entries); err != nil {
+		t.Fatal(err)
+	}
+	expected := []Entry{
+		{Kind: "ConfigMap", Namespace: "openshift-bar", Name: "ca-bundle", Type: certrotation.CertificateTypeCABundle},
+		{
+			Kind:          "Secret",
+			Namespace:     "openshift-foo",
+			Name:          "serving-cert",
+			Type:          certrotation.CertificateTypeTarget,
+			Owner:         "foo",
+			Description:   "Serves the foo API.",
+			Issuer:        "foo-signer",
+			NotBefore:     "2024-01-01T00:00:00Z",
+			NotAfter:      "2024-01-31T00:00:00Z",
+			RefreshPolicy: "360h0m0s",
+		},
+		{
+			Kind:                             "Secret",
+			Namespace:                        "openshift-foo",
+			Name:                             "signer",
+			Type:                             certrotation.CertificateTypeSigner,
+			RefreshPolicy:                    certrotation.RefreshOnExpiry,
+			AutoRegenerateAfterOfflineExpiry: "https://github.com/openshift/foo/pull/1,foo recovers",
+		},
+	}
+	if diff := cmp.Diff(expected, entries); diff != "" {
+		t.Errorf("unexpected inventory (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,371 @@
+package tlslintcontroller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+var (
+	problemsMetric = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Subsystem:      "operator",
+		Name:           "tls_content_problems",
+		Help:           "Problems found in the managed TLS secrets and CA bundle config maps, 1 for every problem.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"controller", "kind", "namespace", "name", "problem"})
+
+	expirationMetric = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Subsystem:      "operator",
+		Name:           "tls_certificate_expiration_timestamp_seconds",
+		Help:           "Expiration of the certificate of the managed TLS secrets, as a unix timestamp.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"controller", "namespace", "name"})
+)
+
+func init() {
+	(&sync.Once{}).Do(func() {
+		legacyregistry.MustRegister(problemsMetric)
+		legacyregistry.MustRegister(expirationMetric)
+	})
+}
+
+// ProblemType is the type of a problem found in TLS content.
+type ProblemType string
+
+const (
+	// ProblemUnparseable means the certificates or the key cannot be parsed.
+	ProblemUnparseable ProblemType = "Unparseable"
+	// ProblemKeyMismatch means the private key does not match the certificate.
+	ProblemKeyMismatch ProblemType = "KeyMismatch"
+	// ProblemWeakKey means a key is shorter than the minimum size.
+	ProblemWeakKey ProblemType = "WeakKey"
+	// ProblemExpired means the certificate is expired.
+	ProblemExpired ProblemType = "Expired"
+	// ProblemNearExpiry means the certificate expires soon.
+	ProblemNearExpiry ProblemType = "NearExpiry"
+	// ProblemDuplicateSerial means a CA bundle holds different certificates with the same issuer and serial number.
+	ProblemDuplicateSerial ProblemType = "DuplicateSerial"
+)
+
+// Problem is a problem found in a secret or config map.
+type Problem struct {
+	// Kind is Secret or ConfigMap.
+	Kind      string
+	Namespace string
+	Name      string
+	Type      ProblemType
+	Message   string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s %s/%s: %s", strings.ToLower(p.Kind), p.Namespace, p.Name, p.Message)
+}
+
+// maxReportedProblems is the number of problems listed in the condition message.
+const maxReportedProblems = 20
+
+// Option configures the TLSLintController.
+type Option func(*TLSLintController)
+
+// WithExpiryThreshold sets how long before its expiration a certificate is near expiry. A certificate is also near
+// expiry only when less than a tenth of its lifetime remains, so the short lived certificates are not always reported.
+// It defaults to 7 days.
+func WithExpiryThreshold(threshold time.Duration) Option {
+	return func(c *TLSLintController) {
+		c.expiryThreshold = threshold
+	}
+}
+
+// WithMinRSAKeyBits sets the minimum size of RSA keys, it defaults to 2048. ECDSA keys must be at least 256 bits.
+func WithMinRSAKeyBits(bits int) Option {
+	return func(c *TLSLintController) {
+		c.minRSAKeyBits = bits
+	}
+}
+
+// WithClock sets the clock the expirations are checked with.
+func WithClock(clock clock.PassiveClock) Option {
+	return func(c *TLSLintController) {
+		c.clock = clock
+	}
+}
+
+// TLSLintController periodically checks the TLS secrets and CA bundle config maps labeled with
+// certrotation.ManagedCertificateTypeLabelName for problems: certificates not matching their key, weak keys, expired
+// and near expiry certificates, and CA bundles holding several certificates with the same serial number.
+//
+// The problems are reported in the <name>TLSContentProblemsDetected condition, which does not degrade the operator,
+// and in the operator_tls_content_problems metric. The expirations of the certificates are exposed in the
+// operator_tls_certificate_expiration_timestamp_seconds metric.
+type TLSLintController struct {
+	name                   string
+	controllerInstanceName string
+	conditionType          string
+	expiryThreshold        time.Duration
+	minRSAKeyBits          int
+
+	operatorClient  v1helpers.OperatorClient
+	secretLister    corev1lister.SecretLister
+	configMapLister corev1lister.ConfigMapLister
+	clock           clock.PassiveClock
+
+	// reportedProblems and reportedExpirations are the label values of the metrics set by the last sync, to delete
+	// those gone since
+	reportedProblems    sets.Set[Problem]
+	reportedExpirations sets.Set[string]
+	lock                sync.Mutex
+}
+
+// NewTLSLintController returns a controller checking the managed TLS content of the namespaces of the informers.
+func NewTLSLintController(
+	name string,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	operatorClient v1helpers.OperatorClient,
+	recorder events.Recorder,
+	options ...Option,
+) factory.Controller {
+	c := &TLSLintController{
+		name:                   name,
+		controllerInstanceName: factory.ControllerInstanceName(name, "TLSLint"),
+		conditionType:          name + "TLSContentProblemsDetected",
+		expiryThreshold:        7 * 24 * time.Hour,
+		minRSAKeyBits:          2048,
+		operatorClient:         operatorClient,
+		secretLister:           kubeInformersForNamespaces.SecretLister(),
+		configMapLister:        kubeInformersForNamespaces.ConfigMapLister(),
+		clock:                  clock.RealClock{},
+		reportedProblems:       sets.New[Problem](),
+		reportedExpirations:    sets.New[string](),
+	}
+	for _, option := range options {
+		option(c)
+	}
+
+	var informers []factory.Informer
+	for _, namespace := range sets.List(kubeInformersForNamespaces.Namespaces()) {
+		namespaceInformers := kubeInformersForNamespaces.InformersFor(namespace)
+		informers = append(informers,
+			namespaceInformers.Core().V1().Secrets().Informer(),
+			namespaceInformers.Core().V1().ConfigMaps().Informer(),
+		)
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers(isManagedCertificate, informers...).
+		WithInformers(operatorClient.Informer()).
+		WithSync(c.sync).
+		ResyncEvery(10*time.Minute).
+		WithControllerInstanceName(c.controllerInstanceName).
+		ToController(
+			name+"TLSLint",
+			recorder.WithComponentSuffix(strings.ToLower(name)+"-tls-lint"),
+		)
+}
+
+func isManagedCertificate(obj interface{}) bool {
+	accessor, ok := obj.(metav1.Object)
+	if !ok {
+		return true
+	}
+	_, ok = accessor.GetLabels()[certrotation.ManagedCertificateTypeLabelName]
+	return ok
+}
+
+func (c *TLSLintController) sync(ctx context.Context, syncContext factory.SyncContext) error {
+	managed, err := labels.NewRequirement(certrotation.ManagedCertificateTypeLabelName, selection.Exists, nil)
+	if err != nil {
+		return err
+	}
+	selector := labels.NewSelector().Add(*managed)
+	secrets, err := c.secretLister.List(selector)
+	if err != nil {
+		return err
+	}
+	configMaps, err := c.configMapLister.List(selector)
+	if err != nil {
+		return err
+	}
+
+	now := c.clock.Now()
+	var problems []Problem
+	expirations := map[string]time.Time{}
+	for _, secret := range secrets {
+		secretProblems, notAfter := c.lintSecret(secret, now)
+		problems = append(problems, secretProblems...)
+		if !notAfter.IsZero() {
+			expirations[secret.Namespace+"/"+secret.Name] = notAfter
+		}
+	}
+	for _, configMap := range configMaps {
+		problems = append(problems, c.lintCABundle(configMap)...)
+	}
+	sort.Slice(problems, func(i, j int) bool {
+		return problems[i].String() < problems[j].String()
+	})
+	c.reportMetrics(problems, expirations)
+
+	cond := applyoperatorv1.OperatorCondition().
+		WithType(c.conditionType).
+		WithStatus(operatorv1.ConditionFalse).
+		WithReason("AsExpected")
+	if len(problems) > 0 {
+		cond = cond.
+			WithStatus(operatorv1.ConditionTrue).
+			WithReason("ProblemsDetected").
+			WithMessage(problemsMessage(problems))
+	}
+	return c.operatorClient.ApplyOperatorStatus(ctx, c.controllerInstanceName, applyoperatorv1.OperatorStatus().WithConditions(cond))
+}
+
+// lintSecret returns the problems of the secret and the expiration of its certificate, zero when it has none.
+func (c *TLSLintController) lintSecret(secret *corev1.Secret, now time.Time) ([]Problem, time.Time) {
+	problem := func(problemType ProblemType, messageFmt string, args ...interface{}) Problem {
+		return Problem{Kind: "Secret", Namespace: secret.Namespace, Name: secret.Name, Type: problemType, Message: fmt.Sprintf(messageFmt, args...)}
+	}
+	certPEM, keyPEM := secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]
+	if len(certPEM) == 0 {
+		return nil, time.Time{}
+	}
+	certs, err := crypto.CertsFromPEM(certPEM)
+	if err != nil {
+		return []Problem{problem(ProblemUnparseable, "unable to parse %s: %v", corev1.TLSCertKey, err)}, time.Time{}
+	}
+
+	var problems []Problem
+	if len(keyPEM) > 0 {
+		if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+			problems = append(problems, problem(ProblemKeyMismatch, "%s does not match %s: %v", corev1.TLSPrivateKeyKey, corev1.TLSCertKey, err))
+		}
+	}
+	for _, cert := range certs {
+		if message := c.weakKey(cert); len(message) > 0 {
+			problems = append(problems, problem(ProblemWeakKey, "certificate %q has %s", cert.Subject.CommonName, message))
+		}
+	}
+
+	leaf := certs[0]
+	lifetime := leaf.NotAfter.Sub(leaf.NotBefore)
+	remaining := leaf.NotAfter.Sub(now)
+	switch {
+	case remaining <= 0:
+		problems = append(problems, problem(ProblemExpired, "certificate %q expired at %s", leaf.Subject.CommonName, leaf.NotAfter.UTC().Format(time.RFC3339)))
+	case remaining < c.expiryThreshold && remaining < lifetime/10:
+		problems = append(problems, problem(ProblemNearExpiry, "certificate %q expires at %s", leaf.Subject.CommonName, leaf.NotAfter.UTC().Format(time.RFC3339)))
+	}
+	return problems, leaf.NotAfter
+}
+
+// lintCABundle returns the problems of the CA bundle of the config map.
+func (c *TLSLintController) lintCABundle(configMap *corev1.ConfigMap) []Problem {
+	problem := func(problemType ProblemType, messageFmt string, args ...interface{}) Problem {
+		return Problem{Kind: "ConfigMap", Namespace: configMap.Namespace, Name: configMap.Name, Type: problemType, Message: fmt.Sprintf(messageFmt, args...)}
+	}
+	bundlePEM := configMap.Data["ca-bundle.crt"]
+	if len(bundlePEM) == 0 {
+		return nil
+	}
+	certs, err := crypto.CertsFromPEM([]byte(bundlePEM))
+	if err != nil {
+		return []Problem{problem(ProblemUnparseable, "unable to parse ca-bundle.crt: %v", err)}
+	}
+
+	var problems []Problem
+	type issuerSerial struct {
+		issuer string
+		serial string
+	}
+	seen := map[issuerSerial]*x509.Certificate{}
+	for _, cert := range certs {
+		if message := c.weakKey(cert); len(message) > 0 {
+			problems = append(problems, problem(ProblemWeakKey, "certificate %q has %s", cert.Subject.CommonName, message))
+		}
+		key := issuerSerial{issuer: cert.Issuer.String(), serial: cert.SerialNumber.String()}
+		if other, ok := seen[key]; ok && !other.Equal(cert) {
+			problems = append(problems, problem(ProblemDuplicateSerial, "certificates %q and %q have the same serial %s from issuer %q", other.Subject.CommonName, cert.Subject.CommonName, key.serial, key.issuer))
+			continue
+		}
+		seen[key] = cert
+	}
+	return problems
+}
+
+// weakKey describes the public key of the certificate when it is too short, or returns an empty string.
+func (c *TLSLintController) weakKey(cert *x509.Certificate) string {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if bits := key.N.BitLen(); bits < c.minRSAKeyBits {
+			return fmt.Sprintf("a %d bits RSA key, at least %d bits are required", bits, c.minRSAKeyBits)
+		}
+	case *ecdsa.PublicKey:
+		if bits := key.Curve.Params().BitSize; bits < 256 {
+			return fmt.Sprintf("a %d bits ECDSA key, at least 256 bits are required", bits)
+		}
+	}
+	return ""
+}
+
+func (c *TLSLintController) reportMetrics(problems []Problem, expirations map[string]time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	current := sets.New[Problem]()
+	for _, problem := range problems {
+		// the metric has no message, a single series per type
+		problem.Message = ""
+		current.Insert(problem)
+		problemsMetric.WithLabelValues(c.name, problem.Kind, problem.Namespace, problem.Name, string(problem.Type)).Set(1)
+	}
+	for _, problem := range c.reportedProblems.Difference(current).UnsortedList() {
+		problemsMetric.Delete(map[string]string{"controller": c.name, "kind": problem.Kind, "namespace": problem.Namespace, "name": problem.Name, "problem": string(problem.Type)})
+	}
+	c.reportedProblems = current
+
+	currentExpirations := sets.New[string]()
+	for namespacedName, notAfter := range expirations {
+		currentExpirations.Insert(namespacedName)
+		namespace, name, _ := strings.Cut(namespacedName, "/")
+		expirationMetric.WithLabelValues(c.name, namespace, name).Set(float64(notAfter.Unix()))
+	}
+	for _, namespacedName := range c.reportedExpirations.Difference(currentExpirations).UnsortedList() {
+		namespace, name, _ := strings.Cut(namespacedName, "/")
+		expirationMetric.Delete(map[string]string{"controller": c.name, "namespace": namespace, "name": name})
+	}
+	c.reportedExpirations = currentExpirations
+}
+
+func problemsMessage(problems []Problem) string {
+	var lines []string
+	for i, problem := range problems {
+		if i == maxReportedProblems {
+			lines = append(lines, fmt.Sprintf("and %d more", len(problems)-maxReportedProblems))
+			break
+		}
+		lines = append(lines, problem.String())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,226 @@
+package tlslintcontroller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+// newCert returns a self-signed certificate and its key, in PEM.
+func newCert(t *testing.T, commonName string, serial int64, notBefore time.Time, lifetime time.Duration) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.Add(lifetime),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, err := crypto.EncodeCertificates(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM, err := crypto.EncodeKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certPEM, keyPEM
+}
+
+func TestTLSLintController(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	managed := map[string]string{certrotation.ManagedCertificateTypeLabelName: string(certrotation.CertificateTypeTarget)}
+	secret := func(name string, labels map[string]string, certPEM, keyPEM []byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-foo", Name: name, Labels: labels},
+			Data:       map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM},
+		}
+	}
+	bundle := func(name string, certs ...[]byte) *corev1.ConfigMap {
+		var data []byte
+		for _, cert := range certs {
+			data = append(data, cert...)
+		}
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-foo", Name: name, Labels: map[string]string{certrotation.ManagedCertificateTypeLabelName: string(certrotation.CertificateTypeCABundle)}},
+			Data:       map[string]string{"ca-bundle.crt": string(data)},
+		}
+	}
+
+	validCert, validKey := newCert(t, "valid", 1, now.Add(-time.Hour), 30*24*time.Hour)
+	otherCert, otherKey := newCert(t, "other", 2, now.Add(-time.Hour), 30*24*time.Hour)
+	expiredCert, expiredKey := newCert(t, "expired", 3, now.Add(-2*time.Hour), time.Hour)
+	// 2 days left of 30, less than the 7 days threshold and a tenth of the lifetime
+	nearExpiryCert, nearExpiryKey := newCert(t, "near-expiry", 4, now.Add(-28*24*time.Hour), 30*24*time.Hour)
+	// 2 hours left of 1 day, less than a tenth of the lifetime
+	shortLivedCert, shortLivedKey := newCert(t, "short-lived", 5, now.Add(-22*time.Hour), 24*time.Hour)
+	// 3 hours left of 1 day, more than a tenth of the lifetime
+	healthyShortLivedCert, healthyShortLivedKey := newCert(t, "healthy-short-lived", 6, now.Add(-21*time.Hour), 24*time.Hour)
+	sameSerialCert, _ := newCert(t, "valid", 1, now.Add(-time.Hour), 30*24*time.Hour)
+	rsaCA, err := crypto.UnsafeMakeSelfSignedCAConfigForDurationAtTime("rsa", func() time.Time { return now }, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaCert, rsaKey, err := rsaCA.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name             string
+		secrets          []*corev1.Secret
+		configMaps       []*corev1.ConfigMap
+		options          []Option
+		expectedProblems []ProblemType
+		expectedMessage  string
+	}{
+		{
+			name:       "valid content",
+			secrets:    []*corev1.Secret{secret("valid", managed, validCert, validKey), secret("healthy-short-lived", managed, healthyShortLivedCert, healthyShortLivedKey)},
+			configMaps: []*corev1.ConfigMap{bundle("bundle", validCert, otherCert)},
+		},
+		{
+			name:             "mismatched key",
+			secrets:          []*corev1.Secret{secret("mismatched", managed, validCert, otherKey)},
+			expectedProblems: []ProblemType{ProblemKeyMismatch},
+			expectedMessage:  "secret openshift-foo/mismatched: tls.key does not match tls.crt",
+		},
+		{
+			name:             "unmanaged secrets are ignored",
+			secrets:          []*corev1.Secret{secret("mismatched", nil, validCert, otherKey)},
+			expectedProblems: nil,
+		},
+		{
+			name:             "expired and near expiry certificates",
+			secrets:          []*corev1.Secret{secret("expired", managed, expiredCert, expiredKey), secret("near-expiry", managed, nearExpiryCert, nearExpiryKey), secret("short-lived", managed, shortLivedCert, shortLivedKey)},
+			expectedProblems: []ProblemType{ProblemExpired, ProblemNearExpiry, ProblemNearExpiry},
+			expectedMessage:  `secret openshift-foo/expired: certificate "expired" expired at 2024-01-01T09:00:00Z`,
+		},
+		{
+			name:             "weak keys",
+			secrets:          []*corev1.Secret{secret("rsa", managed, rsaCert, rsaKey), secret("valid", managed, validCert, validKey)},
+			configMaps:       []*corev1.ConfigMap{bundle("bundle", otherCert, rsaCert)},
+			options:          []Option{WithMinRSAKeyBits(4096)},
+			expectedProblems: []ProblemType{ProblemWeakKey, ProblemWeakKey},
+			expectedMessage:  `secret openshift-foo/rsa: certificate "rsa" has a 2048 bits RSA key, at least 4096 bits are required`,
+		},
+		{
+			name:             "duplicate serials",
+			configMaps:       []*corev1.ConfigMap{bundle("bundle", validCert, validCert, sameSerialCert)},
+			expectedProblems: []ProblemType{ProblemDuplicateSerial},
+			expectedMessage:  `configmap openshift-foo/bundle: certificates "valid" and "valid" have the same serial 1 from issuer "CN=valid"`,
+		},
+		{
+			name:             "unparseable bundle",
+			configMaps:       []*corev1.ConfigMap{bundle("bundle", []byte("garbage"))},
+			expectedProblems: []ProblemType{ProblemUnparseable},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secretIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, s := range tt.secrets {
+				if err := secretIndexer.Add(s); err != nil {
+					t.Fatal(err)
+				}
+			}
+			configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, cm := range tt.configMaps {
+				if err := configMapIndexer.Add(cm); err != nil {
+					t.Fatal(err)
+				}
+			}
+			operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{ManagementState: operatorv1.Managed}, &operatorv1.OperatorStatus{}, nil)
+			c := &TLSLintController{
+				name:                   "Foo",
+				controllerInstanceName: "Foo-TLSLint",
+				conditionType:          "FooTLSContentProblemsDetected",
+				expiryThreshold:        7 * 24 * time.Hour,
+				minRSAKeyBits:          2048,
+				operatorClient:         operatorClient,
+				secretLister:           corev1lister.NewSecretLister(secretIndexer),
+				configMapLister:        corev1lister.NewConfigMapLister(configMapIndexer),
+				clock:                  clocktesting.NewFakePassiveClock(now),
+				reportedProblems:       sets.New[Problem](),
+				reportedExpirations:    sets.New[string](),
+			}
+			for _, option := range tt.options {
+				option(c)
+			}
+
+			if err := c.sync(context.TODO(), factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))); err != nil {
+				t.Fatal(err)
+			}
+
+			var problems []ProblemType
+			for problem := range c.reportedProblems {
+				problems = append(problems, problem.Type)
+			}
+			if !sets.New(problems...).Equal(sets.New(tt.expectedProblems...)) || len(problems) != len(tt.expectedProblems) {
+				t.Errorf("expected problems %v, got %v", tt.expectedProblems, problems)
+			}
+
+			_, status, _, _ := operatorClient.GetOperatorState()
+			cond := v1helpers.FindOperatorCondition(status.Conditions, "FooTLSContentProblemsDetected")
+			if cond == nil {
+				t.Fatal("expected the FooTLSContentProblemsDetected condition")
+			}
+			expectedStatus := operatorv1.ConditionFalse
+			if len(tt.expectedProblems) > 0 {
+				expectedStatus = operatorv1.ConditionTrue
+			}
+			if cond.Status != expectedStatus {
+				t.Errorf("expected the condition to be %s, got %s: %s", expectedStatus, cond.Status, cond.Message)
+			}
+			if !strings.Contains(cond.Message, tt.expectedMessage) {
+				t.Errorf("expected the message to contain %q, got %q", tt.expectedMessage, cond.Message)
+			}
+		})
+	}
+}
+
+func TestProblemsMessage(t *testing.T) {
+	var problems []Problem
+	for i := 0; i < maxReportedProblems+5; i++ {
+		problems = append(problems, Problem{Kind: "Secret", Namespace: "ns", Name: "name", Message: "problem"})
+	}
+	message := problemsMessage(problems)
+	if lines := strings.Split(message, "\n"); len(lines) != maxReportedProblems+1 || lines[maxReportedProblems] != "and 5 more" {
+		t.Errorf("unexpected message %q", message)
+	}
+}
@@ -0,0 +1,30 @@
+package trustbundle
+
+import (
+	opv1 "github.com/openshift/api/operator/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	dc "github.com/openshift/library-go/pkg/operator/deploymentcontroller"
+)
+
+// WithDeploymentHook copies the hash of the assembled trust bundle to the pod template of the deployment, so that it
+// is rolled out whenever the bundle changes. A missing bundle is not an error, the deployment is rolled out once the
+// bundle is written.
+func WithDeploymentHook(configMapLister corev1listers.ConfigMapLister, namespace, name string) dc.DeploymentHookFunc {
+	return func(_ *opv1.OperatorSpec, deployment *appsv1.Deployment) error {
+		configMap, err := configMapLister.ConfigMaps(namespace).Get(name)
+		if err != nil {
+			return nil
+		}
+		hash, ok := configMap.Annotations[HashAnnotation]
+		if !ok {
+			return nil
+		}
+		if deployment.Spec.Template.Annotations == nil {
+			deployment.Spec.Template.Annotations = map[string]string{}
+		}
+		deployment.Spec.Template.Annotations[HashAnnotation] = hash
+		return nil
+	}
+}
@@ -0,0 +1,215 @@
+// Package trustbundle assembles the CA bundle operands trust from the trusted CA of the cluster proxy, additional CA
+// bundles and the service CA.
+package trustbundle
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	opv1 "github.com/openshift/api/operator/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	certutil "k8s.io/client-go/util/cert"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/management"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+const (
+	// CABundleKey is the key of the assembled CA bundle in the target configmap, and of the trusted CA in the
+	// configmap referenced by the cluster proxy.
+	CABundleKey = "ca-bundle.crt"
+	// HashAnnotation on the target configmap is the hash of the assembled CA bundle. Copy it to the pod template of
+	// the operands, e.g. with WithDeploymentHook, to roll them out when the bundle changes.
+	HashAnnotation = "trustbundle.operator.openshift.io/ca-bundle-hash"
+
+	proxyConfigName = "cluster"
+	// ConfigNamespace is the namespace of the configmap referenced by spec.trustedCA of the cluster proxy.
+	ConfigNamespace = "openshift-config"
+)
+
+// Source references a key of a configmap with PEM encoded CA certificates.
+type Source struct {
+	Namespace string
+	Name      string
+	Key       string
+}
+
+// TrustBundle describes the assembled configmap and its sources besides the trusted CA of the cluster proxy.
+type TrustBundle struct {
+	// Namespace and Name of the configmap the bundle is written to under CABundleKey.
+	Namespace string
+	Name      string
+
+	// AdditionalCAs are added to the bundle, e.g. the CA of an internal registry. Missing sources are skipped.
+	AdditionalCAs []Source
+	// ServiceCA, if set, adds the service CA, e.g. from a configmap injected by the service-ca operator.
+	ServiceCA *Source
+}
+
+// TrustBundleController writes the assembled trust bundle and reports the <name>TrustBundleDegraded condition when the
+// trusted CA configmap of the cluster proxy is missing or invalid. The last good bundle is kept in that case.
+type TrustBundleController struct {
+	controllerInstanceName string
+	conditionType          string
+	bundle                 TrustBundle
+
+	operatorClient v1helpers.OperatorClient
+	kubeClient     kubernetes.Interface
+	informers      v1helpers.KubeInformersForNamespaces
+	proxyLister    configv1listers.ProxyLister
+}
+
+// NewTrustBundleController returns a controller assembling the trust bundle. The informers must contain the
+// openshift-config namespace, the namespaces of the sources and the namespace of the bundle.
+func NewTrustBundleController(
+	instanceName string,
+	bundle TrustBundle,
+	operatorClient v1helpers.OperatorClient,
+	kubeClient kubernetes.Interface,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	proxyInformer configv1informers.ProxyInformer,
+	recorder events.Recorder,
+) factory.Controller {
+	c := &TrustBundleController{
+		controllerInstanceName: factory.ControllerInstanceName(instanceName, "TrustBundle"),
+		conditionType:          instanceName + "TrustBundle" + opv1.OperatorStatusTypeDegraded,
+		bundle:                 bundle,
+		operatorClient:         operatorClient,
+		kubeClient:             kubeClient,
+		informers:              kubeInformersForNamespaces,
+		proxyLister:            proxyInformer.Lister(),
+	}
+
+	// the trusted CA configmap of the proxy can have any name, watch all configmaps of the namespaces
+	namespaces := sets.New(ConfigNamespace, bundle.Namespace)
+	for _, source := range c.sources() {
+		namespaces.Insert(source.Namespace)
+	}
+	informers := []factory.Informer{operatorClient.Informer(), proxyInformer.Informer()}
+	for _, namespace := range sets.List(namespaces) {
+		informers = append(informers, kubeInformersForNamespaces.InformersFor(namespace).Core().V1().ConfigMaps().Informer())
+	}
+
+	return factory.New().
+		WithInformers(informers...).
+		WithSync(c.sync).
+		WithSyncDegradedOnError(operatorClient).
+		ResyncEvery(time.Minute).
+		ToController(c.controllerInstanceName, recorder.WithComponentSuffix("trust-bundle-controller"))
+}
+
+func (c *TrustBundleController) sources() []Source {
+	sources := append([]Source{}, c.bundle.AdditionalCAs...)
+	if c.bundle.ServiceCA != nil {
+		sources = append(sources, *c.bundle.ServiceCA)
+	}
+	return sources
+}
+
+func (c *TrustBundleController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	operatorSpec, _, _, err := c.operatorClient.GetOperatorState()
+	if err != nil {
+		return err
+	}
+	if !management.IsOperatorManaged(operatorSpec.ManagementState) {
+		return nil
+	}
+
+	condition := applyoperatorv1.OperatorCondition().
+		WithType(c.conditionType).
+		WithStatus(opv1.ConditionFalse)
+
+	caBundle, err := c.assemble()
+	if err != nil {
+		condition = condition.
+			WithStatus(opv1.ConditionTrue).
+			WithReason("InvalidTrustedCA").
+			WithMessage(err.Error())
+	} else {
+		_, _, err = resourceapply.ApplyConfigMap(ctx, c.kubeClient.CoreV1(), syncCtx.Recorder(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   c.bundle.Namespace,
+				Name:        c.bundle.Name,
+				Annotations: map[string]string{HashAnnotation: fmt.Sprintf("%x", sha256.Sum256(caBundle))},
+			},
+			Data: map[string]string{CABundleKey: string(caBundle)},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.operatorClient.ApplyOperatorStatus(ctx, c.controllerInstanceName, applyoperatorv1.OperatorStatus().WithConditions(condition))
+}
+
+// assemble returns the deduplicated certificates of all sources, the trusted CA of the proxy first. It fails only when
+// the trusted CA is missing or invalid, other invalid sources are skipped.
+func (c *TrustBundleController) assemble() ([]byte, error) {
+	var certs []*x509.Certificate
+
+	proxy, err := c.proxyLister.Get(proxyConfigName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if proxy != nil && len(proxy.Spec.TrustedCA.Name) > 0 {
+		source := Source{Namespace: ConfigNamespace, Name: proxy.Spec.TrustedCA.Name, Key: CABundleKey}
+		trustedCA, err := c.read(source)
+		if err != nil {
+			return nil, fmt.Errorf("trusted CA of the cluster proxy: %w", err)
+		}
+		certs = append(certs, trustedCA...)
+	}
+
+	for _, source := range c.sources() {
+		sourceCerts, err := c.read(source)
+		if err != nil {
+			// additional CAs are optional, e.g. the service CA is injected asynchronously
+			continue
+		}
+		certs = append(certs, sourceCerts...)
+	}
+
+	var caBundle bytes.Buffer
+	seen := sets.New[string]()
+	for _, cert := range certs {
+		if seen.Has(string(cert.Raw)) {
+			continue
+		}
+		seen.Insert(string(cert.Raw))
+		if err := pem.Encode(&caBundle, &pem.Block{Type: certutil.CertificateBlockType, Bytes: cert.Raw}); err != nil {
+			return nil, err
+		}
+	}
+	return caBundle.Bytes(), nil
+}
+
+func (c *TrustBundleController) read(source Source) ([]*x509.Certificate, error) {
+	configMap, err := c.informers.InformersFor(source.Namespace).Core().V1().ConfigMaps().Lister().ConfigMaps(source.Namespace).Get(source.Name)
+	if err != nil {
+		return nil, fmt.Errorf("configmap %s/%s: %w", source.Namespace, source.Name, err)
+	}
+	data, ok := configMap.Data[source.Key]
+	if !ok {
+		return nil, fmt.Errorf("configmap %s/%s has no %s key", source.Namespace, source.Name, source.Key)
+	}
+	certs, err := certutil.ParseCertsPEM([]byte(data))
+	if err != nil {
+		return nil, fmt.Errorf("configmap %s/%s key %s: %w", source.Namespace, source.Name, source.Key, err)
+	}
+	return certs, nil
+}
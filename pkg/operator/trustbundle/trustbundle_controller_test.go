@@ -0,0 +1,149 @@
+package trustbundle
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	opv1 "github.com/openshift/api/operator/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	certutil "k8s.io/client-go/util/cert"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func newCA(t *testing.T, name string) string {
+	t.Helper()
+	ca, err := crypto.MakeSelfSignedCAConfigForDuration(name, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, _, err := ca.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(certPEM)
+}
+
+func TestTrustBundleController(t *testing.T) {
+	proxyCA, additionalCA, serviceCA := newCA(t, "proxy"), newCA(t, "additional"), newCA(t, "service")
+
+	kubeClient := fake.NewSimpleClientset()
+	kubeInformers := v1helpers.NewKubeInformersForNamespaces(kubeClient, ConfigNamespace, "operand")
+	configIndexer := kubeInformers.InformersFor(ConfigNamespace).Core().V1().ConfigMaps().Informer().GetIndexer()
+	operandIndexer := kubeInformers.InformersFor("operand").Core().V1().ConfigMaps().Informer().GetIndexer()
+	proxyIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := proxyIndexer.Add(&configv1.Proxy{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       configv1.ProxySpec{TrustedCA: configv1.ConfigMapNameReference{Name: "user-ca-bundle"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	operatorClient := v1helpers.NewFakeOperatorClient(&opv1.OperatorSpec{ManagementState: opv1.Managed}, &opv1.OperatorStatus{}, nil)
+
+	c := &TrustBundleController{
+		controllerInstanceName: "OperandTrustBundle",
+		conditionType:          "OperandTrustBundleDegraded",
+		bundle: TrustBundle{
+			Namespace: "operand",
+			Name:      "trusted-ca-bundle",
+			// the proxy CA is deduplicated
+			AdditionalCAs: []Source{{Namespace: "operand", Name: "additional-ca", Key: "ca.crt"}, {Namespace: "operand", Name: "duplicate-ca", Key: "ca.crt"}},
+			ServiceCA:     &Source{Namespace: "operand", Name: "service-ca", Key: "service-ca.crt"},
+		},
+		operatorClient: operatorClient,
+		kubeClient:     kubeClient,
+		informers:      kubeInformers,
+		proxyLister:    configv1listers.NewProxyLister(proxyIndexer),
+	}
+	syncContext := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+
+	sync := func(expectedReason string) *corev1.ConfigMap {
+		t.Helper()
+		if err := c.sync(context.TODO(), syncContext); err != nil {
+			t.Fatal(err)
+		}
+		_, status, _, _ := operatorClient.GetOperatorState()
+		if condition := v1helpers.FindOperatorCondition(status.Conditions, "OperandTrustBundleDegraded"); condition == nil || condition.Reason != expectedReason {
+			t.Errorf("expected Degraded reason %q, got %#v", expectedReason, condition)
+		}
+		configMap, err := kubeClient.CoreV1().ConfigMaps("operand").Get(context.TODO(), "trusted-ca-bundle", metav1.GetOptions{})
+		if err != nil {
+			return nil
+		}
+		return configMap
+	}
+
+	// the trusted CA configmap of the proxy does not exist
+	if configMap := sync("InvalidTrustedCA"); configMap != nil {
+		t.Fatalf("expected no bundle, got %#v", configMap)
+	}
+
+	for _, configMap := range []*corev1.ConfigMap{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "operand", Name: "additional-ca"}, Data: map[string]string{"ca.crt": additionalCA}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "operand", Name: "duplicate-ca"}, Data: map[string]string{"ca.crt": proxyCA}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "operand", Name: "service-ca"}, Data: map[string]string{"service-ca.crt": serviceCA}},
+	} {
+		if err := operandIndexer.Add(configMap); err != nil {
+			t.Fatal(err)
+		}
+	}
+	trustedCA := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ConfigNamespace, Name: "user-ca-bundle"},
+		Data:       map[string]string{CABundleKey: proxyCA},
+	}
+	if err := configIndexer.Add(trustedCA); err != nil {
+		t.Fatal(err)
+	}
+	configMap := sync("")
+	if configMap == nil {
+		t.Fatal("expected the bundle to be written")
+	}
+	if expected := proxyCA + additionalCA + serviceCA; configMap.Data[CABundleKey] != expected {
+		t.Errorf("unexpected bundle:\n%s", configMap.Data[CABundleKey])
+	}
+	certs, err := certutil.ParseCertsPEM([]byte(configMap.Data[CABundleKey]))
+	if err != nil || len(certs) != 3 {
+		t.Errorf("expected 3 certificates, got %d: %v", len(certs), err)
+	}
+	hash := configMap.Annotations[HashAnnotation]
+	if len(hash) == 0 {
+		t.Fatal("expected hash annotation")
+	}
+
+	// the deployment hook copies the hash
+	if err := operandIndexer.Add(configMap); err != nil {
+		t.Fatal(err)
+	}
+	deployment := &appsv1.Deployment{}
+	if err := WithDeploymentHook(kubeInformers.InformersFor("operand").Core().V1().ConfigMaps().Lister(), "operand", "trusted-ca-bundle")(nil, deployment); err != nil {
+		t.Fatal(err)
+	}
+	if deployment.Spec.Template.Annotations[HashAnnotation] != hash {
+		t.Errorf("expected hash %q on the pod template, got %v", hash, deployment.Spec.Template.Annotations)
+	}
+
+	// an invalid trusted CA keeps the last good bundle
+	trustedCA.Data[CABundleKey] = "not a certificate"
+	if err := configIndexer.Update(trustedCA); err != nil {
+		t.Fatal(err)
+	}
+	configMap = sync("InvalidTrustedCA")
+	if configMap.Annotations[HashAnnotation] != hash {
+		t.Errorf("expected the bundle to be kept, got %#v", configMap)
+	}
+	_, status, _, _ := operatorClient.GetOperatorState()
+	if condition := v1helpers.FindOperatorCondition(status.Conditions, "OperandTrustBundleDegraded"); !strings.Contains(condition.Message, "user-ca-bundle") {
+		t.Errorf("expected the message to name the configmap, got %q", condition.Message)
+	}
+}
@@ -21,6 +21,8 @@ import (
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/library-go/pkg/operator/objectbudget"
 )
 
 // SetOperandVersion sets the new version and returns the previous value.
@@ -55,6 +57,7 @@ func SetOperatorCondition(conditions *[]operatorv1.OperatorCondition, newConditi
 	if conditions == nil {
 		conditions = &[]operatorv1.OperatorCondition{}
 	}
+	newCondition.Message = objectbudget.TruncateConditionMessage(newCondition.Message)
 	existingCondition := FindOperatorCondition(*conditions, newCondition.Type)
 	if existingCondition == nil {
 		newCondition.LastTransitionTime = metav1.NewTime(time.Now())
@@ -0,0 +1,116 @@
+package v1helpers
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+)
+
+// InformerSelector restricts the objects watched by an informer using label and/or field selectors.
+type InformerSelector struct {
+	LabelSelector string
+	FieldSelector string
+}
+
+func (s InformerSelector) String() string {
+	return fmt.Sprintf("labels=%q,fields=%q", s.LabelSelector, s.FieldSelector)
+}
+
+// ScopedKubeInformersForNamespaces extends KubeInformersForNamespaces with informers that only watch objects matching
+// a selector. Use them for operators that only care about a few labeled objects in namespaces full of unrelated
+// objects (e.g. secrets), to avoid caching all of them.
+type ScopedKubeInformersForNamespaces interface {
+	KubeInformersForNamespaces
+
+	// ScopedInformersFor returns a shared informer factory for the namespace that only watches objects matching the
+	// selector. Factories are shared per namespace and selector. They are started by Start(), so request the informers
+	// before calling it, and are included in WaitForCacheSync(), but not in the unified listers.
+	ScopedInformersFor(namespace string, selector InformerSelector) informers.SharedInformerFactory
+}
+
+type scopedInformerKey struct {
+	namespace string
+	selector  InformerSelector
+}
+
+type scopedKubeInformersForNamespaces struct {
+	kubeInformersForNamespaces
+
+	kubeClient kubernetes.Interface
+
+	lock   sync.Mutex
+	scoped map[scopedInformerKey]informers.SharedInformerFactory
+}
+
+var _ ScopedKubeInformersForNamespaces = &scopedKubeInformersForNamespaces{}
+
+// NewScopedKubeInformersForNamespaces returns KubeInformersForNamespaces for the given namespaces which can also
+// provide selector scoped informers.
+func NewScopedKubeInformersForNamespaces(kubeClient kubernetes.Interface, namespaces ...string) ScopedKubeInformersForNamespaces {
+	return &scopedKubeInformersForNamespaces{
+		kubeInformersForNamespaces: NewKubeInformersForNamespaces(kubeClient, namespaces...).(kubeInformersForNamespaces),
+		kubeClient:                 kubeClient,
+		scoped:                     map[scopedInformerKey]informers.SharedInformerFactory{},
+	}
+}
+
+func (i *scopedKubeInformersForNamespaces) ScopedInformersFor(namespace string, selector InformerSelector) informers.SharedInformerFactory {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	key := scopedInformerKey{namespace: namespace, selector: selector}
+	if factory, ok := i.scoped[key]; ok {
+		return factory
+	}
+	factory := informers.NewSharedInformerFactoryWithOptions(i.kubeClient, 10*time.Minute,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = selector.LabelSelector
+			options.FieldSelector = selector.FieldSelector
+		}),
+	)
+	i.scoped[key] = factory
+	return factory
+}
+
+// Start starts all informers, including the scoped ones.
+func (i *scopedKubeInformersForNamespaces) Start(stopCh <-chan struct{}) {
+	i.kubeInformersForNamespaces.Start(stopCh)
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	for _, factory := range i.scoped {
+		factory.Start(stopCh)
+	}
+}
+
+// WaitForCacheSync waits for all started informers' cache were synced. The results of the scoped informers are
+// merged into the namespace they watch, an informer type is reported as synced only when it synced in all factories.
+func (i *scopedKubeInformersForNamespaces) WaitForCacheSync(stopCh <-chan struct{}) map[string]map[reflect.Type]bool {
+	ret := i.kubeInformersForNamespaces.WaitForCacheSync(stopCh)
+
+	i.lock.Lock()
+	scoped := make(map[scopedInformerKey]informers.SharedInformerFactory, len(i.scoped))
+	for key, factory := range i.scoped {
+		scoped[key] = factory
+	}
+	i.lock.Unlock()
+
+	for key, factory := range scoped {
+		if ret[key.namespace] == nil {
+			ret[key.namespace] = map[reflect.Type]bool{}
+		}
+		for informerType, synced := range factory.WaitForCacheSync(stopCh) {
+			if existing, ok := ret[key.namespace][informerType]; ok {
+				synced = synced && existing
+			}
+			ret[key.namespace][informerType] = synced
+		}
+	}
+	return ret
+}
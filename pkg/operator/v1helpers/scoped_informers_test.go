@@ -0,0 +1,53 @@
+package v1helpers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestScopedKubeInformersForNamespaces(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "labeled", Labels: map[string]string{"app": "operator"}}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "unrelated"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "labeled", Labels: map[string]string{"app": "operator"}}},
+	)
+	informers := NewScopedKubeInformersForNamespaces(kubeClient, "ns")
+	selector := InformerSelector{LabelSelector: "app=operator"}
+	scoped := informers.ScopedInformersFor("ns", selector)
+	if informers.ScopedInformersFor("ns", selector) != scoped {
+		t.Fatal("expected the scoped factory to be shared")
+	}
+	scopedLister := scoped.Core().V1().Secrets().Lister()
+	unscopedLister := informers.InformersFor("ns").Core().V1().Secrets().Lister()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	informers.Start(ctx.Done())
+	for namespace, synced := range informers.WaitForCacheSync(ctx.Done()) {
+		for informerType, ok := range synced {
+			if !ok {
+				t.Fatalf("%s informer in %q did not sync", informerType, namespace)
+			}
+		}
+	}
+
+	secrets, err := scopedLister.List(labels.Everything())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secrets) != 1 || secrets[0].Name != "labeled" || secrets[0].Namespace != "ns" {
+		t.Errorf("expected only the labeled secret in ns, got %v", secrets)
+	}
+	secrets, err = unscopedLister.List(labels.Everything())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secrets) != 2 {
+		t.Errorf("expected both secrets in ns, got %d", len(secrets))
+	}
+}
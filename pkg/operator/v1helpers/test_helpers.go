@@ -83,10 +83,20 @@ func (fakeSharedIndexInformer) SetTransform(f cache.TransformFunc) error {
 
 // NewFakeStaticPodOperatorClient returns a fake operator client suitable to use in static pod controller unit tests.
 func NewFakeStaticPodOperatorClient(
+	staticPodSpec *operatorv1.StaticPodOperatorSpec, staticPodStatus *operatorv1.StaticPodOperatorStatus,
+	triggerStatusErr func(rv string, status *operatorv1.StaticPodOperatorStatus) error,
+	triggerSpecErr func(rv string, spec *operatorv1.StaticPodOperatorSpec) error) *fakeStaticPodOperatorClient {
+	return NewFakeStaticPodOperatorClientWithObjectMeta(nil, staticPodSpec, staticPodStatus, triggerStatusErr, triggerSpecErr)
+}
+
+// NewFakeStaticPodOperatorClientWithObjectMeta returns a fake operator client with the given operator metadata.
+func NewFakeStaticPodOperatorClientWithObjectMeta(
+	meta *metav1.ObjectMeta,
 	staticPodSpec *operatorv1.StaticPodOperatorSpec, staticPodStatus *operatorv1.StaticPodOperatorStatus,
 	triggerStatusErr func(rv string, status *operatorv1.StaticPodOperatorStatus) error,
 	triggerSpecErr func(rv string, spec *operatorv1.StaticPodOperatorSpec) error) *fakeStaticPodOperatorClient {
 	return &fakeStaticPodOperatorClient{
+		fakeObjectMeta:              meta,
 		fakeStaticPodOperatorSpec:   staticPodSpec,
 		fakeStaticPodOperatorStatus: staticPodStatus,
 		resourceVersion:             "0",
@@ -96,6 +106,7 @@ func NewFakeStaticPodOperatorClient(
 }
 
 type fakeStaticPodOperatorClient struct {
+	fakeObjectMeta              *metav1.ObjectMeta
 	fakeStaticPodOperatorSpec   *operatorv1.StaticPodOperatorSpec
 	fakeStaticPodOperatorStatus *operatorv1.StaticPodOperatorStatus
 	resourceVersion             string
@@ -110,7 +121,10 @@ func (c *fakeStaticPodOperatorClient) Informer() cache.SharedIndexInformer {
 
 }
 func (c *fakeStaticPodOperatorClient) GetObjectMeta() (*metav1.ObjectMeta, error) {
-	panic("not supported")
+	if c.fakeObjectMeta == nil {
+		return &metav1.ObjectMeta{}, nil
+	}
+	return c.fakeObjectMeta, nil
 }
 
 func (c *fakeStaticPodOperatorClient) GetStaticPodOperatorState() (*operatorv1.StaticPodOperatorSpec, *operatorv1.StaticPodOperatorStatus, string, error) {
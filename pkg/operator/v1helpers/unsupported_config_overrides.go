@@ -0,0 +1,129 @@
+package v1helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+)
+
+// ConfigSource names the operator spec field a field of a merged config came from.
+type ConfigSource string
+
+const (
+	ObservedConfigSource             ConfigSource = "observedConfig"
+	UnsupportedConfigOverridesSource ConfigSource = "unsupportedConfigOverrides"
+)
+
+// ConfigProvenance maps the dot separated path of every field of a merged config to its source. Lists are fields of
+// their own, they are replaced as a whole by unsupportedConfigOverrides.
+type ConfigProvenance map[string]ConfigSource
+
+// Overridden returns the sorted paths of the fields set by unsupportedConfigOverrides.
+func (p ConfigProvenance) Overridden() []string {
+	var paths []string
+	for path, source := range p {
+		if source == UnsupportedConfigOverridesSource {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// DecodeUnsupportedConfigOverrides decodes the unsupportedConfigOverrides of the operator spec into the given typed
+// struct. Unknown and duplicate fields are rejected so that misspelled overrides are not silently ignored. The struct is
+// left untouched when no overrides are set.
+func DecodeUnsupportedConfigOverrides(spec *operatorv1.OperatorSpec, into interface{}) error {
+	if len(spec.UnsupportedConfigOverrides.Raw) == 0 {
+		return nil
+	}
+	if err := yaml.UnmarshalStrict(spec.UnsupportedConfigOverrides.Raw, into); err != nil {
+		return fmt.Errorf("invalid unsupportedConfigOverrides: %w", err)
+	}
+	return nil
+}
+
+// MergeUnsupportedConfigOverrides merges the unsupportedConfigOverrides of the operator spec over its observedConfig
+// like resourcemerge.MergeProcessConfig and returns the merged config with the source of each of its fields.
+func MergeUnsupportedConfigOverrides(spec *operatorv1.OperatorSpec) ([]byte, ConfigProvenance, error) {
+	observed := map[string]interface{}{}
+	if len(spec.ObservedConfig.Raw) > 0 {
+		if err := yaml.Unmarshal(spec.ObservedConfig.Raw, &observed); err != nil {
+			return nil, nil, fmt.Errorf("invalid observedConfig: %w", err)
+		}
+	}
+	overrides := map[string]interface{}{}
+	if len(spec.UnsupportedConfigOverrides.Raw) > 0 {
+		if err := yaml.Unmarshal(spec.UnsupportedConfigOverrides.Raw, &overrides); err != nil {
+			return nil, nil, fmt.Errorf("invalid unsupportedConfigOverrides: %w", err)
+		}
+	}
+
+	observedJSON, err := json.Marshal(observed)
+	if err != nil {
+		return nil, nil, err
+	}
+	overridesJSON, err := json.Marshal(overrides)
+	if err != nil {
+		return nil, nil, err
+	}
+	merged, err := resourcemerge.MergeProcessConfig(nil, observedJSON, overridesJSON)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	provenance := ConfigProvenance{}
+	for _, path := range configFieldPaths(nil, observed) {
+		provenance[path] = ObservedConfigSource
+	}
+	for _, overridden := range configFieldPaths(nil, overrides) {
+		// an override replaces the observed field at its path, and any observed field it is nested in or contains
+		for path := range provenance {
+			if path == overridden || strings.HasPrefix(path, overridden+".") || strings.HasPrefix(overridden, path+".") {
+				delete(provenance, path)
+			}
+		}
+		provenance[overridden] = UnsupportedConfigOverridesSource
+	}
+	return merged, provenance, nil
+}
+
+// configFieldPaths returns the paths of the non-map fields of the config.
+func configFieldPaths(pathSoFar []string, config map[string]interface{}) []string {
+	var paths []string
+	for key, value := range config {
+		path := append(append([]string{}, pathSoFar...), key)
+		// empty maps do not change the merged config
+		if nested, ok := value.(map[string]interface{}); ok {
+			paths = append(paths, configFieldPaths(path, nested)...)
+			continue
+		}
+		paths = append(paths, strings.Join(path, "."))
+	}
+	return paths
+}
+
+// NewUnsupportedConfigOverridesActiveCondition returns a condition of the given type listing the fields set by
+// unsupportedConfigOverrides. It is meant to be reported permanently, with status False when no overrides are active,
+// so that the use of unsupported overrides is visible to support.
+func NewUnsupportedConfigOverridesActiveCondition(conditionType string, provenance ConfigProvenance) *applyoperatorv1.OperatorConditionApplyConfiguration {
+	condition := applyoperatorv1.OperatorCondition().
+		WithType(conditionType).
+		WithStatus(operatorv1.ConditionFalse).
+		WithReason("NoUnsupportedConfigOverrides")
+
+	if overridden := provenance.Overridden(); len(overridden) > 0 {
+		condition = condition.
+			WithStatus(operatorv1.ConditionTrue).
+			WithReason("UnsupportedConfigOverridesActive").
+			WithMessage(fmt.Sprintf("unsupportedConfigOverrides set: %s", strings.Join(overridden, ", ")))
+	}
+	return condition
+}
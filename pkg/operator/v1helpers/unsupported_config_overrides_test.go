@@ -0,0 +1,103 @@
+package v1helpers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+func TestDecodeUnsupportedConfigOverrides(t *testing.T) {
+	type overrides struct {
+		Encryption struct {
+			Reason string `json:"reason"`
+		} `json:"encryption"`
+	}
+
+	for _, tc := range []struct {
+		name        string
+		raw         string
+		expected    string
+		expectError string
+	}{
+		{name: "empty"},
+		{name: "yaml", raw: "encryption:\n  reason: force\n", expected: "force"},
+		{name: "json", raw: `{"encryption":{"reason":"force"}}`, expected: "force"},
+		{name: "unknown field", raw: `{"encryption":{"reasn":"force"}}`, expectError: `unknown field "reasn"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &operatorv1.OperatorSpec{UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(tc.raw)}}
+			var actual overrides
+			err := DecodeUnsupportedConfigOverrides(spec, &actual)
+			if len(tc.expectError) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tc.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tc.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual.Encryption.Reason != tc.expected {
+				t.Errorf("expected reason %q, got %q", tc.expected, actual.Encryption.Reason)
+			}
+		})
+	}
+}
+
+func TestMergeUnsupportedConfigOverrides(t *testing.T) {
+	spec := &operatorv1.OperatorSpec{
+		ObservedConfig: runtime.RawExtension{Raw: []byte(`{"apiServerArguments":{"feature-gates":["A=true"],"audit-log-path":["/var/log"]},"servingInfo":{"minTLSVersion":"VersionTLS12"},"storage":"etcd"}`)},
+		UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(`
+apiServerArguments:
+  feature-gates:
+  - B=true
+servingInfo: {}
+storage:
+  type: etcd3
+`)},
+	}
+
+	merged, provenance, err := MergeUnsupportedConfigOverrides(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := map[string]interface{}{}
+	if err := yaml.Unmarshal(merged, &actual); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"apiServerArguments": map[string]interface{}{"feature-gates": []interface{}{"B=true"}, "audit-log-path": []interface{}{"/var/log"}},
+		"servingInfo":        map[string]interface{}{"minTLSVersion": "VersionTLS12"},
+		"storage":            map[string]interface{}{"type": "etcd3"},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("unexpected merged config %s", merged)
+	}
+
+	expectedProvenance := ConfigProvenance{
+		"apiServerArguments.feature-gates":  UnsupportedConfigOverridesSource,
+		"apiServerArguments.audit-log-path": ObservedConfigSource,
+		"servingInfo.minTLSVersion":         ObservedConfigSource,
+		"storage.type":                      UnsupportedConfigOverridesSource,
+	}
+	if !reflect.DeepEqual(expectedProvenance, provenance) {
+		t.Errorf("expected provenance %v, got %v", expectedProvenance, provenance)
+	}
+}
+
+func TestNewUnsupportedConfigOverridesActiveCondition(t *testing.T) {
+	condition := NewUnsupportedConfigOverridesActiveCondition("UnsupportedConfigOverridesActive", ConfigProvenance{"a": ObservedConfigSource})
+	if *condition.Status != operatorv1.ConditionFalse || *condition.Reason != "NoUnsupportedConfigOverrides" {
+		t.Errorf("expected no active overrides, got %v %v", *condition.Status, *condition.Reason)
+	}
+
+	condition = NewUnsupportedConfigOverridesActiveCondition("UnsupportedConfigOverridesActive", ConfigProvenance{"b.c": UnsupportedConfigOverridesSource, "a": UnsupportedConfigOverridesSource})
+	if *condition.Status != operatorv1.ConditionTrue || *condition.Message != "unsupportedConfigOverrides set: a, b.c" {
+		t.Errorf("expected active overrides, got %v %v", *condition.Status, *condition.Message)
+	}
+}
@@ -0,0 +1,121 @@
+// Package workloadpartitioning makes operand pods partition-aware: they are annotated as management workloads, so that
+// on clusters with CPU partitioning, e.g. single node OpenShift, they run on the reserved management CPUs only.
+package workloadpartitioning
+
+import (
+	"encoding/json"
+
+	configv1 "github.com/openshift/api/config/v1"
+	opv1 "github.com/openshift/api/operator/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dc "github.com/openshift/library-go/pkg/operator/deploymentcontroller"
+)
+
+const (
+	// ManagementAnnotation marks a pod as a management workload. The namespace of the pod must allow management
+	// workloads with the workload.openshift.io/allowed annotation.
+	ManagementAnnotation = "target.workload.openshift.io/management"
+	// ManagementAnnotationValue prefers, but does not require, the management CPUs.
+	ManagementAnnotationValue = `{"effect": "PreferredDuringScheduling"}`
+	// ResourcesAnnotationPrefix prefixes the name of a container in the annotation with the CPU shares of the container.
+	// The workload admission plugin sets it for regular pods, the kubelet reads it for static pods.
+	ResourcesAnnotationPrefix = "resources.workload.openshift.io/"
+
+	infraConfigName = "cluster"
+)
+
+// Enabled returns true if the nodes of the cluster are configured with CPU partitioning.
+func Enabled(infra *configv1.Infrastructure) bool {
+	return infra.Status.CPUPartitioning == configv1.CPUPartitioningAllNodes
+}
+
+// WithDeploymentHook annotates the pod template of the deployment as a management workload.
+func WithDeploymentHook(infraLister configv1listers.InfrastructureLister) dc.DeploymentHookFunc {
+	return func(_ *opv1.OperatorSpec, deployment *appsv1.Deployment) error {
+		infra, err := infraLister.Get(infraConfigName)
+		if err != nil {
+			return err
+		}
+		AnnotatePodTemplate(&deployment.Spec.Template, Enabled(infra))
+		return nil
+	}
+}
+
+// WithDaemonSetHook annotates the pod template of the daemonset as a management workload. It is meant to be used as
+// csidrivernodeservicecontroller.DaemonSetHookFunc.
+func WithDaemonSetHook(infraLister configv1listers.InfrastructureLister) func(*opv1.OperatorSpec, *appsv1.DaemonSet) error {
+	return func(_ *opv1.OperatorSpec, daemonSet *appsv1.DaemonSet) error {
+		infra, err := infraLister.Get(infraConfigName)
+		if err != nil {
+			return err
+		}
+		AnnotatePodTemplate(&daemonSet.Spec.Template, Enabled(infra))
+		return nil
+	}
+}
+
+// WithStaticPodHook annotates the static pod as a management workload, including the CPU shares of its containers
+// the workload admission plugin would set for regular pods. It is meant to be used as installerpod.PodMutationFunc.
+func WithStaticPodHook(infraLister configv1listers.InfrastructureLister) func(*corev1.Pod) error {
+	return func(pod *corev1.Pod) error {
+		infra, err := infraLister.Get(infraConfigName)
+		if err != nil {
+			return err
+		}
+		return AnnotateStaticPod(pod, Enabled(infra))
+	}
+}
+
+// AnnotatePodTemplate sets the management annotation on the pod template if partitioning is enabled and removes it
+// otherwise.
+func AnnotatePodTemplate(template *corev1.PodTemplateSpec, enabled bool) {
+	setManagementAnnotation(&template.ObjectMeta, enabled)
+}
+
+// AnnotateStaticPod sets the management annotation and the resource annotations of all containers on the static pod if
+// partitioning is enabled and removes them otherwise.
+func AnnotateStaticPod(pod *corev1.Pod, enabled bool) error {
+	setManagementAnnotation(&pod.ObjectMeta, enabled)
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range containers {
+			key := ResourcesAnnotationPrefix + container.Name
+			if !enabled {
+				delete(pod.Annotations, key)
+				continue
+			}
+			resources, err := json.Marshal(struct {
+				CPUShares int64 `json:"cpushares"`
+			}{CPUShares: cpuShares(container.Resources.Requests.Cpu().MilliValue())})
+			if err != nil {
+				return err
+			}
+			pod.Annotations[key] = string(resources)
+		}
+	}
+	return nil
+}
+
+func setManagementAnnotation(meta *metav1.ObjectMeta, enabled bool) {
+	if !enabled {
+		delete(meta.Annotations, ManagementAnnotation)
+		return
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[ManagementAnnotation] = ManagementAnnotationValue
+}
+
+// cpuShares converts milli CPUs into CPU shares the way the kubelet does.
+func cpuShares(milliCPU int64) int64 {
+	const minShares, sharesPerCPU, milliCPUToCPU = 2, 1024, 1000
+	shares := milliCPU * sharesPerCPU / milliCPUToCPU
+	if shares < minShares {
+		return minShares
+	}
+	return shares
+}
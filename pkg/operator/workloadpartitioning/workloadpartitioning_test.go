@@ -0,0 +1,76 @@
+package workloadpartitioning
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	configv1 "github.com/openshift/api/config/v1"
+	opv1 "github.com/openshift/api/operator/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func infraLister(t *testing.T, mode configv1.CPUPartitioningMode) configv1listers.InfrastructureLister {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(&configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status:     configv1.InfrastructureStatus{CPUPartitioning: mode},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return configv1listers.NewInfrastructureLister(indexer)
+}
+
+func TestWithDeploymentHook(t *testing.T) {
+	deployment := &appsv1.Deployment{}
+	if err := WithDeploymentHook(infraLister(t, configv1.CPUPartitioningAllNodes))(&opv1.OperatorSpec{}, deployment); err != nil {
+		t.Fatal(err)
+	}
+	if deployment.Spec.Template.Annotations[ManagementAnnotation] != ManagementAnnotationValue {
+		t.Errorf("expected the management annotation, got %v", deployment.Spec.Template.Annotations)
+	}
+
+	if err := WithDeploymentHook(infraLister(t, configv1.CPUPartitioningNone))(&opv1.OperatorSpec{}, deployment); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := deployment.Spec.Template.Annotations[ManagementAnnotation]; ok {
+		t.Errorf("expected no management annotation without partitioning, got %v", deployment.Spec.Template.Annotations)
+	}
+}
+
+func TestAnnotateStaticPod(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "setup"}},
+			Containers: []corev1.Container{{
+				Name: "server",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+				},
+			}},
+		},
+	}
+
+	if err := AnnotateStaticPod(pod, true); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		ManagementAnnotation:                     ManagementAnnotationValue,
+		"resources.workload.openshift.io/setup":  `{"cpushares":2}`,
+		"resources.workload.openshift.io/server": `{"cpushares":256}`,
+	}
+	if diff := cmp.Diff(expected, pod.Annotations); len(diff) > 0 {
+		t.Errorf("unexpected annotations: %s", diff)
+	}
+
+	if err := AnnotateStaticPod(pod, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(pod.Annotations) != 0 {
+		t.Errorf("expected the annotations to be removed, got %v", pod.Annotations)
+	}
+}
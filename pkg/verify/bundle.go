@@ -0,0 +1,132 @@
+package verify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+// Bundle records the verification of a release digest: the signatures which verified it, the keys they were
+// verified with and when. It is exported signed, so disconnected clusters and audits can check offline what was
+// verified at install or upgrade time.
+type Bundle struct {
+	// ReleaseDigest is the verified release digest.
+	ReleaseDigest string `json:"releaseDigest"`
+	// VerifiedAt is when the signatures were verified.
+	VerifiedAt time.Time `json:"verifiedAt"`
+	// Signatures are the signatures which verified the release digest, one per keyring.
+	Signatures []BundleSignature `json:"signatures"`
+}
+
+// BundleSignature is a signature of the release digest and the key which verified it.
+type BundleSignature struct {
+	// Keyring is the name of the verifier keyring the key belongs to.
+	Keyring string `json:"keyring"`
+	// KeyFingerprint is the fingerprint of the key which signed the signature.
+	KeyFingerprint string `json:"keyFingerprint"`
+	// Signature is the signature in containers/image format.
+	Signature []byte `json:"signature"`
+}
+
+// ExportBundle returns the verification of the release digest by the verifier as a bundle signed by the signer. The
+// digest must have been verified already, see Interface.Verify, and its signatures are verified again at now.
+func ExportBundle(verifier Interface, releaseDigest string, signer *openpgp.Entity, now time.Time) ([]byte, error) {
+	signatures, ok := verifier.Signatures()[releaseDigest]
+	if !ok {
+		return nil, fmt.Errorf("%s has not been verified", releaseDigest)
+	}
+
+	bundle := Bundle{ReleaseDigest: releaseDigest, VerifiedAt: now.UTC()}
+	verifiers := verifier.Verifiers()
+	keyrings := make([]string, 0, len(verifiers))
+	for name := range verifiers {
+		keyrings = append(keyrings, name)
+	}
+	sort.Strings(keyrings)
+	for _, name := range keyrings {
+		signature, fingerprint, err := findSignature(signatures, verifiers[name], releaseDigest, now)
+		if err != nil {
+			return nil, fmt.Errorf("unable to verify %s against keyring %s: %w", releaseDigest, name, err)
+		}
+		bundle.Signatures = append(bundle.Signatures, BundleSignature{Keyring: name, KeyFingerprint: fingerprint, Signature: signature})
+	}
+
+	content, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, err
+	}
+	var signed bytes.Buffer
+	w, err := openpgp.Sign(&signed, signer, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to sign the bundle: %w", err)
+	}
+	if _, err := w.Write(content); err != nil {
+		return nil, fmt.Errorf("unable to sign the bundle: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("unable to sign the bundle: %w", err)
+	}
+	return signed.Bytes(), nil
+}
+
+// VerifyBundle verifies offline a bundle exported by ExportBundle: it must be signed by a key of bundleKeyring, and
+// its release signatures must verify its release digest against all the verifiers, as they would have at the time
+// of the verification. It returns the verified bundle.
+func VerifyBundle(data []byte, bundleKeyring openpgp.EntityList, verifiers map[string]openpgp.EntityList) (*Bundle, error) {
+	if len(verifiers) == 0 {
+		return nil, fmt.Errorf("no verifiers to verify the bundle against")
+	}
+	// the bundle signature is checked now, while the release signatures are checked when they were verified
+	content, _, err := verifySignatureWithKeyring(bytes.NewReader(data), bundleKeyring, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("the bundle signature is not valid: %w", err)
+	}
+	d := json.NewDecoder(bytes.NewReader(content))
+	d.DisallowUnknownFields()
+	bundle := &Bundle{}
+	if err := d.Decode(bundle); err != nil {
+		return nil, fmt.Errorf("the bundle is not valid JSON: %w", err)
+	}
+	if !validReleaseDigest.MatchString(bundle.ReleaseDigest) {
+		return nil, fmt.Errorf("the bundle release digest has an invalid format: %q", bundle.ReleaseDigest)
+	}
+
+	for name, keyring := range verifiers {
+		var signatures [][]byte
+		for _, signature := range bundle.Signatures {
+			if signature.Keyring == name {
+				signatures = append(signatures, signature.Signature)
+			}
+		}
+		if _, _, err := findSignature(signatures, keyring, bundle.ReleaseDigest, bundle.VerifiedAt); err != nil {
+			return nil, fmt.Errorf("unable to verify %s against keyring %s: %w", bundle.ReleaseDigest, name, err)
+		}
+	}
+	return bundle, nil
+}
+
+// findSignature returns the first of the signatures which verifies the release digest against the keyring at now,
+// with the fingerprint of its key.
+func findSignature(signatures [][]byte, keyring openpgp.EntityList, releaseDigest string, now time.Time) ([]byte, string, error) {
+	if len(signatures) == 0 {
+		return nil, "", fmt.Errorf("no signature")
+	}
+	var lastErr error
+	for _, signature := range signatures {
+		content, fingerprint, err := verifySignatureWithKeyring(bytes.NewReader(signature), keyring, now)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if err := verifyAtomicContainerSignature(content, releaseDigest); err != nil {
+			lastErr = err
+			continue
+		}
+		return signature, fingerprint, nil
+	}
+	return nil, "", lastErr
+}
@@ -0,0 +1,91 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"fmt"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/packet"
+
+	"github.com/openshift/library-go/pkg/verify/store/memory"
+)
+
+// sign returns the message signed by the entity.
+func sign(t *testing.T, entity *openpgp.Entity, message []byte) []byte {
+	t.Helper()
+	var signed bytes.Buffer
+	w, err := openpgp.Sign(&signed, entity, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(message); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return signed.Bytes()
+}
+
+func TestBundle(t *testing.T) {
+	const digest = "sha256:e3f12513a4b22a2d7c0e7c9207f52128113758d9d68c7d06b11a0ac7672966f7"
+	newEntity := func(name string) *openpgp.Entity {
+		entity, err := openpgp.NewEntity(name, "", name+"@example.com", &packet.Config{DefaultHash: crypto.SHA256})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return entity
+	}
+	releaseKey, otherReleaseKey, clusterKey := newEntity("release"), newEntity("other-release"), newEntity("cluster")
+	releaseSignature := sign(t, releaseKey, []byte(fmt.Sprintf(`{"critical":{"type":"atomic container signature","image":{"docker-manifest-digest":%q},"identity":{"docker-reference":"quay.io/openshift-release-dev/ocp-release"}},"optional":{}}`, digest)))
+
+	verifiers := map[string]openpgp.EntityList{"release": {releaseKey}}
+	verifier := NewReleaseVerifier(verifiers, &memory.Store{Data: map[string][][]byte{digest: {releaseSignature}}})
+
+	if _, err := ExportBundle(verifier, digest, clusterKey, time.Now()); err == nil {
+		t.Fatal("expected the export of an unverified digest to fail")
+	}
+	if err := verifier.Verify(context.TODO(), digest); err != nil {
+		t.Fatal(err)
+	}
+	verifiedAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	data, err := ExportBundle(verifier, digest, clusterKey, verifiedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bundle, err := VerifyBundle(data, openpgp.EntityList{clusterKey}, verifiers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bundle.ReleaseDigest != digest || !bundle.VerifiedAt.Equal(verifiedAt) || len(bundle.Signatures) != 1 {
+		t.Fatalf("unexpected bundle %#v", bundle)
+	}
+	if signature := bundle.Signatures[0]; signature.Keyring != "release" || signature.KeyFingerprint != fmt.Sprintf("%X", releaseKey.PrimaryKey.Fingerprint) || !bytes.Equal(signature.Signature, releaseSignature) {
+		t.Errorf("unexpected signature %#v", signature)
+	}
+
+	if _, err := VerifyBundle(data, openpgp.EntityList{otherReleaseKey}, verifiers); err == nil {
+		t.Error("expected a bundle signed by an unknown key to be rejected")
+	}
+	if _, err := VerifyBundle(data, openpgp.EntityList{clusterKey}, map[string]openpgp.EntityList{"release": {releaseKey}, "other": {otherReleaseKey}}); err == nil {
+		t.Error("expected a bundle missing the signature of a verifier to be rejected")
+	}
+	tampered := sign(t, clusterKey, bytes.Replace(mustReadSigned(t, data, clusterKey), []byte("e3f1"), []byte("0000"), 1))
+	if _, err := VerifyBundle(tampered, openpgp.EntityList{clusterKey}, verifiers); err == nil {
+		t.Error("expected a bundle whose release signatures do not match its digest to be rejected")
+	}
+}
+
+func mustReadSigned(t *testing.T, data []byte, entity *openpgp.Entity) []byte {
+	t.Helper()
+	content, _, err := verifySignatureWithKeyring(bytes.NewReader(data), openpgp.EntityList{entity}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
@@ -192,7 +192,7 @@ func (v *releaseVerifier) Verify(ctx context.Context, releaseDigest string) erro
 			return false, nil
 		}
 		for k, keyring := range remaining {
-			content, _, err := verifySignatureWithKeyring(bytes.NewReader(signature), keyring)
+			content, _, err := verifySignatureWithKeyring(bytes.NewReader(signature), keyring, time.Now())
 			if err != nil {
 				klog.V(4).Infof("keyring %q could not verify signature for %s: %v", k, releaseDigest, err)
 				errs = append(errs, fmt.Errorf("%s: %w", time.Now().Format(time.RFC3339), err))
@@ -322,8 +322,9 @@ func (s *fileStore) String() string {
 
 // verifySignatureWithKeyring performs a containers/image verification of the provided signature
 // message, checking for the integrity and authenticity of the provided message in r. It will return
-// the identity of the signer if successful along with the message contents.
-func verifySignatureWithKeyring(r io.Reader, keyring openpgp.EntityList) ([]byte, string, error) {
+// the identity of the signer if successful along with the message contents. The signature must not
+// have expired at now.
+func verifySignatureWithKeyring(r io.Reader, keyring openpgp.EntityList, now time.Time) ([]byte, string, error) {
 	md, err := openpgp.ReadMessage(r, keyring, nil, nil)
 	if err != nil {
 		return nil, "", fmt.Errorf("could not read the message: %v", err)
@@ -344,7 +345,7 @@ func verifySignatureWithKeyring(r io.Reader, keyring openpgp.EntityList) ([]byte
 	if md.Signature != nil {
 		if md.Signature.SigLifetimeSecs != nil {
 			expiry := md.Signature.CreationTime.Add(time.Duration(*md.Signature.SigLifetimeSecs) * time.Second)
-			if time.Now().After(expiry) {
+			if now.After(expiry) {
 				return nil, "", fmt.Errorf("signature expired on %s", expiry)
 			}
 		}